@@ -0,0 +1,166 @@
+package wework
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// access_token失效的错误码（重新获取后重试一次）
+const (
+	apiErrInvalidToken = 40014
+	apiErrMissingToken = 41001
+	apiErrTokenExpired = 42001
+)
+
+// KFAPIError 企业微信服务端接口返回的错误（微信客服、通讯录等接口共用）
+type KFAPIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *KFAPIError) Error() string {
+	return fmt.Sprintf("企业微信接口返回错误: %d, %s", e.Code, e.Msg)
+}
+
+// apiClient 企业微信服务端接口的公共部分：按corpid和secret获取并缓存access_token，调用接口并解析errcode
+type apiClient struct {
+	corpID  string
+	secret  string
+	baseURL string
+	client  *http.Client
+
+	mutex   sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newAPIClient 创建接口客户端（API地址默认 DefaultKFBaseURL）
+func newAPIClient(corpID, secret string) *apiClient {
+	return &apiClient{
+		corpID:  corpID,
+		secret:  secret,
+		baseURL: DefaultKFBaseURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// post 以JSON请求体调用需要access_token的接口
+func (c *apiClient) post(ctx context.Context, path string, req, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, http.MethodPost, path, nil, body, resp)
+}
+
+// get 以查询参数调用需要access_token的接口
+func (c *apiClient) get(ctx context.Context, path string, query url.Values, resp interface{}) error {
+	return c.call(ctx, http.MethodGet, path, query, nil, resp)
+}
+
+// call 附带access_token调用接口，token失效时重新获取并重试一次
+func (c *apiClient) call(ctx context.Context, method, path string, query url.Values, body []byte, resp interface{}) error {
+	for attempt := 0; ; attempt++ {
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+		q := url.Values{"access_token": {token}}
+		for k, v := range query {
+			q[k] = v
+		}
+		err = c.do(ctx, method, path+"?"+q.Encode(), body, resp)
+		var apiErr *KFAPIError
+		if attempt == 0 && errors.As(err, &apiErr) && isTokenError(apiErr.Code) {
+			c.invalidateToken(token)
+			continue
+		}
+		return err
+	}
+}
+
+// accessToken 获取access_token（有效期内复用，提前5分钟刷新）
+func (c *apiClient) accessToken(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	query := url.Values{"corpid": {c.corpID}, "corpsecret": {c.secret}}
+	if err := c.do(ctx, http.MethodGet, "/cgi-bin/gettoken?"+query.Encode(), nil, &resp); err != nil {
+		return "", fmt.Errorf("获取access_token失败: %w", err)
+	}
+	c.token = resp.AccessToken
+	c.expires = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - 5*time.Minute)
+	return c.token, nil
+}
+
+// invalidateToken 丢弃已失效的access_token（其他请求已刷新时保留新token）
+func (c *apiClient) invalidateToken(token string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// do 发送请求并解析响应，errcode非0时返回 *KFAPIError
+func (c *apiClient) do(ctx context.Context, method, path string, body []byte, resp interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", httpResp.StatusCode, string(data))
+	}
+
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return &KFAPIError{Code: result.ErrCode, Msg: result.ErrMsg}
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// isTokenError 判断错误码是否表示access_token无效或过期
+func isTokenError(code int) bool {
+	return code == apiErrInvalidToken || code == apiErrMissingToken || code == apiErrTokenExpired
+}
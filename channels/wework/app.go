@@ -0,0 +1,45 @@
+package wework
+
+import (
+	"context"
+	"fmt"
+)
+
+// AppMarkdownMaxBytes 应用消息Markdown内容的最大长度（字节）
+const AppMarkdownMaxBytes = 2048
+
+// AppClient 自建应用消息接口客户端：以应用身份向成员发送消息，自动获取并缓存access_token
+type AppClient struct {
+	*apiClient
+	agentID int
+}
+
+// NewAppClient 创建应用消息接口客户端（secret为自建应用Secret，agentID为应用的AgentId）
+func NewAppClient(corpID, secret string, agentID int) *AppClient {
+	return &AppClient{apiClient: newAPIClient(corpID, secret), agentID: agentID}
+}
+
+// SetBaseURL 替换API地址（如通过代理访问），需在调用接口前设置
+func (c *AppClient) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
+// SendMarkdown 向单个成员发送Markdown消息（成员不在应用可见范围内时返回错误）
+func (c *AppClient) SendMarkdown(ctx context.Context, userID, content string) error {
+	req := map[string]interface{}{
+		"touser":   userID,
+		"msgtype":  "markdown",
+		"agentid":  c.agentID,
+		"markdown": map[string]string{"content": content},
+	}
+	var resp struct {
+		InvalidUser string `json:"invaliduser"`
+	}
+	if err := c.post(ctx, "/cgi-bin/message/send", req, &resp); err != nil {
+		return fmt.Errorf("发送应用消息失败: %w", err)
+	}
+	if resp.InvalidUser != "" {
+		return fmt.Errorf("发送应用消息失败: 成员 %s 无效或不在应用可见范围内", resp.InvalidUser)
+	}
+	return nil
+}
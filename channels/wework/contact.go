@@ -0,0 +1,82 @@
+package wework
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// 成员状态
+const (
+	ContactStatusActive   = 1 // 已激活
+	ContactStatusDisabled = 2 // 已禁用
+	ContactStatusInactive = 4 // 未激活
+	ContactStatusLeft     = 5 // 退出企业
+)
+
+// ContactUser 通讯录成员（只包含常用字段；2022年6月后新创建的应用读取姓名等字段需在管理后台授权）
+type ContactUser struct {
+	UserID         string `json:"userid"`
+	Name           string `json:"name"`
+	Department     []int  `json:"department"`                // 所属部门ID
+	MainDepartment int    `json:"main_department,omitempty"` // 主部门ID
+	Position       string `json:"position,omitempty"`        // 职务
+	Email          string `json:"email,omitempty"`
+	Status         int    `json:"status,omitempty"` // 1已激活 2已禁用 4未激活 5退出企业
+}
+
+// Department 通讯录部门
+type Department struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID int    `json:"parentid"` // 上级部门ID（根部门为0）
+	Order    int    `json:"order,omitempty"`
+}
+
+// ContactClient 通讯录接口客户端：读取部门和成员，自动获取并缓存access_token
+type ContactClient struct {
+	*apiClient
+}
+
+// NewContactClient 创建通讯录接口客户端（secret为通讯录同步Secret，或有通讯录读取权限的自建应用Secret）
+func NewContactClient(corpID, secret string) *ContactClient {
+	return &ContactClient{apiClient: newAPIClient(corpID, secret)}
+}
+
+// SetBaseURL 替换API地址（如通过代理访问），需在调用接口前设置
+func (c *ContactClient) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
+// ListDepartments 获取所有部门（应用可见范围内）
+func (c *ContactClient) ListDepartments(ctx context.Context) ([]Department, error) {
+	var resp struct {
+		Department []Department `json:"department"`
+	}
+	if err := c.get(ctx, "/cgi-bin/department/list", nil, &resp); err != nil {
+		return nil, fmt.Errorf("获取部门列表失败: %w", err)
+	}
+	return resp.Department, nil
+}
+
+// ListDepartmentUsers 获取部门的直属成员（不含子部门）
+func (c *ContactClient) ListDepartmentUsers(ctx context.Context, departmentID int) ([]ContactUser, error) {
+	var resp struct {
+		UserList []ContactUser `json:"userlist"`
+	}
+	query := url.Values{"department_id": {strconv.Itoa(departmentID)}}
+	if err := c.get(ctx, "/cgi-bin/user/list", query, &resp); err != nil {
+		return nil, fmt.Errorf("获取部门 %d 的成员失败: %w", departmentID, err)
+	}
+	return resp.UserList, nil
+}
+
+// GetUser 获取单个成员
+func (c *ContactClient) GetUser(ctx context.Context, userID string) (*ContactUser, error) {
+	var user ContactUser
+	if err := c.get(ctx, "/cgi-bin/user/get", url.Values{"userid": {userID}}, &user); err != nil {
+		return nil, fmt.Errorf("获取成员 %s 失败: %w", userID, err)
+	}
+	return &user, nil
+}
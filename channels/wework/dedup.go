@@ -0,0 +1,92 @@
+package wework
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDedupSize = 10000     // 默认去重缓存容量
+	DefaultDedupTTL  = time.Hour // 默认去重窗口
+)
+
+// Deduplicator 消息去重器（多副本部署时可替换为共享存储实现，如Redis）
+type Deduplicator interface {
+	// Seen 记录消息ID，已在去重窗口内出现过时返回true（检查与记录为原子操作）
+	Seen(msgID string) bool
+}
+
+// MemoryDeduplicator 进程内去重器：按记录顺序组成的有界链表 + TTL，
+// 超出容量或过期的记录从最旧一端淘汰，单次操作均摊O(1)
+type MemoryDeduplicator struct {
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List // 最新记录在前
+	mutex sync.Mutex
+}
+
+// dedupEntry 去重记录
+type dedupEntry struct {
+	id   string
+	seen time.Time
+}
+
+// NewMemoryDeduplicator 创建进程内去重器，size/ttl不大于0时使用默认值
+func NewMemoryDeduplicator(size int, ttl time.Duration) *MemoryDeduplicator {
+	if size <= 0 {
+		size = DefaultDedupSize
+	}
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return &MemoryDeduplicator{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Seen 实现 Deduplicator 接口
+func (d *MemoryDeduplicator) Seen(msgID string) bool {
+	if msgID == "" {
+		return false
+	}
+
+	now := time.Now()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if el, ok := d.items[msgID]; ok {
+		if now.Sub(el.Value.(*dedupEntry).seen) < d.ttl {
+			return true
+		}
+		d.remove(el)
+	}
+
+	d.items[msgID] = d.order.PushFront(&dedupEntry{id: msgID, seen: now})
+
+	// 淘汰超出容量和已过期的最旧记录
+	for back := d.order.Back(); back != nil; back = d.order.Back() {
+		if d.order.Len() <= d.size && now.Sub(back.Value.(*dedupEntry).seen) < d.ttl {
+			break
+		}
+		d.remove(back)
+	}
+	return false
+}
+
+// Len 当前缓存的记录数
+func (d *MemoryDeduplicator) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.order.Len()
+}
+
+// remove 删除记录（调用方需持有锁）
+func (d *MemoryDeduplicator) remove(el *list.Element) {
+	d.order.Remove(el)
+	delete(d.items, el.Value.(*dedupEntry).id)
+}
@@ -0,0 +1,49 @@
+package wework
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryDeduplicator(t *testing.T) {
+	d := NewMemoryDeduplicator(3, time.Hour)
+	if d.Seen("m1") {
+		t.Fatal("首次出现的消息 Seen() 应为false")
+	}
+	if !d.Seen("m1") {
+		t.Error("重复消息 Seen() 应为true")
+	}
+	// 空ID不记录
+	if d.Seen("") || d.Seen("") || d.Len() != 1 {
+		t.Errorf("空ID不应被记录，Len() = %d", d.Len())
+	}
+
+	// 超出容量时淘汰最旧的记录
+	for i := 2; i <= 4; i++ {
+		d.Seen(fmt.Sprintf("m%d", i))
+	}
+	if d.Len() != 3 {
+		t.Errorf("Len() = %d，期望 3", d.Len())
+	}
+	if d.Seen("m1") {
+		t.Error("被淘汰的记录 Seen() 应为false")
+	}
+}
+
+func TestMemoryDeduplicatorTTL(t *testing.T) {
+	d := NewMemoryDeduplicator(10, 20*time.Millisecond)
+	d.Seen("m1")
+	time.Sleep(30 * time.Millisecond)
+
+	// 过期记录视为新消息，并在下次记录时一并淘汰
+	if d.Seen("m1") {
+		t.Error("过期记录 Seen() 应为false")
+	}
+	d.Seen("m2")
+	time.Sleep(30 * time.Millisecond)
+	d.Seen("m3")
+	if d.Len() != 1 {
+		t.Errorf("Len() = %d，期望过期记录被淘汰后为 1", d.Len())
+	}
+}
@@ -0,0 +1,8 @@
+// Package wework 企业微信智能机器人协议层
+//
+// 包含回调消息结构、消息加解密（WXBizJsonMsgCrypt）和基于gin的Webhook处理器，
+// 不依赖具体的AI实现：业务方实现 MessageHandler 接口即可接入企业微信。
+//
+// 本包是独立版本化的Go模块，按语义化版本发布（标签格式 channels/wework/vX.Y.Z），
+// v1之前的次版本号变更可能包含不兼容的API调整。
+package wework
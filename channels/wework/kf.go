@@ -0,0 +1,103 @@
+package wework
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultKFBaseURL 企业微信服务端API地址
+const DefaultKFBaseURL = "https://qyapi.weixin.qq.com"
+
+// KFTextMaxBytes 微信客服文本消息内容的最大长度（字节）
+const KFTextMaxBytes = 2048
+
+// 微信客服消息来源
+const (
+	KFOriginCustomer = 3 // 微信客户发送的消息
+	KFOriginSystem   = 4 // 系统推送的事件消息
+	KFOriginServicer = 5 // 接待人员在企业微信客户端发送的消息
+)
+
+// KFMessage 微信客服消息（sync_msg 返回）
+type KFMessage struct {
+	MsgID          string          `json:"msgid"`
+	OpenKfID       string          `json:"open_kfid"`       // 客服账号ID
+	ExternalUserID string          `json:"external_userid"` // 客户UserID
+	SendTime       int64           `json:"send_time"`       // 发送时间（Unix秒）
+	Origin         int             `json:"origin"`          // 消息来源：3客户 4系统事件 5接待人员
+	ServicerUserID string          `json:"servicer_userid,omitempty"`
+	MsgType        string          `json:"msgtype"` // text|image|voice|video|file|location|link|event 等
+	Text           *KFTextContent  `json:"text,omitempty"`
+	Event          *KFEventContent `json:"event,omitempty"`
+}
+
+// KFTextContent 微信客服文本消息
+type KFTextContent struct {
+	Content string `json:"content"`
+	MenuID  string `json:"menu_id,omitempty"` // 客户点击菜单消息时的菜单ID
+}
+
+// KFEventContent 微信客服事件（如 enter_session 进入会话）
+type KFEventContent struct {
+	EventType      string `json:"event_type"`
+	OpenKfID       string `json:"open_kfid,omitempty"`
+	ExternalUserID string `json:"external_userid,omitempty"`
+	Scene          string `json:"scene,omitempty"`
+	WelcomeCode    string `json:"welcome_code,omitempty"`
+}
+
+// KFSyncRequest 拉取消息请求
+type KFSyncRequest struct {
+	Cursor   string `json:"cursor,omitempty"`    // 上次拉取返回的next_cursor（为空从最早的消息开始）
+	Token    string `json:"token,omitempty"`     // 回调事件中的Token（10分钟内有效，拉取频率限制更宽松）
+	Limit    int    `json:"limit,omitempty"`     // 每次拉取条数（默认1000）
+	OpenKfID string `json:"open_kfid,omitempty"` // 只拉取该客服账号的消息
+}
+
+// KFSyncResponse 拉取消息结果
+type KFSyncResponse struct {
+	NextCursor string      `json:"next_cursor"`
+	HasMore    int         `json:"has_more"` // 1表示还有更多消息
+	MsgList    []KFMessage `json:"msg_list"`
+}
+
+// KFClient 微信客服接口客户端：拉取客户消息（kf/sync_msg）、发送消息（kf/send_msg），自动获取并缓存access_token
+type KFClient struct {
+	*apiClient
+}
+
+// NewKFClient 创建微信客服接口客户端（secret为“微信客服”应用的Secret）
+func NewKFClient(corpID, secret string) *KFClient {
+	return &KFClient{apiClient: newAPIClient(corpID, secret)}
+}
+
+// SetBaseURL 替换API地址（如通过代理访问），需在调用接口前设置
+func (c *KFClient) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
+// SyncMsg 拉取客服消息（收到 kf_msg_or_event 回调后调用，has_more为1时用next_cursor继续拉取）
+func (c *KFClient) SyncMsg(ctx context.Context, req KFSyncRequest) (*KFSyncResponse, error) {
+	var resp KFSyncResponse
+	if err := c.post(ctx, "/cgi-bin/kf/sync_msg", req, &resp); err != nil {
+		return nil, fmt.Errorf("拉取客服消息失败: %w", err)
+	}
+	return &resp, nil
+}
+
+// SendText 向客户发送文本消息（客户发消息后48小时内最多5条），返回消息ID
+func (c *KFClient) SendText(ctx context.Context, externalUserID, openKfID, content string) (string, error) {
+	req := map[string]interface{}{
+		"touser":    externalUserID,
+		"open_kfid": openKfID,
+		"msgtype":   MsgTypeText,
+		"text":      map[string]string{"content": content},
+	}
+	var resp struct {
+		MsgID string `json:"msgid"`
+	}
+	if err := c.post(ctx, "/cgi-bin/kf/send_msg", req, &resp); err != nil {
+		return "", fmt.Errorf("发送客服消息失败: %w", err)
+	}
+	return resp.MsgID, nil
+}
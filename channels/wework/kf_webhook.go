@@ -0,0 +1,116 @@
+package wework
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KFEventMsgOrEvent 微信客服有新消息或事件时的回调事件（需调用 sync_msg 拉取具体内容）
+const KFEventMsgOrEvent = "kf_msg_or_event"
+
+// KFCallbackEvent 微信客服回调事件（解密后的XML）
+type KFCallbackEvent struct {
+	ToUserName string `xml:"ToUserName"` // 企业ID
+	CreateTime int64  `xml:"CreateTime"`
+	MsgType    string `xml:"MsgType"`  // 固定为event
+	Event      string `xml:"Event"`    // 固定为kf_msg_or_event
+	Token      string `xml:"Token"`    // 调用sync_msg时使用的Token（10分钟内有效）
+	OpenKfID   string `xml:"OpenKfId"` // 有新消息的客服账号ID
+}
+
+// KFWebhookHandler 微信客服回调处理器：验证URL，解密 kf_msg_or_event 事件后交给onEvent拉取消息
+type KFWebhookHandler struct {
+	wxcpt   *WXBizJsonMsgCrypt
+	onEvent func(ev *KFCallbackEvent)
+
+	onDecryptFailure func(err error)
+	maxBodySize      int64 // 请求体大小上限（字节）
+}
+
+// NewKFWebhookHandler 创建微信客服回调处理器（onEvent在请求中同步调用，耗时操作应另起goroutine）
+func NewKFWebhookHandler(token, aesKey, corpID string, onEvent func(ev *KFCallbackEvent)) (*KFWebhookHandler, error) {
+	wxcpt, err := NewWXBizMsgCrypt(token, aesKey, corpID, MsgFormatXML) // 微信客服回调为XML格式，receiveId为企业ID
+	if err != nil {
+		return nil, fmt.Errorf("创建加解密实例失败: %w", err)
+	}
+	return &KFWebhookHandler{wxcpt: wxcpt, onEvent: onEvent, maxBodySize: DefaultMaxBodySize}, nil
+}
+
+// SetMaxBodySize 设置回调请求体大小上限（字节，不大于0时使用DefaultMaxBodySize）
+func (k *KFWebhookHandler) SetMaxBodySize(n int64) {
+	if n <= 0 {
+		n = DefaultMaxBodySize
+	}
+	k.maxBodySize = n
+}
+
+// OnDecryptFailure 设置验签/解密失败回调（用于监控）
+func (k *KFWebhookHandler) OnDecryptFailure(fn func(err error)) {
+	k.onDecryptFailure = fn
+}
+
+// HandleWebhook 处理回调请求（GET验证URL，POST接收事件）
+func (k *KFWebhookHandler) HandleWebhook(c *gin.Context) {
+	params, err := parseCallbackParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	signature, timestamp, nonce := params.signature, params.timestamp, params.nonce
+
+	switch c.Request.Method {
+	case http.MethodGet:
+		echostr := c.Query("echostr")
+		if echostr == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required parameters"})
+			return
+		}
+		ret, echoStr, err := k.wxcpt.VerifyURL(signature, timestamp, nonce, echostr)
+		if ret != WXBizMsgCrypt_OK || err != nil {
+			k.reportDecryptFailure(ret, err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Verification failed"})
+			return
+		}
+		c.String(http.StatusOK, echoStr)
+
+	case http.MethodPost:
+		body, status, err := readCallbackBody(c, k.maxBodySize)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		ret, content, err := k.wxcpt.DecryptMsg(string(body), signature, timestamp, nonce)
+		if ret != WXBizMsgCrypt_OK || err != nil {
+			k.reportDecryptFailure(ret, err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Decryption failed"})
+			return
+		}
+
+		var ev KFCallbackEvent
+		if err := xml.Unmarshal([]byte(content), &ev); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message format"})
+			return
+		}
+		if ev.Event == KFEventMsgOrEvent && k.onEvent != nil {
+			k.onEvent(&ev)
+		}
+		c.String(http.StatusOK, "success")
+
+	default:
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
+	}
+}
+
+// reportDecryptFailure 触发验签/解密失败回调
+func (k *KFWebhookHandler) reportDecryptFailure(ret int, err error) {
+	if k.onDecryptFailure == nil {
+		return
+	}
+	if err == nil {
+		err = fmt.Errorf("错误码: %d", ret)
+	}
+	k.onDecryptFailure(err)
+}
@@ -0,0 +1,106 @@
+package wework
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodySize 回调请求体大小上限的默认值（字节）
+//
+// 回调只包含加密后的消息信封，正常大小在几KB以内，图片、文件以URL形式传递。
+const DefaultMaxBodySize int64 = 256 << 10
+
+// 回调签名参数的长度限制
+const (
+	signatureLength = 40 // SHA1十六进制签名
+	maxTimestampLen = 20
+	maxNonceLen     = 64
+)
+
+// acceptedContentTypes 回调请求体允许的Content-Type（未设置时也接受）
+var acceptedContentTypes = map[string]bool{
+	"application/json": true,
+	"text/json":        true,
+	"application/xml":  true,
+	"text/xml":         true,
+	"text/plain":       true,
+}
+
+// callbackParams 回调查询参数中的签名信息
+type callbackParams struct {
+	signature string
+	timestamp string
+	nonce     string
+}
+
+// parseCallbackParams 读取并校验签名参数，在读取请求体和解密之前拒绝明显无效的请求
+func parseCallbackParams(c *gin.Context) (callbackParams, error) {
+	p := callbackParams{
+		signature: c.Query("msg_signature"),
+		timestamp: c.Query("timestamp"),
+		nonce:     c.Query("nonce"),
+	}
+	if p.signature == "" || p.timestamp == "" || p.nonce == "" {
+		return p, errors.New("Missing required parameters")
+	}
+	if len(p.signature) != signatureLength || !isHex(p.signature) {
+		return p, errors.New("Invalid msg_signature")
+	}
+	if len(p.timestamp) > maxTimestampLen || !isDigits(p.timestamp) {
+		return p, errors.New("Invalid timestamp")
+	}
+	if len(p.nonce) > maxNonceLen {
+		return p, errors.New("Invalid nonce")
+	}
+	return p, nil
+}
+
+// readCallbackBody 校验Content-Type并读取不超过maxBytes的请求体，失败时返回应答的HTTP状态码
+func readCallbackBody(c *gin.Context, maxBytes int64) ([]byte, int, error) {
+	if ct := c.GetHeader("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || !acceptedContentTypes[mediaType] {
+			return nil, http.StatusUnsupportedMediaType, fmt.Errorf("Unsupported content type: %s", ct)
+		}
+	}
+	// 声明的长度已超出上限时不读取
+	if c.Request.ContentLength > maxBytes {
+		return nil, http.StatusRequestEntityTooLarge, errors.New("Request body too large")
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, http.StatusRequestEntityTooLarge, errors.New("Request body too large")
+		}
+		return nil, http.StatusBadRequest, errors.New("Failed to read request body")
+	}
+	return body, http.StatusOK, nil
+}
+
+// isHex 是否全部为十六进制字符
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits 是否全部为数字
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package wework
+
+import (
+	"crypto/aes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// mediaClient 下载图片和文件使用的HTTP客户端
+var mediaClient = &http.Client{Timeout: 30 * time.Second}
+
+// DecryptMedia 解密回调中图片、文件URL下载到的内容
+//
+// 加密方式：AES-256-CBC，密钥为EncodingAESKey解码后的32字节，IV取密钥前16字节，
+// PKCS#7按32字节填充。
+func (w *WXBizJsonMsgCrypt) DecryptMedia(data []byte) ([]byte, error) {
+	plain, err := aesCBCDecrypt(w.Key, data)
+	if err != nil {
+		return nil, fmt.Errorf("解密文件失败: %w", err)
+	}
+	return plain, nil
+}
+
+// DownloadMedia 下载并解密图片、文件（URL 5分钟内有效），超过maxBytes时返回错误
+func (w *WXBizJsonMsgCrypt) DownloadMedia(url string, maxBytes int64) ([]byte, error) {
+	resp, err := mediaClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("下载文件失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载文件失败: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+aes.BlockSize*2+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if int64(len(data)) > maxBytes+aes.BlockSize*2 {
+		return nil, fmt.Errorf("文件超过大小限制（%d字节）", maxBytes)
+	}
+	return w.DecryptMedia(data)
+}
@@ -1,6 +1,9 @@
 package wework
 
 import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -11,7 +14,27 @@ const (
 	MsgTypeText   = "text"   // 文本消息
 	MsgTypeImage  = "image"  // 图片消息
 	MsgTypeMixed  = "mixed"  // 图文混排
+	MsgTypeFile   = "file"   // 文件消息
 	MsgTypeStream = "stream" // 流式消息刷新
+	MsgTypeEvent  = "event"  // 事件（如模板卡片按钮点击）
+)
+
+// 回复消息类型常量
+const (
+	MsgTypeTemplateCard           = "template_card"             // 模板卡片
+	MsgTypeStreamWithTemplateCard = "stream_with_template_card" // 流式消息附带模板卡片
+	ResponseTypeUpdateCard        = "update_template_card"      // 更新已发送的模板卡片（响应卡片事件）
+)
+
+// EventType 事件类型常量
+const (
+	EventTypeTemplateCard = "template_card_event" // 模板卡片按钮点击
+)
+
+// CardType 模板卡片类型常量
+const (
+	CardTypeTextNotice        = "text_notice"        // 文本通知卡片
+	CardTypeButtonInteraction = "button_interaction" // 按钮交互卡片
 )
 
 // ChatType 会话类型常量
@@ -45,6 +68,11 @@ type ImageContent struct {
 	URL string `json:"url"` // 图片下载URL（5分钟有效，加密）
 }
 
+// FileContent 文件内容
+type FileContent struct {
+	URL string `json:"url"` // 文件下载URL（5分钟有效，加密）
+}
+
 // MixedContent 图文混排内容
 type MixedContent struct {
 	MsgItem []MixedItem `json:"msg_item"` // 图文混排项目列表
@@ -62,14 +90,30 @@ type StreamContent struct {
 	ID string `json:"id"` // 流式消息ID
 }
 
+// EventContent 事件内容
+type EventContent struct {
+	EventType         string             `json:"eventtype"`                     // 事件类型
+	TemplateCardEvent *TemplateCardEvent `json:"template_card_event,omitempty"` // 模板卡片事件（eventtype为template_card_event时）
+}
+
+// TemplateCardEvent 模板卡片按钮点击事件
+type TemplateCardEvent struct {
+	CardType string `json:"card_type"` // 卡片类型
+	EventKey string `json:"event_key"` // 被点击按钮的key
+	TaskID   string `json:"task_id"`   // 卡片的task_id
+}
+
 // IncomingMessage 通用接收消息结构
 type IncomingMessage struct {
 	BaseMessage
+	RequestID string `json:"-"` // 回调请求ID（由WebhookHandler生成，用于关联日志）
 	// 各种消息类型的内容（根据MsgType判断使用哪个）
 	Text   *TextContent   `json:"text,omitempty"`
 	Image  *ImageContent  `json:"image,omitempty"`
 	Mixed  *MixedContent  `json:"mixed,omitempty"`
+	File   *FileContent   `json:"file,omitempty"`
 	Stream *StreamContent `json:"stream,omitempty"`
+	Event  *EventContent  `json:"event,omitempty"`
 }
 
 // ParseMessage 解析企业微信消息
@@ -147,7 +191,16 @@ func (m *IncomingMessage) IsGroupChat() bool {
 func (m *IncomingMessage) NeedsReply() bool {
 	// 所有消息类型都需要回复
 	return m.MsgType == MsgTypeText || m.MsgType == MsgTypeImage ||
-		m.MsgType == MsgTypeMixed || m.MsgType == MsgTypeStream
+		m.MsgType == MsgTypeMixed || m.MsgType == MsgTypeFile || m.MsgType == MsgTypeStream ||
+		m.MsgType == MsgTypeEvent
+}
+
+// GetTemplateCardEvent 获取模板卡片按钮点击事件（非卡片事件返回nil）
+func (m *IncomingMessage) GetTemplateCardEvent() *TemplateCardEvent {
+	if m.MsgType != MsgTypeEvent || m.Event == nil || m.Event.EventType != EventTypeTemplateCard {
+		return nil
+	}
+	return m.Event.TemplateCardEvent
 }
 
 // GetConversationKey 获取会话唯一标识
@@ -162,7 +215,8 @@ func (m *IncomingMessage) GetConversationKey() string {
 
 // WeWorkResponse 企业微信回复消息基础结构
 type WeWorkResponse struct {
-	MsgType      string               `json:"msgtype"`                 // 消息类型
+	MsgType      string               `json:"msgtype,omitempty"`       // 消息类型
+	ResponseType string               `json:"response_type,omitempty"` // 响应类型（更新模板卡片时使用）
 	Text         *WeWorkTextContent   `json:"text,omitempty"`          // 文本消息
 	Stream       *WeWorkStreamContent `json:"stream,omitempty"`        // 流式消息
 	TemplateCard *WeWorkTemplateCard  `json:"template_card,omitempty"` // 模板卡片
@@ -193,9 +247,58 @@ type WeWorkStreamImage struct {
 	MD5    string `json:"md5"`    // 图片内容的md5值
 }
 
-// WeWorkTemplateCard 企业微信模板卡片（预留扩展）
+// MaxStreamImages 流式消息最多附带的图片数
+const MaxStreamImages = 10
+
+// MaxStreamImageBytes 流式消息单张图片的大小上限（base64编码前，仅支持JPG、PNG）
+const MaxStreamImageBytes = 10 << 20
+
+// NewStreamImageItem 创建流式消息的图片项（计算base64和md5），只能在finish为true的回复中携带
+func NewStreamImageItem(data []byte) WeWorkStreamMsgItem {
+	sum := md5.Sum(data)
+	return WeWorkStreamMsgItem{
+		MsgType: MsgTypeImage,
+		Image: &WeWorkStreamImage{
+			Base64: base64.StdEncoding.EncodeToString(data),
+			MD5:    hex.EncodeToString(sum[:]),
+		},
+	}
+}
+
+// WeWorkTemplateCard 企业微信模板卡片
 type WeWorkTemplateCard struct {
-	// 模板卡片结构，后续扩展
+	CardType              string               `json:"card_type"`                         // 卡片类型：text_notice|button_interaction
+	MainTitle             *CardMainTitle       `json:"main_title,omitempty"`              // 主标题
+	SubTitleText          string               `json:"sub_title_text,omitempty"`          // 二级文本
+	HorizontalContentList []CardHorizontalItem `json:"horizontal_content_list,omitempty"` // 二级标题+文本列表
+	ButtonList            []CardButton         `json:"button_list,omitempty"`             // 按钮列表（button_interaction）
+	CardAction            *CardAction          `json:"card_action,omitempty"`             // 整体卡片点击跳转（text_notice必填）
+	TaskID                string               `json:"task_id,omitempty"`                 // 任务ID（有按钮时必填，点击事件回传）
+}
+
+// CardMainTitle 模板卡片主标题
+type CardMainTitle struct {
+	Title string `json:"title,omitempty"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+// CardHorizontalItem 模板卡片二级标题+文本
+type CardHorizontalItem struct {
+	KeyName string `json:"keyname"`
+	Value   string `json:"value,omitempty"`
+}
+
+// CardButton 模板卡片按钮
+type CardButton struct {
+	Text  string `json:"text"`
+	Style int    `json:"style,omitempty"` // 按钮样式：1蓝 2灰 3红 4白
+	Key   string `json:"key"`             // 点击事件回传的event_key
+}
+
+// CardAction 模板卡片点击跳转
+type CardAction struct {
+	Type int    `json:"type"`          // 0不跳转 1跳转URL
+	URL  string `json:"url,omitempty"` // 跳转链接（type为1时）
 }
 
 // NewTextResponse 创建文本回复
@@ -220,6 +323,30 @@ func NewStreamResponse(streamID, content string, finish bool) *WeWorkResponse {
 	}
 }
 
+// NewTemplateCardResponse 创建模板卡片回复
+func NewTemplateCardResponse(card *WeWorkTemplateCard) *WeWorkResponse {
+	return &WeWorkResponse{
+		MsgType:      MsgTypeTemplateCard,
+		TemplateCard: card,
+	}
+}
+
+// NewStreamWithCardResponse 创建附带模板卡片的流式回复（卡片展示在流式消息下方）
+func NewStreamWithCardResponse(streamID, content string, finish bool, card *WeWorkTemplateCard) *WeWorkResponse {
+	resp := NewStreamResponse(streamID, content, finish)
+	resp.MsgType = MsgTypeStreamWithTemplateCard
+	resp.TemplateCard = card
+	return resp
+}
+
+// NewUpdateCardResponse 创建更新模板卡片的回复（响应卡片按钮点击事件）
+func NewUpdateCardResponse(card *WeWorkTemplateCard) *WeWorkResponse {
+	return &WeWorkResponse{
+		ResponseType: ResponseTypeUpdateCard,
+		TemplateCard: card,
+	}
+}
+
 // ToJSON 转换为JSON字符串
 func (r *WeWorkResponse) ToJSON() ([]byte, error) {
 	return json.Marshal(r)
@@ -0,0 +1,117 @@
+package wework
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseMessageRequiredFields(t *testing.T) {
+	tests := []struct {
+		name, data string
+	}{
+		{"非JSON", `not json`},
+		{"缺少msgid", `{"msgtype":"text","from":{"userid":"zhangsan"}}`},
+		{"缺少msgtype", `{"msgid":"m1","from":{"userid":"zhangsan"}}`},
+		{"缺少from.userid", `{"msgid":"m1","msgtype":"text"}`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseMessage([]byte(tt.data)); err == nil {
+			t.Errorf("%s: 期望返回错误", tt.name)
+		}
+	}
+
+	msg, err := ParseMessage([]byte(`{"msgid":"m1","chattype":"single","msgtype":"text","from":{"userid":"zhangsan"},"text":{"content":"你好"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.GetTextContent() != "你好" || !msg.NeedsReply() || msg.IsGroupChat() {
+		t.Errorf("文本消息解析结果不符: %+v", msg)
+	}
+}
+
+func TestMixedContent(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{
+		"msgid": "m2", "chattype": "group", "chatid": "wr123", "msgtype": "mixed",
+		"from": {"userid": "lisi"},
+		"mixed": {"msg_item": [
+			{"msgtype": "text", "text": {"content": "看这张图"}},
+			{"msgtype": "image", "image": {"url": "https://example.com/a.jpg"}},
+			{"msgtype": "text", "text": {"content": "是什么错误"}},
+			{"msgtype": "image", "image": {"url": ""}}
+		]}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.GetTextContent(); got != "看这张图 是什么错误" {
+		t.Errorf("GetTextContent() = %q，期望 %q", got, "看这张图 是什么错误")
+	}
+	// 空URL的图片项被忽略
+	if got := msg.GetImageURLs(); !reflect.DeepEqual(got, []string{"https://example.com/a.jpg"}) {
+		t.Errorf("GetImageURLs() = %v", got)
+	}
+}
+
+func TestConversationKey(t *testing.T) {
+	single := &IncomingMessage{BaseMessage: BaseMessage{ChatType: ChatTypeSingle, ChatID: "wr123", From: From{UserID: "zhangsan"}}}
+	if got := single.GetConversationKey(); got != "single_zhangsan" {
+		t.Errorf("单聊 GetConversationKey() = %q，期望 %q", got, "single_zhangsan")
+	}
+	// 群聊按群区分，与发送者无关
+	group := &IncomingMessage{BaseMessage: BaseMessage{ChatType: ChatTypeGroup, ChatID: "wr123", From: From{UserID: "zhangsan"}}}
+	if got := group.GetConversationKey(); got != "group_wr123" {
+		t.Errorf("群聊 GetConversationKey() = %q，期望 %q", got, "group_wr123")
+	}
+}
+
+func TestTemplateCardEvent(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{
+		"msgid": "m3", "msgtype": "event", "from": {"userid": "zhangsan"},
+		"event": {"eventtype": "template_card_event", "template_card_event": {"card_type": "button_interaction", "event_key": "approve", "task_id": "t1"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := msg.GetTemplateCardEvent()
+	if event == nil || event.EventKey != "approve" || event.TaskID != "t1" {
+		t.Fatalf("GetTemplateCardEvent() = %+v", event)
+	}
+
+	msg.Event.EventType = "enter_chat"
+	if msg.GetTemplateCardEvent() != nil {
+		t.Error("非卡片事件 GetTemplateCardEvent() 应返回nil")
+	}
+}
+
+func TestResponseJSON(t *testing.T) {
+	card := &WeWorkTemplateCard{CardType: CardTypeButtonInteraction, TaskID: "t1"}
+	tests := []struct {
+		name string
+		resp *WeWorkResponse
+		want string
+	}{
+		{"文本", NewTextResponse("你好"), `{"msgtype":"text","text":{"content":"你好"}}`},
+		{"流式", NewStreamResponse("s1", "", false), `{"msgtype":"stream","stream":{"id":"s1","finish":false,"content":""}}`},
+		{"流式附带卡片", NewStreamWithCardResponse("s1", "完成", true, card),
+			`{"msgtype":"stream_with_template_card","stream":{"id":"s1","finish":true,"content":"完成"},"template_card":{"card_type":"button_interaction","task_id":"t1"}}`},
+		{"更新卡片", NewUpdateCardResponse(card),
+			`{"response_type":"update_template_card","template_card":{"card_type":"button_interaction","task_id":"t1"}}`},
+	}
+	for _, tt := range tests {
+		data, err := tt.resp.ToJSON()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got, want interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ToJSON() = %s，期望 %s", tt.name, data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package wework
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RequestIDHeader 回调响应中携带请求ID的HTTP头
+const RequestIDHeader = "X-Request-ID"
+
+// NewRequestID 生成简短的请求ID（6位十六进制），便于用户报错时提供给运维人员关联服务端日志
+func NewRequestID() string {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%06x", time.Now().UnixNano()&0xffffff)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,4 @@
+package wework
+
+// Version 当前模块版本（与发布标签 channels/wework/<Version> 保持一致）
+const Version = "v0.14.0"
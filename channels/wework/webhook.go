@@ -2,9 +2,7 @@ package wework
 
 import (
 	"fmt"
-	"io"
 	"net/http"
-	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,12 +18,14 @@ type MessageHandler interface {
 
 // WebhookHandler Webhook处理器
 type WebhookHandler struct {
-	wxcpt      *WXBizJsonMsgCrypt // 我们自己的加解密库
-	botID      string             // 机器人ID
-	handler    MessageHandler
-	msgCache   map[string]time.Time // 消息去重缓存
-	cacheMutex sync.RWMutex         // 缓存锁
-	cacheSize  int                  // 缓存大小限制
+	wxcpt   *WXBizJsonMsgCrypt // 我们自己的加解密库
+	botID   string             // 机器人ID
+	handler MessageHandler
+	dedup   Deduplicator // 消息去重
+
+	maxBodySize int64 // 请求体大小上限（字节）
+
+	onDecryptFailure func(err error) // 验签/解密失败回调（用于监控）
 }
 
 // NewWebhookHandler 创建Webhook处理器
@@ -37,14 +37,39 @@ func NewWebhookHandler(token, aesKey, botID string, handler MessageHandler) (*We
 	}
 
 	return &WebhookHandler{
-		wxcpt:     wxcpt,
-		botID:     botID,
-		handler:   handler,
-		msgCache:  make(map[string]time.Time),
-		cacheSize: 1000, // 缓存1000条消息用于去重
+		wxcpt:   wxcpt,
+		botID:   botID,
+		handler: handler,
+		dedup:   NewMemoryDeduplicator(DefaultDedupSize, DefaultDedupTTL),
+
+		maxBodySize: DefaultMaxBodySize,
 	}, nil
 }
 
+// OnDecryptFailure 设置验签/解密失败回调（URL验证和消息解密均会触发）
+func (w *WebhookHandler) OnDecryptFailure(fn func(err error)) {
+	w.onDecryptFailure = fn
+}
+
+// reportDecryptFailure 触发验签/解密失败回调
+func (w *WebhookHandler) reportDecryptFailure(ret int, err error) {
+	if w.onDecryptFailure == nil {
+		return
+	}
+	if err == nil {
+		err = fmt.Errorf("错误码: %d", ret)
+	}
+	w.onDecryptFailure(err)
+}
+
+// SetMaxBodySize 设置回调请求体大小上限（字节，不大于0时使用DefaultMaxBodySize）
+func (w *WebhookHandler) SetMaxBodySize(n int64) {
+	if n <= 0 {
+		n = DefaultMaxBodySize
+	}
+	w.maxBodySize = n
+}
+
 // HandleWebhook 处理Webhook请求
 func (w *WebhookHandler) HandleWebhook(c *gin.Context) {
 	switch c.Request.Method {
@@ -60,23 +85,23 @@ func (w *WebhookHandler) HandleWebhook(c *gin.Context) {
 // handleVerification 处理URL验证（GET请求）
 func (w *WebhookHandler) handleVerification(c *gin.Context) {
 	// 获取查询参数（Gin已自动URL解码）
-	signature := c.Query("msg_signature")
-	timestamp := c.Query("timestamp")
-	nonce := c.Query("nonce")
+	params, err := parseCallbackParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	echostr := c.Query("echostr")
-
-	if signature == "" || timestamp == "" || nonce == "" || echostr == "" {
+	if echostr == "" {
 		// URL验证失败: 缺少必要参数
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required parameters"})
 		return
 	}
 
-	// URL验证请求
-
 	// 使用我们自己的加解密库进行验证（严格按照Python逻辑）
-	ret, echoStr, err := w.wxcpt.VerifyURL(signature, timestamp, nonce, echostr)
+	ret, echoStr, err := w.wxcpt.VerifyURL(params.signature, params.timestamp, params.nonce, echostr)
 	if ret != WXBizMsgCrypt_OK || err != nil {
 		// URL验证失败
+		w.reportDecryptFailure(ret, err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Verification failed"})
 		return
 	}
@@ -87,21 +112,22 @@ func (w *WebhookHandler) handleVerification(c *gin.Context) {
 
 // handleMessage 处理消息（POST请求）
 func (w *WebhookHandler) handleMessage(c *gin.Context) {
-	signature := c.Query("msg_signature")
-	timestamp := c.Query("timestamp")
-	nonce := c.Query("nonce")
+	// 请求ID随响应头返回，并传给消息处理器用于关联日志
+	requestID := NewRequestID()
+	c.Header(RequestIDHeader, requestID)
 
-	if signature == "" || timestamp == "" || nonce == "" {
-		// 消息处理失败: 缺少必要参数
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required parameters"})
+	// 先校验签名参数和请求体大小，再做解密
+	params, err := parseCallbackParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	signature, timestamp, nonce := params.signature, params.timestamp, params.nonce
 
-	// 读取请求体
-	body, err := io.ReadAll(c.Request.Body)
+	// 读取请求体（超出上限返回413）
+	body, status, err := readCallbackBody(c, w.maxBodySize)
 	if err != nil {
-		// 读取请求体失败
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -109,6 +135,7 @@ func (w *WebhookHandler) handleMessage(c *gin.Context) {
 	// 直接传递原始JSON格式给解密函数
 	ret, decryptedContent, err := w.wxcpt.DecryptMsg(string(body), signature, timestamp, nonce)
 	if ret != WXBizMsgCrypt_OK || err != nil {
+		w.reportDecryptFailure(ret, err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Decryption failed"})
 		return
 	}
@@ -122,16 +149,14 @@ func (w *WebhookHandler) handleMessage(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message format"})
 		return
 	}
+	msg.RequestID = requestID
 
-	// 消息去重检查
-	if w.isDuplicateMessage(msg.MsgID) {
+	// 消息去重检查（同时记录消息）
+	if w.dedup.Seen(msg.MsgID) {
 		c.String(http.StatusOK, "success") // 企业微信期望返回success
 		return
 	}
 
-	// 记录消息用于去重
-	w.recordMessage(msg.MsgID)
-
 	// 处理消息
 	var response *WeWorkResponse
 	if msg.MsgType == MsgTypeStream {
@@ -186,37 +211,18 @@ func (w *WebhookHandler) sendEncryptedResponse(c *gin.Context, response *WeWorkR
 	c.String(http.StatusOK, encryptedResp)
 }
 
-// isDuplicateMessage 检查是否为重复消息
-func (w *WebhookHandler) isDuplicateMessage(msgID string) bool {
-	w.cacheMutex.RLock()
-	_, exists := w.msgCache[msgID]
-	w.cacheMutex.RUnlock()
-	return exists
-}
-
-// recordMessage 记录消息用于去重
-func (w *WebhookHandler) recordMessage(msgID string) {
-	w.cacheMutex.Lock()
-	defer w.cacheMutex.Unlock()
-
-	// 清理过期消息（保留最近1小时的消息）
-	now := time.Now()
-	if len(w.msgCache) > w.cacheSize {
-		cutoff := now.Add(-time.Hour)
-		for id, timestamp := range w.msgCache {
-			if timestamp.Before(cutoff) {
-				delete(w.msgCache, id)
-			}
-		}
-	}
-
-	// 记录新消息
-	w.msgCache[msgID] = now
+// SetDeduplicator 替换消息去重器（如多副本部署时使用共享存储），需在开始处理请求前调用
+func (w *WebhookHandler) SetDeduplicator(d Deduplicator) {
+	w.dedup = d
 }
 
 // HealthCheck 健康检查处理器
 func (w *WebhookHandler) HealthCheck(c *gin.Context) {
 	activeTasks := 0
+	cacheSize := 0
+	if sized, ok := w.dedup.(interface{ Len() int }); ok {
+		cacheSize = sized.Len()
+	}
 	if taskManager, ok := w.handler.(interface{ GetActiveStreamCount() int }); ok {
 		activeTasks = taskManager.GetActiveStreamCount()
 	}
@@ -226,7 +232,7 @@ func (w *WebhookHandler) HealthCheck(c *gin.Context) {
 		"service":      "AI-Body 企业微信智能机器人（Python流式模式）",
 		"version":      "1.0.0",
 		"timestamp":    time.Now().Unix(),
-		"cache_size":   len(w.msgCache),
+		"cache_size":   cacheSize,
 		"active_tasks": activeTasks,
 		"features":     []string{"encryption", "deduplication", "mcp_tools", "task_cache", "python_stream_mode"},
 	})
@@ -0,0 +1,224 @@
+package wework
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testTimestamp = "1700000000"
+	testNonce     = "nonce123"
+)
+
+// fakeMessageHandler 记录收到的消息，文本消息回复流式消息，刷新回复完成的流式消息
+type fakeMessageHandler struct {
+	mutex     sync.Mutex
+	messages  []*IncomingMessage
+	refreshes []string
+}
+
+func (h *fakeMessageHandler) HandleMessage(msg *IncomingMessage) (*WeWorkResponse, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.messages = append(h.messages, msg)
+	return NewStreamResponse("s-"+msg.MsgID, "", false), nil
+}
+
+func (h *fakeMessageHandler) HandleStreamRefresh(streamID string) (*WeWorkResponse, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.refreshes = append(h.refreshes, streamID)
+	return NewStreamResponse(streamID, "完成", true), nil
+}
+
+// newTestWebhook 创建使用官方示例Token/EncodingAESKey的Webhook及对应的加解密实例（智能机器人receiveID为空）
+func newTestWebhook(t *testing.T) (*gin.Engine, *WebhookHandler, *fakeMessageHandler, *WXBizJsonMsgCrypt) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	handler := &fakeMessageHandler{}
+	webhook, err := NewWebhookHandler(sampleToken, sampleAESKey, "bot", handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.Any("/callback", webhook.HandleWebhook)
+	return router, webhook, handler, newTestCrypt(t, "", MsgFormatJSON)
+}
+
+// callbackQuery 回调查询参数
+func callbackQuery(signature string) string {
+	return url.Values{
+		"msg_signature": {signature},
+		"timestamp":     {testTimestamp},
+		"nonce":         {testNonce},
+	}.Encode()
+}
+
+// postMessage 加密消息并以企业微信回调的方式发送
+func postMessage(t *testing.T, router *gin.Engine, crypt *WXBizJsonMsgCrypt, plain string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	ts := testTimestamp
+	ret, body, err := crypt.EncryptMsg(plain, testNonce, &ts)
+	if ret != WXBizMsgCrypt_OK {
+		t.Fatalf("加密失败: %d %v", ret, err)
+	}
+	var envelope struct {
+		Signature string `json:"msgsignature"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/callback?"+callbackQuery(envelope.Signature), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decryptResponse 解密回调响应中的回复消息
+func decryptResponse(t *testing.T, crypt *WXBizJsonMsgCrypt, w *httptest.ResponseRecorder) *WeWorkResponse {
+	t.Helper()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d，期望 200: %s", w.Code, w.Body.String())
+	}
+	var envelope struct {
+		Signature string `json:"msgsignature"`
+		Timestamp string `json:"timestamp"`
+		Nonce     string `json:"nonce"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("响应不是加密信封: %s", w.Body.String())
+	}
+	ret, plain, err := crypt.DecryptMsg(w.Body.String(), envelope.Signature, envelope.Timestamp, envelope.Nonce)
+	if ret != WXBizMsgCrypt_OK {
+		t.Fatalf("解密响应失败: %d %v", ret, err)
+	}
+	var resp WeWorkResponse
+	if err := json.Unmarshal([]byte(plain), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return &resp
+}
+
+func TestWebhookMessageRoundTrip(t *testing.T) {
+	router, _, handler, crypt := newTestWebhook(t)
+
+	w := postMessage(t, router, crypt, `{"msgid":"m1","chattype":"single","msgtype":"text","from":{"userid":"zhangsan"},"text":{"content":"你好"}}`)
+	resp := decryptResponse(t, crypt, w)
+	if resp.Stream == nil || resp.Stream.ID != "s-m1" || resp.Stream.Finish {
+		t.Errorf("回复 = %+v，期望未完成的流式消息 s-m1", resp.Stream)
+	}
+	if len(handler.messages) != 1 || handler.messages[0].GetTextContent() != "你好" {
+		t.Fatalf("处理器收到的消息不符: %+v", handler.messages)
+	}
+	// 请求ID同时出现在响应头和消息中
+	if id := w.Header().Get(RequestIDHeader); id == "" || handler.messages[0].RequestID != id {
+		t.Errorf("请求ID = %q，消息中为 %q", id, handler.messages[0].RequestID)
+	}
+
+	// 流式刷新交给 HandleStreamRefresh
+	w = postMessage(t, router, crypt, `{"msgid":"m2","chattype":"single","msgtype":"stream","from":{"userid":"zhangsan"},"stream":{"id":"s-m1"}}`)
+	resp = decryptResponse(t, crypt, w)
+	if resp.Stream == nil || resp.Stream.Content != "完成" || !resp.Stream.Finish {
+		t.Errorf("刷新回复 = %+v，期望已完成的流式消息", resp.Stream)
+	}
+	if len(handler.refreshes) != 1 || handler.refreshes[0] != "s-m1" || len(handler.messages) != 1 {
+		t.Errorf("刷新 = %v，消息数 = %d，期望只刷新 s-m1", handler.refreshes, len(handler.messages))
+	}
+}
+
+func TestWebhookDuplicateMessage(t *testing.T) {
+	router, _, handler, crypt := newTestWebhook(t)
+
+	plain := `{"msgid":"dup","chattype":"single","msgtype":"text","from":{"userid":"zhangsan"},"text":{"content":"你好"}}`
+	decryptResponse(t, crypt, postMessage(t, router, crypt, plain))
+
+	// 企业微信重试投递同一消息时直接返回success，不再交给处理器
+	w := postMessage(t, router, crypt, plain)
+	if w.Code != http.StatusOK || w.Body.String() != "success" {
+		t.Errorf("重复消息响应 = %d %q，期望 200 \"success\"", w.Code, w.Body.String())
+	}
+	if len(handler.messages) != 1 {
+		t.Errorf("处理器收到 %d 条消息，期望 1", len(handler.messages))
+	}
+}
+
+func TestWebhookRejectsBadRequests(t *testing.T) {
+	router, webhook, handler, crypt := newTestWebhook(t)
+	var failures []error
+	webhook.OnDecryptFailure(func(err error) { failures = append(failures, err) })
+	webhook.SetMaxBodySize(1024)
+
+	ts := testTimestamp
+	_, body, _ := crypt.EncryptMsg(`{"msgid":"m1","msgtype":"text","from":{"userid":"zhangsan"}}`, testNonce, &ts)
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"缺少签名参数", "timestamp=" + testTimestamp, "application/json", body, http.StatusBadRequest},
+		{"签名格式错误", callbackQuery("not-a-signature"), "application/json", body, http.StatusBadRequest},
+		{"签名不匹配", callbackQuery(strings.Repeat("0", 40)), "application/json", body, http.StatusUnauthorized},
+		{"不支持的Content-Type", callbackQuery(strings.Repeat("0", 40)), "multipart/form-data", body, http.StatusUnsupportedMediaType},
+		{"请求体过大", callbackQuery(strings.Repeat("0", 40)), "application/json", strings.Repeat("x", 2048), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/callback?"+tt.query, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: 状态码 = %d，期望 %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	// 只有签名不匹配触发解密失败回调，处理器没有收到任何消息
+	if len(failures) != 1 {
+		t.Errorf("解密失败回调触发 %d 次，期望 1", len(failures))
+	}
+	if len(handler.messages) != 0 {
+		t.Errorf("处理器收到 %d 条消息，期望 0", len(handler.messages))
+	}
+}
+
+func TestWebhookVerifyURL(t *testing.T) {
+	router, _, _, crypt := newTestWebhook(t)
+
+	ts := testTimestamp
+	_, body, _ := crypt.EncryptMsg("1616140317555161061", testNonce, &ts)
+	var envelope struct {
+		Encrypt   string `json:"encrypt"`
+		Signature string `json:"msgsignature"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		t.Fatal(err)
+	}
+
+	query := callbackQuery(envelope.Signature) + "&" + url.Values{"echostr": {envelope.Encrypt}}.Encode()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?"+query, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "1616140317555161061" {
+		t.Errorf("URL验证响应 = %d %q，期望返回解密后的echostr", w.Code, w.Body.String())
+	}
+
+	// 签名与echostr不匹配时拒绝
+	query = callbackQuery(strings.Repeat("0", 40)) + "&" + url.Values{"echostr": {envelope.Encrypt}}.Encode()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?"+query, nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("签名不匹配时状态码 = %d，期望 401", w.Code)
+	}
+}
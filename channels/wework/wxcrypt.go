@@ -0,0 +1,480 @@
+package wework
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 错误码定义（对应Python的ierror）
+const (
+	WXBizMsgCrypt_OK                      = 0
+	WXBizMsgCrypt_ValidateSignature_Error = -40001
+	WXBizMsgCrypt_ParseJson_Error         = -40002
+	WXBizMsgCrypt_ComputeSignature_Error  = -40003
+	WXBizMsgCrypt_IllegalAesKey           = -40004
+	WXBizMsgCrypt_ValidateCorpid_Error    = -40005
+	WXBizMsgCrypt_EncryptAES_Error        = -40006
+	WXBizMsgCrypt_DecryptAES_Error        = -40007
+	WXBizMsgCrypt_IllegalBuffer           = -40008
+	WXBizMsgCrypt_EncodeBase64_Error      = -40009
+	WXBizMsgCrypt_DecodeBase64_Error      = -40010
+
+	// WXBizMsgCrypt_ParseXml_Error XML格式回调解析失败（与JSON格式共用错误码）
+	WXBizMsgCrypt_ParseXml_Error = WXBizMsgCrypt_ParseJson_Error
+)
+
+// MsgFormat 回调消息外层格式：签名和加密方式相同，只有包裹密文的格式不同
+type MsgFormat int
+
+const (
+	MsgFormatJSON MsgFormat = iota // 智能机器人
+	MsgFormatXML                   // 自建应用、微信客服
+)
+
+// PKCS7Encoder PKCS7填充算法实现
+type PKCS7Encoder struct {
+	BlockSize int
+}
+
+// NewPKCS7Encoder 创建PKCS7编码器
+func NewPKCS7Encoder() *PKCS7Encoder {
+	return &PKCS7Encoder{BlockSize: 32} // 对应Python的block_size = 32
+}
+
+// Encode 对明文进行PKCS7填充（返回新的切片，不修改text）
+func (p *PKCS7Encoder) Encode(text []byte) []byte {
+	amountToPad := p.BlockSize - (len(text) % p.BlockSize) // 恰好整块时补一整块
+
+	padded := make([]byte, len(text), len(text)+amountToPad)
+	copy(padded, text)
+	for i := 0; i < amountToPad; i++ {
+		padded = append(padded, byte(amountToPad))
+	}
+	return padded
+}
+
+// Decode 移除PKCS7填充，填充无效时原样返回
+//
+// Deprecated: 无法区分填充无效和没有填充，使用 Unpad。
+func (p *PKCS7Encoder) Decode(text []byte) []byte {
+	unpadded, err := p.Unpad(text)
+	if err != nil {
+		return text
+	}
+	return unpadded
+}
+
+// Unpad 校验并移除PKCS7填充（密钥错误或密文损坏时填充无效）
+func (p *PKCS7Encoder) Unpad(text []byte) ([]byte, error) {
+	if len(text) == 0 {
+		return nil, fmt.Errorf("PKCS7填充无效: 数据为空")
+	}
+	pad := int(text[len(text)-1])
+	if pad < 1 || pad > p.BlockSize {
+		return nil, fmt.Errorf("PKCS7填充无效: 填充长度%d超出范围1~%d", pad, p.BlockSize)
+	}
+	if pad > len(text) {
+		return nil, fmt.Errorf("PKCS7填充无效: 填充长度%d超过数据长度%d", pad, len(text))
+	}
+	for _, b := range text[len(text)-pad:] {
+		if int(b) != pad {
+			return nil, fmt.Errorf("PKCS7填充无效: 填充字节不一致")
+		}
+	}
+	return text[:len(text)-pad], nil
+}
+
+// aesCBCEncrypt AES-256-CBC加密（IV取密钥前16字节，明文需已填充）
+func aesCBCEncrypt(key, plain []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, key[:aes.BlockSize]).CryptBlocks(ciphertext, plain)
+	return ciphertext, nil
+}
+
+// aesCBCDecrypt AES-256-CBC解密并校验移除PKCS7填充（IV取密钥前16字节）
+func aesCBCDecrypt(key, data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("密文长度%d不是AES块大小的整数倍", len(data))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, key[:aes.BlockSize]).CryptBlocks(plain, data)
+	return NewPKCS7Encoder().Unpad(plain)
+}
+
+// Prpcrypt AES加解密实现
+type Prpcrypt struct {
+	Key  []byte
+	Mode cipher.BlockMode
+}
+
+// NewPrpcrypt 创建加解密器
+func NewPrpcrypt(key []byte) *Prpcrypt {
+	return &Prpcrypt{Key: key}
+}
+
+// getRandomStr 生成16位随机字符串（对应Python的get_random_str）
+func (p *Prpcrypt) getRandomStr() ([]byte, error) {
+	// Python: return str(random.randint(1000000000000000, 9999999999999999)).encode()
+	min := big.NewInt(1000000000000000)
+	max := big.NewInt(9999999999999999)
+
+	n, err := rand.Int(rand.Reader, new(big.Int).Sub(max, min))
+	if err != nil {
+		return nil, err
+	}
+
+	n.Add(n, min)
+	return []byte(n.String()), nil
+}
+
+// Encrypt 加密消息（对应Python的encrypt方法）
+func (p *Prpcrypt) Encrypt(text, receiveID string) (int, []byte, error) {
+	// 1. 生成16位随机字符串
+	randomStr, err := p.getRandomStr()
+	if err != nil {
+		return WXBizMsgCrypt_EncryptAES_Error, nil, err
+	}
+
+	// 2. 构造消息格式：16位随机字符串 + 4字节长度 + 消息内容 + receiveid
+	textBytes := []byte(text)
+	receiveIDBytes := []byte(receiveID)
+	if uint64(len(textBytes)) > math.MaxUint32 {
+		return WXBizMsgCrypt_EncryptAES_Error, nil, fmt.Errorf("消息长度%d超出4字节长度字段的范围", len(textBytes))
+	}
+
+	// 4字节长度（大端序）
+	lengthBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBytes, uint32(len(textBytes)))
+
+	// 组合消息
+	message := make([]byte, 0, len(randomStr)+4+len(textBytes)+len(receiveIDBytes))
+	message = append(message, randomStr...)
+	message = append(message, lengthBytes...)
+	message = append(message, textBytes...)
+	message = append(message, receiveIDBytes...)
+
+	// 3. PKCS7填充后AES-CBC加密（IV使用密钥前16位）
+	ciphertext, err := aesCBCEncrypt(p.Key, NewPKCS7Encoder().Encode(message))
+	if err != nil {
+		return WXBizMsgCrypt_EncryptAES_Error, nil, err
+	}
+
+	// 4. Base64编码
+	encoded := base64.StdEncoding.EncodeToString(ciphertext)
+
+	return WXBizMsgCrypt_OK, []byte(encoded), nil
+}
+
+// Decrypt 解密消息并校验receiveID（对应Python的decrypt方法）
+func (p *Prpcrypt) Decrypt(encryptedText, receiveID string) (int, string, error) {
+	ret, content, fromReceiveID, err := p.Open(encryptedText)
+	if ret != WXBizMsgCrypt_OK {
+		return ret, "", err
+	}
+	if fromReceiveID != receiveID {
+		// receiveID不匹配
+		return WXBizMsgCrypt_ValidateCorpid_Error, "", fmt.Errorf("receiveID验证失败")
+	}
+	return WXBizMsgCrypt_OK, content, nil
+}
+
+// Open 解密消息，返回消息内容和密文中携带的receiveID（不校验receiveID）
+func (p *Prpcrypt) Open(encryptedText string) (int, string, string, error) {
+	// 1. Base64解码
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptedText)
+	if err != nil {
+		return WXBizMsgCrypt_DecodeBase64_Error, "", "", err
+	}
+
+	// 2. AES-CBC解密并移除PKCS7填充（IV使用密钥前16位）
+	unpaddedText, err := aesCBCDecrypt(p.Key, ciphertext)
+	if err != nil {
+		return WXBizMsgCrypt_DecryptAES_Error, "", "", err
+	}
+
+	// 3. 解析消息格式：16字节随机字符串 + 4字节内容长度 + 内容 + receiveID
+	const headerLen = 16 + 4
+	if len(unpaddedText) < headerLen {
+		return WXBizMsgCrypt_IllegalBuffer, "", "", fmt.Errorf("解密后数据长度%d不足%d字节", len(unpaddedText), headerLen)
+	}
+	msgLen := uint64(binary.BigEndian.Uint32(unpaddedText[16:headerLen]))
+	content := unpaddedText[headerLen:]
+
+	// 长度字段来自密文，按无符号数与剩余长度比较，避免截断或溢出后越界
+	if msgLen > uint64(len(content)) {
+		return WXBizMsgCrypt_IllegalBuffer, "", "", fmt.Errorf("消息长度%d超过剩余数据长度%d", msgLen, len(content))
+	}
+
+	// 提取消息内容和receiveID
+	jsonContent := string(content[:msgLen])
+	fromReceiveID := string(content[msgLen:])
+
+	return WXBizMsgCrypt_OK, jsonContent, fromReceiveID, nil
+}
+
+// SHA1Helper SHA1签名计算辅助类
+type SHA1Helper struct{}
+
+// GetSHA1 计算SHA1签名（对应Python的getSHA1）
+func (s *SHA1Helper) GetSHA1(token, timestamp, nonce, encrypt string) (int, string, error) {
+	// 对应Python的sortlist = [str(token), str(timestamp), str(nonce), str(encrypt)]
+	sortList := []string{token, timestamp, nonce, encrypt}
+	sort.Strings(sortList) // 排序
+
+	// 连接字符串并计算SHA1
+	combined := strings.Join(sortList, "")
+	h := sha1.New()
+	h.Write([]byte(combined))
+	hash := fmt.Sprintf("%x", h.Sum(nil))
+
+	return WXBizMsgCrypt_OK, hash, nil
+}
+
+// signatureEqual 比较签名（常量时间，避免通过响应耗时逐字节猜出签名）
+func signatureEqual(signature, msgSignature string) bool {
+	return subtle.ConstantTimeCompare([]byte(signature), []byte(msgSignature)) == 1
+}
+
+// JsonHelper JSON消息解析和生成辅助类
+type JsonHelper struct{}
+
+// ResponseTemplate JSON响应模板
+const ResponseTemplate = `{
+    "encrypt": "%s",
+    "msgsignature": "%s", 
+    "timestamp": "%s",
+    "nonce": "%s"
+}`
+
+// Extract 从JSON中提取加密消息（对应Python的extract）
+func (j *JsonHelper) Extract(jsonText string) (int, string, error) {
+	var jsonDict map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonText), &jsonDict); err != nil {
+		return WXBizMsgCrypt_ParseJson_Error, "", err
+	}
+
+	encrypt, ok := jsonDict["encrypt"].(string)
+	if !ok {
+		return WXBizMsgCrypt_ParseJson_Error, "", fmt.Errorf("encrypt字段不存在或类型错误")
+	}
+
+	return WXBizMsgCrypt_OK, encrypt, nil
+}
+
+// Generate 生成JSON响应（对应Python的generate）
+func (j *JsonHelper) Generate(encrypt, signature, timestamp, nonce string) string {
+	return fmt.Sprintf(ResponseTemplate, encrypt, signature, timestamp, nonce)
+}
+
+// XMLHelper XML回调消息解析和生成辅助类（自建应用、微信客服等使用XML格式回调）
+type XMLHelper struct{}
+
+// XMLResponseTemplate XML响应模板
+const XMLResponseTemplate = `<xml>
+<Encrypt><![CDATA[%s]]></Encrypt>
+<MsgSignature><![CDATA[%s]]></MsgSignature>
+<TimeStamp>%s</TimeStamp>
+<Nonce><![CDATA[%s]]></Nonce>
+</xml>`
+
+// Extract 从XML中提取加密消息
+func (x *XMLHelper) Extract(xmlText string) (int, string, error) {
+	var envelope struct {
+		Encrypt string `xml:"Encrypt"`
+	}
+	if err := xml.Unmarshal([]byte(xmlText), &envelope); err != nil {
+		return WXBizMsgCrypt_ParseXml_Error, "", err
+	}
+	if envelope.Encrypt == "" {
+		return WXBizMsgCrypt_ParseXml_Error, "", fmt.Errorf("Encrypt字段不存在")
+	}
+	return WXBizMsgCrypt_OK, envelope.Encrypt, nil
+}
+
+// Generate 生成XML响应
+func (x *XMLHelper) Generate(encrypt, signature, timestamp, nonce string) string {
+	return fmt.Sprintf(XMLResponseTemplate, encrypt, signature, timestamp, nonce)
+}
+
+// envelopeHelper 回调消息外层格式的解析和生成
+type envelopeHelper interface {
+	Extract(text string) (int, string, error)
+	Generate(encrypt, signature, timestamp, nonce string) string
+}
+
+// ReceiveIDMode 解密时receiveID的校验方式
+type ReceiveIDMode int
+
+const (
+	// ReceiveIDStrict 密文中的receiveID必须与ReceiveID一致（默认）：
+	// 自建应用、微信客服为企业ID，第三方应用为suite_id，智能机器人为空字符串
+	ReceiveIDStrict ReceiveIDMode = iota
+	// ReceiveIDLenient 不校验receiveID，只依赖签名校验（receiveID不固定或未知时使用）
+	ReceiveIDLenient
+)
+
+// WXBizJsonMsgCrypt 企业微信消息加解密主类（对应Python的WXBizJsonMsgCrypt）
+//
+// 名称沿用JSON版本，Format为MsgFormatXML时按XML格式解析回调和生成响应。
+type WXBizJsonMsgCrypt struct {
+	Token         string
+	Key           []byte
+	ReceiveID     string // 加密时写入密文，解密时按ReceiveIDMode校验
+	Format        MsgFormat
+	ReceiveIDMode ReceiveIDMode
+}
+
+// NewWXBizJsonMsgCrypt 创建JSON格式（智能机器人）的加解密实例
+func NewWXBizJsonMsgCrypt(token, encodingAESKey, receiveID string) (*WXBizJsonMsgCrypt, error) {
+	return NewWXBizMsgCrypt(token, encodingAESKey, receiveID, MsgFormatJSON)
+}
+
+// NewWXBizMsgCrypt 创建指定回调格式的加解密实例
+func NewWXBizMsgCrypt(token, encodingAESKey, receiveID string, format MsgFormat) (*WXBizJsonMsgCrypt, error) {
+	// 对应Python的：self.key = base64.b64decode(sEncodingAESKey+"=")
+	key, err := base64.StdEncoding.DecodeString(encodingAESKey + "=")
+	if err != nil {
+		return nil, fmt.Errorf("EncodingAESKey解码失败: %v", err)
+	}
+
+	if len(key) != 32 {
+		return nil, fmt.Errorf("EncodingAESKey长度必须为32字节，实际为%d字节", len(key))
+	}
+
+	return &WXBizJsonMsgCrypt{
+		Token:     token,
+		Key:       key,
+		ReceiveID: receiveID,
+		Format:    format,
+	}, nil
+}
+
+// SetReceiveIDMode 设置解密时receiveID的校验方式
+func (w *WXBizJsonMsgCrypt) SetReceiveIDMode(mode ReceiveIDMode) {
+	w.ReceiveIDMode = mode
+}
+
+// decrypt 解密并按ReceiveIDMode校验receiveID
+func (w *WXBizJsonMsgCrypt) decrypt(encrypt string) (int, string, error) {
+	pc := NewPrpcrypt(w.Key)
+	if w.ReceiveIDMode == ReceiveIDLenient {
+		ret, content, _, err := pc.Open(encrypt)
+		return ret, content, err
+	}
+	return pc.Decrypt(encrypt, w.ReceiveID)
+}
+
+// envelope 当前回调格式的解析和生成辅助类
+func (w *WXBizJsonMsgCrypt) envelope() envelopeHelper {
+	if w.Format == MsgFormatXML {
+		return &XMLHelper{}
+	}
+	return &JsonHelper{}
+}
+
+// verifySignature 计算签名并与回调中的签名比较
+func (w *WXBizJsonMsgCrypt) verifySignature(msgSignature, timestamp, nonce, encrypt string) (int, error) {
+	sha1Helper := &SHA1Helper{}
+	ret, signature, err := sha1Helper.GetSHA1(w.Token, timestamp, nonce, encrypt)
+	if ret != WXBizMsgCrypt_OK {
+		return ret, err
+	}
+	if !signatureEqual(signature, msgSignature) {
+		return WXBizMsgCrypt_ValidateSignature_Error, fmt.Errorf("签名验证失败")
+	}
+	return WXBizMsgCrypt_OK, nil
+}
+
+// VerifyURL URL验证（对应Python的VerifyURL）
+func (w *WXBizJsonMsgCrypt) VerifyURL(msgSignature, timestamp, nonce, echoStr string) (int, string, error) {
+	// 1. 验证签名
+	if ret, err := w.verifySignature(msgSignature, timestamp, nonce, echoStr); ret != WXBizMsgCrypt_OK {
+		return ret, "", err
+	}
+
+	// 2. 解密echoStr
+	return w.decrypt(echoStr)
+}
+
+// EncryptMsg 加密消息（对应Python的EncryptMsg）
+func (w *WXBizJsonMsgCrypt) EncryptMsg(replyMsg, nonce string, timestamp *string) (int, string, error) {
+	// 1. 如果timestamp为空，使用当前时间
+	var ts string
+	if timestamp == nil {
+		ts = strconv.FormatInt(time.Now().Unix(), 10)
+	} else {
+		ts = *timestamp
+	}
+
+	// 2. 加密消息
+	pc := NewPrpcrypt(w.Key)
+	ret, encryptBytes, err := pc.Encrypt(replyMsg, w.ReceiveID)
+	if ret != WXBizMsgCrypt_OK {
+		return ret, "", err
+	}
+
+	encrypt := string(encryptBytes)
+
+	// 3. 生成签名
+	sha1Helper := &SHA1Helper{}
+	ret, signature, err := sha1Helper.GetSHA1(w.Token, ts, nonce, encrypt)
+	if ret != WXBizMsgCrypt_OK {
+		return ret, "", err
+	}
+
+	// 4. 按回调格式生成响应
+	response := w.envelope().Generate(encrypt, signature, ts, nonce)
+
+	return WXBizMsgCrypt_OK, response, nil
+}
+
+// DecryptMsg 解密消息（对应Python的DecryptMsg），按Format解析回调格式
+func (w *WXBizJsonMsgCrypt) DecryptMsg(postData, msgSignature, timestamp, nonce string) (int, string, error) {
+	return w.decryptMsg(w.envelope(), postData, msgSignature, timestamp, nonce)
+}
+
+// DecryptXMLMsg 解密XML格式的回调消息（签名和加密方式与JSON格式相同，receiveID为企业ID）
+//
+// Deprecated: 使用 NewWXBizMsgCrypt(..., MsgFormatXML) 创建实例后调用 DecryptMsg。
+func (w *WXBizJsonMsgCrypt) DecryptXMLMsg(postData, msgSignature, timestamp, nonce string) (int, string, error) {
+	return w.decryptMsg(&XMLHelper{}, postData, msgSignature, timestamp, nonce)
+}
+
+// decryptMsg 提取密文、验证签名后解密
+func (w *WXBizJsonMsgCrypt) decryptMsg(helper envelopeHelper, postData, msgSignature, timestamp, nonce string) (int, string, error) {
+	// 1. 提取加密数据
+	ret, encrypt, err := helper.Extract(postData)
+	if ret != WXBizMsgCrypt_OK {
+		return ret, "", err
+	}
+
+	// 2. 验证签名
+	if ret, err := w.verifySignature(msgSignature, timestamp, nonce, encrypt); ret != WXBizMsgCrypt_OK {
+		return ret, "", err
+	}
+
+	// 3. 解密消息
+	return w.decrypt(encrypt)
+}
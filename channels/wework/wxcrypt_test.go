@@ -0,0 +1,364 @@
+package wework
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// 企业微信官方加解密示例（WXBizMsgCrypt sample）中的参数和数据
+const (
+	sampleToken     = "QDG6eK"
+	sampleAESKey    = "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C"
+	sampleReceiveID = "wx5823bf96d3bd56c7"
+
+	sampleVerifySignature = "5c45ff5e21c57e6ad56bac8758b79b1d9ac89fd3"
+	sampleVerifyTimestamp = "1409659589"
+	sampleVerifyNonce     = "263014780"
+	sampleVerifyEchoStr   = "P9nAzCzyDtyTWESHep1vC5X9xho/qYX3Zpb4yKa9SKld1DsH3Iyt3tP3zNdtp+4RPcs8TgAE7OaBO+FZXvnaqQ=="
+	sampleVerifyPlain     = "1616140317555161061"
+
+	sampleMsgSignature = "477715d11cdb4164915debcba66cb864d751f3e6"
+	sampleMsgTimestamp = "1409659813"
+	sampleMsgNonce     = "1372623149"
+	sampleMsgEncrypt   = "RypEvHKD8QQKFhvQ6QleEB4J58tiPdvo+rtK1I9qca6aM/wvqnLSV5zEPeusUiX5L5X/0lWfrf0QADHHhGd3QczcdCUpj911L3vg3W/sYYvuJTs3TUUkSUXxaccAS0qhxchrRYt66wiSpGLYL42aM6A8dTT+6k4aSknmPj48kzJs8qLjvd4Xgpue06DOdnLxAUHzM6+kDZ+HMZfJYuR+LtwGc2hgf5gsijff0ekUNXZiqATP7PF5mZxZ3Izoun1s4zG4LUMnvw2r+KqCKIw+3IQH03v+BCA9nMELNqbSf6tiWSrXJB3LAVGUcallcrw8V2t9EL4EhzJWrQUax5wLVMNS0+rUPA3k22Ncx4XXZS9o0MBH27Bo6BpNelZpS+/uh9KsNlY6bHCmJU9p8g7m3fVKn28H3KDYA5Pl/T8Z1ptDAVe0lXdQ2YoyyH2uyPIGHBZZIs2pDBS8R07+qN+E7Q=="
+	sampleMsgPlain     = "<xml><ToUserName><![CDATA[wx5823bf96d3bd56c7]]></ToUserName>\n<FromUserName><![CDATA[mycreate]]></FromUserName>\n<CreateTime>1409659813</CreateTime>\n<MsgType><![CDATA[text]]></MsgType>\n<Content><![CDATA[hello]]></Content>\n<MsgId>4561255354251345929</MsgId>\n<AgentID>218</AgentID>\n</xml>"
+)
+
+// sampleMsgBody 官方示例中的XML回调请求体
+var sampleMsgBody = "<xml><ToUserName><![CDATA[wx5823bf96d3bd56c7]]></ToUserName><Encrypt><![CDATA[" + sampleMsgEncrypt + "]]></Encrypt><AgentID><![CDATA[218]]></AgentID></xml>"
+
+// newTestCrypt 使用官方示例参数创建加解密实例
+func newTestCrypt(t testing.TB, receiveID string, format MsgFormat) *WXBizJsonMsgCrypt {
+	t.Helper()
+	crypt, err := NewWXBizMsgCrypt(sampleToken, sampleAESKey, receiveID, format)
+	if err != nil {
+		t.Fatalf("创建加解密实例失败: %v", err)
+	}
+	return crypt
+}
+
+// sign 计算回调签名
+func sign(token, timestamp, nonce, encrypt string) string {
+	_, signature, _ := (&SHA1Helper{}).GetSHA1(token, timestamp, nonce, encrypt)
+	return signature
+}
+
+// encryptRaw 按消息格式（16字节随机串 + 4字节长度 + 内容 + receiveID）加密任意明文，length为写入的长度字段
+func encryptRaw(t testing.TB, key []byte, length uint32, body []byte) string {
+	t.Helper()
+	plain := make([]byte, 20, 20+len(body))
+	copy(plain, "0123456789abcdef")
+	binary.BigEndian.PutUint32(plain[16:20], length)
+	plain = append(plain, body...)
+	ciphertext, err := aesCBCEncrypt(key, NewPKCS7Encoder().Encode(plain))
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+// encryptBlocks 对已按块对齐的数据直接做AES-CBC加密（不填充），用于构造填充无效的密文
+func encryptBlocks(t testing.TB, key, plain []byte) string {
+	t.Helper()
+	ciphertext, err := aesCBCEncrypt(key, plain)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func TestSampleVerifyURL(t *testing.T) {
+	crypt := newTestCrypt(t, sampleReceiveID, MsgFormatXML)
+	ret, echo, err := crypt.VerifyURL(sampleVerifySignature, sampleVerifyTimestamp, sampleVerifyNonce, sampleVerifyEchoStr)
+	if ret != WXBizMsgCrypt_OK {
+		t.Fatalf("VerifyURL返回%d: %v", ret, err)
+	}
+	if echo != sampleVerifyPlain {
+		t.Errorf("echostr = %q，期望 %q", echo, sampleVerifyPlain)
+	}
+}
+
+func TestSampleDecryptMsg(t *testing.T) {
+	crypt := newTestCrypt(t, sampleReceiveID, MsgFormatXML)
+	ret, msg, err := crypt.DecryptMsg(sampleMsgBody, sampleMsgSignature, sampleMsgTimestamp, sampleMsgNonce)
+	if ret != WXBizMsgCrypt_OK {
+		t.Fatalf("DecryptMsg返回%d: %v", ret, err)
+	}
+	if msg != sampleMsgPlain {
+		t.Errorf("明文 = %q，期望 %q", msg, sampleMsgPlain)
+	}
+}
+
+func TestSampleSignature(t *testing.T) {
+	if got := sign(sampleToken, sampleMsgTimestamp, sampleMsgNonce, sampleMsgEncrypt); got != sampleMsgSignature {
+		t.Errorf("签名 = %s，期望 %s", got, sampleMsgSignature)
+	}
+	if got := sign(sampleToken, sampleVerifyTimestamp, sampleVerifyNonce, sampleVerifyEchoStr); got != sampleVerifySignature {
+		t.Errorf("签名 = %s，期望 %s", got, sampleVerifySignature)
+	}
+}
+
+func TestSampleReceiveID(t *testing.T) {
+	crypt := newTestCrypt(t, "wwother", MsgFormatXML)
+	if ret, _, _ := crypt.DecryptMsg(sampleMsgBody, sampleMsgSignature, sampleMsgTimestamp, sampleMsgNonce); ret != WXBizMsgCrypt_ValidateCorpid_Error {
+		t.Errorf("receiveID不一致时返回%d，期望%d", ret, WXBizMsgCrypt_ValidateCorpid_Error)
+	}
+
+	crypt.SetReceiveIDMode(ReceiveIDLenient)
+	ret, msg, err := crypt.DecryptMsg(sampleMsgBody, sampleMsgSignature, sampleMsgTimestamp, sampleMsgNonce)
+	if ret != WXBizMsgCrypt_OK || msg != sampleMsgPlain {
+		t.Errorf("不校验receiveID时返回%d %q: %v", ret, msg, err)
+	}
+
+	ret, msg, receiveID, err := NewPrpcrypt(crypt.Key).Open(sampleMsgEncrypt)
+	if ret != WXBizMsgCrypt_OK || msg != sampleMsgPlain || receiveID != sampleReceiveID {
+		t.Errorf("Open返回%d %q %q: %v", ret, msg, receiveID, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	messages := []string{
+		"",
+		"a",
+		`{"msgtype":"text","text":{"content":"你好"}}`,
+		strings.Repeat("x", 11),   // 加上20字节头部和receiveID后恰好一个块
+		strings.Repeat("y", 12),   // 跨块
+		strings.Repeat("中", 1000), // 多字节字符
+		string([]byte{0, 1, 2, 0xff, 0xfe}),
+	}
+	for i := 0; i < 50; i++ {
+		b := make([]byte, r.Intn(4097))
+		r.Read(b)
+		messages = append(messages, string(b))
+	}
+
+	for _, format := range []MsgFormat{MsgFormatJSON, MsgFormatXML} {
+		for _, receiveID := range []string{"", sampleReceiveID} {
+			crypt := newTestCrypt(t, receiveID, format)
+			for _, msg := range messages {
+				timestamp, nonce := "1700000000", "nonce"
+				ret, body, err := crypt.EncryptMsg(msg, nonce, &timestamp)
+				if ret != WXBizMsgCrypt_OK {
+					t.Fatalf("EncryptMsg返回%d: %v", ret, err)
+				}
+				_, encrypt, err := crypt.envelope().Extract(body)
+				if err != nil {
+					t.Fatalf("解析加密结果失败: %v", err)
+				}
+				ret, plain, err := crypt.DecryptMsg(body, sign(sampleToken, timestamp, nonce, encrypt), timestamp, nonce)
+				if ret != WXBizMsgCrypt_OK {
+					t.Fatalf("格式%d receiveID=%q 长度%d: DecryptMsg返回%d: %v", format, receiveID, len(msg), ret, err)
+				}
+				if plain != msg {
+					t.Fatalf("格式%d receiveID=%q: 解密结果与原文不一致（%d字节 → %d字节）", format, receiveID, len(msg), len(plain))
+				}
+			}
+		}
+	}
+}
+
+func TestEncryptRandomized(t *testing.T) {
+	pc := NewPrpcrypt(newTestCrypt(t, "", MsgFormatJSON).Key)
+	_, first, err := pc.Encrypt("hello", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := pc.Encrypt("hello", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("相同明文两次加密得到相同密文，随机串未生效")
+	}
+}
+
+func TestBadSignature(t *testing.T) {
+	crypt := newTestCrypt(t, sampleReceiveID, MsgFormatXML)
+	for _, signature := range []string{
+		"",
+		"0" + sampleMsgSignature[1:],
+		strings.ToUpper(sampleMsgSignature),
+		sampleMsgSignature + "0",
+	} {
+		if ret, _, _ := crypt.DecryptMsg(sampleMsgBody, signature, sampleMsgTimestamp, sampleMsgNonce); ret != WXBizMsgCrypt_ValidateSignature_Error {
+			t.Errorf("签名%q返回%d，期望%d", signature, ret, WXBizMsgCrypt_ValidateSignature_Error)
+		}
+	}
+	// 签名正确但时间戳被改动
+	if ret, _, _ := crypt.DecryptMsg(sampleMsgBody, sampleMsgSignature, "1409659814", sampleMsgNonce); ret != WXBizMsgCrypt_ValidateSignature_Error {
+		t.Errorf("时间戳改动后返回%d，期望%d", ret, WXBizMsgCrypt_ValidateSignature_Error)
+	}
+}
+
+func TestTampered(t *testing.T) {
+	crypt := newTestCrypt(t, sampleReceiveID, MsgFormatXML)
+	ciphertext, err := base64.StdEncoding.DecodeString(sampleMsgEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tamper := map[string]func([]byte) []byte{
+		"截断半块":   func(c []byte) []byte { return c[:len(c)-8] },
+		"删除末块":   func(c []byte) []byte { return c[:len(c)-16] },
+		"删除首块":   func(c []byte) []byte { return c[16:] },
+		"追加一块":   func(c []byte) []byte { return append(c, make([]byte, 16)...) },
+		"翻转首字节":  func(c []byte) []byte { c[0] ^= 0x80; return c },
+		"翻转中间字节": func(c []byte) []byte { c[len(c)/2] ^= 1; return c },
+		"翻转末字节":  func(c []byte) []byte { c[len(c)-1] ^= 1; return c },
+	}
+	for name, fn := range tamper {
+		forged := base64.StdEncoding.EncodeToString(fn(append([]byte(nil), ciphertext...)))
+		signature := sign(sampleToken, sampleMsgTimestamp, sampleMsgNonce, forged)
+		body := (&XMLHelper{}).Generate(forged, signature, sampleMsgTimestamp, sampleMsgNonce)
+		ret, msg, _ := crypt.DecryptMsg(body, signature, sampleMsgTimestamp, sampleMsgNonce)
+		if ret == WXBizMsgCrypt_OK && msg == sampleMsgPlain {
+			t.Errorf("%s后仍解密出原文", name)
+		}
+	}
+}
+
+func TestDecryptMalformed(t *testing.T) {
+	pc := NewPrpcrypt(newTestCrypt(t, "", MsgFormatJSON).Key)
+	tests := []struct {
+		name    string
+		encrypt string
+		want    int
+	}{
+		{"非Base64", "not base64!", WXBizMsgCrypt_DecodeBase64_Error},
+		{"空密文", "", WXBizMsgCrypt_DecryptAES_Error},
+		{"长度不是块大小的整数倍", base64.StdEncoding.EncodeToString(make([]byte, 31)), WXBizMsgCrypt_DecryptAES_Error},
+		{"填充长度为0", encryptBlocks(t, pc.Key, make([]byte, 32)), WXBizMsgCrypt_DecryptAES_Error},
+		{"填充长度超过块大小", encryptBlocks(t, pc.Key, bytes.Repeat([]byte{33}, 64)), WXBizMsgCrypt_DecryptAES_Error},
+		{"填充字节不一致", encryptBlocks(t, pc.Key, append(make([]byte, 29), 1, 2, 3)), WXBizMsgCrypt_DecryptAES_Error},
+		{"头部不足20字节", encryptBlocks(t, pc.Key, append(make([]byte, 16), bytes.Repeat([]byte{16}, 16)...)), WXBizMsgCrypt_IllegalBuffer},
+		{"长度字段超过剩余数据", encryptRaw(t, pc.Key, 6, []byte("hello")), WXBizMsgCrypt_IllegalBuffer},
+		{"长度字段为最大值", encryptRaw(t, pc.Key, 0xffffffff, []byte("hello")), WXBizMsgCrypt_IllegalBuffer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ret, msg, _, _ := pc.Open(tt.encrypt); ret != tt.want {
+				t.Errorf("Open返回%d %q，期望%d", ret, msg, tt.want)
+			}
+		})
+	}
+
+	// 长度字段恰好等于剩余长度：内容为全部数据，receiveID为空
+	ret, msg, receiveID, err := pc.Open(encryptRaw(t, pc.Key, 5, []byte("hello")))
+	if ret != WXBizMsgCrypt_OK || msg != "hello" || receiveID != "" {
+		t.Errorf("Open返回%d %q %q: %v", ret, msg, receiveID, err)
+	}
+}
+
+func TestNewWXBizMsgCryptInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "short", sampleAESKey + "A", "!" + sampleAESKey[1:]} {
+		if _, err := NewWXBizMsgCrypt(sampleToken, key, "", MsgFormatJSON); err == nil {
+			t.Errorf("EncodingAESKey %q 未返回错误", key)
+		}
+	}
+}
+
+func TestPKCS7(t *testing.T) {
+	p := NewPKCS7Encoder()
+	for n := 0; n <= 3*p.BlockSize; n++ {
+		data := bytes.Repeat([]byte{0xab}, n)
+		padded := p.Encode(data)
+		if len(padded)%p.BlockSize != 0 || len(padded) <= n {
+			t.Fatalf("长度%d填充后为%d", n, len(padded))
+		}
+		unpadded, err := p.Unpad(padded)
+		if err != nil || !bytes.Equal(unpadded, data) {
+			t.Fatalf("长度%d去除填充失败: %v", n, err)
+		}
+	}
+
+	// Encode不修改传入切片的底层数组
+	buf := make([]byte, 3, 64)
+	p.Encode(buf)
+	if got := buf[:4][3]; got != 0 {
+		t.Errorf("Encode修改了传入切片的底层数组: %d", got)
+	}
+}
+
+func TestPKCS7BadPadding(t *testing.T) {
+	p := NewPKCS7Encoder()
+	tests := map[string][]byte{
+		"空数据":     nil,
+		"填充长度为0":  append(make([]byte, 31), 0),
+		"填充长度超范围": bytes.Repeat([]byte{33}, 64),
+		"填充超过数据":  {4, 4, 4},
+		"填充字节不一致": append(make([]byte, 29), 2, 3, 3),
+	}
+	for name, data := range tests {
+		if _, err := p.Unpad(data); err == nil {
+			t.Errorf("%s: Unpad未返回错误", name)
+		}
+		if got := p.Decode(data); !bytes.Equal(got, data) {
+			t.Errorf("%s: Decode应原样返回", name)
+		}
+	}
+}
+
+// FuzzDecrypt 任意密文解密只能返回错误，不能panic；解密成功时内容和receiveID必须来自解密后的数据
+func FuzzDecrypt(f *testing.F) {
+	crypt := newTestCrypt(f, sampleReceiveID, MsgFormatXML)
+	pc := NewPrpcrypt(crypt.Key)
+	f.Add(sampleMsgEncrypt)
+	f.Add(sampleVerifyEchoStr)
+	f.Add("")
+	f.Add("AAAA")
+	f.Add(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	f.Add(encryptRaw(f, crypt.Key, 0xffffffff, []byte("hello")))
+
+	f.Fuzz(func(t *testing.T, encrypt string) {
+		ret, msg, receiveID, err := pc.Open(encrypt)
+		if ret != WXBizMsgCrypt_OK {
+			if err == nil {
+				t.Errorf("返回%d但没有错误", ret)
+			}
+			return
+		}
+		ciphertext, _ := base64.StdEncoding.DecodeString(encrypt)
+		if len(msg)+len(receiveID)+20 > len(ciphertext) {
+			t.Errorf("解密结果%d+%d字节超过密文长度%d", len(msg), len(receiveID), len(ciphertext))
+		}
+
+		// 完整回调流程同样不能panic
+		signature := sign(sampleToken, sampleMsgTimestamp, sampleMsgNonce, encrypt)
+		crypt.DecryptMsg((&XMLHelper{}).Generate(encrypt, signature, sampleMsgTimestamp, sampleMsgNonce), signature, sampleMsgTimestamp, sampleMsgNonce)
+		crypt.VerifyURL(signature, sampleMsgTimestamp, sampleMsgNonce, encrypt)
+	})
+}
+
+// FuzzUnpad 任意数据去除填充只能返回错误或合法结果；任意数据填充后去除必须还原
+func FuzzUnpad(f *testing.F) {
+	p := NewPKCS7Encoder()
+	f.Add([]byte{})
+	f.Add([]byte{1})
+	f.Add(bytes.Repeat([]byte{32}, 32))
+	f.Add(append(make([]byte, 29), 3, 3, 3))
+	f.Add([]byte{0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if unpadded, err := p.Unpad(data); err == nil {
+			pad := len(data) - len(unpadded)
+			if pad < 1 || pad > p.BlockSize || !bytes.Equal(unpadded, data[:len(unpadded)]) {
+				t.Fatalf("去除了%d字节填充", pad)
+			}
+			for _, b := range data[len(unpadded):] {
+				if int(b) != pad {
+					t.Fatalf("接受了不一致的填充字节 %v", data[len(unpadded):])
+				}
+			}
+		}
+
+		unpadded, err := p.Unpad(p.Encode(data))
+		if err != nil || !bytes.Equal(unpadded, data) {
+			t.Fatalf("填充后去除未还原: %v", err)
+		}
+	})
+}
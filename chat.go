@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// chatClient 千问HTTP API的终端客户端（会话内多轮对话）
+type chatClient struct {
+	baseURL   string
+	apiKey    string
+	sessionID string
+	http      *http.Client
+}
+
+// chatEvent 服务端SSE事件
+type chatEvent struct {
+	Type      string `json:"type"`
+	Content   string `json:"content"`
+	RequestID string `json:"request_id"`
+}
+
+// runChat 终端聊天：连接 serve-http 服务，返回进程退出码
+func runChat(args []string) int {
+	fs := flag.NewFlagSet("chat", flag.ExitOnError)
+	baseURL := fs.String("url", envOr("AIBODY_URL", "http://localhost:8080"), "HTTP API服务地址（环境变量 AIBODY_URL）")
+	apiKey := fs.String("api-key", os.Getenv("AIBODY_API_KEY"), "API密钥（环境变量 AIBODY_API_KEY）")
+	sessionID := fs.String("session", "", "继续已有会话（默认创建新会话）")
+	fs.Parse(args)
+
+	client := &chatClient{
+		baseURL:   strings.TrimRight(*baseURL, "/"),
+		apiKey:    *apiKey,
+		sessionID: *sessionID,
+		http:      &http.Client{},
+	}
+	if client.sessionID == "" {
+		if err := client.newSession(); err != nil {
+			fmt.Printf("%s❌ %v%s\n", ColorRed, err, ColorReset)
+			return 1
+		}
+	}
+
+	fmt.Printf("%s🤖 AI-Body 终端聊天%s\n", ColorCyan, ColorReset)
+	fmt.Printf("服务地址: %s%s%s, 会话: %s%s%s\n", ColorYellow, client.baseURL, ColorReset, ColorYellow, client.sessionID, ColorReset)
+	fmt.Printf("%s提示: 输入 'new' 开始新会话，'exit' 退出，回复过程中按 Ctrl+C 取消%s\n\n", ColorGray, ColorReset)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Printf("%s你: %s", ColorGreen, ColorReset)
+		if !scanner.Scan() {
+			break
+		}
+		input := strings.TrimSpace(scanner.Text())
+		switch input {
+		case "":
+			continue
+		case "exit", "quit":
+			fmt.Printf("%s👋 再见!%s\n", ColorYellow, ColorReset)
+			return 0
+		case "new":
+			if err := client.newSession(); err != nil {
+				fmt.Printf("%s❌ %v%s\n", ColorRed, err, ColorReset)
+				continue
+			}
+			fmt.Printf("%s🆕 新会话: %s%s\n\n", ColorGray, client.sessionID, ColorReset)
+			continue
+		}
+
+		if err := client.send(input); err != nil {
+			fmt.Printf("\n%s❌ %v%s\n", ColorRed, err, ColorReset)
+		}
+		fmt.Println()
+	}
+	return 0
+}
+
+// newSession 创建会话 POST /sessions
+func (c *chatClient) newSession() error {
+	resp, err := c.do(context.Background(), http.MethodPost, "/sessions", nil)
+	if err != nil {
+		return fmt.Errorf("创建会话失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return fmt.Errorf("解析会话失败: %w", err)
+	}
+	c.sessionID = session.SessionID
+	return nil
+}
+
+// send 发送消息并流式输出回复，Ctrl+C 时通知服务端取消生成
+func (c *chatClient) send(message string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	body, _ := json.Marshal(map[string]string{"message": message})
+	resp, err := c.do(ctx, http.MethodPost, "/sessions/"+c.sessionID+"/chat", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	start := time.Now()
+	var requestID string
+	fmt.Printf("%s🤖 助手: %s", ColorPurple, ColorReset)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var event chatEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			continue
+		}
+
+		switch event.Type {
+		case "start":
+			requestID = event.RequestID
+		case "content":
+			fmt.Print(event.Content)
+		case "error":
+			return fmt.Errorf("服务端错误: %s", event.Content)
+		case "cancelled":
+			fmt.Printf(" %s(已取消)%s\n", ColorGray, ColorReset)
+			return nil
+		case "done":
+			fmt.Printf(" %s(耗时: %.2fs)%s\n", ColorGray, time.Since(start).Seconds(), ColorReset)
+			return nil
+		}
+	}
+
+	if ctx.Err() != nil && requestID != "" {
+		// 断开连接后服务端会在宽限期后才取消，这里立即取消
+		if resp, err := c.do(context.Background(), http.MethodDelete, "/chat/"+requestID, nil); err == nil {
+			resp.Body.Close()
+		}
+		fmt.Printf(" %s(已取消)%s\n", ColorGray, ColorReset)
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取回复失败: %w", err)
+	}
+	return fmt.Errorf("回复意外中断")
+}
+
+// do 发送带API密钥的请求，非2xx响应转换为错误
+func (c *chatClient) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Error == "" {
+			apiErr.Error = strings.TrimSpace(string(data))
+		}
+		return nil, fmt.Errorf("服务端返回 %d: %s", resp.StatusCode, apiErr.Error)
+	}
+	return resp, nil
+}
+
+// envOr 读取环境变量，未设置时返回默认值
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Example 可运行的示例
+type Example struct {
+	Name        string
+	Description string
+	Path        string
+	Color       string
+}
+
+// runExampleSelector 交互式示例选择器（以 go run 运行示例，需在项目根目录执行）
+func runExampleSelector(args []string) int {
+	fs := flag.NewFlagSet("examples", flag.ExitOnError)
+	dir := fs.String("dir", "examples", "示例目录")
+	fs.Parse(args)
+
+	examples, err := discoverExamples(*dir)
+	if err != nil {
+		fmt.Printf("%s❌ %v%s\n", ColorRed, err, ColorReset)
+		return 1
+	}
+	if len(examples) == 0 {
+		fmt.Printf("%s❌ %s 下没有可运行的示例（包含 main.go 的子目录）%s\n", ColorRed, *dir, ColorReset)
+		return 1
+	}
+
+	fmt.Printf("%s╔══════════════════════════════════════════╗%s\n", ColorCyan, ColorReset)
+	fmt.Printf("%s║           AI-Body 示例选择器             ║%s\n", ColorCyan, ColorReset)
+	fmt.Printf("%s║        企业微信智能机器人框架            ║%s\n", ColorCyan, ColorReset)
+	fmt.Printf("%s╚══════════════════════════════════════════╝%s\n", ColorCyan, ColorReset)
+	fmt.Println()
+
+	for {
+		displayMenu(examples)
+
+		scanner := bufio.NewScanner(os.Stdin)
+		fmt.Printf("%s请选择示例 (1-%d) 或输入 'quit' 退出: %s", ColorBlue, len(examples), ColorReset)
+
+		if !scanner.Scan() {
+			break
+		}
+
+		input := strings.TrimSpace(scanner.Text())
+
+		if input == "quit" || input == "exit" || input == "q" {
+			fmt.Printf("%s再见！%s\n", ColorGreen, ColorReset)
+			break
+		}
+
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 1 || choice > len(examples) {
+			fmt.Printf("%s无效选择，请输入 1-%d%s\n\n", ColorYellow, len(examples), ColorReset)
+			continue
+		}
+
+		selectedExample := examples[choice-1]
+		runExample(selectedExample)
+	}
+	return 0
+}
+
+// exampleColors 菜单中轮流使用的颜色
+var exampleColors = []string{ColorGreen, ColorPurple, ColorCyan, ColorYellow}
+
+// discoverExamples 扫描包含 main.go 的子目录，名称和简介取自 README.md 的一级标题和首段
+func discoverExamples(dir string) ([]Example, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取示例目录失败（请在项目根目录运行）: %w", err)
+	}
+
+	var examples []Example
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(path, "main.go")); err != nil {
+			continue
+		}
+
+		name, description := readExampleMeta(filepath.Join(path, "README.md"))
+		if name == "" {
+			name = entry.Name()
+		}
+		examples = append(examples, Example{
+			Name:        name,
+			Description: description,
+			Path:        filepath.ToSlash(path),
+			Color:       exampleColors[len(examples)%len(exampleColors)],
+		})
+	}
+	return examples, nil
+}
+
+// readExampleMeta 读取README的一级标题和标题后的第一段（缺失时返回空）
+func readExampleMeta(readme string) (name, description string) {
+	data, err := os.ReadFile(readme)
+	if err != nil {
+		return "", ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case name == "":
+			if title, ok := strings.CutPrefix(line, "# "); ok {
+				name = strings.TrimSpace(title)
+			}
+		case line == "":
+			if description != "" {
+				return name, description
+			}
+		case strings.HasPrefix(line, "#"):
+			return name, description
+		default:
+			description = strings.TrimSpace(description + " " + strings.ReplaceAll(line, "**", ""))
+		}
+	}
+	return name, description
+}
+
+func displayMenu(examples []Example) {
+	fmt.Printf("%s可用示例:%s\n", ColorCyan, ColorReset)
+	fmt.Println()
+
+	for i, example := range examples {
+		fmt.Printf("%s[%d] %s%s%s\n", ColorBlue, i+1, example.Color, example.Name, ColorReset)
+		if example.Description != "" {
+			fmt.Printf("    %s%s%s\n", ColorYellow, example.Description, ColorReset)
+		}
+		fmt.Printf("    路径: %s\n", example.Path)
+		fmt.Println()
+	}
+}
+
+func runExample(example Example) {
+	fmt.Printf("%s正在运行: %s%s%s\n", ColorGreen, example.Color, example.Name, ColorReset)
+	fmt.Printf("%s路径: %s%s\n", ColorBlue, example.Path, ColorReset)
+	fmt.Printf("%s%s%s\n", ColorYellow, strings.Repeat("=", 50), ColorReset)
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = example.Path
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+
+	err := cmd.Run()
+	if err != nil {
+		fmt.Printf("%s运行示例时出错: %v%s\n", ColorYellow, err, ColorReset)
+	}
+
+	fmt.Printf("\n%s%s%s\n", ColorYellow, strings.Repeat("=", 50), ColorReset)
+	fmt.Printf("%s示例运行完毕，按 Enter 返回菜单...%s", ColorGreen, ColorReset)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+
+	// 清屏
+	fmt.Print("\033[2J\033[H")
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/audit"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// RunAudit 审计日志相关子命令
+// 用法: go run . audit verify [-config config.json] [-file data/audit.jsonl]
+func RunAudit(args []string) int {
+	if len(args) == 0 || args[0] != "verify" {
+		fmt.Println("用法: audit verify [-config config.json] [-file data/audit.jsonl]")
+		fmt.Println("  verify  校验审计日志哈希链，检查记录是否被修改、删除或插入")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("audit verify", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径（未指定 -file 时使用其中的 audit.path）")
+	file := fs.String("file", "", "审计日志路径")
+	fs.Parse(args[1:])
+
+	path := *file
+	if path == "" {
+		cfg, err := config.LoadConfigFromFile(*configPath)
+		if err != nil {
+			fmt.Printf("❌ 配置加载失败: %v\n", err)
+			return 1
+		}
+		path = cfg.Audit.Path
+	}
+
+	count, err := audit.Verify(path)
+	if err != nil {
+		fmt.Printf("❌ 审计日志校验失败（前%d条记录完好）: %v\n", count, err)
+		return 1
+	}
+	fmt.Printf("✅ 审计日志完好: %s（%d条记录）\n", path, count)
+	return 0
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/mcp"
+)
+
+// RunConfig 配置相关子命令
+// 用法: go run . config validate|doctor|schema [-config config.json]
+func RunConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Println("用法: config <validate|doctor|schema> [-config config.json]")
+		fmt.Println("  validate  离线校验配置文件")
+		fmt.Println("  doctor    校验配置并实际测试企业微信加解密、LLM和MCP服务器连通性")
+		fmt.Println("  schema    输出配置文件的JSON Schema（可供编辑器提示和校验）")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config "+args[0], flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	fs.StringVar(configPath, "c", "config.json", "配置文件路径 (短参数)")
+	timeout := fs.Duration("timeout", 30*time.Second, "doctor单项检查超时时间")
+	fs.Parse(args[1:])
+
+	switch args[0] {
+	case "validate":
+		_, ok := validateConfigFile(*configPath)
+		if !ok {
+			return 1
+		}
+		return 0
+
+	case "doctor":
+		cfg, ok := validateConfigFile(*configPath)
+		if !ok {
+			return 1
+		}
+		if !runDoctor(cfg, *timeout) {
+			return 1
+		}
+		return 0
+
+	case "schema":
+		data, err := config.MarshalSchema()
+		if err != nil {
+			fmt.Printf("❌ 生成JSON Schema失败: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(data))
+		return 0
+
+	default:
+		fmt.Printf("❌ 未知的config子命令: %s\n", args[0])
+		return 2
+	}
+}
+
+// validateConfigFile 离线校验配置文件（不回退默认配置）
+func validateConfigFile(path string) (*config.Config, bool) {
+	cfg, err := config.ReloadConfigFromFile(path)
+	if err != nil {
+		fmt.Printf("❌ 配置无效: %s\n   %v\n", path, err)
+		return nil, false
+	}
+
+	enabledMCP := 0
+	for _, server := range cfg.MCP.Servers {
+		if server.Enabled {
+			enabledMCP++
+		}
+	}
+
+	fmt.Printf("✅ 配置有效: %s\n", path)
+	fmt.Printf("   默认LLM: %s (共%d个提供商)\n", cfg.LLM.Default, len(cfg.LLM.Providers))
+	fmt.Printf("   MCP服务器: %d/%d 已启用\n", enabledMCP, len(cfg.MCP.Servers))
+	fmt.Printf("   群聊配置: %d 个\n", len(cfg.Groups))
+	fmt.Printf("   监听地址: %s\n", cfg.Server.Addr())
+	return cfg, true
+}
+
+// doctorCheck 单项检查结果
+type doctorCheck struct {
+	name   string
+	err    error
+	detail string
+}
+
+// runDoctor 主动测试外部依赖并打印就绪报告，全部通过返回true
+func runDoctor(cfg *config.Config, timeout time.Duration) bool {
+	fmt.Println("\n🩺 开始部署前检查...")
+
+	var checks []doctorCheck
+	for _, b := range cfg.BotConfigs() {
+		check := checkWeWorkCrypto(b.WeWork)
+		if len(cfg.Bots) > 0 {
+			check.name = fmt.Sprintf("%s (%s)", check.name, b.Name)
+		}
+		checks = append(checks, check)
+	}
+
+	if cfg.Server.TLS.CertFile != "" {
+		checks = append(checks, checkTLSCert(cfg.Server.TLS))
+	}
+
+	names := make([]string, 0, len(cfg.LLM.Providers))
+	for name := range cfg.LLM.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		checks = append(checks, checkLLM(cfg, name, timeout))
+	}
+
+	for _, server := range cfg.MCP.Servers {
+		if !server.Enabled {
+			continue
+		}
+		checks = append(checks, checkMCP(server, timeout))
+	}
+
+	fmt.Println("\n📋 就绪报告:")
+	failed := 0
+	for _, c := range checks {
+		if c.err != nil {
+			failed++
+			fmt.Printf("   ❌ %s\n", c.name)
+			for _, line := range strings.Split(c.err.Error(), "\n") {
+				fmt.Printf("      %s\n", strings.TrimSpace(line))
+			}
+			continue
+		}
+		fmt.Printf("   ✅ %s\n", strings.TrimSpace(c.name+" "+c.detail))
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n❌ %d/%d 项检查未通过\n", failed, len(checks))
+		return false
+	}
+	fmt.Printf("\n✅ 全部 %d 项检查通过，可以部署\n", len(checks))
+	return true
+}
+
+// checkWeWorkCrypto 使用配置的Token/AESKey/BotID做一次加解密往返
+func checkWeWorkCrypto(cfg config.WeWorkConfig) doctorCheck {
+	check := doctorCheck{name: "企业微信加解密"}
+
+	crypt, err := wework.NewWXBizJsonMsgCrypt(cfg.Token, cfg.AESKey, cfg.BotID)
+	if err != nil {
+		check.err = err
+		return check
+	}
+
+	const probe = `{"msgtype":"text","text":{"content":"doctor"}}`
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	nonce := "doctor"
+
+	_, encrypted, err := crypt.EncryptMsg(probe, nonce, &timestamp)
+	if err != nil {
+		check.err = fmt.Errorf("加密失败: %w", err)
+		return check
+	}
+
+	// 从加密结果中取出签名后按回调流程解密
+	_, signature, err := (&wework.SHA1Helper{}).GetSHA1(cfg.Token, timestamp, nonce, extractEncrypt(encrypted))
+	if err != nil {
+		check.err = fmt.Errorf("签名失败: %w", err)
+		return check
+	}
+	_, decrypted, err := crypt.DecryptMsg(encrypted, signature, timestamp, nonce)
+	if err != nil {
+		check.err = fmt.Errorf("解密失败: %w", err)
+		return check
+	}
+	if decrypted != probe {
+		check.err = fmt.Errorf("加解密结果不一致")
+		return check
+	}
+
+	if cfg.BotID == "" {
+		check.detail = "(未配置bot_id，不校验接收方ID)"
+	}
+	return check
+}
+
+// checkTLSCert 加载HTTPS证书并检查有效期
+func checkTLSCert(cfg config.TLSConfig) doctorCheck {
+	check := doctorCheck{name: "HTTPS证书"}
+
+	pair, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		check.err = fmt.Errorf("加载证书失败: %w", err)
+		return check
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		check.err = fmt.Errorf("解析证书失败: %w", err)
+		return check
+	}
+	if time.Now().After(cert.NotAfter) {
+		check.err = fmt.Errorf("证书已于 %s 过期", cert.NotAfter.Format("2006-01-02"))
+		return check
+	}
+
+	check.detail = fmt.Sprintf("(有效期至 %s)", cert.NotAfter.Format("2006-01-02"))
+	return check
+}
+
+// extractEncrypt 从加密响应JSON中提取密文
+func extractEncrypt(response string) string {
+	_, encrypt, _ := (&wework.JsonHelper{}).Extract(response)
+	return encrypt
+}
+
+// checkLLM 向LLM提供商发送一次最小请求
+func checkLLM(cfg *config.Config, name string, timeout time.Duration) doctorCheck {
+	provider := cfg.LLM.Providers[name]
+	check := doctorCheck{name: fmt.Sprintf("LLM %s (%s/%s)", name, provider.Provider, provider.Model)}
+	if name == cfg.LLM.Default {
+		check.name += " [默认]"
+	}
+
+	client, err := llm.CreateLLMByName(cfg, name, applog.SDK())
+	if err != nil {
+		check.err = err
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.Generate(ctx, "ping，请只回复pong"); err != nil {
+		check.err = fmt.Errorf("请求失败: %w", err)
+		return check
+	}
+	check.detail = fmt.Sprintf("(耗时 %v)", time.Since(start).Round(time.Millisecond))
+	return check
+}
+
+// checkMCP 连接MCP服务器并列出工具
+func checkMCP(server config.MCPServerConfig, timeout time.Duration) doctorCheck {
+	check := doctorCheck{name: fmt.Sprintf("MCP %s (%s)", server.Name, server.Type)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	count, err := mcp.CheckServer(ctx, server)
+	if err != nil {
+		check.err = err
+		return check
+	}
+	check.detail = fmt.Sprintf("(%d 个工具)", count)
+	return check
+}
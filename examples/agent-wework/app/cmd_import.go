@@ -0,0 +1,88 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/importer"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/knowledge"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/profile"
+)
+
+// RunImport 导入历史工单对话到用户画像和知识库
+// 用法: go run . import -file history.csv [-format csv|json|jsonl] [-config config.json] [-dry-run]
+func RunImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	file := fs.String("file", "", "历史工单文件（CSV/JSON/JSONL）")
+	format := fs.String("format", "", "文件格式，默认按扩展名识别")
+	skipProfiles := fs.Bool("skip-profiles", false, "不写入用户画像")
+	skipKnowledge := fs.Bool("skip-knowledge", false, "不写入知识库")
+	dryRun := fs.Bool("dry-run", false, "只解析统计，不写入")
+	fs.Parse(args)
+
+	if *file == "" {
+		fmt.Println("❌ 请通过 -file 指定要导入的历史工单文件")
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.LoadConfigFromFile(*configPath)
+	if err != nil {
+		fmt.Printf("❌ 配置加载失败: %v\n", err)
+		return 1
+	}
+
+	records, err := importer.LoadRecords(*file, *format)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("📥 读取到 %d 条历史消息\n", len(records))
+
+	var profiles *profile.Store
+	var kb *knowledge.Store
+	if !*skipProfiles {
+		if profiles, err = profile.NewStore(cfg.Profile.Path); err != nil {
+			fmt.Printf("❌ 加载用户画像失败: %v\n", err)
+			return 1
+		}
+	}
+	if !*skipKnowledge {
+		if kb, err = knowledge.NewStore(cfg.Knowledge.Path, cfg.Knowledge.ChunkSize); err != nil {
+			fmt.Printf("❌ 加载知识库失败: %v\n", err)
+			return 1
+		}
+	}
+
+	result := importer.Import(records, profiles, kb)
+	fmt.Printf("📊 工单: %d, 用户: %d, 知识文档: %d, 跳过: %d\n",
+		result.Tickets, result.Users, result.Documents, result.Skipped)
+
+	if *dryRun {
+		fmt.Println("ℹ️  dry-run 模式，未写入任何数据")
+		return 0
+	}
+
+	if profiles != nil {
+		if err := profiles.Save(); err != nil {
+			fmt.Printf("❌ 保存用户画像失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✅ 用户画像已写入: %s (共 %d 位用户)\n", cfg.Profile.Path, profiles.Count())
+	}
+	if kb != nil {
+		if err := kb.Save(); err != nil {
+			fmt.Printf("❌ 保存知识库失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✅ 知识库已写入: %s (共 %d 篇文档)\n", cfg.Knowledge.Path, len(kb.List()))
+	}
+
+	if !cfg.Profile.Enabled || !cfg.Knowledge.Enabled {
+		fmt.Println("💡 提示: 请在配置中启用 profile.enabled 和 knowledge.enabled 以在对话中使用导入的数据")
+	}
+
+	return 0
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/audit"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/bot"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/cluster"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/errreport"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/outbound"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/queue"
+)
+
+// RunWorker 消息队列worker：消费Webhook副本入队的消息，运行Agent并通过共享状态发布回复
+// 用法: go run . worker [-config config.json]
+func RunWorker(args []string) int {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	fs.StringVar(configPath, "c", "config.json", "配置文件路径 (短参数)")
+	fs.Parse(args)
+
+	cfg, err := config.LoadConfigFromFile(*configPath)
+	if err != nil {
+		slog.Error("配置加载失败", "err", err)
+		return 1
+	}
+	if err := applog.Setup(cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		slog.Error("日志初始化失败", "err", err)
+		return 1
+	}
+	if err := errreport.Setup(cfg.ErrorReport); err != nil {
+		slog.Error("错误上报初始化失败", "err", err)
+		return 1
+	}
+	if !cfg.Queue.Enabled {
+		slog.Error("未启用消息队列模式（queue.enabled）")
+		return 1
+	}
+	// 定时任务由Webhook服务执行，避免重复推送
+	cfg.Schedules = nil
+
+	streamStore, err := cluster.New(cfg.Cluster)
+	if err != nil {
+		slog.Error("共享状态初始化失败", "err", err)
+		return 1
+	}
+	if closer, ok := streamStore.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	broker, err := queue.New(cfg.Queue, cfg.Cluster.RedisPassword, time.Duration(cfg.Cluster.TTL)*time.Second)
+	if err != nil {
+		slog.Error("消息队列初始化失败", "err", err)
+		return 1
+	}
+	defer broker.Close()
+
+	// 工具调用等对外操作发生在worker中，按副本单独记录审计日志（各自一条哈希链）
+	var auditLog *audit.Log
+	if cfg.Audit.Enabled {
+		ext := filepath.Ext(cfg.Audit.Path)
+		path := strings.TrimSuffix(cfg.Audit.Path, ext) + "_worker_" + cfg.Cluster.InstanceID + ext
+		if auditLog, err = audit.Open(path); err != nil {
+			slog.Error("审计日志初始化失败", "err", err)
+			return 1
+		}
+		defer auditLog.Close()
+		slog.Info("审计日志", "path", path)
+	}
+
+	// 新会话、转人工和工具失败发生在worker中，由worker推送出站Webhook
+	dispatcher := outbound.New(cfg.Outbound)
+	if dispatcher != nil {
+		defer dispatcher.Close()
+	}
+
+	handlers := make(map[string]*bot.BotHandler)
+	for _, b := range cfg.BotConfigs() {
+		handler, err := bot.NewBotHandler(cfg.ForBot(b))
+		if err != nil {
+			slog.Error("机器人初始化失败", "bot", b.Name, "err", err)
+			return 1
+		}
+		defer handler.Close()
+		handler.SetStreamStore(streamStore)
+		if auditLog != nil {
+			audit.Subscribe(auditLog, b.Name, handler.Events())
+		}
+		if dispatcher != nil {
+			dispatcher.Subscribe(b.Name, handler.Org(), handler.Events())
+		}
+		handlers[b.Name] = handler
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("worker已启动", "instance", cfg.Cluster.InstanceID, "backend", cfg.Queue.Backend,
+		"queue", cfg.Queue.Name, "concurrency", cfg.Queue.Concurrency, "bots", len(handlers))
+	err = broker.Consume(ctx, func(ctx context.Context, job queue.Job) error {
+		handler, ok := handlers[job.Bot]
+		if !ok {
+			return fmt.Errorf("机器人 %s 不存在（worker与Webhook服务的配置不一致？）", job.Bot)
+		}
+		return handler.RunJob(ctx, job)
+	})
+	if err != nil {
+		slog.Error("消费消息队列失败", "err", err)
+		return 1
+	}
+	slog.Info("worker已停止")
+	return 0
+}
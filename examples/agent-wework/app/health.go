@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/health"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/mcp"
+)
+
+// buildHealthProbes 根据配置生成依赖探测项：
+// 机器人使用的LLM为关键依赖，MCP服务器和本地存储为非关键依赖（失败时降级）
+func buildHealthProbes(cfg *config.Config) []health.Probe {
+	var probes []health.Probe
+
+	providers := map[string]bool{cfg.LLM.Default: true}
+	for _, b := range cfg.BotConfigs() {
+		if b.LLMProvider != "" {
+			providers[b.LLMProvider] = true
+		}
+	}
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		probes = append(probes, llmProbe(cfg, name))
+	}
+
+	for _, server := range cfg.MCP.Servers {
+		if !server.Enabled {
+			continue
+		}
+		server := server
+		probes = append(probes, health.Probe{
+			Name: "mcp:" + server.Name,
+			Check: func(ctx context.Context) error {
+				_, err := mcp.CheckServer(ctx, server)
+				return err
+			},
+		})
+	}
+
+	dirs := []string{filepath.Dir(cfg.Profile.Path), filepath.Dir(cfg.Knowledge.Path)}
+	if cfg.Logging.Enabled {
+		dirs = append(dirs, cfg.Logging.LogDir)
+	}
+	probes = append(probes, health.Probe{
+		Name: "storage",
+		Check: func(ctx context.Context) error {
+			for _, dir := range dirs {
+				if err := checkWritable(dir); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+
+	return probes
+}
+
+// llmProbe 向LLM提供商发送一次最小请求（客户端在探测项创建时初始化一次）
+func llmProbe(cfg *config.Config, name string) health.Probe {
+	client, err := llm.CreateLLMByName(cfg, name, applog.SDK())
+	return health.Probe{
+		Name:     "llm:" + name,
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			if err != nil {
+				return err
+			}
+			_, genErr := client.Generate(ctx, "ping，请只回复pong")
+			return genErr
+		},
+	}
+}
+
+// checkWritable 检查目录可写（写入并删除临时文件）
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("目录 %s 不可写: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,416 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/admin"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/audit"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/bot"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/cluster"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/dedup"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/errreport"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/health"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/kf"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/outbound"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/queue"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/slack"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/telegram"
+	"github.com/deepsage-ai/b0dy/pkg/metrics"
+)
+
+// Main 企业微信机器人命令入口：import、config、audit、worker 子命令，其余参数启动服务，返回进程退出码
+func Main(args []string) int {
+	// 子命令分发
+	if len(args) > 0 {
+		switch args[0] {
+		case "import":
+			return RunImport(args[1:])
+		case "config":
+			return RunConfig(args[1:])
+		case "audit":
+			return RunAudit(args[1:])
+		case "worker":
+			return RunWorker(args[1:])
+		}
+	}
+	Serve(args)
+	return 0
+}
+
+// Serve 启动企业微信机器人服务（阻塞运行，启动失败时退出进程）
+func Serve(args []string) {
+	// 解析命令行参数
+	var configPath string
+	var watchConfig bool
+	fs := flag.NewFlagSet("serve-wework", flag.ExitOnError)
+	fs.StringVar(&configPath, "config", "config.json", "配置文件路径（支持 .json / .yaml / .yml）")
+	fs.StringVar(&configPath, "c", "config.json", "配置文件路径 (短参数)")
+	fs.BoolVar(&watchConfig, "watch", true, "监听配置文件变更并热更新")
+	fs.Parse(args)
+
+	// 加载配置
+	slog.Info("启动 AI-Body 企业微信智能机器人（Python流式模式）", "config", configPath)
+	cfg, err := config.LoadConfigFromFile(configPath)
+	if err != nil {
+		fatal("配置加载失败", err)
+	}
+	if err := applog.Setup(cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		fatal("日志初始化失败", err)
+	}
+	if err := errreport.Setup(cfg.ErrorReport); err != nil {
+		fatal("错误上报初始化失败", err)
+	}
+
+	// 显示配置信息（掩码敏感信息）
+	bots := cfg.BotConfigs()
+	for _, b := range bots {
+		slog.Info("机器人配置", "bot", b.Name, "org", cfg.ForBot(b).Org(),
+			"token", maskSecret(b.WeWork.Token), "aes_key", maskSecret(b.WeWork.AESKey), "bot_id", maskSecret(b.WeWork.BotID))
+	}
+	slog.Info("LLM配置", "default", cfg.LLM.Default, "providers", len(cfg.LLM.Providers))
+	slog.Info("MCP服务器配置", "servers", len(cfg.MCP.Servers))
+
+	// 回调消息去重（所有机器人共享，按组织加键前缀；多副本部署时可使用Redis）
+	deduplicator, err := dedup.New(cfg.Dedup)
+	if err != nil {
+		fatal("消息去重初始化失败", err)
+	}
+	if closer, ok := deduplicator.(io.Closer); ok {
+		defer closer.Close()
+	}
+	slog.Info("消息去重", "backend", dedupBackendName(cfg.Dedup), "ttl_seconds", cfg.Dedup.TTL)
+
+	// 多副本共享状态（任意副本都能响应流式刷新）
+	streamStore, err := cluster.New(cfg.Cluster)
+	if err != nil {
+		fatal("共享状态初始化失败", err)
+	}
+	if closer, ok := streamStore.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if streamStore != nil {
+		slog.Info("多副本模式：流式状态同步到Redis", "instance", cfg.Cluster.InstanceID, "sync_interval_ms", cfg.Cluster.SyncInterval)
+	}
+
+	// 消息队列模式（Webhook只入队，由worker进程运行Agent）
+	broker, err := queue.New(cfg.Queue, cfg.Cluster.RedisPassword, time.Duration(cfg.Cluster.TTL)*time.Second)
+	if err != nil {
+		fatal("消息队列初始化失败", err)
+	}
+	if broker != nil {
+		defer broker.Close()
+		slog.Info("消息队列模式：由 worker 子命令处理消息", "backend", cfg.Queue.Backend, "queue", cfg.Queue.Name)
+	}
+
+	// 审计日志（所有机器人共用一条哈希链）
+	var auditLog *audit.Log
+	if cfg.Audit.Enabled {
+		auditLog, err = audit.Open(cfg.Audit.Path)
+		if err != nil {
+			fatal("审计日志初始化失败", err)
+		}
+		defer auditLog.Close()
+		slog.Info("审计日志", "path", cfg.Audit.Path)
+	}
+
+	// 出站Webhook（所有机器人共用发送队列）
+	dispatcher := outbound.New(cfg.Outbound)
+	if dispatcher != nil {
+		defer dispatcher.Close()
+		slog.Info("出站Webhook", "endpoints", len(cfg.Outbound.Endpoints))
+	}
+
+	// 初始化机器人（每个机器人独立的处理器和Webhook路由）
+	handlers := make(map[string]*bot.BotHandler, len(bots))
+	webhookHandlers := make([]*wework.WebhookHandler, len(bots))
+	for i, b := range bots {
+		slog.Info("初始化AI机器人", "bot", b.Name)
+		botHandler, err := bot.NewBotHandler(cfg.ForBot(b))
+		if err != nil {
+			fatal("机器人初始化失败", err, "bot", b.Name)
+		}
+		defer botHandler.Close()
+		if streamStore != nil {
+			botHandler.SetStreamStore(streamStore)
+		}
+		if broker != nil {
+			botHandler.SetQueue(broker, b.Name)
+		}
+		if auditLog != nil {
+			audit.Subscribe(auditLog, b.Name, botHandler.Events())
+		}
+		if dispatcher != nil {
+			dispatcher.Subscribe(b.Name, botHandler.Org(), botHandler.Events())
+		}
+		handlers[b.Name] = botHandler
+
+		webhookHandler, err := wework.NewWebhookHandler(
+			b.WeWork.Token,
+			b.WeWork.AESKey,
+			b.WeWork.BotID,
+			botHandler,
+		)
+		if err != nil {
+			fatal("机器人Webhook处理器初始化失败", err, "bot", b.Name)
+		}
+		webhookHandler.SetDeduplicator(dedup.Scoped(deduplicator, botHandler.Org()))
+		webhookHandler.SetMaxBodySize(int64(cfg.Server.MaxBodySize) << 10)
+		webhookHandler.OnDecryptFailure(decryptFailureHook("bot:" + b.Name))
+		metrics.RegisterActiveTasks(botHandler.GetActiveStreamCount)
+		webhookHandlers[i] = webhookHandler
+	}
+	slog.Info("AI机器人初始化完成", "bots", len(bots))
+
+	// 微信客服渠道（外部客户的消息交给指定机器人处理）
+	var kfHandler *wework.KFWebhookHandler
+	if cfg.KF.Enabled {
+		kfBot := handlers[cfg.ChannelBot(cfg.KF.Bot)]
+		kfChannel, err := kf.NewChannel(cfg.KF, kfBot, dedup.Scoped(deduplicator, kfBot.Org()))
+		if err != nil {
+			fatal("微信客服渠道初始化失败", err)
+		}
+		kfHandler, err = wework.NewKFWebhookHandler(cfg.KF.Token, cfg.KF.AESKey, cfg.KF.CorpID, kfChannel.HandleEvent)
+		if err != nil {
+			fatal("微信客服回调处理器初始化失败", err)
+		}
+		kfHandler.OnDecryptFailure(decryptFailureHook("kf"))
+		kfHandler.SetMaxBodySize(int64(cfg.Server.MaxBodySize) << 10)
+		slog.Info("微信客服渠道", "bot", cfg.ChannelBot(cfg.KF.Bot))
+	}
+
+	// Slack渠道（Socket Mode主动连接，或通过Events API接收回调）
+	var slackAdapter *slack.Adapter
+	if cfg.Slack.Enabled {
+		slackBot := handlers[cfg.ChannelBot(cfg.Slack.Bot)]
+		slackAdapter, err = slack.NewAdapter(cfg.Slack, slackBot, dedup.Scoped(deduplicator, slackBot.Org()))
+		if err != nil {
+			fatal("Slack渠道初始化失败", err)
+		}
+		if slackAdapter.SocketMode() {
+			slackCtx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go slackAdapter.RunSocketMode(slackCtx)
+		}
+		slog.Info("Slack渠道", "bot", cfg.ChannelBot(cfg.Slack.Bot))
+	}
+
+	// Telegram渠道（长轮询，或配置webhook_url后通过Webhook接收更新）
+	var telegramAdapter *telegram.Adapter
+	if cfg.Telegram.Enabled {
+		telegramBot := handlers[cfg.ChannelBot(cfg.Telegram.Bot)]
+		telegramAdapter, err = telegram.NewAdapter(cfg.Telegram, telegramBot, dedup.Scoped(deduplicator, telegramBot.Org()))
+		if err != nil {
+			fatal("Telegram渠道初始化失败", err)
+		}
+		var commands []telegram.BotCommand
+		for _, c := range telegramBot.Commands() {
+			commands = append(commands, telegram.BotCommand{Command: strings.TrimPrefix(c.Name, "/"), Description: c.Description})
+		}
+		telegramAdapter.SetCommands(commands)
+		telegramCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if err := telegramAdapter.Start(telegramCtx); err != nil {
+			fatal("Telegram渠道启动失败", err)
+		}
+		slog.Info("Telegram渠道", "bot", cfg.ChannelBot(cfg.Telegram.Bot))
+	}
+
+	// 依赖健康检查（后台异步探测LLM、MCP服务器和本地存储）
+	healthChecker := health.NewChecker(
+		time.Duration(cfg.Health.Interval)*time.Second,
+		time.Duration(cfg.Health.Timeout)*time.Second,
+	)
+	healthChecker.SetProbes(buildHealthProbes(cfg))
+	healthChecker.Start()
+	defer healthChecker.Stop()
+
+	// 运行时配置（配置热更新、管理接口共用同一应用路径）
+	var cfgMutex sync.Mutex
+	currentCfg := cfg
+	applyConfig := func(newCfg *config.Config) {
+		cfgMutex.Lock()
+		defer cfgMutex.Unlock()
+		currentCfg = newCfg
+		if err := applog.SetLevel(newCfg.Logging.Level); err != nil {
+			slog.Warn("日志级别无效，保持当前级别", "err", err)
+		}
+		applyBotConfigs(handlers, newCfg)
+		healthChecker.SetProbes(buildHealthProbes(newCfg))
+	}
+
+	// 监听配置文件变更（热更新）
+	if watchConfig {
+		if _, statErr := os.Stat(configPath); statErr != nil {
+			slog.Warn("配置文件不存在，跳过热更新监听", "path", configPath)
+		} else if watcher, err := config.NewWatcher(configPath, applyConfig); err != nil {
+			slog.Warn("配置热更新启动失败", "err", err)
+		} else {
+			defer watcher.Close()
+			slog.Info("已启用配置热更新", "path", configPath)
+		}
+	}
+
+	// 创建Gin引擎
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Logger(), gin.Recovery())
+	r.Use(metrics.Middleware())
+
+	// 添加CORS中间件（可选）
+	r.Use(func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	})
+
+	// 路由配置
+	for i, b := range bots {
+		r.Any(b.Path, webhookHandlers[i].HandleWebhook) // 企业微信Webhook
+	}
+	if kfHandler != nil {
+		r.Any(cfg.KF.Path, kfHandler.HandleWebhook) // 微信客服回调
+	}
+	if slackAdapter != nil && !slackAdapter.SocketMode() {
+		r.POST(cfg.Slack.Path, slackAdapter.HandleEvents) // Slack Events API
+	}
+	if telegramAdapter != nil && telegramAdapter.Webhook() {
+		r.POST(cfg.Telegram.Path, telegramAdapter.HandleWebhook) // Telegram Webhook
+	}
+	r.GET(cfg.Server.HealthPath, healthChecker.Handler(func() gin.H { // 健康检查（含依赖状态）
+		activeTasks := 0
+		for _, h := range handlers {
+			activeTasks += h.GetActiveStreamCount()
+		}
+		return gin.H{
+			"service":      "AI-Body 企业微信智能机器人（Python流式模式）",
+			"version":      "1.0.0",
+			"active_tasks": activeTasks,
+		}
+	}))
+	r.GET(cfg.Server.HealthPath+"/live", health.LiveHandler)          // 存活探针
+	r.GET(cfg.Server.HealthPath+"/ready", healthChecker.ReadyHandler) // 就绪探针
+	r.GET("/metrics", metrics.Handler())                              // Prometheus指标
+
+	// 管理接口
+	if cfg.Admin.Enabled {
+		admin.NewServer(admin.Options{
+			Token: cfg.Admin.Token,
+			Bots:  handlers,
+			Config: func() *config.Config {
+				cfgMutex.Lock()
+				defer cfgMutex.Unlock()
+				return currentCfg
+			},
+			Apply: applyConfig,
+			Reload: func() error {
+				newCfg, err := config.LoadConfigFromFile(configPath)
+				if err != nil {
+					return err
+				}
+				applyConfig(newCfg)
+				return nil
+			},
+			Audit: auditLog,
+		}).Register(r.Group(cfg.Server.AdminPath()))
+	}
+
+	// 显示服务信息
+	baseURL := serverBaseURL(cfg.Server)
+	slog.Info("企业微信机器人服务启动", "url", baseURL)
+	for _, b := range bots {
+		slog.Info("Webhook地址", "bot", b.Name, "url", baseURL+b.Path)
+	}
+	if kfHandler != nil {
+		slog.Info("微信客服回调地址", "url", baseURL+cfg.KF.Path)
+	}
+	if slackAdapter != nil && !slackAdapter.SocketMode() {
+		slog.Info("Slack Events API地址", "url", baseURL+cfg.Slack.Path)
+	}
+	if telegramAdapter != nil && telegramAdapter.Webhook() {
+		slog.Info("Telegram Webhook地址", "url", baseURL+cfg.Telegram.Path)
+	}
+	slog.Info("健康检查（存活 /live，就绪 /ready）", "url", baseURL+cfg.Server.HealthPath)
+	slog.Info("监控指标", "url", baseURL+"/metrics")
+	if cfg.Admin.Enabled {
+		slog.Info("管理接口", "url", baseURL+cfg.Server.AdminPath())
+	}
+	if cfg.Server.TLS.Autocert.Enabled {
+		slog.Info("Let's Encrypt自动证书", "domains", cfg.Server.TLS.Autocert.Domains, "cache_dir", cfg.Server.TLS.Autocert.CacheDir)
+	} else if cfg.Server.TLS.Enabled() {
+		slog.Info("HTTPS证书", "cert_file", cfg.Server.TLS.CertFile)
+	}
+	slog.Info("服务已启动，等待企业微信消息", "default_llm", cfg.LLM.Default)
+
+	// 启动服务器
+	if err := runServer(cfg.Server, r); err != nil {
+		fatal("服务启动失败", err)
+	}
+}
+
+// applyBotConfigs 将热更新后的配置分发给各机器人（增删机器人或修改路由需重启服务）
+func applyBotConfigs(handlers map[string]*bot.BotHandler, newCfg *config.Config) {
+	seen := make(map[string]bool, len(handlers))
+	for _, b := range newCfg.BotConfigs() {
+		handler, ok := handlers[b.Name]
+		if !ok {
+			slog.Warn("新增机器人需要重启服务后生效", "bot", b.Name)
+			continue
+		}
+		seen[b.Name] = true
+		handler.ApplyConfig(newCfg.ForBot(b))
+	}
+	for name := range handlers {
+		if !seen[name] {
+			slog.Warn("机器人已从配置中移除，需要重启服务后生效", "bot", name)
+		}
+	}
+}
+
+// dedupBackendName 去重后端名称（用于启动信息）
+func dedupBackendName(cfg config.DedupConfig) string {
+	if cfg.Backend == "redis" {
+		return "Redis"
+	}
+	return "进程内"
+}
+
+// fatal 记录启动失败原因并退出进程
+func fatal(msg string, err error, args ...interface{}) {
+	slog.Error(msg, append(args, "err", err)...)
+	os.Exit(1)
+}
+
+// decryptFailureHook 回调验签/解密失败时计数并上报（source标识回调来源）
+func decryptFailureHook(source string) func(error) {
+	return func(err error) {
+		metrics.WebhookDecryptFailures.Inc()
+		errreport.Error(context.Background(), errreport.KindDecrypt, err, map[string]interface{}{"source": source})
+	}
+}
+
+// maskSecret 掩码敏感信息
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + "****" + secret[len(secret)-4:]
+}
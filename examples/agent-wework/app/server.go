@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// runServer 按配置以HTTP或HTTPS（证书文件/Let's Encrypt自动证书）启动服务
+func runServer(cfg config.ServerConfig, handler http.Handler) error {
+	server := newHTTPServer(cfg, cfg.Addr(), handler)
+	tls := cfg.TLS
+
+	switch {
+	case tls.Autocert.Enabled:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(tls.Autocert.Domains...),
+			Cache:      autocert.DirCache(tls.Autocert.CacheDir),
+			Email:      tls.Autocert.Email,
+		}
+
+		// HTTP端口用于HTTP-01验证，其余请求跳转到HTTPS
+		if tls.Autocert.HTTPPort != "off" {
+			challenge := newHTTPServer(cfg, net.JoinHostPort(cfg.Host, tls.Autocert.HTTPPort), manager.HTTPHandler(nil))
+			go func() {
+				if err := challenge.ListenAndServe(); err != nil {
+					slog.Warn("autocert HTTP验证端口监听失败", "err", err)
+				}
+			}()
+		}
+
+		server.TLSConfig = manager.TLSConfig()
+		return server.ListenAndServeTLS("", "")
+
+	case tls.CertFile != "":
+		return server.ListenAndServeTLS(tls.CertFile, tls.KeyFile)
+
+	default:
+		return server.ListenAndServe()
+	}
+}
+
+// newHTTPServer 按配置的超时和请求头上限创建HTTP服务器
+func newHTTPServer(cfg config.ServerConfig, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:           addr,
+		Handler:        handler,
+		ReadTimeout:    time.Duration(cfg.ReadTimeout) * time.Second,
+		WriteTimeout:   time.Duration(cfg.WriteTimeout) * time.Second,
+		IdleTimeout:    time.Duration(cfg.IdleTimeout) * time.Second,
+		MaxHeaderBytes: cfg.MaxHeaderSize << 10,
+	}
+}
+
+// serverBaseURL 生成启动信息中展示的服务地址
+func serverBaseURL(cfg config.ServerConfig) string {
+	if !cfg.TLS.Enabled() {
+		return "http://" + net.JoinHostPort(displayHost(cfg.Host), cfg.Port)
+	}
+
+	host := displayHost(cfg.Host)
+	if len(cfg.TLS.Autocert.Domains) > 0 {
+		host = cfg.TLS.Autocert.Domains[0]
+	}
+	if cfg.Port == "443" {
+		return "https://" + host
+	}
+	return fmt.Sprintf("https://%s", net.JoinHostPort(host, cfg.Port))
+}
+
+// displayHost 启动信息中展示的主机名（监听所有网卡时显示localhost）
+func displayHost(host string) string {
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		return "localhost"
+	}
+	return host
+}
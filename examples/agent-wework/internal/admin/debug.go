@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startTime 进程启动时间（运行时状态中计算运行时长）
+var startTime = time.Now()
+
+// runtimeStats 运行时状态
+type runtimeStats struct {
+	Uptime        string                `json:"uptime"`
+	GoVersion     string                `json:"go_version"`
+	NumCPU        int                   `json:"num_cpu"`
+	Goroutines    int                   `json:"goroutines"`
+	HeapAlloc     uint64                `json:"heap_alloc"`     // 堆上已分配且未释放的字节数
+	HeapInuse     uint64                `json:"heap_inuse"`     // 堆使用中的字节数
+	HeapObjects   uint64                `json:"heap_objects"`   // 堆对象数
+	Sys           uint64                `json:"sys"`            // 从操作系统获取的字节数
+	NumGC         uint32                `json:"num_gc"`         // 已完成的GC次数
+	LastGC        time.Time             `json:"last_gc"`        // 最近一次GC时间
+	PauseTotalMs  float64               `json:"pause_total_ms"` // GC累计停顿
+	ActiveStreams int                   `json:"active_streams"` // 所有机器人正在处理的任务数
+	Agents        int                   `json:"agents"`         // 所有机器人内存中的会话Agent数
+	Bots          map[string]botRuntime `json:"bots"`
+}
+
+// botRuntime 单个机器人的运行时状态
+type botRuntime struct {
+	Org           string `json:"org"` // 所属组织
+	ActiveStreams int    `json:"active_streams"`
+	Agents        int    `json:"agents"`
+}
+
+// registerDebug 注册运行时状态和pprof接口（与其他管理接口使用同一令牌）
+func (s *Server) registerDebug(group *gin.RouterGroup) {
+	group.GET("/runtime", s.runtime)
+	group.GET("/debug/pprof/*name", s.pprof)
+	group.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+}
+
+// runtime 运行时状态 GET /runtime（协程数、内存、各机器人的任务数和会话Agent数）
+func (s *Server) runtime(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := runtimeStats{
+		Uptime:       time.Since(startTime).Round(time.Second).String(),
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		PauseTotalMs: float64(mem.PauseTotalNs) / float64(time.Millisecond),
+		Bots:         make(map[string]botRuntime, len(s.options.Bots)),
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC))
+	}
+	for name, handler := range s.options.Bots {
+		usage := handler.Stats()
+		stats.Bots[name] = botRuntime{Org: handler.Org(), ActiveStreams: usage.ActiveTasks, Agents: usage.Conversations}
+		stats.ActiveStreams += usage.ActiveTasks
+		stats.Agents += usage.Conversations
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// pprof 性能分析 GET /debug/pprof/（索引）、/debug/pprof/profile?seconds=30、/debug/pprof/heap 等
+func (s *Server) pprof(c *gin.Context) {
+	switch name := strings.TrimPrefix(c.Param("name"), "/"); name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// 路由带有管理接口前缀，pprof.Index无法从路径识别名称，直接按名称处理
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
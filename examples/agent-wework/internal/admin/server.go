@@ -0,0 +1,553 @@
+package admin
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/analytics"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/audit"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/bot"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/scheduler"
+)
+
+// auditBodyLimit 审计日志中记录的请求体长度上限（字节）
+const auditBodyLimit = 64 * 1024
+
+// Options 管理接口依赖
+type Options struct {
+	Token  string                     // 访问令牌
+	Bots   map[string]*bot.BotHandler // 机器人名称 -> 处理器
+	Config func() *config.Config      // 获取当前生效配置
+	Apply  func(*config.Config)       // 应用新配置（与配置热更新同一路径）
+	Reload func() error               // 从配置文件重新加载
+	Audit  *audit.Log                 // 审计日志（未启用时为nil）
+}
+
+// Server 管理接口
+type Server struct {
+	options Options
+	mutex   sync.Mutex // 串行化运行时配置修改（MCP开关、重新加载）
+}
+
+// NewServer 创建管理接口
+func NewServer(options Options) *Server {
+	return &Server{options: options}
+}
+
+// Register 在路由组上注册管理接口（路由组需为独立前缀，如 /b0dy/admin）
+func (s *Server) Register(group *gin.RouterGroup) {
+	group.Use(s.authenticate, s.audit)
+
+	group.GET("/tasks", s.listTasks)
+	group.DELETE("/tasks/:id", s.cancelTask)
+	group.GET("/conversations", s.listConversations)
+	group.DELETE("/conversations/:id", s.evictConversation)
+	group.GET("/conversations/:id/messages", s.listMessages)
+	group.GET("/stats", s.stats)
+	group.GET("/analytics", s.analytics)
+	group.GET("/budget", s.listBudget)
+	group.DELETE("/budget/:id", s.resetBudget)
+	group.POST("/config/reload", s.reloadConfig)
+	group.GET("/mcp", s.listMCP)
+	group.PUT("/mcp/:name", s.toggleMCP)
+	group.GET("/schedules", s.listSchedules)
+	group.POST("/schedules/:name/run", s.runSchedule)
+	s.registerDebug(group)
+}
+
+// authenticate 校验 Authorization: Bearer <token>
+func (s *Server) authenticate(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if s.options.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.options.Token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	c.Next()
+}
+
+// audit 将修改类请求（非GET）记入审计日志
+func (s *Server) audit(c *gin.Context) {
+	if s.options.Audit == nil || c.Request.Method == http.MethodGet {
+		c.Next()
+		return
+	}
+
+	// 保留请求体供审计（读取后放回，不影响处理函数解析）
+	var body []byte
+	if c.Request.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(c.Request.Body, auditBodyLimit))
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	}
+	c.Next()
+
+	s.options.Audit.Record(audit.Entry{
+		Actor:  "admin",
+		Action: "admin_request",
+		Target: c.Request.Method + " " + c.Request.URL.Path,
+		Detail: map[string]interface{}{
+			"query":     c.Request.URL.RawQuery,
+			"body":      string(body),
+			"status":    c.Writer.Status(),
+			"client_ip": c.ClientIP(),
+		},
+	})
+}
+
+// selectedBots 按 ?bot= 过滤机器人（未指定时返回全部，按名称排序）
+func (s *Server) selectedBots(c *gin.Context) ([]string, bool) {
+	if name := c.Query("bot"); name != "" {
+		if _, ok := s.options.Bots[name]; !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("机器人 %s 不存在", name)})
+			return nil, false
+		}
+		return []string{name}, true
+	}
+
+	names := make([]string, 0, len(s.options.Bots))
+	for name := range s.options.Bots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+// listTasks 列出任务 GET /tasks?bot=&active=true
+func (s *Server) listTasks(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+	activeOnly := c.Query("active") == "true"
+
+	type item struct {
+		Bot string `json:"bot"`
+		bot.TaskSummary
+	}
+	items := []item{}
+	for _, name := range names {
+		for _, task := range s.options.Bots[name].Tasks() {
+			if activeOnly && task.Finished {
+				continue
+			}
+			items = append(items, item{Bot: name, TaskSummary: task})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"tasks": items})
+}
+
+// cancelTask 终止任务 DELETE /tasks/:id
+func (s *Server) cancelTask(c *gin.Context) {
+	id := c.Param("id")
+	for name, handler := range s.options.Bots {
+		if handler.CancelTask(id) {
+			slog.Info("管理接口终止任务", "stream_id", id, "bot", name)
+			c.JSON(http.StatusOK, gin.H{"cancelled": id, "bot": name})
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在或已结束"})
+}
+
+// listConversations 列出会话Agent GET /conversations?bot=
+func (s *Server) listConversations(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+
+	type item struct {
+		Bot string `json:"bot"`
+		bot.ConversationSummary
+	}
+	items := []item{}
+	for _, name := range names {
+		for _, conv := range s.options.Bots[name].Conversations() {
+			items = append(items, item{Bot: name, ConversationSummary: conv})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"conversations": items})
+}
+
+// evictConversation 移除会话Agent及记忆 DELETE /conversations/:id?bot=
+func (s *Server) evictConversation(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	var evicted []string
+	for _, name := range names {
+		if s.options.Bots[name].EvictConversation(id) {
+			evicted = append(evicted, name)
+		}
+	}
+	if len(evicted) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在"})
+		return
+	}
+	slog.Info("管理接口移除会话", "conversation_id", id, "bots", strings.Join(evicted, ", "))
+	c.JSON(http.StatusOK, gin.H{"evicted": id, "bots": evicted})
+}
+
+// 聊天记录分页参数
+const (
+	defaultMessageLimit = 50
+	maxMessageLimit     = 500
+)
+
+// listMessages 会话聊天记录 GET /conversations/:id/messages?bot=&from=&to=&limit=50&offset=0
+// from/to 为RFC3339时间或 2006-01-02 日期（to为日期时包含当天），多机器人时需指定bot
+func (s *Server) listMessages(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+	if len(names) > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "存在多个机器人，请通过 ?bot= 指定"})
+		return
+	}
+
+	var query bot.MessageQuery
+	var err error
+	if query.Since, err = parseQueryTime(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.Until, err = parseQueryTime(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.Limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultMessageLimit)))
+	if err != nil || query.Limit < 1 || query.Limit > maxMessageLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit 应为1-%d的整数", maxMessageLimit)})
+		return
+	}
+	query.Offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || query.Offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset 应为非负整数"})
+		return
+	}
+
+	handler := s.options.Bots[names[0]]
+	if !handler.ChatLogEnabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "聊天记录未启用（logging.enabled）"})
+		return
+	}
+	id := c.Param("id")
+	messages, total, err := handler.Messages(id, query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"bot":             names[0],
+		"conversation_id": id,
+		"total":           total,
+		"limit":           query.Limit,
+		"offset":          query.Offset,
+		"messages":        messages,
+	})
+}
+
+// parseQueryTime 解析RFC3339时间或日期（按本地时区），endOfDay时日期表示当天结束
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(analytics.DayLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("时间格式应为RFC3339或 %s: %s", analytics.DayLayout, value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// stats 使用统计 GET /stats
+func (s *Server) stats(c *gin.Context) {
+	stats := make(map[string]bot.UsageStats, len(s.options.Bots))
+	for name, handler := range s.options.Bots {
+		stats[name] = handler.Stats()
+	}
+	c.JSON(http.StatusOK, gin.H{"bots": stats})
+}
+
+// analytics 会话统计 GET /analytics?bot=&from=2006-01-02&to=2006-01-02&days=7&top=10
+// 未指定from时统计最近days天（默认7天，含今天）
+func (s *Server) analytics(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	to := c.DefaultQuery("to", now.Format(analytics.DayLayout))
+	from := c.Query("from")
+	if from == "" {
+		days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+		if err != nil || days < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days 应为正整数"})
+			return
+		}
+		from = now.AddDate(0, 0, 1-days).Format(analytics.DayLayout)
+	}
+	for _, day := range []string{from, to} {
+		if _, err := time.Parse(analytics.DayLayout, day); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("日期格式应为 %s: %s", analytics.DayLayout, day)})
+			return
+		}
+	}
+	top, err := strconv.Atoi(c.DefaultQuery("top", "10"))
+	if err != nil || top < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top 应为正整数"})
+		return
+	}
+
+	reports := make(map[string]*analytics.Report, len(names))
+	for _, name := range names {
+		report, err := s.options.Bots[name].Analytics(from, to, top)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("%s: %v", name, err)})
+			return
+		}
+		reports[name] = report
+	}
+	c.JSON(http.StatusOK, gin.H{"bots": reports})
+}
+
+// listBudget 当天的用量预算 GET /budget?bot=&exceeded=true
+func (s *Server) listBudget(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+	exceededOnly := c.Query("exceeded") == "true"
+
+	type item struct {
+		Bot string `json:"bot"`
+		bot.BudgetUsage
+	}
+	items := []item{}
+	var day string
+	enabled := false
+	for _, name := range names {
+		d, usage, ok := s.options.Bots[name].Budget()
+		if !ok {
+			continue
+		}
+		day, enabled = d, true
+		for _, u := range usage {
+			if exceededOnly && !u.Exceeded {
+				continue
+			}
+			items = append(items, item{Bot: name, BudgetUsage: u})
+		}
+	}
+	if !enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用量预算未启用"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"day": day, "conversations": items})
+}
+
+// resetBudget 清零会话当天的用量 DELETE /budget/:id?bot=
+func (s *Server) resetBudget(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	var reset []string
+	for _, name := range names {
+		done, err := s.options.Bots[name].ResetBudget(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", name, err)})
+			return
+		}
+		if done {
+			reset = append(reset, name)
+		}
+	}
+	if len(reset) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话今日没有用量"})
+		return
+	}
+	slog.Info("管理接口清零用量", "conversation_id", id, "bots", strings.Join(reset, ", "))
+	c.JSON(http.StatusOK, gin.H{"reset": id, "bots": reset})
+}
+
+// reloadConfig 从文件重新加载配置 POST /config/reload
+func (s *Server) reloadConfig(c *gin.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.options.Reload(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reloaded": true})
+}
+
+// mcpStatus MCP服务器状态
+type mcpStatus struct {
+	Bot     string `json:"bot,omitempty"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// listMCP 列出MCP服务器 GET /mcp?bot=
+// 指定机器人时列出该机器人可用的服务器及对其生效的启用状态
+func (s *Server) listMCP(c *gin.Context) {
+	cfg := s.options.Config()
+	servers := cfg.MCP.Servers
+	botName := c.Query("bot")
+	if botName != "" {
+		b, ok := s.botConfig(c, cfg, botName)
+		if !ok {
+			return
+		}
+		servers = cfg.ForBot(b).MCP.Servers
+	}
+
+	items := make([]mcpStatus, 0, len(servers))
+	for _, server := range servers {
+		items = append(items, mcpStatus{Bot: botName, Name: server.Name, Type: server.Type, Enabled: server.Enabled})
+	}
+	c.JSON(http.StatusOK, gin.H{"servers": items})
+}
+
+// botConfig 按名称查找机器人配置（不存在时返回404）
+func (s *Server) botConfig(c *gin.Context, cfg *config.Config, name string) (config.BotConfig, bool) {
+	for _, b := range cfg.BotConfigs() {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("机器人 %s 不存在", name)})
+	return config.BotConfig{}, false
+}
+
+// toggleMCP 启用/停用MCP服务器 PUT /mcp/:name?bot= {"enabled": true}
+// 未指定机器人时切换全局开关；指定时只对该机器人生效（写入其mcp_disabled/mcp_servers），全局停用的服务器不能按机器人启用。
+// 仅修改运行时配置，配置文件变更或重新加载后以文件为准
+func (s *Server) toggleMCP(c *gin.Context) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Enabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": `请求体应为 {"enabled": true|false}`})
+		return
+	}
+	enabled := *body.Enabled
+
+	// 读取-修改-应用期间持有锁，并发的切换或重新加载不会覆盖彼此的修改
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := s.options.Config()
+	name := c.Param("name")
+	index := -1
+	for i, server := range current.MCP.Servers {
+		if server.Name == name {
+			index = i
+		}
+	}
+	if index < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("MCP服务器 %s 不存在", name)})
+		return
+	}
+	server := current.MCP.Servers[index]
+	newCfg := *current
+
+	botName := c.Query("bot")
+	if botName != "" {
+		if _, ok := s.botConfig(c, current, botName); !ok {
+			return
+		}
+	}
+	if botName == "" || len(current.Bots) == 0 {
+		// 全局开关（单机器人模式下与按机器人切换相同）
+		newCfg.MCP.Servers = append([]config.MCPServerConfig(nil), current.MCP.Servers...)
+		newCfg.MCP.Servers[index].Enabled = enabled
+	} else {
+		if enabled && !server.Enabled {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("MCP服务器 %s 已全局停用，请先不指定bot启用", name)})
+			return
+		}
+		newCfg.Bots = append([]config.BotConfig(nil), current.Bots...)
+		for i := range newCfg.Bots {
+			if b := &newCfg.Bots[i]; b.Name == botName {
+				b.MCPDisabled = slices.DeleteFunc(slices.Clone(b.MCPDisabled), func(n string) bool { return n == name })
+				if !enabled {
+					b.MCPDisabled = append(b.MCPDisabled, name)
+				} else if len(b.MCPServers) > 0 && !slices.Contains(b.MCPServers, name) {
+					b.MCPServers = append(slices.Clone(b.MCPServers), name)
+				}
+			}
+		}
+	}
+
+	slog.Info("管理接口切换MCP服务器", "server", name, "bot", botName, "enabled", enabled)
+	s.options.Apply(&newCfg)
+	c.JSON(http.StatusOK, mcpStatus{Bot: botName, Name: name, Type: server.Type, Enabled: enabled})
+}
+
+// listSchedules 列出定时任务状态 GET /schedules?bot=
+func (s *Server) listSchedules(c *gin.Context) {
+	names, ok := s.selectedBots(c)
+	if !ok {
+		return
+	}
+
+	type item struct {
+		Bot string `json:"bot"`
+		scheduler.Status
+	}
+	items := []item{}
+	for _, name := range names {
+		for _, status := range s.options.Bots[name].Schedules() {
+			items = append(items, item{Bot: name, Status: status})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": items})
+}
+
+// runSchedule 立即执行定时任务 POST /schedules/:name/run（在后台执行，结果通过主动通知推送）
+func (s *Server) runSchedule(c *gin.Context) {
+	name := c.Param("name")
+	for botName, handler := range s.options.Bots {
+		for _, status := range handler.Schedules() {
+			if status.Name != name {
+				continue
+			}
+			if err := handler.RunScheduleNow(name); err != nil {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			slog.Info("管理接口触发定时任务", "job", name, "bot", botName)
+			c.JSON(http.StatusAccepted, gin.H{"started": name, "bot": botName})
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("定时任务 %s 不存在", name)})
+}
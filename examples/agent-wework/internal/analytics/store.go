@@ -0,0 +1,364 @@
+package analytics
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite驱动
+)
+
+// DayLayout 统计日期格式
+const DayLayout = "2006-01-02"
+
+// schema 建表语句
+const schema = `
+CREATE TABLE IF NOT EXISTS messages (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	day             TEXT    NOT NULL,
+	time            INTEGER NOT NULL,
+	conversation_id TEXT    NOT NULL,
+	user_id         TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_messages_day ON messages(day);
+
+CREATE TABLE IF NOT EXISTS turns (
+	stream_id       TEXT    PRIMARY KEY,
+	day             TEXT    NOT NULL,
+	time            INTEGER NOT NULL,
+	conversation_id TEXT    NOT NULL,
+	intent          TEXT    NOT NULL DEFAULT '',
+	variant         TEXT    NOT NULL DEFAULT '',
+	tool_calls      INTEGER NOT NULL DEFAULT 0,
+	latency_ms      INTEGER NOT NULL DEFAULT 0,
+	failed          INTEGER NOT NULL DEFAULT 0,
+	handoff         INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_turns_day ON turns(day);
+CREATE INDEX IF NOT EXISTS idx_turns_conversation ON turns(conversation_id, day);
+
+CREATE TABLE IF NOT EXISTS tool_calls (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	day             TEXT    NOT NULL,
+	time            INTEGER NOT NULL,
+	conversation_id TEXT    NOT NULL,
+	tool            TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_calls_day ON tool_calls(day);
+`
+
+// migrations 为旧版本数据库补充的列（列已存在时跳过）
+var migrations = []struct {
+	table, column, definition string
+}{
+	{"turns", "variant", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// Turn 一次回复的统计记录
+type Turn struct {
+	StreamID       string
+	ConversationID string
+	Variant        string // A/B实验变体（未参与实验时为空）
+	ToolCalls      int
+	Latency        time.Duration
+	Failed         bool
+	Time           time.Time
+}
+
+// DayStats 单日统计
+type DayStats struct {
+	Day            string  `json:"day,omitempty"`
+	Messages       int     `json:"messages"`        // 用户消息数
+	Users          int     `json:"users"`           // 独立用户数
+	Turns          int     `json:"turns"`           // AI回复数
+	ToolCalls      int     `json:"tool_calls"`      // 工具调用次数
+	AvgLatencyMs   int64   `json:"avg_latency_ms"`  // 平均回复耗时
+	Resolved       int     `json:"resolved"`        // 未出错且未转人工的回复数
+	ResolutionRate float64 `json:"resolution_rate"` // 解决率（Resolved/Turns）
+}
+
+// VariantStats A/B实验单个变体的统计
+type VariantStats struct {
+	Name           string  `json:"name"`
+	Conversations  int     `json:"conversations"`   // 会话数
+	Turns          int     `json:"turns"`           // AI回复数
+	ToolCalls      int     `json:"tool_calls"`      // 工具调用次数
+	AvgLatencyMs   int64   `json:"avg_latency_ms"`  // 平均回复耗时
+	Failed         int     `json:"failed"`          // 出错的回复数
+	Handoffs       int     `json:"handoffs"`        // 当天转人工的回复数
+	Resolved       int     `json:"resolved"`        // 未出错且未转人工的回复数
+	ResolutionRate float64 `json:"resolution_rate"` // 解决率（Resolved/Turns）
+}
+
+// Count 分项计数
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Report 时间范围内的统计报表
+type Report struct {
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Total      DayStats       `json:"total"` // 汇总（Day为空，Users为范围内去重用户数）
+	Days       []DayStats     `json:"days"`
+	TopIntents []Count        `json:"top_intents"`
+	TopTools   []Count        `json:"top_tools"`
+	Variants   []VariantStats `json:"variants"` // A/B实验各变体对比（未开展实验时为空）
+}
+
+// Store 会话统计存储（SQLite）
+type Store struct {
+	db *sql.DB
+}
+
+// Open 打开（或创建）统计数据库
+func Open(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("创建统计数据目录失败: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("打开统计数据库失败: %w", err)
+	}
+	// SQLite同一时间只允许一个写入者
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化统计数据库失败: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("升级统计数据库失败: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// migrate 为旧版本数据库补充新增的列
+func migrate(db *sql.DB) error {
+	for _, m := range migrations {
+		var exists int
+		err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, m.table, m.column).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE ` + m.table + ` ADD COLUMN ` + m.column + ` ` + m.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 关闭数据库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// RecordMessage 记录用户消息
+func (s *Store) RecordMessage(conversationID, userID string, t time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO messages (day, time, conversation_id, user_id) VALUES (?, ?, ?, ?)`,
+		t.Format(DayLayout), t.Unix(), conversationID, userID)
+	if err != nil {
+		return fmt.Errorf("记录消息统计失败: %w", err)
+	}
+	return nil
+}
+
+// RecordTurn 记录一次回复
+func (s *Store) RecordTurn(turn Turn) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO turns (stream_id, day, time, conversation_id, variant, tool_calls, latency_ms, failed)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		turn.StreamID, turn.Time.Format(DayLayout), turn.Time.Unix(), turn.ConversationID, turn.Variant,
+		turn.ToolCalls, turn.Latency.Milliseconds(), turn.Failed)
+	if err != nil {
+		return fmt.Errorf("记录回复统计失败: %w", err)
+	}
+	return nil
+}
+
+// SetIntent 记录回复对应问题的意图
+func (s *Store) SetIntent(streamID, intent string) error {
+	if _, err := s.db.Exec(`UPDATE turns SET intent = ? WHERE stream_id = ?`, intent, streamID); err != nil {
+		return fmt.Errorf("记录意图失败: %w", err)
+	}
+	return nil
+}
+
+// RecordTool 记录工具调用
+func (s *Store) RecordTool(conversationID, tool string, t time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO tool_calls (day, time, conversation_id, tool) VALUES (?, ?, ?, ?)`,
+		t.Format(DayLayout), t.Unix(), conversationID, tool)
+	if err != nil {
+		return fmt.Errorf("记录工具调用统计失败: %w", err)
+	}
+	return nil
+}
+
+// MarkHandoff 会话转人工：当天该会话的回复计为未解决
+func (s *Store) MarkHandoff(conversationID string, t time.Time) error {
+	_, err := s.db.Exec(`UPDATE turns SET handoff = 1 WHERE conversation_id = ? AND day = ?`,
+		conversationID, t.Format(DayLayout))
+	if err != nil {
+		return fmt.Errorf("记录转人工统计失败: %w", err)
+	}
+	return nil
+}
+
+// Prune 删除指定日期之前的统计
+func (s *Store) Prune(before time.Time) error {
+	day := before.Format(DayLayout)
+	for _, table := range []string{"messages", "turns", "tool_calls"} {
+		if _, err := s.db.Exec(`DELETE FROM `+table+` WHERE day < ?`, day); err != nil {
+			return fmt.Errorf("清理统计数据失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Report 查询[from, to]日期范围内的统计（日期格式 2006-01-02），top为意图和工具排行的条数
+func (s *Store) Report(from, to string, top int) (*Report, error) {
+	report := &Report{From: from, To: to, Days: []DayStats{}}
+	days := make(map[string]*DayStats)
+	day := func(name string) *DayStats {
+		if d, ok := days[name]; ok {
+			return d
+		}
+		d := &DayStats{Day: name}
+		days[name] = d
+		return d
+	}
+
+	rows, err := s.db.Query(`SELECT day, COUNT(*), COUNT(DISTINCT user_id) FROM messages
+		WHERE day BETWEEN ? AND ? GROUP BY day`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("查询消息统计失败: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var messages, users int
+		if err := rows.Scan(&name, &messages, &users); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取消息统计失败: %w", err)
+		}
+		d := day(name)
+		d.Messages, d.Users = messages, users
+	}
+	rows.Close()
+
+	rows, err = s.db.Query(`SELECT day, COUNT(*), SUM(tool_calls), SUM(latency_ms),
+		SUM(CASE WHEN failed = 0 AND handoff = 0 THEN 1 ELSE 0 END)
+		FROM turns WHERE day BETWEEN ? AND ? GROUP BY day`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("查询回复统计失败: %w", err)
+	}
+	latency := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var turns, tools, resolved int
+		var totalLatency int64
+		if err := rows.Scan(&name, &turns, &tools, &totalLatency, &resolved); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取回复统计失败: %w", err)
+		}
+		d := day(name)
+		d.Turns, d.ToolCalls, d.Resolved = turns, tools, resolved
+		latency[name] = totalLatency
+	}
+	rows.Close()
+
+	names := make([]string, 0, len(days))
+	for name := range days {
+		names = append(names, name)
+	}
+	sort.Strings(names) // 日期格式按字典序即时间顺序
+	var totalLatency int64
+	for _, name := range names {
+		d := days[name]
+		if d.Turns > 0 {
+			d.AvgLatencyMs = latency[name] / int64(d.Turns)
+			d.ResolutionRate = float64(d.Resolved) / float64(d.Turns)
+		}
+		report.Days = append(report.Days, *d)
+
+		report.Total.Messages += d.Messages
+		report.Total.Turns += d.Turns
+		report.Total.ToolCalls += d.ToolCalls
+		report.Total.Resolved += d.Resolved
+		totalLatency += latency[name]
+	}
+	if report.Total.Turns > 0 {
+		report.Total.AvgLatencyMs = totalLatency / int64(report.Total.Turns)
+		report.Total.ResolutionRate = float64(report.Total.Resolved) / float64(report.Total.Turns)
+	}
+	err = s.db.QueryRow(`SELECT COUNT(DISTINCT user_id) FROM messages WHERE day BETWEEN ? AND ?`, from, to).
+		Scan(&report.Total.Users)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户统计失败: %w", err)
+	}
+
+	if report.TopIntents, err = s.counts(`SELECT intent, COUNT(*) AS n FROM turns
+		WHERE day BETWEEN ? AND ? AND intent != '' GROUP BY intent ORDER BY n DESC, intent LIMIT ?`, from, to, top); err != nil {
+		return nil, fmt.Errorf("查询意图排行失败: %w", err)
+	}
+	if report.TopTools, err = s.counts(`SELECT tool, COUNT(*) AS n FROM tool_calls
+		WHERE day BETWEEN ? AND ? GROUP BY tool ORDER BY n DESC, tool LIMIT ?`, from, to, top); err != nil {
+		return nil, fmt.Errorf("查询工具排行失败: %w", err)
+	}
+	if report.Variants, err = s.variants(from, to); err != nil {
+		return nil, fmt.Errorf("查询实验变体统计失败: %w", err)
+	}
+	return report, nil
+}
+
+// variants 按A/B实验变体汇总回复统计
+func (s *Store) variants(from, to string) ([]VariantStats, error) {
+	rows, err := s.db.Query(`SELECT variant, COUNT(DISTINCT conversation_id), COUNT(*), SUM(tool_calls), SUM(latency_ms),
+		SUM(failed), SUM(handoff), SUM(CASE WHEN failed = 0 AND handoff = 0 THEN 1 ELSE 0 END)
+		FROM turns WHERE day BETWEEN ? AND ? AND variant != '' GROUP BY variant ORDER BY variant`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []VariantStats{}
+	for rows.Next() {
+		var v VariantStats
+		var totalLatency int64
+		if err := rows.Scan(&v.Name, &v.Conversations, &v.Turns, &v.ToolCalls, &totalLatency, &v.Failed, &v.Handoffs, &v.Resolved); err != nil {
+			return nil, err
+		}
+		if v.Turns > 0 {
+			v.AvgLatencyMs = totalLatency / int64(v.Turns)
+			v.ResolutionRate = float64(v.Resolved) / float64(v.Turns)
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+// counts 执行分项计数查询
+func (s *Store) counts(query string, args ...interface{}) ([]Count, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []Count{}
+	for rows.Next() {
+		var c Count
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
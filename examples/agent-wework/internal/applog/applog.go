@@ -0,0 +1,75 @@
+// Package applog 应用日志：基于log/slog的分级结构化日志
+//
+// 各模块直接使用 slog.Info/Warn/Error/Debug 输出，级别和格式由 logging 配置决定，
+// 启动时调用 Setup，配置热更新时调用 SetLevel。处理一条消息时使用带请求ID的上下文
+// 输出日志（slog.*Context），即可按 request_id 关联同一请求的全部日志。
+package applog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level 当前日志级别（支持热更新）
+var level = new(slog.LevelVar)
+
+// ParseLevel 解析日志级别：debug、info、warn、error（为空时为info）
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("日志级别无效: %s（支持debug/info/warn/error）", s)
+}
+
+// Setup 按级别和格式（text或json）设置默认日志输出到标准输出
+func Setup(levelName, format string) error {
+	return SetupWriter(os.Stdout, levelName, format)
+}
+
+// SetupWriter 按级别和格式设置默认日志输出到w
+func SetupWriter(w io.Writer, levelName, format string) error {
+	if err := SetLevel(levelName); err != nil {
+		return err
+	}
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, options)
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("日志格式无效: %s（支持text/json）", format)
+	}
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// SetLevel 修改日志级别（立即生效）
+func SetLevel(levelName string) error {
+	l, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
+// Logf 按格式输出调试日志，用于只接受printf风格回调的组件（如会话级MCP连接管理器）
+func Logf(format string, args ...interface{}) {
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		slog.Debug(fmt.Sprintf(format, args...))
+	}
+}
@@ -0,0 +1,53 @@
+package applog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
+)
+
+// requestIDKey 上下文中请求ID的键
+type requestIDKey struct{}
+
+// WithRequestID 在上下文中记录请求ID，之后使用该上下文输出的日志（slog.*Context）都会带上request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 获取上下文中的请求ID（没有时为空）
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler 从上下文中取出请求ID和组织ID加入日志属性
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	if ctx != nil {
+		if orgID, err := multitenancy.GetOrgID(ctx); err == nil {
+			r.AddAttrs(slog.String("org_id", orgID))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,49 @@
+package applog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/logging"
+)
+
+// sdkLogger 将agent-sdk-go的日志转发到slog，与应用日志使用相同的级别和格式
+type sdkLogger struct{}
+
+// SDK 返回供agent-sdk-go使用的日志记录器
+func SDK() logging.Logger {
+	return sdkLogger{}
+}
+
+func (sdkLogger) Info(ctx context.Context, msg string, fields map[string]interface{}) {
+	log(ctx, slog.LevelInfo, msg, fields)
+}
+
+func (sdkLogger) Warn(ctx context.Context, msg string, fields map[string]interface{}) {
+	log(ctx, slog.LevelWarn, msg, fields)
+}
+
+func (sdkLogger) Error(ctx context.Context, msg string, fields map[string]interface{}) {
+	log(ctx, slog.LevelError, msg, fields)
+}
+
+func (sdkLogger) Debug(ctx context.Context, msg string, fields map[string]interface{}) {
+	log(ctx, slog.LevelDebug, msg, fields)
+}
+
+// log 输出一条SDK日志（字段转为slog属性，标记来源为sdk）
+func log(ctx context.Context, l slog.Level, msg string, fields map[string]interface{}) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := slog.Default()
+	if !logger.Enabled(ctx, l) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, len(fields)+1)
+	attrs = append(attrs, slog.String("source", "sdk"))
+	for k, v := range fields {
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	logger.LogAttrs(ctx, l, msg, attrs...)
+}
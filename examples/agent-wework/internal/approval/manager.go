@@ -0,0 +1,163 @@
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Request 工具调用审批请求
+type Request struct {
+	ID             string    `json:"id"`
+	StreamID       string    `json:"stream_id,omitempty"` // 发起调用的回复（审批卡片附在该回复下方）
+	ConversationID string    `json:"conversation_id"`
+	UserID         string    `json:"user_id"` // 发起对话的用户
+	Tool           string    `json:"tool"`
+	Arguments      string    `json:"arguments"`
+	Approvers      []string  `json:"approvers,omitempty"` // 审批人（为空时由发起对话的用户确认）
+	Created        time.Time `json:"created"`
+}
+
+// CanDecide 用户是否可以审批该请求
+func (r Request) CanDecide(userID string) bool {
+	if len(r.Approvers) == 0 {
+		return userID == r.UserID
+	}
+	return slices.Contains(r.Approvers, userID)
+}
+
+// Decision 审批结果
+type Decision struct {
+	Approved bool
+	By       string // 审批人（超时为空）
+	Time     time.Time
+}
+
+// pending 等待中的审批
+type pending struct {
+	request  Request
+	decision chan Decision
+	carded   bool // 审批卡片已发送
+}
+
+// Manager 工具调用审批：Request阻塞直到审批人决定、超时或ctx结束
+type Manager struct {
+	tools   []string // 需要审批的工具（支持通配符，如 delete_*）
+	timeout time.Duration
+	pending map[string]*pending
+	mutex   sync.Mutex
+}
+
+// NewManager 创建审批管理器
+func NewManager(tools []string, timeout time.Duration) *Manager {
+	return &Manager{
+		tools:   tools,
+		timeout: timeout,
+		pending: make(map[string]*pending),
+	}
+}
+
+// Requires 工具调用是否需要审批
+func (m *Manager) Requires(tool string) bool {
+	for _, pattern := range m.tools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Request 提交审批并等待结果，超时视为拒绝；onCreated在请求登记后调用（用于发送审批通知）
+func (m *Manager) Request(ctx context.Context, req Request, onCreated func(Request)) (Decision, error) {
+	id, err := newID()
+	if err != nil {
+		return Decision{}, err
+	}
+	req.ID = id
+	req.Created = time.Now()
+
+	p := &pending{request: req, decision: make(chan Decision, 1)}
+	m.mutex.Lock()
+	m.pending[id] = p
+	m.mutex.Unlock()
+	defer func() {
+		m.mutex.Lock()
+		delete(m.pending, id)
+		m.mutex.Unlock()
+	}()
+
+	if onCreated != nil {
+		onCreated(req)
+	}
+
+	timer := time.NewTimer(m.timeout)
+	defer timer.Stop()
+	select {
+	case d := <-p.decision:
+		return d, nil
+	case <-timer.C:
+		return Decision{Approved: false, Time: time.Now()}, nil
+	case <-ctx.Done():
+		return Decision{}, ctx.Err()
+	}
+}
+
+// Decide 审批请求，请求不存在（已处理或超时）或用户无权审批时返回错误
+func (m *Manager) Decide(id, userID string, approved bool) (Request, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	p, ok := m.pending[id]
+	if !ok {
+		return Request{}, fmt.Errorf("审批请求 %s 不存在或已处理", id)
+	}
+	if !p.request.CanDecide(userID) {
+		return p.request, fmt.Errorf("您没有审批该请求的权限")
+	}
+
+	delete(m.pending, id)
+	p.decision <- Decision{Approved: approved, By: userID, Time: time.Now()}
+	return p.request, nil
+}
+
+// TakeCard 返回附在指定回复上、尚未发送卡片的审批（每个请求只返回一次）
+func (m *Manager) TakeCard(streamID string) (Request, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, p := range m.pending {
+		if p.request.StreamID == streamID && !p.carded {
+			p.carded = true
+			return p.request, true
+		}
+	}
+	return Request{}, false
+}
+
+// List 列出等待中的审批（按提交时间排序）
+func (m *Manager) List() []Request {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	list := make([]Request, 0, len(m.pending))
+	for _, p := range m.pending {
+		list = append(list, p.request)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+	return list
+}
+
+// newID 生成审批编号
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成审批编号失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+)
+
+// Subscribe 订阅机器人事件总线，记录工具调用、发出的回复和通知、审批、转人工和配置热更新
+func Subscribe(l *Log, bot string, bus *events.Bus) {
+	events.Subscribe(bus, func(e events.ToolCalled) {
+		l.Record(Entry{
+			Actor:  "agent",
+			Action: "tool_call",
+			Bot:    bot,
+			Target: e.ConversationID,
+			Detail: map[string]string{"stream_id": e.StreamID, "tool": e.Tool, "arguments": e.Arguments},
+			Time:   e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.ToolApproval) {
+		actor := "system" // 超时
+		if e.By != "" {
+			actor = "user:" + e.By
+		}
+		l.Record(Entry{
+			Actor:  actor,
+			Action: "tool_approval",
+			Bot:    bot,
+			Target: e.ConversationID,
+			Detail: map[string]string{
+				"request_id": e.RequestID,
+				"requester":  e.UserID,
+				"tool":       e.Tool,
+				"arguments":  e.Arguments,
+				"result":     e.Result,
+			},
+			Time: e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.TurnFinished) {
+		detail := map[string]interface{}{"stream_id": e.StreamID, "content": e.Answer, "tool_calls": e.ToolCalls}
+		if e.Err != nil {
+			detail["error"] = e.Err.Error()
+		}
+		if e.Variant != "" {
+			detail["variant"] = e.Variant
+		}
+		l.Record(Entry{
+			Actor:  "agent",
+			Action: "message_sent",
+			Bot:    bot,
+			Target: e.ConversationID,
+			Detail: detail,
+			Time:   e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.NotificationSent) {
+		l.Record(Entry{
+			Actor:  "agent",
+			Action: "notification_sent",
+			Bot:    bot,
+			Target: e.Target,
+			Detail: map[string]string{"category": e.Category, "title": e.Title, "content": e.Content},
+			Time:   e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.HandoffStarted) {
+		l.Record(Entry{
+			Actor:  "user:" + e.UserID,
+			Action: "handoff_started",
+			Bot:    bot,
+			Target: e.ConversationID,
+			Detail: map[string]interface{}{"ticket": e.Ticket, "reason": e.Reason},
+			Time:   e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.HandoffReleased) {
+		l.Record(Entry{
+			Actor:  "user:" + e.Agent,
+			Action: "handoff_released",
+			Bot:    bot,
+			Target: e.ConversationID,
+			Detail: map[string]interface{}{"ticket": e.Ticket, "duration_ms": e.Duration.Milliseconds()},
+			Time:   e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.ConfigReloaded) {
+		l.Record(Entry{
+			Actor:  "system",
+			Action: "config_reload",
+			Bot:    bot,
+			Detail: map[string][]string{"changes": e.Changes, "restart_required": e.Restart},
+			Time:   e.Time,
+		})
+	})
+}
@@ -0,0 +1,256 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// queueSize 审计写入队列长度（队列满时等待写入，不丢弃记录）
+const queueSize = 1024
+
+// maxLineSize 单条审计记录的最大长度（回复内容较长时记录也较长）
+const maxLineSize = 16 * 1024 * 1024
+
+// Entry 待记录的对外操作
+type Entry struct {
+	Actor  string      // 操作者：user:<id>、agent、admin 或 system
+	Action string      // 操作类型，如 tool_call、message_sent、config_reload、admin_request
+	Bot    string      // 所属机器人（进程级操作为空）
+	Target string      // 操作对象，如会话ID、接口路径
+	Detail interface{} // 操作详情（序列化为JSON）
+	Time   time.Time   // 操作时间（为空时取当前时间）
+}
+
+// Record 审计记录：每条记录包含上一条记录的哈希，任意修改、删除或插入都会使后续哈希校验失败
+type Record struct {
+	Seq      int64           `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Actor    string          `json:"actor"`
+	Action   string          `json:"action"`
+	Bot      string          `json:"bot,omitempty"`
+	Target   string          `json:"target,omitempty"`
+	Detail   json.RawMessage `json:"detail,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// digest 计算记录哈希（覆盖除hash外的全部字段）
+func (r *Record) digest() string {
+	h := sha256.New()
+	for _, field := range []string{
+		strconv.FormatInt(r.Seq, 10),
+		r.Time.UTC().Format(time.RFC3339Nano),
+		r.Actor,
+		r.Action,
+		r.Bot,
+		r.Target,
+		string(r.Detail),
+		r.PrevHash,
+	} {
+		// 字段长度前缀，避免字段边界被移动后哈希不变
+		fmt.Fprintf(h, "%d:%s\n", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Log 只追加的审计日志（JSON Lines，哈希链防篡改），与聊天记录分开存放
+type Log struct {
+	file     *os.File
+	seq      int64
+	lastHash string
+	queue    chan Entry
+	done     chan struct{}
+	closed   bool
+	mutex    sync.RWMutex // 保护closed，避免关闭后继续写入队列
+}
+
+// Open 打开审计日志，从最后一条记录继续哈希链
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建审计日志目录失败: %w", err)
+	}
+
+	l := &Log{
+		queue: make(chan Entry, queueSize),
+		done:  make(chan struct{}),
+	}
+	last, err := lastRecord(path)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		l.seq = last.Seq
+		l.lastHash = last.Hash
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("打开审计日志失败: %w", err)
+	}
+	l.file = file
+
+	go l.run()
+	return l, nil
+}
+
+// Record 记录一次对外操作（异步写入；l为nil时忽略，便于未启用审计时直接调用）
+func (l *Log) Record(e Entry) {
+	if l == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if l.closed {
+		slog.Warn("审计日志已关闭，丢弃记录", "actor", e.Actor, "action", e.Action)
+		return
+	}
+	l.queue <- e
+}
+
+// run 按顺序写入审计记录
+func (l *Log) run() {
+	defer close(l.done)
+	for e := range l.queue {
+		if err := l.write(e); err != nil {
+			slog.Error("写入审计日志失败", "actor", e.Actor, "action", e.Action, "err", err)
+		}
+	}
+}
+
+// write 追加一条记录并推进哈希链
+func (l *Log) write(e Entry) error {
+	r := Record{
+		Seq:      l.seq + 1,
+		Time:     e.Time.UTC(),
+		Actor:    e.Actor,
+		Action:   e.Action,
+		Bot:      e.Bot,
+		Target:   e.Target,
+		PrevHash: l.lastHash,
+	}
+	if e.Detail != nil {
+		detail, err := json.Marshal(e.Detail)
+		if err != nil {
+			return fmt.Errorf("序列化审计详情失败: %w", err)
+		}
+		r.Detail = detail
+	}
+	r.Hash = r.digest()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %w", err)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	l.seq = r.Seq
+	l.lastHash = r.Hash
+	return nil
+}
+
+// Close 写完队列中的记录后关闭审计日志
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.queue)
+	l.mutex.Unlock()
+
+	<-l.done
+	if err := l.file.Sync(); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// lastRecord 读取审计日志的最后一条记录（文件不存在或为空时返回nil）
+func lastRecord(path string) (*Record, error) {
+	var last *Record
+	err := scan(path, func(r *Record) error {
+		last = r
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return last, err
+}
+
+// scan 逐条读取审计记录
+func scan(path string, fn func(*Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewScanner(file)
+	reader.Buffer(make([]byte, 64*1024), maxLineSize)
+	line := 0
+	for reader.Scan() {
+		line++
+		if len(reader.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(reader.Bytes(), &r); err != nil {
+			return fmt.Errorf("审计日志第%d行格式错误: %w", line, err)
+		}
+		if err := fn(&r); err != nil {
+			return err
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return fmt.Errorf("读取审计日志失败: %w", err)
+	}
+	return nil
+}
+
+// Verify 校验审计日志的哈希链，返回校验通过的记录数；发现篡改时返回第一处异常
+func Verify(path string) (int, error) {
+	count := 0
+	var prev *Record
+	err := scan(path, func(r *Record) error {
+		switch {
+		case prev == nil && (r.Seq != 1 || r.PrevHash != ""):
+			return fmt.Errorf("第%d条记录不是日志开头，之前的记录可能已被删除", r.Seq)
+		case prev != nil && r.Seq != prev.Seq+1:
+			return fmt.Errorf("记录序号不连续: %d 之后是 %d", prev.Seq, r.Seq)
+		case prev != nil && r.PrevHash != prev.Hash:
+			return fmt.Errorf("第%d条记录的prev_hash与上一条记录不符", r.Seq)
+		case r.Hash != r.digest():
+			return fmt.Errorf("第%d条记录的哈希校验失败，内容已被修改", r.Seq)
+		}
+		prev = r
+		count++
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, fmt.Errorf("审计日志不存在: %s", path)
+		}
+		return count, err
+	}
+	return count, nil
+}
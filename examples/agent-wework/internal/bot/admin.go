@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+)
+
+// TaskSummary 任务概要（管理接口）
+type TaskSummary struct {
+	StreamID       string    `json:"stream_id"`
+	ConversationID string    `json:"conversation_id"`
+	Question       string    `json:"question"`
+	CreatedTime    time.Time `json:"created_time"`
+	LastUpdate     time.Time `json:"last_update"`
+	Processing     bool      `json:"processing"`
+	Finished       bool      `json:"finished"`
+}
+
+// ConversationSummary 会话Agent概要（管理接口）
+type ConversationSummary struct {
+	ConversationID string    `json:"conversation_id"`
+	LastActivity   time.Time `json:"last_activity"`
+	Stale          bool      `json:"stale"` // 配置已变更，下次消息时重建
+}
+
+// ChatMessage 聊天记录中的一条消息（管理接口）
+type ChatMessage struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	Sender    string    `json:"sender"` // 用户ID，AI回复为 AI（A/B实验中为 AI@<变体>），会话摘要为 SUMMARY
+	Content   string    `json:"content"`
+}
+
+// UsageStats 使用统计（进程启动以来）
+type UsageStats struct {
+	Since         time.Time `json:"since"`
+	Messages      int64     `json:"messages"`       // 收到的用户消息
+	Turns         int64     `json:"turns"`          // 完成的回复
+	Errors        int64     `json:"errors"`         // 失败的回复
+	ToolCalls     int64     `json:"tool_calls"`     // 工具调用次数
+	AvgLatencyMs  int64     `json:"avg_latency_ms"` // 平均回复耗时
+	Users         int       `json:"users"`          // 独立用户数
+	ActiveTasks   int       `json:"active_tasks"`
+	Conversations int       `json:"conversations"` // 内存中的会话Agent数
+}
+
+// usageCounter 订阅事件总线累计使用统计
+type usageCounter struct {
+	since    time.Time
+	messages int64
+	turns    int64
+	errors   int64
+	tools    int64
+	duration time.Duration
+	users    map[string]bool
+	mutex    sync.Mutex
+}
+
+// newUsageCounter 创建使用统计并订阅事件
+func newUsageCounter(bus *events.Bus) *usageCounter {
+	u := &usageCounter{since: time.Now(), users: make(map[string]bool)}
+
+	events.Subscribe(bus, func(e events.MessageReceived) {
+		u.mutex.Lock()
+		defer u.mutex.Unlock()
+		u.messages++
+		u.users[e.UserID] = true
+	})
+	events.Subscribe(bus, func(e events.TurnFinished) {
+		u.mutex.Lock()
+		defer u.mutex.Unlock()
+		u.turns++
+		u.tools += int64(e.ToolCalls)
+		u.duration += e.Duration
+		if e.Err != nil {
+			u.errors++
+		}
+	})
+	return u
+}
+
+// snapshot 获取当前统计
+func (u *usageCounter) snapshot() UsageStats {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	stats := UsageStats{
+		Since:     u.since,
+		Messages:  u.messages,
+		Turns:     u.turns,
+		Errors:    u.errors,
+		ToolCalls: u.tools,
+		Users:     len(u.users),
+	}
+	if u.turns > 0 {
+		stats.AvgLatencyMs = (u.duration / time.Duration(u.turns)).Milliseconds()
+	}
+	return stats
+}
+
+// Tasks 列出内存中的任务（按创建时间倒序）
+func (b *BotHandler) Tasks() []TaskSummary {
+	b.taskCache.mutex.RLock()
+	defer b.taskCache.mutex.RUnlock()
+
+	tasks := make([]TaskSummary, 0, len(b.taskCache.tasks))
+	for _, task := range b.taskCache.tasks {
+		task.mutex.RLock()
+		tasks = append(tasks, TaskSummary{
+			StreamID:       task.StreamID,
+			ConversationID: task.ConversationID,
+			Question:       task.Question,
+			CreatedTime:    task.CreatedTime,
+			LastUpdate:     task.LastUpdate,
+			Processing:     task.IsProcessing,
+			Finished:       !task.IsProcessing && task.Buffer.IsAIFinished(),
+		})
+		task.mutex.RUnlock()
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CreatedTime.After(tasks[j].CreatedTime) })
+	return tasks
+}
+
+// CancelTask 终止进行中的任务，任务不存在或已结束时返回false
+func (b *BotHandler) CancelTask(streamID string) bool {
+	b.taskCache.mutex.RLock()
+	task, exists := b.taskCache.tasks[streamID]
+	b.taskCache.mutex.RUnlock()
+	if !exists || task.Buffer.IsAIFinished() {
+		return false
+	}
+
+	task.Buffer.Push("\n\n（本次回复已被管理员终止）")
+	task.cancel()
+	return true
+}
+
+// Conversations 列出内存中的会话Agent（按最近活跃倒序）
+func (b *BotHandler) Conversations() []ConversationSummary {
+	cam := b.convAgentManager
+	cam.mutex.RLock()
+	defer cam.mutex.RUnlock()
+
+	list := make([]ConversationSummary, 0, len(cam.agents))
+	for id, convAgent := range cam.agents {
+		convAgent.mutex.RLock()
+		list = append(list, ConversationSummary{
+			ConversationID: id,
+			LastActivity:   convAgent.lastActivity,
+			Stale:          convAgent.generation != cam.generation,
+		})
+		convAgent.mutex.RUnlock()
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastActivity.After(list[j].LastActivity) })
+	return list
+}
+
+// ChatLogEnabled 是否启用聊天记录
+func (b *BotHandler) ChatLogEnabled() bool {
+	return b.logger != nil
+}
+
+// Messages 分页查询会话的聊天记录，返回当前页和符合条件的总数（未启用聊天记录时返回错误）
+func (b *BotHandler) Messages(conversationID string, query MessageQuery) ([]ChatMessage, int, error) {
+	if b.logger == nil {
+		return nil, 0, fmt.Errorf("聊天记录未启用")
+	}
+	entries, total, err := b.logger.Messages(conversationID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	messages := make([]ChatMessage, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, ChatMessage{
+			Time:      entry.Timestamp,
+			RequestID: entry.RequestID,
+			Sender:    entry.UserID,
+			Content:   entry.Content,
+		})
+	}
+	return messages, total, nil
+}
+
+// EvictConversation 移除会话Agent及其记忆，下次消息时重新创建
+func (b *BotHandler) EvictConversation(conversationID string) bool {
+	cam := b.convAgentManager
+	cam.mutex.Lock()
+	defer cam.mutex.Unlock()
+
+	if _, exists := cam.agents[conversationID]; !exists {
+		return false
+	}
+	delete(cam.agents, conversationID)
+	return true
+}
+
+// Stats 获取使用统计
+func (b *BotHandler) Stats() UsageStats {
+	stats := b.usage.snapshot()
+	stats.ActiveTasks = b.GetActiveStreamCount()
+
+	b.convAgentManager.mutex.RLock()
+	stats.Conversations = len(b.convAgentManager.agents)
+	b.convAgentManager.mutex.RUnlock()
+	return stats
+}
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/analytics"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+)
+
+const (
+	analyticsQueueSize     = 256              // 统计写入队列长度（队列满时丢弃，避免拖慢回复）
+	analyticsClassifiers   = 4                // 同时进行的意图分类数（超出时跳过分类）
+	analyticsClassifyLimit = 10 * time.Second // 意图分类超时
+)
+
+// analyticsRecorder 订阅事件总线，在后台写入会话统计
+type analyticsRecorder struct {
+	store      *analytics.Store
+	classifier *analytics.Classifier // 意图分类（未启用时为nil）
+	retention  int
+	queue      chan func()
+	classify   chan struct{} // 意图分类并发限制
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// newAnalyticsRecorder 打开统计数据库并订阅事件（未启用时返回nil）
+func newAnalyticsRecorder(cfg *config.Config, bus *events.Bus) (*analyticsRecorder, error) {
+	if !cfg.Analytics.Enabled {
+		return nil, nil
+	}
+	store, err := analytics.Open(cfg.Analytics.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &analyticsRecorder{
+		store:     store,
+		retention: cfg.Analytics.Retention,
+		queue:     make(chan func(), analyticsQueueSize),
+		classify:  make(chan struct{}, analyticsClassifiers),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if cfg.Analytics.Classify {
+		llmName := cfg.Analytics.LLMProvider
+		if llmName == "" {
+			llmName = cfg.LLM.Default
+		}
+		client, err := llm.CreateLLMByName(cfg, llmName, applog.SDK())
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("创建意图分类模型失败: %w", err)
+		}
+		r.classifier = analytics.NewClassifier(client, cfg.Analytics.Intents, analyticsClassifyLimit)
+	}
+
+	events.Subscribe(bus, func(e events.MessageReceived) {
+		r.enqueue(func() error { return store.RecordMessage(e.ConversationID, e.UserID, e.Time) })
+	})
+	events.Subscribe(bus, func(e events.ToolCalled) {
+		r.enqueue(func() error { return store.RecordTool(e.ConversationID, e.Tool, e.Time) })
+	})
+	events.Subscribe(bus, func(e events.HandoffStarted) {
+		r.enqueue(func() error { return store.MarkHandoff(e.ConversationID, e.Time) })
+	})
+	events.Subscribe(bus, func(e events.TurnFinished) {
+		r.enqueue(func() error {
+			return store.RecordTurn(analytics.Turn{
+				StreamID:       e.StreamID,
+				ConversationID: e.ConversationID,
+				Variant:        e.Variant,
+				ToolCalls:      e.ToolCalls,
+				Latency:        e.Duration,
+				Failed:         e.Err != nil,
+				Time:           e.Time,
+			})
+		})
+		if r.classifier != nil {
+			r.classifyTurn(e.StreamID, e.Question)
+		}
+	})
+
+	go r.run()
+	return r, nil
+}
+
+// enqueue 将写入操作放入后台队列，队列满时丢弃
+func (r *analyticsRecorder) enqueue(job func() error) {
+	select {
+	case r.queue <- func() {
+		if err := job(); err != nil {
+			slog.Warn("写入会话统计失败", "err", err)
+		}
+	}:
+	default:
+		slog.Warn("统计写入队列已满，丢弃一条记录")
+	}
+}
+
+// classifyTurn 在后台判断问题意图，完成后写入统计（分类繁忙时跳过）
+func (r *analyticsRecorder) classifyTurn(streamID, question string) {
+	select {
+	case r.classify <- struct{}{}:
+	default:
+		return
+	}
+	go func() {
+		defer func() { <-r.classify }()
+		intent, err := r.classifier.Classify(context.Background(), stripUserPrefix(question))
+		if err != nil {
+			slog.Warn("问题意图分类失败", "err", err)
+			return
+		}
+		r.enqueue(func() error { return r.store.SetIntent(streamID, intent) })
+	}()
+}
+
+// run 串行执行写入操作，并每天清理过期统计
+func (r *analyticsRecorder) run() {
+	defer close(r.done)
+
+	r.prune()
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case job := <-r.queue:
+			job()
+		case <-ticker.C:
+			r.prune()
+		case <-r.stop:
+			// 写完队列中剩余的记录
+			for {
+				select {
+				case job := <-r.queue:
+					job()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// prune 删除超过保留天数的统计
+func (r *analyticsRecorder) prune() {
+	if r.retention <= 0 {
+		return
+	}
+	if err := r.store.Prune(time.Now().AddDate(0, 0, -r.retention)); err != nil {
+		slog.Warn("写入会话统计失败", "err", err)
+	}
+}
+
+// Close 写完剩余记录后关闭数据库
+func (r *analyticsRecorder) Close() error {
+	close(r.stop)
+	<-r.done
+	return r.store.Close()
+}
+
+// stripUserPrefix 去掉消息开头的 [用户 xxx]: 前缀
+func stripUserPrefix(question string) string {
+	if strings.HasPrefix(question, "[用户 ") {
+		if _, rest, ok := strings.Cut(question, "]: "); ok {
+			return rest
+		}
+	}
+	return question
+}
+
+// Analytics 查询会话统计（日期格式 2006-01-02），top为意图和工具排行的条数
+func (b *BotHandler) Analytics(from, to string, top int) (*analytics.Report, error) {
+	if b.analytics == nil {
+		return nil, fmt.Errorf("会话统计未启用")
+	}
+	return b.analytics.store.Report(from, to, top)
+}
@@ -0,0 +1,290 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/approval"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/notify"
+)
+
+// approvalUsage 审批命令说明
+const approvalUsage = `🔐 工具调用审批命令：
+/approvals —— 列出等待审批的工具调用
+/approve <编号> —— 同意执行
+/deny <编号> —— 拒绝执行`
+
+// approvalArgumentsLimit 卡片和通知中展示的工具参数长度上限（字符）
+const approvalArgumentsLimit = 200
+
+// ApproveFunc 工具调用审批：返回错误时不执行工具（错误信息作为工具结果交给Agent）
+type ApproveFunc func(ctx context.Context, tool, args string) error
+
+// streamIDKey 上下文中的任务ID（审批卡片附在该任务的流式回复下方）
+type streamIDKey struct{}
+
+// requesterKey 上下文中发起对话的用户ID
+type requesterKey struct{}
+
+// withRequester 在上下文中记录发起对话的用户
+func withRequester(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, requesterKey{}, userID)
+}
+
+// approvalTool 执行前需要审批的本地工具
+type approvalTool struct {
+	interfaces.Tool
+	approve ApproveFunc
+}
+
+// Run 审批通过后执行工具
+func (t *approvalTool) Run(ctx context.Context, input string) (string, error) {
+	if err := t.approve(ctx, t.Name(), input); err != nil {
+		return "", err
+	}
+	return t.Tool.Run(ctx, input)
+}
+
+// Execute 审批通过后执行工具
+func (t *approvalTool) Execute(ctx context.Context, args string) (string, error) {
+	if err := t.approve(ctx, t.Name(), args); err != nil {
+		return "", err
+	}
+	return t.Tool.Execute(ctx, args)
+}
+
+// approvalServer 工具调用前需要审批的MCP服务器
+//
+// 包装共享的MCP服务器，不影响其他会话；Close由原服务器的所有者负责。
+type approvalServer struct {
+	interfaces.MCPServer
+	approve ApproveFunc
+}
+
+// CallTool 审批通过后调用工具
+func (s *approvalServer) CallTool(ctx context.Context, name string, args interface{}) (*interfaces.MCPToolResponse, error) {
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		arguments = []byte(fmt.Sprint(args))
+	}
+	if err := s.approve(ctx, name, string(arguments)); err != nil {
+		return nil, err
+	}
+	return s.MCPServer.CallTool(ctx, name, args)
+}
+
+// Close 不关闭共享的底层服务器
+func (s *approvalServer) Close() error {
+	return nil
+}
+
+// approveToolCall 需要审批的工具调用：暂停执行，等待用户点击卡片按钮或审批人回复命令
+func (b *BotHandler) approveToolCall(ctx context.Context, tool, args string) error {
+	if !b.approvals.Requires(tool) {
+		return nil
+	}
+
+	streamID, _ := ctx.Value(streamIDKey{}).(string)
+	userID, _ := ctx.Value(requesterKey{}).(string)
+	if streamID == "" || userID == "" {
+		// 定时任务等无人值守的场景无法审批
+		slog.Info("拒绝执行需审批的工具：当前任务无人可审批", "tool", tool)
+		return fmt.Errorf("工具 %s 需要人工审批，当前任务无人可审批，已拒绝执行", tool)
+	}
+	conversationID, _ := memory.GetConversationID(ctx)
+
+	req := approval.Request{
+		StreamID:       streamID,
+		ConversationID: conversationID,
+		UserID:         userID,
+		Tool:           tool,
+		Arguments:      args,
+		Approvers:      b.config.Approval.Approvers,
+	}
+	decision, err := b.approvals.Request(ctx, req, func(r approval.Request) {
+		req = r
+		b.announceApproval(r)
+	})
+	if err != nil {
+		return fmt.Errorf("等待工具调用审批失败: %w", err)
+	}
+
+	result := "denied"
+	switch {
+	case decision.Approved:
+		result = "approved"
+	case decision.By == "":
+		result = "timeout"
+	}
+	slog.Info("工具调用审批", "id", req.ID, "tool", tool, "conversation_id", conversationID, "result", result)
+	b.events.Publish(events.ToolApproval{
+		RequestID:      req.ID,
+		StreamID:       streamID,
+		ConversationID: conversationID,
+		UserID:         userID,
+		Tool:           tool,
+		Arguments:      args,
+		Result:         result,
+		By:             decision.By,
+		Wait:           decision.Time.Sub(req.Created),
+		Time:           decision.Time,
+	})
+
+	b.setTaskEphemeral(streamID, "")
+	switch result {
+	case "approved":
+		return nil
+	case "timeout":
+		return fmt.Errorf("工具 %s 的调用审批已超时，未执行。请告知用户操作未执行，需要时可重新发起", tool)
+	default:
+		return fmt.Errorf("用户拒绝执行工具 %s，未执行。请告知用户操作已取消，不要再次尝试调用", tool)
+	}
+}
+
+// announceApproval 提示用户等待审批，配置了审批人时通知审批人
+func (b *BotHandler) announceApproval(r approval.Request) {
+	slog.Info("工具调用等待审批", "id", r.ID, "tool", r.Tool, "conversation_id", r.ConversationID)
+
+	if len(r.Approvers) == 0 {
+		b.setTaskEphemeral(r.StreamID, fmt.Sprintf("🔐 执行 %s 前需要您确认，请点击下方卡片按钮（或回复 /approve %s 同意、/deny %s 拒绝）", r.Tool, r.ID, r.ID))
+		return
+	}
+
+	b.setTaskEphemeral(r.StreamID, fmt.Sprintf("🔐 执行 %s 需要管理员审批，已通知审批人，请稍候…", r.Tool))
+	content := fmt.Sprintf("用户 %s 请求执行工具 %s\n参数：%s\n回复 /approve %s 同意，/deny %s 拒绝",
+		r.UserID, r.Tool, truncateRunes(r.Arguments, approvalArgumentsLimit), r.ID, r.ID)
+	for _, approver := range r.Approvers {
+		err := b.Notify(notify.Notification{
+			Target:   "single_" + approver,
+			Category: config.ApprovalCategory,
+			Title:    "🔐 工具调用审批",
+			Content:  content,
+			Time:     time.Now(),
+		})
+		if err != nil {
+			slog.Warn("通知审批人失败", "approver", approver, "err", err)
+		}
+	}
+}
+
+// setTaskEphemeral 更新任务的临时提示（任务不存在时忽略）
+func (b *BotHandler) setTaskEphemeral(streamID, content string) {
+	b.taskCache.mutex.RLock()
+	task, exists := b.taskCache.tasks[streamID]
+	b.taskCache.mutex.RUnlock()
+	if exists {
+		task.Buffer.SetEphemeral(content)
+	}
+}
+
+// approvalCard 附在流式回复下方的审批卡片（由发起对话的用户确认时）
+func (b *BotHandler) approvalCard(streamID string) *wework.WeWorkTemplateCard {
+	if b.approvals == nil {
+		return nil
+	}
+	r, ok := b.approvals.TakeCard(streamID)
+	if !ok || len(r.Approvers) > 0 {
+		return nil
+	}
+	return newApprovalCard(r, "", true)
+}
+
+// newApprovalCard 构建审批卡片，status为审批结果等提示，buttons为false时不再展示按钮
+func newApprovalCard(r approval.Request, status string, buttons bool) *wework.WeWorkTemplateCard {
+	card := &wework.WeWorkTemplateCard{
+		CardType:     wework.CardTypeButtonInteraction,
+		MainTitle:    &wework.CardMainTitle{Title: "🔐 工具调用确认", Desc: "编号 " + r.ID},
+		SubTitleText: status,
+		HorizontalContentList: []wework.CardHorizontalItem{
+			{KeyName: "工具", Value: r.Tool},
+			{KeyName: "参数", Value: truncateRunes(r.Arguments, approvalArgumentsLimit)},
+		},
+		TaskID: "approval_" + r.ID,
+	}
+	if buttons {
+		card.ButtonList = []wework.CardButton{
+			{Text: "同意", Style: 1, Key: "approve:" + r.ID},
+			{Text: "拒绝", Style: 3, Key: "deny:" + r.ID},
+		}
+	}
+	return card
+}
+
+// handleApprovalMessage 处理审批卡片按钮点击和审批命令，其他消息返回handled=false
+func (b *BotHandler) handleApprovalMessage(msg *wework.IncomingMessage) (resp *wework.WeWorkResponse, handled bool) {
+	if b.approvals == nil {
+		return nil, false
+	}
+
+	if event := msg.GetTemplateCardEvent(); event != nil {
+		action, id, ok := strings.Cut(event.EventKey, ":")
+		if !ok || (action != "approve" && action != "deny") {
+			return nil, false
+		}
+		r, err := b.approvals.Decide(id, msg.From.UserID, action == "approve")
+		switch {
+		case err != nil && r.ID != "":
+			// 无权审批：保留按钮供有权限的用户操作
+			return wework.NewUpdateCardResponse(newApprovalCard(r, fmt.Sprintf("❌ %v", err), true)), true
+		case err != nil:
+			r.ID = id
+			return wework.NewUpdateCardResponse(newApprovalCard(r, fmt.Sprintf("❌ %v", err), false)), true
+		case action == "approve":
+			return wework.NewUpdateCardResponse(newApprovalCard(r, fmt.Sprintf("✅ 已同意执行（%s）", msg.From.UserID), false)), true
+		default:
+			return wework.NewUpdateCardResponse(newApprovalCard(r, fmt.Sprintf("🚫 已拒绝执行（%s）", msg.From.UserID), false)), true
+		}
+	}
+
+	command, ok := strings.CutPrefix(stripMention(msg.GetTextContent()), "/")
+	if !ok {
+		return nil, false
+	}
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "approvals":
+		return wework.NewTextResponse(b.listApprovals(msg.From.UserID)), true
+	case "approve", "deny":
+		if arg == "" {
+			return wework.NewTextResponse(approvalUsage), true
+		}
+		r, err := b.approvals.Decide(arg, msg.From.UserID, name == "approve")
+		if err != nil {
+			return wework.NewTextResponse(fmt.Sprintf("❌ %v", err)), true
+		}
+		if name == "approve" {
+			return wework.NewTextResponse(fmt.Sprintf("✅ 已同意执行 %s（%s）", r.Tool, r.ID)), true
+		}
+		return wework.NewTextResponse(fmt.Sprintf("🚫 已拒绝执行 %s（%s）", r.Tool, r.ID)), true
+	}
+	return nil, false
+}
+
+// listApprovals 列出用户可以审批的工具调用
+func (b *BotHandler) listApprovals(userID string) string {
+	var sb strings.Builder
+	count := 0
+	for _, r := range b.approvals.List() {
+		if !r.CanDecide(userID) {
+			continue
+		}
+		count++
+		fmt.Fprintf(&sb, "\n%s %s（%s，已等待%s）\n  参数：%s", r.ID, r.Tool, r.UserID,
+			time.Since(r.Created).Truncate(time.Second), truncateRunes(r.Arguments, approvalArgumentsLimit))
+	}
+	if count == 0 {
+		return "🔐 当前没有等待您审批的工具调用"
+	}
+	return fmt.Sprintf("🔐 等待审批的工具调用（%d个）：%s\n\n%s", count, sb.String(), approvalUsage)
+}
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/budget"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+)
+
+// budgetGuard 用量预算：回复结束后累计估算用量，超出限额的会话改用备用模型或拒绝回复
+type budgetGuard struct {
+	config config.BudgetConfig
+	ledger *budget.Ledger
+}
+
+// BudgetUsage 会话当天的用量及限额（管理接口）
+type BudgetUsage struct {
+	budget.Usage
+	TokenLimit int     `json:"token_limit,omitempty"` // 0为不限
+	CostLimit  float64 `json:"cost_limit,omitempty"`  // 0为不限
+	Exceeded   bool    `json:"exceeded"`
+	Action     string  `json:"action,omitempty"` // 超出后的处理：fallback（改用备用模型）或 refuse（拒绝回复）
+}
+
+// newBudgetGuard 创建用量预算并订阅回复结束事件（未启用时返回nil）
+func newBudgetGuard(cfg *config.Config, bus *events.Bus) (*budgetGuard, error) {
+	if !cfg.Budget.Enabled {
+		return nil, nil
+	}
+	ledger, err := budget.NewLedger(cfg.Budget.Path)
+	if err != nil {
+		return nil, err
+	}
+	g := &budgetGuard{config: cfg.Budget, ledger: ledger}
+
+	// 同步记账：同一会话的下一轮回复开始前用量已计入
+	events.Subscribe(bus, func(e events.TurnFinished) {
+		if e.LLMProvider == "" {
+			return
+		}
+		g.record(e.ConversationID, e.LLMProvider, e.Question, e.Answer)
+	})
+	return g, nil
+}
+
+// record 按问题和回复估算本轮用量并记账
+func (g *budgetGuard) record(conversationID, provider, question, answer string) {
+	tokens := budget.EstimateTokens(question) + budget.EstimateTokens(answer)
+	cost := float64(tokens) / 1000 * g.config.Prices[provider]
+	if err := g.ledger.Add(conversationID, tokens, cost); err != nil {
+		slog.Warn("保存用量记录失败", "conversation_id", conversationID, "err", err)
+	}
+}
+
+// status 会话当天的用量及是否超出限额
+func (g *budgetGuard) status(usage budget.Usage) BudgetUsage {
+	tokenLimit, costLimit := g.config.Limits(usage.ConversationID)
+	s := BudgetUsage{Usage: usage, TokenLimit: tokenLimit, CostLimit: costLimit}
+	s.Exceeded = (tokenLimit > 0 && usage.Tokens >= tokenLimit) || (costLimit > 0 && usage.Cost >= costLimit)
+	if s.Exceeded {
+		s.Action = "refuse"
+		if g.config.Fallback != "" {
+			s.Action = "fallback"
+		}
+	}
+	return s
+}
+
+// exceeded 会话今日用量是否已超出限额
+func (g *budgetGuard) exceeded(conversationID string) (BudgetUsage, bool) {
+	s := g.status(g.ledger.Get(conversationID))
+	return s, s.Exceeded
+}
+
+// downgraded 会话是否因超出用量预算改用备用模型（调用方需持有cam.mutex）
+func (cam *ConversationAgentManager) downgraded(conversationID string) bool {
+	if cam.budget == nil || cam.budget.config.Fallback == "" {
+		return false
+	}
+	_, exceeded := cam.budget.exceeded(conversationID)
+	return exceeded
+}
+
+// handleOverBudget 超出用量预算且未配置备用模型时拒绝回复并说明原因
+func (b *BotHandler) handleOverBudget(msg *wework.IncomingMessage) (*wework.WeWorkResponse, bool) {
+	if b.budget == nil || b.budget.config.Fallback != "" {
+		return nil, false
+	}
+	conversationID := msg.GetConversationKey()
+	s, exceeded := b.budget.exceeded(conversationID)
+	if !exceeded {
+		return nil, false
+	}
+	slog.Info("超出用量预算，拒绝回复", "conversation_id", conversationID, "tokens", s.Tokens)
+	return wework.NewTextResponse(fmt.Sprintf("%s\n\n今日已用约 %d tokens（%d轮对话）", b.budget.config.Message, s.Tokens, s.Turns)), true
+}
+
+// Budget 列出当天有用量的会话（未启用用量预算时返回false）
+func (b *BotHandler) Budget() (string, []BudgetUsage, bool) {
+	if b.budget == nil {
+		return "", nil, false
+	}
+	day, usage := b.budget.ledger.List()
+	list := make([]BudgetUsage, 0, len(usage))
+	for _, u := range usage {
+		list = append(list, b.budget.status(u))
+	}
+	return day, list, true
+}
+
+// ResetBudget 清零会话当天的用量，会话没有用量时返回false
+func (b *BotHandler) ResetBudget(conversationID string) (bool, error) {
+	if b.budget == nil {
+		return false, nil
+	}
+	return b.budget.ledger.Reset(conversationID)
+}
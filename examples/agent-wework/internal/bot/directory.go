@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+)
+
+// userLabel 消息前缀中的用户标识：启用通讯录时附带姓名、部门和职务，如“zhangsan（张三，技术部/运维组，工程师）”
+func (b *BotHandler) userLabel(ctx context.Context, userID string) string {
+	dir := b.convAgentManager.directory
+	if dir == nil {
+		return userID
+	}
+	if p, ok := dir.Lookup(ctx, userID); ok {
+		return fmt.Sprintf("%s（%s）", userID, p.Describe())
+	}
+	return userID
+}
+
+// handleAccessDenied 配置了 directory.allow 时拒绝范围外的用户（客服群中的客服不受限制）
+func (b *BotHandler) handleAccessDenied(msg *wework.IncomingMessage) (*wework.WeWorkResponse, bool) {
+	d := b.config.Directory
+	if len(d.Allow) == 0 {
+		return nil, false
+	}
+	if msg.ChatID != "" && msg.ChatID == b.config.Handoff.SupportGroup {
+		return nil, false
+	}
+	if b.convAgentManager.directory.Match(context.Background(), msg.From.UserID, d.Allow) {
+		return nil, false
+	}
+	slog.Info("用户不在允许范围内，拒绝处理", "user_id", msg.From.UserID, "conversation_id", msg.GetConversationKey())
+	if d.DenyReply != "" {
+		return wework.NewTextResponse(d.DenyReply), true
+	}
+	return wework.NewTextResponse(b.text(b.userLocale(msg.From.UserID), i18n.AccessDenied)), true
+}
+
+// isKnowledgeAdmin 是否为知识库管理员（knowledge.admins 中的用户ID，或启用通讯录时所属的部门）
+func (b *BotHandler) isKnowledgeAdmin(userID string) bool {
+	admins := b.config.Knowledge.Admins
+	if dir := b.convAgentManager.directory; dir != nil {
+		return dir.Match(context.Background(), userID, admins)
+	}
+	return slices.Contains(admins, userID)
+}
@@ -0,0 +1,10 @@
+package bot
+
+// Variant 获取会话所在的A/B实验变体（未启用实验时为空）
+func (cam *ConversationAgentManager) Variant(conversationID string) string {
+	cam.mutex.RLock()
+	defer cam.mutex.RUnlock()
+
+	variant, _ := cam.config.Experiment.Assign(conversationID)
+	return variant.Name
+}
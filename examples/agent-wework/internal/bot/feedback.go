@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+)
+
+// handleFeedbackMessage 处理 /feedback 命令（反馈上一条回复有问题，由出站Webhook推送），未启用或其他消息返回handled=false
+func (b *BotHandler) handleFeedbackMessage(msg *wework.IncomingMessage) (*wework.WeWorkResponse, bool) {
+	if !b.config.Outbound.Enabled {
+		return nil, false
+	}
+	command, ok := strings.CutPrefix(stripMention(msg.GetTextContent()), "/feedback")
+	if !ok || (command != "" && command[0] != ' ') {
+		return nil, false
+	}
+	conversationID := msg.GetConversationKey()
+
+	question, answer := b.taskCache.lastTurn(conversationID)
+	comment := strings.TrimSpace(command)
+	slog.Info("收到负面反馈", "request_id", msg.RequestID, "conversation_id", conversationID, "user_id", msg.From.UserID, "comment", comment)
+	b.events.Publish(events.NegativeFeedback{
+		RequestID:      msg.RequestID,
+		ConversationID: conversationID,
+		UserID:         msg.From.UserID,
+		Comment:        comment,
+		Question:       question,
+		Answer:         answer,
+		Time:           time.Now(),
+	})
+	return wework.NewTextResponse("感谢反馈，我们会尽快核实并改进。"), true
+}
+
+// lastTurn 会话最近一条已结束回复的问题和内容（没有时返回空字符串）
+func (tcm *TaskCacheManager) lastTurn(conversationID string) (question, answer string) {
+	tcm.mutex.RLock()
+	defer tcm.mutex.RUnlock()
+
+	var last *TaskInfo
+	for _, task := range tcm.tasks {
+		if task.ConversationID != conversationID || !task.Buffer.IsAIFinished() {
+			continue
+		}
+		if last == nil || task.CreatedTime.After(last.CreatedTime) {
+			last = task
+		}
+	}
+	if last == nil {
+		return "", ""
+	}
+	return last.Question, last.Buffer.Snapshot()
+}
@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// GroupSettings 群聊级配置 - 配置文件中的覆盖可在运行时修改（如通过管理接口）
+type GroupSettings struct {
+	config *config.Config
+	groups map[string]config.GroupConfig // chatID -> 群聊配置
+	mutex  sync.RWMutex
+}
+
+// NewGroupSettings 从配置创建群聊级配置
+func NewGroupSettings(cfg *config.Config) *GroupSettings {
+	groups := make(map[string]config.GroupConfig, len(cfg.Groups))
+	for chatID, group := range cfg.Groups {
+		groups[chatID] = group
+	}
+	return &GroupSettings{config: cfg, groups: groups}
+}
+
+// Features 获取会话的功能开关（全局默认值 → 人设 → 群聊配置 → 会话级覆盖）
+//
+// persona为会话通过 /persona 选择的人设，为空或已不存在时使用群聊或全局默认人设。
+func (gs *GroupSettings) Features(conversationID, persona string) config.Features {
+	gs.mutex.RLock()
+	features := gs.config.DefaultFeatures()
+	chatID, isGroup := strings.CutPrefix(conversationID, "group_")
+	group, hasGroup := gs.groups[chatID]
+	override, hasOverride := gs.config.Overrides[conversationID]
+	if _, ok := gs.config.Personas[persona]; !ok {
+		persona = gs.defaultPersonaLocked(conversationID)
+	}
+	personaConfig, hasPersona := gs.config.Personas[persona]
+	gs.mutex.RUnlock()
+
+	if hasPersona {
+		features = personaConfig.Apply(features)
+		features.Persona = persona
+	}
+	if isGroup && hasGroup {
+		features = group.Apply(features)
+	}
+	if hasOverride {
+		features = override.Apply(features)
+	}
+	return features
+}
+
+// DefaultPersona 获取会话的默认人设（群聊配置优先于default_persona）
+func (gs *GroupSettings) DefaultPersona(conversationID string) string {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	return gs.defaultPersonaLocked(conversationID)
+}
+
+// defaultPersonaLocked 获取会话的默认人设（调用方需持有锁）
+func (gs *GroupSettings) defaultPersonaLocked(conversationID string) string {
+	if chatID, ok := strings.CutPrefix(conversationID, "group_"); ok {
+		if group, ok := gs.groups[chatID]; ok && group.Persona != "" {
+			return group.Persona
+		}
+	}
+	return gs.config.DefaultPersona
+}
+
+// Webhook 获取群聊会话配置的群机器人Webhook（单聊或未配置时返回空）
+func (gs *GroupSettings) Webhook(conversationID string) string {
+	chatID, ok := strings.CutPrefix(conversationID, "group_")
+	if !ok {
+		return ""
+	}
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	return gs.groups[chatID].Webhook
+}
+
+// Reload 按新配置重置群聊配置（运行时修改的群聊配置会被配置文件覆盖）
+func (gs *GroupSettings) Reload(cfg *config.Config) {
+	groups := make(map[string]config.GroupConfig, len(cfg.Groups))
+	for chatID, group := range cfg.Groups {
+		groups[chatID] = group
+	}
+
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	gs.config = cfg
+	gs.groups = groups
+}
+
+// Get 获取群聊配置
+func (gs *GroupSettings) Get(chatID string) (config.GroupConfig, bool) {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	group, ok := gs.groups[chatID]
+	return group, ok
+}
+
+// Set 设置群聊配置
+func (gs *GroupSettings) Set(chatID string, group config.GroupConfig) {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	gs.groups[chatID] = group
+}
+
+// Delete 删除群聊配置（恢复全局默认）
+func (gs *GroupSettings) Delete(chatID string) bool {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	if _, ok := gs.groups[chatID]; !ok {
+		return false
+	}
+	delete(gs.groups, chatID)
+	return true
+}
+
+// List 列出所有群聊配置
+func (gs *GroupSettings) List() map[string]config.GroupConfig {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	groups := make(map[string]config.GroupConfig, len(gs.groups))
+	for chatID, group := range gs.groups {
+		groups[chatID] = group
+	}
+	return groups
+}
@@ -2,130 +2,73 @@ package bot
 
 import (
 	"context"
-	"crypto/rand"
 	"fmt"
-	"math/big"
-	"regexp"
+	"log/slog"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Ingenimax/agent-sdk-go/pkg/agent"
 	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
-	"github.com/Ingenimax/agent-sdk-go/pkg/logging"
 	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
 	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
 	"github.com/Ingenimax/agent-sdk-go/pkg/tools"
 
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/approval"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/cluster"
 	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
-	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/directory"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/errreport"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fetch"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/handoff"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/imagegen"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/jobs"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/knowledge"
 	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/mcp"
-	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/moderation"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/notify"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/policy"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/preferences"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/profile"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/queue"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/router"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/scheduler"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/translate"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/welcome"
+	"github.com/deepsage-ai/b0dy/pkg/bot"
+	"github.com/deepsage-ai/b0dy/pkg/metrics"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
 )
 
 // === 真正的流式传输架构 - 生产者消费者模式 ===
 
-// StreamBuffer 流式内容缓冲区 - 实现累积模式（按照Python示例）
-type StreamBuffer struct {
-	chunks     []string     // 所有内容块（累积存储，不移除）
-	mutex      sync.RWMutex // 线程安全锁
-	aiFinished bool         // AI是否完成生成
-	lastIndex  int          // 最后返回的块索引（模拟Python的current_step）
-	lastUpdate time.Time    // 最后更新时间
-}
-
-// NewStreamBuffer 创建流式缓冲区
-func NewStreamBuffer() *StreamBuffer {
-	return &StreamBuffer{
-		chunks:     make([]string, 0),
-		lastUpdate: time.Now(),
-	}
-}
-
-// Push AI生产内容到缓冲区
-func (sb *StreamBuffer) Push(content string) {
-	if content == "" {
-		return
-	}
-
-	sb.mutex.Lock()
-	defer sb.mutex.Unlock()
-
-	sb.chunks = append(sb.chunks, content)
-	sb.lastUpdate = time.Now()
-}
-
-// GetAccumulated 获取累积内容（优化版本：一次性返回所有已生成内容）
-func (sb *StreamBuffer) GetAccumulated() (string, bool) {
-	sb.mutex.Lock()
-	defer sb.mutex.Unlock()
-
-	// 关键修改：直接更新lastIndex到当前chunks长度，而不是每次只加1
-	currentChunkCount := len(sb.chunks)
-	if sb.lastIndex < currentChunkCount {
-		// 一次性更新到当前所有chunks
-		sb.lastIndex = currentChunkCount
-		sb.lastUpdate = time.Now()
-	}
-
-	// 构建累积内容（返回所有已生成的内容）
-	var accumulated strings.Builder
-	for i := 0; i < sb.lastIndex; i++ {
-		accumulated.WriteString(sb.chunks[i])
-	}
-
-	// 检查AI是否完成
-	isFinished := sb.aiFinished && sb.lastIndex >= len(sb.chunks)
-
-	// 合并多个think标签（企业微信只能识别一个）
-	content := mergeThinkTags(accumulated.String())
-	return content, isFinished
-}
-
-// SetAIFinished 标记AI完成生成
-func (sb *StreamBuffer) SetAIFinished() {
-	sb.mutex.Lock()
-	defer sb.mutex.Unlock()
-
-	sb.aiFinished = true
-	sb.lastUpdate = time.Now()
-}
-
-// IsEmpty 检查是否还有未展示的内容
-func (sb *StreamBuffer) IsEmpty() bool {
-	sb.mutex.RLock()
-	defer sb.mutex.RUnlock()
-
-	// 累积模式：检查是否所有内容都已展示
-	return sb.lastIndex >= len(sb.chunks)
-}
-
-// IsAIFinished 检查AI是否完成
-func (sb *StreamBuffer) IsAIFinished() bool {
-	sb.mutex.RLock()
-	defer sb.mutex.RUnlock()
-
-	return sb.aiFinished
-}
-
-// GetStatus 获取缓冲区状态（用于调试）
-func (sb *StreamBuffer) GetStatus() (totalChunks int, displayedChunks int, aiFinished bool) {
-	sb.mutex.RLock()
-	defer sb.mutex.RUnlock()
-
-	return len(sb.chunks), sb.lastIndex, sb.aiFinished
-}
-
 // TaskInfo 任务信息 - 基于StreamBuffer的真正流式架构
 type TaskInfo struct {
-	StreamID       string        `json:"stream_id"`
-	Question       string        `json:"question"`
-	ConversationID string        `json:"conversation_id"` // 会话ID（用于记忆连续性）
-	CreatedTime    time.Time     `json:"created_time"`
-	Buffer         *StreamBuffer `json:"-"`             // 流式缓冲区（替换累积内容）
-	IsProcessing   bool          `json:"is_processing"` // AI是否正在处理
-	LastUpdate     time.Time     `json:"last_update"`
-	mutex          sync.RWMutex  `json:"-"`
+	StreamID       string             `json:"stream_id"`
+	Question       string             `json:"question"`
+	ConversationID string             `json:"conversation_id"` // 会话ID（用于记忆连续性）
+	CreatedTime    time.Time          `json:"created_time"`
+	Buffer         *stream.Buffer     `json:"-"`                    // 流式缓冲区（替换累积内容）
+	HideThinking   bool               `json:"hide_thinking"`        // 是否隐藏思考过程（群聊配置）
+	Variant        string             `json:"variant,omitempty"`    // A/B实验变体（未参与实验时为空）
+	RequestID      string             `json:"request_id,omitempty"` // 回调请求ID（错误回复中作为错误码）
+	LLMProvider    string             `json:"llm_provider"`         // 会话Agent使用的LLM提供商
+	Locale         string             `json:"locale,omitempty"`     // 系统消息（占位内容、错误提示等）的语言
+	Images         [][]byte           `json:"-"`                    // 回复结束时附带的生成图片
+	JobID          int                `json:"job_id,omitempty"`     // 转为长任务后的任务编号
+	jobCarded      bool               `json:"-"`                    // 长任务卡片是否已发送
+	overflow       string             `json:"-"`                    // 回复超长时本条流式消息的最终内容
+	delivered      string             `json:"-"`                    // 超长时已在流式消息中展示的正式回复
+	continued      bool               `json:"-"`                    // 剩余内容是否已开始续发
+	IsProcessing   bool               `json:"is_processing"`        // AI是否正在处理
+	LastUpdate     time.Time          `json:"last_update"`
+	cancel         context.CancelFunc `json:"-"` // 取消任务处理
+	mutex          sync.RWMutex       `json:"-"`
 
 	// ❌ 已移除的累积模式字段：
 	// CurrentStep  int             - 不再需要固定步数
@@ -139,13 +82,31 @@ type TaskCacheManager struct {
 	tasks            map[string]*TaskInfo
 	mutex            sync.RWMutex
 	convAgentManager *ConversationAgentManager // 会话级Agent管理器
+	streamConfig     config.StreamConfig       // 流式输出配置
+	translator       *translate.Service        // 翻译服务（未启用时为nil）
+	images           *imageReader              // 图片文字识别（未启用时为nil）
+	voice            *voiceReply               // 语音回复（未启用时为nil）
+	jobs             *jobs.Tracker             // 长任务跟踪（未启用时为nil）
+	jobsAfter        time.Duration             // 转为长任务的运行时间阈值
+	continuation     *continuationChannels     // 超长回复续发（会话没有续发渠道时截断）
+	events           *events.Bus               // 事件总线
+	shared           cluster.Store             // 多副本共享状态（未启用时为nil）
+	clusterConfig    config.ClusterConfig      // 共享状态配置
+	org              string                    // 组织ID（共享状态的键按组织隔离）
+	moderator        *moderation.Moderator     // 内容审核（未启用时为nil）
+	moderation       config.ModerationConfig   // 内容审核配置
+	router           *router.Router            // 多智能体路由（未启用时为nil）
+	turns            *bot.TurnOrder            // 同一会话的回复按顺序执行
+	journal          *streamJournal            // 预写记录（未配置时为nil）
 }
 
 // NewTaskCacheManager 创建任务缓存管理器
-func NewTaskCacheManager(convAgentManager *ConversationAgentManager) *TaskCacheManager {
+func NewTaskCacheManager(convAgentManager *ConversationAgentManager, streamConfig config.StreamConfig) *TaskCacheManager {
 	return &TaskCacheManager{
 		tasks:            make(map[string]*TaskInfo),
 		convAgentManager: convAgentManager,
+		streamConfig:     streamConfig,
+		turns:            bot.NewTurnOrder(),
 	}
 }
 
@@ -161,57 +122,80 @@ func (tcm *TaskCacheManager) Close() {
 	// 任务缓存管理器已关闭
 }
 
-// generateTaskID 生成任务ID - 严格按照Python示例的_generate_random_string(10)
-func generateTaskID() (string, error) {
-	// Python: letters = string.ascii_letters + string.digits
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	const length = 10 // Python固定生成10位
-
-	result := make([]byte, length)
-	for i := range result {
-		randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-		if err != nil {
-			return "", err
-		}
-		result[i] = letters[randomIndex.Int64()]
-	}
-
-	return string(result), nil
-}
-
 // Invoke 创建新任务 - 模拟Python LLMDemo.invoke()
 func (tcm *TaskCacheManager) Invoke(ctx context.Context, question string, conversationID string) (string, error) {
-	streamID, err := generateTaskID()
+	// 同一会话的消息按到达顺序依次回复
+	return tcm.invoke(ctx, question, conversationID, tcm.inTurn(conversationID, tcm.processTaskAsync))
+}
+
+// invoke 创建任务并在后台执行process（AI回复，或转人工期间等待客服回复）
+func (tcm *TaskCacheManager) invoke(ctx context.Context, question, conversationID string, process func(ctx context.Context, streamID string)) (string, error) {
+	streamID, err := bot.NewStreamID()
 	if err != nil {
 		return "", fmt.Errorf("生成任务ID失败: %w", err)
 	}
 
+	// 启动异步AI处理（模拟Python的后台处理）
+	ctx = tcm.addTask(ctx, streamID, question, conversationID)
+	go process(ctx, streamID)
+
+	return streamID, nil
+}
+
+// addTask 登记任务（多副本时开始发布共享状态），返回可单独取消的任务上下文
+func (tcm *TaskCacheManager) addTask(ctx context.Context, streamID, question, conversationID string) context.Context {
+	// 任务可被单独取消（如管理接口终止任务）
+	ctx, cancel := context.WithCancel(ctx)
+
 	// 创建任务信息 - 基于StreamBuffer的真正流式架构
 	task := &TaskInfo{
 		StreamID:       streamID,
 		Question:       question,
 		ConversationID: conversationID, // ✅ 保存会话ID
 		CreatedTime:    time.Now(),
-		Buffer:         NewStreamBuffer(), // ✅ 创建流式缓冲区
+		Buffer:         stream.NewBuffer(), // ✅ 创建流式缓冲区
+		HideThinking:   !tcm.convAgentManager.Features(conversationID).Thinking,
+		Variant:        tcm.convAgentManager.Variant(conversationID),
+		RequestID:      applog.RequestID(ctx),
+		Locale:         tcm.contextLocale(ctx),
 		IsProcessing:   false,
 		LastUpdate:     time.Now(),
+		cancel:         cancel,
 	}
 
 	tcm.mutex.Lock()
 	tcm.tasks[streamID] = task
 	tcm.mutex.Unlock()
 
-	// 启动异步AI处理（模拟Python的后台处理）
-	go tcm.processTaskAsync(ctx, streamID)
-
-	return streamID, nil
+	if tcm.shared != nil {
+		go tcm.publishShared(task)
+	}
+	if tcm.journal != nil {
+		go tcm.journalTask(task)
+	}
+	return ctx
 }
 
 // processTaskAsync 异步处理任务
 func (tcm *TaskCacheManager) processTaskAsync(ctx context.Context, streamID string) {
 	defer func() {
 		if r := recover(); r != nil {
-			// 任务处理异常
+			// 任务处理异常：记录并上报，避免静默丢失
+			slog.ErrorContext(ctx, "任务处理异常", "stream_id", streamID, "panic", r)
+			extra := map[string]interface{}{"stream_id": streamID}
+			tcm.mutex.RLock()
+			task, ok := tcm.tasks[streamID]
+			tcm.mutex.RUnlock()
+			if ok {
+				extra["conversation_id"] = task.ConversationID
+				extra["llm_provider"] = task.LLMProvider
+			}
+			errreport.Panic(ctx, r, extra)
+
+			// 结束回复，避免企业微信持续刷新一个永远不会结束的流式消息直到任务过期
+			if ok && !task.Buffer.IsAIFinished() {
+				tcm.failTask(task, fmt.Errorf("%v", r))
+			}
 		}
 	}()
 
@@ -223,72 +207,323 @@ func (tcm *TaskCacheManager) processTaskAsync(ctx context.Context, streamID stri
 		// 任务不存在
 		return
 	}
+	defer task.cancel()
 
 	task.mutex.Lock()
 	task.IsProcessing = true
 	task.LastUpdate = time.Now()
 	task.mutex.Unlock()
 
+	startTime := time.Now()
+	state := &streamState{started: startTime}
+	tcm.events.Publish(events.TurnStarted{
+		StreamID:       streamID,
+		RequestID:      task.RequestID,
+		ConversationID: task.ConversationID,
+		Question:       task.Question,
+		Time:           startTime,
+	})
+
 	// ✅ 关键修改：使用conversationID作为会话标识，实现连续对话记忆
 	// 同一用户/群组的对话会共享记忆上下文
 	ctx = context.WithValue(ctx, memory.ConversationIDKey, task.ConversationID)
+	ctx = context.WithValue(ctx, streamIDKey{}, streamID)
+	ctx = events.WithBus(ctx, tcm.events) // MCP工具调用失败时发布事件
+
+	// 图片文字识别：识别结果加入问题（无需视觉模型），路由和Agent都能看到
+	if urls := imagesFrom(ctx); len(urls) > 0 && tcm.images != nil {
+		tcm.readImages(ctx, task, urls)
+	}
+
+	// 多智能体：由主管模型选择专家，会话Agent按专家人设创建
+	if tcm.router != nil {
+		tcm.routeTask(ctx, task)
+	}
 
 	// 获取或创建会话Agent
-	convAgent, err := tcm.convAgentManager.GetOrCreateAgent(task.ConversationID)
+	reset := tcm.convAgentManager.expireIdle(task.ConversationID)
+	convAgent, created, err := tcm.convAgentManager.GetOrCreateAgent(task.ConversationID)
 	if err != nil {
 		// 获取会话Agent失败
-		task.Buffer.Push(fmt.Sprintf("系统错误: %v", err))
-		task.Buffer.SetAIFinished()
-		task.mutex.Lock()
-		task.IsProcessing = false
-		task.LastUpdate = time.Now()
-		task.mutex.Unlock()
+		slog.ErrorContext(ctx, "获取会话Agent失败", "stream_id", streamID, "conversation_id", task.ConversationID, "err", err)
+		tcm.failTask(task, err)
+		tcm.publishTurnFinished(task, startTime, state, err)
 		return
 	}
+	task.LLMProvider = tcm.convAgentManager.Provider(task.ConversationID)
+	if created {
+		userID, _ := ctx.Value(requesterKey{}).(string)
+		tcm.events.Publish(events.ConversationStarted{
+			RequestID:      task.RequestID,
+			ConversationID: task.ConversationID,
+			UserID:         userID,
+			Time:           time.Now(),
+		})
+	}
+	if reset && tcm.convAgentManager.config.Session.Notice {
+		task.Buffer.Push(tcm.convAgentManager.text(task.Locale, i18n.SessionReset) + "\n\n")
+	}
 
-	// 记录调用分析
-	callCount := 0
-	chunkCount := 0
-
-	// 调用Agent进行流式处理
-	events, err := convAgent.RunStream(ctx, task.Question)
-	if err != nil {
-
-		// 推送错误信息到缓冲区
-		errorMsg := fmt.Sprintf("处理失败: %v", err)
-		task.Buffer.Push(errorMsg)
-		task.Buffer.SetAIFinished() // 标记AI完成（错误情况）
+	// 非中文消息：翻译为中文交给Agent，完成后翻译回原语言
+	question := task.Question
+	sourceLang := translate.SourceLanguage(ctx)
+	needTranslate := tcm.translator != nil && sourceLang != "" && sourceLang != translate.LangChinese
+	if needTranslate {
+		if translated, err := tcm.translator.ToChinese(ctx, question, sourceLang); err != nil {
+			slog.WarnContext(ctx, "翻译用户消息失败", "stream_id", streamID, "err", err)
+		} else {
+			question = translated
+		}
+	}
 
-		task.mutex.Lock()
-		task.IsProcessing = false
-		task.LastUpdate = time.Now()
-		task.mutex.Unlock()
-		return
+	// 需要翻译或审核回复时，Agent输出先写入临时缓冲区，处理完成后再展示
+	moderateOutput := tcm.moderator.ChecksOutput()
+	output := task.Buffer
+	if needTranslate || moderateOutput {
+		output = stream.AcquireBuffer()
+		defer output.Release()
 	}
 
+	// 调用Agent进行流式处理（尚未输出内容时失败会自动重试）
 	// ✅ 关键改造：从累积模式改为推送模式
 	// AI生成内容实时推送到StreamBuffer，供企业微信消趟
+	streamErr := tcm.runAgent(ctx, task, convAgent, question, output, state)
+
+	// 流式中途断开：携带已输出内容重新请求，继续追加到同一个StreamBuffer
+	for attempt := 1; streamErr != nil && state.hasNormalContent && attempt <= tcm.streamConfig.MaxResumeAttempts; attempt++ {
+		slog.WarnContext(ctx, "流式输出中断，尝试续传", "attempt", attempt, "max_attempts", tcm.streamConfig.MaxResumeAttempts, "stream_id", streamID, "err", streamErr)
+
+		select {
+		case <-ctx.Done():
+			streamErr = ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
+		if ctx.Err() != nil {
+			break
+		}
 
-	// 跟踪状态，用于识别final call
-	var hasToolCall bool = false
+		agentEvents, err := convAgent.RunStream(ctx, buildResumePrompt(question, output.Snapshot()))
+		if err != nil {
+			streamErr = err
+			continue
+		}
+		streamErr = tcm.consumeEvents(task, output, agentEvents, state)
+	}
+
+	// 重试用尽仍没有任何内容：展示友好提示，原始错误只记录日志（任务被终止时除外）
+	if streamErr != nil && !state.hasNormalContent && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "Agent运行失败", "stream_id", streamID, "err", streamErr)
+		output.Push(tcm.convAgentManager.withErrorCode(task.Locale, tcm.convAgentManager.text(task.Locale, i18n.RetryFailed), task.RequestID))
+	}
+
+	if output != task.Buffer {
+		answer := output.Snapshot()
+		// 审核中文回复，不合规时重新生成或替换
+		if moderateOutput {
+			answer = tcm.moderateAnswer(ctx, task, convAgent, question, answer, state)
+		}
+		// 将中文回复翻译回用户语言（保留代码块与Markdown）
+		if needTranslate {
+			if translated, err := tcm.translator.FromChinese(ctx, answer, sourceLang); err != nil {
+				slog.WarnContext(ctx, "翻译回复失败", "stream_id", streamID, "err", err)
+			} else {
+				answer = translated
+			}
+		}
+		task.Buffer.Push(answer)
+	}
+
+	// 已输出部分内容后中断且续传用尽：另起一段说明回复不完整（任务被终止时除外）
+	if streamErr != nil && state.hasNormalContent && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "续传用尽，回复不完整", "stream_id", streamID, "err", streamErr)
+		task.Buffer.Push("\n\n" + tcm.convAgentManager.withErrorCode(task.Locale, tcm.convAgentManager.text(task.Locale, i18n.ResumeFailed), task.RequestID))
+	}
 
-	var hasNormalContent bool = false // 是否有正常内容生成
+	// AI处理完成，标记缓冲区状态
+	task.mutex.Lock()
+	task.IsProcessing = false
+	task.LastUpdate = time.Now()
+	task.mutex.Unlock()
+
+	// ✅ 标记AI完成生成（但可能还有内容在缓冲区等待消费）
+	task.Buffer.SetAIFinished()
+	tcm.finishJob(task, state, streamErr)
+	tcm.continueOverflow(task)
+
+	// 语音回复：单聊用户开启语音时在后台合成并发送
+	if tcm.voice != nil && streamErr == nil && tcm.wantsVoice(task.ConversationID) {
+		go tcm.sendVoice(task)
+	}
+
+	tcm.publishTurnFinished(task, startTime, state, streamErr)
+}
+
+// failTask 以错误提示结束回复（已有内容时另起一段）
+func (tcm *TaskCacheManager) failTask(task *TaskInfo, err error) {
+	text := tcm.convAgentManager.withErrorCode(task.Locale, tcm.convAgentManager.text(task.Locale, i18n.SystemError, "error", err.Error()), task.RequestID)
+	if task.Buffer.Snapshot() != "" {
+		text = "\n\n" + text
+	}
+	task.Buffer.Push(text)
+	task.Buffer.SetAIFinished()
+	task.mutex.Lock()
+	task.IsProcessing = false
+	task.LastUpdate = time.Now()
+	task.mutex.Unlock()
+}
+
+// publishTurnFinished 发布回复结束事件
+func (tcm *TaskCacheManager) publishTurnFinished(task *TaskInfo, startTime time.Time, state *streamState, err error) {
+	metrics.ObserveLLM(startTime, err)
+	tcm.events.Publish(events.TurnFinished{
+		StreamID:       task.StreamID,
+		RequestID:      task.RequestID,
+		ConversationID: task.ConversationID,
+		Variant:        task.Variant,
+		LLMProvider:    task.LLMProvider,
+		Question:       task.Question,
+		Answer:         task.Buffer.Snapshot(),
+		ToolCalls:      state.toolCalls,
+		Duration:       time.Since(startTime),
+		Err:            err,
+		Time:           time.Now(),
+	})
+}
+
+// streamState 跨续传保持的流式处理状态
+type streamState struct {
+	started          time.Time // 回复开始时间
+	hasToolCall      bool      // 是否发生过工具调用
+	hasNormalContent bool      // 是否有正常内容生成
+	toolCalls        int       // 工具调用次数
+	step             string    // 最近调用的工具
+	stepID           string    // 最近调用的工具调用ID（同一调用会收到多个事件）
+	steps            int       // 不同的工具调用数（长任务进度）
+	jobID            int       // 转为长任务后的任务编号
+}
+
+// streamStallThreshold 流式输出停滞判定时间
+const streamStallThreshold = 30 * time.Second
+
+// consumeEvents 消费Agent事件流并推送到缓冲区，返回流中出现的错误
+func (tcm *TaskCacheManager) consumeEvents(task *TaskInfo, output *stream.Buffer, agentEvents <-chan interfaces.AgentStreamEvent, state *streamState) error {
+	var streamErr error
+
+	stallTimer := time.NewTimer(streamStallThreshold)
+	defer stallTimer.Stop()
+	lastEvent := time.Now()
+
+	// 长时间没有可见内容时在回复末尾显示进度提示
+	interim := newInterimTracker(tcm.streamConfig, tcm.convAgentManager.config.Messages, task.Locale, task.Buffer)
+	interim.content = output == task.Buffer && state.hasNormalContent // 续传时已有内容
+	defer interim.stop()
+
+	// 工具调用链运行较久时转为长任务
+	jobC, stopJobTimer := tcm.jobTimer(state)
+	defer stopJobTimer()
+
+	// 过滤工具调用后（或续传时）重复输出的已有内容
+	var repeats *bot.RepeatFilter
+	if tcm.streamConfig.SuppressRepeats {
+		repeats = &bot.RepeatFilter{}
+		if state.hasNormalContent {
+			repeats.Boundary(output.Tail(bot.RepeatWindow))
+		}
+	}
+
+	thinking := false // 是否处于未闭合的think块中
+	for {
+		var event interfaces.AgentStreamEvent
+		var ok bool
+
+		select {
+		case event, ok = <-agentEvents:
+		case <-stallTimer.C:
+			// 长时间没有新事件：发布停滞事件后继续等待
+			tcm.events.Publish(events.StreamStalled{
+				StreamID:       task.StreamID,
+				ConversationID: task.ConversationID,
+				Idle:           time.Since(lastEvent),
+				Time:           time.Now(),
+			})
+			stallTimer.Reset(streamStallThreshold)
+			continue
+		case <-interim.C():
+			interim.show()
+			continue
+		case <-interim.HeartbeatC():
+			interim.beat()
+			continue
+		case <-jobC:
+			jobC = nil
+			tcm.trackJob(task, state, interim)
+			continue
+		}
+		if !ok {
+			break
+		}
+
+		lastEvent = time.Now()
+		if !stallTimer.Stop() {
+			select {
+			case <-stallTimer.C:
+			default:
+			}
+		}
+		stallTimer.Reset(streamStallThreshold)
+
+		// 思考过程包在think标签中输出，是否展示由群聊的thinking配置决定
+		if event.Type == interfaces.AgentEventThinking {
+			if event.ThinkingStep != "" {
+				if !thinking {
+					output.Push("<think>\n")
+					thinking = true
+				}
+				output.Push(event.ThinkingStep)
+			}
+			continue
+		}
+		if thinking {
+			output.Push("\n</think>\n")
+			thinking = false
+		}
 
-	for event := range events {
 		// 检查是否有工具调用
 		if event.Type == interfaces.AgentEventToolCall {
-			hasToolCall = true
+			state.hasToolCall = true
+			state.toolCalls++
+			if event.ToolCall != nil {
+				interim.setPhase(phaseTool, event.ToolCall.Name)
+			}
+			tcm.updateJob(state, interim, event)
+			tcm.trackJob(task, state, interim)
 
 			// 不再推送工具调用提示，让用户专注于最终结果
+			if event.ToolCall != nil {
+				tcm.events.Publish(events.ToolCalled{
+					StreamID:       task.StreamID,
+					ConversationID: task.ConversationID,
+					Tool:           event.ToolCall.Name,
+					Arguments:      event.ToolCall.Arguments,
+					Time:           time.Now(),
+				})
+			}
 		} else if event.Type == interfaces.AgentEventToolResult {
 			// 工具结果不直接显示，等待AI整理后的内容
-			hasToolCall = true
+			state.hasToolCall = true
+			interim.setPhase(phaseSummarize, "")
+			tcm.updateJob(state, interim, event)
+			repeats.Boundary(output.Tail(bot.RepeatWindow))
 			// 记录工具结果用于调试
 			if event.Metadata != nil {
 				if result, ok := event.Metadata["result"].(string); ok {
-					fmt.Printf("🔧 工具结果 - %v: %s\n", event.ToolCall, result)
+					slog.Debug("工具结果", "stream_id", task.StreamID, "result", result)
 				}
 			}
+		} else if event.Type == interfaces.AgentEventError && event.Error != nil {
+			// 记录流中错误，由调用方决定是否续传
+			streamErr = event.Error
+			continue
 		}
 
 		// 检查metadata中的final_call标记
@@ -300,31 +535,21 @@ func (tcm *TaskCacheManager) processTaskAsync(ctx context.Context, streamID stri
 		}
 
 		if event.Content != "" {
-			chunkCount++
-
-			// 在final call时记录详细信息
-			if isFinalCall && hasToolCall {
-			}
-
 			// ✨ Final Call内容过滤策略
 			// 如果已经有正常内容生成，final call是多余的，应该过滤
 			// 因为agent-sdk-go在没有新工具调用时会break并触发final call
 			// 但此时AI可能已经在生成正确的最终回复
-			if isFinalCall && hasNormalContent {
+			if isFinalCall && state.hasNormalContent {
 				// 已有正常内容，过滤final call
 				continue
 			}
 
 			// 标记有正常内容生成
-			hasNormalContent = true
-
-			// 检测是否是新的调用开始（通过内容模式识别）
-			if strings.Contains(event.Content, "企业微信") && strings.Contains(event.Content, "智能助手") {
-				callCount++
-			}
+			state.hasNormalContent = true
 
 			// 通过过滤，推送到缓冲区（生产者模式）
-			task.Buffer.Push(event.Content)
+			output.Push(repeats.Filter(event.Content))
+			interim.contentArrived()
 
 			task.mutex.Lock()
 			task.LastUpdate = time.Now()
@@ -332,73 +557,87 @@ func (tcm *TaskCacheManager) processTaskAsync(ctx context.Context, streamID stri
 		}
 	}
 
-	// AI处理完成，标记缓冲区状态
-	task.mutex.Lock()
-	task.IsProcessing = false
-	task.LastUpdate = time.Now()
-	task.mutex.Unlock()
+	if thinking {
+		output.Push("\n</think>\n")
+	}
+	// 流结束时仍在比对的内容（不是重复的部分）照常输出
+	output.Push(repeats.Flush())
 
-	// ✅ 标记AI完成生成（但可能还有内容在缓冲区等待消费）
-	task.Buffer.SetAIFinished()
+	return streamErr
 }
 
-// GetAnswer 获取当前答案 - 真正的流式消费模式
-func (tcm *TaskCacheManager) GetAnswer(streamID string) string {
+// buildResumePrompt 构造续传提示词，让模型从中断处继续输出
+func buildResumePrompt(question, partial string) string {
+	return fmt.Sprintf("%s\n\n[系统提示] 你对上述问题的回答在输出过程中意外中断，已输出的内容如下：\n<partial>\n%s\n</partial>\n请直接从中断处继续输出剩余内容，不要重复已输出的部分，也不要解释中断原因。",
+		question, partial)
+}
+
+// GetAnswer 获取当前答案和是否完成 - 真正的流式消费模式
+//
+// 内容与完成状态来自缓冲区的同一次读取：群成员并发刷新同一条消息时，
+// 不会出现一个请求读到较旧的内容、却因另一个请求已读完全部内容而结束消息的情况。
+func (tcm *TaskCacheManager) GetAnswer(streamID string) (string, bool) {
 	tcm.mutex.RLock()
 	task, exists := tcm.tasks[streamID]
 	tcm.mutex.RUnlock()
 
 	if !exists {
-		return "任务不存在或已过期"
+		// 重启前中断的回复：以已生成的部分内容结束
+		if answer, ok := tcm.recoveredAnswer(streamID); ok {
+			return answer, true
+		}
+		return "任务不存在或已过期", true // 任务不存在视为已完成
 	}
 
-	// ✅ 核心改造：获取累积内容（严格按照Python示例）
-	accumulatedContent, _ := task.Buffer.GetAccumulated()
+	// ✅ 核心改造：获取累积内容（严格按照Python示例），AI完成且所有内容都已展示才算真正完成
+	accumulatedContent, allDisplayed := task.Buffer.GetAccumulated()
+	if task.HideThinking {
+		accumulatedContent = stream.StripThinkTags(accumulatedContent)
+	}
 
 	// 更新任务状态
 	task.mutex.Lock()
 	task.LastUpdate = time.Now()
+	isFinished := allDisplayed && !task.IsProcessing
 	task.mutex.Unlock()
 
 	// ✅ 关键：返回累积的完整内容（企业微信用此替换整个消息）
-	return accumulatedContent
-}
-
-// IsTaskFinish 检查任务是否完成 - 基于StreamBuffer的真正流式架构
-func (tcm *TaskCacheManager) IsTaskFinish(streamID string) bool {
-	tcm.mutex.RLock()
-	task, exists := tcm.tasks[streamID]
-	tcm.mutex.RUnlock()
-
-	if !exists {
-		return true // 任务不存在视为已完成
-	}
-
-	task.mutex.RLock()
-	defer task.mutex.RUnlock()
-
-	// ✅ 新逻辑：AI完成且所有内容都已展示才算真正完成
-	// 这确保了所有生成的内容都被企业微信展示完毕
-	aiFinished := !task.IsProcessing && task.Buffer.IsAIFinished()
-	allDisplayed := task.Buffer.IsEmpty() // 在累积模式下，IsEmpty表示所有内容都已展示
-	isFinished := aiFinished && allDisplayed
-
-	return isFinished
+	return accumulatedContent, isFinished
 }
 
 // ConversationAgent 会话级Agent
 type ConversationAgent struct {
 	agentInstance *agent.Agent
+	memory        interfaces.Memory // 会话记忆（配置变更重建Agent时沿用）
+	generation    int               // 创建时的配置版本
+	restricted    bool              // 是否按非营业时间受限模式创建
+	downgraded    bool              // 是否因超出用量预算改用备用模型创建
+	provider      string            // 使用的LLM提供商
 	lastActivity  time.Time
 	mutex         sync.RWMutex
 }
 
 // ConversationAgentManager 会话级Agent管理器
 type ConversationAgentManager struct {
-	agents     map[string]*ConversationAgent // conversationID -> agent
-	config     *config.Config
-	mcpServers []interfaces.MCPServer
-	mutex      sync.RWMutex
+	agents      map[string]*ConversationAgent // conversationID -> agent
+	config      *config.Config
+	mcpServers  []mcp.NamedServer
+	groups      *GroupSettings        // 群聊级配置
+	personas    *personaSelections    // 会话通过 /persona 选择的人设
+	profiles    *profile.Store        // 用户画像（未启用时为nil）
+	prefs       *preferences.Store    // 用户偏好（未启用时为nil）
+	knowledge   *knowledge.Store      // 知识库（未启用时为nil）
+	transfer    handoff.TransferFunc  // 转人工（未启用时为nil，启用后为Agent提供transfer_to_human工具）
+	approve     ApproveFunc           // 工具调用审批（未启用时为nil）
+	imageTool   *imagegen.Tool        // 图片生成（未启用时为nil）
+	ticketTools []interfaces.Tool     // 工单工具（未启用时为nil）
+	directory   *directory.Directory  // 通讯录（未启用时为nil）
+	hours       *policy.BusinessHours // 营业时间（未启用时为nil）
+	budget      *budgetGuard          // 用量预算（未启用时为nil）
+	warm        *warmPool             // 预热池（未启用时为nil）
+	fetch       fetch.Options         // http_get工具选项（各会话共享同一连接池，配置热更新时重建）
+	generation  int                   // 配置版本，配置热更新时递增
+	mutex       sync.RWMutex
 }
 
 // BotHandler 机器人处理器
@@ -407,118 +646,547 @@ type BotHandler struct {
 	convAgentManager *ConversationAgentManager // 会话级Agent管理器
 	taskCache        *TaskCacheManager
 	mcpServers       []interfaces.MCPServer
-	logger           *ChatLogger // 聊天日志记录器
+	logger           *ChatLogger          // 聊天日志记录器
+	translator       *translate.Service   // 翻译服务（未启用时为nil）
+	notifier         *notify.Batcher      // 主动通知合并器（未启用时为nil）
+	reloadMutex      sync.Mutex           // 串行化配置热更新
+	events           *events.Bus          // 事件总线（指标、审计、告警等横切功能订阅）
+	usage            *usageCounter        // 使用统计（管理接口）
+	scheduler        *scheduler.Scheduler // 定时任务
+	handoff          *handoff.Manager     // 转人工（未启用时为nil）
+	handoffSender    notify.Sender        // 客服群消息发送
+	approvals        *approval.Manager    // 工具调用审批（未启用时为nil）
+	analytics        *analyticsRecorder   // 会话统计（未启用时为nil）
+	queue            queue.Broker         // 消息队列模式下的任务队列（未启用时为nil）
+	name             string               // 机器人名称（入队任务据此分派到worker中的同名机器人）
+	welcome          *welcome.Store       // 已欢迎的会话（未启用首次对话欢迎时为nil）
+	summaries        *sessionSummaries    // 会话空闲后生成摘要（未启用时为nil）
+	budget           *budgetGuard         // 用量预算（未启用时为nil）
 }
 
 // NewConversationAgentManager 创建会话级Agent管理器
-func NewConversationAgentManager(config *config.Config, mcpServers []interfaces.MCPServer) *ConversationAgentManager {
+func NewConversationAgentManager(config *config.Config, mcpServers []mcp.NamedServer) *ConversationAgentManager {
+	warm := newWarmPool(config)
 	return &ConversationAgentManager{
 		agents:     make(map[string]*ConversationAgent),
 		config:     config,
-		mcpServers: mcpServers,
+		mcpServers: warm.wrapServers(mcpServers),
+		groups:     NewGroupSettings(config),
+		personas:   newPersonaSelections(),
+		hours:      parseBusinessHours(config),
+		warm:       warm,
+		fetch:      fetchOptions(config.Fetch),
+	}
+}
+
+// Features 获取会话的功能开关（/persona 选择的人设优先于路由选定的专家）
+func (cam *ConversationAgentManager) Features(conversationID string) config.Features {
+	persona := cam.Persona(conversationID)
+	if persona == "" {
+		persona = cam.routedPersona(conversationID)
+	}
+	return cam.groups.Features(conversationID, persona)
+}
+
+// ResetAgent 标记会话Agent过期，下次消息时沿用会话记忆按最新配置重建
+func (cam *ConversationAgentManager) ResetAgent(conversationID string) {
+	cam.mutex.Lock()
+	defer cam.mutex.Unlock()
+
+	if convAgent, exists := cam.agents[conversationID]; exists {
+		convAgent.generation = -1
+	}
+}
+
+// Reload 应用新配置：所有会话Agent在下次消息时按新配置重建（进行中的回复不受影响）
+func (cam *ConversationAgentManager) Reload(cfg *config.Config, mcpServers []mcp.NamedServer) {
+	cam.mutex.Lock()
+	defer cam.mutex.Unlock()
+
+	cam.config = cfg
+	cam.mcpServers = mcpServers
+	cam.generation++
+	cam.groups.Reload(cfg)
+	cam.hours = parseBusinessHours(cfg)
+	cam.fetch.Transport.CloseIdleConnections()
+	cam.fetch = fetchOptions(cfg.Fetch)
+	if cam.warm != nil {
+		cam.warm.reload(cfg)
 	}
 }
 
-// GetOrCreateAgent 获取或创建会话Agent
-func (cam *ConversationAgentManager) GetOrCreateAgent(conversationID string) (*agent.Agent, error) {
+// GetOrCreateAgent 获取或创建会话Agent，created表示新建了会话（而非复用或按新配置重建）
+func (cam *ConversationAgentManager) GetOrCreateAgent(conversationID string) (agentInstance *agent.Agent, created bool, err error) {
 	cam.mutex.Lock()
 	defer cam.mutex.Unlock()
 
+	restricted := cam.restricted(time.Now())
+	downgraded := cam.downgraded(conversationID)
+
 	// 检查是否已存在
 	if convAgent, exists := cam.agents[conversationID]; exists {
 		convAgent.mutex.Lock()
+		defer convAgent.mutex.Unlock()
 		convAgent.lastActivity = time.Now()
-		convAgent.mutex.Unlock()
+
 		// 复用会话Agent
-		return convAgent.agentInstance, nil
+		if convAgent.generation == cam.generation && convAgent.restricted == restricted && convAgent.downgraded == downgraded {
+			return convAgent.agentInstance, false, nil
+		}
+
+		// 配置已变更、进出营业时间或超出用量预算：沿用会话记忆按新配置重建
+		features := cam.agentFeatures(conversationID, restricted, downgraded)
+		newAgent, _, err := cam.createAgent(conversationID, features, convAgent.memory)
+		if err != nil {
+			return nil, false, err
+		}
+		convAgent.agentInstance = newAgent
+		convAgent.generation = cam.generation
+		convAgent.restricted = restricted
+		convAgent.downgraded = downgraded
+		convAgent.provider = cam.providerName(features)
+		return newAgent, false, nil
 	}
 
-	// 创建新的Agent
 	// 创建新会话Agent
-	newAgent, err := cam.createNewAgent()
+	features := cam.agentFeatures(conversationID, restricted, downgraded)
+	newAgent, mem, err := cam.createAgent(conversationID, features, nil)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// 保存到缓存
 	cam.agents[conversationID] = &ConversationAgent{
 		agentInstance: newAgent,
+		memory:        mem,
+		generation:    cam.generation,
+		restricted:    restricted,
+		downgraded:    downgraded,
+		provider:      cam.providerName(features),
 		lastActivity:  time.Now(),
 	}
 
-	return newAgent, nil
+	return newAgent, true, nil
+}
+
+// fetchOptions 将配置转换为http_get工具选项（附带按同一策略检查地址的共享Transport）
+func fetchOptions(cfg config.FetchConfig) fetch.Options {
+	options := fetch.Options{
+		Policy: fetch.Policy{
+			Allow:        cfg.Allow,
+			Deny:         cfg.Deny,
+			AllowPrivate: cfg.AllowPrivate,
+		},
+		MaxBytes: cfg.MaxBytes,
+		Timeout:  time.Duration(cfg.Timeout) * time.Second,
+		AuditLog: cfg.AuditLog,
+	}
+	options.Transport = fetch.NewTransport(options.Policy, options.Timeout)
+	return options
+}
+
+// agentFeatures 会话Agent的功能开关，restricted为true时按非营业时间受限模式，downgraded为true时改用预算备用模型
+func (cam *ConversationAgentManager) agentFeatures(conversationID string, restricted, downgraded bool) config.Features {
+	features := cam.applyPreferences(conversationID, cam.Features(conversationID))
+	if variant, ok := cam.config.Experiment.Assign(conversationID); ok {
+		features = variant.Apply(features)
+	}
+	if restricted {
+		features = cam.restrict(features)
+	}
+	if downgraded {
+		features.LLMProvider = cam.budget.config.Fallback
+	}
+	return features
+}
+
+// providerName 功能开关实际使用的LLM提供商（与createAgent的选择一致）
+func (cam *ConversationAgentManager) providerName(features config.Features) string {
+	if features.LLMProvider != "" {
+		return features.LLMProvider
+	}
+	if override := os.Getenv("LLM_PROVIDER"); override != "" {
+		return override
+	}
+	return cam.config.LLM.Default
+}
+
+// Provider 获取会话Agent当前使用的LLM提供商（会话不存在时返回空）
+func (cam *ConversationAgentManager) Provider(conversationID string) string {
+	cam.mutex.RLock()
+	defer cam.mutex.RUnlock()
+
+	convAgent, exists := cam.agents[conversationID]
+	if !exists {
+		return ""
+	}
+	convAgent.mutex.RLock()
+	defer convAgent.mutex.RUnlock()
+	return convAgent.provider
 }
 
-// createNewAgent 创建新的Agent实例
-func (cam *ConversationAgentManager) createNewAgent() (*agent.Agent, error) {
-	logger := logging.New()
+// createAgent 按指定功能开关创建Agent实例（定时任务等场景可在会话配置上叠加人设）
+func (cam *ConversationAgentManager) createAgent(conversationID string, features config.Features, mem interfaces.Memory) (*agent.Agent, interfaces.Memory, error) {
+	logger := applog.SDK()
 
-	// 使用LLM工厂创建LLM客户端
-	llmClient, err := llm.CreateLLMFromConfig(cam.config, logger)
+	// 使用LLM工厂创建LLM客户端（会话覆盖可指定提供商，启用预热池时从池中取用）
+	llmClient, err := cam.newLLM(features, logger)
 	if err != nil {
-		return nil, fmt.Errorf("创建LLM客户端失败: %w", err)
+		return nil, nil, fmt.Errorf("创建LLM客户端失败: %w", err)
 	}
 
-	// 创建工具注册器
+	// 创建工具注册器（按会话覆盖的工具白名单筛选）
 	toolRegistry := tools.NewRegistry()
+	var localTools []interfaces.Tool
+	if cam.knowledge != nil && features.Knowledge {
+		localTools = append(localTools, knowledge.NewSearchTool(cam.knowledge, cam.config.Knowledge.TopK))
+	}
+	if cam.config.Fetch.Enabled {
+		localTools = append(localTools, fetch.NewTool(cam.fetch, conversationID))
+	}
+	if cam.transfer != nil && features.Handoff {
+		localTools = append(localTools, handoff.NewTool(conversationID, cam.transfer))
+	}
+	if cam.imageTool != nil {
+		localTools = append(localTools, cam.imageTool)
+	}
+	localTools = append(localTools, cam.ticketTools...)
+	for _, tool := range localTools {
+		if !features.AllowsTool(tool.Name()) {
+			continue
+		}
+		if cam.approve != nil {
+			tool = &approvalTool{Tool: tool, approve: cam.approve}
+		}
+		toolRegistry.Register(tool)
+	}
+
+	// 按群聊配置筛选可用的MCP服务器
+	var mcpServers []interfaces.MCPServer
+	for _, server := range cam.mcpServers {
+		if !features.AllowsMCPServer(server.Name) {
+			continue
+		}
+		mcpServer := server.Server
+		if len(features.AllowedTools) > 0 {
+			mcpServer = newToolFilterServer(mcpServer, features.AllowsTool)
+		}
+		if cam.approve != nil {
+			mcpServer = &approvalServer{MCPServer: mcpServer, approve: cam.approve}
+		}
+		mcpServers = append(mcpServers, mcpServer)
+	}
+
+	systemPrompt := cam.buildSystemPrompt(conversationID, features)
 
 	// 创建Agent
 	var agentInstance *agent.Agent
 
-	if len(cam.mcpServers) > 0 {
+	if len(mcpServers) > 0 {
+		if mem == nil {
+			mem = memory.NewConversationBuffer(memory.WithMaxSize(3))
+		}
 		agentInstance, err = agent.NewAgent(
 			agent.WithLLM(llmClient),
-			agent.WithMemory(memory.NewConversationBuffer(memory.WithMaxSize(3))),
+			agent.WithMemory(mem),
 			agent.WithTools(toolRegistry.List()...),
-			agent.WithMCPServers(cam.mcpServers),
+			agent.WithMCPServers(mcpServers),
 			agent.WithRequirePlanApproval(false),
-			agent.WithSystemPrompt(cam.config.LLM.SystemPrompt),
+			agent.WithSystemPrompt(systemPrompt),
 			agent.WithMaxIterations(5), // 增加迭代次数，避免过早触发final call
 			agent.WithName("AIBodyWeWorkAssistant"),
 		)
 	} else {
+		if mem == nil {
+			mem = memory.NewConversationBuffer()
+		}
 		agentInstance, err = agent.NewAgent(
 			agent.WithLLM(llmClient),
-			agent.WithMemory(memory.NewConversationBuffer()),
+			agent.WithMemory(mem),
 			agent.WithTools(toolRegistry.List()...),
-			agent.WithSystemPrompt(cam.config.LLM.SystemPrompt),
+			agent.WithRequirePlanApproval(false),
+			agent.WithSystemPrompt(systemPrompt),
 			agent.WithMaxIterations(5), // 增加迭代次数，避免过早触发final call
 			agent.WithName("AIBodyWeWorkAssistant"),
 		)
 	}
 
-	return agentInstance, err
+	return agentInstance, mem, err
+}
+
+// buildSystemPrompt 构建系统提示词（会话覆盖可替换），追加群聊专属说明，单聊时注入通讯录身份和用户画像背景
+func (cam *ConversationAgentManager) buildSystemPrompt(conversationID string, features config.Features) string {
+	prompt := cam.config.LLM.SystemPrompt
+	if features.SystemPrompt != "" {
+		prompt = features.SystemPrompt
+	}
+	if features.ExtraPrompt != "" {
+		prompt += "\n\n# 本群说明\n" + features.ExtraPrompt
+	}
+	if features.Notice != "" {
+		prompt += "\n\n# 当前状态\n" + features.Notice
+	}
+	if p, ok := cam.userPreferences(conversationID); ok {
+		if text := p.Prompt(); text != "" {
+			prompt += "\n\n# 用户偏好\n" + text
+		}
+	}
+	userID, ok := strings.CutPrefix(conversationID, "single_")
+	if !ok {
+		return prompt
+	}
+
+	if cam.directory != nil {
+		if p, found := cam.directory.Lookup(context.Background(), userID); found {
+			prompt += "\n\n# 当前用户\n" + p.Prompt()
+		}
+	}
+	if cam.profiles == nil {
+		return prompt
+	}
+	if summary := cam.profiles.Summary(userID); summary != "" {
+		prompt += "\n\n# 用户背景\n" + summary
+	}
+	return prompt
 }
 
 // NewBotHandler 创建机器人处理器
 func NewBotHandler(cfg *config.Config) (*BotHandler, error) {
 	// 创建MCP服务器
-	mcpServers, err := mcp.CreateMCPServersFromConfig(cfg)
+	namedServers, err := mcp.CreateNamedMCPServersFromConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("创建MCP服务器失败: %w", err)
 	}
 
 	handler := &BotHandler{
 		config:     cfg,
-		mcpServers: mcpServers,
+		mcpServers: mcp.ServersOf(namedServers),
+		events:     events.NewBus(),
 	}
+	handler.usage = newUsageCounter(handler.events)
 
 	// 创建会话级Agent管理器
-	handler.convAgentManager = NewConversationAgentManager(cfg, mcpServers)
+	handler.convAgentManager = NewConversationAgentManager(cfg, namedServers)
+
+	// 加载用户画像和知识库（如果启用）
+	if cfg.Profile.Enabled {
+		profiles, err := profile.NewStore(cfg.Profile.Path)
+		if err != nil {
+			return nil, fmt.Errorf("加载用户画像失败: %w", err)
+		}
+		handler.convAgentManager.profiles = profiles
+	}
+	if cfg.Preferences.Enabled {
+		prefs, err := preferences.NewStore(cfg.Preferences.Path)
+		if err != nil {
+			return nil, fmt.Errorf("加载用户偏好失败: %w", err)
+		}
+		handler.convAgentManager.prefs = prefs
+	}
+	if cfg.Knowledge.Enabled {
+		kb, err := knowledge.NewStore(cfg.Knowledge.Path, cfg.Knowledge.ChunkSize)
+		if err != nil {
+			return nil, fmt.Errorf("加载知识库失败: %w", err)
+		}
+		handler.convAgentManager.knowledge = kb
+	}
 
 	// 初始化任务缓存管理器
-	handler.taskCache = NewTaskCacheManager(handler.convAgentManager)
+	handler.taskCache = NewTaskCacheManager(handler.convAgentManager, cfg.Stream)
+	handler.taskCache.events = handler.events
+	handler.taskCache.continuation = newContinuationChannels(cfg.Stream.Continuation, handler.convAgentManager.groups)
+
+	// 打开预写记录（如果配置），读回重启前中断的回复
+	journal, err := openStreamJournal(cfg.Stream.Journal)
+	if err != nil {
+		return nil, err
+	}
+	handler.taskCache.journal = journal
+
+	// 初始化翻译服务（如果启用）
+	translator, err := translate.NewServiceFromConfig(cfg, applog.SDK())
+	if err != nil {
+		return nil, fmt.Errorf("创建翻译服务失败: %w", err)
+	}
+	handler.translator = translator
+	handler.taskCache.translator = translator
+
+	// 初始化图片文字识别（如果启用）
+	images, err := newImageReader(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建图片文字识别失败: %w", err)
+	}
+	handler.taskCache.images = images
+
+	// 初始化语音回复（如果启用）
+	voice, err := newVoiceReply(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建语音回复失败: %w", err)
+	}
+	handler.taskCache.voice = voice
+
+	// 初始化图片生成（如果启用）
+	imageTool, err := newImageTool(cfg, handler.taskCache)
+	if err != nil {
+		return nil, fmt.Errorf("创建图片生成失败: %w", err)
+	}
+	handler.convAgentManager.imageTool = imageTool
+
+	// 初始化工单工具（如果启用）
+	ticketTools, err := newTicketTools(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建工单工具失败: %w", err)
+	}
+	handler.convAgentManager.ticketTools = ticketTools
+
+	// 初始化通讯录（如果启用），在后台加载并定期刷新
+	handler.convAgentManager.directory = directory.New(cfg.Directory)
+	if ticketTools != nil {
+		slog.Info("工单工具", "provider", cfg.Ticket.Provider, "base_url", cfg.Ticket.BaseURL)
+	}
+
+	// 初始化长任务跟踪（如果启用）
+	tracker, err := newJobTracker(cfg.Jobs)
+	if err != nil {
+		return nil, fmt.Errorf("加载长任务记录失败: %w", err)
+	}
+	handler.taskCache.jobs = tracker
+	handler.taskCache.jobsAfter = time.Duration(cfg.Jobs.After) * time.Second
+	if imageTool != nil {
+		slog.Info("图片生成", "provider", cfg.ImageGen.Provider, "model", cfg.ImageGen.Model)
+	}
+
+	// 初始化内容审核（如果启用）
+	moderator, err := newModerator(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建内容审核失败: %w", err)
+	}
+	handler.taskCache.moderator = moderator
+	handler.taskCache.moderation = cfg.Moderation
+	if moderator != nil {
+		subscribeModerationAudit(handler.events, cfg.Moderation.AuditLog)
+	}
+
+	// 初始化多智能体路由（如果启用）
+	taskRouter, err := newRouter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	handler.taskCache.router = taskRouter
+	if taskRouter != nil {
+		slog.Info("多智能体路由", "specialists", strings.Join(cfg.SpecialistNames(), ", "))
+	}
+
+	// 初始化主动通知（如果启用）
+	if cfg.Notify.Enabled {
+		handler.notifier = notify.NewBatcher(notify.NewWebhookSender(cfg.Notify.WebhookURL), notify.Options{
+			Window:     time.Duration(cfg.Notify.BatchWindow) * time.Second,
+			MaxBatch:   cfg.Notify.MaxBatchSize,
+			Categories: cfg.Notify.Categories,
+		})
+	}
+
+	// 初始化转人工（如果启用）
+	if cfg.Handoff.Enabled {
+		manager, err := handoff.NewManager(cfg.Handoff.Path)
+		if err != nil {
+			return nil, fmt.Errorf("加载转人工状态失败: %w", err)
+		}
+		handler.handoff = manager
+		handler.handoffSender = notify.NewWebhookSender(cfg.Handoff.WebhookURL)
+		handler.convAgentManager.transfer = func(conversationID, reason string) (handoff.Session, error) {
+			userID, _ := strings.CutPrefix(conversationID, "single_")
+			return handler.transferToHuman(conversationID, userID, reason)
+		}
+		if sessions := manager.List(); len(sessions) > 0 {
+			slog.Info("转人工中的会话", "count", len(sessions))
+		}
+	}
+
+	// 初始化首次对话欢迎（如果启用）
+	if cfg.Welcome.Enabled {
+		store, err := welcome.NewStore(cfg.Welcome.Path)
+		if err != nil {
+			return nil, fmt.Errorf("加载欢迎记录失败: %w", err)
+		}
+		handler.welcome = store
+	}
+
+	// 初始化工具调用审批（如果启用）
+	if cfg.Approval.Enabled {
+		handler.approvals = approval.NewManager(cfg.Approval.Tools, time.Duration(cfg.Approval.Timeout)*time.Second)
+		handler.convAgentManager.approve = handler.approveToolCall
+		slog.Info("工具调用审批", "tools", strings.Join(cfg.Approval.Tools, ", "))
+	}
+
+	// 初始化会话统计（如果启用）
+	recorder, err := newAnalyticsRecorder(cfg, handler.events)
+	if err != nil {
+		return nil, fmt.Errorf("初始化会话统计失败: %w", err)
+	}
+	handler.analytics = recorder
+	if recorder != nil {
+		slog.Info("会话统计", "path", cfg.Analytics.Path)
+	}
+
+	// 启动定时任务
+	handler.scheduler = scheduler.New()
+	handler.scheduler.Set(handler.scheduleJobs(cfg))
+	handler.scheduler.Start()
+	if len(cfg.Schedules) > 0 {
+		slog.Info("定时任务", "count", len(cfg.Schedules))
+	}
 
 	// 初始化日志记录器（如果启用）
 	if cfg.Logging.Enabled {
-		logger, err := NewChatLogger(cfg.Logging.LogDir)
+		logger, err := NewChatLogger(cfg.Logging)
 		if err != nil {
-			// 日志初始化失败不影响主程序运行，只打印警告
+			// 日志初始化失败不影响主程序运行，只记录警告
+			slog.Warn("聊天日志初始化失败，不记录聊天记录", "dir", cfg.Logging.LogDir, "err", err)
 		} else {
 			handler.logger = logger
+			// 记录用户消息到日志文件
+			events.Subscribe(handler.events, func(e events.MessageReceived) {
+				if err := logger.LogMessage(e.ConversationID, e.RequestID, e.UserID, e.Content); err != nil {
+					// 日志记录失败不影响主流程
+					slog.Warn("记录聊天日志失败", "conversation_id", e.ConversationID, "err", err)
+				}
+			})
+			// 记录AI回复（A/B实验中的回复标注变体，便于对比）
+			events.Subscribe(handler.events, func(e events.TurnFinished) {
+				if e.Answer == "" {
+					return
+				}
+				sender := chatLogAISender
+				if e.Variant != "" {
+					sender += "@" + e.Variant
+				}
+				logger.LogMessage(e.ConversationID, e.RequestID, sender, e.Answer)
+			})
 		}
 	}
 
+	// 初始化用量预算（如果启用）
+	guard, err := newBudgetGuard(cfg, handler.events)
+	if err != nil {
+		return nil, fmt.Errorf("加载用量预算失败: %w", err)
+	}
+	handler.budget = guard
+	handler.convAgentManager.budget = guard
+	if guard != nil {
+		slog.Info("用量预算", "path", cfg.Budget.Path)
+	}
+
+	// 初始化会话摘要（如果启用，需在日志记录器之后）
+	summaries, err := newSessionSummaries(cfg, handler.events, handler.logger, handler.convAgentManager.profiles)
+	if err != nil {
+		return nil, fmt.Errorf("初始化会话摘要失败: %w", err)
+	}
+	handler.summaries = summaries
+	if summaries != nil {
+		slog.Info("会话摘要", "idle_minutes", cfg.Summary.IdleAfter)
+	}
+
 	return handler, nil
 }
 
@@ -530,19 +1198,93 @@ func (b *BotHandler) Close() {
 	if b.convAgentManager != nil {
 		b.convAgentManager.Close()
 	}
+	if b.scheduler != nil {
+		b.scheduler.Stop()
+	}
+	if b.convAgentManager != nil && b.convAgentManager.directory != nil {
+		b.convAgentManager.directory.Close()
+	}
+	// 发送尚在合并窗口中的通知
+	if b.notifier != nil {
+		b.notifier.Close()
+	}
 	// 关闭所有MCP服务器
 	for _, server := range b.mcpServers {
 		if closer, ok := server.(interface{ Close() error }); ok {
 			closer.Close()
 		}
 	}
+	// 写完剩余统计后关闭数据库
+	if b.analytics != nil {
+		if err := b.analytics.Close(); err != nil {
+			slog.Warn("关闭统计数据库失败", "err", err)
+		}
+	}
+	if b.summaries != nil {
+		b.summaries.Close()
+	}
 	// 关闭日志记录器
 	if b.logger != nil {
 		if err := b.logger.Close(); err != nil {
+			slog.Warn("关闭聊天日志记录器失败", "err", err)
 		}
 	}
 }
 
+// Notify 向会话主动推送通知（低优先级通知按窗口合并为摘要）
+func (b *BotHandler) Notify(n notify.Notification) error {
+	if b.notifier == nil {
+		return fmt.Errorf("主动通知未启用")
+	}
+	if !b.convAgentManager.Features(n.Target).Proactive {
+		return fmt.Errorf("会话 %s 已禁用主动推送", n.Target)
+	}
+	if !b.notifyAllowed(n.Target, n.Category) {
+		return fmt.Errorf("用户已通过 /settings 关闭主动通知: %s", n.Target)
+	}
+	if err := b.notifier.Notify(n); err != nil {
+		return err
+	}
+	b.events.Publish(events.NotificationSent{
+		Target:   n.Target,
+		Category: n.Category,
+		Title:    n.Title,
+		Content:  n.Content,
+		Time:     n.Time,
+	})
+	return nil
+}
+
+// Org 获取机器人所属的组织ID
+func (b *BotHandler) Org() string {
+	return b.config.Org()
+}
+
+// Events 获取事件总线（供指标、审计、告警等模块订阅）
+func (b *BotHandler) Events() *events.Bus {
+	return b.events
+}
+
+// GroupSettings 获取群聊级配置
+func (b *BotHandler) GroupSettings() *GroupSettings {
+	return b.convAgentManager.groups
+}
+
+// SetGroupConfig 更新群聊配置，并重建该群的会话Agent使其生效
+func (b *BotHandler) SetGroupConfig(chatID string, group config.GroupConfig) {
+	b.convAgentManager.groups.Set(chatID, group)
+	b.convAgentManager.ResetAgent("group_" + chatID)
+}
+
+// DeleteGroupConfig 删除群聊配置（恢复全局默认）
+func (b *BotHandler) DeleteGroupConfig(chatID string) bool {
+	deleted := b.convAgentManager.groups.Delete(chatID)
+	if deleted {
+		b.convAgentManager.ResetAgent("group_" + chatID)
+	}
+	return deleted
+}
+
 // Close 关闭会话Agent管理器
 func (cam *ConversationAgentManager) Close() {
 	cam.mutex.Lock()
@@ -556,22 +1298,105 @@ func (cam *ConversationAgentManager) Close() {
 
 // HandleMessage 处理普通消息
 func (b *BotHandler) HandleMessage(msg *wework.IncomingMessage) (*wework.WeWorkResponse, error) {
-	// 提取文本内容
+	// 工具调用审批（卡片按钮点击和审批命令）不经过Agent
+	if resp, handled := b.handleApprovalMessage(msg); handled {
+		return resp, nil
+	}
+	if msg.MsgType == wework.MsgTypeEvent {
+		return nil, nil // 其他事件无需回复
+	}
+
+	// 通讯录访问控制：不在允许范围内的用户直接回复说明
+	if resp, denied := b.handleAccessDenied(msg); denied {
+		return resp, nil
+	}
+
+	// 知识库管理（/kb 命令和文件上传）不经过Agent
+	if resp, handled := b.handleKnowledgeMessage(msg); handled {
+		return resp, nil
+	}
+
+	// 转人工：客服群命令、用户要求转人工、转人工期间的消息不经过Agent
+	if resp, handled := b.handleHandoffMessage(msg); handled {
+		return resp, nil
+	}
+
+	// 人设切换（/persona 命令）不经过Agent
+	if resp, handled := b.handlePersonaMessage(msg); handled {
+		return resp, nil
+	}
+
+	// 个人设置（/settings 命令）不经过Agent
+	if resp, handled := b.handlePreferencesMessage(msg); handled {
+		return resp, nil
+	}
+
+	// 长任务查询（/status 命令）不经过Agent
+	if resp, handled := b.handleJobsMessage(msg); handled {
+		return resp, nil
+	}
+
+	// 负面反馈（/feedback 命令）不经过Agent
+	if resp, handled := b.handleFeedbackMessage(msg); handled {
+		return resp, nil
+	}
+
+	// 系统消息（占位内容、错误提示等）的语言
+	locale := b.userLocale(msg.From.UserID)
+
+	// 提取文本内容（启用图片文字识别时，图片随任务识别后加入问题）
 	textContent := msg.GetTextContent()
+	var imageURLs []string
+	if b.taskCache.images != nil {
+		imageURLs = msg.GetImageURLs()
+	}
+	if textContent == "" && len(imageURLs) > 0 {
+		textContent = b.text(locale, i18n.ImageOnly)
+	}
 	if textContent == "" {
 		// 如果有图片但没有文本，提供默认提示
 		if len(msg.GetImageURLs()) > 0 {
-			return wework.NewTextResponse("我收到了您发送的图片，但目前暂不支持图片分析功能。您可以用文字描述问题，我来帮您解答。"), nil
+			return wework.NewTextResponse(b.text(locale, i18n.ImageUnsupported)), nil
 		}
 		return nil, nil // 无需回复
 	}
 
-	// 统一为所有消息添加用户信息
-	messageWithUserInfo := fmt.Sprintf("[用户 %s]: %s", msg.From.UserID, textContent)
+	// 营业时间：非营业时间自动回复（命令类消息已在上方处理，不受影响）
+	if resp, handled := b.handleOffHours(msg); handled {
+		return resp, nil
+	}
+
+	// 内容审核：被拦截的消息直接回复策略提示
+	if resp, blocked := b.moderateInput(msg, textContent); blocked {
+		return resp, nil
+	}
+
+	// 用量预算：超出限额且未配置备用模型时直接说明原因
+	if resp, handled := b.handleOverBudget(msg); handled {
+		return resp, nil
+	}
+
+	// 请求ID：随上下文传递到日志、LLM和MCP调用，出错时作为错误码告知用户
+	requestID := msg.RequestID
+	if requestID == "" {
+		requestID = wework.NewRequestID()
+	}
 
 	// 创建上下文
 	ctx := context.Background()
-	ctx = multitenancy.WithOrgID(ctx, "wework-org")
+	ctx = multitenancy.WithOrgID(ctx, b.config.Org())
+	ctx = applog.WithRequestID(ctx, requestID)
+	ctx = i18n.WithLocale(ctx, locale)
+
+	// 统一为所有消息添加用户信息（启用通讯录时附带姓名、部门和职务）
+	messageWithUserInfo := fmt.Sprintf("[用户 %s]: %s", b.userLabel(ctx, msg.From.UserID), textContent)
+	ctx = withRequester(ctx, msg.From.UserID)
+	if b.translator != nil {
+		ctx = translate.WithSourceLanguage(ctx, translate.DetectLanguage(textContent))
+	}
+	if len(imageURLs) > 0 {
+		ctx = withImages(ctx, imageURLs)
+	}
 	// ✅ 注意：conversation ID已移至processTaskAsync中使用streamID设置
 	// 这样确保每个任务有独立的对话上下文，避免memory污染
 
@@ -579,50 +1404,82 @@ func (b *BotHandler) HandleMessage(msg *wework.IncomingMessage) (*wework.WeWorkR
 	// 使用稳定的会话ID确保对话连续性
 	conversationID := msg.GetConversationKey()
 
-	// 记录用户消息到日志文件
-	if b.logger != nil {
-		if err := b.logger.LogMessage(conversationID, msg.From.UserID, textContent); err != nil {
-			// 日志记录失败不影响主流程
-		}
+	b.events.Publish(events.MessageReceived{
+		RequestID:      requestID,
+		ConversationID: conversationID,
+		UserID:         msg.From.UserID,
+		ChatID:         msg.ChatID,
+		Content:        textContent,
+		Time:           time.Now(),
+	})
+
+	// 消息队列模式：入队后由worker处理，回复通过共享状态返回
+	var streamID string
+	var err error
+	if b.queue != nil {
+		streamID, err = b.enqueueTask(ctx, msg.From.UserID, messageWithUserInfo, conversationID)
+	} else {
+		streamID, err = b.taskCache.Invoke(ctx, messageWithUserInfo, conversationID)
 	}
-
-	streamID, err := b.taskCache.Invoke(ctx, messageWithUserInfo, conversationID)
 	if err != nil {
-		return wework.NewTextResponse("系统忙，请稍后再试"), err
+		slog.ErrorContext(ctx, "创建任务失败", "conversation_id", conversationID, "err", err)
+		return wework.NewTextResponse(b.convAgentManager.withErrorCode(locale, b.text(locale, i18n.Busy), requestID)), err
 	}
 
-	// 2. 获取第一步答案（模拟Python LLMDemo.get_answer()）
-	answer := b.taskCache.GetAnswer(streamID)
-
-	// 3. 检查是否完成（模拟Python LLMDemo.is_task_finish()）
-	finish := b.taskCache.IsTaskFinish(streamID)
-
-	// ✅ 优化返回策略：首次必须有内容，即使AI还在处理中
-	if answer == "" && !finish {
-		// 如果没有内容且未完成，返回处理中提示
-		answer = "正在为您思考中..."
+	// 立即返回占位内容，不读取后台任务的进度（避免与AI处理竞争），回复内容全部由刷新请求获取
+	// 新会话的首次对话：在回复下方附带欢迎卡片
+	if card := b.welcomeCard(conversationID, msg.From.UserID); card != nil {
+		return wework.NewStreamWithCardResponse(streamID, b.text(locale, i18n.Pending), false, card), nil
 	}
 
-	// 记录初始返回内容
-
-	// 4. 返回stream消息（模拟Python MakeTextStream + EncryptMessage）
 	// 关键：finish=false时企业微信会发送刷新请求！
-	return wework.NewStreamResponse(streamID, answer, finish), nil
+	return wework.NewStreamResponse(streamID, b.text(locale, i18n.Pending), false), nil
 }
 
 // HandleStreamRefresh 处理流式消息刷新 - 模拟Python示例的stream消息处理
 func (b *BotHandler) HandleStreamRefresh(streamID string) (*wework.WeWorkResponse, error) {
-	// 1. 获取最新答案（模拟Python LLMDemo.get_answer()）
-	answer := b.taskCache.GetAnswer(streamID)
+	metrics.StreamRefreshes.Inc()
+
+	// 任务由其他副本处理时从共享状态读取
+	if answer, finish, images, ok := b.taskCache.sharedAnswer(streamID); ok {
+		resp := wework.NewStreamResponse(streamID, answer, finish)
+		if finish {
+			resp.Stream.MsgItem = streamImageItems(images)
+		}
+		return resp, nil
+	}
+
+	// 1. 获取最新答案和是否完成（模拟Python LLMDemo.get_answer()和is_task_finish()）
+	answer, finish := b.taskCache.GetAnswer(streamID)
+
+	// 还没有内容时继续展示占位内容（空内容会清空企业微信已展示的提示）
+	if answer == "" && !finish {
+		answer = b.text(b.taskCache.taskLocale(streamID), i18n.Pending)
+	}
 
-	// 2. 检查是否完成（模拟Python LLMDemo.is_task_finish()）
-	finish := b.taskCache.IsTaskFinish(streamID)
+	// 超过单条流式消息上限时结束本条消息，剩余内容续发
+	answer, finish = b.taskCache.clampAnswer(streamID, answer, finish)
 
 	// 记录实际返回的文本内容
 
-	// 3. 返回stream消息（模拟Python MakeTextStream + EncryptMessage）
-	// 继续返回，直到finish=true为止
-	return wework.NewStreamResponse(streamID, answer, finish), nil
+	// 工具调用等待确认时，在回复下方附上审批卡片（只发送一次）
+	var resp *wework.WeWorkResponse
+	if card := b.approvalCard(streamID); card != nil {
+		resp = wework.NewStreamWithCardResponse(streamID, answer, finish, card)
+	} else if card := b.jobCard(streamID); card != nil {
+		// 转为长任务时附上任务卡片（只发送一次）
+		resp = wework.NewStreamWithCardResponse(streamID, answer, finish, card)
+	} else {
+		// 3. 返回stream消息（模拟Python MakeTextStream + EncryptMessage）
+		// 继续返回，直到finish=true为止
+		resp = wework.NewStreamResponse(streamID, answer, finish)
+	}
+
+	// 生成的图片只能随最后一次刷新发送
+	if finish {
+		resp.Stream.MsgItem = b.taskCache.replyImageItems(streamID)
+	}
+	return resp, nil
 }
 
 // GetActiveStreamCount 获取活跃任务数量
@@ -650,42 +1507,3 @@ func (b *BotHandler) GetActiveStreamCount() int {
 
 	return count
 }
-
-// mergeThinkTags 合并多个think标签为一个（企业微信只识别第一个）
-func mergeThinkTags(content string) string {
-	// 如果内容为空或不包含think标签，直接返回
-	if content == "" || !strings.Contains(content, "<think>") {
-		return content
-	}
-
-	// 使用正则表达式匹配所有的think标签及其内容
-	thinkRegex := regexp.MustCompile(`(?s)<think>(.*?)</think>`)
-	matches := thinkRegex.FindAllStringSubmatch(content, -1)
-
-	// 如果没有匹配或只有一个think标签，直接返回
-	if len(matches) <= 1 {
-		return content
-	}
-
-	// 收集所有think内容
-	var thinkContents []string
-	for _, match := range matches {
-		if len(match) > 1 && strings.TrimSpace(match[1]) != "" {
-			thinkContents = append(thinkContents, strings.TrimSpace(match[1]))
-		}
-	}
-
-	// 移除所有think标签
-	cleanContent := thinkRegex.ReplaceAllString(content, "")
-
-	// 如果没有收集到think内容，返回清理后的内容
-	if len(thinkContents) == 0 {
-		return cleanContent
-	}
-
-	// 合并所有think内容，用换行分隔
-	mergedThink := "<think>\n" + strings.Join(thinkContents, "\n\n") + "\n</think>\n"
-
-	// 将合并后的think标签放在内容开头
-	return mergedThink + strings.TrimSpace(cleanContent)
-}
@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/agent"
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+)
+
+// scriptedLLM 由测试控制输出的流式LLM：事件写入events后才会输出，关闭events结束回复
+type scriptedLLM struct {
+	events chan interfaces.StreamEvent
+}
+
+func (l *scriptedLLM) Generate(ctx context.Context, prompt string, options ...interfaces.GenerateOption) (string, error) {
+	return "", nil
+}
+
+func (l *scriptedLLM) GenerateWithTools(ctx context.Context, prompt string, tools []interfaces.Tool, options ...interfaces.GenerateOption) (string, error) {
+	return "", nil
+}
+
+func (l *scriptedLLM) Name() string { return "scripted" }
+
+func (l *scriptedLLM) SupportsStreaming() bool { return true }
+
+func (l *scriptedLLM) GenerateStream(ctx context.Context, prompt string, options ...interfaces.GenerateOption) (<-chan interfaces.StreamEvent, error) {
+	return l.events, nil
+}
+
+func (l *scriptedLLM) GenerateWithToolsStream(ctx context.Context, prompt string, tools []interfaces.Tool, options ...interfaces.GenerateOption) (<-chan interfaces.StreamEvent, error) {
+	return l.events, nil
+}
+
+// newScriptedHandler 创建使用默认配置（不连接MCP服务器）的机器人处理器，会话Agent使用llm输出
+func newScriptedHandler(t *testing.T, conversationID string, llm interfaces.LLM) *BotHandler {
+	t.Helper()
+
+	cfg := config.GetDefaultConfig()
+	cfg.MCP.Servers = nil
+	b, err := NewBotHandler(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+
+	agentInstance, err := agent.NewAgent(agent.WithLLM(llm), agent.WithMemory(memory.NewConversationBuffer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.convAgentManager.agents[conversationID] = &ConversationAgent{
+		agentInstance: agentInstance,
+		generation:    b.convAgentManager.generation,
+		lastActivity:  time.Now(),
+	}
+	return b
+}
+
+// waitFor 等待条件成立（后台任务处理是异步的）
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// refresh 模拟企业微信的流式消息刷新请求
+func refresh(t *testing.T, b *BotHandler, streamID string) (string, bool) {
+	t.Helper()
+
+	resp, err := b.HandleStreamRefresh(streamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stream == nil || resp.Stream.ID != streamID {
+		t.Fatalf("刷新返回的不是流式消息 %s: %+v", streamID, resp)
+	}
+	return resp.Stream.Content, resp.Stream.Finish
+}
+
+func TestHandleMessagePendingUntilContent(t *testing.T) {
+	llm := &scriptedLLM{events: make(chan interfaces.StreamEvent, 1)}
+	msg := &wework.IncomingMessage{
+		BaseMessage: wework.BaseMessage{
+			MsgID:    "msg-1",
+			ChatType: wework.ChatTypeSingle,
+			From:     wework.From{UserID: "zhangsan"},
+			MsgType:  wework.MsgTypeText,
+		},
+		Text: &wework.TextContent{Content: "你好"},
+	}
+	b := newScriptedHandler(t, msg.GetConversationKey(), llm)
+	pending := i18n.T(b.userLocale("zhangsan"), i18n.Pending)
+
+	// Agent还没有输出：首次回复立即返回占位内容，且未结束
+	resp, err := b.HandleMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stream == nil || resp.Stream.ID == "" {
+		t.Fatalf("首次回复不是流式消息: %+v", resp)
+	}
+	if resp.Stream.Content != pending || resp.Stream.Finish {
+		t.Fatalf("首次回复 = %q, finish=%v，期望占位内容 %q, finish=false", resp.Stream.Content, resp.Stream.Finish, pending)
+	}
+	streamID := resp.Stream.ID
+
+	b.taskCache.mutex.RLock()
+	task := b.taskCache.tasks[streamID]
+	b.taskCache.mutex.RUnlock()
+	if task == nil {
+		t.Fatalf("任务 %s 未登记", streamID)
+	}
+
+	// 仍然没有输出：刷新继续展示占位内容（空内容会清空已展示的提示）
+	waitFor(t, "开始处理任务", func() bool {
+		task.mutex.RLock()
+		defer task.mutex.RUnlock()
+		return task.IsProcessing
+	})
+	if content, finish := refresh(t, b, streamID); content != pending || finish {
+		t.Errorf("没有输出时刷新 = %q, finish=%v，期望占位内容", content, finish)
+	}
+
+	// 出现第一段内容：后续刷新取到内容，Agent未完成前不结束
+	llm.events <- interfaces.StreamEvent{Type: interfaces.StreamEventContentDelta, Content: "您好"}
+	waitFor(t, "第一段内容", func() bool { return task.Buffer.Snapshot() != "" })
+	if content, finish := refresh(t, b, streamID); content != "您好" || finish {
+		t.Errorf("第一段内容后刷新 = %q, finish=%v，期望 %q, false", content, finish, "您好")
+	}
+	if content, finish := refresh(t, b, streamID); content != "您好" || finish {
+		t.Errorf("内容已全部展示但Agent未完成时刷新 = %q, finish=%v，期望 %q, false", content, finish, "您好")
+	}
+
+	// Agent完成（SetAIFinished）后才报告结束
+	close(llm.events)
+	waitFor(t, "Agent完成", task.Buffer.IsAIFinished)
+	waitFor(t, "任务结束", func() bool {
+		task.mutex.RLock()
+		defer task.mutex.RUnlock()
+		return !task.IsProcessing
+	})
+	if content, finish := refresh(t, b, streamID); content != "您好" || !finish {
+		t.Errorf("Agent完成后刷新 = %q, finish=%v，期望 %q, true", content, finish, "您好")
+	}
+}
@@ -0,0 +1,297 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/handoff"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+// handoffUsage 客服群命令说明
+const handoffUsage = `🙋 转人工命令（在客服群中@机器人）：
+/handoffs —— 列出转人工中的会话
+/reply <工单号> <内容> —— 回复用户
+/release <工单号> —— 结束转人工，恢复AI回复`
+
+// transcriptMessageLimit 转人工通知中每条历史消息的长度上限（字符），群机器人消息最长4096字节
+const transcriptMessageLimit = 100
+
+const (
+	handoffWaitingText   = "⏳ 已转达人工客服，请稍候…"
+	handoffQueuedText    = "✅ 已转达人工客服，客服回复后会在您下次发消息时送达。"
+	handoffReleaseNotice = "人工客服已结束本次服务，接下来由AI助手继续为您解答。"
+)
+
+// handleHandoffMessage 处理转人工相关消息（客服群命令、用户要求转人工、转人工期间的用户消息），其他消息返回handled=false
+func (b *BotHandler) handleHandoffMessage(msg *wework.IncomingMessage) (resp *wework.WeWorkResponse, handled bool) {
+	if b.handoff == nil {
+		return nil, false
+	}
+	text := stripMention(msg.GetTextContent())
+
+	if msg.ChatID != "" && msg.ChatID == b.config.Handoff.SupportGroup {
+		if command, ok := parseHandoffCommand(text); ok {
+			return b.handleSupportCommand(msg, command), true
+		}
+	}
+
+	conversationID := msg.GetConversationKey()
+	if session, active := b.handoff.Active(conversationID); active {
+		return b.forwardToSupport(msg, session, text), true
+	}
+
+	if slices.Contains(b.config.Handoff.Keywords, text) {
+		session, err := b.transferToHuman(conversationID, msg.From.UserID, "用户要求转人工")
+		if err != nil {
+			slog.Error("转人工失败", "conversation_id", conversationID, "err", err)
+			return wework.NewTextResponse("抱歉，暂时无法转接人工客服，请稍后再试。"), true
+		}
+		return wework.NewTextResponse(fmt.Sprintf("已为您转接人工客服（工单 #%d），请稍候。接下来您发送的消息会直接转给客服。", session.ID)), true
+	}
+	return nil, false
+}
+
+// transferToHuman 开始转人工：通知客服群（附最近对话）并冻结AI，通知失败时撤销
+func (b *BotHandler) transferToHuman(conversationID, userID, reason string) (handoff.Session, error) {
+	session, created, err := b.handoff.Start(conversationID, userID, reason)
+	if err != nil || !created {
+		return session, err
+	}
+
+	if err := b.sendToSupport(b.handoffNotice(session)); err != nil {
+		// 客服群未收到通知时不冻结AI，避免用户无人应答
+		if _, _, releaseErr := b.handoff.Release(session.ID, ""); releaseErr != nil {
+			slog.Warn("撤销转人工失败", "err", releaseErr)
+		}
+		return handoff.Session{}, err
+	}
+
+	slog.Info("会话已转人工", "conversation_id", conversationID, "ticket", session.ID, "reason", reason)
+	b.events.Publish(events.HandoffStarted{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Ticket:         session.ID,
+		Reason:         reason,
+		Time:           session.Since,
+	})
+	return session, nil
+}
+
+// handoffNotice 构造转人工通知（会话、原因和最近对话）
+func (b *BotHandler) handoffNotice(session handoff.Session) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**🙋 转人工 #%d**\n> 会话：%s\n> 原因：%s\n", session.ID, session.ConversationID, session.Reason)
+
+	messages := b.convAgentManager.recentMessages(session.ConversationID, b.config.Handoff.TranscriptTurns)
+	if len(messages) > 0 {
+		sb.WriteString("\n**最近对话**\n")
+		for i, m := range messages {
+			// 记忆中可能有重复写入的消息
+			if i > 0 && m.Role == messages[i-1].Role && m.Content == messages[i-1].Content {
+				continue
+			}
+			speaker := "用户"
+			if m.Role == "assistant" {
+				speaker = "AI"
+			}
+			content := strings.Join(strings.Fields(stream.StripThinkTags(m.Content)), " ")
+			fmt.Fprintf(&sb, "> %s：%s\n", speaker, truncateRunes(content, transcriptMessageLimit))
+		}
+	}
+	fmt.Fprintf(&sb, "\n在本群@我回复：`/reply %d 内容`，结束：`/release %d`", session.ID, session.ID)
+	return sb.String()
+}
+
+// sendToSupport 发送消息到客服群
+func (b *BotHandler) sendToSupport(content string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return b.handoffSender.Send(ctx, "group_"+b.config.Handoff.SupportGroup, content)
+}
+
+// forwardToSupport 转人工期间将用户消息转给客服，并保持流式回复打开等待客服回复
+func (b *BotHandler) forwardToSupport(msg *wework.IncomingMessage, session handoff.Session, text string) *wework.WeWorkResponse {
+	if text == "" {
+		text = "[非文本消息，请提醒用户用文字描述]"
+	}
+	content := fmt.Sprintf("**🙋 #%d %s**：%s\n> 回复：`/reply %d 内容`", session.ID, msg.From.UserID, text, session.ID)
+	if err := b.sendToSupport(content); err != nil {
+		slog.Error("转发用户消息到客服群失败", "ticket", session.ID, "err", err)
+		return wework.NewTextResponse("抱歉，消息未能转达人工客服，请稍后再试。")
+	}
+
+	b.events.Publish(events.MessageReceived{
+		RequestID:      msg.RequestID,
+		ConversationID: session.ConversationID,
+		UserID:         msg.From.UserID,
+		ChatID:         msg.ChatID,
+		Content:        text,
+		Time:           time.Now(),
+	})
+
+	streamID, err := b.taskCache.invoke(context.Background(), text, session.ConversationID, b.awaitHuman)
+	if err != nil {
+		return wework.NewTextResponse(handoffQueuedText)
+	}
+	return wework.NewStreamResponse(streamID, handoffWaitingText, false)
+}
+
+// awaitHuman 等待客服回复并推送到用户的流式回复，超时后提示稍后送达
+func (b *BotHandler) awaitHuman(ctx context.Context, streamID string) {
+	tcm := b.taskCache
+	tcm.mutex.RLock()
+	task, exists := tcm.tasks[streamID]
+	tcm.mutex.RUnlock()
+	if !exists {
+		return
+	}
+	defer task.cancel()
+
+	task.mutex.Lock()
+	task.IsProcessing = true
+	task.LastUpdate = time.Now()
+	task.mutex.Unlock()
+	task.Buffer.SetEphemeral(handoffWaitingText)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(b.config.Handoff.WaitTimeout)*time.Second)
+	defer cancel()
+	if reply, ok := b.handoff.Wait(ctx, task.ConversationID); ok {
+		task.Buffer.Push(reply)
+	} else {
+		task.Buffer.Push(handoffQueuedText)
+	}
+
+	task.mutex.Lock()
+	task.IsProcessing = false
+	task.LastUpdate = time.Now()
+	task.mutex.Unlock()
+	task.Buffer.SetAIFinished()
+}
+
+// handleSupportCommand 处理客服群中的转人工命令
+func (b *BotHandler) handleSupportCommand(msg *wework.IncomingMessage, command string) *wework.WeWorkResponse {
+	agents := b.config.Handoff.Agents
+	if len(agents) > 0 && !slices.Contains(agents, msg.From.UserID) {
+		return wework.NewTextResponse("抱歉，您不在客服名单中，请联系管理员将您加入 handoff.agents。")
+	}
+
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "handoffs":
+		return wework.NewTextResponse(b.listHandoffs())
+	case "reply":
+		idText, text, _ := strings.Cut(arg, " ")
+		id, err := strconv.Atoi(strings.TrimPrefix(idText, "#"))
+		text = strings.TrimSpace(text)
+		if err != nil || text == "" {
+			return wework.NewTextResponse("用法：/reply <工单号> <内容>")
+		}
+		session, delivered, err := b.handoff.Reply(id, msg.From.UserID, "👩‍💼 人工客服："+text)
+		if err != nil {
+			return wework.NewTextResponse(fmt.Sprintf("❌ %v", err))
+		}
+		if delivered {
+			return wework.NewTextResponse(fmt.Sprintf("✅ 已送达 #%d（%s）", session.ID, session.ConversationID))
+		}
+		return wework.NewTextResponse(fmt.Sprintf("📨 用户当前没有等待中的消息，回复将在 #%d 用户下次发消息时送达", session.ID))
+	case "release":
+		id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if err != nil {
+			return wework.NewTextResponse("用法：/release <工单号>")
+		}
+		session, undelivered, err := b.handoff.Release(id, handoffReleaseNotice)
+		if err != nil {
+			return wework.NewTextResponse(fmt.Sprintf("❌ %v", err))
+		}
+		slog.Info("结束转人工", "user_id", msg.From.UserID, "ticket", session.ID, "conversation_id", session.ConversationID)
+		b.events.Publish(events.HandoffReleased{
+			ConversationID: session.ConversationID,
+			Ticket:         session.ID,
+			Agent:          msg.From.UserID,
+			Duration:       time.Since(session.Since),
+			Time:           time.Now(),
+		})
+		reply := fmt.Sprintf("✅ 工单 #%d 已结束，会话 %s 恢复AI回复", session.ID, session.ConversationID)
+		if undelivered > 0 {
+			reply += fmt.Sprintf("（%d条回复未送达）", undelivered)
+		}
+		return wework.NewTextResponse(reply)
+	default:
+		return wework.NewTextResponse(handoffUsage)
+	}
+}
+
+// listHandoffs 列出转人工中的会话
+func (b *BotHandler) listHandoffs() string {
+	sessions := b.handoff.List()
+	if len(sessions) == 0 {
+		return "🙋 当前没有转人工中的会话"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🙋 转人工中的会话（%d个）：", len(sessions))
+	for _, s := range sessions {
+		fmt.Fprintf(&sb, "\n#%d %s（%s，已等待%s）", s.ID, s.ConversationID, s.Reason,
+			time.Since(s.Since).Truncate(time.Minute))
+		if len(s.Pending) > 0 {
+			fmt.Fprintf(&sb, "，%d条回复待送达", len(s.Pending))
+		}
+	}
+	return sb.String()
+}
+
+// parseHandoffCommand 解析客服群命令（/handoffs、/reply、/release），返回去掉斜杠后的命令
+func parseHandoffCommand(text string) (string, bool) {
+	command, ok := strings.CutPrefix(text, "/")
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(command, " ")
+	switch name {
+	case "handoffs", "reply", "release":
+		return command, true
+	}
+	return "", false
+}
+
+// stripMention 去掉群聊消息开头的@机器人
+func stripMention(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "@") {
+		_, rest, _ := strings.Cut(text, " ")
+		text = strings.TrimSpace(rest)
+	}
+	return text
+}
+
+// recentMessages 读取会话记忆中最近的用户和AI消息（会话不在内存中时为空）
+func (cam *ConversationAgentManager) recentMessages(conversationID string, limit int) []interfaces.Message {
+	cam.mutex.RLock()
+	convAgent, exists := cam.agents[conversationID]
+	cam.mutex.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	ctx := multitenancy.WithOrgID(context.Background(), cam.config.Org())
+	ctx = context.WithValue(ctx, memory.ConversationIDKey, conversationID)
+	messages, err := convAgent.memory.GetMessages(ctx, interfaces.WithRoles("user", "assistant"), interfaces.WithLimit(limit))
+	if err != nil {
+		slog.Warn("读取会话记忆失败", "conversation_id", conversationID, "err", err)
+		return nil
+	}
+	return messages
+}
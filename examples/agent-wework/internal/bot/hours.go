@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/policy"
+)
+
+// parseBusinessHours 解析营业时间（未启用时返回nil，配置已在加载时验证）
+func parseBusinessHours(cfg *config.Config) *policy.BusinessHours {
+	hours, err := cfg.BusinessHours.Parse()
+	if err != nil {
+		slog.Warn("营业时间配置无效，已忽略", "err", err)
+		return nil
+	}
+	return hours
+}
+
+// restricted 指定时间是否应以非营业时间受限模式回答（调用方需持有cam.mutex）
+func (cam *ConversationAgentManager) restricted(now time.Time) bool {
+	return cam.hours != nil && cam.config.BusinessHours.Restricted() && !cam.hours.Open(now)
+}
+
+// restrict 受限模式：关闭工具调用和转人工，并告知模型当前为非营业时间
+func (cam *ConversationAgentManager) restrict(f config.Features) config.Features {
+	f.Tools = false
+	f.Handoff = false
+	f.Notice = cam.config.BusinessHours.Render(cam.config.BusinessHours.Notice, cam.hours)
+	return f
+}
+
+// offHoursReply 非营业时间的自动回复（营业时间内、未启用或受限模式时返回false）
+func (cam *ConversationAgentManager) offHoursReply(now time.Time) (string, bool) {
+	cam.mutex.RLock()
+	defer cam.mutex.RUnlock()
+
+	h := cam.config.BusinessHours
+	if cam.hours == nil || h.Restricted() || cam.hours.Open(now) {
+		return "", false
+	}
+	return h.Render(h.Reply, cam.hours), true
+}
+
+// handleOffHours 非营业时间直接自动回复，不经过Agent（受限模式下仍交给Agent处理）
+func (b *BotHandler) handleOffHours(msg *wework.IncomingMessage) (*wework.WeWorkResponse, bool) {
+	reply, ok := b.convAgentManager.offHoursReply(time.Now())
+	if !ok {
+		return nil, false
+	}
+	slog.Info("非营业时间自动回复", "conversation_id", msg.GetConversationKey())
+	return wework.NewTextResponse(reply), true
+}
@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/imagegen"
+)
+
+// newImageTool 创建图片生成工具（未启用时返回nil），生成的图片附在当前任务的流式回复末尾
+func newImageTool(cfg *config.Config, tcm *TaskCacheManager) (*imagegen.Tool, error) {
+	if !cfg.ImageGen.Enabled {
+		return nil, nil
+	}
+	generator, err := imagegen.NewGeneratorFromConfig(cfg.ImageGen)
+	if err != nil {
+		return nil, err
+	}
+	sink := &replyImages{tcm: tcm, max: cfg.ImageGen.MaxPerReply}
+	return imagegen.NewTool(generator, sink, time.Duration(cfg.ImageGen.Timeout)*time.Second), nil
+}
+
+// replyImages 将生成的图片暂存在任务上，回复结束时随最后一次刷新发送
+type replyImages struct {
+	tcm *TaskCacheManager
+	max int
+}
+
+// task 上下文对应的任务（定时任务等没有流式回复的场景返回nil）
+func (r *replyImages) task(ctx context.Context) *TaskInfo {
+	streamID, _ := ctx.Value(streamIDKey{}).(string)
+	if streamID == "" {
+		return nil
+	}
+	r.tcm.mutex.RLock()
+	defer r.tcm.mutex.RUnlock()
+	return r.tcm.tasks[streamID]
+}
+
+// Check 实现imagegen.Sink接口
+func (r *replyImages) Check(ctx context.Context) error {
+	task := r.task(ctx)
+	if task == nil {
+		return fmt.Errorf("当前回复无法附带图片")
+	}
+	task.mutex.RLock()
+	count := len(task.Images)
+	task.mutex.RUnlock()
+	if count >= r.max {
+		return fmt.Errorf("本次回复最多附带%d张图片", r.max)
+	}
+	return nil
+}
+
+// Attach 实现imagegen.Sink接口
+func (r *replyImages) Attach(ctx context.Context, image []byte) error {
+	task := r.task(ctx)
+	if task == nil {
+		return fmt.Errorf("当前回复无法附带图片")
+	}
+	task.mutex.Lock()
+	defer task.mutex.Unlock()
+	if len(task.Images) >= r.max {
+		return fmt.Errorf("本次回复最多附带%d张图片", r.max)
+	}
+	task.Images = append(task.Images, image)
+	return nil
+}
+
+// replyImageItems 回复已结束时返回要附带的图片项（企业微信只在finish为true的回复中展示图片）
+func (tcm *TaskCacheManager) replyImageItems(streamID string) []wework.WeWorkStreamMsgItem {
+	tcm.mutex.RLock()
+	task, exists := tcm.tasks[streamID]
+	tcm.mutex.RUnlock()
+	if !exists {
+		return nil
+	}
+	task.mutex.RLock()
+	defer task.mutex.RUnlock()
+	return streamImageItems(task.Images)
+}
+
+// streamImageItems 将图片转为流式消息的图片项
+func streamImageItems(images [][]byte) []wework.WeWorkStreamMsgItem {
+	if len(images) > wework.MaxStreamImages {
+		images = images[:wework.MaxStreamImages]
+	}
+	items := make([]wework.WeWorkStreamMsgItem, 0, len(images))
+	for _, image := range images {
+		items = append(items, wework.NewStreamImageItem(image))
+	}
+	return items
+}
@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+// 生成阶段（用于进度提示文案）
+const (
+	phaseThinking  = "thinking"
+	phaseTool      = "tool"
+	phaseSummarize = "summarize"
+)
+
+// defaultInterimMessages 各阶段的默认进度提示文案（{tool}替换为工具名）
+var defaultInterimMessages = map[string]i18n.Key{
+	phaseThinking:  i18n.InterimThinking,
+	phaseTool:      i18n.InterimTool,
+	phaseSummarize: i18n.InterimSummarize,
+}
+
+// defaultHeartbeatFrames 默认心跳内容
+var defaultHeartbeatFrames = []string{"·", "··", "···"}
+
+// interimTracker 跟踪生成阶段，无可见内容超过阈值时向缓冲区写入临时进度提示，
+// 并按心跳间隔轮换心跳内容，避免长时间停滞时回复内容一成不变
+type interimTracker struct {
+	after     time.Duration
+	messages  map[string]string
+	overrides map[string]string // 配置的系统消息文案（messages）
+	locale    string            // 默认文案和占位内容的语言
+	buffer    *stream.Buffer
+	timer     *time.Timer
+	heartbeat time.Duration
+	frames    []string
+	ticker    *time.Ticker
+	beats     int       // 本次停滞以来的心跳次数
+	quietFrom time.Time // 最近一次可见内容的时间
+	content   bool      // 是否已有可见内容
+	phase     string
+	tool      string
+	job       string // 长任务进度（转为跟踪任务后代替阶段提示）
+	shown     bool
+}
+
+// newInterimTracker 创建进度提示跟踪器（InterimAfter<0时禁用）
+func newInterimTracker(cfg config.StreamConfig, overrides map[string]string, locale string, buffer *stream.Buffer) *interimTracker {
+	it := &interimTracker{
+		messages:  cfg.InterimMessages,
+		overrides: overrides,
+		locale:    locale,
+		buffer:    buffer,
+		quietFrom: time.Now(),
+		phase:     phaseThinking,
+	}
+	if cfg.InterimAfter > 0 {
+		it.after = time.Duration(cfg.InterimAfter) * time.Second
+		it.timer = time.NewTimer(it.after)
+	}
+	if cfg.Heartbeat > 0 {
+		it.heartbeat = time.Duration(cfg.Heartbeat) * time.Second
+		it.ticker = time.NewTicker(it.heartbeat)
+		it.frames = cfg.HeartbeatFrames
+		if len(it.frames) == 0 {
+			it.frames = defaultHeartbeatFrames
+		}
+	}
+	return it
+}
+
+// C 返回到期通道（禁用时返回nil，select永远不会选中）
+func (it *interimTracker) C() <-chan time.Time {
+	if it.timer == nil {
+		return nil
+	}
+	return it.timer.C
+}
+
+// HeartbeatC 返回心跳通道（禁用时返回nil）
+func (it *interimTracker) HeartbeatC() <-chan time.Time {
+	if it.ticker == nil {
+		return nil
+	}
+	return it.ticker.C
+}
+
+// beat 无可见内容超过心跳间隔时轮换心跳内容
+func (it *interimTracker) beat() {
+	if time.Since(it.quietFrom) < it.heartbeat {
+		return
+	}
+	it.beats++
+	it.buffer.SetEphemeral(it.render())
+}
+
+// setPhase 切换生成阶段，已显示提示时立即更新文案
+func (it *interimTracker) setPhase(phase, tool string) {
+	it.phase = phase
+	it.tool = tool
+	if it.shown {
+		it.buffer.SetEphemeral(it.render())
+	}
+}
+
+// setJob 更新长任务进度并立即显示
+func (it *interimTracker) setJob(progress string) {
+	it.job = progress
+	it.show()
+}
+
+// show 显示当前阶段的进度提示
+func (it *interimTracker) show() {
+	it.shown = true
+	it.buffer.SetEphemeral(it.render())
+}
+
+// contentArrived 有可见内容时重新计时（Push已替换掉临时提示）
+func (it *interimTracker) contentArrived() {
+	it.shown = false
+	it.beats = 0
+	it.quietFrom = time.Now()
+	it.content = true
+	if it.timer == nil {
+		return
+	}
+	if !it.timer.Stop() {
+		select {
+		case <-it.timer.C:
+		default:
+		}
+	}
+	it.timer.Reset(it.after)
+}
+
+// stop 停止计时
+func (it *interimTracker) stop() {
+	if it.timer != nil {
+		it.timer.Stop()
+	}
+	if it.ticker != nil {
+		it.ticker.Stop()
+	}
+}
+
+// render 生成临时片段：已显示的进度提示（还没有内容时为占位内容），加上当前的心跳内容
+func (it *interimTracker) render() string {
+	var text string
+	switch {
+	case it.shown:
+		text = it.message()
+	case !it.content && it.beats > 0:
+		text = i18n.Text(it.overrides, it.locale, i18n.Pending)
+	}
+	if it.beats == 0 {
+		return text
+	}
+	frame := it.frames[(it.beats-1)%len(it.frames)]
+	if text == "" {
+		return frame
+	}
+	return text + " " + frame
+}
+
+// message 生成当前阶段的提示文案（长任务显示进度，否则优先 tool:<工具名>，其次阶段名，最后默认文案）
+func (it *interimTracker) message() string {
+	if it.job != "" {
+		return it.job
+	}
+	text, ok := "", false
+	if it.phase == phaseTool && it.tool != "" {
+		text, ok = it.messages[phaseTool+":"+it.tool]
+	}
+	if !ok {
+		text, ok = it.messages[it.phase]
+	}
+	if !ok {
+		text = i18n.Text(it.overrides, it.locale, defaultInterimMessages[it.phase])
+	}
+	return strings.ReplaceAll(text, "{tool}", it.tool)
+}
@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/jobs"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+const (
+	// jobListLimit /status 列出的最近任务数
+	jobListLimit = 5
+	// jobAnswerLimit /status 展示的回复长度
+	jobAnswerLimit = 800
+	// jobQuestionLimit 卡片和列表中展示的问题长度
+	jobQuestionLimit = 40
+)
+
+// jobStateNames 任务状态说明
+var jobStateNames = map[string]string{
+	jobs.StateRunning:     "⏳ 进行中",
+	jobs.StateSucceeded:   "✅ 已完成",
+	jobs.StateFailed:      "❌ 失败",
+	jobs.StateInterrupted: "⚠️ 已中断（服务重启）",
+}
+
+// newJobTracker 创建长任务跟踪（未启用时返回nil）
+func newJobTracker(cfg config.JobsConfig) (*jobs.Tracker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return jobs.NewTracker(cfg.Path, time.Duration(cfg.Retention)*time.Hour)
+}
+
+// jobTimer 距离转为跟踪任务的到期通道（未启用或已在跟踪时返回nil，select永远不会选中）
+func (tcm *TaskCacheManager) jobTimer(state *streamState) (<-chan time.Time, func()) {
+	if tcm.jobs == nil || state.jobID != 0 {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(max(tcm.jobsAfter-time.Since(state.started), 0))
+	return timer.C, func() { timer.Stop() }
+}
+
+// trackJob 工具调用链运行超过阈值时转为跟踪任务，回复中显示任务编号和进度
+func (tcm *TaskCacheManager) trackJob(task *TaskInfo, state *streamState, interim *interimTracker) {
+	if tcm.jobs == nil || state.jobID != 0 || !state.hasToolCall || time.Since(state.started) < tcm.jobsAfter {
+		return
+	}
+	job, err := tcm.jobs.Create(jobs.Job{
+		StreamID:       task.StreamID,
+		ConversationID: task.ConversationID,
+		Question:       stripUserPrefix(task.Question),
+		Step:           state.step,
+		Steps:          state.steps,
+		Percent:        -1,
+		Started:        state.started,
+	})
+	if err != nil {
+		slog.Warn("创建长任务失败", "stream_id", task.StreamID, "err", err)
+		return
+	}
+	state.jobID = job.ID
+
+	task.mutex.Lock()
+	task.JobID = job.ID
+	task.mutex.Unlock()
+
+	slog.Info("转为长任务", "job_id", job.ID, "stream_id", task.StreamID)
+	interim.setJob(jobProgress(job))
+}
+
+// updateJob 按工具调用事件更新步骤和长任务进度（工具可在元数据或JSON结果中上报 progress 和 step）
+func (tcm *TaskCacheManager) updateJob(state *streamState, interim *interimTracker, event interfaces.AgentStreamEvent) {
+	newStep := false
+	if event.Type == interfaces.AgentEventToolCall && event.ToolCall != nil {
+		newStep = event.ToolCall.ID == "" || event.ToolCall.ID != state.stepID
+		state.step = event.ToolCall.Name
+		state.stepID = event.ToolCall.ID
+		if newStep {
+			state.steps++
+		}
+	}
+	if state.jobID == 0 {
+		return
+	}
+
+	var job jobs.Job
+	var ok bool
+	if event.Type == interfaces.AgentEventToolCall {
+		job, ok = tcm.jobs.Progress(state.jobID, state.step, -1, newStep)
+	} else {
+		step, percent := toolProgress(event)
+		job, ok = tcm.jobs.Progress(state.jobID, step, percent, false)
+	}
+	if ok {
+		interim.setJob(jobProgress(job))
+	}
+}
+
+// finishJob 回复结束时保存长任务结果
+func (tcm *TaskCacheManager) finishJob(task *TaskInfo, state *streamState, err error) {
+	if state.jobID == 0 {
+		return
+	}
+	answer := task.Buffer.Snapshot()
+	if task.HideThinking {
+		answer = stream.StripThinkTags(answer)
+	}
+	if err := tcm.jobs.Finish(state.jobID, answer, err); err != nil {
+		slog.Warn("保存长任务结果失败", "job_id", state.jobID, "err", err)
+	}
+}
+
+// toolProgress 读取工具上报的进度：优先事件元数据，其次JSON结果中的 progress（0-100）和 step
+func toolProgress(event interfaces.AgentStreamEvent) (string, int) {
+	values := event.Metadata
+	if _, ok := values["progress"]; !ok && event.ToolCall != nil {
+		var result map[string]interface{}
+		if json.Unmarshal([]byte(event.ToolCall.Result), &result) == nil {
+			values = result
+		}
+	}
+
+	step, _ := values["step"].(string)
+	percent := -1
+	switch v := values["progress"].(type) {
+	case float64:
+		percent = int(v)
+	case int:
+		percent = v
+	}
+	return step, percent
+}
+
+// jobProgress 回复中显示的长任务进度
+func jobProgress(job jobs.Job) string {
+	parts := []string{fmt.Sprintf("⏳ 长任务 #%d 进行中", job.ID)}
+	if job.Step != "" {
+		parts = append(parts, fmt.Sprintf("第%d步 %s", max(job.Steps, 1), job.Step))
+	}
+	if job.Percent >= 0 {
+		parts = append(parts, fmt.Sprintf("%d%%", job.Percent))
+	}
+	parts = append(parts, "已用时 "+job.Elapsed().String())
+	return strings.Join(parts, " · ") + fmt.Sprintf("\n回复中断后可发送 /status %d 查看结果", job.ID)
+}
+
+// jobCard 任务转为长任务后，在回复下方附上任务卡片（只发送一次）
+func (b *BotHandler) jobCard(streamID string) *wework.WeWorkTemplateCard {
+	if b.taskCache.jobs == nil {
+		return nil
+	}
+	b.taskCache.mutex.RLock()
+	task, exists := b.taskCache.tasks[streamID]
+	b.taskCache.mutex.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	task.mutex.Lock()
+	id := task.JobID
+	if id == 0 || task.jobCarded {
+		task.mutex.Unlock()
+		return nil
+	}
+	task.jobCarded = true
+	task.mutex.Unlock()
+
+	job, ok := b.taskCache.jobs.Get(id)
+	if !ok {
+		return nil
+	}
+	return &wework.WeWorkTemplateCard{
+		CardType:     wework.CardTypeTextNotice,
+		MainTitle:    &wework.CardMainTitle{Title: "📋 已转为长任务", Desc: fmt.Sprintf("编号 #%d", job.ID)},
+		SubTitleText: fmt.Sprintf("处理时间较长，进度会显示在回复末尾。回复中断后可发送 /status %d 查看进度和结果。", job.ID),
+		HorizontalContentList: []wework.CardHorizontalItem{
+			{KeyName: "问题", Value: truncateRunes(job.Question, jobQuestionLimit)},
+		},
+		CardAction: &wework.CardAction{Type: 0},
+		TaskID:     fmt.Sprintf("job_%d", job.ID),
+	}
+}
+
+// handleJobsMessage 处理 /status 命令（查看本会话的长任务），未启用或其他消息返回handled=false
+func (b *BotHandler) handleJobsMessage(msg *wework.IncomingMessage) (*wework.WeWorkResponse, bool) {
+	tracker := b.taskCache.jobs
+	if tracker == nil {
+		return nil, false
+	}
+	command, ok := strings.CutPrefix(stripMention(msg.GetTextContent()), "/status")
+	if !ok || (command != "" && command[0] != ' ') {
+		return nil, false
+	}
+	conversationID := msg.GetConversationKey()
+
+	arg := strings.TrimPrefix(strings.TrimSpace(command), "#")
+	if arg == "" {
+		list := tracker.List(conversationID, jobListLimit)
+		if len(list) == 0 {
+			return wework.NewTextResponse("当前会话没有长任务"), true
+		}
+		lines := []string{"最近的长任务："}
+		for _, job := range list {
+			lines = append(lines, fmt.Sprintf("#%d %s %s", job.ID, jobStateNames[job.State], truncateRunes(job.Question, jobQuestionLimit)))
+		}
+		lines = append(lines, "", "发送 /status <编号> 查看详情")
+		return wework.NewTextResponse(strings.Join(lines, "\n")), true
+	}
+
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return wework.NewTextResponse("用法：/status [编号]"), true
+	}
+	job, ok := tracker.Get(id)
+	if !ok || job.ConversationID != conversationID {
+		return wework.NewTextResponse(fmt.Sprintf("长任务 #%d 不存在或已过期", id)), true
+	}
+	return wework.NewTextResponse(describeJob(job)), true
+}
+
+// describeJob 长任务详情
+func describeJob(job jobs.Job) string {
+	lines := []string{
+		fmt.Sprintf("长任务 #%d %s", job.ID, jobStateNames[job.State]),
+		"问题：" + truncateRunes(job.Question, jobQuestionLimit),
+	}
+	if job.State == jobs.StateRunning {
+		progress := fmt.Sprintf("进度：第%d步", max(job.Steps, 1))
+		if job.Step != "" {
+			progress += " " + job.Step
+		}
+		if job.Percent >= 0 {
+			progress += fmt.Sprintf("（%d%%）", job.Percent)
+		}
+		lines = append(lines, progress)
+	}
+	lines = append(lines, fmt.Sprintf("开始：%s，用时 %s", job.Started.Format("01-02 15:04:05"), job.Elapsed()))
+	if job.Error != "" {
+		lines = append(lines, "原因："+job.Error)
+	}
+	if job.Answer != "" {
+		lines = append(lines, "", truncateRunes(strings.TrimSpace(stream.StripThinkTags(job.Answer)), jobAnswerLimit))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/cluster"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+const (
+	// journalInterval 处理中的任务写入预写记录的间隔（内容没有变化时不写）
+	journalInterval = time.Second
+	// journalMaxAge 重启时恢复的记录的最长保留时间（更早的消息企业微信已不再刷新，直接删除）
+	journalMaxAge = 10 * time.Minute
+)
+
+// journalRecord 任务的预写记录
+type journalRecord struct {
+	StreamID       string    `json:"stream_id"`
+	ConversationID string    `json:"conversation_id"`
+	RequestID      string    `json:"request_id,omitempty"`
+	Locale         string    `json:"locale,omitempty"`
+	Answer         string    `json:"answer"` // 已生成的回复内容（隐藏思考过程时已去除）
+	Updated        time.Time `json:"updated"`
+}
+
+// streamJournal 流式回复的预写记录：处理中的任务定期将已生成内容写入各自的文件，回复结束后删除
+//
+// 进程崩溃或被强制终止后，重启时读回未删除的记录；企业微信继续刷新这些消息时，
+// 以已生成的部分内容加中断说明结束消息，而不是一直显示占位内容或提示任务不存在。
+type streamJournal struct {
+	dir       string
+	recovered map[string]journalRecord // 重启前未完成的任务（streamID -> 记录）
+	mutex     sync.Mutex
+}
+
+// openStreamJournal 打开预写记录目录并读回上次未完成的记录（未配置目录时返回nil）
+func openStreamJournal(dir string) (*streamJournal, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建预写记录目录失败: %w", err)
+	}
+	j := &streamJournal{dir: dir, recovered: make(map[string]journalRecord)}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		var rec journalRecord
+		if _, err := fsutil.ReadJSON(path, &rec); err != nil || rec.StreamID == "" {
+			slog.Warn("预写记录无法读取，已删除", "path", path, "err", err)
+			os.Remove(path)
+			continue
+		}
+		if time.Since(rec.Updated) > journalMaxAge {
+			os.Remove(path)
+			continue
+		}
+		j.recovered[rec.StreamID] = rec
+	}
+	if len(j.recovered) > 0 {
+		slog.Info("已读回中断的流式回复", "count", len(j.recovered), "dir", dir)
+	}
+	return j, nil
+}
+
+// path 任务的记录文件（streamID由本服务生成，只含字母和数字；其他值返回空，避免路径穿越）
+func (j *streamJournal) path(streamID string) string {
+	if streamID == "" || strings.ContainsFunc(streamID, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		return ""
+	}
+	return filepath.Join(j.dir, streamID+".json")
+}
+
+// write 写入任务的最新内容
+func (j *streamJournal) write(rec journalRecord) {
+	path := j.path(rec.StreamID)
+	if path == "" {
+		return
+	}
+	if err := fsutil.WriteJSONAtomic(path, rec); err != nil {
+		slog.Warn("写入预写记录失败", "stream_id", rec.StreamID, "err", err)
+	}
+}
+
+// remove 删除任务的记录（回复已结束）
+func (j *streamJournal) remove(streamID string) {
+	if path := j.path(streamID); path != "" {
+		os.Remove(path)
+	}
+}
+
+// take 取出重启前未完成的任务记录（只返回一次，同时删除文件）
+func (j *streamJournal) take(streamID string) (journalRecord, bool) {
+	j.mutex.Lock()
+	rec, ok := j.recovered[streamID]
+	delete(j.recovered, streamID)
+	j.mutex.Unlock()
+
+	if ok {
+		j.remove(streamID)
+	}
+	return rec, ok
+}
+
+// takeAll 取出全部重启前未完成的任务记录
+func (j *streamJournal) takeAll() []journalRecord {
+	j.mutex.Lock()
+	records := make([]journalRecord, 0, len(j.recovered))
+	for streamID, rec := range j.recovered {
+		records = append(records, rec)
+		delete(j.recovered, streamID)
+	}
+	j.mutex.Unlock()
+
+	for _, rec := range records {
+		j.remove(rec.StreamID)
+	}
+	return records
+}
+
+// journalTask 定期将任务已生成的内容写入预写记录，回复结束后删除记录
+func (tcm *TaskCacheManager) journalTask(task *TaskInfo) {
+	ticker := time.NewTicker(journalInterval)
+	defer ticker.Stop()
+
+	written := -1
+	for {
+		task.mutex.RLock()
+		finished := !task.IsProcessing && task.Buffer.IsAIFinished()
+		task.mutex.RUnlock()
+		if finished {
+			tcm.journal.remove(task.StreamID)
+			return
+		}
+
+		answer := task.Buffer.Snapshot()
+		if size := len(answer); size != written {
+			written = size
+			if task.HideThinking {
+				answer = stream.StripThinkTags(answer)
+			}
+			tcm.journal.write(journalRecord{
+				StreamID:       task.StreamID,
+				ConversationID: task.ConversationID,
+				RequestID:      task.RequestID,
+				Locale:         task.Locale,
+				Answer:         stream.MergeThinkTags(answer),
+				Updated:        time.Now(),
+			})
+		}
+		<-ticker.C
+	}
+}
+
+// interruptedAnswer 重启前中断的回复：已生成的部分内容加中断说明（超过单条流式消息上限时截断部分内容）
+func (tcm *TaskCacheManager) interruptedAnswer(rec journalRecord) string {
+	notice := tcm.convAgentManager.text(rec.Locale, i18n.Interrupted)
+	if strings.TrimSpace(rec.Answer) == "" {
+		return notice
+	}
+	notice = "\n\n" + notice
+	return safeCut(rec.Answer, tcm.streamConfig.MaxBytes-len(notice)) + notice
+}
+
+// recoveredAnswer 本副本没有该任务时查找重启前中断的回复（只返回一次）
+func (tcm *TaskCacheManager) recoveredAnswer(streamID string) (string, bool) {
+	if tcm.journal == nil {
+		return "", false
+	}
+	rec, ok := tcm.journal.take(streamID)
+	if !ok {
+		return "", false
+	}
+	slog.Info("以部分内容结束重启前中断的回复", "stream_id", streamID, "conversation_id", rec.ConversationID, "request_id", rec.RequestID)
+	return tcm.interruptedAnswer(rec), true
+}
+
+// publishRecovered 启用共享状态时，将重启前中断的回复作为已结束的状态发布，由任意副本响应刷新
+func (tcm *TaskCacheManager) publishRecovered() {
+	if tcm.journal == nil || tcm.shared == nil {
+		return
+	}
+	for _, rec := range tcm.journal.takeAll() {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+		err := tcm.shared.Put(ctx, tcm.sharedKey(rec.StreamID), cluster.StreamState{
+			Answer:   tcm.interruptedAnswer(rec),
+			Finished: true,
+			Owner:    tcm.clusterConfig.InstanceID,
+			Updated:  time.Now(),
+		})
+		cancel()
+		if err != nil {
+			slog.Warn("发布中断的回复失败", "stream_id", rec.StreamID, "err", err)
+			continue
+		}
+		slog.Info("已发布重启前中断的回复", "stream_id", rec.StreamID, "conversation_id", rec.ConversationID)
+	}
+}
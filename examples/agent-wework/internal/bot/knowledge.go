@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// kbListLimit /kb list 最多展示的文档数（最近添加的）
+const kbListLimit = 30
+
+// kbUsage 知识库管理命令说明
+const kbUsage = `📚 知识库管理命令：
+/kb list —— 列出文档
+/kb add <标题> 换行后接正文 —— 添加文档
+/kb delete <文档ID> —— 删除文档
+/kb reindex —— 重建检索索引
+直接发送文本文件（txt、md、csv等）也会添加为文档，标题取首行`
+
+// handleKnowledgeMessage 处理聊天中的知识库管理（/kb 命令和文件上传），其他消息返回handled=false
+func (b *BotHandler) handleKnowledgeMessage(msg *wework.IncomingMessage) (resp *wework.WeWorkResponse, handled bool) {
+	isFile := msg.MsgType == wework.MsgTypeFile
+	command, isCommand := parseKBCommand(msg.GetTextContent())
+	if !isFile && !isCommand {
+		return nil, false
+	}
+
+	kb := b.convAgentManager.knowledge
+	switch {
+	case kb == nil:
+		return wework.NewTextResponse("知识库未启用，无法管理文档。"), true
+	case !b.isKnowledgeAdmin(msg.From.UserID):
+		if isFile {
+			return wework.NewTextResponse("我收到了您发送的文件，但目前只支持知识库管理员上传文件。您可以把问题用文字描述给我。"), true
+		}
+		return wework.NewTextResponse("抱歉，您没有管理知识库的权限，请联系管理员将您加入 knowledge.admins。"), true
+	}
+
+	if isFile {
+		return b.addKnowledgeFile(msg), true
+	}
+
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "list":
+		return wework.NewTextResponse(b.listKnowledge()), true
+	case "add":
+		title, content, _ := strings.Cut(arg, "\n")
+		title, content = strings.TrimSpace(title), strings.TrimSpace(content)
+		if title == "" || content == "" {
+			return wework.NewTextResponse("用法：/kb add <标题>，换行后接正文"), true
+		}
+		return b.saveKnowledgeDoc(msg.From.UserID, title, content), true
+	case "delete", "del", "rm":
+		if arg == "" {
+			return wework.NewTextResponse("用法：/kb delete <文档ID>（文档ID可通过 /kb list 查看）"), true
+		}
+		doc, ok := kb.Get(arg)
+		if !ok || !kb.Delete(arg) {
+			return wework.NewTextResponse(fmt.Sprintf("未找到文档 %s", arg)), true
+		}
+		if err := kb.Save(); err != nil {
+			return wework.NewTextResponse(fmt.Sprintf("❌ 文档已从索引移除，但保存知识库失败: %v", err)), true
+		}
+		slog.Info("删除知识库文档", "user_id", msg.From.UserID, "doc_id", doc.ID, "title", doc.Title)
+		return wework.NewTextResponse(fmt.Sprintf("🗑️ 已删除文档 %s：%s", doc.ID, doc.Title)), true
+	case "reindex":
+		chunks := kb.Reindex()
+		slog.Info("重建知识库索引", "user_id", msg.From.UserID, "chunks", chunks)
+		return wework.NewTextResponse(fmt.Sprintf("✅ 已重建检索索引：%d 篇文档，%d 个分块", len(kb.List()), chunks)), true
+	default:
+		return wework.NewTextResponse(kbUsage), true
+	}
+}
+
+// parseKBCommand 解析 /kb 命令（群聊中允许以@机器人开头），返回去掉前缀后的参数
+func parseKBCommand(text string) (string, bool) {
+	rest, ok := strings.CutPrefix(stripMention(text), "/kb")
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\n') {
+		return "", false
+	}
+	return strings.TrimLeft(rest, " \n"), true
+}
+
+// listKnowledge 列出最近添加的文档
+func (b *BotHandler) listKnowledge() string {
+	docs := b.convAgentManager.knowledge.List()
+	if len(docs) == 0 {
+		return "📚 知识库暂无文档。发送 /kb add 或直接发送文本文件添加。"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📚 知识库共 %d 篇文档", len(docs))
+	if len(docs) > kbListLimit {
+		fmt.Fprintf(&sb, "（仅显示最近 %d 篇）", kbListLimit)
+		docs = docs[len(docs)-kbListLimit:]
+	}
+	sb.WriteString("：")
+	for i := len(docs) - 1; i >= 0; i-- {
+		doc := docs[i]
+		fmt.Fprintf(&sb, "\n- %s %s（%s，%s）", doc.ID, doc.Title, doc.Source, doc.CreatedAt.Format("2006-01-02"))
+	}
+	return sb.String()
+}
+
+// addKnowledgeFile 下载上传的文本文件并添加为文档（标题取首行）
+func (b *BotHandler) addKnowledgeFile(msg *wework.IncomingMessage) *wework.WeWorkResponse {
+	if msg.File == nil || msg.File.URL == "" {
+		return wework.NewTextResponse("❌ 未获取到文件地址，请重新发送。")
+	}
+
+	wxcpt, err := wework.NewWXBizJsonMsgCrypt(b.config.WeWork.Token, b.config.WeWork.AESKey, "")
+	if err != nil {
+		return wework.NewTextResponse(fmt.Sprintf("❌ 初始化解密失败: %v", err))
+	}
+	maxBytes := int64(b.config.Knowledge.MaxFileSize) * 1024
+	data, err := wxcpt.DownloadMedia(msg.File.URL, maxBytes)
+	if err != nil {
+		return wework.NewTextResponse(fmt.Sprintf("❌ 获取文件失败: %v", err))
+	}
+	if !utf8.Valid(data) {
+		return wework.NewTextResponse("❌ 仅支持UTF-8编码的文本文件（txt、md、csv等），请转换后重新发送。")
+	}
+
+	content := strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff"))
+	if content == "" {
+		return wework.NewTextResponse("❌ 文件内容为空。")
+	}
+	title, _, _ := strings.Cut(content, "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	if runes := []rune(title); len(runes) > 50 {
+		title = string(runes[:50]) + "..."
+	}
+	return b.saveKnowledgeDoc(msg.From.UserID, title, content)
+}
+
+// saveKnowledgeDoc 添加文档并持久化
+func (b *BotHandler) saveKnowledgeDoc(userID, title, content string) *wework.WeWorkResponse {
+	kb := b.convAgentManager.knowledge
+	doc := kb.Add(title, content, "upload")
+	if err := kb.Save(); err != nil {
+		kb.Delete(doc.ID)
+		return wework.NewTextResponse(fmt.Sprintf("❌ 保存知识库失败: %v", err))
+	}
+	slog.Info("添加知识库文档", "user_id", userID, "doc_id", doc.ID, "title", doc.Title, "chars", utf8.RuneCountInString(content))
+	return wework.NewTextResponse(fmt.Sprintf("✅ 已添加文档 %s：%s（%d 字）", doc.ID, doc.Title, utf8.RuneCountInString(content)))
+}
@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+)
+
+// userLocale 用户的系统消息语言：/settings 中设置的回复语言为支持的语言时优先，否则使用配置的locale
+func (b *BotHandler) userLocale(userID string) string {
+	if prefs := b.convAgentManager.prefs; prefs != nil {
+		if locale, ok := i18n.Parse(prefs.Get(userID).Language); ok {
+			return locale
+		}
+	}
+	return b.config.Locale
+}
+
+// text 系统消息文案（messages 中配置的文案优先），vars 为成对的变量名和值
+func (cam *ConversationAgentManager) text(locale string, key i18n.Key, vars ...string) string {
+	return i18n.Text(cam.config.Messages, locale, key, vars...)
+}
+
+// text 系统消息文案（messages 中配置的文案优先）
+func (b *BotHandler) text(locale string, key i18n.Key, vars ...string) string {
+	return b.convAgentManager.text(locale, key, vars...)
+}
+
+// contextLocale 上下文中的系统消息语言（未设置时使用配置的locale，如旧版本入队的任务）
+func (tcm *TaskCacheManager) contextLocale(ctx context.Context) string {
+	if locale := i18n.FromContext(ctx); locale != "" {
+		return locale
+	}
+	return tcm.convAgentManager.config.Locale
+}
+
+// taskLocale 任务的系统消息语言（任务不存在时使用配置的locale）
+func (tcm *TaskCacheManager) taskLocale(streamID string) string {
+	tcm.mutex.RLock()
+	task, exists := tcm.tasks[streamID]
+	tcm.mutex.RUnlock()
+	if !exists {
+		return tcm.convAgentManager.config.Locale
+	}
+	return task.Locale
+}
@@ -1,29 +1,76 @@
 package bot
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"log/slog"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// chatLogAISender 聊天记录中AI回复的发送者
+const chatLogAISender = "AI"
+
+// 聊天记录存储驱动
+const (
+	ChatLogDriverFile   = "file"   // 按会话写入纯文本文件（默认）
+	ChatLogDriverSQLite = "sqlite" // 写入 <log_dir>/chat.db 的messages表
 )
 
+// chatLogDriver 聊天记录存储驱动，由ChatLogger的后台协程批量调用
+type chatLogDriver interface {
+	// write 写入同一会话的一批记录
+	write(conversationID string, entries []LogEntry) error
+	// read 按时间顺序读取会话的记录，返回当前页和符合条件的总数
+	read(conversationID string, query MessageQuery) ([]LogEntry, int, error)
+	// maintain 定期维护（刷新缓冲区、关闭空闲文件等）
+	maintain()
+	// close 关闭存储（所有记录已写入后调用）
+	close() error
+}
+
 // LogEntry 日志条目
 type LogEntry struct {
 	ConversationID string
+	RequestID      string // 回调请求ID（为空时不记录）
 	UserID         string
 	Content        string
 	Timestamp      time.Time
 }
 
+// MessageQuery 聊天记录查询条件
+type MessageQuery struct {
+	Since  time.Time // 起始时间（含，零值不限）
+	Until  time.Time // 结束时间（不含，零值不限）
+	Limit  int       // 每页条数
+	Offset int       // 跳过的条数
+}
+
+// match 记录时间是否在查询范围内
+func (q MessageQuery) match(t time.Time) bool {
+	return (q.Since.IsZero() || !t.Before(q.Since)) && (q.Until.IsZero() || t.Before(q.Until))
+}
+
+// page 对按时间排序的全部匹配记录分页
+func (q MessageQuery) page(entries []LogEntry) []LogEntry {
+	if q.Offset >= len(entries) {
+		return []LogEntry{}
+	}
+	entries = entries[q.Offset:]
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries
+}
+
 // ChatLogger 异步聊天记录日志管理器
 type ChatLogger struct {
-	logDir     string
-	logQueue   chan LogEntry       // 异步日志队列
-	fileMap    map[string]*logFile // conversationID -> logFile
-	fileMutex  sync.RWMutex
+	driver     chatLogDriver  // 存储驱动
+	logQueue   chan LogEntry  // 异步日志队列
 	workerWG   sync.WaitGroup // 工作协程等待组
 	shutdownCh chan struct{}  // 关闭信号
 
@@ -37,24 +84,25 @@ type ChatLogger struct {
 	flushInterval time.Duration // 刷新间隔
 }
 
-// logFile 包装日志文件和缓冲写入器
-type logFile struct {
-	file       *os.File
-	writer     *bufio.Writer
-	lastAccess time.Time
-}
-
-// NewChatLogger 创建异步聊天日志记录器
-func NewChatLogger(logDir string) (*ChatLogger, error) {
-	// 确保日志目录存在
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+// NewChatLogger 创建异步聊天日志记录器（按logging.driver选择存储）
+func NewChatLogger(cfg config.LoggingConfig) (*ChatLogger, error) {
+	var driver chatLogDriver
+	var err error
+	switch cfg.Driver {
+	case "", ChatLogDriverFile:
+		driver, err = newFileLogDriver(cfg.LogDir, 10*time.Minute)
+	case ChatLogDriverSQLite:
+		driver, err = newSQLiteLogDriver(filepath.Join(cfg.LogDir, "chat.db"))
+	default:
+		err = fmt.Errorf("不支持的聊天记录存储: %s", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	logger := &ChatLogger{
-		logDir:        logDir,
+		driver:        driver,
 		logQueue:      make(chan LogEntry, 10000), // 10k 缓冲队列
-		fileMap:       make(map[string]*logFile),
 		shutdownCh:    make(chan struct{}),
 		queueSize:     10000,
 		batchSize:     100,
@@ -72,10 +120,11 @@ func NewChatLogger(logDir string) (*ChatLogger, error) {
 	return logger, nil
 }
 
-// LogMessage 异步记录用户消息（非阻塞）
-func (cl *ChatLogger) LogMessage(conversationID, userID, content string) error {
+// LogMessage 异步记录用户消息（非阻塞），requestID用于与运行日志关联
+func (cl *ChatLogger) LogMessage(conversationID, requestID, userID, content string) error {
 	entry := LogEntry{
 		ConversationID: conversationID,
+		RequestID:      requestID,
 		UserID:         userID,
 		Content:        content,
 		Timestamp:      time.Now(),
@@ -155,79 +204,10 @@ func (cl *ChatLogger) shouldFlush(batch map[string][]LogEntry) bool {
 // writeBatches 批量写入日志
 func (cl *ChatLogger) writeBatches(batches map[string][]LogEntry) {
 	for conversationID, entries := range batches {
-		cl.writeEntries(conversationID, entries)
-	}
-}
-
-// writeEntries 写入一批日志条目到指定会话文件
-func (cl *ChatLogger) writeEntries(conversationID string, entries []LogEntry) {
-	lf, err := cl.getOrCreateLogFile(conversationID)
-	if err != nil {
-		fmt.Printf("获取日志文件失败 [%s]: %v\n", conversationID, err)
-		return
-	}
-
-	// 批量写入
-	for _, entry := range entries {
-		logLine := fmt.Sprintf("[%s]%s:%s\n",
-			entry.Timestamp.Format("2006-01-02 15:04:05"),
-			entry.UserID,
-			entry.Content)
-
-		if _, err := lf.writer.WriteString(logLine); err != nil {
-			fmt.Printf("写入日志失败 [%s]: %v\n", conversationID, err)
-			break
+		if err := cl.driver.write(conversationID, entries); err != nil {
+			slog.Warn("写入聊天日志失败", "conversation_id", conversationID, "err", err)
 		}
 	}
-
-	// 更新最后访问时间
-	lf.lastAccess = time.Now()
-}
-
-// getOrCreateLogFile 获取或创建日志文件
-func (cl *ChatLogger) getOrCreateLogFile(conversationID string) (*logFile, error) {
-	cl.fileMutex.RLock()
-	if lf, exists := cl.fileMap[conversationID]; exists {
-		cl.fileMutex.RUnlock()
-		return lf, nil
-	}
-	cl.fileMutex.RUnlock()
-
-	// 需要创建新文件
-	cl.fileMutex.Lock()
-	defer cl.fileMutex.Unlock()
-
-	// 双重检查
-	if lf, exists := cl.fileMap[conversationID]; exists {
-		return lf, nil
-	}
-
-	// 构建文件路径
-	filename := fmt.Sprintf("%s.log", conversationID)
-	filepath := filepath.Join(cl.logDir, filename)
-
-	// 以追加模式打开文件
-	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("打开日志文件失败: %w", err)
-	}
-
-	// 创建大缓冲写入器（64KB）
-	writer := bufio.NewWriterSize(file, 65536)
-
-	lf := &logFile{
-		file:       file,
-		writer:     writer,
-		lastAccess: time.Now(),
-	}
-
-	cl.fileMap[conversationID] = lf
-
-	// 写入会话开始标记
-	startLine := fmt.Sprintf("\n=== 会话开始: %s ===\n", time.Now().Format("2006-01-02 15:04:05"))
-	writer.WriteString(startLine)
-
-	return lf, nil
 }
 
 // maintenance 定期维护任务
@@ -240,7 +220,7 @@ func (cl *ChatLogger) maintenance() {
 	for {
 		select {
 		case <-ticker.C:
-			cl.flushAllFiles()
+			cl.driver.maintain()
 			cl.printStats()
 
 		case <-cl.shutdownCh:
@@ -249,19 +229,7 @@ func (cl *ChatLogger) maintenance() {
 	}
 }
 
-// flushAllFiles 刷新所有文件的缓冲区
-func (cl *ChatLogger) flushAllFiles() {
-	cl.fileMutex.RLock()
-	defer cl.fileMutex.RUnlock()
-
-	for conversationID, lf := range cl.fileMap {
-		if err := lf.writer.Flush(); err != nil {
-			fmt.Printf("刷新日志文件 %s 失败: %v\n", conversationID, err)
-		}
-	}
-}
-
-// printStats 打印统计信息（仅在需要时）
+// printStats 输出统计信息（仅在需要时）
 func (cl *ChatLogger) printStats() {
 	logged := atomic.LoadUint64(&cl.totalLogged)
 	dropped := atomic.LoadUint64(&cl.totalDropped)
@@ -269,14 +237,13 @@ func (cl *ChatLogger) printStats() {
 
 	// 只在有问题时打印，避免日志噪音
 	if dropped > 0 || queueLen > cl.queueSize/2 {
-		fmt.Printf("📊 日志统计 - 已记录: %d, 丢弃: %d, 队列长度: %d/%d\n",
-			logged, dropped, queueLen, cl.queueSize)
+		slog.Warn("聊天日志队列积压", "logged", logged, "dropped", dropped, "queue", queueLen, "capacity", cl.queueSize)
 	}
 }
 
 // Close 优雅关闭日志记录器
 func (cl *ChatLogger) Close() error {
-	fmt.Println("正在关闭日志记录器...")
+	slog.Info("正在关闭聊天日志记录器")
 
 	// 发送关闭信号
 	close(cl.shutdownCh)
@@ -284,38 +251,32 @@ func (cl *ChatLogger) Close() error {
 	// 等待工作协程完成
 	cl.workerWG.Wait()
 
-	// 最后刷新并关闭所有文件
-	cl.fileMutex.Lock()
-	defer cl.fileMutex.Unlock()
-
-	for conversationID, lf := range cl.fileMap {
-		// 写入会话结束标记
-		endLine := fmt.Sprintf("=== 会话结束: %s ===\n\n", time.Now().Format("2006-01-02 15:04:05"))
-		lf.writer.WriteString(endLine)
-
-		// 刷新缓冲区
-		if err := lf.writer.Flush(); err != nil {
-			fmt.Printf("刷新日志文件 %s 失败: %v\n", conversationID, err)
-		}
-
-		// 关闭文件
-		if err := lf.file.Close(); err != nil {
-			fmt.Printf("关闭日志文件 %s 失败: %v\n", conversationID, err)
-		}
+	// 最后刷新并关闭存储
+	if err := cl.driver.close(); err != nil {
+		slog.Warn("关闭聊天日志存储失败", "err", err)
 	}
 
 	// 打印最终统计
 	logged := atomic.LoadUint64(&cl.totalLogged)
 	dropped := atomic.LoadUint64(&cl.totalDropped)
 	if dropped > 0 {
-		fmt.Printf("⚠️  日志记录器已关闭 - 总计记录: %d, 丢弃: %d\n", logged, dropped)
+		slog.Warn("聊天日志记录器已关闭", "logged", logged, "dropped", dropped)
 	} else {
-		fmt.Printf("✅ 日志记录器已关闭 - 总计记录: %d\n", logged)
+		slog.Info("聊天日志记录器已关闭", "logged", logged)
 	}
 
 	return nil
 }
 
+// Messages 查询会话的聊天记录（尚在写入队列中的记录最多延迟flushInterval可见）
+func (cl *ChatLogger) Messages(conversationID string, query MessageQuery) ([]LogEntry, int, error) {
+	// 会话标识同时用作文件名，拒绝路径分隔符
+	if conversationID == "" || strings.ContainsAny(conversationID, `/\`) || strings.Contains(conversationID, "..") {
+		return nil, 0, fmt.Errorf("会话标识无效: %q", conversationID)
+	}
+	return cl.driver.read(conversationID, query)
+}
+
 // GetStats 获取统计信息（供外部监控使用）
 func (cl *ChatLogger) GetStats() (logged uint64, dropped uint64, queueLen int) {
 	return atomic.LoadUint64(&cl.totalLogged),
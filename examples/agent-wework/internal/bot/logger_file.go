@@ -0,0 +1,249 @@
+package bot
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileLogDriver 按会话写入纯文本文件的聊天记录存储（默认）
+//
+// 每个会话一个 <conversationID>.log 文件，写入经过64KB缓冲；
+// 空闲超过idleTimeout的文件会被关闭，下次写入时重新打开追加。
+type fileLogDriver struct {
+	logDir      string
+	fileMap     map[string]*logFile // conversationID -> logFile
+	fileMutex   sync.RWMutex
+	idleTimeout time.Duration // 文件空闲超过该时间后关闭，下次写入时重新打开
+}
+
+// logFile 包装日志文件和缓冲写入器
+type logFile struct {
+	file       *os.File
+	writer     *bufio.Writer
+	lastAccess time.Time
+	closed     bool       // 已因空闲关闭（写入方需重新获取）
+	mutex      sync.Mutex // 保护写入、刷新与关闭
+}
+
+// newFileLogDriver 创建文件存储（确保日志目录存在）
+func newFileLogDriver(logDir string, idleTimeout time.Duration) (*fileLogDriver, error) {
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	return &fileLogDriver{
+		logDir:      logDir,
+		fileMap:     make(map[string]*logFile),
+		idleTimeout: idleTimeout,
+	}, nil
+}
+
+// write 追加一批日志条目到会话文件
+func (d *fileLogDriver) write(conversationID string, entries []LogEntry) error {
+	lf, err := d.lockLogFile(conversationID)
+	if err != nil {
+		return err
+	}
+	defer lf.mutex.Unlock()
+
+	// 更新最后访问时间
+	lf.lastAccess = time.Now()
+
+	// 批量写入
+	for _, entry := range entries {
+		timestamp := entry.Timestamp.Format("2006-01-02 15:04:05")
+		if entry.RequestID != "" {
+			timestamp += "][" + entry.RequestID
+		}
+		logLine := fmt.Sprintf("[%s]%s:%s\n", timestamp, entry.UserID, entry.Content)
+
+		if _, err := lf.writer.WriteString(logLine); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockLogFile 获取并锁定会话的日志文件（获取后恰好因空闲被关闭时重新打开）
+func (d *fileLogDriver) lockLogFile(conversationID string) (*logFile, error) {
+	for {
+		lf, err := d.getOrCreateLogFile(conversationID)
+		if err != nil {
+			return nil, err
+		}
+		lf.mutex.Lock()
+		if !lf.closed {
+			return lf, nil
+		}
+		lf.mutex.Unlock()
+	}
+}
+
+// getOrCreateLogFile 获取或创建日志文件
+func (d *fileLogDriver) getOrCreateLogFile(conversationID string) (*logFile, error) {
+	d.fileMutex.RLock()
+	if lf, exists := d.fileMap[conversationID]; exists {
+		d.fileMutex.RUnlock()
+		return lf, nil
+	}
+	d.fileMutex.RUnlock()
+
+	// 需要创建新文件
+	d.fileMutex.Lock()
+	defer d.fileMutex.Unlock()
+
+	// 双重检查
+	if lf, exists := d.fileMap[conversationID]; exists {
+		return lf, nil
+	}
+
+	// 构建文件路径
+	filename := fmt.Sprintf("%s.log", conversationID)
+	path := filepath.Join(d.logDir, filename)
+
+	// 以追加模式打开文件
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+
+	// 创建大缓冲写入器（64KB）
+	writer := bufio.NewWriterSize(file, 65536)
+
+	lf := &logFile{
+		file:       file,
+		writer:     writer,
+		lastAccess: time.Now(),
+	}
+
+	d.fileMap[conversationID] = lf
+
+	// 写入会话开始标记
+	startLine := fmt.Sprintf("\n=== 会话开始: %s ===\n", time.Now().Format("2006-01-02 15:04:05"))
+	writer.WriteString(startLine)
+
+	return lf, nil
+}
+
+// flushAllFiles 刷新所有文件的缓冲区
+func (d *fileLogDriver) flushAllFiles() {
+	d.fileMutex.RLock()
+	defer d.fileMutex.RUnlock()
+
+	for conversationID, lf := range d.fileMap {
+		lf.mutex.Lock()
+		if err := lf.writer.Flush(); err != nil {
+			slog.Warn("刷新聊天日志文件失败", "conversation_id", conversationID, "err", err)
+		}
+		lf.mutex.Unlock()
+	}
+}
+
+// closeIdleFiles 关闭空闲超时的文件，释放文件描述符（下次写入时重新打开）
+func (d *fileLogDriver) closeIdleFiles() {
+	d.fileMutex.Lock()
+	defer d.fileMutex.Unlock()
+
+	now := time.Now()
+	for conversationID, lf := range d.fileMap {
+		lf.mutex.Lock()
+		if now.Sub(lf.lastAccess) >= d.idleTimeout {
+			closeLogFile(conversationID, lf)
+			delete(d.fileMap, conversationID)
+		}
+		lf.mutex.Unlock()
+	}
+}
+
+// closeLogFile 写入会话结束标记、刷新并关闭文件（调用方需持有lf.mutex）
+func closeLogFile(conversationID string, lf *logFile) {
+	// 写入会话结束标记
+	endLine := fmt.Sprintf("=== 会话结束: %s ===\n\n", time.Now().Format("2006-01-02 15:04:05"))
+	lf.writer.WriteString(endLine)
+
+	// 刷新缓冲区
+	if err := lf.writer.Flush(); err != nil {
+		slog.Warn("刷新聊天日志文件失败", "conversation_id", conversationID, "err", err)
+	}
+
+	// 关闭文件
+	if err := lf.file.Close(); err != nil {
+		slog.Warn("关闭聊天日志文件失败", "conversation_id", conversationID, "err", err)
+	}
+	lf.closed = true
+}
+
+// logLinePattern 记录行：[时间][请求ID]发送者:内容（请求ID可选），不匹配的行属于上一条记录的多行内容
+var logLinePattern = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\](?:\[([^\]]+)\])?([^:\[]*):(.*)$`)
+
+// read 读取会话文件（先刷新缓冲区），解析后按时间范围分页
+func (d *fileLogDriver) read(conversationID string, query MessageQuery) ([]LogEntry, int, error) {
+	d.fileMutex.RLock()
+	if lf, ok := d.fileMap[conversationID]; ok {
+		lf.mutex.Lock()
+		lf.writer.Flush()
+		lf.mutex.Unlock()
+	}
+	d.fileMutex.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(d.logDir, conversationID+".log"))
+	if os.IsNotExist(err) {
+		return []LogEntry{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matched []LogEntry
+	var current *LogEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := logLinePattern.FindStringSubmatch(line); m != nil {
+			if current != nil && query.match(current.Timestamp) {
+				matched = append(matched, *current)
+			}
+			t, _ := time.ParseInLocation("2006-01-02 15:04:05", m[1], time.Local)
+			current = &LogEntry{ConversationID: conversationID, Timestamp: t, RequestID: m[2], UserID: m[3], Content: m[4]}
+			continue
+		}
+		if strings.HasPrefix(line, "=== 会话") {
+			continue
+		}
+		if current != nil {
+			current.Content += "\n" + line
+		}
+	}
+	if current != nil && query.match(current.Timestamp) {
+		matched = append(matched, *current)
+	}
+	for i := range matched {
+		// 记录之间的空行（会话结束标记前后）不属于内容
+		matched[i].Content = strings.TrimRight(matched[i].Content, "\n")
+	}
+	return query.page(matched), len(matched), nil
+}
+
+// maintain 刷新缓冲区并关闭空闲文件
+func (d *fileLogDriver) maintain() {
+	d.flushAllFiles()
+	d.closeIdleFiles()
+}
+
+// close 写入会话结束标记并关闭所有文件
+func (d *fileLogDriver) close() error {
+	d.fileMutex.Lock()
+	defer d.fileMutex.Unlock()
+
+	for conversationID, lf := range d.fileMap {
+		lf.mutex.Lock()
+		closeLogFile(conversationID, lf)
+		lf.mutex.Unlock()
+	}
+	d.fileMap = make(map[string]*logFile)
+	return nil
+}
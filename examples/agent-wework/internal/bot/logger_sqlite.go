@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite驱动
+)
+
+// chatLogSchema 聊天记录表（按会话和时间建索引，便于查询历史、统计和按时间清理）
+const chatLogSchema = `
+CREATE TABLE IF NOT EXISTS messages (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	time            INTEGER NOT NULL,
+	conversation_id TEXT    NOT NULL,
+	request_id      TEXT    NOT NULL DEFAULT '',
+	user_id         TEXT    NOT NULL,
+	content         TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(conversation_id, time);
+CREATE INDEX IF NOT EXISTS idx_messages_time ON messages(time);
+`
+
+// sqliteLogDriver 写入SQLite数据库的聊天记录存储
+//
+// 同一批记录在一个事务中写入；time为Unix秒，可直接按会话和时间范围查询：
+//
+//	SELECT datetime(time, 'unixepoch', 'localtime'), user_id, content
+//	FROM messages WHERE conversation_id = ? ORDER BY time, id
+type sqliteLogDriver struct {
+	db *sql.DB
+}
+
+// newSQLiteLogDriver 打开（或创建）聊天记录数据库
+func newSQLiteLogDriver(path string) (*sqliteLogDriver, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("打开聊天记录数据库失败: %w", err)
+	}
+	// SQLite同一时间只允许一个写入者
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(chatLogSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化聊天记录数据库失败: %w", err)
+	}
+	return &sqliteLogDriver{db: db}, nil
+}
+
+// write 在一个事务中插入一批记录
+func (d *sqliteLogDriver) write(conversationID string, entries []LogEntry) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO messages (time, conversation_id, request_id, user_id, content) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		if _, err := stmt.Exec(entry.Timestamp.Unix(), conversationID, entry.RequestID, entry.UserID, entry.Content); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// read 按时间范围分页查询会话记录
+func (d *sqliteLogDriver) read(conversationID string, query MessageQuery) ([]LogEntry, int, error) {
+	where := `conversation_id = ?`
+	args := []interface{}{conversationID}
+	if !query.Since.IsZero() {
+		where += ` AND time >= ?`
+		args = append(args, query.Since.Unix())
+	}
+	if !query.Until.IsZero() {
+		where += ` AND time < ?`
+		args = append(args, query.Until.Unix())
+	}
+
+	var total int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite中LIMIT -1表示不限
+	}
+	rows, err := d.db.Query(`SELECT time, request_id, user_id, content FROM messages WHERE `+where+
+		` ORDER BY time, id LIMIT ? OFFSET ?`, append(args, limit, query.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []LogEntry{}
+	for rows.Next() {
+		var unix int64
+		entry := LogEntry{ConversationID: conversationID}
+		if err := rows.Scan(&unix, &entry.RequestID, &entry.UserID, &entry.Content); err != nil {
+			return nil, 0, err
+		}
+		entry.Timestamp = time.Unix(unix, 0)
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
+
+// maintain 每次写入已提交，无需维护
+func (d *sqliteLogDriver) maintain() {}
+
+// close 关闭数据库
+func (d *sqliteLogDriver) close() error {
+	return d.db.Close()
+}
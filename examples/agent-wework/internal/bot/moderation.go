@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/agent"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/moderation"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+// auditContentLimit 审核日志中记录的内容长度上限（字符）
+const auditContentLimit = 500
+
+// newModerator 根据配置创建内容审核器（未启用时返回nil）
+func newModerator(cfg *config.Config) (*moderation.Moderator, error) {
+	m := cfg.Moderation
+	if !m.Enabled {
+		return nil, nil
+	}
+
+	opts := moderation.Options{
+		Input:    moderation.Rules{Keywords: m.Input.Keywords, Patterns: m.Input.Patterns, UseLLM: m.Input.LLM},
+		Output:   moderation.Rules{Keywords: m.Output.Keywords, Patterns: m.Output.Patterns, UseLLM: m.Output.LLM},
+		Criteria: m.Criteria,
+		Timeout:  time.Duration(m.Timeout) * time.Second,
+	}
+	if m.Input.LLM || m.Output.LLM {
+		llmName := m.LLMProvider
+		if llmName == "" {
+			llmName = cfg.LLM.Default
+		}
+		client, err := llm.CreateLLMByName(cfg, llmName, applog.SDK())
+		if err != nil {
+			return nil, fmt.Errorf("创建审核模型失败: %w", err)
+		}
+		opts.LLM = client
+	}
+	return moderation.New(opts)
+}
+
+// subscribeModerationAudit 输出并记录审核命中
+func subscribeModerationAudit(bus *events.Bus, path string) {
+	events.Subscribe(bus, func(e events.ModerationHit) {
+		detail := e.Match
+		if detail == "" {
+			detail = e.Reason
+		}
+		slog.Info("内容审核命中", "conversation_id", e.ConversationID, "stage", e.Stage, "rule", e.Rule, "detail", detail, "action", e.Action)
+
+		if path == "" {
+			return
+		}
+		entry := moderation.AuditEntry{
+			Time:           e.Time,
+			ConversationID: e.ConversationID,
+			UserID:         e.UserID,
+			StreamID:       e.StreamID,
+			Stage:          e.Stage,
+			Rule:           e.Rule,
+			Match:          e.Match,
+			Reason:         e.Reason,
+			Action:         e.Action,
+			Content:        truncateRunes(e.Content, auditContentLimit),
+		}
+		if err := fsutil.AppendJSONLine(path, entry); err != nil {
+			slog.Warn("写入内容审核日志失败", "err", err)
+		}
+	})
+}
+
+// moderateInput 审核用户消息，被拦截时返回策略回复（消息不进入Agent和会话记忆）
+func (b *BotHandler) moderateInput(msg *wework.IncomingMessage, text string) (*wework.WeWorkResponse, bool) {
+	tcm := b.taskCache
+	if !tcm.moderator.ChecksInput() {
+		return nil, false
+	}
+	hit := tcm.moderator.CheckInput(context.Background(), text)
+	if hit == nil {
+		return nil, false
+	}
+
+	b.events.Publish(events.ModerationHit{
+		ConversationID: msg.GetConversationKey(),
+		UserID:         msg.From.UserID,
+		Stage:          string(hit.Stage),
+		Rule:           hit.Rule,
+		Match:          hit.Match,
+		Reason:         hit.Reason,
+		Action:         "blocked",
+		Content:        text,
+		Time:           time.Now(),
+	})
+	return wework.NewTextResponse(tcm.moderation.PolicyReply), true
+}
+
+// moderateAnswer 审核AI回复：命中时告知原因重新生成，超过次数仍不合规时替换为配置的文本
+func (tcm *TaskCacheManager) moderateAnswer(ctx context.Context, task *TaskInfo, convAgent *agent.Agent, question, answer string, state *streamState) string {
+	hit := tcm.moderator.CheckOutput(ctx, answer)
+	for attempt := 1; hit != nil; attempt++ {
+		regenerate := attempt <= tcm.moderation.MaxRegenerate
+		action := "replaced"
+		if regenerate {
+			action = "regenerate"
+		}
+		tcm.events.Publish(events.ModerationHit{
+			StreamID:       task.StreamID,
+			ConversationID: task.ConversationID,
+			Stage:          string(hit.Stage),
+			Rule:           hit.Rule,
+			Match:          hit.Match,
+			Reason:         hit.Reason,
+			Action:         action,
+			Content:        answer,
+			Time:           time.Now(),
+		})
+		if !regenerate {
+			return tcm.moderation.Replacement
+		}
+
+		output := stream.AcquireBuffer()
+		agentEvents, err := convAgent.RunStream(ctx, buildRegeneratePrompt(question, hit))
+		if err == nil {
+			err = tcm.consumeEvents(task, output, agentEvents, state)
+		}
+		answer = output.Snapshot()
+		output.Release()
+		if err != nil {
+			slog.Warn("重新生成回复失败", "stream_id", task.StreamID, "err", err)
+			return tcm.moderation.Replacement
+		}
+		hit = tcm.moderator.CheckOutput(ctx, answer)
+	}
+	return answer
+}
+
+// buildRegeneratePrompt 构造重新生成请求：说明未通过审核的原因，不附带原回答避免再次引用
+func buildRegeneratePrompt(question string, hit *moderation.Hit) string {
+	reason := hit.Reason
+	switch hit.Rule {
+	case "keyword":
+		reason = fmt.Sprintf("包含敏感词「%s」", hit.Match)
+	case "regex":
+		reason = fmt.Sprintf("包含不允许输出的内容「%s」", hit.Match)
+	}
+	return fmt.Sprintf("%s\n\n[系统提示] 你对上述问题的回答未通过内容安全审核（%s）。请重新回答，避免任何不合规的内容；如果无法合规地回答，请礼貌地说明无法提供帮助。",
+		question, reason)
+}
+
+// truncateRunes 按字符截断
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/ocr"
+)
+
+// imagesKey 上下文中待识别的图片URL
+type imagesKey struct{}
+
+// withImages 在上下文中记录消息携带的图片（由任务处理时识别文字）
+func withImages(ctx context.Context, urls []string) context.Context {
+	return context.WithValue(ctx, imagesKey{}, urls)
+}
+
+// imagesFrom 获取上下文中待识别的图片
+func imagesFrom(ctx context.Context) []string {
+	urls, _ := ctx.Value(imagesKey{}).([]string)
+	return urls
+}
+
+// imageReader 图片文字识别：下载并解密企业微信图片后交给OCR服务
+type imageReader struct {
+	ocr   *ocr.Service
+	crypt *wework.WXBizJsonMsgCrypt
+}
+
+// newImageReader 创建图片文字识别（未启用时返回nil）
+func newImageReader(cfg *config.Config) (*imageReader, error) {
+	service, err := ocr.NewServiceFromConfig(cfg.OCR)
+	if err != nil || service == nil {
+		return nil, err
+	}
+	crypt, err := wework.NewWXBizJsonMsgCrypt(cfg.WeWork.Token, cfg.WeWork.AESKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("初始化图片解密失败: %w", err)
+	}
+	return &imageReader{ocr: service, crypt: crypt}, nil
+}
+
+// readImages 识别图片中的文字并加入问题，识别期间显示进度提示
+func (tcm *TaskCacheManager) readImages(ctx context.Context, task *TaskInfo, urls []string) {
+	task.Buffer.SetEphemeral("🔍 正在识别图片中的文字...")
+	text := tcm.images.ocr.Extract(ctx, urls, tcm.images.crypt.DownloadMedia)
+	task.Buffer.SetEphemeral("")
+
+	var note string
+	if text == "" {
+		note = "\n\n[用户发送了图片，未能识别出其中的文字]"
+	} else {
+		note = "\n\n[用户发送的图片中的文字（OCR识别，可能有个别错字）]\n" + text
+		slog.Info("图片文字识别完成", "chars", len([]rune(text)), "stream_id", task.StreamID)
+	}
+
+	task.mutex.Lock()
+	task.Question += note
+	task.mutex.Unlock()
+}
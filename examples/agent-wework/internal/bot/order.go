@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+	"github.com/deepsage-ai/b0dy/pkg/bot"
+)
+
+// inTurn 按调用顺序登记会话的一轮回复，返回的process在上一轮结束后才开始执行
+//
+// 登记在收到消息时同步完成，保证执行顺序与消息到达顺序一致
+func (tcm *TaskCacheManager) inTurn(conversationID string, process func(ctx context.Context, streamID string)) func(ctx context.Context, streamID string) {
+	turn := tcm.turns.Enter(conversationID)
+
+	return func(ctx context.Context, streamID string) {
+		defer turn.Done()
+		tcm.waitTurn(ctx, streamID, turn)
+		// 等待期间任务被取消时process会立即结束任务
+		process(ctx, streamID)
+	}
+}
+
+// waitTurn 等待上一轮回复结束，期间在回复中展示等待提示
+func (tcm *TaskCacheManager) waitTurn(ctx context.Context, streamID string, turn *bot.Turn) {
+	if turn.Ready() {
+		return
+	}
+
+	tcm.mutex.RLock()
+	task, exists := tcm.tasks[streamID]
+	tcm.mutex.RUnlock()
+	if exists {
+		task.Buffer.SetEphemeral(tcm.convAgentManager.text(task.Locale, i18n.TurnWaiting))
+		defer task.Buffer.SetEphemeral("")
+	}
+
+	slog.Debug("等待会话的上一轮回复结束", "stream_id", streamID)
+	turn.Wait(ctx)
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/notify"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/relay"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+const (
+	// webhookContinuationBytes 群机器人续发的单条长度（Markdown消息上限4096字节）
+	webhookContinuationBytes = 4000
+	// appContinuationBytes 应用消息续发的单条长度（Markdown消息上限2048字节，预留序号前缀）
+	appContinuationBytes = wework.AppMarkdownMaxBytes - 64
+	// continuationTimeout 续发全部剩余内容的超时
+	continuationTimeout = 30 * time.Second
+)
+
+// continuationChannels 超长回复的续发渠道：单聊通过自建应用消息发给用户本人，群聊通过该群配置的群机器人
+//
+// 没有对应渠道的会话截断，不会退回到共享的通知群机器人，避免单聊内容发到其他人所在的群。
+type continuationChannels struct {
+	app    *notify.AppSender // 单聊续发（未配置自建应用时为nil）
+	groups *GroupSettings    // 群聊续发的Webhook（groups.<chatid>.webhook）
+}
+
+// newContinuationChannels 创建超长回复的续发渠道
+func newContinuationChannels(cfg config.ContinuationConfig, groups *GroupSettings) *continuationChannels {
+	channels := &continuationChannels{groups: groups}
+	if cfg.AgentID > 0 {
+		client := wework.NewAppClient(cfg.CorpID, cfg.Secret, cfg.AgentID)
+		if cfg.BaseURL != "" {
+			client.SetBaseURL(cfg.BaseURL)
+		}
+		channels.app = notify.NewAppSender(client)
+	}
+	return channels
+}
+
+// sender 会话的续发渠道和单条长度（没有渠道时返回nil，超长部分截断）
+func (c *continuationChannels) sender(conversationID string) (notify.Sender, int) {
+	if c == nil {
+		return nil, 0
+	}
+	if strings.HasPrefix(conversationID, "single_") {
+		if c.app == nil {
+			return nil, 0
+		}
+		return c.app, appContinuationBytes
+	}
+	if url := c.groups.Webhook(conversationID); url != "" {
+		return notify.NewWebhookSender(url), webhookContinuationBytes
+	}
+	return nil, 0
+}
+
+// clampAnswer 回复超过单条流式消息上限时，在安全位置结束本条消息（finish=true），剩余内容在回复完成后续发
+func (tcm *TaskCacheManager) clampAnswer(streamID, answer string, finish bool) (string, bool) {
+	if len(answer) <= tcm.streamConfig.MaxBytes {
+		return answer, finish
+	}
+	tcm.mutex.RLock()
+	task, exists := tcm.tasks[streamID]
+	tcm.mutex.RUnlock()
+	if !exists {
+		notice := tcm.convAgentManager.text(tcm.convAgentManager.config.Locale, i18n.Truncated)
+		return safeCut(answer, tcm.streamConfig.MaxBytes-len(notice)) + notice, true
+	}
+
+	notice := tcm.convAgentManager.text(task.Locale, i18n.Truncated)
+	if sender, _ := tcm.continuation.sender(task.ConversationID); sender != nil {
+		notice = tcm.convAgentManager.text(task.Locale, i18n.Continued)
+	}
+
+	task.mutex.Lock()
+	if task.overflow == "" {
+		head := safeCut(answer, tcm.streamConfig.MaxBytes-len(notice))
+		task.overflow = head + notice
+		task.delivered = stream.StripThinkTags(head)
+		slog.Info("回复超过上限，结束本条流式消息", "max_bytes", tcm.streamConfig.MaxBytes, "stream_id", streamID)
+	}
+	shown := task.overflow
+	task.mutex.Unlock()
+
+	// 回复已完成时立即续发，否则由回复完成时续发
+	if task.Buffer.IsAIFinished() {
+		tcm.continueOverflow(task)
+	}
+	return shown, true
+}
+
+// continueOverflow 回复完成后续发超长回复的剩余内容（只发送一次）
+func (tcm *TaskCacheManager) continueOverflow(task *TaskInfo) {
+	sender, size := tcm.continuation.sender(task.ConversationID)
+	if sender == nil {
+		return
+	}
+	task.mutex.Lock()
+	if task.overflow == "" || task.continued {
+		task.mutex.Unlock()
+		return
+	}
+	task.continued = true
+	delivered := task.delivered
+	task.mutex.Unlock()
+
+	go tcm.sendContinuation(task, delivered, sender, size)
+}
+
+// sendContinuation 通过会话的续发渠道分条发送剩余内容（不含思考过程）
+func (tcm *TaskCacheManager) sendContinuation(task *TaskInfo, delivered string, sender notify.Sender, size int) {
+	final := stream.StripThinkTags(task.Buffer.Snapshot())
+	remainder, ok := strings.CutPrefix(final, delivered)
+	if !ok {
+		// 已展示的内容在之后被改写（如合并思考过程），按长度续发
+		cut := min(len(delivered), len(final))
+		for cut < len(final) && !utf8.RuneStart(final[cut]) {
+			cut++
+		}
+		remainder = final[cut:]
+	}
+	remainder = strings.TrimSpace(remainder)
+	if remainder == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), continuationTimeout)
+	defer cancel()
+	chunks := relay.Split(remainder, size)
+	for i, chunk := range chunks {
+		content := tcm.convAgentManager.text(task.Locale, i18n.ContinuationPart, "part", strconv.Itoa(i+1), "total", strconv.Itoa(len(chunks))) + "\n" + chunk
+		if err := sender.Send(ctx, task.ConversationID, content); err != nil {
+			slog.Warn("续发超长回复失败", "stream_id", task.StreamID, "err", err)
+			return
+		}
+	}
+	slog.Info("已续发超长回复的剩余内容", "messages", len(chunks), "stream_id", task.StreamID)
+}
+
+// safeCut 截取不超过limit字节的开头部分：尽量在换行处断开，不截断UTF-8字符，
+// 不停在未闭合的代码块或思考过程中（无法回退时补上闭合标记）
+func safeCut(text string, limit int) string {
+	limit -= len("\n```\n</think>") // 预留闭合标记
+	head := strings.TrimRight(relay.Split(text, limit)[0], "\n")
+	if strings.Count(head, "```")%2 == 1 {
+		if i := strings.LastIndex(head, "```"); i > limit/2 {
+			head = strings.TrimRight(head[:i], "\n")
+		} else {
+			head += "\n```"
+		}
+	}
+	if strings.Count(head, "<think>") > strings.Count(head, "</think>") {
+		head += "\n</think>"
+	}
+	return head
+}
@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// personaSelections 会话通过 /persona 选择或由路由选定的人设（仅保存在内存中，重启后恢复默认人设）
+type personaSelections struct {
+	selected map[string]string // conversationID -> /persona 选择的人设
+	routed   map[string]string // conversationID -> 多智能体路由选定的专家
+	mutex    sync.RWMutex
+}
+
+// newPersonaSelections 创建人设选择记录
+func newPersonaSelections() *personaSelections {
+	return &personaSelections{
+		selected: make(map[string]string),
+		routed:   make(map[string]string),
+	}
+}
+
+// Persona 获取会话选择的人设（未选择时为空）
+func (cam *ConversationAgentManager) Persona(conversationID string) string {
+	cam.personas.mutex.RLock()
+	defer cam.personas.mutex.RUnlock()
+
+	return cam.personas.selected[conversationID]
+}
+
+// routedPersona 获取路由为会话选定的专家（未路由时为空）
+func (cam *ConversationAgentManager) routedPersona(conversationID string) string {
+	cam.personas.mutex.RLock()
+	defer cam.personas.mutex.RUnlock()
+
+	return cam.personas.routed[conversationID]
+}
+
+// setRoutedPersona 记录路由选定的专家，专家变化时会话Agent沿用会话记忆按新人设重建
+func (cam *ConversationAgentManager) setRoutedPersona(conversationID, persona string) {
+	cam.personas.mutex.Lock()
+	changed := cam.personas.routed[conversationID] != persona
+	cam.personas.routed[conversationID] = persona
+	cam.personas.mutex.Unlock()
+
+	if changed {
+		cam.ResetAgent(conversationID)
+	}
+}
+
+// SetPersona 切换会话人设（为空时恢复默认人设），会话Agent在下次消息时沿用会话记忆按新人设重建
+func (cam *ConversationAgentManager) SetPersona(conversationID, persona string) {
+	cam.personas.mutex.Lock()
+	if persona == "" {
+		delete(cam.personas.selected, conversationID)
+		delete(cam.personas.routed, conversationID)
+	} else {
+		cam.personas.selected[conversationID] = persona
+	}
+	cam.personas.mutex.Unlock()
+
+	cam.ResetAgent(conversationID)
+}
+
+// handlePersonaMessage 处理 /persona 命令（列出或切换人设），未配置人设或其他消息返回handled=false
+func (b *BotHandler) handlePersonaMessage(msg *wework.IncomingMessage) (resp *wework.WeWorkResponse, handled bool) {
+	cam := b.convAgentManager
+	personas := b.config.Personas
+	if len(personas) == 0 {
+		return nil, false
+	}
+	command, ok := strings.CutPrefix(stripMention(msg.GetTextContent()), "/persona")
+	if !ok || (command != "" && command[0] != ' ') {
+		return nil, false
+	}
+
+	conversationID := msg.GetConversationKey()
+	name := strings.TrimSpace(command)
+	switch name {
+	case "":
+		return wework.NewTextResponse(listPersonas(personas, cam.Features(conversationID).Persona)), true
+	case config.PersonaReset:
+		cam.SetPersona(conversationID, "")
+		current := cam.Features(conversationID).Persona
+		slog.Info("会话恢复默认人设", "conversation_id", conversationID, "persona", current)
+		if current == "" {
+			return wework.NewTextResponse("🎭 已恢复默认助手"), true
+		}
+		return wework.NewTextResponse(fmt.Sprintf("🎭 已恢复默认人设：%s", personas[current].Title(current))), true
+	}
+
+	persona, ok := personas[name]
+	if !ok {
+		return wework.NewTextResponse(fmt.Sprintf("❌ 人设 %s 不存在\n\n%s", name, listPersonas(personas, cam.Features(conversationID).Persona))), true
+	}
+	cam.SetPersona(conversationID, name)
+	slog.Info("会话切换人设", "conversation_id", conversationID, "persona", name)
+	return wework.NewTextResponse(fmt.Sprintf("🎭 已切换为 %s，接下来由我为您服务", persona.Title(name))), true
+}
+
+// listPersonas 列出可用人设，标记当前人设
+func listPersonas(personas map[string]config.PersonaConfig, current string) string {
+	names := make([]string, 0, len(personas))
+	for name := range personas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("🎭 可用人设：")
+	for _, name := range names {
+		persona := personas[name]
+		marker := ""
+		if name == current {
+			marker = "（当前）"
+		}
+		fmt.Fprintf(&sb, "\n%s —— %s%s", name, persona.Title(name), marker)
+		if persona.Description != "" {
+			fmt.Fprintf(&sb, "：%s", persona.Description)
+		}
+	}
+	fmt.Fprintf(&sb, "\n\n发送 /persona <名称> 切换，/persona %s 恢复默认", config.PersonaReset)
+	return sb.String()
+}
@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/preferences"
+)
+
+// maxLanguageLength 回复语言名称的最大长度（字符）
+const maxLanguageLength = 20
+
+// verbosityNames 回答详略的展示名称
+var verbosityNames = map[string]string{
+	"":                            "适中",
+	preferences.VerbosityConcise:  "简洁",
+	preferences.VerbosityNormal:   "适中",
+	preferences.VerbosityDetailed: "详细",
+}
+
+// userPreferences 获取单聊会话用户的偏好（未启用或群聊时返回false，群聊Agent由多人共用）
+func (cam *ConversationAgentManager) userPreferences(conversationID string) (preferences.Preferences, bool) {
+	if cam.prefs == nil {
+		return preferences.Preferences{}, false
+	}
+	userID, ok := strings.CutPrefix(conversationID, "single_")
+	if !ok {
+		return preferences.Preferences{}, false
+	}
+	return cam.prefs.Get(userID), true
+}
+
+// applyPreferences 按用户偏好选择模型（会话覆盖指定的模型优先，偏好的模型已不可选时忽略）
+func (cam *ConversationAgentManager) applyPreferences(conversationID string, f config.Features) config.Features {
+	p, ok := cam.userPreferences(conversationID)
+	if !ok || p.Model == "" || f.LLMProvider != "" {
+		return f
+	}
+	if slices.Contains(cam.config.Preferences.Models, p.Model) {
+		f.LLMProvider = p.Model
+	}
+	return f
+}
+
+// notifyAllowed 用户是否接收该类主动通知（审批通知不受个人设置影响）
+func (b *BotHandler) notifyAllowed(target, category string) bool {
+	if category == config.ApprovalCategory {
+		return true
+	}
+	p, ok := b.convAgentManager.userPreferences(target)
+	return !ok || p.NotifyEnabled()
+}
+
+// handlePreferencesMessage 处理 /settings 命令（查看或修改个人偏好），未启用或其他消息返回handled=false
+func (b *BotHandler) handlePreferencesMessage(msg *wework.IncomingMessage) (*wework.WeWorkResponse, bool) {
+	store := b.convAgentManager.prefs
+	if store == nil {
+		return nil, false
+	}
+	command, ok := strings.CutPrefix(stripMention(msg.GetTextContent()), "/settings")
+	if !ok || (command != "" && command[0] != ' ') {
+		return nil, false
+	}
+
+	userID := msg.From.UserID
+	models := b.config.Preferences.Models
+	key, value, _ := strings.Cut(strings.TrimSpace(command), " ")
+	value = strings.TrimSpace(value)
+
+	var update func(p *preferences.Preferences)
+	switch key {
+	case "":
+		return wework.NewTextResponse(describePreferences(store.Get(userID), b.config)), true
+	case "reset":
+		update = func(p *preferences.Preferences) { *p = preferences.Preferences{UserID: userID} }
+	case "language":
+		if value == "" || utf8.RuneCountInString(value) > maxLanguageLength {
+			return wework.NewTextResponse("用法：/settings language <语言>，如 English、日本語；auto 表示跟随提问语言"), true
+		}
+		if value == "auto" {
+			value = ""
+		}
+		update = func(p *preferences.Preferences) { p.Language = value }
+	case "verbosity":
+		if value != preferences.VerbosityConcise && value != preferences.VerbosityNormal && value != preferences.VerbosityDetailed {
+			return wework.NewTextResponse("用法：/settings verbosity concise|normal|detailed（简洁/适中/详细）"), true
+		}
+		if value == preferences.VerbosityNormal {
+			value = ""
+		}
+		update = func(p *preferences.Preferences) { p.Verbosity = value }
+	case "model":
+		if len(models) == 0 {
+			return wework.NewTextResponse("当前未开放模型选择"), true
+		}
+		if value != "default" && !slices.Contains(models, value) {
+			return wework.NewTextResponse(fmt.Sprintf("用法：/settings model <名称>|default，可选：%s", strings.Join(models, "、"))), true
+		}
+		if value == "default" {
+			value = ""
+		}
+		update = func(p *preferences.Preferences) { p.Model = value }
+	case "notify":
+		if value != "on" && value != "off" {
+			return wework.NewTextResponse("用法：/settings notify on|off（接收/不接收主动通知）"), true
+		}
+		enabled := value == "on"
+		update = func(p *preferences.Preferences) {
+			p.Notify = nil
+			if !enabled {
+				p.Notify = &enabled
+			}
+		}
+	case "voice":
+		if !b.config.TTS.Enabled {
+			return wework.NewTextResponse("当前未开启语音回复"), true
+		}
+		if value != "on" && value != "off" {
+			return wework.NewTextResponse("用法：/settings voice on|off（单聊回答完成后是否附带语音）"), true
+		}
+		enabled := value == "on"
+		update = func(p *preferences.Preferences) {
+			p.Voice = nil
+			if enabled != b.config.TTS.Default {
+				p.Voice = &enabled
+			}
+		}
+	default:
+		return wework.NewTextResponse(describePreferences(store.Get(userID), b.config)), true
+	}
+
+	p, err := store.Update(userID, update)
+	if err != nil {
+		return wework.NewTextResponse(fmt.Sprintf("❌ 保存设置失败: %v", err)), true
+	}
+	// 单聊Agent沿用会话记忆按新偏好重建
+	b.convAgentManager.ResetAgent("single_" + userID)
+	slog.Info("修改个人设置", "user_id", userID, "key", key, "value", value)
+
+	reply := "✅ 设置已更新\n\n" + describePreferences(p, b.config)
+	if msg.IsGroupChat() && key != "notify" {
+		reply += "\n\n语言、详略、模型和语音设置仅在与我单聊时生效"
+	}
+	return wework.NewTextResponse(reply), true
+}
+
+// describePreferences 展示当前偏好和修改方法
+func describePreferences(p preferences.Preferences, cfg *config.Config) string {
+	language := p.Language
+	if language == "" {
+		language = "跟随提问"
+	}
+	notifyText := "接收"
+	if !p.NotifyEnabled() {
+		notifyText = "不接收"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⚙️ 个人设置：")
+	fmt.Fprintf(&sb, "\n回复语言（language）：%s", language)
+	fmt.Fprintf(&sb, "\n回答详略（verbosity）：%s", verbosityNames[p.Verbosity])
+	if models := cfg.Preferences.Models; len(models) > 0 {
+		model := p.Model
+		if model == "" {
+			model = "默认"
+		}
+		fmt.Fprintf(&sb, "\n模型（model）：%s", model)
+	}
+	fmt.Fprintf(&sb, "\n主动通知（notify）：%s", notifyText)
+	if cfg.TTS.Enabled {
+		voiceText := "关闭"
+		if p.VoiceEnabled(cfg.TTS.Default) {
+			voiceText = "开启"
+		}
+		fmt.Fprintf(&sb, "\n语音回复（voice）：%s", voiceText)
+	}
+
+	sb.WriteString("\n\n修改：")
+	sb.WriteString("\n/settings language English|auto")
+	sb.WriteString("\n/settings verbosity concise|normal|detailed")
+	if models := cfg.Preferences.Models; len(models) > 0 {
+		fmt.Fprintf(&sb, "\n/settings model %s|default", strings.Join(models, "|"))
+	}
+	sb.WriteString("\n/settings notify on|off")
+	if cfg.TTS.Enabled {
+		sb.WriteString("\n/settings voice on|off")
+	}
+	sb.WriteString("\n/settings reset 恢复默认")
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/cluster"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/queue"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/translate"
+	"github.com/deepsage-ai/b0dy/pkg/bot"
+)
+
+// SetQueue 启用消息队列模式：消息入队由worker处理（需同时启用共享状态），需在开始处理消息前调用
+func (b *BotHandler) SetQueue(broker queue.Broker, name string) {
+	b.queue = broker
+	b.name = name
+}
+
+// enqueueTask 发布任务到队列，并写入排队状态供任意副本响应流式刷新
+func (b *BotHandler) enqueueTask(ctx context.Context, userID, question, conversationID string) (string, error) {
+	streamID, err := bot.NewStreamID()
+	if err != nil {
+		return "", fmt.Errorf("生成任务ID失败: %w", err)
+	}
+
+	putCtx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	err = b.taskCache.shared.Put(putCtx, b.taskCache.sharedKey(streamID), cluster.StreamState{
+		Answer:  b.text(i18n.FromContext(ctx), i18n.Pending), // worker开始处理前展示
+		Owner:   "queue",
+		Updated: time.Now(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("写入任务排队状态失败: %w", err)
+	}
+
+	job := queue.Job{
+		StreamID:       streamID,
+		Bot:            b.name,
+		Org:            b.config.Org(),
+		ConversationID: conversationID,
+		UserID:         userID,
+		Question:       question,
+		Language:       translate.SourceLanguage(ctx),
+		Locale:         i18n.FromContext(ctx),
+		Images:         imagesFrom(ctx),
+		Persona:        b.convAgentManager.Persona(conversationID),
+		RequestID:      applog.RequestID(ctx),
+		Created:        time.Now(),
+	}
+	if err := b.queue.Publish(ctx, job); err != nil {
+		return "", err
+	}
+	slog.InfoContext(ctx, "消息已入队", "stream_id", streamID, "conversation_id", conversationID)
+	return streamID, nil
+}
+
+// RunJob 处理队列中的任务（worker进程调用），回复通过共享状态发布，阻塞直到回复结束
+func (b *BotHandler) RunJob(ctx context.Context, job queue.Job) error {
+	if b.taskCache.shared == nil {
+		return fmt.Errorf("未启用共享状态，无法返回回复")
+	}
+
+	// 任务只由同一组织的机器人处理（多个部署共用队列时防止跨租户处理）
+	if job.Org != "" && job.Org != b.config.Org() {
+		return fmt.Errorf("任务属于组织 %s，机器人 %s 属于组织 %s（worker与Webhook服务的配置不一致？）", job.Org, job.Bot, b.config.Org())
+	}
+
+	// 沿用用户在Webhook副本上通过 /persona 选择的人设
+	cam := b.convAgentManager
+	if cam.Persona(job.ConversationID) != job.Persona {
+		cam.SetPersona(job.ConversationID, job.Persona)
+	}
+
+	ctx = multitenancy.WithOrgID(ctx, b.config.Org())
+	ctx = applog.WithRequestID(ctx, job.RequestID)
+	ctx = withRequester(ctx, job.UserID)
+	if job.Language != "" {
+		ctx = translate.WithSourceLanguage(ctx, job.Language)
+	}
+	if job.Locale != "" {
+		ctx = i18n.WithLocale(ctx, job.Locale)
+	}
+	if len(job.Images) > 0 {
+		ctx = withImages(ctx, job.Images)
+	}
+
+	slog.InfoContext(ctx, "处理队列任务", "stream_id", job.StreamID, "conversation_id", job.ConversationID, "queued", time.Since(job.Created).Truncate(time.Millisecond))
+	ctx = b.taskCache.addTask(ctx, job.StreamID, job.Question, job.ConversationID)
+	b.taskCache.inTurn(job.ConversationID, b.taskCache.processTaskAsync)(ctx, job.StreamID)
+
+	// 回复已结束（最终内容由共享状态发布），worker不再保留任务
+	b.taskCache.mutex.Lock()
+	delete(b.taskCache.tasks, job.StreamID)
+	b.taskCache.mutex.Unlock()
+	return nil
+}
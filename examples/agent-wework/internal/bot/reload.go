@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/mcp"
+)
+
+// mcpRetireDelay 旧MCP服务器的延迟关闭时间，保证进行中的回复能完成工具调用
+const mcpRetireDelay = 2 * time.Minute
+
+// ApplyConfig 热更新配置：系统提示词、LLM选择、MCP服务器启用状态、群聊配置、人设和营业时间即时生效，
+// 已有会话保留记忆，在下一条消息时按新配置重建Agent
+func (b *BotHandler) ApplyConfig(newCfg *config.Config) {
+	b.reloadMutex.Lock()
+	defer b.reloadMutex.Unlock()
+
+	oldCfg := b.config
+	var changes []string
+
+	if oldCfg.LLM.SystemPrompt != newCfg.LLM.SystemPrompt {
+		changes = append(changes, "系统提示词")
+	}
+	if oldCfg.LLM.Default != newCfg.LLM.Default || !reflect.DeepEqual(oldCfg.LLM.Providers, newCfg.LLM.Providers) {
+		changes = append(changes, fmt.Sprintf("LLM(默认=%s)", newCfg.LLM.Default))
+	}
+	if !reflect.DeepEqual(oldCfg.Groups, newCfg.Groups) {
+		changes = append(changes, "群聊配置")
+	}
+	if !reflect.DeepEqual(oldCfg.Personas, newCfg.Personas) || oldCfg.DefaultPersona != newCfg.DefaultPersona {
+		changes = append(changes, fmt.Sprintf("人设(%d个)", len(newCfg.Personas)))
+	}
+	if !reflect.DeepEqual(oldCfg.BusinessHours, newCfg.BusinessHours) {
+		changes = append(changes, "营业时间")
+	}
+	if !reflect.DeepEqual(oldCfg.Preferences.Models, newCfg.Preferences.Models) {
+		changes = append(changes, "可选模型")
+	}
+	if !reflect.DeepEqual(oldCfg.Experiment, newCfg.Experiment) {
+		changes = append(changes, "A/B实验")
+	}
+	if !reflect.DeepEqual(oldCfg.Welcome, newCfg.Welcome) {
+		changes = append(changes, "欢迎卡片")
+	}
+	if !reflect.DeepEqual(oldCfg.Schedules, newCfg.Schedules) {
+		changes = append(changes, fmt.Sprintf("定时任务(%d个)", len(newCfg.Schedules)))
+	}
+
+	// MCP配置变化时重新创建服务器，旧服务器延迟关闭
+	namedServers := b.convAgentManager.mcpServers
+	if !reflect.DeepEqual(oldCfg.MCP, newCfg.MCP) {
+		created, err := mcp.CreateNamedMCPServersFromConfig(newCfg)
+		if err != nil {
+			slog.Error("MCP配置变更被拒绝（继续使用当前MCP服务器）", "err", err)
+			newCfg.MCP = oldCfg.MCP
+		} else {
+			retireServers(b.mcpServers)
+			namedServers = b.convAgentManager.warm.wrapServers(created)
+			b.mcpServers = mcp.ServersOf(created)
+			changes = append(changes, fmt.Sprintf("MCP服务器(%d个)", len(created)))
+		}
+	}
+
+	restart := restartRequired(oldCfg, newCfg)
+	if len(restart) > 0 {
+		slog.Warn("以下配置变更需重启服务后生效", "fields", strings.Join(restart, ", "))
+	}
+
+	if len(changes) == 0 {
+		slog.Info("配置文件无可热更新的变更")
+		if len(restart) > 0 {
+			b.events.Publish(events.ConfigReloaded{Restart: restart, Time: time.Now()})
+		}
+		return
+	}
+
+	b.config = newCfg
+	b.convAgentManager.Reload(newCfg, namedServers)
+	b.scheduler.Set(b.scheduleJobs(newCfg))
+	slog.Info("配置已热更新", "changes", strings.Join(changes, ", "))
+	b.events.Publish(events.ConfigReloaded{Changes: changes, Restart: restart, Time: time.Now()})
+}
+
+// restartRequired 列出需要重启才能生效的配置变更
+func restartRequired(oldCfg, newCfg *config.Config) []string {
+	var sections []string
+	check := func(name string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			sections = append(sections, name)
+		}
+	}
+
+	check("wework", oldCfg.WeWork, newCfg.WeWork)
+	check("kf", oldCfg.KF, newCfg.KF)
+	check("slack", oldCfg.Slack, newCfg.Slack)
+	check("telegram", oldCfg.Telegram, newCfg.Telegram)
+	check("server", oldCfg.Server, newCfg.Server)
+	oldLogging, newLogging := oldCfg.Logging, newCfg.Logging
+	oldLogging.Level, newLogging.Level = "", "" // 日志级别由服务统一热更新
+	check("logging", oldLogging, newLogging)
+	check("stream", oldCfg.Stream, newCfg.Stream)
+	check("warm_pool", oldCfg.WarmPool, newCfg.WarmPool)
+	check("translation", oldCfg.Translation, newCfg.Translation)
+	check("ocr", oldCfg.OCR, newCfg.OCR)
+	check("tts", oldCfg.TTS, newCfg.TTS)
+	check("image_gen", oldCfg.ImageGen, newCfg.ImageGen)
+	check("ticket", oldCfg.Ticket, newCfg.Ticket)
+	check("directory", oldCfg.Directory, newCfg.Directory)
+	check("org_id", oldCfg.Org(), newCfg.Org())
+	check("jobs", oldCfg.Jobs, newCfg.Jobs)
+	check("summary", oldCfg.Summary, newCfg.Summary)
+	check("budget", oldCfg.Budget, newCfg.Budget)
+	check("profile", oldCfg.Profile, newCfg.Profile)
+	check("knowledge", oldCfg.Knowledge, newCfg.Knowledge)
+	check("notify", oldCfg.Notify, newCfg.Notify)
+	check("error_report", oldCfg.ErrorReport, newCfg.ErrorReport)
+	check("outbound_webhooks", oldCfg.Outbound, newCfg.Outbound)
+	check("moderation", oldCfg.Moderation, newCfg.Moderation)
+	check("handoff", oldCfg.Handoff, newCfg.Handoff)
+	check("approval", oldCfg.Approval, newCfg.Approval)
+	check("router", oldCfg.Router, newCfg.Router)
+	check("analytics", oldCfg.Analytics, newCfg.Analytics)
+	check("audit", oldCfg.Audit, newCfg.Audit)
+	check("preferences.enabled", oldCfg.Preferences.Enabled, newCfg.Preferences.Enabled)
+	check("preferences.path", oldCfg.Preferences.Path, newCfg.Preferences.Path)
+	check("welcome.enabled", oldCfg.Welcome.Enabled, newCfg.Welcome.Enabled)
+	check("welcome.path", oldCfg.Welcome.Path, newCfg.Welcome.Path)
+	check("dedup", oldCfg.Dedup, newCfg.Dedup)
+	check("cluster", oldCfg.Cluster, newCfg.Cluster)
+	check("queue", oldCfg.Queue, newCfg.Queue)
+
+	return sections
+}
+
+// retireServers 延迟关闭被替换的MCP服务器
+func retireServers(servers []interfaces.MCPServer) {
+	if len(servers) == 0 {
+		return
+	}
+	time.AfterFunc(mcpRetireDelay, func() {
+		for _, server := range servers {
+			if closer, ok := server.(interface{ Close() error }); ok {
+				closer.Close()
+			}
+		}
+	})
+}
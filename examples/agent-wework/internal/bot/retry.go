@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/agent"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+// withErrorCode 在错误回复后附上请求ID作为错误码，便于根据用户反馈在日志中定位（error_code配置为空时不附带）
+func (cam *ConversationAgentManager) withErrorCode(locale, text, requestID string) string {
+	if requestID == "" {
+		return text
+	}
+	suffix := cam.text(locale, i18n.ErrorCode, "code", requestID)
+	if suffix == "" {
+		return text
+	}
+	return text + "\n\n" + suffix
+}
+
+// runAgent 运行Agent并消费事件流，返回流中出现的错误
+//
+// 尚未输出任何内容时失败（模型服务5xx、网络中断等）按指数退避重新运行，重试前撤回失败尝试已输出的思考过程；
+// 已输出内容的中断由调用方续传，已执行工具调用时不重试（避免重复执行有副作用的操作）
+func (tcm *TaskCacheManager) runAgent(ctx context.Context, task *TaskInfo, convAgent *agent.Agent, question string, output *stream.Buffer, state *streamState) error {
+	delay := time.Duration(tcm.streamConfig.RetryBackoff) * time.Second
+	for attempt := 1; ; attempt++ {
+		mark := output.Chunks()
+		agentEvents, err := convAgent.RunStream(ctx, question)
+		if err == nil {
+			err = tcm.consumeEvents(task, output, agentEvents, state)
+		}
+		if err == nil || state.hasNormalContent || state.hasToolCall || ctx.Err() != nil || attempt > tcm.streamConfig.MaxRetries {
+			return err
+		}
+
+		slog.Warn("Agent运行失败，稍后重试", "delay", delay, "attempt", attempt, "max_retries", tcm.streamConfig.MaxRetries, "stream_id", task.StreamID, "err", err)
+		output.Truncate(mark)
+		task.Buffer.SetEphemeral(tcm.convAgentManager.text(task.Locale, i18n.RetryWaiting))
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+		task.Buffer.SetEphemeral("")
+		if ctx.Err() != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+)
+
+// attemptsLLM 每次运行依次输出一组预设事件的流式LLM（用于模拟失败后重试）
+type attemptsLLM struct {
+	scriptedLLM
+	mutex    sync.Mutex
+	attempts [][]interfaces.StreamEvent
+}
+
+func (l *attemptsLLM) next() <-chan interfaces.StreamEvent {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var events []interfaces.StreamEvent
+	if len(l.attempts) > 0 {
+		events, l.attempts = l.attempts[0], l.attempts[1:]
+	}
+	ch := make(chan interfaces.StreamEvent, len(events))
+	for _, event := range events {
+		ch <- event
+	}
+	close(ch)
+	return ch
+}
+
+func (l *attemptsLLM) GenerateStream(ctx context.Context, prompt string, options ...interfaces.GenerateOption) (<-chan interfaces.StreamEvent, error) {
+	return l.next(), nil
+}
+
+func (l *attemptsLLM) GenerateWithToolsStream(ctx context.Context, prompt string, tools []interfaces.Tool, options ...interfaces.GenerateOption) (<-chan interfaces.StreamEvent, error) {
+	return l.next(), nil
+}
+
+func TestRetryDropsFailedAttemptThinking(t *testing.T) {
+	llm := &attemptsLLM{attempts: [][]interfaces.StreamEvent{
+		{
+			{Type: interfaces.StreamEventThinking, Content: "先查询订单状态"},
+			{Type: interfaces.StreamEventError, Error: errors.New("upstream 502")},
+		},
+		{
+			{Type: interfaces.StreamEventContentDelta, Content: "订单已发货"},
+		},
+	}}
+	msg := &wework.IncomingMessage{
+		BaseMessage: wework.BaseMessage{
+			MsgID:    "msg-retry",
+			ChatType: wework.ChatTypeSingle,
+			From:     wework.From{UserID: "zhangsan"},
+			MsgType:  wework.MsgTypeText,
+		},
+		Text: &wework.TextContent{Content: "我的订单到哪了"},
+	}
+	b := newScriptedHandler(t, msg.GetConversationKey(), llm)
+
+	resp, err := b.HandleMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamID := resp.Stream.ID
+
+	b.taskCache.mutex.RLock()
+	task := b.taskCache.tasks[streamID]
+	b.taskCache.mutex.RUnlock()
+	if task == nil {
+		t.Fatalf("任务 %s 未登记", streamID)
+	}
+
+	waitFor(t, "Agent完成", task.Buffer.IsAIFinished)
+	// 失败尝试的思考过程在重试前撤回，不与重试的输出拼在一起
+	if got := task.Buffer.Snapshot(); strings.Contains(got, "先查询订单状态") || strings.Contains(got, "<think>") {
+		t.Errorf("重试后缓冲区 = %q，不应包含失败尝试的思考过程", got)
+	}
+	if content, finish := refresh(t, b, streamID); content != "订单已发货" || !finish {
+		t.Errorf("重试完成后刷新 = %q, finish=%v，期望 %q, true", content, finish, "订单已发货")
+	}
+}
+
+func TestResumeExhaustedAppendsNotice(t *testing.T) {
+	failure := interfaces.StreamEvent{Type: interfaces.StreamEventError, Error: errors.New("connection reset")}
+	llm := &attemptsLLM{attempts: [][]interfaces.StreamEvent{
+		{{Type: interfaces.StreamEventContentDelta, Content: "第一步：打开设置"}, failure},
+		{failure}, // 续传同样失败
+	}}
+	msg := &wework.IncomingMessage{
+		BaseMessage: wework.BaseMessage{
+			MsgID:    "msg-resume",
+			ChatType: wework.ChatTypeSingle,
+			From:     wework.From{UserID: "zhangsan"},
+			MsgType:  wework.MsgTypeText,
+		},
+		Text: &wework.TextContent{Content: "怎么连VPN"},
+	}
+	b := newScriptedHandler(t, msg.GetConversationKey(), llm)
+	b.taskCache.streamConfig.MaxResumeAttempts = 1
+
+	resp, err := b.HandleMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamID := resp.Stream.ID
+
+	b.taskCache.mutex.RLock()
+	task := b.taskCache.tasks[streamID]
+	b.taskCache.mutex.RUnlock()
+	if task == nil {
+		t.Fatalf("任务 %s 未登记", streamID)
+	}
+
+	waitFor(t, "Agent完成", task.Buffer.IsAIFinished)
+	// 保留已输出内容，另起一段说明回复不完整
+	want := "第一步：打开设置\n\n" + i18n.T(task.Locale, i18n.ResumeFailed)
+	if content, finish := refresh(t, b, streamID); !strings.HasPrefix(content, want) || !finish {
+		t.Errorf("续传用尽后刷新 = %q, finish=%v，期望以 %q 开头并结束", content, finish, want)
+	}
+}
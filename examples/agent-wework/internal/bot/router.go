@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/router"
+)
+
+// newRouter 按配置创建多智能体路由（未启用时返回nil）
+func newRouter(cfg *config.Config) (*router.Router, error) {
+	if !cfg.Router.Enabled {
+		return nil, nil
+	}
+	llmName := cfg.Router.LLMProvider
+	if llmName == "" {
+		llmName = cfg.LLM.Default
+	}
+	client, err := llm.CreateLLMByName(cfg, llmName, applog.SDK())
+	if err != nil {
+		return nil, fmt.Errorf("创建路由模型失败: %w", err)
+	}
+	return router.New(client, time.Duration(cfg.Router.Timeout)*time.Second), nil
+}
+
+// routeTask 由主管模型选择处理本条消息的专家，之后的回复由该专家人设的Agent生成
+//
+// 用户通过 /persona 指定人设的会话不参与路由。
+func (tcm *TaskCacheManager) routeTask(ctx context.Context, task *TaskInfo) {
+	cam := tcm.convAgentManager
+	if cam.Persona(task.ConversationID) != "" {
+		return
+	}
+
+	cam.mutex.RLock()
+	cfg := cam.config
+	cam.mutex.RUnlock()
+	names := cfg.SpecialistNames()
+	specialists := make([]router.Specialist, 0, len(names))
+	for _, name := range names {
+		persona, ok := cfg.Personas[name]
+		if !ok {
+			continue
+		}
+		description := persona.Description
+		if description == "" {
+			description = persona.Title(name)
+		}
+		specialists = append(specialists, router.Specialist{Name: name, Description: description})
+	}
+
+	start := time.Now()
+	previous := cam.routedPersona(task.ConversationID)
+	current := cam.Features(task.ConversationID).Persona
+	specialist, err := tcm.router.Route(ctx, task.Question, specialists, current)
+	fallback := err != nil
+	if fallback {
+		slog.Warn("路由失败，使用回退专家", "stream_id", task.StreamID, "err", err)
+		specialist = routerFallback(cfg, names, current)
+	}
+
+	switch {
+	case previous == "":
+		slog.Info("路由", "conversation_id", task.ConversationID, "specialist", specialist)
+	case specialist != previous:
+		slog.Info("路由", "conversation_id", task.ConversationID, "previous", previous, "specialist", specialist)
+	}
+	cam.setRoutedPersona(task.ConversationID, specialist)
+	tcm.events.Publish(events.TaskRouted{
+		StreamID:       task.StreamID,
+		ConversationID: task.ConversationID,
+		Specialist:     specialist,
+		Previous:       previous,
+		Fallback:       fallback,
+		Duration:       time.Since(start),
+		Time:           time.Now(),
+	})
+}
+
+// routerFallback 路由失败时的专家：router.fallback → 当前专家 → default_persona → 第一个专家
+func routerFallback(cfg *config.Config, names []string, current string) string {
+	for _, candidate := range []string{cfg.Router.Fallback, current, cfg.DefaultPersona} {
+		if candidate != "" && slices.Contains(names, candidate) {
+			return candidate
+		}
+	}
+	return names[0]
+}
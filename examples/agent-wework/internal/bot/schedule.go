@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/notify"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/scheduler"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+// weekdayNames 星期的中文名称
+var weekdayNames = [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// scheduleJobs 将配置中的定时任务转换为调度任务（已暂停的任务不参与调度）
+func (b *BotHandler) scheduleJobs(cfg *config.Config) []scheduler.Job {
+	var jobs []scheduler.Job
+	for _, s := range cfg.Schedules {
+		if s.Disabled {
+			continue
+		}
+		cron, err := s.ParseCron()
+		if err != nil {
+			slog.Warn("定时任务配置无效，已跳过", "job", s.Name, "err", err)
+			continue
+		}
+		jobs = append(jobs, scheduler.Job{
+			Name:    s.Name,
+			Cron:    cron,
+			Timeout: time.Duration(s.Timeout) * time.Second,
+			Run:     func(ctx context.Context) error { return b.runSchedule(ctx, s) },
+		})
+	}
+	return jobs
+}
+
+// runSchedule 执行定时任务：以任务人设创建一次性Agent（可调用工具），将结果主动推送到目标会话
+func (b *BotHandler) runSchedule(ctx context.Context, s config.ScheduleConfig) error {
+	cam := b.convAgentManager
+	features := s.Persona.Apply(cam.Features(s.Target))
+	features.Handoff = false // 定时任务没有等待回复的用户，不提供转人工
+
+	// 每次执行使用独立的记忆，不影响目标会话的对话上下文
+	agentInstance, _, err := cam.createAgent(s.Target, features, nil)
+	if err != nil {
+		return fmt.Errorf("创建Agent失败: %w", err)
+	}
+
+	now := time.Now().In(s.Location())
+	prompt := fmt.Sprintf("[定时任务 %s，当前时间 %s %s]\n%s",
+		s.Name, now.Format("2006-01-02 15:04"), weekdayNames[now.Weekday()], s.Prompt)
+
+	ctx = multitenancy.WithOrgID(ctx, b.config.Org())
+	ctx = context.WithValue(ctx, memory.ConversationIDKey, "schedule_"+s.Name)
+	answer, err := agentInstance.Run(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("Agent执行失败: %w", err)
+	}
+
+	answer = strings.TrimSpace(stream.StripThinkTags(answer))
+	if answer == "" {
+		return fmt.Errorf("Agent未返回内容")
+	}
+	return b.Notify(notify.Notification{
+		Target:   s.Target,
+		Category: s.Category,
+		Title:    s.Name,
+		Content:  answer,
+		Time:     now,
+	})
+}
+
+// Schedules 列出定时任务状态
+func (b *BotHandler) Schedules() []scheduler.Status {
+	return b.scheduler.Status()
+}
+
+// RunScheduleNow 立即执行定时任务（不影响下次触发时间）
+func (b *BotHandler) RunScheduleNow(name string) error {
+	return b.scheduler.RunNow(name)
+}
@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"log/slog"
+	"time"
+)
+
+// expireIdle 会话空闲超过 session.idle_reset 时移除会话Agent及其记忆（随后按新会话创建），返回是否已清空
+func (cam *ConversationAgentManager) expireIdle(conversationID string) bool {
+	cam.mutex.Lock()
+	defer cam.mutex.Unlock()
+
+	window := time.Duration(cam.config.Session.IdleReset) * time.Minute
+	if window <= 0 {
+		return false
+	}
+	convAgent, exists := cam.agents[conversationID]
+	if !exists {
+		return false
+	}
+	convAgent.mutex.RLock()
+	idle := time.Since(convAgent.lastActivity)
+	convAgent.mutex.RUnlock()
+	if idle < window {
+		return false
+	}
+
+	delete(cam.agents, conversationID)
+	slog.Info("会话空闲超时，已清空会话记忆", "conversation_id", conversationID, "idle", idle.Round(time.Second))
+	return true
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/cluster"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+// sharedTimeout 单次共享状态读写超时（刷新回调需尽快响应）
+const sharedTimeout = time.Second
+
+// SetStreamStore 启用多副本共享状态，需在开始处理消息前调用
+func (b *BotHandler) SetStreamStore(store cluster.Store) {
+	b.taskCache.shared = store
+	b.taskCache.clusterConfig = b.config.Cluster
+	b.taskCache.org = b.config.Org()
+	b.taskCache.publishRecovered()
+}
+
+// sharedKey 共享状态的键（按组织隔离，不同组织的机器人无法读取彼此的回复）
+func (tcm *TaskCacheManager) sharedKey(streamID string) string {
+	return tcm.org + ":" + streamID
+}
+
+// publishShared 定期发布本副本处理中的任务状态，直到回复结束
+func (tcm *TaskCacheManager) publishShared(task *TaskInfo) {
+	ticker := time.NewTicker(time.Duration(tcm.clusterConfig.SyncInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	// 任务异常未能结束时，超过共享状态保留时间后停止发布
+	deadline := time.Now().Add(time.Duration(tcm.clusterConfig.TTL) * time.Second)
+
+	for {
+		// 先判断是否结束再读取内容，保证最后一次发布的是完整回复
+		task.mutex.RLock()
+		finished := !task.IsProcessing && task.Buffer.IsAIFinished()
+		task.mutex.RUnlock()
+
+		answer := task.Buffer.Peek()
+		if task.HideThinking {
+			answer = stream.StripThinkTags(answer)
+		}
+		answer, finished = tcm.clampAnswer(task.StreamID, answer, finished)
+
+		state := cluster.StreamState{
+			Answer:   answer,
+			Finished: finished,
+			Owner:    tcm.clusterConfig.InstanceID,
+			Updated:  time.Now(),
+		}
+		if finished {
+			// 生成的图片只随最后一次发布，避免反复写入大内容
+			task.mutex.RLock()
+			state.Images = task.Images
+			task.mutex.RUnlock()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+		err := tcm.shared.Put(ctx, tcm.sharedKey(task.StreamID), state)
+		cancel()
+		if err != nil {
+			slog.Warn("发布任务共享状态失败", "stream_id", task.StreamID, "err", err)
+		}
+
+		if finished || time.Now().After(deadline) {
+			return
+		}
+		<-ticker.C
+	}
+}
+
+// sharedAnswer 本副本没有该任务时从共享状态读取，ok为false表示应按本地逻辑处理
+func (tcm *TaskCacheManager) sharedAnswer(streamID string) (answer string, finished bool, images [][]byte, ok bool) {
+	if tcm.shared == nil {
+		return "", false, nil, false
+	}
+
+	tcm.mutex.RLock()
+	_, local := tcm.tasks[streamID]
+	tcm.mutex.RUnlock()
+	if local {
+		return "", false, nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+	defer cancel()
+
+	state, found, err := tcm.shared.Get(ctx, tcm.sharedKey(streamID))
+	if err != nil {
+		slog.Warn("读取任务共享状态失败", "stream_id", streamID, "err", err)
+		return "", false, nil, false
+	}
+	if !found {
+		return "", false, nil, false
+	}
+	return state.Answer, state.Finished, state.Images, true
+}
@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/profile"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/summary"
+	"github.com/deepsage-ai/b0dy/pkg/stream"
+)
+
+const (
+	summaryMaxTurns   = 20               // 每个会话保留用于摘要的最近问答轮数
+	summaryTimeout    = 30 * time.Second // 生成摘要超时
+	summaryCheckEvery = time.Minute      // 检查空闲会话的间隔
+	summaryLogUser    = "SUMMARY"        // 摘要在聊天记录中的发送者
+)
+
+// sessionSummaries 记录各会话的问答，会话空闲后生成摘要写入聊天记录（可选保存到用户画像）
+type sessionSummaries struct {
+	summarizer *summary.Summarizer
+	idle       time.Duration
+	logger     *ChatLogger    // 聊天记录（未启用时为nil）
+	profiles   *profile.Store // 保存单聊摘要的用户画像（未启用remember时为nil）
+	sessions   map[string]*sessionTurns
+	mutex      sync.Mutex
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// sessionTurns 会话自上次摘要以来的问答
+type sessionTurns struct {
+	turns []summary.Turn
+	last  time.Time
+}
+
+// newSessionSummaries 创建会话摘要并订阅回复结束事件（未启用时返回nil）
+func newSessionSummaries(cfg *config.Config, bus *events.Bus, logger *ChatLogger, profiles *profile.Store) (*sessionSummaries, error) {
+	if !cfg.Summary.Enabled {
+		return nil, nil
+	}
+	llmName := cfg.Summary.LLMProvider
+	if llmName == "" {
+		llmName = cfg.LLM.Default
+	}
+	client, err := llm.CreateLLMByName(cfg, llmName, applog.SDK())
+	if err != nil {
+		return nil, fmt.Errorf("创建会话摘要模型失败: %w", err)
+	}
+
+	s := &sessionSummaries{
+		summarizer: summary.NewSummarizer(client, summaryTimeout),
+		idle:       time.Duration(cfg.Summary.IdleAfter) * time.Minute,
+		logger:     logger,
+		sessions:   make(map[string]*sessionTurns),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if cfg.Summary.Remember {
+		s.profiles = profiles
+	}
+
+	events.Subscribe(bus, func(e events.TurnFinished) {
+		if e.Err == nil {
+			s.record(e.ConversationID, e.Question, e.Answer, e.Time)
+		}
+	})
+
+	go s.run()
+	return s, nil
+}
+
+// record 记录一轮问答（只保留最近的若干轮）
+func (s *sessionSummaries) record(conversationID, question, answer string, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[conversationID]
+	if !ok {
+		session = &sessionTurns{}
+		s.sessions[conversationID] = session
+	}
+	session.turns = append(session.turns, summary.Turn{
+		Question: stripUserPrefix(question),
+		Answer:   stream.StripThinkTags(answer),
+		Time:     at,
+	})
+	if len(session.turns) > summaryMaxTurns {
+		session.turns = session.turns[len(session.turns)-summaryMaxTurns:]
+	}
+	session.last = at
+}
+
+// run 定期为空闲的会话生成摘要（逐个生成，避免占用过多模型并发）
+func (s *sessionSummaries) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(summaryCheckEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for conversationID, session := range s.takeIdle() {
+				s.summarize(conversationID, session)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// takeIdle 取出已空闲的会话（之后的新消息开始新的一段会话）
+func (s *sessionSummaries) takeIdle() map[string]*sessionTurns {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idle := make(map[string]*sessionTurns)
+	for conversationID, session := range s.sessions {
+		if time.Since(session.last) >= s.idle {
+			idle[conversationID] = session
+			delete(s.sessions, conversationID)
+		}
+	}
+	return idle
+}
+
+// summarize 生成会话摘要，写入聊天记录，单聊摘要按配置保存到用户画像
+func (s *sessionSummaries) summarize(conversationID string, session *sessionTurns) {
+	text, err := s.summarizer.Summarize(context.Background(), session.turns)
+	if err != nil {
+		slog.Warn("生成会话摘要失败", "conversation_id", conversationID, "err", err)
+		return
+	}
+	if text == "" {
+		return
+	}
+	slog.Info("会话摘要", "conversation_id", conversationID, "turns", len(session.turns))
+
+	if s.logger != nil {
+		s.logger.LogMessage(conversationID, "", summaryLogUser, strings.ReplaceAll(text, "\n", " | "))
+	}
+	if s.profiles == nil {
+		return
+	}
+	userID, ok := strings.CutPrefix(conversationID, "single_")
+	if !ok {
+		return
+	}
+	s.profiles.SetSession(userID, text, session.last)
+	if err := s.profiles.Save(); err != nil {
+		slog.Warn("保存会话摘要到用户画像失败", "conversation_id", conversationID, "err", err)
+	}
+}
+
+// Close 停止检查空闲会话（尚未空闲的会话不再生成摘要）
+func (s *sessionSummaries) Close() {
+	close(s.stop)
+	<-s.done
+}
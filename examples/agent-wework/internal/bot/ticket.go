@@ -0,0 +1,28 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/ticket"
+)
+
+// newTicketTools 创建工单工具（未启用时返回nil），提交的工单附带发起对话的用户
+func newTicketTools(cfg *config.Config) ([]interfaces.Tool, error) {
+	if !cfg.Ticket.Enabled {
+		return nil, nil
+	}
+	provider, err := ticket.NewProviderFromConfig(cfg.Ticket)
+	if err != nil {
+		return nil, err
+	}
+	requester := func(ctx context.Context) string {
+		userID, _ := ctx.Value(requesterKey{}).(string)
+		return userID
+	}
+	return []interfaces.Tool{
+		ticket.NewCreateTool(provider, requester),
+		ticket.NewGetTool(provider),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// toolFilterServer 按工具白名单过滤MCP服务器暴露的工具
+//
+// 包装共享的MCP服务器，不影响其他会话；Close由原服务器的所有者负责。
+type toolFilterServer struct {
+	interfaces.MCPServer
+	allows func(name string) bool
+}
+
+// newToolFilterServer 创建工具过滤包装
+func newToolFilterServer(server interfaces.MCPServer, allows func(name string) bool) *toolFilterServer {
+	return &toolFilterServer{MCPServer: server, allows: allows}
+}
+
+// ListTools 只返回白名单内的工具
+func (s *toolFilterServer) ListTools(ctx context.Context) ([]interfaces.MCPTool, error) {
+	tools, err := s.MCPServer.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := tools[:0:0]
+	for _, tool := range tools {
+		if s.allows(tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed, nil
+}
+
+// CallTool 拒绝调用白名单外的工具
+func (s *toolFilterServer) CallTool(ctx context.Context, name string, args interface{}) (*interfaces.MCPToolResponse, error) {
+	if !s.allows(name) {
+		return nil, fmt.Errorf("当前会话不允许使用工具: %s", name)
+	}
+	return s.MCPServer.CallTool(ctx, name, args)
+}
+
+// Close 不关闭共享的底层服务器
+func (s *toolFilterServer) Close() error {
+	return nil
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/notify"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/tts"
+)
+
+// voiceSendTimeout 上传并发送语音消息的超时
+const voiceSendTimeout = 30 * time.Second
+
+// voiceReply 语音回复：回答完成后合成语音，通过群机器人发送语音消息
+// （智能机器人的回复不支持语音消息）
+type voiceReply struct {
+	tts       *tts.Service
+	sender    *notify.WebhookSender
+	defaultOn bool
+}
+
+// newVoiceReply 创建语音回复（未启用时返回nil）
+func newVoiceReply(cfg *config.Config) (*voiceReply, error) {
+	service, err := tts.NewServiceFromConfig(cfg.TTS)
+	if err != nil || service == nil {
+		return nil, err
+	}
+	return &voiceReply{
+		tts:       service,
+		sender:    notify.NewWebhookSender(cfg.TTS.WebhookURL),
+		defaultOn: cfg.TTS.Default,
+	}, nil
+}
+
+// wantsVoice 会话是否需要语音回复：仅单聊，按用户的 /settings voice 设置（未启用个人设置时按tts.default）
+func (tcm *TaskCacheManager) wantsVoice(conversationID string) bool {
+	if !strings.HasPrefix(conversationID, "single_") {
+		return false
+	}
+	if p, ok := tcm.convAgentManager.userPreferences(conversationID); ok {
+		return p.VoiceEnabled(tcm.voice.defaultOn)
+	}
+	return tcm.voice.defaultOn
+}
+
+// sendVoice 将最终回答转为语音，先@用户说明再发送语音消息（失败只记录日志，不影响文字回复）
+func (tcm *TaskCacheManager) sendVoice(task *TaskInfo) {
+	audio, err := tcm.voice.tts.Speak(context.Background(), task.Buffer.Snapshot())
+	if err != nil {
+		slog.Warn("语音合成失败", "stream_id", task.StreamID, "err", err)
+		return
+	}
+	if audio == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), voiceSendTimeout)
+	defer cancel()
+	err = tcm.voice.sender.Send(ctx, task.ConversationID, "🔊 语音版回答：")
+	if err == nil {
+		err = tcm.voice.sender.SendVoice(ctx, audio)
+	}
+	if err != nil {
+		slog.Warn("语音消息发送失败", "stream_id", task.StreamID, "err", err)
+		return
+	}
+	slog.Info("已发送语音回复", "bytes", len(audio), "stream_id", task.StreamID)
+}
@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/logging"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/mcp"
+)
+
+// warmPool 预热池：按LLM提供商预先创建客户端，创建会话Agent时直接取用，取走后在后台补充
+//
+// 会话Agent的系统提示词、工具和记忆都与会话绑定，只有LLM客户端和MCP工具列表可以提前准备。
+type warmPool struct {
+	size     int
+	toolsTTL time.Duration
+	config   *config.Config
+	clients  map[string]chan interfaces.LLM // LLM提供商 -> 预先创建的客户端
+	mutex    sync.Mutex
+}
+
+// newWarmPool 创建预热池并在后台为默认LLM提供商预先创建客户端（未启用时返回nil）
+func newWarmPool(cfg *config.Config) *warmPool {
+	if !cfg.WarmPool.Enabled {
+		return nil
+	}
+	p := &warmPool{
+		size:     cfg.WarmPool.Size,
+		toolsTTL: time.Duration(cfg.WarmPool.ToolsTTL) * time.Second,
+		config:   cfg,
+		clients:  make(map[string]chan interfaces.LLM),
+	}
+	go p.fill(cfg.LLM.Default, p.slots(cfg.LLM.Default))
+	return p
+}
+
+// slots 获取提供商的客户端队列（不存在时创建）
+func (p *warmPool) slots(provider string) chan interfaces.LLM {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ch, ok := p.clients[provider]
+	if !ok {
+		ch = make(chan interfaces.LLM, p.size)
+		p.clients[provider] = ch
+	}
+	return ch
+}
+
+// claim 取用预先创建的客户端（池中没有时直接创建），并在后台补充
+func (p *warmPool) claim(provider string) (interfaces.LLM, error) {
+	ch := p.slots(provider)
+	defer func() { go p.fill(provider, ch) }()
+
+	select {
+	case client := <-ch:
+		return client, nil
+	default:
+		return p.create(provider)
+	}
+}
+
+// fill 补充客户端直到队列满（配置重新加载后旧队列不再补充）
+func (p *warmPool) fill(provider string, ch chan interfaces.LLM) {
+	for len(ch) < cap(ch) {
+		p.mutex.Lock()
+		current := p.clients[provider] == ch
+		p.mutex.Unlock()
+		if !current {
+			return
+		}
+
+		client, err := p.create(provider)
+		if err != nil {
+			slog.Warn("预热LLM客户端失败", "provider", provider, "err", err)
+			return
+		}
+		select {
+		case ch <- client:
+		default:
+			return
+		}
+	}
+}
+
+// create 按当前配置创建客户端
+func (p *warmPool) create(provider string) (interfaces.LLM, error) {
+	p.mutex.Lock()
+	cfg := p.config
+	p.mutex.Unlock()
+	return llm.CreateLLMByName(cfg, provider, applog.SDK())
+}
+
+// reload 应用新配置：LLM提供商变更时丢弃已预先创建的客户端
+func (p *warmPool) reload(cfg *config.Config) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	changed := p.config.LLM.Default != cfg.LLM.Default || !reflect.DeepEqual(p.config.LLM.Providers, cfg.LLM.Providers)
+	p.config = cfg
+	if changed {
+		p.clients = make(map[string]chan interfaces.LLM)
+		ch := make(chan interfaces.LLM, p.size)
+		p.clients[cfg.LLM.Default] = ch
+		go p.fill(cfg.LLM.Default, ch)
+	}
+}
+
+// wrapServers 为MCP服务器缓存工具列表（预热池未启用时原样返回）
+func (p *warmPool) wrapServers(servers []mcp.NamedServer) []mcp.NamedServer {
+	if p == nil {
+		return servers
+	}
+	return mcp.WithCachedTools(servers, p.toolsTTL)
+}
+
+// newLLM 创建会话Agent使用的LLM客户端（启用预热池时从池中取用）
+func (cam *ConversationAgentManager) newLLM(features config.Features, logger logging.Logger) (interfaces.LLM, error) {
+	if cam.warm != nil {
+		return cam.warm.claim(cam.providerName(features))
+	}
+	if features.LLMProvider != "" {
+		return llm.CreateLLMByName(cam.config, features.LLMProvider, logger)
+	}
+	return llm.CreateLLMFromConfig(cam.config, logger)
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+)
+
+// maxCardItems 模板卡片二级标题+文本的数量上限（企业微信限制）
+const maxCardItems = 6
+
+// welcomeCard 新会话首次对话时返回欢迎卡片（未启用或已欢迎过时返回nil）
+func (b *BotHandler) welcomeCard(conversationID, userID string) *wework.WeWorkTemplateCard {
+	if b.welcome == nil {
+		return nil
+	}
+	first, err := b.welcome.First(conversationID)
+	if err != nil {
+		slog.Warn("保存欢迎记录失败（本次不发送欢迎卡片）", "err", err)
+		return nil
+	}
+	if !first {
+		return nil
+	}
+	slog.Info("首次对话，附带欢迎卡片", "conversation_id", conversationID)
+	locale := b.userLocale(userID)
+	return newWelcomeCard(b.config.Welcome, b.text(locale, i18n.WelcomeAbilities), b.text(locale, i18n.WelcomeExamples), b.availableCommands(userID, locale))
+}
+
+// Command 用户可用的斜杠命令
+type Command struct {
+	Name        string // 命令（含 / 前缀）
+	Description string // 说明
+}
+
+// Commands 按已启用的功能列出斜杠命令（不含仅管理员可用的命令），外部渠道可用于注册命令菜单
+//
+// 命令说明使用配置的locale。
+func (b *BotHandler) Commands() []Command {
+	return b.commands(b.config.Locale)
+}
+
+// commands 按已启用的功能列出斜杠命令，命令说明使用指定语言
+func (b *BotHandler) commands(locale string) []Command {
+	var commands []Command
+	if len(b.config.Personas) > 0 {
+		commands = append(commands, Command{Name: "/persona", Description: b.text(locale, i18n.CommandPersona)})
+	}
+	if b.config.Preferences.Enabled {
+		commands = append(commands, Command{Name: "/settings", Description: b.text(locale, i18n.CommandSettings)})
+	}
+	if b.config.Jobs.Enabled {
+		commands = append(commands, Command{Name: "/status", Description: b.text(locale, i18n.CommandStatus)})
+	}
+	if b.config.Outbound.Enabled {
+		commands = append(commands, Command{Name: "/feedback", Description: b.text(locale, i18n.CommandFeedback)})
+	}
+	return commands
+}
+
+// availableCommands 按已启用的功能列出用户可用的命令
+func (b *BotHandler) availableCommands(userID, locale string) []wework.CardHorizontalItem {
+	var commands []wework.CardHorizontalItem
+	for _, c := range b.commands(locale) {
+		commands = append(commands, wework.CardHorizontalItem{KeyName: c.Name, Value: c.Description})
+	}
+	if b.config.Handoff.Enabled && len(b.config.Handoff.Keywords) > 0 {
+		commands = append(commands, wework.CardHorizontalItem{KeyName: b.config.Handoff.Keywords[0], Value: b.text(locale, i18n.CommandHandoff)})
+	}
+	if b.config.Knowledge.Enabled && b.isKnowledgeAdmin(userID) {
+		commands = append(commands, wework.CardHorizontalItem{KeyName: "/kb", Value: b.text(locale, i18n.CommandKnowledge)})
+	}
+	return commands
+}
+
+// newWelcomeCard 构建欢迎卡片：标题和简介、能力介绍与示例问题（abilities、examples为两部分的标题）、可用命令
+func newWelcomeCard(w config.WelcomeConfig, abilities, examples string, commands []wework.CardHorizontalItem) *wework.WeWorkTemplateCard {
+	var sections []string
+	if len(w.Capabilities) > 0 {
+		sections = append(sections, abilities+"\n· "+strings.Join(w.Capabilities, "\n· "))
+	}
+	if len(w.Examples) > 0 {
+		sections = append(sections, examples+"\n· "+strings.Join(w.Examples, "\n· "))
+	}
+	if len(commands) > maxCardItems {
+		commands = commands[:maxCardItems]
+	}
+
+	card := &wework.WeWorkTemplateCard{
+		CardType:              wework.CardTypeTextNotice,
+		MainTitle:             &wework.CardMainTitle{Title: w.Title, Desc: w.Description},
+		SubTitleText:          strings.Join(sections, "\n\n"),
+		HorizontalContentList: commands,
+		CardAction:            &wework.CardAction{Type: 0},
+	}
+	if w.URL != "" {
+		card.CardAction = &wework.CardAction{Type: 1, URL: w.URL}
+	}
+	return card
+}
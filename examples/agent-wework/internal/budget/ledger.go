@@ -0,0 +1,138 @@
+package budget
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// DayLayout 用量按天统计的日期格式
+const DayLayout = "2006-01-02"
+
+// Usage 会话当天的用量
+type Usage struct {
+	ConversationID string    `json:"conversation_id"`
+	Tokens         int       `json:"tokens"` // 估算的token数（问题+回复）
+	Cost           float64   `json:"cost"`   // 按提供商单价估算的费用
+	Turns          int       `json:"turns"`
+	Updated        time.Time `json:"updated"`
+}
+
+// state 持久化内容
+type state struct {
+	Day   string            `json:"day"`
+	Usage map[string]*Usage `json:"usage"`
+}
+
+// Ledger 每日用量账本（JSON文件持久化，跨天自动清零）
+type Ledger struct {
+	path  string
+	day   string
+	usage map[string]*Usage // conversationID -> 当天用量
+	mutex sync.Mutex
+}
+
+// NewLedger 创建账本并加载当天已有用量
+func NewLedger(path string) (*Ledger, error) {
+	l := &Ledger{
+		path:  path,
+		day:   time.Now().Format(DayLayout),
+		usage: make(map[string]*Usage),
+	}
+
+	var s state
+	if _, err := fsutil.ReadJSON(path, &s); err != nil {
+		return nil, err
+	}
+	if s.Day == l.day && s.Usage != nil {
+		l.usage = s.Usage
+	}
+	return l, nil
+}
+
+// rolloverLocked 跨天时清空用量（调用方需持有mutex）
+func (l *Ledger) rolloverLocked(now time.Time) {
+	if day := now.Format(DayLayout); day != l.day {
+		l.day = day
+		l.usage = make(map[string]*Usage)
+	}
+}
+
+// Add 累计一次回复的用量并保存
+func (l *Ledger) Add(conversationID string, tokens int, cost float64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.rolloverLocked(now)
+	u, ok := l.usage[conversationID]
+	if !ok {
+		u = &Usage{ConversationID: conversationID}
+		l.usage[conversationID] = u
+	}
+	u.Tokens += tokens
+	u.Cost += cost
+	u.Turns++
+	u.Updated = now
+	return l.saveLocked()
+}
+
+// Get 获取会话当天的用量（没有用量时返回零值）
+func (l *Ledger) Get(conversationID string) Usage {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.rolloverLocked(time.Now())
+	if u, ok := l.usage[conversationID]; ok {
+		return *u
+	}
+	return Usage{ConversationID: conversationID}
+}
+
+// List 列出当天有用量的会话（按token数倒序）
+func (l *Ledger) List() (string, []Usage) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.rolloverLocked(time.Now())
+	list := make([]Usage, 0, len(l.usage))
+	for _, u := range l.usage {
+		list = append(list, *u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Tokens > list[j].Tokens })
+	return l.day, list
+}
+
+// Reset 清零会话当天的用量，会话没有用量时返回false
+func (l *Ledger) Reset(conversationID string) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.rolloverLocked(time.Now())
+	if _, ok := l.usage[conversationID]; !ok {
+		return false, nil
+	}
+	delete(l.usage, conversationID)
+	return true, l.saveLocked()
+}
+
+// saveLocked 保存到文件（调用方需持有mutex）
+func (l *Ledger) saveLocked() error {
+	return fsutil.WriteJSONAtomic(l.path, state{Day: l.day, Usage: l.usage})
+}
+
+// EstimateTokens 粗略估算文本的token数：中日韩字符按1个，其他字符按4个折合1个
+func EstimateTokens(text string) int {
+	var cjk, other int
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
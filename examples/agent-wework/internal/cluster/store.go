@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/redisutil"
+)
+
+// StreamState 跨副本共享的流式任务状态（由处理任务的副本定期发布）
+type StreamState struct {
+	Answer   string    `json:"answer"`   // 当前应展示的完整回复
+	Finished bool      `json:"finished"` // 回复是否已结束
+	Owner    string    `json:"owner"`    // 处理任务的副本标识
+	Updated  time.Time `json:"updated"`
+	Images   [][]byte  `json:"images,omitempty"` // 回复结束时附带的生成图片（仅在结束时写入）
+}
+
+// Store 流式任务共享状态存储
+type Store interface {
+	Put(ctx context.Context, streamID string, state StreamState) error
+	Get(ctx context.Context, streamID string) (StreamState, bool, error)
+}
+
+// RedisStore 基于Redis的共享状态存储
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore 连接Redis并创建共享状态存储
+func NewRedisStore(cfg config.ClusterConfig) (*RedisStore, error) {
+	client, err := redisutil.Connect(cfg.RedisURL, cfg.RedisPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisStore{
+		client: client,
+		prefix: cfg.KeyPrefix,
+		ttl:    time.Duration(cfg.TTL) * time.Second,
+	}, nil
+}
+
+// Put 发布任务状态
+func (s *RedisStore) Put(ctx context.Context, streamID string, state StreamState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.prefix+streamID, data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("写入共享状态失败: %w", err)
+	}
+	return nil
+}
+
+// Get 读取任务状态，不存在时返回false
+func (s *RedisStore) Get(ctx context.Context, streamID string) (StreamState, bool, error) {
+	var state StreamState
+	data, err := s.client.Get(ctx, s.prefix+streamID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, fmt.Errorf("读取共享状态失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false, fmt.Errorf("解析共享状态失败: %w", err)
+	}
+	return state, true, nil
+}
+
+// Close 关闭Redis连接
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// New 按配置创建共享状态存储，未启用时返回nil
+func New(cfg config.ClusterConfig) (Store, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	store, err := NewRedisStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// DefaultWebhookPath 单机器人模式的默认Webhook路由（位于server.prefix下）
+const DefaultWebhookPath = "/webhook"
+
+// BotConfigs 返回需要启动的机器人列表，未配置bots时由顶层wework配置构成单个机器人
+func (c *Config) BotConfigs() []BotConfig {
+	if len(c.Bots) == 0 {
+		return []BotConfig{{
+			Name:   "default",
+			Path:   c.Server.WebhookPath,
+			WeWork: c.WeWork,
+		}}
+	}
+
+	bots := make([]BotConfig, len(c.Bots))
+	for i, b := range c.Bots {
+		if b.Path == "" {
+			b.Path = c.Server.Route("/" + b.Name + "/webhook")
+		}
+		bots[i] = b
+	}
+	return bots
+}
+
+// ChannelBot 外部渠道（微信客服、Slack等）接待消息的机器人名称：name为空时为第一个机器人，不存在时返回空字符串
+func (c *Config) ChannelBot(name string) string {
+	for _, b := range c.BotConfigs() {
+		if name == "" || b.Name == name {
+			return b.Name
+		}
+	}
+	return ""
+}
+
+// ForBot 生成单个机器人的完整配置（共享全局LLM提供商、MCP服务器、群聊等配置）
+func (c *Config) ForBot(b BotConfig) *Config {
+	derived := *c
+	derived.Bots = nil
+	derived.WeWork = b.WeWork
+
+	if b.LLMProvider != "" {
+		derived.LLM.Default = b.LLMProvider
+	}
+	if b.SystemPrompt != "" {
+		derived.LLM.SystemPrompt = b.SystemPrompt
+	}
+
+	if b.Persona != "" {
+		derived.DefaultPersona = b.Persona
+	}
+	if b.OrgID != "" {
+		derived.OrgID = b.OrgID
+	}
+	if b.Locale != "" {
+		derived.Locale = b.Locale
+	}
+	if len(b.Messages) > 0 {
+		derived.Messages = make(map[string]string, len(c.Messages)+len(b.Messages))
+		for key, text := range c.Messages {
+			derived.Messages[key] = text
+		}
+		for key, text := range b.Messages {
+			derived.Messages[key] = text
+		}
+	}
+	if b.Moderation != nil {
+		derived.Moderation = *b.Moderation
+	}
+	if b.BusinessHours != nil {
+		derived.BusinessHours = *b.BusinessHours
+	}
+	if b.Welcome != nil {
+		derived.Welcome = *b.Welcome
+	}
+
+	if len(b.MCPServers) > 0 {
+		allowed := make(map[string]bool, len(b.MCPServers))
+		for _, name := range b.MCPServers {
+			allowed[name] = true
+		}
+		derived.MCP.Servers = nil
+		for _, server := range c.MCP.Servers {
+			if allowed[server.Name] {
+				derived.MCP.Servers = append(derived.MCP.Servers, server)
+			}
+		}
+	}
+	if len(b.MCPDisabled) > 0 {
+		servers := make([]MCPServerConfig, len(derived.MCP.Servers))
+		copy(servers, derived.MCP.Servers)
+		for i := range servers {
+			for _, name := range b.MCPDisabled {
+				if servers[i].Name == name {
+					servers[i].Enabled = false
+				}
+			}
+		}
+		derived.MCP.Servers = servers
+	}
+
+	// 定时任务只由指定的机器人执行，避免重复推送
+	derived.Schedules = nil
+	for _, schedule := range c.Schedules {
+		if schedule.Bot == b.Name || (schedule.Bot == "" && b.Name == c.BotConfigs()[0].Name) {
+			derived.Schedules = append(derived.Schedules, schedule)
+		}
+	}
+
+	// 多租户模式下所有按机器人区分的数据先按组织分开，同一组织的机器人共享用户画像和知识库
+	org := derived.Org()
+	tenant := c.MultiTenant()
+	botDir := func(dir string) string {
+		if tenant {
+			dir = filepath.Join(dir, org)
+		}
+		return filepath.Join(dir, b.Name)
+	}
+	botPath := func(path string) string {
+		if tenant {
+			path = scopedPath(path, org)
+		}
+		return scopedPath(path, b.Name)
+	}
+
+	// 多机器人时按机器人分目录记录聊天日志，避免同一用户的会话互相覆盖
+	if len(c.Bots) > 0 && c.Logging.LogDir != "" {
+		derived.Logging.LogDir = botDir(c.Logging.LogDir)
+	}
+	// 预写记录按机器人分目录，重启后各机器人只恢复自己的回复
+	if len(c.Bots) > 0 && c.Stream.Journal != "" {
+		derived.Stream.Journal = botDir(c.Stream.Journal)
+	}
+	// 转人工状态按机器人分文件保存，同一用户在不同机器人的会话互不影响
+	if len(c.Bots) > 0 && c.Handoff.Path != "" {
+		derived.Handoff.Path = botPath(c.Handoff.Path)
+	}
+	// 欢迎记录按机器人分文件保存，用户首次使用每个机器人时都会收到欢迎
+	if len(c.Bots) > 0 && derived.Welcome.Path != "" {
+		derived.Welcome.Path = botPath(derived.Welcome.Path)
+	}
+	// 长任务记录按机器人分文件保存
+	if len(c.Bots) > 0 && c.Jobs.Path != "" {
+		derived.Jobs.Path = botPath(c.Jobs.Path)
+	}
+	// 用户偏好按机器人分文件保存
+	if len(c.Bots) > 0 && c.Preferences.Path != "" {
+		derived.Preferences.Path = botPath(c.Preferences.Path)
+	}
+	// 会话统计按机器人分库
+	if len(c.Bots) > 0 && c.Analytics.Path != "" {
+		derived.Analytics.Path = botPath(c.Analytics.Path)
+	}
+	// 用量记录按机器人分文件保存，每个机器人独立计算预算
+	if len(c.Bots) > 0 && c.Budget.Path != "" {
+		derived.Budget.Path = botPath(c.Budget.Path)
+	}
+	// 用户画像和知识库按组织分文件（包括全局组织的机器人），不同组织互不共享
+	if tenant {
+		if c.Profile.Path != "" {
+			derived.Profile.Path = scopedPath(c.Profile.Path, org)
+		}
+		if c.Knowledge.Path != "" {
+			derived.Knowledge.Path = scopedPath(c.Knowledge.Path, org)
+		}
+	}
+
+	return &derived
+}
+
+// validateBots 验证多机器人配置
+func validateBots(config *Config, mcpNames map[string]bool) error {
+	names := make(map[string]bool)
+	paths := make(map[string]string)
+
+	for i, b := range config.BotConfigs() {
+		if b.Name == "" {
+			return fmt.Errorf("第%d个机器人缺少name", i+1)
+		}
+		if names[b.Name] {
+			return fmt.Errorf("机器人名称重复: %s", b.Name)
+		}
+		names[b.Name] = true
+
+		if other, ok := paths[b.Path]; ok {
+			return fmt.Errorf("机器人 '%s' 与 '%s' 的Webhook路由重复: %s", b.Name, other, b.Path)
+		}
+		paths[b.Path] = b.Name
+
+		if err := validateWeWork(b.WeWork); err != nil {
+			return fmt.Errorf("机器人 '%s': %w", b.Name, err)
+		}
+		if b.LLMProvider != "" {
+			if _, ok := config.LLM.Providers[b.LLMProvider]; !ok {
+				return fmt.Errorf("机器人 '%s' 引用的LLM提供商 '%s' 在配置中不存在", b.Name, b.LLMProvider)
+			}
+		}
+		if b.Persona != "" {
+			if _, ok := config.Personas[b.Persona]; !ok {
+				return fmt.Errorf("机器人 '%s' 引用的人设 '%s' 在personas中不存在", b.Name, b.Persona)
+			}
+		}
+		for _, names := range [][]string{b.MCPServers, b.MCPDisabled} {
+			for _, name := range names {
+				if !mcpNames[name] {
+					return fmt.Errorf("机器人 '%s' 引用的MCP服务器 '%s' 在配置中不存在", b.Name, name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// tenantConfig 两个机器人属于组织globex、一个机器人属于全局（默认）组织
+func tenantConfig() *Config {
+	cfg := &Config{}
+	cfg.Logging.LogDir = "logs"
+	cfg.Stream.Journal = "data/journal"
+	cfg.Handoff.Path = "data/handoff.json"
+	cfg.Jobs.Path = "data/jobs.json"
+	cfg.Budget.Path = "data/budget.json"
+	cfg.Analytics.Path = "data/analytics.db"
+	cfg.Profile.Path = "data/profiles.json"
+	cfg.Knowledge.Path = "data/knowledge.json"
+	cfg.Bots = []BotConfig{
+		{Name: "it", OrgID: "globex"},
+		{Name: "hr", OrgID: "globex"},
+		{Name: "ops"},
+	}
+	return cfg
+}
+
+func TestForBotScopesByOrgInMultiTenantMode(t *testing.T) {
+	cfg := tenantConfig()
+	if !cfg.MultiTenant() {
+		t.Fatal("存在其他组织的机器人时 MultiTenant() 应为true")
+	}
+
+	derived := map[string]*Config{}
+	for _, b := range cfg.BotConfigs() {
+		derived[b.Name] = cfg.ForBot(b)
+	}
+
+	tests := []struct {
+		bot, org string
+	}{
+		{"it", "globex"},
+		{"hr", "globex"},
+		{"ops", DefaultOrgID},
+	}
+	for _, tt := range tests {
+		d := derived[tt.bot]
+		if got := d.Org(); got != tt.org {
+			t.Errorf("%s: Org() = %q，期望 %q", tt.bot, got, tt.org)
+		}
+
+		paths := map[string][2]string{
+			"logging.log_dir": {d.Logging.LogDir, filepath.Join("logs", tt.org, tt.bot)},
+			"stream.journal":  {d.Stream.Journal, filepath.Join("data/journal", tt.org, tt.bot)},
+			"handoff.path":    {d.Handoff.Path, "data/handoff_" + tt.org + "_" + tt.bot + ".json"},
+			"jobs.path":       {d.Jobs.Path, "data/jobs_" + tt.org + "_" + tt.bot + ".json"},
+			"budget.path":     {d.Budget.Path, "data/budget_" + tt.org + "_" + tt.bot + ".json"},
+			"analytics.path":  {d.Analytics.Path, "data/analytics_" + tt.org + "_" + tt.bot + ".db"},
+			"profile.path":    {d.Profile.Path, "data/profiles_" + tt.org + ".json"},
+			"knowledge.path":  {d.Knowledge.Path, "data/knowledge_" + tt.org + ".json"},
+		}
+		for field, p := range paths {
+			if p[0] != p[1] {
+				t.Errorf("%s: %s = %q，期望 %q", tt.bot, field, p[0], p[1])
+			}
+		}
+	}
+
+	// 同一组织的机器人共享画像和知识库，其余数据互不共享；不同组织之间都不共享
+	if derived["it"].Profile.Path != derived["hr"].Profile.Path || derived["it"].Knowledge.Path != derived["hr"].Knowledge.Path {
+		t.Error("同一组织的机器人应共享用户画像和知识库")
+	}
+	if derived["it"].Profile.Path == derived["ops"].Profile.Path || derived["it"].Knowledge.Path == derived["ops"].Knowledge.Path {
+		t.Error("不同组织的机器人不应共享用户画像和知识库")
+	}
+	if derived["it"].Budget.Path == derived["hr"].Budget.Path {
+		t.Error("同一组织的不同机器人不应共享用量记录")
+	}
+}
+
+func TestForBotSingleOrgKeepsPaths(t *testing.T) {
+	cfg := tenantConfig()
+	cfg.OrgID = "globex" // 全部机器人属于同一组织：不是多租户模式
+	if cfg.MultiTenant() {
+		t.Fatal("所有机器人属于全局组织时 MultiTenant() 应为false")
+	}
+
+	d := cfg.ForBot(cfg.BotConfigs()[2])
+	if d.Logging.LogDir != filepath.Join("logs", "ops") {
+		t.Errorf("logging.log_dir = %q，期望 %q", d.Logging.LogDir, filepath.Join("logs", "ops"))
+	}
+	if d.Budget.Path != "data/budget_ops.json" {
+		t.Errorf("budget.path = %q，期望 %q", d.Budget.Path, "data/budget_ops.json")
+	}
+	if d.Profile.Path != "data/profiles.json" || d.Knowledge.Path != "data/knowledge.json" {
+		t.Errorf("单组织时画像和知识库路径应保持不变: %q, %q", d.Profile.Path, d.Knowledge.Path)
+	}
+}
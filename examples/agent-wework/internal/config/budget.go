@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// applyBudgetDefaults 填充用量预算默认值
+func applyBudgetDefaults(b *BudgetConfig) {
+	if b.Message == "" {
+		b.Message = "今日对话额度已用完，请明天再来，或联系管理员调整额度。"
+	}
+	if b.Path == "" {
+		b.Path = "data/budget.json"
+	}
+}
+
+// validateBudget 验证用量预算配置
+func validateBudget(config *Config) error {
+	b := config.Budget
+	if !b.Enabled {
+		return nil
+	}
+	if b.UserTokens < 0 || b.GroupTokens < 0 || b.UserCost < 0 || b.GroupCost < 0 {
+		return fmt.Errorf("budget 的限额不能为负数")
+	}
+	if b.UserTokens == 0 && b.GroupTokens == 0 && b.UserCost == 0 && b.GroupCost == 0 {
+		return fmt.Errorf("启用用量预算时至少需要配置一项限额（user_tokens、group_tokens、user_cost、group_cost）")
+	}
+	if (b.UserCost > 0 || b.GroupCost > 0) && len(b.Prices) == 0 {
+		return fmt.Errorf("配置费用限额时必须配置budget.prices")
+	}
+	for name, price := range b.Prices {
+		if _, ok := config.LLM.Providers[name]; !ok {
+			return fmt.Errorf("budget.prices 引用的LLM提供商 '%s' 在配置中不存在", name)
+		}
+		if price < 0 {
+			return fmt.Errorf("budget.prices.%s 不能为负数", name)
+		}
+	}
+	if b.Fallback != "" {
+		if _, ok := config.LLM.Providers[b.Fallback]; !ok {
+			return fmt.Errorf("budget.fallback 引用的LLM提供商 '%s' 在配置中不存在", b.Fallback)
+		}
+	}
+	return nil
+}
+
+// Limits 会话的每日限额（群聊使用群聊限额，其他会话使用单聊限额，0为不限）
+func (b BudgetConfig) Limits(conversationID string) (tokens int, cost float64) {
+	if strings.HasPrefix(conversationID, "group_") {
+		return b.GroupTokens, b.GroupCost
+	}
+	return b.UserTokens, b.UserCost
+}
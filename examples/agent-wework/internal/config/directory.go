@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DepartmentRulePrefix 按部门匹配用户的规则前缀（如 dept:运维组 或 dept:12，包含子部门成员）
+const DepartmentRulePrefix = "dept:"
+
+// applyDirectoryDefaults 填充通讯录默认值
+func applyDirectoryDefaults(d *DirectoryConfig) {
+	if d.Refresh == 0 {
+		d.Refresh = 60
+	}
+}
+
+// validateDirectory 验证通讯录配置（按部门的规则需要启用通讯录）
+func validateDirectory(config *Config) error {
+	d := config.Directory
+	if !d.Enabled {
+		if len(d.Allow) > 0 {
+			return fmt.Errorf("directory.allow 需要启用通讯录（directory.enabled）")
+		}
+		for _, admin := range config.Knowledge.Admins {
+			if strings.HasPrefix(admin, DepartmentRulePrefix) {
+				return fmt.Errorf("knowledge.admins 中的部门规则 %q 需要启用通讯录（directory.enabled）", admin)
+			}
+		}
+		return nil
+	}
+	if d.CorpID == "" || d.Secret == "" {
+		return fmt.Errorf("启用通讯录时必须配置directory.corp_id和directory.secret")
+	}
+	if d.Refresh < 0 {
+		return fmt.Errorf("directory.refresh 不能为负数")
+	}
+	for _, rules := range [][]string{d.Allow, config.Knowledge.Admins} {
+		for _, rule := range rules {
+			if strings.TrimSpace(strings.TrimPrefix(rule, DepartmentRulePrefix)) == "" {
+				return fmt.Errorf("无效的用户或部门规则: %q", rule)
+			}
+		}
+	}
+	return nil
+}
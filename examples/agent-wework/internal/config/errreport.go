@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// applyErrorReportDefaults 填充错误上报默认值
+func applyErrorReportDefaults(e *ErrorReportConfig) {
+	if e.Environment == "" {
+		e.Environment = "production"
+	}
+	if e.ToolFailures == 0 {
+		e.ToolFailures = 3
+	}
+	if e.Interval == 0 {
+		e.Interval = 300
+	}
+}
+
+// validateErrorReport 验证错误上报配置
+func validateErrorReport(e ErrorReportConfig) error {
+	if !e.Enabled {
+		return nil
+	}
+	if e.SentryDSN == "" && e.WebhookURL == "" {
+		return fmt.Errorf("启用错误上报时必须配置error_report.sentry_dsn或error_report.webhook_url")
+	}
+	if e.SentryDSN != "" {
+		u, err := url.Parse(e.SentryDSN)
+		if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" || len(u.Path) < 2 {
+			return fmt.Errorf("error_report.sentry_dsn 格式无效（应为 https://<key>@<host>/<project>）")
+		}
+	}
+	if e.ToolFailures < 0 || e.Interval < 0 {
+		return fmt.Errorf("error_report.tool_failures、interval 不能为负数")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Assign 按会话ID确定性地分配实验变体（同一会话始终落在同一变体，未启用时返回false）
+func (e ExperimentConfig) Assign(conversationID string) (VariantConfig, bool) {
+	if !e.Enabled {
+		return VariantConfig{}, false
+	}
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return VariantConfig{}, false
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + ":" + conversationID))
+	n := int(h.Sum32() % uint32(total))
+	for _, v := range e.Variants {
+		if n < v.Weight {
+			return v, true
+		}
+		n -= v.Weight
+	}
+	return VariantConfig{}, false
+}
+
+// Apply 将变体应用到功能开关（会话覆盖、人设或用户偏好已指定的提示词和模型不替换）
+func (v VariantConfig) Apply(f Features) Features {
+	if prompt := strings.TrimSpace(v.SystemPrompt); prompt != "" && f.SystemPrompt == "" {
+		f.SystemPrompt = prompt
+	}
+	if v.LLMProvider != "" && f.LLMProvider == "" {
+		f.LLMProvider = v.LLMProvider
+	}
+	return f
+}
+
+// validateExperiment 验证A/B实验配置
+func validateExperiment(config *Config) error {
+	e := config.Experiment
+	if !e.Enabled {
+		return nil
+	}
+	if e.Name == "" {
+		return fmt.Errorf("启用A/B实验时必须配置experiment.name")
+	}
+	if len(e.Variants) < 2 {
+		return fmt.Errorf("A/B实验至少需要2个变体")
+	}
+
+	names := make(map[string]bool)
+	total := 0
+	for i, v := range e.Variants {
+		if v.Name == "" {
+			return fmt.Errorf("第%d个实验变体缺少name", i+1)
+		}
+		if names[v.Name] {
+			return fmt.Errorf("实验变体名称重复: %s", v.Name)
+		}
+		names[v.Name] = true
+		if v.Weight < 0 {
+			return fmt.Errorf("实验变体 '%s' 的weight不能为负数", v.Name)
+		}
+		total += v.Weight
+		if v.LLMProvider != "" {
+			if _, ok := config.LLM.Providers[v.LLMProvider]; !ok {
+				return fmt.Errorf("实验变体 '%s' 引用的LLM提供商 '%s' 在配置中不存在", v.Name, v.LLMProvider)
+			}
+		}
+	}
+	if total == 0 {
+		return fmt.Errorf("实验变体的weight之和必须大于0")
+	}
+	return nil
+}
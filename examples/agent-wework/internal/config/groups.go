@@ -0,0 +1,100 @@
+package config
+
+import "strings"
+
+// Features 会话级功能开关（由全局配置与群聊覆盖合并得到）
+type Features struct {
+	Tools       bool     // 是否启用工具调用
+	MCPServers  []string // 允许使用的MCP服务器名称（为空表示全部）
+	Knowledge   bool     // 是否启用知识库检索
+	Thinking    bool     // 是否展示思考过程
+	Proactive   bool     // 是否允许主动推送消息
+	Handoff     bool     // 是否提供转人工工具（定时任务等非对话场景关闭）
+	ExtraPrompt string   // 追加的系统提示词
+	Notice      string   // 追加的状态说明（如非营业时间受限模式）
+	Persona     string   // 当前人设名称（未使用人设时为空）
+
+	// 会话级覆盖
+	SystemPrompt string   // 替换全局系统提示词（为空使用全局）
+	LLMProvider  string   // 使用的LLM提供商（为空使用默认）
+	AllowedTools []string // 允许使用的工具名称（为空表示全部）
+}
+
+// DefaultFeatures 全局默认功能开关
+func (c *Config) DefaultFeatures() Features {
+	return Features{
+		Tools:     true,
+		Knowledge: c.Knowledge.Enabled,
+		Thinking:  true,
+		Proactive: true,
+		Handoff:   c.Handoff.Enabled,
+	}
+}
+
+// Apply 将群聊覆盖应用到功能开关上
+func (g GroupConfig) Apply(f Features) Features {
+	if g.Tools != nil {
+		f.Tools = *g.Tools
+	}
+	if len(g.MCPServers) > 0 {
+		f.MCPServers = append([]string(nil), g.MCPServers...)
+	}
+	if g.Knowledge != nil {
+		// 知识库未全局启用时无法按群开启
+		f.Knowledge = f.Knowledge && *g.Knowledge
+	}
+	if g.Thinking != nil {
+		f.Thinking = *g.Thinking
+	}
+	if g.Proactive != nil {
+		f.Proactive = *g.Proactive
+	}
+	f.ExtraPrompt = strings.TrimSpace(g.SystemPrompt)
+	return f
+}
+
+// AllowsMCPServer 判断是否允许使用指定MCP服务器
+func (f Features) AllowsMCPServer(name string) bool {
+	if !f.Tools {
+		return false
+	}
+	if len(f.MCPServers) == 0 {
+		return true
+	}
+	for _, allowed := range f.MCPServers {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 将会话级覆盖应用到功能开关上
+func (o OverrideConfig) Apply(f Features) Features {
+	if prompt := strings.TrimSpace(o.SystemPrompt); prompt != "" {
+		f.SystemPrompt = prompt
+	}
+	if o.LLMProvider != "" {
+		f.LLMProvider = o.LLMProvider
+	}
+	if len(o.Tools) > 0 {
+		f.AllowedTools = append([]string(nil), o.Tools...)
+	}
+	return f
+}
+
+// AllowsTool 判断是否允许使用指定工具
+func (f Features) AllowsTool(name string) bool {
+	if !f.Tools {
+		return false
+	}
+	if len(f.AllowedTools) == 0 {
+		return true
+	}
+	for _, allowed := range f.AllowedTools {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/policy"
+)
+
+// DefaultOffHoursReply 非营业时间的默认自动回复
+const DefaultOffHoursReply = "您好，现在是非工作时间（工作时间：{hours}），请在工作时间再联系我们。"
+
+// DefaultOffHoursNotice 受限模式下追加到系统提示词的默认说明
+const DefaultOffHoursNotice = "当前为非工作时间（工作时间：{hours}），你无法调用任何工具或转接人工，只能根据已有知识回答咨询类问题；需要办理的事项请告知用户在工作时间再联系。"
+
+// 非营业时间的处理方式
+const (
+	OffHoursReply      = "reply"
+	OffHoursRestricted = "restricted"
+)
+
+// applyBusinessHoursDefaults 填充营业时间默认值
+func applyBusinessHoursDefaults(h *BusinessHoursConfig) {
+	if len(h.Weekdays) == 0 {
+		h.Weekdays = []int{1, 2, 3, 4, 5}
+	}
+	if len(h.Hours) == 0 {
+		h.Hours = []string{"09:00-18:00"}
+	}
+	if h.OffHours == "" {
+		h.OffHours = OffHoursReply
+	}
+	if h.Reply == "" {
+		h.Reply = DefaultOffHoursReply
+	}
+	if h.Notice == "" {
+		h.Notice = DefaultOffHoursNotice
+	}
+}
+
+// Restricted 非营业时间是否以受限模式继续回答
+func (h BusinessHoursConfig) Restricted() bool {
+	return h.OffHours == OffHoursRestricted
+}
+
+// Parse 解析营业时间（未启用时返回nil）
+func (h BusinessHoursConfig) Parse() (*policy.BusinessHours, error) {
+	if !h.Enabled {
+		return nil, nil
+	}
+	loc := time.Local
+	if h.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(h.Timezone); err != nil {
+			return nil, fmt.Errorf("时区无效: %s", h.Timezone)
+		}
+	}
+	return policy.NewBusinessHours(loc, h.Weekdays, h.Hours, h.Holidays, h.Workdays)
+}
+
+// Render 替换文本中的 {hours} 为营业时间说明
+func (h BusinessHoursConfig) Render(text string, hours *policy.BusinessHours) string {
+	return strings.ReplaceAll(text, "{hours}", hours.String())
+}
+
+// validateBusinessHours 验证营业时间配置（name用于错误信息，如 business_hours 或 bots[sales].business_hours）
+func validateBusinessHours(name string, h BusinessHoursConfig) error {
+	if !h.Enabled {
+		return nil
+	}
+	if h.OffHours != OffHoursReply && h.OffHours != OffHoursRestricted {
+		return fmt.Errorf("%s.off_hours 仅支持 reply 或 restricted: %s", name, h.OffHours)
+	}
+	if _, err := h.Parse(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
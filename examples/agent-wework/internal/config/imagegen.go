@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// imageSizeRegex 图片尺寸格式（宽x高）
+var imageSizeRegex = regexp.MustCompile(`^\d{3,4}x\d{3,4}$`)
+
+// applyImageGenDefaults 填充图片生成默认值
+func applyImageGenDefaults(g *ImageGenConfig) {
+	if g.Provider == "" {
+		g.Provider = "openai"
+	}
+	if g.Model == "" {
+		switch g.Provider {
+		case "openai":
+			g.Model = "dall-e-3"
+		case "wanx":
+			g.Model = "wanx-v1"
+		}
+	}
+	if g.Size == "" {
+		g.Size = "1024x1024"
+	}
+	if g.Timeout == 0 {
+		g.Timeout = 120
+	}
+	if g.MaxPerReply == 0 {
+		g.MaxPerReply = 4
+	}
+}
+
+// validateImageGen 验证图片生成配置
+func validateImageGen(g ImageGenConfig) error {
+	if !g.Enabled {
+		return nil
+	}
+	if g.Provider != "openai" && g.Provider != "wanx" {
+		return fmt.Errorf("不支持的图片生成服务: %q（可选 openai、wanx）", g.Provider)
+	}
+	if g.APIKey == "" {
+		return fmt.Errorf("启用图片生成时必须配置image_gen.api_key")
+	}
+	if !imageSizeRegex.MatchString(g.Size) {
+		return fmt.Errorf("image_gen.size 格式应为 宽x高（如1024x1024）: %s", g.Size)
+	}
+	if g.MaxPerReply < 1 || g.MaxPerReply > 10 {
+		return fmt.Errorf("image_gen.max_per_reply 必须在1-10之间")
+	}
+	if g.Timeout < 0 {
+		return fmt.Errorf("image_gen.timeout 不能为负数")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import "fmt"
+
+// applyJobsDefaults 填充长任务跟踪默认值
+func applyJobsDefaults(j *JobsConfig) {
+	if j.After == 0 {
+		j.After = 20
+	}
+	if j.Retention == 0 {
+		j.Retention = 24
+	}
+	if j.Path == "" {
+		j.Path = "data/jobs.json"
+	}
+}
+
+// validateJobs 验证长任务跟踪配置
+func validateJobs(j JobsConfig) error {
+	if !j.Enabled {
+		return nil
+	}
+	if j.After < 1 {
+		return fmt.Errorf("jobs.after 必须大于0")
+	}
+	if j.Retention < 1 {
+		return fmt.Errorf("jobs.retention 必须大于0")
+	}
+	return nil
+}
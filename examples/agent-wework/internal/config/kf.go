@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultKFPath 微信客服回调的默认路由（位于server.prefix下）
+const DefaultKFPath = "/kf/callback"
+
+// applyKFDefaults 填充微信客服渠道默认值
+func applyKFDefaults(kf *KFConfig, server ServerConfig) {
+	if kf.Path == "" {
+		kf.Path = server.Route(DefaultKFPath)
+	}
+	if kf.CursorPath == "" {
+		kf.CursorPath = "data/kf_cursor.json"
+	}
+}
+
+// validateKF 验证微信客服渠道配置
+func validateKF(config *Config) error {
+	kf := config.KF
+	if !kf.Enabled {
+		return nil
+	}
+	if kf.CorpID == "" || kf.Secret == "" {
+		return fmt.Errorf("启用微信客服时必须配置kf.corp_id和kf.secret")
+	}
+	if kf.Token == "" || len(kf.AESKey) != 43 {
+		return fmt.Errorf("启用微信客服时必须配置kf.token和43位的kf.aes_key")
+	}
+	if !strings.HasPrefix(kf.Path, "/") {
+		return fmt.Errorf("kf.path 必须以 / 开头: %s", kf.Path)
+	}
+	for _, b := range config.BotConfigs() {
+		if kf.Path == b.Path {
+			return fmt.Errorf("kf.path 与机器人 %s 的Webhook路由冲突: %s", b.Name, kf.Path)
+		}
+	}
+	if config.ChannelBot(kf.Bot) == "" {
+		return fmt.Errorf("kf.bot 引用的机器人 '%s' 在配置中不存在", kf.Bot)
+	}
+	return nil
+}
@@ -1,11 +1,21 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/secrets"
+	"gopkg.in/yaml.v3"
 )
 
 // LoadConfigFromFile 从文件加载配置
@@ -19,37 +29,145 @@ func LoadConfigFromFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			fmt.Printf("配置文件 '%s' 不存在，使用默认配置\n", path)
-			return GetDefaultConfig(), nil
+			if strictSecretsForced() {
+				return nil, fmt.Errorf("严格密钥模式下配置文件必须存在: %s", path)
+			}
+			slog.Info("配置文件不存在，使用默认配置（凭证从环境变量读取）", "path", path)
+			return loadDefaultConfig()
 		}
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
-	// 解析JSON
+	config, err := parseConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("成功加载配置文件", "path", path)
+	return config, nil
+}
+
+// ReloadConfigFromFile 重新加载配置文件（用于热更新，文件缺失或无效时返回错误而不回退默认配置）
+func ReloadConfigFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	return parseConfig(path, data)
+}
+
+// parseConfig 解析、填充默认值并验证配置
+func parseConfig(path string, data []byte) (*Config, error) {
+	// 严格校验：拒绝未知配置项和类型不匹配
+	if err := ValidateSchema(data); err != nil {
+		return nil, err
+	}
+
+	// 解析配置（按扩展名识别JSON或YAML）
 	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	if err := decodeConfig(path, data, &config); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
+	// 严格密钥模式：拒绝明文密钥
+	if config.StrictSecrets || strictSecretsForced() {
+		if err := checkSecretSources(&config); err != nil {
+			return nil, err
+		}
+	}
+
 	// 处理环境变量引用
 	processConfigEnvVars(&config)
 
+	// 解析密钥引用（vault:、aws-kms:、file:、envfile:）
+	if err := resolveSecrets(&config); err != nil {
+		return nil, err
+	}
+
+	// 填充默认值
+	applyDefaults(&config)
+
 	// 验证配置
 	if err := validateConfig(&config); err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("✅ 成功加载配置文件: %s\n", path)
 	return &config, nil
 }
 
-// GetDefaultConfig 返回默认配置
+// decodeConfig 解析配置内容，.yaml/.yml 文件先转换为JSON再解析，与JSON共用同一套字段定义；
+// 字符串字段中未加引号的整数（如 port: 8080）按Schema转换为字符串
+func decodeConfig(path string, data []byte, config *Config) error {
+	var raw interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		raw = normalizeYAML(raw)
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber() // 保留整数精度
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if _, err := decoder.Token(); err != io.EOF {
+			return fmt.Errorf("JSON内容之后有多余的数据")
+		}
+	}
+
+	jsonData, err := json.Marshal(GenerateSchema().coerceStrings(raw))
+	if err != nil {
+		return fmt.Errorf("配置转换失败: %w", err)
+	}
+	return json.Unmarshal(jsonData, config)
+}
+
+// normalizeYAML 将YAML中的非字符串键（如纯数字的群ChatID）转换为字符串，便于转换为JSON
+func normalizeYAML(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeYAML(item)
+		}
+		return value
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			result[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeYAML(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// loadDefaultConfig 展开默认配置中的环境变量并验证，缺少必需的环境变量时返回错误
+func loadDefaultConfig() (*Config, error) {
+	config := GetDefaultConfig()
+	processConfigEnvVars(config)
+	if err := resolveSecrets(config); err != nil {
+		return nil, err
+	}
+	applyDefaults(config)
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("默认配置无效（请设置 WEWORK_TOKEN、WEWORK_AES_KEY、DASHSCOPE_API_KEY 等环境变量）: %w", err)
+	}
+	return config, nil
+}
+
+// GetDefaultConfig 返回默认配置（凭证均为环境变量引用，不包含明文密钥）
 func GetDefaultConfig() *Config {
 	return &Config{
 		WeWork: WeWorkConfig{
-			Token:  "LYDMxF6qKEPWVGdKaQdAYw9xxfSzDsIC",
-			AESKey: "f4gfCYzaqGAfh4rqxWjqG9udsZwI0d3uRlx5cXVUgDu",
-			BotID:  "aibNFPh36tB2I_wrYlPhlPyOY_fQavVb3mc",
+			Token:  "${WEWORK_TOKEN}",
+			AESKey: "${WEWORK_AES_KEY}",
+			BotID:  "${WEWORK_BOT_ID}",
 		},
 		LLM: LLMConfigs{
 			Default:      "qwen",
@@ -57,7 +175,7 @@ func GetDefaultConfig() *Config {
 			Providers: map[string]LLMProviderConfig{
 				"qwen": {
 					Provider: "qwen",
-					APIKey:   "sk-0d8bebab081044f682fbeb6c147d8f2c",
+					APIKey:   "${DASHSCOPE_API_KEY}",
 					Model:    "qwen-max",
 					BaseURL:  "https://dashscope.aliyuncs.com/compatible-mode/v1",
 				},
@@ -81,53 +199,510 @@ func GetDefaultConfig() *Config {
 		Server: ServerConfig{
 			Port: "8889",
 		},
+		Stream: StreamConfig{
+			MaxResumeAttempts: DefaultMaxResumeAttempts,
+			MaxRetries:        DefaultMaxRetries,
+			RetryBackoff:      DefaultRetryBackoff,
+			InterimAfter:      DefaultInterimAfter,
+			Heartbeat:         DefaultHeartbeat,
+			MaxBytes:          DefaultStreamMaxBytes,
+		},
+		Profile: ProfileConfig{
+			Path: "data/profiles.json",
+		},
+		Knowledge: KnowledgeConfig{
+			Path:        "data/knowledge.json",
+			MaxFileSize: 1024,
+		},
+		Notify: NotifyConfig{
+			BatchWindow:  300,
+			MaxBatchSize: 10,
+		},
+	}
+}
+
+// DefaultMaxResumeAttempts 默认流式续传次数
+const DefaultMaxResumeAttempts = 2
+
+// DefaultMaxRetries 默认Agent运行失败重试次数
+const DefaultMaxRetries = 2
+
+// DefaultRetryBackoff 默认首次重试等待时间（秒）
+const DefaultRetryBackoff = 1
+
+// DefaultApprovalTimeout 默认等待审批时长（秒）
+const DefaultApprovalTimeout = 120
+
+// ApprovalCategory 审批通知的通知类别
+const ApprovalCategory = "approval"
+
+// DefaultInterimAfter 默认进度提示等待时间（秒）
+const DefaultInterimAfter = 8
+
+// DefaultHeartbeat 默认心跳间隔（秒）
+const DefaultHeartbeat = 5
+
+// DefaultStreamMaxBytes 默认单条流式消息内容上限（字节，预留结尾提示的空间）
+const DefaultStreamMaxBytes = 20000
+
+// MaxStreamBytes 企业微信流式消息内容上限（字节）
+const MaxStreamBytes = 20480
+
+// applyDefaults 为未配置的可选项填充默认值
+func applyDefaults(config *Config) {
+	if config.Stream.MaxResumeAttempts == 0 {
+		config.Stream.MaxResumeAttempts = DefaultMaxResumeAttempts
+	}
+	if config.Stream.MaxRetries == 0 {
+		config.Stream.MaxRetries = DefaultMaxRetries
+	}
+	if config.Stream.RetryBackoff <= 0 {
+		config.Stream.RetryBackoff = DefaultRetryBackoff
+	}
+	if config.Stream.InterimAfter == 0 {
+		config.Stream.InterimAfter = DefaultInterimAfter
+	}
+	if config.Stream.Heartbeat == 0 {
+		config.Stream.Heartbeat = DefaultHeartbeat
+	}
+	if config.Stream.MaxBytes == 0 {
+		config.Stream.MaxBytes = DefaultStreamMaxBytes
+	}
+	if config.Profile.Path == "" {
+		config.Profile.Path = "data/profiles.json"
+	}
+	if config.Preferences.Path == "" {
+		config.Preferences.Path = "data/preferences.json"
+	}
+	if config.Knowledge.Path == "" {
+		config.Knowledge.Path = "data/knowledge.json"
+	}
+	if config.Knowledge.MaxFileSize == 0 {
+		config.Knowledge.MaxFileSize = 1024
+	}
+	for i := range config.Schedules {
+		if config.Schedules[i].Category == "" {
+			config.Schedules[i].Category = DefaultScheduleCategory
+		}
+		if config.Schedules[i].Timeout == 0 {
+			config.Schedules[i].Timeout = DefaultScheduleTimeout
+		}
+	}
+	if config.Analytics.Path == "" {
+		config.Analytics.Path = "data/analytics.db"
+	}
+	if config.Audit.Path == "" {
+		config.Audit.Path = "data/audit.jsonl"
+	}
+	if config.Router.Timeout == 0 {
+		config.Router.Timeout = DefaultRouterTimeout
+	}
+	if config.Approval.Timeout == 0 {
+		config.Approval.Timeout = DefaultApprovalTimeout
+	}
+	// 审批通知默认立即推送
+	if config.Approval.Enabled && len(config.Approval.Approvers) > 0 {
+		if _, ok := config.Notify.Categories[ApprovalCategory]; !ok {
+			if config.Notify.Categories == nil {
+				config.Notify.Categories = make(map[string]string)
+			}
+			config.Notify.Categories[ApprovalCategory] = "urgent"
+		}
+	}
+	// 定时任务结果默认立即推送（可在notify.categories中改为normal参与合并）
+	if len(config.Schedules) > 0 {
+		if _, ok := config.Notify.Categories[DefaultScheduleCategory]; !ok {
+			if config.Notify.Categories == nil {
+				config.Notify.Categories = make(map[string]string)
+			}
+			config.Notify.Categories[DefaultScheduleCategory] = "urgent"
+		}
+	}
+	if config.Notify.BatchWindow == 0 {
+		config.Notify.BatchWindow = 300
+	}
+	if config.Notify.MaxBatchSize == 0 {
+		config.Notify.MaxBatchSize = 10
+	}
+	if config.Fetch.AuditLog == "" {
+		config.Fetch.AuditLog = "data/fetch_audit.jsonl"
+	}
+	applyModerationDefaults(&config.Moderation)
+	if config.Handoff.Enabled {
+		applyHandoffDefaults(&config.Handoff, config.Notify)
+	}
+	applyBusinessHoursDefaults(&config.BusinessHours)
+	applyWelcomeDefaults(&config.Welcome)
+	applyOCRDefaults(&config.OCR)
+	applyTTSDefaults(&config.TTS, config.Notify)
+	applyImageGenDefaults(&config.ImageGen)
+	applyTicketDefaults(&config.Ticket)
+	applyDirectoryDefaults(&config.Directory)
+	applyLocaleDefaults(config)
+	applyJobsDefaults(&config.Jobs)
+	applyWarmPoolDefaults(&config.WarmPool)
+	applySummaryDefaults(&config.Summary)
+	applyBudgetDefaults(&config.Budget)
+	applyServerDefaults(&config.Server)
+	applyKFDefaults(&config.KF, config.Server)
+	applySlackDefaults(&config.Slack, config.Server)
+	applyTelegramDefaults(&config.Telegram, config.Server)
+	applyErrorReportDefaults(&config.ErrorReport)
+	applyOutboundDefaults(&config.Outbound)
+	for i := range config.Bots {
+		if config.Bots[i].Moderation != nil {
+			applyModerationDefaults(config.Bots[i].Moderation)
+		}
+		if config.Bots[i].BusinessHours != nil {
+			applyBusinessHoursDefaults(config.Bots[i].BusinessHours)
+		}
+		if config.Bots[i].Welcome != nil {
+			applyWelcomeDefaults(config.Bots[i].Welcome)
+		}
+	}
+	if config.Dedup.Size == 0 {
+		config.Dedup.Size = 10000
+	}
+	if config.Dedup.TTL == 0 {
+		config.Dedup.TTL = 3600
+	}
+	if config.Dedup.KeyPrefix == "" {
+		config.Dedup.KeyPrefix = "b0dy:dedup:"
+	}
+	if config.Cluster.Enabled {
+		applyClusterDefaults(&config.Cluster, config.Dedup)
+	}
+	if config.Queue.Enabled {
+		applyQueueDefaults(&config.Queue, config.Cluster)
+	}
+	if config.Health.Interval == 0 {
+		config.Health.Interval = 60
+	}
+	if config.Health.Timeout == 0 {
+		config.Health.Timeout = 10
+	}
+}
+
+// applyHandoffDefaults 填充转人工默认值，Webhook未配置时沿用主动通知的群机器人
+func applyHandoffDefaults(handoff *HandoffConfig, notify NotifyConfig) {
+	if len(handoff.Keywords) == 0 {
+		handoff.Keywords = []string{"转人工", "人工客服"}
+	}
+	if handoff.WebhookURL == "" {
+		handoff.WebhookURL = notify.WebhookURL
+	}
+	if handoff.WaitTimeout == 0 {
+		handoff.WaitTimeout = 240
+	}
+	if handoff.TranscriptTurns == 0 {
+		handoff.TranscriptTurns = 10
+	}
+	if handoff.Path == "" {
+		handoff.Path = "data/handoff.json"
 	}
 }
 
-// processConfigEnvVars 处理配置中的环境变量引用
+// applyClusterDefaults 填充共享状态默认值，Redis连接未配置时沿用去重配置
+func applyClusterDefaults(cluster *ClusterConfig, dedup DedupConfig) {
+	if cluster.InstanceID == "" {
+		cluster.InstanceID, _ = os.Hostname()
+	}
+	if cluster.RedisURL == "" {
+		cluster.RedisURL = dedup.RedisURL
+		if cluster.RedisPassword == "" {
+			cluster.RedisPassword = dedup.RedisPassword
+		}
+	}
+	if cluster.KeyPrefix == "" {
+		cluster.KeyPrefix = "b0dy:stream:"
+	}
+	if cluster.SyncInterval == 0 {
+		cluster.SyncInterval = 500
+	}
+	if cluster.TTL == 0 {
+		cluster.TTL = 600
+	}
+}
+
+// applyQueueDefaults 填充消息队列默认值，redis后端未配置地址时沿用共享状态的Redis
+func applyQueueDefaults(queue *QueueConfig, cluster ClusterConfig) {
+	if queue.Backend == "" {
+		queue.Backend = "nats"
+	}
+	if queue.Backend == "redis" && queue.URL == "" {
+		queue.URL = cluster.RedisURL
+	}
+	if queue.Name == "" {
+		queue.Name = "b0dy_jobs"
+	}
+	if queue.Concurrency == 0 {
+		queue.Concurrency = 4
+	}
+	if queue.JobTimeout == 0 {
+		queue.JobTimeout = 300
+	}
+}
+
+// processConfigEnvVars 处理配置中所有字符串字段的环境变量引用
 func processConfigEnvVars(config *Config) {
-	// 处理LLM配置中的环境变量
+	expandEnvFields(reflect.ValueOf(config).Elem())
+}
+
+// expandEnvFields 递归展开结构体、切片和映射中的字符串值
+func expandEnvFields(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(processEnvVar(v.String()))
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			expandEnvFields(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				expandEnvFields(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			expandEnvFields(v.Index(i))
+		}
+	case reflect.Map:
+		// 映射元素不可寻址，复制后展开再写回
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			expandEnvFields(elem)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+// forEachSecret 遍历所有敏感字段，fn可修改字段值；includeEnv为true时包含MCP服务器的env映射
+func forEachSecret(config *Config, includeEnv bool, fn func(field string, value *string) error) error {
+	if err := fn("wework.token", &config.WeWork.Token); err != nil {
+		return err
+	}
+	if err := fn("wework.aes_key", &config.WeWork.AESKey); err != nil {
+		return err
+	}
+	for i := range config.Bots {
+		bot := &config.Bots[i]
+		if err := fn("bots."+bot.Name+".wework.token", &bot.WeWork.Token); err != nil {
+			return err
+		}
+		if err := fn("bots."+bot.Name+".wework.aes_key", &bot.WeWork.AESKey); err != nil {
+			return err
+		}
+	}
+
 	for name, provider := range config.LLM.Providers {
-		provider.APIKey = processEnvVar(provider.APIKey)
-		provider.BaseURL = processEnvVar(provider.BaseURL)
+		if err := fn("llm.providers."+name+".api_key", &provider.APIKey); err != nil {
+			return err
+		}
 		config.LLM.Providers[name] = provider
 	}
 
-	// 处理MCP配置中的环境变量
+	if err := fn("kf.secret", &config.KF.Secret); err != nil {
+		return err
+	}
+	if err := fn("kf.token", &config.KF.Token); err != nil {
+		return err
+	}
+	if err := fn("kf.aes_key", &config.KF.AESKey); err != nil {
+		return err
+	}
+	if err := fn("slack.bot_token", &config.Slack.BotToken); err != nil {
+		return err
+	}
+	if err := fn("slack.signing_secret", &config.Slack.SigningSecret); err != nil {
+		return err
+	}
+	if err := fn("slack.app_token", &config.Slack.AppToken); err != nil {
+		return err
+	}
+	if err := fn("telegram.token", &config.Telegram.Token); err != nil {
+		return err
+	}
+	if err := fn("telegram.secret_token", &config.Telegram.SecretToken); err != nil {
+		return err
+	}
+	if err := fn("translation.api_key", &config.Translation.APIKey); err != nil {
+		return err
+	}
+	if err := fn("ocr.api_key", &config.OCR.APIKey); err != nil {
+		return err
+	}
+	if err := fn("ocr.secret_key", &config.OCR.SecretKey); err != nil {
+		return err
+	}
+	if err := fn("tts.api_key", &config.TTS.APIKey); err != nil {
+		return err
+	}
+	if err := fn("stream.continuation.secret", &config.Stream.Continuation.Secret); err != nil {
+		return err
+	}
+	for chatID, group := range config.Groups {
+		if err := fn("groups."+chatID+".webhook", &group.Webhook); err != nil {
+			return err
+		}
+		config.Groups[chatID] = group
+	}
+	if err := fn("tts.webhook_url", &config.TTS.WebhookURL); err != nil {
+		return err
+	}
+	if err := fn("image_gen.api_key", &config.ImageGen.APIKey); err != nil {
+		return err
+	}
+	if err := fn("ticket.token", &config.Ticket.Token); err != nil {
+		return err
+	}
+	if err := fn("directory.secret", &config.Directory.Secret); err != nil {
+		return err
+	}
+	if err := fn("notify.webhook_url", &config.Notify.WebhookURL); err != nil {
+		return err
+	}
+	if err := fn("error_report.sentry_dsn", &config.ErrorReport.SentryDSN); err != nil {
+		return err
+	}
+	if err := fn("error_report.webhook_url", &config.ErrorReport.WebhookURL); err != nil {
+		return err
+	}
+	for i := range config.Outbound.Endpoints {
+		endpoint := &config.Outbound.Endpoints[i]
+		if err := fn("outbound_webhooks.endpoints."+endpoint.Name+".url", &endpoint.URL); err != nil {
+			return err
+		}
+		if err := fn("outbound_webhooks.endpoints."+endpoint.Name+".secret", &endpoint.Secret); err != nil {
+			return err
+		}
+	}
+	if err := fn("dedup.redis_password", &config.Dedup.RedisPassword); err != nil {
+		return err
+	}
+	if err := fn("cluster.redis_password", &config.Cluster.RedisPassword); err != nil {
+		return err
+	}
+	if err := fn("queue.url", &config.Queue.URL); err != nil {
+		return err
+	}
+	if err := fn("admin.token", &config.Admin.Token); err != nil {
+		return err
+	}
+
 	for i := range config.MCP.Servers {
 		server := &config.MCP.Servers[i]
-		server.BaseURL = processEnvVar(server.BaseURL)
-		server.Token = processEnvVar(server.Token)
-
-		// 处理Env映射
+		if err := fn("mcp.servers."+server.Name+".token", &server.Token); err != nil {
+			return err
+		}
+		if !includeEnv {
+			continue
+		}
 		for k, v := range server.Env {
-			server.Env[k] = processEnvVar(v)
+			if err := fn("mcp.servers."+server.Name+".env."+k, &v); err != nil {
+				return err
+			}
+			server.Env[k] = v
 		}
 	}
+
+	return nil
 }
 
-// processEnvVar 处理单个环境变量引用
-func processEnvVar(value string) string {
-	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
-		envVar := strings.Trim(value, "${}")
-		return os.Getenv(envVar)
+// resolveSecrets 解析敏感字段中的密钥引用，任一引用解析失败则整个配置加载失败
+func resolveSecrets(config *Config) error {
+	resolver := secrets.NewResolver()
+	return forEachSecret(config, true, func(field string, value *string) error {
+		if !resolver.IsReference(*value) {
+			return nil
+		}
+		secret, err := resolver.Resolve(*value)
+		if err != nil {
+			return fmt.Errorf("配置项 %s: %w", field, err)
+		}
+		*value = secret
+		return nil
+	})
+}
+
+// StrictSecretsEnv 强制启用严格密钥模式的环境变量
+const StrictSecretsEnv = "AIBODY_STRICT_SECRETS"
+
+// strictSecretsForced 判断是否通过环境变量强制启用严格密钥模式
+func strictSecretsForced() bool {
+	switch strings.ToLower(os.Getenv(StrictSecretsEnv)) {
+	case "1", "true", "yes", "on":
+		return true
 	}
-	return value
+	return false
 }
 
-// validateConfig 验证配置的有效性
-func validateConfig(config *Config) error {
-	// 验证企业微信配置
-	if config.WeWork.Token == "" {
-		return fmt.Errorf("企业微信Token不能为空")
+// checkSecretSources 严格密钥模式：敏感字段必须来自环境变量或密钥后端，不允许明文
+// 需在展开环境变量之前调用，一次列出所有明文字段
+func checkSecretSources(config *Config) error {
+	resolver := secrets.NewResolver()
+	var plaintext []string
+	forEachSecret(config, false, func(field string, value *string) error {
+		if *value != "" && !strings.Contains(*value, "${") && !resolver.IsReference(*value) {
+			plaintext = append(plaintext, field)
+		}
+		return nil
+	})
+
+	if len(plaintext) > 0 {
+		sort.Strings(plaintext)
+		return fmt.Errorf("严格密钥模式下以下配置项不允许使用明文，请改为 ${ENV_VAR} 或密钥引用: %s", strings.Join(plaintext, ", "))
 	}
+	return nil
+}
 
-	if config.WeWork.AESKey == "" {
-		return fmt.Errorf("企业微信AESKey不能为空")
+// processEnvVar 展开字符串中的环境变量引用
+//
+// 支持 ${VAR}（未设置时为空）和 ${VAR:-默认值}（未设置或为空时使用默认值），
+// 可嵌入任意位置，如 "https://${MCP_HOST:-localhost}:8080/sse"；未闭合的 ${ 原样保留。
+func processEnvVar(value string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+
+	var sb strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			sb.WriteString(value)
+			break
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			sb.WriteString(value)
+			break
+		}
+		end += start
+
+		sb.WriteString(value[:start])
+		expr := value[start+2 : end]
+		name, fallback, hasDefault := strings.Cut(expr, ":-")
+		envValue := os.Getenv(name)
+		if envValue == "" && hasDefault {
+			envValue = fallback
+		}
+		sb.WriteString(envValue)
+
+		value = value[end+1:]
 	}
+	return sb.String()
+}
 
-	if len(config.WeWork.AESKey) != 43 {
-		return fmt.Errorf("企业微信AESKey长度必须为43位，当前长度: %d", len(config.WeWork.AESKey))
+// validateConfig 验证配置的有效性
+func validateConfig(config *Config) error {
+	// 验证企业微信配置（多机器人时在validateBots中逐个验证）
+	if len(config.Bots) == 0 {
+		if err := validateWeWork(config.WeWork); err != nil {
+			return err
+		}
 	}
 
 	// 验证LLM配置
@@ -140,8 +715,292 @@ func validateConfig(config *Config) error {
 	}
 
 	// 验证服务器配置
-	if config.Server.Port == "" {
-		return fmt.Errorf("服务端口不能为空")
+	if err := validateServer(config); err != nil {
+		return err
+	}
+	if config.Stream.MaxBytes < 1024 || config.Stream.MaxBytes > MaxStreamBytes {
+		return fmt.Errorf("stream.max_bytes 必须在1024-%d之间", MaxStreamBytes)
+	}
+	if c := config.Stream.Continuation; (c.CorpID != "" || c.Secret != "" || c.AgentID != 0) && (c.CorpID == "" || c.Secret == "" || c.AgentID <= 0) {
+		return fmt.Errorf("单聊续发需要同时配置stream.continuation.corp_id、secret和agent_id")
+	}
+
+	if _, err := applog.ParseLevel(config.Logging.Level); err != nil {
+		return fmt.Errorf("logging.level无效: %s（支持debug/info/warn/error）", config.Logging.Level)
+	}
+	switch config.Logging.Driver {
+	case "", "file", "sqlite":
+	default:
+		return fmt.Errorf("logging.driver无效: %s（支持file/sqlite）", config.Logging.Driver)
+	}
+	switch config.Logging.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("logging.format无效: %s（支持text/json）", config.Logging.Format)
+	}
+
+	switch config.Dedup.Backend {
+	case "", "memory":
+	case "redis":
+		if config.Dedup.RedisURL == "" {
+			return fmt.Errorf("dedup.backend为redis时必须配置dedup.redis_url")
+		}
+	default:
+		return fmt.Errorf("dedup.backend无效: %s（支持memory/redis）", config.Dedup.Backend)
+	}
+	if config.Admin.Enabled && len(config.Admin.Token) < 16 {
+		return fmt.Errorf("启用管理接口时admin.token至少16个字符")
+	}
+	if config.Cluster.Enabled {
+		if config.Cluster.RedisURL == "" {
+			return fmt.Errorf("启用cluster时必须配置cluster.redis_url或dedup.redis_url")
+		}
+		if config.Dedup.Backend != "redis" {
+			return fmt.Errorf("启用cluster时dedup.backend必须为redis，否则重发的回调可能在其他副本被重复处理")
+		}
+	}
+	if err := validateQueue(config); err != nil {
+		return err
+	}
+
+	if config.Notify.Enabled && config.Notify.WebhookURL == "" {
+		return fmt.Errorf("启用主动通知时必须配置notify.webhook_url")
+	}
+	if err := validateErrorReport(config.ErrorReport); err != nil {
+		return err
+	}
+	if err := validateOutbound(config.Outbound); err != nil {
+		return err
+	}
+
+	// 验证群聊配置引用的MCP服务器
+	mcpNames := make(map[string]bool)
+	for _, server := range config.MCP.Servers {
+		mcpNames[server.Name] = true
+		switch server.VCR {
+		case "", "record", "playback":
+		default:
+			return fmt.Errorf("MCP服务器 '%s' 的vcr模式无效: %s（支持record/playback）", server.Name, server.VCR)
+		}
+	}
+	for chatID, group := range config.Groups {
+		for _, name := range group.MCPServers {
+			if !mcpNames[name] {
+				return fmt.Errorf("群聊 '%s' 引用的MCP服务器 '%s' 在配置中不存在", chatID, name)
+			}
+		}
+	}
+
+	if err := validatePersonas(config, mcpNames); err != nil {
+		return err
+	}
+	if err := validateRouter(config); err != nil {
+		return err
+	}
+	if config.Analytics.LLMProvider != "" {
+		if _, ok := config.LLM.Providers[config.Analytics.LLMProvider]; !ok {
+			return fmt.Errorf("analytics 引用的LLM提供商 '%s' 在配置中不存在", config.Analytics.LLMProvider)
+		}
+	}
+	if config.Analytics.Retention < 0 {
+		return fmt.Errorf("analytics.retention 不能为负数")
+	}
+
+	for key, override := range config.Overrides {
+		if !strings.HasPrefix(key, "group_") && !strings.HasPrefix(key, "single_") {
+			return fmt.Errorf("会话覆盖 '%s' 的key必须以 group_ 或 single_ 开头", key)
+		}
+		if override.LLMProvider != "" {
+			if _, ok := config.LLM.Providers[override.LLMProvider]; !ok {
+				return fmt.Errorf("会话覆盖 '%s' 引用的LLM提供商 '%s' 在配置中不存在", key, override.LLMProvider)
+			}
+		}
+	}
+
+	if len(config.Bots) > 0 {
+		if err := validateBots(config, mcpNames); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSchedules(config); err != nil {
+		return err
+	}
+
+	if config.Handoff.Enabled {
+		if config.Handoff.SupportGroup == "" {
+			return fmt.Errorf("启用转人工时必须配置handoff.support_group")
+		}
+		if config.Handoff.WebhookURL == "" {
+			return fmt.Errorf("启用转人工时必须配置handoff.webhook_url或notify.webhook_url")
+		}
+	}
+
+	if config.Approval.Enabled {
+		if len(config.Approval.Tools) == 0 {
+			return fmt.Errorf("启用工具调用审批时必须配置approval.tools")
+		}
+		for _, pattern := range config.Approval.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("approval.tools 通配符无效: %s", pattern)
+			}
+		}
+		if len(config.Approval.Approvers) > 0 && !config.Notify.Enabled {
+			return fmt.Errorf("配置approval.approvers时需启用notify，用于通知审批人")
+		}
+	}
+
+	if err := validateModeration(config, "moderation", config.Moderation); err != nil {
+		return err
+	}
+	for _, b := range config.Bots {
+		if b.Moderation != nil {
+			if err := validateModeration(config, fmt.Sprintf("bots[%s].moderation", b.Name), *b.Moderation); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := validateBusinessHours("business_hours", config.BusinessHours); err != nil {
+		return err
+	}
+	for _, b := range config.Bots {
+		if b.BusinessHours != nil {
+			if err := validateBusinessHours(fmt.Sprintf("bots[%s].business_hours", b.Name), *b.BusinessHours); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range config.Preferences.Models {
+		if _, ok := config.LLM.Providers[name]; !ok {
+			return fmt.Errorf("preferences.models 引用的LLM提供商 '%s' 在配置中不存在", name)
+		}
+	}
+
+	if err := validateExperiment(config); err != nil {
+		return err
+	}
+
+	if err := validateOCR(config.OCR); err != nil {
+		return err
+	}
+	if err := validateTTS(config.TTS); err != nil {
+		return err
+	}
+	if err := validateImageGen(config.ImageGen); err != nil {
+		return err
+	}
+	if err := validateTicket(config.Ticket); err != nil {
+		return err
+	}
+	if err := validateDirectory(config); err != nil {
+		return err
+	}
+	if err := validateOrg(config); err != nil {
+		return err
+	}
+	if err := validateLocale(config); err != nil {
+		return err
+	}
+	if err := validateJobs(config.Jobs); err != nil {
+		return err
+	}
+	if err := validateWarmPool(config.WarmPool); err != nil {
+		return err
+	}
+	if err := validateSummary(config); err != nil {
+		return err
+	}
+	if err := validateSession(config.Session); err != nil {
+		return err
+	}
+	if err := validateBudget(config); err != nil {
+		return err
+	}
+	if err := validateKF(config); err != nil {
+		return err
+	}
+	if err := validateSlack(config); err != nil {
+		return err
+	}
+	if err := validateTelegram(config); err != nil {
+		return err
+	}
+
+	if err := validateWelcome("welcome", config.Welcome); err != nil {
+		return err
+	}
+	for _, b := range config.Bots {
+		if b.Welcome != nil {
+			if err := validateWelcome(fmt.Sprintf("bots[%s].welcome", b.Name), *b.Welcome); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTLS 验证HTTPS配置
+func validateTLS(t TLSConfig) error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("server.tls.cert_file和server.tls.key_file必须同时配置")
+	}
+	if !t.Autocert.Enabled {
+		return nil
+	}
+	if t.CertFile != "" {
+		return fmt.Errorf("server.tls不能同时配置证书文件和autocert")
+	}
+	if len(t.Autocert.Domains) == 0 {
+		return fmt.Errorf("启用autocert时必须配置server.tls.autocert.domains")
+	}
+	return nil
+}
+
+// validateQueue 验证消息队列模式
+func validateQueue(config *Config) error {
+	q := config.Queue
+	if !q.Enabled {
+		return nil
+	}
+	switch q.Backend {
+	case "nats", "redis":
+	default:
+		return fmt.Errorf("queue.backend无效: %s（支持nats/redis）", q.Backend)
+	}
+	if q.URL == "" {
+		return fmt.Errorf("启用queue时必须配置queue.url")
+	}
+	if !config.Cluster.Enabled {
+		return fmt.Errorf("启用queue时必须启用cluster，worker通过共享状态返回流式回复")
+	}
+	if q.Concurrency < 0 || q.JobTimeout < 0 {
+		return fmt.Errorf("queue.concurrency和queue.job_timeout不能为负数")
+	}
+	// 审批和转人工依赖同一进程内的等待状态，Webhook副本与worker分离后无法交互
+	if config.Approval.Enabled {
+		return fmt.Errorf("queue模式暂不支持approval（审批需在处理任务的进程内等待用户操作）")
+	}
+	if config.Handoff.Enabled {
+		return fmt.Errorf("queue模式暂不支持handoff（转人工状态需在Webhook副本与worker间共享）")
+	}
+	return nil
+}
+
+// validateWeWork 验证企业微信凭证
+func validateWeWork(wework WeWorkConfig) error {
+	if wework.Token == "" {
+		return fmt.Errorf("企业微信Token不能为空")
+	}
+
+	if wework.AESKey == "" {
+		return fmt.Errorf("企业微信AESKey不能为空")
+	}
+
+	if len(wework.AESKey) != 43 {
+		return fmt.Errorf("企业微信AESKey长度必须为43位，当前长度: %d", len(wework.AESKey))
 	}
 
 	return nil
@@ -220,6 +1079,18 @@ func SaveConfigTemplate(path string) error {
 		return err
 	}
 
+	// YAML模板：经由JSON转换以保持字段名一致
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var raw interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if data, err = yaml.Marshal(raw); err != nil {
+			return err
+		}
+	}
+
 	// 确保目录存在
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestConfig 写入临时配置文件并加载（凭证通过环境变量提供，不是真实凭证）
+func loadTestConfig(t *testing.T, name, content string) *Config {
+	t.Helper()
+
+	t.Setenv("TEST_WEWORK_TOKEN", "test-token")
+	t.Setenv("TEST_WEWORK_AES_KEY", strings.Repeat("a", 43))
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("加载 %s 失败: %v", name, err)
+	}
+	return cfg
+}
+
+func TestLoadUnquotedPort(t *testing.T) {
+	cfg := loadTestConfig(t, "config.yaml", `
+server:
+  port: 8080
+  tls:
+    autocert:
+      http_port: 80
+wework:
+  token: ${TEST_WEWORK_TOKEN}
+  aes_key: ${TEST_WEWORK_AES_KEY}
+llm:
+  default: local
+  providers:
+    local:
+      provider: ollama
+      model: qwen3:32b
+`)
+	if cfg.Server.Port != "8080" {
+		t.Errorf("server.port = %q，期望 %q", cfg.Server.Port, "8080")
+	}
+	if cfg.Server.TLS.Autocert.HTTPPort != "80" {
+		t.Errorf("server.tls.autocert.http_port = %q，期望 %q", cfg.Server.TLS.Autocert.HTTPPort, "80")
+	}
+}
+
+func TestLoadUnquotedPortJSON(t *testing.T) {
+	cfg := loadTestConfig(t, "config.json", `{
+  "server": {"port": 8080},
+  "wework": {"token": "${TEST_WEWORK_TOKEN}", "aes_key": "${TEST_WEWORK_AES_KEY}"},
+  "llm": {"default": "local", "providers": {"local": {"provider": "ollama", "model": "qwen3:32b", "temperature": 0.3}}}
+}`)
+	if cfg.Server.Port != "8080" {
+		t.Errorf("server.port = %q，期望 %q", cfg.Server.Port, "8080")
+	}
+	if got := cfg.LLM.Providers["local"].Temperature; got != 0.3 {
+		t.Errorf("temperature = %v，期望 0.3", got)
+	}
+}
+
+func TestSchemaRejectsNonScalarString(t *testing.T) {
+	// 放宽只针对整数：布尔值和对象仍按类型不匹配报告
+	err := ValidateSchema([]byte("server:\n  port: true\n  host: {a: 1}\n"))
+	if err == nil {
+		t.Fatal("期望报告类型不匹配")
+	}
+	if problems := err.(*SchemaError).Problems; len(problems) != 2 {
+		t.Errorf("问题数 = %d，期望 2: %v", len(problems), problems)
+	}
+}
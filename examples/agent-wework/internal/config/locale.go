@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/i18n"
+)
+
+// applyLocaleDefaults 填充系统消息语言（未配置时为中文，en-US、English 等写法统一为语言代码）
+func applyLocaleDefaults(config *Config) {
+	if config.Locale == "" {
+		config.Locale = i18n.Default
+	}
+	if locale, ok := i18n.Parse(config.Locale); ok {
+		config.Locale = locale
+	}
+	for i := range config.Bots {
+		if locale, ok := i18n.Parse(config.Bots[i].Locale); ok {
+			config.Bots[i].Locale = locale
+		}
+	}
+}
+
+// validateLocale 验证系统消息语言（全局和各机器人）
+func validateLocale(config *Config) error {
+	if _, ok := i18n.Parse(config.Locale); !ok {
+		return fmt.Errorf("不支持的locale: %q（可选: %s）", config.Locale, strings.Join(i18n.Supported, ", "))
+	}
+	for _, b := range config.Bots {
+		if b.Locale == "" {
+			continue
+		}
+		if _, ok := i18n.Parse(b.Locale); !ok {
+			return fmt.Errorf("机器人 '%s' 不支持的locale: %q（可选: %s）", b.Name, b.Locale, strings.Join(i18n.Supported, ", "))
+		}
+	}
+	if err := checkMessages("messages", config.Messages); err != nil {
+		return err
+	}
+	for _, b := range config.Bots {
+		if err := checkMessages(fmt.Sprintf("机器人 '%s' 的messages", b.Name), b.Messages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkMessages 验证文案覆盖：键为已定义的消息键，语言后缀为支持的语言，除error_code外不能为空
+func checkMessages(field string, messages map[string]string) error {
+	for key, text := range messages {
+		name, locale, scoped := strings.Cut(key, ":")
+		if !i18n.Known(name) {
+			return fmt.Errorf("%s 中未知的消息键: %q", field, key)
+		}
+		if scoped && !slices.Contains(i18n.Supported, locale) {
+			return fmt.Errorf("%s 中 %q 的语言不支持（可选: %s）", field, key, strings.Join(i18n.Supported, ", "))
+		}
+		if strings.TrimSpace(text) == "" && i18n.Key(name) != i18n.ErrorCode {
+			return fmt.Errorf("%s 中 %q 不能为空", field, key)
+		}
+	}
+	return nil
+}
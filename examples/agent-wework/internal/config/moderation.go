@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultPolicyReply 用户消息被拦截时的默认回复
+const DefaultPolicyReply = "抱歉，您的消息包含不符合使用规范的内容，我无法处理。如有疑问请联系管理员。"
+
+// DefaultModerationReplacement AI回复仍不合规时的默认替换文本
+const DefaultModerationReplacement = "抱歉，这个问题我暂时无法回答。"
+
+// applyModerationDefaults 填充内容审核默认值
+func applyModerationDefaults(m *ModerationConfig) {
+	if m.Timeout == 0 {
+		m.Timeout = 5
+	}
+	if m.PolicyReply == "" {
+		m.PolicyReply = DefaultPolicyReply
+	}
+	if m.Replacement == "" {
+		m.Replacement = DefaultModerationReplacement
+	}
+	if m.MaxRegenerate == 0 {
+		m.MaxRegenerate = 1
+	}
+	if m.AuditLog == "" {
+		m.AuditLog = "data/moderation_audit.jsonl"
+	}
+}
+
+// validateModeration 验证内容审核配置（name用于错误信息，如 moderation 或 bots[sales].moderation）
+func validateModeration(config *Config, name string, m ModerationConfig) error {
+	if !m.Enabled {
+		return nil
+	}
+	if m.Input.Empty() && m.Output.Empty() {
+		return fmt.Errorf("启用%s时至少需要配置一条input或output规则", name)
+	}
+	stages := []struct {
+		name  string
+		rules ModerationRules
+	}{{"input", m.Input}, {"output", m.Output}}
+	for _, stage := range stages {
+		for _, pattern := range stage.rules.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%s.%s.patterns 正则无效 %q: %w", name, stage.name, pattern, err)
+			}
+		}
+	}
+	if m.LLMProvider != "" {
+		if _, ok := config.LLM.Providers[m.LLMProvider]; !ok {
+			return fmt.Errorf("%s引用的LLM提供商 '%s' 在配置中不存在", name, m.LLMProvider)
+		}
+	}
+	if m.MaxRegenerate < -1 {
+		return fmt.Errorf("%s.max_regenerate无效: %d", name, m.MaxRegenerate)
+	}
+	return nil
+}
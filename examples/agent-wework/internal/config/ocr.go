@@ -0,0 +1,42 @@
+package config
+
+import "fmt"
+
+// applyOCRDefaults 填充图片文字识别默认值
+func applyOCRDefaults(o *OCRConfig) {
+	if o.Timeout == 0 {
+		o.Timeout = 15
+	}
+	if o.MaxImages == 0 {
+		o.MaxImages = 3
+	}
+	if o.MaxSize == 0 {
+		o.MaxSize = 4096
+	}
+	if o.MaxChars == 0 {
+		o.MaxChars = 2000
+	}
+}
+
+// validateOCR 验证图片文字识别配置
+func validateOCR(o OCRConfig) error {
+	if !o.Enabled {
+		return nil
+	}
+	switch o.Provider {
+	case "paddleocr":
+		if o.BaseURL == "" {
+			return fmt.Errorf("ocr.provider 为 paddleocr 时必须配置ocr.base_url")
+		}
+	case "baidu":
+		if o.APIKey == "" || o.SecretKey == "" {
+			return fmt.Errorf("ocr.provider 为 baidu 时必须配置ocr.api_key和ocr.secret_key")
+		}
+	default:
+		return fmt.Errorf("不支持的OCR服务: %q（可选 paddleocr、baidu）", o.Provider)
+	}
+	if o.Timeout < 0 || o.MaxImages < 0 || o.MaxSize < 0 || o.MaxChars < 0 {
+		return fmt.Errorf("ocr.timeout、max_images、max_size、max_chars 不能为负数")
+	}
+	return nil
+}
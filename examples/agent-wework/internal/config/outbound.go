@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// OutboundEvents 出站Webhook支持的事件
+var OutboundEvents = []string{"conversation_started", "negative_feedback", "handoff_requested", "tool_failed"}
+
+// applyOutboundDefaults 填充出站Webhook默认值
+func applyOutboundDefaults(o *OutboundConfig) {
+	if o.Timeout == 0 {
+		o.Timeout = 10
+	}
+	if o.Retries == 0 {
+		o.Retries = 2
+	}
+	if o.QueueSize == 0 {
+		o.QueueSize = 1000
+	}
+}
+
+// validateOutbound 验证出站Webhook配置
+func validateOutbound(o OutboundConfig) error {
+	if !o.Enabled {
+		return nil
+	}
+	if len(o.Endpoints) == 0 {
+		return fmt.Errorf("启用出站Webhook时必须配置outbound_webhooks.endpoints")
+	}
+	if o.Timeout < 0 || o.Retries < 0 || o.QueueSize < 0 {
+		return fmt.Errorf("outbound_webhooks.timeout、retries、queue_size 不能为负数")
+	}
+
+	known := make(map[string]bool, len(OutboundEvents))
+	for _, name := range OutboundEvents {
+		known[name] = true
+	}
+	names := make(map[string]bool, len(o.Endpoints))
+	for i, e := range o.Endpoints {
+		if e.Name == "" {
+			return fmt.Errorf("第%d个出站Webhook缺少name", i+1)
+		}
+		if names[e.Name] {
+			return fmt.Errorf("出站Webhook名称重复: %s", e.Name)
+		}
+		names[e.Name] = true
+
+		u, err := url.Parse(e.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("出站Webhook '%s' 的url无效（需为http或https地址）", e.Name)
+		}
+		for _, event := range e.Events {
+			if !known[event] {
+				return fmt.Errorf("出站Webhook '%s' 订阅了未知事件: %s（支持 %v）", e.Name, event, OutboundEvents)
+			}
+		}
+	}
+	return nil
+}
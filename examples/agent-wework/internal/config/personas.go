@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// PersonaReset /persona 命令中表示恢复默认人设的参数（不能用作人设名）
+const PersonaReset = "reset"
+
+// Title 人设的展示名称
+func (p PersonaConfig) Title(name string) string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return name
+}
+
+// Apply 将人设应用到功能开关上
+func (p PersonaConfig) Apply(f Features) Features {
+	if prompt := strings.TrimSpace(p.SystemPrompt); prompt != "" {
+		f.SystemPrompt = prompt
+	}
+	if p.LLMProvider != "" {
+		f.LLMProvider = p.LLMProvider
+	}
+	if len(p.Tools) > 0 {
+		f.AllowedTools = append([]string(nil), p.Tools...)
+	}
+	if len(p.MCPServers) > 0 {
+		f.MCPServers = append([]string(nil), p.MCPServers...)
+	}
+	return f
+}
+
+// validatePersonas 验证人设配置及其引用
+func validatePersonas(config *Config, mcpNames map[string]bool) error {
+	for name, persona := range config.Personas {
+		if name == "" || name == PersonaReset || strings.ContainsAny(name, " \t\n") {
+			return fmt.Errorf("人设名称无效: '%s'（不能为空、包含空白或使用保留名 %s）", name, PersonaReset)
+		}
+		if persona.LLMProvider != "" {
+			if _, ok := config.LLM.Providers[persona.LLMProvider]; !ok {
+				return fmt.Errorf("人设 '%s' 引用的LLM提供商 '%s' 在配置中不存在", name, persona.LLMProvider)
+			}
+		}
+		for _, server := range persona.MCPServers {
+			if !mcpNames[server] {
+				return fmt.Errorf("人设 '%s' 引用的MCP服务器 '%s' 在配置中不存在", name, server)
+			}
+		}
+	}
+
+	if config.DefaultPersona != "" {
+		if _, ok := config.Personas[config.DefaultPersona]; !ok {
+			return fmt.Errorf("default_persona '%s' 在personas中不存在", config.DefaultPersona)
+		}
+	}
+	for chatID, group := range config.Groups {
+		if group.Persona == "" {
+			continue
+		}
+		if _, ok := config.Personas[group.Persona]; !ok {
+			return fmt.Errorf("群聊 '%s' 引用的人设 '%s' 在personas中不存在", chatID, group.Persona)
+		}
+	}
+	return nil
+}
+
+// DefaultRouterTimeout 路由模型默认超时（秒）
+const DefaultRouterTimeout = 10
+
+// SpecialistNames 参与路由的专家名称（未配置时为全部人设，按名称排序）
+func (c *Config) SpecialistNames() []string {
+	if len(c.Router.Specialists) > 0 {
+		return c.Router.Specialists
+	}
+	names := make([]string, 0, len(c.Personas))
+	for name := range c.Personas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateRouter 验证多智能体路由配置
+func validateRouter(config *Config) error {
+	r := config.Router
+	if !r.Enabled {
+		return nil
+	}
+	if len(config.Personas) < 2 {
+		return fmt.Errorf("启用多智能体路由时至少需要配置两个人设（personas）作为专家")
+	}
+	for _, name := range r.Specialists {
+		if _, ok := config.Personas[name]; !ok {
+			return fmt.Errorf("router.specialists 引用的人设 '%s' 在personas中不存在", name)
+		}
+	}
+	if r.Fallback != "" && !slices.Contains(config.SpecialistNames(), r.Fallback) {
+		return fmt.Errorf("router.fallback '%s' 不在参与路由的专家中", r.Fallback)
+	}
+	if r.LLMProvider != "" {
+		if _, ok := config.LLM.Providers[r.LLMProvider]; !ok {
+			return fmt.Errorf("router 引用的LLM提供商 '%s' 在配置中不存在", r.LLMProvider)
+		}
+	}
+	return nil
+}
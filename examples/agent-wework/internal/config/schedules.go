@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/scheduler"
+)
+
+// DefaultScheduleTimeout 定时任务默认执行超时（秒）
+const DefaultScheduleTimeout = 300
+
+// DefaultScheduleCategory 定时任务默认的通知类别
+const DefaultScheduleCategory = "schedule"
+
+// Location 任务使用的时区（未配置或无效时为服务器本地时区）
+func (s ScheduleConfig) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// ParseCron 解析任务的cron表达式（按配置的时区计算触发时间）
+func (s ScheduleConfig) ParseCron() (*scheduler.Cron, error) {
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return nil, fmt.Errorf("时区无效: %s", s.Timezone)
+		}
+	}
+	return scheduler.ParseCron(s.Cron, s.Location())
+}
+
+// validateSchedules 验证定时任务配置
+func validateSchedules(config *Config) error {
+	if len(config.Schedules) == 0 {
+		return nil
+	}
+	if !config.Notify.Enabled {
+		return fmt.Errorf("定时任务通过主动通知推送结果，需启用notify")
+	}
+
+	bots := make(map[string]bool)
+	for _, b := range config.BotConfigs() {
+		bots[b.Name] = true
+	}
+
+	names := make(map[string]bool)
+	for i, s := range config.Schedules {
+		if s.Name == "" {
+			return fmt.Errorf("第%d个定时任务缺少name", i+1)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("定时任务名称重复: %s", s.Name)
+		}
+		names[s.Name] = true
+
+		cron, err := s.ParseCron()
+		if err != nil {
+			return fmt.Errorf("定时任务 '%s': %w", s.Name, err)
+		}
+		if cron.Next(time.Now()).IsZero() {
+			return fmt.Errorf("定时任务 '%s' 的cron表达式永远不会触发: %s", s.Name, s.Cron)
+		}
+		if !strings.HasPrefix(s.Target, "group_") && !strings.HasPrefix(s.Target, "single_") {
+			return fmt.Errorf("定时任务 '%s' 的target必须以 group_ 或 single_ 开头", s.Name)
+		}
+		if strings.TrimSpace(s.Prompt) == "" {
+			return fmt.Errorf("定时任务 '%s' 缺少prompt", s.Name)
+		}
+		if p := s.Persona.LLMProvider; p != "" {
+			if _, ok := config.LLM.Providers[p]; !ok {
+				return fmt.Errorf("定时任务 '%s' 引用的LLM提供商 '%s' 在配置中不存在", s.Name, p)
+			}
+		}
+		if s.Bot != "" && !bots[s.Bot] {
+			return fmt.Errorf("定时任务 '%s' 引用的机器人 '%s' 在配置中不存在", s.Name, s.Bot)
+		}
+	}
+	return nil
+}
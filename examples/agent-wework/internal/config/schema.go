@@ -0,0 +1,286 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema 配置文件的JSON Schema（由Config结构体生成）
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false 或 *Schema
+}
+
+// GenerateSchema 由Config结构体生成JSON Schema
+func GenerateSchema() *Schema {
+	schema := schemaFor(reflect.TypeOf(Config{}))
+	schema.SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "AI-Body 企业微信机器人配置"
+	schema.Properties["$schema"] = &Schema{Type: "string"} // 允许配置文件引用Schema以获得编辑器提示
+	return schema
+}
+
+// MarshalSchema 生成格式化的JSON Schema文本
+func MarshalSchema() ([]byte, error) {
+	return json.MarshalIndent(GenerateSchema(), "", "  ")
+}
+
+// schemaFor 按Go类型生成Schema
+func schemaFor(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			s.Properties[name] = schemaFor(field.Type)
+		}
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// SchemaError 配置不符合Schema的错误（包含全部问题）
+type SchemaError struct {
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return "配置不符合规范:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// ValidateSchema 按Schema严格校验配置内容（JSON与YAML均按YAML解析以获取行号）
+// 报告未知配置项和类型不匹配；内容无法解析时不报错，交由后续解码报告语法错误
+func ValidateSchema(data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+
+	var problems []string
+	GenerateSchema().validate(root.Content[0], "", &problems)
+	if len(problems) > 0 {
+		return &SchemaError{Problems: problems}
+	}
+	return nil
+}
+
+// validate 递归校验节点
+func (s *Schema) validate(node *yaml.Node, path string, problems *[]string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, fmt.Sprintf("第%d行 %s: %s", node.Line, displayPath(path), fmt.Sprintf(format, args...)))
+	}
+
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			report("应为对象，实际为%s", nodeTypeName(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := joinPath(path, key.Value)
+
+			if prop, ok := s.Properties[key.Value]; ok {
+				prop.validate(value, childPath, problems)
+				continue
+			}
+			if additional, ok := s.AdditionalProperties.(*Schema); ok {
+				additional.validate(value, childPath, problems)
+				continue
+			}
+
+			msg := fmt.Sprintf("第%d行 %s: 未知配置项", key.Line, displayPath(childPath))
+			if suggestion := s.suggest(key.Value); suggestion != "" {
+				msg += fmt.Sprintf("（是否应为 %s？）", suggestion)
+			}
+			*problems = append(*problems, msg)
+		}
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			report("应为数组，实际为%s", nodeTypeName(node))
+			return
+		}
+		for i, item := range node.Content {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+
+	case "string":
+		// 未加引号的整数（如 port: 8080）按字符串接受，解码时由coerceStrings转换
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!str" && node.Tag != "!!int") {
+			report("应为字符串，实际为%s", nodeTypeName(node))
+		}
+
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			report("应为布尔值，实际为%s", nodeTypeName(node))
+		}
+
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			report("应为整数，实际为%s", nodeTypeName(node))
+		}
+
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			report("应为数字，实际为%s", nodeTypeName(node))
+		}
+	}
+}
+
+// coerceStrings 将字符串类型字段中的整数转换为字符串（如YAML中未加引号的 port: 8080），
+// v为解析后的通用结构（JSON需使用UseNumber解析），就地修改并返回
+func (s *Schema) coerceStrings(v interface{}) interface{} {
+	switch s.Type {
+	case "string":
+		switch n := v.(type) {
+		case int, int64, uint64:
+			return fmt.Sprint(n)
+		case json.Number:
+			if _, err := n.Int64(); err == nil {
+				return n.String()
+			}
+		}
+
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for key, item := range m {
+			if prop, ok := s.Properties[key]; ok {
+				m[key] = prop.coerceStrings(item)
+			} else if additional, ok := s.AdditionalProperties.(*Schema); ok {
+				m[key] = additional.coerceStrings(item)
+			}
+		}
+
+	case "array":
+		if items, ok := v.([]interface{}); ok {
+			for i, item := range items {
+				items[i] = s.Items.coerceStrings(item)
+			}
+		}
+	}
+	return v
+}
+
+// suggest 为未知配置项寻找最相近的已知字段名（忽略大小写和分隔符后相同，或编辑距离不超过2）
+func (s *Schema) suggest(key string) string {
+	normalize := func(v string) string {
+		return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(v))
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if normalize(name) == normalize(key) {
+			return name
+		}
+		if d := editDistance(name, key); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// nodeTypeName 节点类型的中文描述
+func nodeTypeName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "对象"
+	case yaml.SequenceNode:
+		return "数组"
+	}
+	switch node.Tag {
+	case "!!str":
+		return fmt.Sprintf("字符串 %q", node.Value)
+	case "!!bool":
+		return "布尔值 " + node.Value
+	case "!!int", "!!float":
+		return "数字 " + node.Value
+	}
+	return node.Value
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(根)"
+	}
+	return path
+}
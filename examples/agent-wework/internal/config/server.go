@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultRoutePrefix 所有路由的默认前缀
+const DefaultRoutePrefix = "/b0dy"
+
+// applyServerDefaults 填充服务器默认值（路由前缀需先于各渠道的默认路由填充）
+func applyServerDefaults(s *ServerConfig) {
+	if s.Prefix == "" {
+		s.Prefix = DefaultRoutePrefix
+	}
+	if s.WebhookPath == "" {
+		s.WebhookPath = s.Route(DefaultWebhookPath)
+	}
+	if s.HealthPath == "" {
+		s.HealthPath = s.Route("/health")
+	}
+	if s.MaxBodySize == 0 {
+		s.MaxBodySize = 256
+	}
+	if s.ReadTimeout == 0 {
+		s.ReadTimeout = 15
+	}
+	if s.WriteTimeout == 0 {
+		s.WriteTimeout = 60
+	}
+	if s.IdleTimeout == 0 {
+		s.IdleTimeout = 120
+	}
+	if s.MaxHeaderSize == 0 {
+		s.MaxHeaderSize = 64
+	}
+	if s.TLS.Autocert.Enabled {
+		if s.TLS.Autocert.CacheDir == "" {
+			s.TLS.Autocert.CacheDir = "data/autocert"
+		}
+		if s.TLS.Autocert.HTTPPort == "" {
+			s.TLS.Autocert.HTTPPort = "80"
+		}
+	}
+}
+
+// Addr 监听地址（host:port）
+func (s ServerConfig) Addr() string {
+	return net.JoinHostPort(s.Host, s.Port)
+}
+
+// Route 在路由前缀下拼接路径（前缀为 / 时挂在根路径下）
+func (s ServerConfig) Route(path string) string {
+	return strings.TrimSuffix(s.Prefix, "/") + path
+}
+
+// AdminPath 管理接口的路由组
+func (s ServerConfig) AdminPath() string {
+	return s.Route("/admin")
+}
+
+// validateServer 验证服务器配置
+func validateServer(config *Config) error {
+	s := config.Server
+	if s.Port == "" {
+		return fmt.Errorf("服务端口不能为空")
+	}
+	if s.MaxBodySize < 0 || s.MaxHeaderSize < 0 {
+		return fmt.Errorf("server.max_body_size 和 server.max_header_size 不能为负数")
+	}
+	if s.ReadTimeout < 0 || s.WriteTimeout < 0 || s.IdleTimeout < 0 {
+		return fmt.Errorf("server.read_timeout、write_timeout、idle_timeout 不能为负数")
+	}
+	// IPv6地址直接填写（如 ::1），由Addr加上括号
+	if strings.ContainsAny(s.Host, "[]/ ") || strings.Contains(s.Host, ":") && net.ParseIP(s.Host) == nil {
+		return fmt.Errorf("server.host 无效（只填写IP或主机名，不含端口和括号）: %s", s.Host)
+	}
+
+	routes := []struct {
+		name string
+		path string
+	}{
+		{"server.prefix", s.Prefix},
+		{"server.webhook_path", s.WebhookPath},
+		{"server.health_path", s.HealthPath},
+	}
+	for _, r := range routes {
+		if !strings.HasPrefix(r.path, "/") {
+			return fmt.Errorf("%s 必须以 / 开头: %s", r.name, r.path)
+		}
+	}
+	// 存活、就绪探针挂在健康检查路由下
+	if strings.HasSuffix(s.HealthPath, "/") {
+		return fmt.Errorf("server.health_path 不能以 / 结尾: %s", s.HealthPath)
+	}
+	for _, b := range config.BotConfigs() {
+		if b.Path == s.HealthPath || strings.HasPrefix(b.Path, s.HealthPath+"/") {
+			return fmt.Errorf("机器人 %s 的Webhook路由与健康检查路由冲突: %s", b.Name, b.Path)
+		}
+		if config.Admin.Enabled && strings.HasPrefix(b.Path, s.AdminPath()+"/") {
+			return fmt.Errorf("机器人 %s 的Webhook路由与管理接口冲突: %s", b.Name, b.Path)
+		}
+	}
+
+	return validateTLS(s.TLS)
+}
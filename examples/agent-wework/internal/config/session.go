@@ -0,0 +1,14 @@
+package config
+
+import "fmt"
+
+// validateSession 验证会话配置
+func validateSession(s SessionConfig) error {
+	if s.IdleReset < 0 {
+		return fmt.Errorf("session.idle_reset 不能为负数")
+	}
+	if s.Notice && s.IdleReset == 0 {
+		return fmt.Errorf("session.notice 需要配置 session.idle_reset")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSlackPath Slack Events API的默认路由（位于server.prefix下）
+const DefaultSlackPath = "/slack/events"
+
+// DefaultSlackEditInterval Slack流式回复的默认编辑间隔（毫秒）
+const DefaultSlackEditInterval = 1000
+
+// applySlackDefaults 填充Slack渠道默认值
+func applySlackDefaults(s *SlackConfig, server ServerConfig) {
+	if s.Path == "" {
+		s.Path = server.Route(DefaultSlackPath)
+	}
+	if s.EditInterval == 0 {
+		s.EditInterval = DefaultSlackEditInterval
+	}
+}
+
+// validateSlack 验证Slack渠道配置
+func validateSlack(config *Config) error {
+	s := config.Slack
+	if !s.Enabled {
+		return nil
+	}
+	if !strings.HasPrefix(s.BotToken, "xoxb-") {
+		return fmt.Errorf("启用Slack时必须配置slack.bot_token（xoxb-开头）")
+	}
+	if s.AppToken == "" && s.SigningSecret == "" {
+		return fmt.Errorf("启用Slack时必须配置slack.app_token（Socket Mode）或slack.signing_secret（Events API）")
+	}
+	if s.AppToken != "" && !strings.HasPrefix(s.AppToken, "xapp-") {
+		return fmt.Errorf("slack.app_token 必须以 xapp- 开头")
+	}
+	if !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("slack.path 必须以 / 开头: %s", s.Path)
+	}
+	if s.EditInterval < 200 {
+		return fmt.Errorf("slack.edit_interval 不能小于200毫秒")
+	}
+	if config.ChannelBot(s.Bot) == "" {
+		return fmt.Errorf("slack.bot 引用的机器人 '%s' 在配置中不存在", s.Bot)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import "fmt"
+
+// applySummaryDefaults 填充会话摘要默认值
+func applySummaryDefaults(s *SummaryConfig) {
+	if s.IdleAfter == 0 {
+		s.IdleAfter = 30
+	}
+}
+
+// validateSummary 验证会话摘要配置
+func validateSummary(config *Config) error {
+	s := config.Summary
+	if !s.Enabled {
+		return nil
+	}
+	if s.IdleAfter < 1 {
+		return fmt.Errorf("summary.idle_after 必须大于0")
+	}
+	if s.LLMProvider != "" {
+		if _, ok := config.LLM.Providers[s.LLMProvider]; !ok {
+			return fmt.Errorf("summary 引用的LLM提供商 '%s' 在配置中不存在", s.LLMProvider)
+		}
+	}
+	if s.Remember && !config.Profile.Enabled {
+		return fmt.Errorf("summary.remember 需要启用 profile")
+	}
+	return nil
+}
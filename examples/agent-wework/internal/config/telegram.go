@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultTelegramPath Telegram Webhook的默认路由（位于server.prefix下）
+const DefaultTelegramPath = "/telegram/webhook"
+
+// DefaultTelegramEditInterval Telegram流式回复的默认编辑间隔（毫秒）
+const DefaultTelegramEditInterval = 1000
+
+// telegramSecretRegex Webhook校验令牌允许的字符（Telegram限制）
+var telegramSecretRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// applyTelegramDefaults 填充Telegram渠道默认值
+func applyTelegramDefaults(t *TelegramConfig, server ServerConfig) {
+	if t.Path == "" {
+		t.Path = server.Route(DefaultTelegramPath)
+	}
+	if t.EditInterval == 0 {
+		t.EditInterval = DefaultTelegramEditInterval
+	}
+}
+
+// validateTelegram 验证Telegram渠道配置
+func validateTelegram(config *Config) error {
+	t := config.Telegram
+	if !t.Enabled {
+		return nil
+	}
+	if t.Token == "" {
+		return fmt.Errorf("启用Telegram时必须配置telegram.token")
+	}
+	if t.WebhookURL != "" {
+		if !strings.HasPrefix(t.WebhookURL, "https://") {
+			return fmt.Errorf("telegram.webhook_url 必须以 https:// 开头: %s", t.WebhookURL)
+		}
+		if !telegramSecretRegex.MatchString(t.SecretToken) {
+			return fmt.Errorf("使用Webhook时必须配置telegram.secret_token（1-256位字母、数字、_或-）")
+		}
+	}
+	if !strings.HasPrefix(t.Path, "/") {
+		return fmt.Errorf("telegram.path 必须以 / 开头: %s", t.Path)
+	}
+	if t.EditInterval < 200 {
+		return fmt.Errorf("telegram.edit_interval 不能小于200毫秒")
+	}
+	if config.ChannelBot(t.Bot) == "" {
+		return fmt.Errorf("telegram.bot 引用的机器人 '%s' 在配置中不存在", t.Bot)
+	}
+	return nil
+}
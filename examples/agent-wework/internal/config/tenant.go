@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultOrgID 未配置org_id且无法从企业ID推导时使用的组织ID（与早期版本的固定值一致）
+const DefaultOrgID = "wework-org"
+
+// orgIDPattern 组织ID用于Redis键和文件名，只允许字母、数字和 _ . -
+var orgIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// Org 组织（租户）ID：依次取org_id、directory.corp_id、kf.corp_id，均未配置时为DefaultOrgID
+//
+// 会话记忆、日志、共享状态和消息去重的键都按组织隔离，不同组织的机器人不会读到彼此的数据。
+func (c *Config) Org() string {
+	switch {
+	case c.OrgID != "":
+		return c.OrgID
+	case c.Directory.CorpID != "":
+		return c.Directory.CorpID
+	case c.KF.CorpID != "":
+		return c.KF.CorpID
+	default:
+		return DefaultOrgID
+	}
+}
+
+// MultiTenant 是否为多租户模式：存在组织与全局组织不同的机器人
+//
+// 多租户模式下各机器人的聊天日志、转人工、长任务、预算、统计等数据按组织和机器人分开保存，
+// 用户画像和知识库按组织分文件（全局组织的机器人也是如此）。
+func (c *Config) MultiTenant() bool {
+	org := c.Org()
+	for _, b := range c.Bots {
+		if b.OrgID != "" && b.OrgID != org {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOrg 验证组织ID（全局和各机器人）
+func validateOrg(config *Config) error {
+	if err := checkOrgID("org_id", config.Org()); err != nil {
+		return err
+	}
+	for _, b := range config.Bots {
+		if b.OrgID == "" {
+			continue
+		}
+		if err := checkOrgID(fmt.Sprintf("机器人 '%s' 的org_id", b.Name), b.OrgID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOrgID 组织ID只能包含字母、数字和 _ . -，最长64个字符
+func checkOrgID(field, orgID string) error {
+	if !orgIDPattern.MatchString(orgID) {
+		return fmt.Errorf("%s 无效（只能包含字母、数字和 _ . -，最长64个字符）: %q", field, orgID)
+	}
+	return nil
+}
+
+// scopedPath 在文件名后追加后缀区分机器人或组织：data/budget.json -> data/budget_<suffix>.json
+func scopedPath(path, suffix string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + suffix + ext
+}
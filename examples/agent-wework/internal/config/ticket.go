@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DefaultFeishuProjectURL 飞书项目开放接口的默认地址
+const DefaultFeishuProjectURL = "https://project.feishu.cn"
+
+// applyTicketDefaults 填充工单工具默认值
+func applyTicketDefaults(t *TicketConfig) {
+	switch t.Provider {
+	case "jira":
+		if t.Type == "" {
+			t.Type = "Task"
+		}
+	case "feishu_project":
+		if t.Type == "" {
+			t.Type = "issue"
+		}
+		if t.BaseURL == "" {
+			t.BaseURL = DefaultFeishuProjectURL
+		}
+	case "servicenow":
+		if t.Type == "" {
+			t.Type = "incident"
+		}
+	}
+	if t.Timeout == 0 {
+		t.Timeout = 15
+	}
+}
+
+// validateTicket 验证工单工具配置
+func validateTicket(t TicketConfig) error {
+	if !t.Enabled {
+		return nil
+	}
+	switch t.Provider {
+	case "jira":
+		if t.Project == "" {
+			return fmt.Errorf("使用Jira时必须配置ticket.project")
+		}
+	case "feishu_project":
+		if t.Project == "" || t.PluginID == "" || t.Username == "" {
+			return fmt.Errorf("使用飞书项目时必须配置ticket.project、ticket.plugin_id和ticket.username（提单人user_key）")
+		}
+	case "servicenow":
+		if t.Username == "" {
+			return fmt.Errorf("使用ServiceNow时必须配置ticket.username")
+		}
+	default:
+		return fmt.Errorf("不支持的工单系统: %q（可选 jira、feishu_project、servicenow）", t.Provider)
+	}
+	if t.Token == "" {
+		return fmt.Errorf("启用工单工具时必须配置ticket.token")
+	}
+	u, err := url.Parse(t.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("ticket.base_url 无效（需为http或https地址）: %s", t.BaseURL)
+	}
+	if t.Timeout < 0 {
+		return fmt.Errorf("ticket.timeout 不能为负数")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// applyTTSDefaults 填充语音回复默认值，Webhook未配置时沿用主动通知的群机器人
+func applyTTSDefaults(t *TTSConfig, notify NotifyConfig) {
+	if t.Model == "" {
+		t.Model = "tts-1"
+	}
+	if t.Voice == "" {
+		t.Voice = "alloy"
+	}
+	if t.Format == "" {
+		t.Format = "mp3"
+	}
+	if t.FFmpeg == "" {
+		t.FFmpeg = "ffmpeg"
+	}
+	if t.WebhookURL == "" {
+		t.WebhookURL = notify.WebhookURL
+	}
+	if t.MaxChars == 0 {
+		t.MaxChars = 300
+	}
+	if t.Timeout == 0 {
+		t.Timeout = 30
+	}
+}
+
+// validateTTS 验证语音回复配置
+func validateTTS(t TTSConfig) error {
+	if !t.Enabled {
+		return nil
+	}
+	if t.BaseURL == "" {
+		return fmt.Errorf("启用语音回复时必须配置tts.base_url")
+	}
+	if t.WebhookURL == "" {
+		return fmt.Errorf("启用语音回复时必须配置tts.webhook_url或notify.webhook_url")
+	}
+	if !strings.Contains(t.WebhookURL, "/webhook/send") {
+		return fmt.Errorf("tts.webhook_url 必须是群机器人Webhook地址（.../cgi-bin/webhook/send?key=...）")
+	}
+	if t.MaxChars < 0 || t.Timeout < 0 {
+		return fmt.Errorf("tts.max_chars、timeout 不能为负数")
+	}
+	return nil
+}
@@ -2,11 +2,58 @@ package config
 
 // Config 完整的应用配置
 type Config struct {
-	WeWork  WeWorkConfig  `json:"wework"`
-	LLM     LLMConfigs    `json:"llm"`
-	MCP     MCPConfigs    `json:"mcp"`
-	Server  ServerConfig  `json:"server"`
-	Logging LoggingConfig `json:"logging"`
+	WeWork        WeWorkConfig              `json:"wework"`
+	KF            KFConfig                  `json:"kf"`
+	Slack         SlackConfig               `json:"slack"`
+	Telegram      TelegramConfig            `json:"telegram"`
+	LLM           LLMConfigs                `json:"llm"`
+	MCP           MCPConfigs                `json:"mcp"`
+	Server        ServerConfig              `json:"server"`
+	Logging       LoggingConfig             `json:"logging"`
+	Stream        StreamConfig              `json:"stream"`
+	WarmPool      WarmPoolConfig            `json:"warm_pool"`
+	Translation   TranslationConfig         `json:"translation"`
+	OCR           OCRConfig                 `json:"ocr"`
+	TTS           TTSConfig                 `json:"tts"`
+	ImageGen      ImageGenConfig            `json:"image_gen"`
+	Ticket        TicketConfig              `json:"ticket"`
+	Directory     DirectoryConfig           `json:"directory"`
+	Jobs          JobsConfig                `json:"jobs"`
+	Summary       SummaryConfig             `json:"summary"`
+	Session       SessionConfig             `json:"session"`
+	Budget        BudgetConfig              `json:"budget"`
+	Profile       ProfileConfig             `json:"profile"`
+	Preferences   PreferencesConfig         `json:"preferences"`
+	Knowledge     KnowledgeConfig           `json:"knowledge"`
+	Groups        map[string]GroupConfig    `json:"groups,omitempty"`    // 群聊级配置覆盖（key为群ChatID）
+	Overrides     map[string]OverrideConfig `json:"overrides,omitempty"` // 会话级覆盖（key为 group_<chatid> 或 single_<userid>）
+	Personas      map[string]PersonaConfig  `json:"personas,omitempty"`  // 命名人设（key为人设名，用户可通过 /persona 切换）
+	Router        RouterConfig              `json:"router"`
+	Experiment    ExperimentConfig          `json:"experiment"`
+	Notify        NotifyConfig              `json:"notify"`
+	ErrorReport   ErrorReportConfig         `json:"error_report"`
+	Outbound      OutboundConfig            `json:"outbound_webhooks"`
+	Fetch         FetchConfig               `json:"fetch"`
+	Moderation    ModerationConfig          `json:"moderation"`
+	BusinessHours BusinessHoursConfig       `json:"business_hours"`
+	Welcome       WelcomeConfig             `json:"welcome"`
+	Handoff       HandoffConfig             `json:"handoff"`
+	Approval      ApprovalConfig            `json:"approval"`
+	Analytics     AnalyticsConfig           `json:"analytics"`
+	Audit         AuditConfig               `json:"audit"`
+	Health        HealthConfig              `json:"health"`
+	Dedup         DedupConfig               `json:"dedup"`
+	Cluster       ClusterConfig             `json:"cluster"`
+	Queue         QueueConfig               `json:"queue"`
+	Admin         AdminConfig               `json:"admin"`
+	Bots          []BotConfig               `json:"bots,omitempty"`      // 同一进程托管的多个机器人（为空时使用顶层wework配置）
+	Schedules     []ScheduleConfig          `json:"schedules,omitempty"` // 定时任务：到点运行Agent并主动推送结果
+	Messages      map[string]string         `json:"messages,omitempty"`  // 覆盖系统消息文案：键为消息键（如 pending），"<键>:<语言>" 只覆盖该语言
+
+	OrgID          string `json:"org_id,omitempty"`          // 组织（租户）ID，会话记忆、日志和共享存储按组织隔离（默认取企业ID）
+	Locale         string `json:"locale,omitempty"`          // 系统消息语言：zh、en、ja（默认zh，用户在 /settings 中设置的回复语言优先）
+	DefaultPersona string `json:"default_persona,omitempty"` // 默认人设（为空时使用全局系统提示词和工具，群聊可单独配置）
+	StrictSecrets  bool   `json:"strict_secrets,omitempty"`  // 严格密钥模式：敏感字段只能来自环境变量或密钥后端
 }
 
 // WeWorkConfig 企业微信配置
@@ -50,6 +97,10 @@ type MCPServerConfig struct {
 	Path    string `json:"path,omitempty"`
 	Token   string `json:"token,omitempty"`
 
+	// 录制/回放（仅HTTP类型）：record 录制真实调用，playback 离线回放
+	VCR      string `json:"vcr,omitempty"`
+	Cassette string `json:"cassette,omitempty"` // 录制文件路径（默认 data/cassettes/<name>.json）
+
 	// Stdio类型配置
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
@@ -58,11 +109,513 @@ type MCPServerConfig struct {
 
 // ServerConfig HTTP服务器配置
 type ServerConfig struct {
-	Port string `json:"port"`
+	Host        string    `json:"host,omitempty"` // 监听地址（默认监听所有网卡，如 127.0.0.1 仅接受本机反向代理转发）
+	Port        string    `json:"port"`
+	TLS         TLSConfig `json:"tls,omitempty"`           // HTTPS配置（未启用时使用HTTP）
+	MaxBodySize int       `json:"max_body_size,omitempty"` // 企业微信/微信客服回调请求体大小上限（KB，默认256）
+
+	// HTTP服务器参数：限制慢连接占用资源
+	ReadTimeout   int `json:"read_timeout,omitempty"`    // 读取请求头和请求体的超时（秒，默认15）
+	WriteTimeout  int `json:"write_timeout,omitempty"`   // 从读完请求头到写完应答的超时（秒，默认60，需大于pprof采样时长）
+	IdleTimeout   int `json:"idle_timeout,omitempty"`    // Keep-Alive连接的空闲超时（秒，默认120）
+	MaxHeaderSize int `json:"max_header_size,omitempty"` // 请求头大小上限（KB，默认64）
+
+	// 路由：前缀同时作用于管理接口（<prefix>/admin）和各渠道的默认回调路由
+	Prefix      string `json:"prefix,omitempty"`       // 路由前缀（默认 /b0dy，设为 / 时挂在根路径下）
+	WebhookPath string `json:"webhook_path,omitempty"` // 单机器人模式的Webhook路由（默认 <prefix>/webhook）
+	HealthPath  string `json:"health_path,omitempty"`  // 健康检查路由（默认 <prefix>/health，探针为其下的 /live、/ready）
+}
+
+// TLSConfig HTTPS配置：指定证书文件，或使用Let's Encrypt自动签发（二选一）
+type TLSConfig struct {
+	CertFile string `json:"cert_file,omitempty"` // 证书文件路径（PEM）
+	KeyFile  string `json:"key_file,omitempty"`  // 私钥文件路径（PEM）
+
+	Autocert AutocertConfig `json:"autocert,omitempty"` // Let's Encrypt自动证书
+}
+
+// AutocertConfig Let's Encrypt自动证书配置
+type AutocertConfig struct {
+	Enabled  bool     `json:"enabled"`             // 是否启用自动证书
+	Domains  []string `json:"domains,omitempty"`   // 允许签发证书的域名（必填）
+	Email    string   `json:"email,omitempty"`     // 证书到期通知邮箱（可选）
+	CacheDir string   `json:"cache_dir,omitempty"` // 证书缓存目录（默认 data/autocert）
+	HTTPPort string   `json:"http_port,omitempty"` // HTTP-01验证及HTTP跳转端口（默认80，设为off关闭）
+}
+
+// Enabled 是否启用HTTPS
+func (t TLSConfig) Enabled() bool {
+	return t.Autocert.Enabled || t.CertFile != "" || t.KeyFile != ""
 }
 
 // LoggingConfig 日志配置
 type LoggingConfig struct {
-	Enabled bool   `json:"enabled"` // 是否启用日志
-	LogDir  string `json:"log_dir"` // 日志目录
+	Enabled bool   `json:"enabled"`          // 是否启用聊天记录日志
+	LogDir  string `json:"log_dir"`          // 聊天记录日志目录
+	Driver  string `json:"driver,omitempty"` // 聊天记录存储: file（默认，按会话写文本文件）或 sqlite（<log_dir>/chat.db）
+	Level   string `json:"level,omitempty"`  // 运行日志级别: debug、info（默认）、warn、error
+	Format  string `json:"format,omitempty"` // 运行日志格式: text（默认）或 json
+}
+
+// StreamConfig 流式输出配置
+type StreamConfig struct {
+	MaxResumeAttempts int                `json:"max_resume_attempts"`        // 流式中断后的最大续传次数（0使用默认值，-1禁用）
+	MaxRetries        int                `json:"max_retries,omitempty"`      // 尚未输出内容时Agent运行失败（模型服务错误、网络中断）的最大重试次数（0使用默认值，-1禁用）
+	RetryBackoff      int                `json:"retry_backoff,omitempty"`    // 首次重试前的等待秒数，之后每次加倍（0使用默认值）
+	InterimAfter      int                `json:"interim_after,omitempty"`    // 无可见内容多少秒后显示进度提示（0使用默认值，-1禁用）
+	InterimMessages   map[string]string  `json:"interim_messages,omitempty"` // 进度提示文案: thinking、tool、summarize 或 tool:<工具名>（覆盖各语言的默认文案）
+	Heartbeat         int                `json:"heartbeat,omitempty"`        // 无可见内容时每隔多少秒轮换一次心跳内容，使回复持续变化（0使用默认值，-1禁用）
+	HeartbeatFrames   []string           `json:"heartbeat_frames,omitempty"` // 心跳内容（依次轮换，追加在进度提示后，默认“·”“··”“···”）
+	MaxBytes          int                `json:"max_bytes,omitempty"`        // 单条流式消息的内容上限（字节，默认20000，企业微信上限20480），超出时结束本条消息
+	Continuation      ContinuationConfig `json:"continuation,omitempty"`     // 超长回复剩余内容的续发渠道（单聊和群聊分别配置，未配置的会话截断）
+	SuppressRepeats   bool               `json:"suppress_repeats,omitempty"` // 过滤模型在工具调用后或续传时重复输出的已有内容
+	Journal           string             `json:"journal,omitempty"`          // 预写记录目录（如 data/streams）：进程崩溃重启后以已生成的部分内容结束未完成的回复（为空时不记录）
+}
+
+// ContinuationConfig 超长回复的续发渠道：单聊通过自建应用消息发给用户本人，群聊通过该群自己的群机器人（groups.<chatid>.webhook）
+//
+// 不使用共享的通知群机器人，避免单聊内容发到其他人所在的群。
+type ContinuationConfig struct {
+	CorpID  string `json:"corp_id,omitempty"`  // 企业ID
+	Secret  string `json:"secret,omitempty"`   // 自建应用Secret（应用可见范围需包含使用机器人的成员）
+	AgentID int    `json:"agent_id,omitempty"` // 自建应用AgentId（为0时单聊不续发）
+	BaseURL string `json:"base_url,omitempty"` // 企业微信API地址（默认 https://qyapi.weixin.qq.com，可配置代理）
+}
+
+// WarmPoolConfig 预热配置：预先创建LLM客户端并缓存MCP工具列表，缩短新会话首条消息和每次回复的等待
+type WarmPoolConfig struct {
+	Enabled  bool `json:"enabled"`             // 是否启用预热
+	Size     int  `json:"size,omitempty"`      // 每个LLM提供商预先创建的客户端数（默认2）
+	ToolsTTL int  `json:"tools_ttl,omitempty"` // MCP工具列表缓存时间（秒，默认300），过期后在后台刷新
+}
+
+// TranslationConfig 跨语言翻译配置
+type TranslationConfig struct {
+	Enabled     bool   `json:"enabled"`                // 是否启用翻译（非中文消息翻译为中文，回复翻译回原语言）
+	Provider    string `json:"provider"`               // 翻译提供商: llm(默认) 或 deepl
+	LLMProvider string `json:"llm_provider,omitempty"` // provider=llm时使用的LLM名称（默认llm.default）
+	APIKey      string `json:"api_key,omitempty"`      // 外部翻译API密钥
+	BaseURL     string `json:"base_url,omitempty"`     // 外部翻译API地址（可选）
+}
+
+// OCRConfig 图片文字识别配置（用户发送截图时识别文字并加入问题，无需视觉模型）
+type OCRConfig struct {
+	Enabled   bool   `json:"enabled"`              // 是否启用图片文字识别
+	Provider  string `json:"provider"`             // 识别服务: paddleocr（自建PaddleOCR服务） 或 baidu（百度智能云通用文字识别）
+	BaseURL   string `json:"base_url,omitempty"`   // 识别服务地址（paddleocr必填，如 http://127.0.0.1:8866/predict/ocr_system）
+	APIKey    string `json:"api_key,omitempty"`    // 云服务API Key
+	SecretKey string `json:"secret_key,omitempty"` // 云服务Secret Key
+	Timeout   int    `json:"timeout,omitempty"`    // 单张图片识别超时（秒，默认15）
+	MaxImages int    `json:"max_images,omitempty"` // 每条消息最多识别的图片数（默认3）
+	MaxSize   int    `json:"max_size,omitempty"`   // 图片大小上限（KB，默认4096）
+	MaxChars  int    `json:"max_chars,omitempty"`  // 加入问题的识别文字上限（字符，默认2000）
+}
+
+// TTSConfig 语音回复配置：单聊回答完成后转为语音，通过群机器人发送语音消息
+type TTSConfig struct {
+	Enabled    bool   `json:"enabled"`               // 是否启用语音回复
+	Default    bool   `json:"default,omitempty"`     // 用户未通过 /settings voice 设置时是否发送语音（默认不发送）
+	BaseURL    string `json:"base_url"`              // 语音合成接口地址（OpenAI兼容，如 https://api.openai.com/v1）
+	APIKey     string `json:"api_key,omitempty"`     // 语音合成API密钥
+	Model      string `json:"model,omitempty"`       // 语音合成模型（默认 tts-1）
+	Voice      string `json:"voice,omitempty"`       // 音色（默认 alloy）
+	Format     string `json:"format,omitempty"`      // 合成接口返回的音频格式（默认mp3，非amr时用ffmpeg转码）
+	FFmpeg     string `json:"ffmpeg,omitempty"`      // ffmpeg路径（默认从PATH查找）
+	WebhookURL string `json:"webhook_url,omitempty"` // 发送语音的群机器人Webhook（默认notify.webhook_url）
+	MaxChars   int    `json:"max_chars,omitempty"`   // 转为语音的最大字数（企业微信语音不超过60秒，默认300）
+	Timeout    int    `json:"timeout,omitempty"`     // 合成和转码超时（秒，默认30）
+}
+
+// ImageGenConfig 图片生成工具配置（生成的图片附在流式回复末尾）
+type ImageGenConfig struct {
+	Enabled     bool   `json:"enabled"`                 // 是否为Agent提供generate_image工具
+	Provider    string `json:"provider"`                // 生成服务: openai（DALL·E，默认） 或 wanx（通义万相）
+	BaseURL     string `json:"base_url,omitempty"`      // 服务地址（默认 https://api.openai.com/v1 或 https://dashscope.aliyuncs.com）
+	APIKey      string `json:"api_key"`                 // API密钥
+	Model       string `json:"model,omitempty"`         // 模型（默认 dall-e-3 或 wanx-v1）
+	Size        string `json:"size,omitempty"`          // 图片尺寸（默认1024x1024）
+	Timeout     int    `json:"timeout,omitempty"`       // 单张图片生成超时（秒，默认120）
+	MaxPerReply int    `json:"max_per_reply,omitempty"` // 每次回复最多生成的图片数（默认4，最多10）
+}
+
+// TicketConfig 工单工具配置：为Agent提供create_ticket、get_ticket工具，在Jira、飞书项目或ServiceNow中提单和查询
+type TicketConfig struct {
+	Enabled  bool   `json:"enabled"`             // 是否为Agent提供工单工具
+	Provider string `json:"provider"`            // 工单系统: jira、feishu_project（飞书项目） 或 servicenow
+	BaseURL  string `json:"base_url,omitempty"`  // 实例地址（如 https://example.atlassian.net；飞书项目默认 https://project.feishu.cn）
+	Project  string `json:"project,omitempty"`   // Jira项目Key或飞书项目的project_key（ServiceNow不需要）
+	Type     string `json:"type,omitempty"`      // 工单类型：Jira问题类型（默认Task）、飞书项目工作项类型（默认issue）或ServiceNow表（默认incident）
+	Username string `json:"username,omitempty"`  // Jira账号邮箱、ServiceNow用户名或飞书项目提单人的user_key
+	Token    string `json:"token"`               // Jira API令牌（未填username时作为个人访问令牌）、ServiceNow密码或飞书项目插件密钥
+	PluginID string `json:"plugin_id,omitempty"` // 飞书项目插件ID
+	Timeout  int    `json:"timeout,omitempty"`   // 单次请求超时（秒，默认15）
+}
+
+// DirectoryConfig 通讯录配置：将UserID解析为姓名、部门和职务，注入提示词并用于按部门的权限判断
+type DirectoryConfig struct {
+	Enabled   bool     `json:"enabled"`              // 是否启用通讯录
+	CorpID    string   `json:"corp_id"`              // 企业ID
+	Secret    string   `json:"secret"`               // 通讯录同步Secret（或有通讯录读取权限的自建应用Secret）
+	BaseURL   string   `json:"base_url,omitempty"`   // 企业微信API地址（默认 https://qyapi.weixin.qq.com，可配置代理）
+	Refresh   int      `json:"refresh,omitempty"`    // 全量刷新间隔（分钟，默认60）
+	Allow     []string `json:"allow,omitempty"`      // 允许使用机器人的用户ID或部门（dept:部门名或dept:部门ID，为空表示所有人）
+	DenyReply string   `json:"deny_reply,omitempty"` // 不在allow中的用户收到的回复（为空时按系统消息语言使用默认文案）
+}
+
+// JobsConfig 长任务跟踪配置：工具调用链运行较久时转为跟踪任务，回复中显示进度，结束后可用 /status 查询
+type JobsConfig struct {
+	Enabled   bool   `json:"enabled"`             // 是否启用长任务跟踪
+	After     int    `json:"after,omitempty"`     // 有工具调用且运行超过该秒数后转为跟踪任务（默认20）
+	Retention int    `json:"retention,omitempty"` // 任务结束后保留时间（小时，默认24）
+	Path      string `json:"path,omitempty"`      // 任务记录文件（默认 data/jobs.json）
+}
+
+// SummaryConfig 会话摘要配置：会话空闲后生成摘要（话题、处理结果、待办事项）写入聊天记录
+type SummaryConfig struct {
+	Enabled     bool   `json:"enabled"`                // 是否启用会话摘要
+	IdleAfter   int    `json:"idle_after,omitempty"`   // 会话空闲多少分钟后生成摘要（默认30）
+	LLMProvider string `json:"llm_provider,omitempty"` // 生成摘要使用的LLM提供商（默认llm.default）
+	Remember    bool   `json:"remember,omitempty"`     // 是否将单聊摘要保存到用户画像，用户下次对话时作为背景（需启用profile）
+}
+
+// SessionConfig 会话配置：空闲过久的会话清空记忆，避免几天前的上下文影响新的提问
+type SessionConfig struct {
+	IdleReset int  `json:"idle_reset,omitempty"` // 会话空闲多少分钟后清空记忆，下一条消息开始新会话（0表示不清空）
+	Notice    bool `json:"notice,omitempty"`     // 清空后在新会话的第一条回复前提示“新的会话已开始”
+}
+
+// BudgetConfig 用量预算：按会话统计每日估算的token数和费用，超出限额后改用备用模型或拒绝回复
+type BudgetConfig struct {
+	Enabled     bool               `json:"enabled"`                // 是否启用用量预算
+	UserTokens  int                `json:"user_tokens,omitempty"`  // 单聊每日token上限（0为不限）
+	GroupTokens int                `json:"group_tokens,omitempty"` // 群聊每日token上限（0为不限）
+	UserCost    float64            `json:"user_cost,omitempty"`    // 单聊每日费用上限（0为不限）
+	GroupCost   float64            `json:"group_cost,omitempty"`   // 群聊每日费用上限（0为不限）
+	Prices      map[string]float64 `json:"prices,omitempty"`       // LLM提供商 -> 每千token单价（未配置的提供商不计费用）
+	Fallback    string             `json:"fallback,omitempty"`     // 超出预算后改用的LLM提供商（为空时拒绝回复）
+	Message     string             `json:"message,omitempty"`      // 拒绝回复时的说明
+	Path        string             `json:"path,omitempty"`         // 用量记录文件（默认 data/budget.json）
+}
+
+// ProfileConfig 用户画像记忆配置
+type ProfileConfig struct {
+	Enabled bool   `json:"enabled"` // 是否在对话中注入用户历史问题背景
+	Path    string `json:"path"`    // 画像数据文件（默认 data/profiles.json）
+}
+
+// PreferencesConfig 用户个人偏好配置（用户通过 /settings 修改）
+type PreferencesConfig struct {
+	Enabled bool     `json:"enabled"`          // 是否启用用户偏好
+	Path    string   `json:"path"`             // 偏好数据文件（默认 data/preferences.json）
+	Models  []string `json:"models,omitempty"` // 用户可选择的模型（llm.providers中的名称，为空时不开放模型选择）
+}
+
+// KnowledgeConfig 知识库配置
+type KnowledgeConfig struct {
+	Enabled   bool   `json:"enabled"`              // 是否为Agent提供知识库检索工具
+	Path      string `json:"path"`                 // 知识库数据文件（默认 data/knowledge.json）
+	ChunkSize int    `json:"chunk_size,omitempty"` // 分块大小（字符数）
+	TopK      int    `json:"top_k,omitempty"`      // 每次检索返回的分块数
+
+	Admins      []string `json:"admins,omitempty"`        // 可在聊天中管理知识库的用户ID或部门（dept:部门名，需启用通讯录；/kb 命令和上传文件）
+	MaxFileSize int      `json:"max_file_size,omitempty"` // 聊天上传文件大小上限（KB，默认1024）
+}
+
+// NotifyConfig 主动通知配置
+type NotifyConfig struct {
+	Enabled      bool              `json:"enabled"`                  // 是否启用主动通知
+	WebhookURL   string            `json:"webhook_url"`              // 企业微信群机器人Webhook地址
+	BatchWindow  int               `json:"batch_window,omitempty"`   // 合并窗口（秒，默认300）
+	MaxBatchSize int               `json:"max_batch_size,omitempty"` // 单条摘要最多合并的通知数（默认10）
+	Categories   map[string]string `json:"categories,omitempty"`     // 类别紧急程度: urgent(立即发送) 或 normal(合并发送)
+}
+
+// ErrorReportConfig 错误上报配置：任务处理panic、回调解密失败和工具连续失败上报到Sentry或通用Webhook
+type ErrorReportConfig struct {
+	Enabled      bool   `json:"enabled"`                 // 是否启用错误上报
+	SentryDSN    string `json:"sentry_dsn,omitempty"`    // Sentry DSN（https://<key>@<host>/<project>）
+	WebhookURL   string `json:"webhook_url,omitempty"`   // 通用Webhook地址（POST JSON，与Sentry可同时配置）
+	Environment  string `json:"environment,omitempty"`   // 环境标识，如 production（默认 production）
+	ToolFailures int    `json:"tool_failures,omitempty"` // 同一工具连续失败多少次后上报（默认3）
+	Interval     int    `json:"interval,omitempty"`      // 相同错误的最小上报间隔（秒，默认300）
+}
+
+// OutboundConfig 出站Webhook配置：新会话、负面反馈、转人工和工具失败等事件推送到ITSM、告警等企业系统
+type OutboundConfig struct {
+	Enabled   bool                     `json:"enabled"`              // 是否启用（启用后用户可通过 /feedback 反馈回复问题）
+	Endpoints []OutboundEndpointConfig `json:"endpoints,omitempty"`  // 接收事件的地址
+	Timeout   int                      `json:"timeout,omitempty"`    // 单次请求超时（秒，默认10）
+	Retries   int                      `json:"retries,omitempty"`    // 失败后的重试次数（默认2，按1s、2s、4s...退避）
+	QueueSize int                      `json:"queue_size,omitempty"` // 待发送事件的上限，超出时丢弃（默认1000）
+}
+
+// OutboundEndpointConfig 单个出站Webhook地址
+type OutboundEndpointConfig struct {
+	Name   string   `json:"name"`             // 名称（用于日志）
+	URL    string   `json:"url"`              // 接收地址（POST JSON）
+	Secret string   `json:"secret,omitempty"` // HMAC-SHA256签名密钥（为空时不签名）
+	Events []string `json:"events,omitempty"` // 订阅的事件（为空表示全部）：conversation_started、negative_feedback、handoff_requested、tool_failed
+}
+
+// FetchConfig 出站HTTP工具（http_get）配置
+type FetchConfig struct {
+	Enabled      bool     `json:"enabled"`                 // 是否为Agent提供http_get工具
+	Allow        []string `json:"allow,omitempty"`         // 允许访问的主机（支持 *.example.com，为空表示所有公网主机）
+	Deny         []string `json:"deny,omitempty"`          // 禁止访问的主机（优先于allow）
+	AllowPrivate bool     `json:"allow_private,omitempty"` // 是否允许访问内网地址（默认禁止）
+	MaxBytes     int64    `json:"max_bytes,omitempty"`     // 响应最大字节数（默认512KB）
+	Timeout      int      `json:"timeout,omitempty"`       // 请求超时（秒，默认10）
+	AuditLog     string   `json:"audit_log,omitempty"`     // 审计日志文件（默认 data/fetch_audit.jsonl）
+}
+
+// ModerationConfig 内容审核配置：用户消息和AI回复分别经过关键词、正则和可选的审核模型检查
+type ModerationConfig struct {
+	Enabled       bool            `json:"enabled"`                  // 是否启用内容审核
+	Input         ModerationRules `json:"input"`                    // 用户消息审核规则
+	Output        ModerationRules `json:"output"`                   // AI回复审核规则（启用后回复在审核通过后一次性展示）
+	LLMProvider   string          `json:"llm_provider,omitempty"`   // 审核模型（llm.providers中的名称，默认llm.default）
+	Criteria      string          `json:"criteria,omitempty"`       // 审核模型使用的审核标准（默认违法违规、色情暴力、歧视辱骂、隐私泄露）
+	Timeout       int             `json:"timeout,omitempty"`        // 审核模型超时（秒，默认5；超时或出错时放行）
+	PolicyReply   string          `json:"policy_reply,omitempty"`   // 用户消息被拦截时的回复
+	Replacement   string          `json:"replacement,omitempty"`    // AI回复重新生成后仍不合规时的替换文本
+	MaxRegenerate int             `json:"max_regenerate,omitempty"` // AI回复被拦截后重新生成的次数（默认1，-1表示直接替换）
+	AuditLog      string          `json:"audit_log,omitempty"`      // 审核命中日志文件（默认 data/moderation_audit.jsonl）
+}
+
+// BusinessHoursConfig 营业时间策略：非营业时间自动回复，或以受限模式（不调用工具、不转人工）继续回答
+type BusinessHoursConfig struct {
+	Enabled  bool     `json:"enabled"`             // 是否启用营业时间策略
+	Timezone string   `json:"timezone,omitempty"`  // 时区，如 Asia/Shanghai（默认服务器本地时区）
+	Weekdays []int    `json:"weekdays,omitempty"`  // 营业日（1=周一 … 7=周日，默认周一至周五）
+	Hours    []string `json:"hours,omitempty"`     // 每日营业时段，如 ["09:00-12:00", "13:30-18:00"]（默认 09:00-18:00）
+	Holidays []string `json:"holidays,omitempty"`  // 节假日（全天不营业），如 2026-10-01 或 2026-10-01~2026-10-07
+	Workdays []string `json:"workdays,omitempty"`  // 调休上班日（非营业日按营业时段营业），格式同holidays
+	OffHours string   `json:"off_hours,omitempty"` // 非营业时间的处理：reply（自动回复，默认）或 restricted（受限模式）
+	Reply    string   `json:"reply,omitempty"`     // 自动回复内容（{hours} 替换为营业时间说明）
+	Notice   string   `json:"notice,omitempty"`    // 受限模式追加到系统提示词的说明（{hours} 同上）
+}
+
+// WelcomeConfig 首次对话欢迎：新会话的第一条消息在回复下方附带欢迎卡片（能力介绍、示例问题和可用命令）
+type WelcomeConfig struct {
+	Enabled      bool     `json:"enabled"`                // 是否启用首次对话欢迎
+	Title        string   `json:"title,omitempty"`        // 卡片标题（默认“👋 欢迎使用智能助手”）
+	Description  string   `json:"description,omitempty"`  // 标题下方的简介
+	Capabilities []string `json:"capabilities,omitempty"` // 能力介绍（每项一行）
+	Examples     []string `json:"examples,omitempty"`     // 示例问题
+	URL          string   `json:"url,omitempty"`          // 点击卡片打开的链接（如使用手册，为空时不跳转）
+	Path         string   `json:"path,omitempty"`         // 已欢迎会话的记录文件（默认 data/welcome.json）
+}
+
+// ModerationRules 单个审核阶段的规则
+type ModerationRules struct {
+	Keywords []string `json:"keywords,omitempty"` // 关键词（不区分大小写）
+	Patterns []string `json:"patterns,omitempty"` // 正则表达式（Go RE2语法）
+	LLM      bool     `json:"llm,omitempty"`      // 是否调用审核模型
+}
+
+// Empty 是否未配置任何规则
+func (r ModerationRules) Empty() bool {
+	return len(r.Keywords) == 0 && len(r.Patterns) == 0 && !r.LLM
+}
+
+// HandoffConfig 转人工配置：用户要求或AI无法解决时冻结AI，由客服在客服群中通过机器人回复用户
+type HandoffConfig struct {
+	Enabled         bool     `json:"enabled"`                    // 是否启用转人工
+	Keywords        []string `json:"keywords,omitempty"`         // 触发转人工的用户消息（完全匹配，默认 转人工、人工客服）
+	SupportGroup    string   `json:"support_group"`              // 客服群ChatID（机器人需在群内，客服在群中@机器人回复）
+	WebhookURL      string   `json:"webhook_url,omitempty"`      // 客服群的群机器人Webhook（默认notify.webhook_url）
+	Agents          []string `json:"agents,omitempty"`           // 可以回复和结束转人工的客服用户ID（为空表示客服群所有成员）
+	WaitTimeout     int      `json:"wait_timeout,omitempty"`     // 用户消息等待客服回复的时长（秒，默认240）
+	TranscriptTurns int      `json:"transcript_turns,omitempty"` // 通知客服时附带的最近消息条数（默认10）
+	Path            string   `json:"path,omitempty"`             // 转人工状态文件（默认 data/handoff.json）
+}
+
+// ApprovalConfig 工具调用审批配置：危险工具执行前暂停，通过卡片按钮或命令确认
+type ApprovalConfig struct {
+	Enabled   bool     `json:"enabled"`             // 是否启用工具调用审批
+	Tools     []string `json:"tools"`               // 需要审批的工具名称（支持通配符，如 delete_*）
+	Approvers []string `json:"approvers,omitempty"` // 审批人用户ID（为空时由发起对话的用户确认；非空时需启用notify）
+	Timeout   int      `json:"timeout,omitempty"`   // 等待审批的时长（秒，默认120），超时视为拒绝
+}
+
+// AnalyticsConfig 会话统计配置：按天汇总消息量、用户数、意图、工具使用、耗时和解决率
+type AnalyticsConfig struct {
+	Enabled     bool     `json:"enabled"`                // 是否启用会话统计
+	Path        string   `json:"path,omitempty"`         // SQLite数据库路径（默认 data/analytics.db，多机器人时按机器人分文件）
+	Classify    bool     `json:"classify,omitempty"`     // 是否用LLM判断每个问题的意图（用于意图排行）
+	Intents     []string `json:"intents,omitempty"`      // 预设意图（为空时由模型给出简短标签）
+	LLMProvider string   `json:"llm_provider,omitempty"` // 意图分类使用的LLM提供商（默认llm.default）
+	Retention   int      `json:"retention,omitempty"`    // 统计保留天数（0表示永久保留）
+}
+
+// AuditConfig 审计日志配置：记录工具调用、发出的消息、配置热更新和管理操作（哈希链防篡改，与聊天记录分开）
+type AuditConfig struct {
+	Enabled bool   `json:"enabled"`        // 是否启用审计日志
+	Path    string `json:"path,omitempty"` // 审计日志路径（默认 data/audit.jsonl，所有机器人共用）
+}
+
+// HealthConfig 依赖健康检查配置
+type HealthConfig struct {
+	Interval int `json:"interval,omitempty"` // 探测间隔（秒，默认60；LLM探测会产生少量调用费用）
+	Timeout  int `json:"timeout,omitempty"`  // 单项探测超时（秒，默认10）
+}
+
+// DedupConfig 回调消息去重配置
+type DedupConfig struct {
+	Backend       string `json:"backend,omitempty"`        // memory（默认，进程内）或 redis（多副本共享）
+	Size          int    `json:"size,omitempty"`           // 进程内缓存容量（默认10000）
+	TTL           int    `json:"ttl,omitempty"`            // 去重窗口（秒，默认3600）
+	RedisURL      string `json:"redis_url,omitempty"`      // Redis地址，如 redis://host:6379/0
+	RedisPassword string `json:"redis_password,omitempty"` // Redis密码（也可写在redis_url中）
+	KeyPrefix     string `json:"key_prefix,omitempty"`     // Redis键前缀（默认 b0dy:dedup:）
+}
+
+// ClusterConfig 多副本共享状态配置：任意副本都能响应任意streamID的流式刷新
+type ClusterConfig struct {
+	Enabled       bool   `json:"enabled"`                  // 是否启用共享状态（需同时将dedup.backend设为redis）
+	InstanceID    string `json:"instance_id,omitempty"`    // 副本标识（默认主机名）
+	RedisURL      string `json:"redis_url,omitempty"`      // Redis地址（为空时沿用dedup.redis_url）
+	RedisPassword string `json:"redis_password,omitempty"` // Redis密码（为空时沿用dedup.redis_password）
+	KeyPrefix     string `json:"key_prefix,omitempty"`     // Redis键前缀（默认 b0dy:stream:）
+	SyncInterval  int    `json:"sync_interval,omitempty"`  // 任务状态发布间隔（毫秒，默认500）
+	TTL           int    `json:"ttl,omitempty"`            // 共享状态保留时间（秒，默认600）
+}
+
+// QueueConfig 消息队列模式：Webhook只将消息入队，由独立的worker进程运行Agent，回复通过cluster共享状态返回
+type QueueConfig struct {
+	Enabled     bool   `json:"enabled"`               // 是否启用消息队列模式（需同时启用cluster）
+	Backend     string `json:"backend,omitempty"`     // nats（JetStream，默认）或 redis（Redis Streams）
+	URL         string `json:"url,omitempty"`         // 队列地址，如 nats://host:4222；redis后端为空时沿用cluster.redis_url
+	Name        string `json:"name,omitempty"`        // 队列名（NATS stream/subject 或 Redis Stream 键，默认 b0dy_jobs）
+	Concurrency int    `json:"concurrency,omitempty"` // 每个worker进程同时处理的任务数（默认4）
+	JobTimeout  int    `json:"job_timeout,omitempty"` // 单个任务最长处理时间（秒，默认300），worker异常退出时超过该时间的任务重新投递
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Enabled bool   `json:"enabled"`         // 是否启用 <prefix>/admin 管理接口
+	Token   string `json:"token,omitempty"` // 访问令牌（Authorization: Bearer <token>）
+}
+
+// KFConfig 微信客服渠道：外部微信客户通过微信客服咨询，由机器人拉取消息并自动回复
+type KFConfig struct {
+	Enabled    bool   `json:"enabled"`               // 是否启用微信客服渠道
+	CorpID     string `json:"corp_id"`               // 企业ID
+	Secret     string `json:"secret"`                // 微信客服Secret（管理后台“微信客服”应用）
+	Token      string `json:"token"`                 // 回调Token
+	AESKey     string `json:"aes_key"`               // 回调EncodingAESKey
+	Path       string `json:"path,omitempty"`        // 回调路由（默认 <prefix>/kf/callback）
+	Bot        string `json:"bot,omitempty"`         // 接待客户的机器人名称（多机器人时使用，默认第一个）
+	CursorPath string `json:"cursor_path,omitempty"` // 消息拉取游标的保存文件（默认 data/kf_cursor.json）
+	BaseURL    string `json:"base_url,omitempty"`    // 企业微信API地址（默认 https://qyapi.weixin.qq.com，可配置代理）
+}
+
+// SlackConfig Slack渠道：频道中@机器人或私信提问，回复发在消息的话题（thread）中并随生成进度编辑
+type SlackConfig struct {
+	Enabled       bool   `json:"enabled"`                  // 是否启用Slack渠道
+	BotToken      string `json:"bot_token"`                // Bot User OAuth Token（xoxb-开头）
+	SigningSecret string `json:"signing_secret,omitempty"` // Events API签名密钥（使用Events API时必填）
+	AppToken      string `json:"app_token,omitempty"`      // App-Level Token（xapp-开头，配置后使用Socket Mode，无需公网回调地址）
+	Path          string `json:"path,omitempty"`           // Events API路由（默认 <prefix>/slack/events）
+	Bot           string `json:"bot,omitempty"`            // 处理Slack消息的机器人名称（多机器人时使用，默认第一个）
+	EditInterval  int    `json:"edit_interval,omitempty"`  // 流式回复的编辑间隔（毫秒，默认1000，Slack限制每频道约每秒1次）
+	BaseURL       string `json:"base_url,omitempty"`       // Slack Web API地址（默认 https://slack.com/api）
+}
+
+// TelegramConfig Telegram渠道：私聊或群聊中@机器人提问，回复随生成进度编辑（editMessageText）
+type TelegramConfig struct {
+	Enabled      bool   `json:"enabled"`                 // 是否启用Telegram渠道
+	Token        string `json:"token"`                   // BotFather颁发的Bot Token
+	WebhookURL   string `json:"webhook_url,omitempty"`   // 公网回调地址（https，配置后使用Webhook，否则使用长轮询）
+	SecretToken  string `json:"secret_token,omitempty"`  // Webhook校验令牌（使用Webhook时必填）
+	Path         string `json:"path,omitempty"`          // Webhook路由（默认 <prefix>/telegram/webhook）
+	Bot          string `json:"bot,omitempty"`           // 处理Telegram消息的机器人名称（多机器人时使用，默认第一个）
+	EditInterval int    `json:"edit_interval,omitempty"` // 流式回复的编辑间隔（毫秒，默认1000）
+	BaseURL      string `json:"base_url,omitempty"`      // Bot API地址（默认 https://api.telegram.org）
+}
+
+// GroupConfig 群聊级配置覆盖（未设置的字段沿用全局配置）
+type GroupConfig struct {
+	Name         string   `json:"name,omitempty"`          // 群名称（仅用于标识）
+	Tools        *bool    `json:"tools,omitempty"`         // 是否启用工具调用（MCP与知识库）
+	MCPServers   []string `json:"mcp_servers,omitempty"`   // 允许使用的MCP服务器名称（为空表示全部）
+	Knowledge    *bool    `json:"knowledge,omitempty"`     // 是否启用知识库检索
+	Thinking     *bool    `json:"thinking,omitempty"`      // 是否展示思考过程
+	Proactive    *bool    `json:"proactive,omitempty"`     // 是否允许主动推送消息
+	SystemPrompt string   `json:"system_prompt,omitempty"` // 追加到系统提示词的群专属说明
+	Persona      string   `json:"persona,omitempty"`       // 群默认人设（为空时使用default_persona）
+	Webhook      string   `json:"webhook,omitempty"`       // 本群的群机器人Webhook（超长回复的剩余内容续发到本群，为空时截断）
+}
+
+// OverrideConfig 会话级配置覆盖（优先于群聊配置，未设置的字段沿用原配置）
+type OverrideConfig struct {
+	SystemPrompt string   `json:"system_prompt,omitempty"` // 替换全局系统提示词
+	LLMProvider  string   `json:"llm_provider,omitempty"`  // 使用的LLM提供商
+	Tools        []string `json:"tools,omitempty"`         // 允许使用的工具名称（含MCP工具，为空表示全部）
+}
+
+// PersonaConfig 命名人设：系统提示词、模型和工具集（未设置的字段沿用全局配置）
+type PersonaConfig struct {
+	DisplayName  string   `json:"display_name,omitempty"`  // 展示名称（如“小兴 IT助手”，默认为人设名）
+	Description  string   `json:"description,omitempty"`   // 简介（/persona 列表中展示）
+	SystemPrompt string   `json:"system_prompt,omitempty"` // 替换全局系统提示词
+	LLMProvider  string   `json:"llm_provider,omitempty"`  // 使用的LLM提供商
+	Tools        []string `json:"tools,omitempty"`         // 允许使用的工具名称（含MCP工具，为空表示全部）
+	MCPServers   []string `json:"mcp_servers,omitempty"`   // 允许使用的MCP服务器名称（为空表示全部）
+}
+
+// RouterConfig 多智能体路由配置：主管模型判断问题类型，委派给对应的专家人设处理
+type RouterConfig struct {
+	Enabled     bool     `json:"enabled"`                // 是否启用多智能体路由
+	Specialists []string `json:"specialists,omitempty"`  // 参与路由的专家（人设名，为空表示全部人设）
+	LLMProvider string   `json:"llm_provider,omitempty"` // 路由使用的LLM提供商（默认llm.default）
+	Fallback    string   `json:"fallback,omitempty"`     // 路由失败时使用的专家（默认保持当前专家，其次default_persona）
+	Timeout     int      `json:"timeout,omitempty"`      // 路由模型超时（秒，默认10）
+}
+
+// ExperimentConfig 提示词/模型A/B实验：会话按权重确定性地分配到变体，回复统计按变体对比
+type ExperimentConfig struct {
+	Enabled  bool            `json:"enabled"`            // 是否启用A/B实验
+	Name     string          `json:"name"`               // 实验名称（参与分组计算，更换名称会重新分组）
+	Variants []VariantConfig `json:"variants,omitempty"` // 实验变体（至少2个）
+}
+
+// VariantConfig 实验变体（未设置的字段沿用当前配置，对照组可全部留空）
+type VariantConfig struct {
+	Name         string `json:"name"`                    // 变体名称（记录在日志和统计中）
+	Weight       int    `json:"weight"`                  // 流量权重（相对值，0表示不再分配）
+	SystemPrompt string `json:"system_prompt,omitempty"` // 替换系统提示词
+	LLMProvider  string `json:"llm_provider,omitempty"`  // 使用的LLM提供商（llm.providers中的名称）
+}
+
+// BotConfig 单个机器人配置（未设置的字段沿用全局配置）
+type BotConfig struct {
+	Name          string               `json:"name"`                     // 机器人名称（唯一，用于路由和日志目录）
+	Path          string               `json:"path,omitempty"`           // Webhook路由（默认 <prefix>/<name>/webhook）
+	WeWork        WeWorkConfig         `json:"wework"`                   // 企业微信凭证
+	LLMProvider   string               `json:"llm_provider,omitempty"`   // 使用的LLM提供商（默认llm.default）
+	SystemPrompt  string               `json:"system_prompt,omitempty"`  // 系统提示词（默认llm.system_prompt）
+	MCPServers    []string             `json:"mcp_servers,omitempty"`    // 使用的MCP服务器名称（为空表示全部）
+	MCPDisabled   []string             `json:"mcp_disabled,omitempty"`   // 对本机器人停用的MCP服务器名称（优先于mcp_servers，管理接口按机器人停用时写入）
+	Moderation    *ModerationConfig    `json:"moderation,omitempty"`     // 内容审核配置（整体替换全局moderation）
+	BusinessHours *BusinessHoursConfig `json:"business_hours,omitempty"` // 营业时间策略（整体替换全局business_hours）
+	Welcome       *WelcomeConfig       `json:"welcome,omitempty"`        // 首次对话欢迎（整体替换全局welcome）
+	Persona       string               `json:"persona,omitempty"`        // 默认人设（默认default_persona）
+	OrgID         string               `json:"org_id,omitempty"`         // 所属组织（默认沿用全局org_id），不同组织的机器人互不共享数据
+	Locale        string               `json:"locale,omitempty"`         // 系统消息语言（默认沿用全局locale）
+	Messages      map[string]string    `json:"messages,omitempty"`       // 覆盖系统消息文案（与全局messages合并，同一键以此处为准）
+}
+
+// ScheduleConfig 定时任务配置（结果通过主动通知推送，需启用notify）
+type ScheduleConfig struct {
+	Name     string         `json:"name"`               // 任务名称（唯一，用作推送标题）
+	Cron     string         `json:"cron"`               // cron表达式（分 时 日 月 周），如 "0 9 * * 1-5"，支持 @daily 等
+	Timezone string         `json:"timezone,omitempty"` // 时区，如 Asia/Shanghai（默认服务器本地时区）
+	Target   string         `json:"target"`             // 推送目标：single_<userid> 或 group_<chatid>
+	Prompt   string         `json:"prompt"`             // 交给Agent执行的任务描述
+	Persona  OverrideConfig `json:"persona,omitempty"`  // Agent人设：系统提示词、LLM提供商、可用工具（未设置时沿用目标会话的配置）
+	Category string         `json:"category,omitempty"` // 通知类别（默认 schedule，由notify.categories决定是否合并发送）
+	Timeout  int            `json:"timeout,omitempty"`  // 单次执行超时（秒，默认300）
+	Bot      string         `json:"bot,omitempty"`      // 执行任务的机器人名称（多机器人时使用，默认第一个）
+	Disabled bool           `json:"disabled,omitempty"` // 暂停任务
 }
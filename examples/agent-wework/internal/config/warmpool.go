@@ -0,0 +1,27 @@
+package config
+
+import "fmt"
+
+// applyWarmPoolDefaults 填充预热默认值
+func applyWarmPoolDefaults(w *WarmPoolConfig) {
+	if w.Size == 0 {
+		w.Size = 2
+	}
+	if w.ToolsTTL == 0 {
+		w.ToolsTTL = 300
+	}
+}
+
+// validateWarmPool 验证预热配置
+func validateWarmPool(w WarmPoolConfig) error {
+	if !w.Enabled {
+		return nil
+	}
+	if w.Size < 1 || w.Size > 16 {
+		return fmt.Errorf("warm_pool.size 必须在1-16之间")
+	}
+	if w.ToolsTTL < 1 {
+		return fmt.Errorf("warm_pool.tools_ttl 必须大于0")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 文件变更去抖间隔（编辑器保存时通常会触发多次写事件）
+const reloadDebounce = 500 * time.Millisecond
+
+// Watcher 配置文件监听器 - 文件变更后重新加载并验证，验证通过才回调
+type Watcher struct {
+	path     string
+	onChange func(*Config)
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewWatcher 创建配置文件监听器
+func NewWatcher(path string, onChange func(*Config)) (*Watcher, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建文件监听失败: %w", err)
+	}
+
+	// 监听所在目录而不是文件本身，兼容编辑器"写临时文件再重命名"的保存方式
+	if err := fsWatcher.Add(filepath.Dir(absPath)); err != nil {
+		fsWatcher.Close()
+		return nil, fmt.Errorf("监听配置目录失败: %w", err)
+	}
+
+	w := &Watcher{
+		path:     absPath,
+		onChange: onChange,
+		watcher:  fsWatcher,
+		done:     make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.loop()
+
+	return w, nil
+}
+
+// loop 处理文件事件
+func (w *Watcher) loop() {
+	defer w.wg.Done()
+
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path {
+				continue
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(reloadDebounce)
+			} else {
+				timer.Reset(reloadDebounce)
+			}
+			timerC = timer.C
+
+		case <-timerC:
+			timerC = nil
+			w.reload()
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("配置文件监听错误", "err", err)
+		}
+	}
+}
+
+// reload 重新加载配置，无效配置被拒绝并保留当前配置
+func (w *Watcher) reload() {
+	cfg, err := ReloadConfigFromFile(w.path)
+	if err != nil {
+		slog.Error("配置文件变更被拒绝（继续使用当前配置）", "err", err)
+		return
+	}
+
+	slog.Info("检测到配置文件变更", "path", w.path)
+	w.onChange(cfg)
+}
+
+// Close 停止监听
+func (w *Watcher) Close() error {
+	close(w.done)
+	err := w.watcher.Close()
+	w.wg.Wait()
+	return err
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultWelcomeTitle 欢迎卡片的默认标题
+const DefaultWelcomeTitle = "👋 欢迎使用智能助手"
+
+// applyWelcomeDefaults 填充首次对话欢迎默认值
+func applyWelcomeDefaults(w *WelcomeConfig) {
+	if w.Title == "" {
+		w.Title = DefaultWelcomeTitle
+	}
+	if w.Path == "" {
+		w.Path = "data/welcome.json"
+	}
+}
+
+// validateWelcome 验证首次对话欢迎配置（name用于错误信息，如 welcome 或 bots[sales].welcome）
+func validateWelcome(name string, w WelcomeConfig) error {
+	if !w.Enabled {
+		return nil
+	}
+	if w.URL != "" && !strings.HasPrefix(w.URL, "http://") && !strings.HasPrefix(w.URL, "https://") {
+		return fmt.Errorf("%s.url 必须以 http:// 或 https:// 开头: %s", name, w.URL)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package dedup
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/redisutil"
+)
+
+// redisTimeout 单次Redis操作超时（回调需在企业微信超时前响应）
+const redisTimeout = 500 * time.Millisecond
+
+// RedisDeduplicator 基于Redis SETNX的去重器，多副本共享去重窗口；
+// Redis不可用时退回进程内去重，宁可偶尔重复处理也不丢消息
+type RedisDeduplicator struct {
+	client   *redis.Client
+	prefix   string
+	ttl      time.Duration
+	fallback *wework.MemoryDeduplicator
+}
+
+// NewRedisDeduplicator 连接Redis并创建去重器
+func NewRedisDeduplicator(cfg config.DedupConfig) (*RedisDeduplicator, error) {
+	client, err := redisutil.Connect(cfg.RedisURL, cfg.RedisPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(cfg.TTL) * time.Second
+	return &RedisDeduplicator{
+		client:   client,
+		prefix:   cfg.KeyPrefix,
+		ttl:      ttl,
+		fallback: wework.NewMemoryDeduplicator(cfg.Size, ttl),
+	}, nil
+}
+
+// Seen 实现 wework.Deduplicator 接口
+func (d *RedisDeduplicator) Seen(msgID string) bool {
+	if msgID == "" {
+		return false
+	}
+
+	// 本副本已处理过的消息无需访问Redis
+	if d.fallback.Seen(msgID) {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	created, err := d.client.SetNX(ctx, d.prefix+msgID, 1, d.ttl).Result()
+	if err != nil {
+		slog.Warn("Redis去重失败，使用本地去重结果", "err", err)
+		return false
+	}
+	return !created
+}
+
+// Len 本地缓存的记录数
+func (d *RedisDeduplicator) Len() int {
+	return d.fallback.Len()
+}
+
+// Close 关闭Redis连接
+func (d *RedisDeduplicator) Close() error {
+	return d.client.Close()
+}
+
+// New 按配置创建去重器，返回值可直接传给 WebhookHandler.SetDeduplicator
+func New(cfg config.DedupConfig) (wework.Deduplicator, error) {
+	if cfg.Backend == "redis" {
+		return NewRedisDeduplicator(cfg)
+	}
+	return wework.NewMemoryDeduplicator(cfg.Size, time.Duration(cfg.TTL)*time.Second), nil
+}
+
+// Scoped 为去重器加上组织前缀：多个组织共用同一去重器（或同一Redis）时，消息ID互不干扰
+func Scoped(d wework.Deduplicator, org string) wework.Deduplicator {
+	return scopedDeduplicator{inner: d, prefix: org + ":"}
+}
+
+// scopedDeduplicator 按组织隔离的去重器
+type scopedDeduplicator struct {
+	inner  wework.Deduplicator
+	prefix string
+}
+
+// Seen 实现 wework.Deduplicator 接口
+func (d scopedDeduplicator) Seen(msgID string) bool {
+	if msgID == "" {
+		return false
+	}
+	return d.inner.Seen(d.prefix + msgID)
+}
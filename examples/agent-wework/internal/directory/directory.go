@@ -0,0 +1,263 @@
+// Package directory 企业微信通讯录：将UserID解析为姓名、部门和职务
+//
+// 启动时在后台全量拉取部门和成员，之后按配置的间隔刷新；缓存中没有的用户（如新入职员工）
+// 在首次查询时单独拉取，拉取失败的用户在下次全量刷新前不再重试。
+package directory
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+const (
+	refreshTimeout = 5 * time.Minute // 单次全量刷新的超时
+	lookupTimeout  = 3 * time.Second // 缓存未命中时单独拉取成员的超时（在消息处理路径上，需尽快返回）
+)
+
+// Person 通讯录中的用户
+type Person struct {
+	UserID      string   `json:"user_id"`
+	Name        string   `json:"name"`
+	Position    string   `json:"position,omitempty"`
+	Departments []string `json:"departments,omitempty"` // 所属部门路径（如 技术部/运维组），主部门在前
+
+	departmentIDs []int // 所属部门及其上级部门的ID（按部门ID匹配规则时使用）
+}
+
+// Describe 用于消息前缀的简短描述，如“张三，技术部/运维组，工程师”
+func (p Person) Describe() string {
+	parts := []string{p.Name}
+	if len(p.Departments) > 0 {
+		parts = append(parts, p.Departments[0])
+	}
+	if p.Position != "" {
+		parts = append(parts, p.Position)
+	}
+	return strings.Join(parts, "，")
+}
+
+// Prompt 注入系统提示词的用户身份说明
+func (p Person) Prompt() string {
+	lines := []string{"姓名：" + p.Name}
+	if len(p.Departments) > 0 {
+		lines = append(lines, "部门："+strings.Join(p.Departments, "、"))
+	}
+	if p.Position != "" {
+		lines = append(lines, "职务："+p.Position)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inDepartment 是否属于指定部门（部门名或部门ID，包含子部门）
+func (p Person) inDepartment(dept string) bool {
+	if id, err := strconv.Atoi(dept); err == nil {
+		return slices.Contains(p.departmentIDs, id)
+	}
+	for _, path := range p.Departments {
+		if slices.Contains(strings.Split(path, "/"), dept) {
+			return true
+		}
+	}
+	return false
+}
+
+// Directory 通讯录缓存
+type Directory struct {
+	client  *wework.ContactClient
+	refresh time.Duration
+
+	mutex   sync.RWMutex
+	people  map[string]Person
+	depts   map[int]wework.Department
+	missing map[string]bool // 单独拉取失败的用户（下次全量刷新时清空）
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New 创建通讯录并在后台开始加载和定期刷新（未启用时返回nil）
+func New(cfg config.DirectoryConfig) *Directory {
+	if !cfg.Enabled {
+		return nil
+	}
+	client := wework.NewContactClient(cfg.CorpID, cfg.Secret)
+	if cfg.BaseURL != "" {
+		client.SetBaseURL(cfg.BaseURL)
+	}
+	d := &Directory{
+		client:  client,
+		refresh: time.Duration(cfg.Refresh) * time.Minute,
+		people:  make(map[string]Person),
+		depts:   make(map[int]wework.Department),
+		missing: make(map[string]bool),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// Close 停止定期刷新
+func (d *Directory) Close() {
+	close(d.stop)
+	<-d.done
+}
+
+// run 立即加载一次，之后按间隔刷新
+func (d *Directory) run() {
+	defer close(d.done)
+
+	d.reload()
+	ticker := time.NewTicker(d.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.reload()
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// reload 全量刷新并记录结果（失败时保留原有数据）
+func (d *Directory) reload() {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := d.Refresh(ctx); err != nil {
+		slog.Warn("刷新通讯录失败，继续使用已缓存的数据", "err", err)
+		return
+	}
+	slog.Info("通讯录已刷新", "people", d.Size(), "duration", time.Since(start).Round(time.Millisecond))
+}
+
+// Refresh 全量拉取部门和成员，替换缓存
+func (d *Directory) Refresh(ctx context.Context) error {
+	depts, err := d.client.ListDepartments(ctx)
+	if err != nil {
+		return err
+	}
+	deptMap := make(map[int]wework.Department, len(depts))
+	for _, dept := range depts {
+		deptMap[dept.ID] = dept
+	}
+
+	people := make(map[string]Person)
+	for _, dept := range depts {
+		users, err := d.client.ListDepartmentUsers(ctx, dept.ID)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			// 同时属于多个部门的成员会在各部门中重复出现
+			if _, ok := people[u.UserID]; !ok {
+				people[u.UserID] = newPerson(u, deptMap)
+			}
+		}
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.people = people
+	d.depts = deptMap
+	d.missing = make(map[string]bool)
+	return nil
+}
+
+// Size 缓存中的用户数
+func (d *Directory) Size() int {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return len(d.people)
+}
+
+// Lookup 查询用户，缓存中没有时单独拉取
+func (d *Directory) Lookup(ctx context.Context, userID string) (Person, bool) {
+	if userID == "" {
+		return Person{}, false
+	}
+	d.mutex.RLock()
+	p, ok := d.people[userID]
+	missing := d.missing[userID]
+	d.mutex.RUnlock()
+	if ok || missing {
+		return p, ok
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	user, err := d.client.GetUser(ctx, userID)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err != nil {
+		slog.WarnContext(ctx, "查询通讯录成员失败", "user_id", userID, "err", err)
+		d.missing[userID] = true
+		return Person{}, false
+	}
+	p = newPerson(*user, d.depts)
+	d.people[userID] = p
+	return p, true
+}
+
+// Match 用户是否匹配任一规则：用户ID，或 dept:部门名 / dept:部门ID（包含子部门成员）
+func (d *Directory) Match(ctx context.Context, userID string, rules []string) bool {
+	if slices.Contains(rules, userID) {
+		return true
+	}
+	var depts []string
+	for _, rule := range rules {
+		if dept, ok := strings.CutPrefix(rule, config.DepartmentRulePrefix); ok {
+			depts = append(depts, strings.TrimSpace(dept))
+		}
+	}
+	if len(depts) == 0 || d == nil {
+		return false
+	}
+	p, ok := d.Lookup(ctx, userID)
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(depts, p.inDepartment)
+}
+
+// newPerson 由通讯录成员构建用户，部门按主部门在前排列并展开为路径
+func newPerson(u wework.ContactUser, depts map[int]wework.Department) Person {
+	p := Person{UserID: u.UserID, Name: u.Name, Position: u.Position}
+	if p.Name == "" {
+		p.Name = u.UserID
+	}
+	ids := slices.Clone(u.Department)
+	if i := slices.Index(ids, u.MainDepartment); i > 0 {
+		ids[0], ids[i] = ids[i], ids[0]
+	}
+	for _, id := range ids {
+		var names []string
+		// 逐级向上查找，跳过根部门（企业名称），上级部门不可见时停止
+		for cur, ok := depts[id]; ok; cur, ok = depts[cur.ParentID] {
+			p.departmentIDs = append(p.departmentIDs, cur.ID)
+			if cur.ParentID == 0 && len(names) > 0 {
+				break
+			}
+			names = append(names, cur.Name)
+			if len(names) > len(depts) {
+				break // 防止异常数据形成环
+			}
+		}
+		if len(names) > 0 {
+			slices.Reverse(names)
+			p.Departments = append(p.Departments, strings.Join(names, "/"))
+		}
+	}
+	return p
+}
@@ -0,0 +1,206 @@
+// Package errreport 错误上报：任务处理panic、回调解密失败和工具连续失败上报到Sentry或通用Webhook
+//
+// 上报器在服务启动时通过Setup设置为全局默认，未启用时所有上报函数为空操作。
+// 事件附带上下文中的请求ID和会话ID，相同错误在上报间隔内只上报一次，发送在后台进行不阻塞调用方。
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// 事件类型
+const (
+	KindPanic   = "panic"   // 任务处理panic
+	KindDecrypt = "decrypt" // 回调验签/解密失败
+	KindTool    = "tool"    // 工具连续失败
+)
+
+// sendTimeout 单次上报的超时
+const sendTimeout = 10 * time.Second
+
+// Event 上报的错误事件
+type Event struct {
+	Kind           string                 `json:"kind"`
+	Message        string                 `json:"message"`
+	RequestID      string                 `json:"request_id,omitempty"`
+	ConversationID string                 `json:"conversation_id,omitempty"`
+	Stack          string                 `json:"stack,omitempty"`
+	Extra          map[string]interface{} `json:"extra,omitempty"`
+	Environment    string                 `json:"environment"`
+	Time           time.Time              `json:"time"`
+}
+
+// Reporter 错误上报器
+type Reporter struct {
+	sentry       *sentryTarget
+	webhookURL   string
+	environment  string
+	toolFailures int
+	interval     time.Duration
+	client       *http.Client
+
+	mutex    sync.Mutex
+	lastSent map[string]time.Time // 错误指纹 -> 最近上报时间
+	failures map[string]int       // 服务器/工具 -> 连续失败次数
+}
+
+// defaultReporter 全局上报器（未启用时为nil）
+var defaultReporter atomic.Pointer[Reporter]
+
+// New 创建上报器（未启用时返回nil）
+func New(cfg config.ErrorReportConfig) (*Reporter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	r := &Reporter{
+		webhookURL:   cfg.WebhookURL,
+		environment:  cfg.Environment,
+		toolFailures: cfg.ToolFailures,
+		interval:     time.Duration(cfg.Interval) * time.Second,
+		client:       &http.Client{Timeout: sendTimeout},
+		lastSent:     make(map[string]time.Time),
+		failures:     make(map[string]int),
+	}
+	if cfg.SentryDSN != "" {
+		target, err := parseDSN(cfg.SentryDSN)
+		if err != nil {
+			return nil, err
+		}
+		r.sentry = target
+	}
+	return r, nil
+}
+
+// Setup 按配置创建上报器并设置为全局默认（未启用时清除）
+func Setup(cfg config.ErrorReportConfig) error {
+	r, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	defaultReporter.Store(r)
+	return nil
+}
+
+// Panic 上报recover得到的panic（附带调用栈）
+func Panic(ctx context.Context, recovered interface{}, extra map[string]interface{}) {
+	if r := defaultReporter.Load(); r != nil {
+		r.capture(ctx, KindPanic, fmt.Sprintf("panic: %v", recovered), string(debug.Stack()), extra)
+	}
+}
+
+// Error 上报错误
+func Error(ctx context.Context, kind string, err error, extra map[string]interface{}) {
+	if r := defaultReporter.Load(); r != nil && err != nil {
+		r.capture(ctx, kind, err.Error(), "", extra)
+	}
+}
+
+// ToolResult 记录工具调用结果，同一工具连续失败达到阈值时上报（成功后重新计数）
+func ToolResult(ctx context.Context, server, tool string, err error) {
+	r := defaultReporter.Load()
+	if r == nil {
+		return
+	}
+	key := server + "/" + tool
+	r.mutex.Lock()
+	if err == nil {
+		delete(r.failures, key)
+		r.mutex.Unlock()
+		return
+	}
+	r.failures[key]++
+	count := r.failures[key]
+	r.mutex.Unlock()
+
+	if count == r.toolFailures {
+		r.capture(ctx, KindTool, fmt.Sprintf("工具 %s 连续失败%d次: %v", key, count, err), "", map[string]interface{}{
+			"server": server,
+			"tool":   tool,
+		})
+	}
+}
+
+// capture 组装事件并在后台发送（相同错误在上报间隔内只发送一次）
+func (r *Reporter) capture(ctx context.Context, kind, message, stack string, extra map[string]interface{}) {
+	fingerprint := kind + "\x00" + message
+	now := time.Now()
+	r.mutex.Lock()
+	if last, ok := r.lastSent[fingerprint]; ok && now.Sub(last) < r.interval {
+		r.mutex.Unlock()
+		return
+	}
+	r.lastSent[fingerprint] = now
+	r.mutex.Unlock()
+
+	event := Event{
+		Kind:        kind,
+		Message:     message,
+		RequestID:   applog.RequestID(ctx),
+		Stack:       stack,
+		Extra:       extra,
+		Environment: r.environment,
+		Time:        now,
+	}
+	if id, ok := memory.GetConversationID(ctx); ok {
+		event.ConversationID = id
+	} else if id, ok := extra["conversation_id"].(string); ok {
+		event.ConversationID = id
+	}
+	go r.send(event)
+}
+
+// send 发送到已配置的Sentry和Webhook
+func (r *Reporter) send(event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if r.sentry != nil {
+		if err := r.sentry.send(ctx, r.client, event); err != nil {
+			slog.Warn("上报错误到Sentry失败", "kind", event.Kind, "err", err)
+		}
+	}
+	if r.webhookURL != "" {
+		if err := postJSON(ctx, r.client, r.webhookURL, nil, event); err != nil {
+			slog.Warn("上报错误到Webhook失败", "kind", event.Kind, "err", err)
+		}
+	}
+}
+
+// postJSON 以JSON格式POST请求体，非2xx状态视为失败
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
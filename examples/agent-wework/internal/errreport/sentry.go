@@ -0,0 +1,85 @@
+package errreport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// sentryTarget Sentry的事件接收地址（store接口，无需引入SDK）
+type sentryTarget struct {
+	storeURL string
+	key      string
+}
+
+// parseDSN 解析Sentry DSN：https://<key>@<host>[/<path>]/<project>
+func parseDSN(dsn string) (*sentryTarget, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("解析Sentry DSN失败: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("Sentry DSN 缺少公钥")
+	}
+	path := strings.Trim(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	prefix, project := "", path
+	if idx >= 0 {
+		prefix, project = "/"+path[:idx], path[idx+1:]
+	}
+	if project == "" {
+		return nil, fmt.Errorf("Sentry DSN 缺少项目ID")
+	}
+	return &sentryTarget{
+		storeURL: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		key:      u.User.Username(),
+	}, nil
+}
+
+// send 将事件转换为Sentry事件格式并发送
+func (s *sentryTarget) send(ctx context.Context, client *http.Client, event Event) error {
+	level := "error"
+	if event.Kind == KindPanic {
+		level = "fatal"
+	}
+	tags := map[string]string{"kind": event.Kind}
+	if event.RequestID != "" {
+		tags["request_id"] = event.RequestID
+	}
+	if event.ConversationID != "" {
+		tags["conversation_id"] = event.ConversationID
+	}
+	extra := make(map[string]interface{}, len(event.Extra)+1)
+	for k, v := range event.Extra {
+		extra[k] = v
+	}
+	if event.Stack != "" {
+		extra["stack"] = event.Stack
+	}
+
+	body := map[string]interface{}{
+		"event_id":    newEventID(),
+		"timestamp":   event.Time.UTC().Format("2006-01-02T15:04:05"),
+		"platform":    "go",
+		"level":       level,
+		"logger":      "agent-wework",
+		"environment": event.Environment,
+		"message":     map[string]string{"formatted": event.Message},
+		"tags":        tags,
+		"extra":       extra,
+	}
+	header := http.Header{}
+	header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=agent-wework/1.0, sentry_key=%s", s.key))
+	return postJSON(ctx, client, s.storeURL, header, body)
+}
+
+// newEventID 生成Sentry事件ID（32位十六进制）
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
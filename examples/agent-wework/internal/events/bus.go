@@ -0,0 +1,117 @@
+package events
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// Event 事件接口
+type Event interface {
+	EventName() string
+}
+
+// subscription 订阅记录
+type subscription struct {
+	id      int
+	handler func(Event)
+}
+
+// Bus 进程内事件总线 - 按事件类型分发，订阅方与发布方解耦
+//
+// 事件在发布方的goroutine中同步分发，订阅处理函数应尽快返回，
+// 耗时操作（网络请求、写文件等）需自行异步化。
+type Bus struct {
+	subs   map[reflect.Type][]subscription
+	all    []subscription
+	nextID int
+	mutex  sync.RWMutex
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[reflect.Type][]subscription),
+	}
+}
+
+// Subscribe 订阅指定类型的事件，返回取消订阅函数
+func Subscribe[T Event](b *Bus, handler func(T)) func() {
+	eventType := reflect.TypeOf((*T)(nil)).Elem()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subs[eventType] = append(b.subs[eventType], subscription{
+		id: id,
+		handler: func(e Event) {
+			handler(e.(T))
+		},
+	})
+
+	return func() { b.unsubscribe(eventType, id) }
+}
+
+// SubscribeAll 订阅所有事件（用于审计、调试等），返回取消订阅函数
+func (b *Bus) SubscribeAll(handler func(Event)) func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.all = append(b.all, subscription{id: id, handler: handler})
+
+	return func() { b.unsubscribe(nil, id) }
+}
+
+// Publish 发布事件（nil总线上发布为空操作）
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.RLock()
+	typed := b.subs[reflect.TypeOf(e)]
+	handlers := make([]subscription, 0, len(typed)+len(b.all))
+	handlers = append(handlers, typed...)
+	handlers = append(handlers, b.all...)
+	b.mutex.RUnlock()
+
+	for _, sub := range handlers {
+		dispatch(sub, e)
+	}
+}
+
+// dispatch 调用订阅处理函数，隔离处理函数中的panic
+func dispatch(sub subscription, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("事件处理异常", "event", e.EventName(), "panic", r)
+		}
+	}()
+	sub.handler(e)
+}
+
+// unsubscribe 取消订阅（eventType为nil表示全量订阅）
+func (b *Bus) unsubscribe(eventType reflect.Type, id int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	remove := func(subs []subscription) []subscription {
+		kept := subs[:0]
+		for _, sub := range subs {
+			if sub.id != id {
+				kept = append(kept, sub)
+			}
+		}
+		return kept
+	}
+
+	if eventType == nil {
+		b.all = remove(b.all)
+		return
+	}
+	b.subs[eventType] = remove(b.subs[eventType])
+}
@@ -0,0 +1,17 @@
+package events
+
+import "context"
+
+// busKey 上下文中事件总线的键
+type busKey struct{}
+
+// WithBus 将事件总线放入上下文，供不直接持有总线的组件（如MCP工具调用包装）发布事件
+func WithBus(ctx context.Context, b *Bus) context.Context {
+	return context.WithValue(ctx, busKey{}, b)
+}
+
+// FromContext 上下文中的事件总线（未设置时返回nil，在nil总线上发布为空操作）
+func FromContext(ctx context.Context) *Bus {
+	b, _ := ctx.Value(busKey{}).(*Bus)
+	return b
+}
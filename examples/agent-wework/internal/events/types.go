@@ -0,0 +1,202 @@
+package events
+
+import "time"
+
+// MessageReceived 收到用户消息
+type MessageReceived struct {
+	RequestID      string // 回调请求ID（用于关联日志）
+	ConversationID string
+	UserID         string
+	ChatID         string // 群聊ID（单聊为空）
+	Content        string
+	Time           time.Time
+}
+
+// EventName implements Event
+func (MessageReceived) EventName() string { return "message_received" }
+
+// ConversationStarted 新会话开始（首次对话，或会话Agent过期回收后重新对话）
+type ConversationStarted struct {
+	RequestID      string
+	ConversationID string
+	UserID         string
+	Time           time.Time
+}
+
+// EventName implements Event
+func (ConversationStarted) EventName() string { return "conversation_started" }
+
+// TurnStarted 开始生成回复
+type TurnStarted struct {
+	StreamID       string
+	RequestID      string
+	ConversationID string
+	Question       string
+	Time           time.Time
+}
+
+// EventName implements Event
+func (TurnStarted) EventName() string { return "turn_started" }
+
+// TurnFinished 回复生成结束
+type TurnFinished struct {
+	StreamID       string
+	RequestID      string
+	ConversationID string
+	Variant        string // A/B实验变体（未参与实验时为空）
+	LLMProvider    string // 生成回复的LLM提供商（未能创建会话Agent时为空）
+	Question       string
+	Answer         string
+	ToolCalls      int
+	Duration       time.Duration
+	Err            error // 最终未能恢复的错误（成功时为nil）
+	Time           time.Time
+}
+
+// EventName implements Event
+func (TurnFinished) EventName() string { return "turn_finished" }
+
+// ToolCalled 智能体调用工具
+type ToolCalled struct {
+	StreamID       string
+	ConversationID string
+	Tool           string
+	Arguments      string
+	Time           time.Time
+}
+
+// EventName implements Event
+func (ToolCalled) EventName() string { return "tool_called" }
+
+// ToolFailed MCP工具调用失败（调用出错或工具返回错误结果）
+type ToolFailed struct {
+	RequestID      string
+	ConversationID string
+	Server         string
+	Tool           string
+	Error          string
+	Duration       time.Duration
+	Time           time.Time
+}
+
+// EventName implements Event
+func (ToolFailed) EventName() string { return "tool_failed" }
+
+// StreamStalled 流式输出长时间没有新事件
+type StreamStalled struct {
+	StreamID       string
+	ConversationID string
+	Idle           time.Duration
+	Time           time.Time
+}
+
+// EventName implements Event
+func (StreamStalled) EventName() string { return "stream_stalled" }
+
+// ModerationHit 内容审核命中
+type ModerationHit struct {
+	StreamID       string // 输入阶段为空（消息未进入任务）
+	ConversationID string
+	UserID         string // 输出阶段为空
+	Stage          string // input 或 output
+	Rule           string // keyword、regex 或 llm
+	Match          string
+	Reason         string
+	Action         string // blocked（拦截输入）、regenerate（重新生成）或 replaced（替换回复）
+	Content        string // 被拦截的内容
+	Time           time.Time
+}
+
+// EventName implements Event
+func (ModerationHit) EventName() string { return "moderation_hit" }
+
+// NegativeFeedback 用户通过 /feedback 反馈上一条回复有问题
+type NegativeFeedback struct {
+	RequestID      string
+	ConversationID string
+	UserID         string
+	Comment        string // 用户填写的原因（可为空）
+	Question       string // 被反馈的问题（找不到上一条回复时为空）
+	Answer         string // 被反馈的回复
+	Time           time.Time
+}
+
+// EventName implements Event
+func (NegativeFeedback) EventName() string { return "negative_feedback" }
+
+// HandoffStarted 会话转人工
+type HandoffStarted struct {
+	ConversationID string
+	UserID         string
+	Ticket         int
+	Reason         string
+	Time           time.Time
+}
+
+// EventName implements Event
+func (HandoffStarted) EventName() string { return "handoff_started" }
+
+// HandoffReleased 客服结束转人工，恢复AI回复
+type HandoffReleased struct {
+	ConversationID string
+	Ticket         int
+	Agent          string // 结束转人工的客服
+	Duration       time.Duration
+	Time           time.Time
+}
+
+// EventName implements Event
+func (HandoffReleased) EventName() string { return "handoff_released" }
+
+// ToolApproval 工具调用审批结束
+type ToolApproval struct {
+	RequestID      string
+	StreamID       string
+	ConversationID string
+	UserID         string // 发起对话的用户
+	Tool           string
+	Arguments      string
+	Result         string // approved、denied 或 timeout
+	By             string // 审批人（超时为空）
+	Wait           time.Duration
+	Time           time.Time
+}
+
+// EventName implements Event
+func (ToolApproval) EventName() string { return "tool_approval" }
+
+// TaskRouted 多智能体路由选定处理消息的专家
+type TaskRouted struct {
+	StreamID       string
+	ConversationID string
+	Specialist     string
+	Previous       string // 会话之前的专家（首次路由为空）
+	Fallback       bool   // 路由失败，使用了回退专家
+	Duration       time.Duration
+	Time           time.Time
+}
+
+// EventName implements Event
+func (TaskRouted) EventName() string { return "task_routed" }
+
+// NotificationSent 机器人主动推送通知（低优先级通知可能稍后合并为摘要发送）
+type NotificationSent struct {
+	Target   string
+	Category string
+	Title    string
+	Content  string
+	Time     time.Time
+}
+
+// EventName implements Event
+func (NotificationSent) EventName() string { return "notification_sent" }
+
+// ConfigReloaded 配置热更新生效
+type ConfigReloaded struct {
+	Changes []string // 已生效的变更
+	Restart []string // 需重启才能生效的配置段
+	Time    time.Time
+}
+
+// EventName implements Event
+func (ConfigReloaded) EventName() string { return "config_reloaded" }
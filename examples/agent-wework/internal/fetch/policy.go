@@ -0,0 +1,93 @@
+package fetch
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Policy 出站HTTP访问策略
+type Policy struct {
+	Allow        []string // 允许的主机（支持 *.example.com 通配，为空表示允许所有公网主机）
+	Deny         []string // 禁止的主机（优先于Allow）
+	AllowPrivate bool     // 是否允许访问内网/回环/链路本地地址
+}
+
+// CheckURL 检查URL是否允许访问
+func (p Policy) CheckURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL格式错误: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("只允许http/https协议: %s", u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return nil, fmt.Errorf("URL缺少主机名")
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("URL不允许包含用户信息")
+	}
+
+	for _, pattern := range p.Deny {
+		if matchHost(pattern, host) {
+			return nil, fmt.Errorf("主机 %s 在禁止列表中", host)
+		}
+	}
+	if len(p.Allow) > 0 {
+		allowed := false
+		for _, pattern := range p.Allow {
+			if matchHost(pattern, host) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("主机 %s 不在允许列表中", host)
+		}
+	}
+
+	// 字面IP在此直接检查，域名在建立连接时按解析结果检查
+	if ip := net.ParseIP(host); ip != nil {
+		if err := p.CheckIP(ip); err != nil {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
+
+// CheckIP 检查目标IP是否允许访问
+func (p Policy) CheckIP(ip net.IP) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() || isSharedAddress(ip) {
+		return fmt.Errorf("禁止访问内网地址: %s", ip)
+	}
+	return nil
+}
+
+// sharedAddressSpace 运营商级NAT地址段（100.64.0.0/10），常用于云厂商元数据等内部服务
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isSharedAddress(ip net.IP) bool {
+	return sharedAddressSpace.Contains(ip)
+}
+
+// matchHost 主机匹配：精确匹配或 *.domain 后缀匹配（同时匹配domain本身）
+func matchHost(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "*" {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*.") {
+		domain := pattern[2:]
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}
+	return host == pattern
+}
@@ -0,0 +1,209 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// 默认限制
+const (
+	DefaultMaxBytes = 512 * 1024
+	DefaultTimeout  = 10 * time.Second
+	maxRedirects    = 3
+)
+
+// AuditEntry 出站访问审计记录
+type AuditEntry struct {
+	Time           time.Time `json:"time"`
+	ConversationID string    `json:"conversation_id"`
+	URL            string    `json:"url"`
+	Allowed        bool      `json:"allowed"`
+	Status         int       `json:"status,omitempty"`
+	Bytes          int       `json:"bytes,omitempty"`
+	Duration       string    `json:"duration,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// Options HTTP获取工具配置
+type Options struct {
+	Policy    Policy
+	MaxBytes  int64
+	Timeout   time.Duration
+	AuditLog  string          // 审计日志文件（JSON Lines，为空不记录文件）
+	Transport *http.Transport // 共享的连接池（由NewTransport按同一Policy创建，为空时每个工具单独创建）
+}
+
+// Tool 受限的HTTP GET工具（供Agent调用），按策略限制可访问的地址、响应大小和耗时
+type Tool struct {
+	options        Options
+	conversationID string
+	client         *http.Client
+}
+
+// NewTransport 创建按策略检查连接地址的Transport，可在使用同一策略的所有会话工具间共享连接池
+func NewTransport(policy Policy, timeout time.Duration) *http.Transport {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	// 在建立连接时检查解析后的IP，防止通过DNS指向内网地址
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("无法识别的目标地址: %s", host)
+			}
+			return policy.CheckIP(ip)
+		},
+	}
+
+	return &http.Transport{
+		Proxy:                 nil, // 不走代理，确保地址检查对实际连接生效
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+	}
+}
+
+// NewTool 创建HTTP获取工具
+func NewTool(options Options, conversationID string) *Tool {
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultMaxBytes
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+	if options.Transport == nil {
+		options.Transport = NewTransport(options.Policy, options.Timeout)
+	}
+
+	t := &Tool{options: options, conversationID: conversationID}
+	t.client = &http.Client{
+		Timeout:   options.Timeout,
+		Transport: options.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("重定向次数过多")
+			}
+			_, err := options.Policy.CheckURL(req.URL.String())
+			return err
+		},
+	}
+
+	return t
+}
+
+// Name implements interfaces.Tool.Name
+func (t *Tool) Name() string {
+	return "http_get"
+}
+
+// Description implements interfaces.Tool.Description
+func (t *Tool) Description() string {
+	return "获取公开网页或API的内容（HTTP GET）。仅能访问允许的外部地址，响应内容会被截断。"
+}
+
+// Parameters implements interfaces.Tool.Parameters
+func (t *Tool) Parameters() map[string]interfaces.ParameterSpec {
+	return map[string]interfaces.ParameterSpec{
+		"url": {
+			Type:        "string",
+			Description: "要获取的完整URL（http或https）",
+			Required:    true,
+		},
+	}
+}
+
+// Run implements interfaces.Tool.Run
+func (t *Tool) Run(ctx context.Context, input string) (string, error) {
+	rawURL := strings.TrimSpace(input)
+	entry := AuditEntry{Time: time.Now(), ConversationID: t.conversationID, URL: rawURL}
+	defer func() { t.audit(entry) }()
+
+	u, err := t.options.Policy.CheckURL(rawURL)
+	if err != nil {
+		entry.Error = err.Error()
+		return "", fmt.Errorf("访问被拒绝: %w", err)
+	}
+	entry.Allowed = true
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		entry.Error = err.Error()
+		return "", err
+	}
+	req.Header.Set("User-Agent", "AI-Body-WeWork/1.0")
+
+	start := time.Now()
+	resp, err := t.client.Do(req)
+	entry.Duration = time.Since(start).String()
+	if err != nil {
+		entry.Error = err.Error()
+		return "", fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	entry.Status = resp.StatusCode
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.options.MaxBytes+1))
+	if err != nil {
+		entry.Error = err.Error()
+		return "", fmt.Errorf("读取响应失败: %w", err)
+	}
+	truncated := int64(len(body)) > t.options.MaxBytes
+	if truncated {
+		body = body[:t.options.MaxBytes]
+	}
+	entry.Bytes = len(body)
+
+	content := strings.ToValidUTF8(string(body), "")
+	if truncated {
+		content += "\n\n[内容过长，已截断]"
+	}
+
+	return fmt.Sprintf("HTTP %d\n%s", resp.StatusCode, content), nil
+}
+
+// Execute implements interfaces.Tool.Execute
+func (t *Tool) Execute(ctx context.Context, args string) (string, error) {
+	var params struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil || params.URL == "" {
+		// 兼容直接传入URL的情况
+		return t.Run(ctx, args)
+	}
+	return t.Run(ctx, params.URL)
+}
+
+// audit 输出并记录审计日志
+func (t *Tool) audit(entry AuditEntry) {
+	if entry.Allowed && entry.Error == "" {
+		slog.Info("http_get", "url", entry.URL, "status", entry.Status, "bytes", entry.Bytes, "duration", entry.Duration)
+	} else {
+		slog.Warn("http_get 被拒绝", "url", entry.URL, "reason", entry.Error)
+	}
+
+	if t.options.AuditLog == "" {
+		return
+	}
+	if err := fsutil.AppendJSONLine(t.options.AuditLog, entry); err != nil {
+		slog.Warn("写入出站访问审计日志失败", "err", err)
+	}
+}
@@ -0,0 +1,209 @@
+package handoff
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// Session 转人工会话：期间AI不再回复，用户消息转给客服，客服回复经机器人送达用户
+type Session struct {
+	ID             int       `json:"id"`              // 工单号（客服回复时引用）
+	ConversationID string    `json:"conversation_id"` // 会话标识（single_<userid> 或 group_<chatid>）
+	UserID         string    `json:"user_id"`         // 发起转人工的用户
+	Reason         string    `json:"reason"`
+	Since          time.Time `json:"since"`
+	Agent          string    `json:"agent,omitempty"`   // 最近回复的客服
+	Pending        []string  `json:"pending,omitempty"` // 尚未送达用户的客服回复
+}
+
+// state 持久化内容
+type state struct {
+	NextID   int        `json:"next_id"`
+	Sessions []*Session `json:"sessions"`
+}
+
+// Manager 转人工会话管理，状态保存在JSON文件中（重启后保持）
+type Manager struct {
+	path     string
+	nextID   int
+	sessions map[string]*Session    // conversationID -> 会话
+	waiters  map[string]chan string // conversationID -> 等待客服回复的用户消息
+	mutex    sync.Mutex
+}
+
+// NewManager 创建管理器并加载已有状态
+func NewManager(path string) (*Manager, error) {
+	m := &Manager{
+		path:     path,
+		nextID:   1,
+		sessions: make(map[string]*Session),
+		waiters:  make(map[string]chan string),
+	}
+
+	var s state
+	if _, err := fsutil.ReadJSON(path, &s); err != nil {
+		return nil, err
+	}
+	if s.NextID > 0 {
+		m.nextID = s.NextID
+	}
+	for _, session := range s.Sessions {
+		m.sessions[session.ConversationID] = session
+	}
+	return m, nil
+}
+
+// Start 开始转人工，会话已在转人工中时返回已有会话和false
+func (m *Manager) Start(conversationID, userID, reason string) (Session, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if session, ok := m.sessions[conversationID]; ok {
+		return *session, false, nil
+	}
+	session := &Session{
+		ID:             m.nextID,
+		ConversationID: conversationID,
+		UserID:         userID,
+		Reason:         reason,
+		Since:          time.Now(),
+	}
+	m.nextID++
+	m.sessions[conversationID] = session
+	if err := m.saveLocked(); err != nil {
+		delete(m.sessions, conversationID)
+		return Session{}, false, err
+	}
+	return *session, true, nil
+}
+
+// Active 返回会话的转人工状态
+func (m *Manager) Active(conversationID string) (Session, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session, ok := m.sessions[conversationID]
+	if !ok {
+		return Session{}, false
+	}
+	return *session, true
+}
+
+// List 列出转人工中的会话（按工单号排序）
+func (m *Manager) List() []Session {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	list := make([]Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		list = append(list, *session)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Reply 客服回复：用户正在等待时立即送达（返回true），否则暂存到用户下次发消息时送达
+func (m *Manager) Reply(id int, agent, text string) (Session, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session := m.findLocked(id)
+	if session == nil {
+		return Session{}, false, fmt.Errorf("转人工工单 #%d 不存在或已结束", id)
+	}
+	session.Agent = agent
+
+	if waiter, ok := m.waiters[session.ConversationID]; ok {
+		delete(m.waiters, session.ConversationID)
+		waiter <- text
+		return *session, true, m.saveLocked()
+	}
+	session.Pending = append(session.Pending, text)
+	return *session, false, m.saveLocked()
+}
+
+// Release 结束转人工，恢复AI回复；notice非空且用户正在等待时送达用户。返回未送达的客服回复数
+func (m *Manager) Release(id int, notice string) (Session, int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session := m.findLocked(id)
+	if session == nil {
+		return Session{}, 0, fmt.Errorf("转人工工单 #%d 不存在或已结束", id)
+	}
+	delete(m.sessions, session.ConversationID)
+	if waiter, ok := m.waiters[session.ConversationID]; ok {
+		delete(m.waiters, session.ConversationID)
+		waiter <- notice
+	}
+	return *session, len(session.Pending), m.saveLocked()
+}
+
+// Wait 等待客服回复：有暂存的回复时立即返回，否则等到客服回复、结束转人工或ctx结束（返回false）
+func (m *Manager) Wait(ctx context.Context, conversationID string) (string, bool) {
+	m.mutex.Lock()
+	session, ok := m.sessions[conversationID]
+	if !ok {
+		m.mutex.Unlock()
+		return "", false
+	}
+	if len(session.Pending) > 0 {
+		text := strings.Join(session.Pending, "\n\n")
+		session.Pending = nil
+		if err := m.saveLocked(); err != nil {
+			slog.Warn("保存转人工状态失败", "err", err)
+		}
+		m.mutex.Unlock()
+		return text, true
+	}
+
+	// 同一会话只保留最新的等待者，之前的等待结束
+	if old, ok := m.waiters[conversationID]; ok {
+		close(old)
+	}
+	waiter := make(chan string, 1)
+	m.waiters[conversationID] = waiter
+	m.mutex.Unlock()
+
+	select {
+	case text, ok := <-waiter:
+		return text, ok && text != ""
+	case <-ctx.Done():
+		m.mutex.Lock()
+		if m.waiters[conversationID] == waiter {
+			delete(m.waiters, conversationID)
+		}
+		m.mutex.Unlock()
+		return "", false
+	}
+}
+
+// findLocked 按工单号查找会话（调用方需持有锁）
+func (m *Manager) findLocked(id int) *Session {
+	for _, session := range m.sessions {
+		if session.ID == id {
+			return session
+		}
+	}
+	return nil
+}
+
+// saveLocked 保存状态（调用方需持有锁）
+func (m *Manager) saveLocked() error {
+	s := state{NextID: m.nextID, Sessions: make([]*Session, 0, len(m.sessions))}
+	for _, session := range m.sessions {
+		s.Sessions = append(s.Sessions, session)
+	}
+	sort.Slice(s.Sessions, func(i, j int) bool { return s.Sessions[i].ID < s.Sessions[j].ID })
+	if err := fsutil.WriteJSONAtomic(m.path, s); err != nil {
+		return fmt.Errorf("保存转人工状态失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package handoff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// TransferFunc 将会话转给人工客服（通知客服群并冻结AI）
+type TransferFunc func(conversationID, reason string) (Session, error)
+
+// Tool 转人工工具（供Agent在无法帮助用户时调用）
+type Tool struct {
+	conversationID string
+	transfer       TransferFunc
+}
+
+// NewTool 创建转人工工具
+func NewTool(conversationID string, transfer TransferFunc) *Tool {
+	return &Tool{conversationID: conversationID, transfer: transfer}
+}
+
+// Name implements interfaces.Tool.Name
+func (t *Tool) Name() string {
+	return "transfer_to_human"
+}
+
+// Description implements interfaces.Tool.Description
+func (t *Tool) Description() string {
+	return "将当前会话转给人工客服。仅在你确实无法解决用户的问题、需要人工操作或授权、或用户情绪激动时使用。转接后由人工客服在本会话中回复，你只需简短告知用户已转接。"
+}
+
+// Parameters implements interfaces.Tool.Parameters
+func (t *Tool) Parameters() map[string]interfaces.ParameterSpec {
+	return map[string]interfaces.ParameterSpec{
+		"reason": {
+			Type:        "string",
+			Description: "转人工的原因和问题摘要（供客服快速了解情况）",
+			Required:    true,
+		},
+	}
+}
+
+// Run implements interfaces.Tool.Run
+func (t *Tool) Run(ctx context.Context, input string) (string, error) {
+	reason := strings.TrimSpace(input)
+	if reason == "" {
+		reason = "AI无法解决用户的问题"
+	}
+	session, err := t.transfer(t.conversationID, reason)
+	if err != nil {
+		return "", fmt.Errorf("转人工失败: %w", err)
+	}
+	return fmt.Sprintf("已转接人工客服（工单 #%d）。请简短告知用户已为其转接人工客服，客服会在本会话中回复，不要再尝试解答。", session.ID), nil
+}
+
+// Execute implements interfaces.Tool.Execute
+func (t *Tool) Execute(ctx context.Context, args string) (string, error) {
+	var params struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil || params.Reason == "" {
+		// 兼容直接传入文本的情况
+		return t.Run(ctx, args)
+	}
+	return t.Run(ctx, params.Reason)
+}
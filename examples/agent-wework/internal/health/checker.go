@@ -0,0 +1,219 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status 健康状态
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"   // 全部依赖正常
+	StatusDegraded  Status = "degraded"  // 非关键依赖异常，服务仍可用
+	StatusUnhealthy Status = "unhealthy" // 关键依赖异常，服务不可用
+	StatusPending   Status = "pending"   // 尚未完成首次探测
+)
+
+// Probe 依赖探测项
+type Probe struct {
+	Name     string                          // 依赖名称，如 llm:qwen、mcp:7soft-tools
+	Critical bool                            // 关键依赖失败时整体为unhealthy，否则为degraded
+	Check    func(ctx context.Context) error // 探测函数
+}
+
+// Result 单个依赖的探测结果
+type Result struct {
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Report 整体健康报告
+type Report struct {
+	Status       Status   `json:"status"`
+	Dependencies []Result `json:"dependencies"`
+}
+
+// Checker 依赖健康检查器：后台定期异步探测，HTTP请求只读取缓存结果
+type Checker struct {
+	interval time.Duration
+	timeout  time.Duration
+
+	probes  []Probe
+	results map[string]Result
+	mutex   sync.RWMutex
+
+	trigger chan struct{}
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewChecker 创建健康检查器
+func NewChecker(interval, timeout time.Duration) *Checker {
+	return &Checker{
+		interval: interval,
+		timeout:  timeout,
+		results:  make(map[string]Result),
+		trigger:  make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+}
+
+// SetProbes 替换探测项（配置热更新时调用），并立即触发一轮探测
+func (c *Checker) SetProbes(probes []Probe) {
+	c.mutex.Lock()
+	c.probes = probes
+	results := make(map[string]Result, len(probes))
+	for _, p := range probes {
+		if r, ok := c.results[p.Name]; ok {
+			r.Critical = p.Critical
+			results[p.Name] = r
+		}
+	}
+	c.results = results
+	c.mutex.Unlock()
+
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动后台探测循环
+func (c *Checker) Start() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.runAll()
+		for {
+			select {
+			case <-ticker.C:
+				c.runAll()
+			case <-c.trigger:
+				c.runAll()
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台探测
+func (c *Checker) Stop() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+// runAll 并发执行所有探测
+func (c *Checker) runAll() {
+	c.mutex.RLock()
+	probes := c.probes
+	c.mutex.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p Probe) {
+			defer wg.Done()
+			result := c.run(p)
+
+			c.mutex.Lock()
+			c.results[p.Name] = result
+			c.mutex.Unlock()
+		}(p)
+	}
+	wg.Wait()
+}
+
+// run 执行单个探测
+func (c *Checker) run(p Probe) Result {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := p.Check(ctx)
+
+	result := Result{
+		Name:      p.Name,
+		Status:    StatusHealthy,
+		Critical:  p.Critical,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Report 汇总当前健康状态
+func (c *Checker) Report() Report {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	report := Report{Status: StatusHealthy, Dependencies: make([]Result, 0, len(c.probes))}
+	for _, p := range c.probes {
+		result, ok := c.results[p.Name]
+		if !ok {
+			result = Result{Name: p.Name, Status: StatusPending, Critical: p.Critical}
+		}
+		report.Dependencies = append(report.Dependencies, result)
+
+		if result.Status == StatusHealthy {
+			continue
+		}
+		if p.Critical {
+			report.Status = StatusUnhealthy
+		} else if report.Status == StatusHealthy {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// Handler 完整健康报告（unhealthy时返回503），extra用于附加服务自身信息
+func (c *Checker) Handler(extra func() gin.H) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := c.Report()
+
+		body := gin.H{
+			"status":       report.Status,
+			"timestamp":    time.Now().Unix(),
+			"dependencies": report.Dependencies,
+		}
+		if extra != nil {
+			for k, v := range extra() {
+				body[k] = v
+			}
+		}
+		ctx.JSON(statusCode(report.Status), body)
+	}
+}
+
+// ReadyHandler 就绪探针：关键依赖异常时返回503，降级状态仍视为就绪
+func (c *Checker) ReadyHandler(ctx *gin.Context) {
+	status := c.Report().Status
+	ctx.JSON(statusCode(status), gin.H{"status": status})
+}
+
+// LiveHandler 存活探针：进程能响应即返回200，不检查依赖（避免依赖故障导致重启风暴）
+func LiveHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// statusCode 健康状态对应的HTTP状态码
+func statusCode(status Status) int {
+	if status == StatusUnhealthy {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,94 @@
+// Package i18n 机器人系统消息（占位内容、错误提示、欢迎卡片等）的多语言文案
+//
+// 语言按用户在 /settings 中设置的回复语言选择，未设置或不支持时使用配置的 locale。
+// 缺少某条译文时回退到中文。文案中的 {变量名} 按调用时传入的变量替换，
+// 部署时可通过 messages 配置按消息键覆盖内置文案。
+package i18n
+
+import (
+	"context"
+	"strings"
+)
+
+// 支持的语言
+const (
+	Chinese  = "zh"
+	English  = "en"
+	Japanese = "ja"
+)
+
+// Default 默认语言
+const Default = Chinese
+
+// Supported 支持的语言列表
+var Supported = []string{Chinese, English, Japanese}
+
+// Key 消息键
+type Key string
+
+// Parse 将语言代码或名称（如 en-US、English、英文、日本語）解析为支持的语言
+func Parse(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", false
+	}
+	switch {
+	case s == Chinese || strings.HasPrefix(s, "zh-") || strings.HasPrefix(s, "zh_") || strings.HasPrefix(s, "chinese") ||
+		strings.Contains(s, "中文") || strings.Contains(s, "汉语") || strings.Contains(s, "简体"):
+		return Chinese, true
+	case s == English || strings.HasPrefix(s, "en-") || strings.HasPrefix(s, "en_") || strings.HasPrefix(s, "english") ||
+		strings.Contains(s, "英文") || strings.Contains(s, "英语"):
+		return English, true
+	case s == Japanese || strings.HasPrefix(s, "ja-") || strings.HasPrefix(s, "ja_") || strings.HasPrefix(s, "japanese") ||
+		strings.Contains(s, "日本語") || strings.Contains(s, "日语") || strings.Contains(s, "日文"):
+		return Japanese, true
+	}
+	return "", false
+}
+
+// T 获取指定语言的内置文案，vars 为成对的变量名和值（如 "code", requestID）
+func T(locale string, key Key, vars ...string) string {
+	return Text(nil, locale, key, vars...)
+}
+
+// Text 获取文案：overrides 中 "<键>:<语言>"、"<键>" 依次优先，其次为该语言的内置文案，缺少译文时使用中文；
+// vars 为成对的变量名和值，替换文案中的 {变量名}
+func Text(overrides map[string]string, locale string, key Key, vars ...string) string {
+	text, ok := overrides[string(key)+":"+locale]
+	if !ok {
+		text, ok = overrides[string(key)]
+	}
+	if !ok {
+		text, ok = catalog[locale][key]
+	}
+	if !ok {
+		text = catalog[Default][key]
+	}
+	if len(vars) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, len(vars))
+	for i := 0; i+1 < len(vars); i += 2 {
+		pairs = append(pairs, "{"+vars[i]+"}", vars[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Known 是否为已定义的消息键
+func Known(key string) bool {
+	_, ok := catalog[Default][Key(key)]
+	return ok
+}
+
+type localeKey struct{}
+
+// WithLocale 在上下文中记录回复使用的语言
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext 从上下文获取回复使用的语言（未设置时为空，T按默认语言处理）
+func FromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
@@ -0,0 +1,123 @@
+package i18n
+
+// 消息键
+const (
+	Pending          Key = "pending"           // 首次回复及输出内容前的占位内容
+	Busy             Key = "busy"              // 创建任务失败
+	SystemError      Key = "system_error"      // 处理出错（{error}为错误）
+	ErrorCode        Key = "error_code"        // 错误回复后附带的错误码（{code}为请求ID，为空时不附带）
+	RetryWaiting     Key = "retry_waiting"     // 模型服务重试等待中
+	RetryFailed      Key = "retry_failed"      // 重试用尽
+	ResumeFailed     Key = "resume_failed"     // 已输出部分内容后中断且续传用尽
+	TurnWaiting      Key = "turn_waiting"      // 等待上一条消息的回复结束
+	InterimThinking  Key = "interim_thinking"  // 进度提示：思考中
+	InterimTool      Key = "interim_tool"      // 进度提示：调用工具（{tool}为工具名）
+	InterimSummarize Key = "interim_summarize" // 进度提示：整理结果
+	Continued        Key = "continued"         // 超长回复将续发
+	Truncated        Key = "truncated"         // 超长回复已截断
+	ContinuationPart Key = "continuation_part" // 续发内容的前缀（{part}/{total}为序号和总数）
+	ImageOnly        Key = "image_only"        // 只发送图片时代替用户提问
+	ImageUnsupported Key = "image_unsupported" // 未启用图片识别时收到图片
+	AccessDenied     Key = "access_denied"     // 不在通讯录允许范围内
+	SessionReset     Key = "session_reset"     // 会话空闲超时清空记忆后的提示
+	Interrupted      Key = "interrupted"       // 服务重启导致回复中断
+	WelcomeAbilities Key = "welcome_abilities" // 欢迎卡片：能力介绍标题
+	WelcomeExamples  Key = "welcome_examples"  // 欢迎卡片：示例问题标题
+	CommandPersona   Key = "command_persona"   // 命令说明：/persona
+	CommandSettings  Key = "command_settings"  // 命令说明：/settings
+	CommandStatus    Key = "command_status"    // 命令说明：/status
+	CommandFeedback  Key = "command_feedback"  // 命令说明：/feedback
+	CommandHandoff   Key = "command_handoff"   // 命令说明：转人工关键词
+	CommandKnowledge Key = "command_knowledge" // 命令说明：/kb
+)
+
+// catalog 各语言的文案
+var catalog = map[string]map[Key]string{
+	Chinese: {
+		Pending:          "正在为您思考中...",
+		Busy:             "系统忙，请稍后再试",
+		SystemError:      "系统错误: {error}",
+		ErrorCode:        "如需帮助，请提供错误码 {code} 给IT",
+		RetryWaiting:     "⏳ 服务繁忙，正在重试…",
+		RetryFailed:      "抱歉，AI服务暂时不可用，请稍后再试。",
+		ResumeFailed:     "（回复中断，以上内容不完整，请重新发送问题）",
+		TurnWaiting:      "⏳ 正在处理您的上一条消息，请稍候…",
+		InterimThinking:  "⏳ 正在思考，请稍候…",
+		InterimTool:      "⏳ 正在调用工具 {tool}…",
+		InterimSummarize: "⏳ 正在整理结果…",
+		Continued:        "\n\n……（回复较长，剩余内容将另行发送）",
+		Truncated:        "\n\n……（回复过长，已截断）",
+		ContinuationPart: "（续 {part}/{total}）",
+		ImageOnly:        "请看一下我发送的图片",
+		ImageUnsupported: "我收到了您发送的图片，但目前暂不支持图片分析功能。您可以用文字描述问题，我来帮您解答。",
+		AccessDenied:     "抱歉，您暂无使用本助手的权限，如有需要请联系管理员。",
+		SessionReset:     "🆕 新的会话已开始，之前的对话内容不再作为上下文。",
+		Interrupted:      "（服务重启，本次回复已中断，请重新发送问题）",
+		WelcomeAbilities: "我可以帮您：",
+		WelcomeExamples:  "试试这样问：",
+		CommandPersona:   "查看或切换人设",
+		CommandSettings:  "个人设置（回复语言、详略、通知）",
+		CommandStatus:    "查看长任务进度和结果",
+		CommandFeedback:  "反馈上一条回复的问题",
+		CommandHandoff:   "转接人工客服",
+		CommandKnowledge: "管理知识库",
+	},
+	English: {
+		Pending:          "Thinking...",
+		Busy:             "The system is busy, please try again later.",
+		SystemError:      "System error: {error}",
+		ErrorCode:        "If you need help, please give error code {code} to IT.",
+		RetryWaiting:     "⏳ The service is busy, retrying…",
+		RetryFailed:      "Sorry, the AI service is temporarily unavailable. Please try again later.",
+		ResumeFailed:     "(The reply was interrupted and the content above is incomplete. Please send your question again.)",
+		TurnWaiting:      "⏳ Still working on your previous message, please wait…",
+		InterimThinking:  "⏳ Thinking, please wait…",
+		InterimTool:      "⏳ Calling tool {tool}…",
+		InterimSummarize: "⏳ Putting the results together…",
+		Continued:        "\n\n… (The reply is long; the rest will be sent separately.)",
+		Truncated:        "\n\n… (The reply was too long and has been truncated.)",
+		ContinuationPart: "(cont. {part}/{total})",
+		ImageOnly:        "Please take a look at the image I sent.",
+		ImageUnsupported: "I received your image, but image analysis is not supported yet. Please describe your question in text and I'll help.",
+		AccessDenied:     "Sorry, you don't have permission to use this assistant. Please contact your administrator if you need access.",
+		SessionReset:     "🆕 A new conversation has started; earlier messages are no longer used as context.",
+		Interrupted:      "(The service restarted and this reply was interrupted. Please send your question again.)",
+		WelcomeAbilities: "I can help you with:",
+		WelcomeExamples:  "Try asking:",
+		CommandPersona:   "View or switch persona",
+		CommandSettings:  "Personal settings (reply language, verbosity, notifications)",
+		CommandStatus:    "Check long-running task progress and results",
+		CommandFeedback:  "Report a problem with the last reply",
+		CommandHandoff:   "Talk to a human agent",
+		CommandKnowledge: "Manage the knowledge base",
+	},
+	Japanese: {
+		Pending:          "考えています...",
+		Busy:             "システムが混み合っています。しばらくしてから再度お試しください。",
+		SystemError:      "システムエラー: {error}",
+		ErrorCode:        "お問い合わせの際は、エラーコード {code} をIT部門にお伝えください。",
+		RetryWaiting:     "⏳ サービスが混み合っています。再試行しています…",
+		RetryFailed:      "申し訳ありません。AIサービスは一時的に利用できません。しばらくしてから再度お試しください。",
+		ResumeFailed:     "（回答が中断されたため、上記の内容は不完全です。もう一度質問を送信してください）",
+		TurnWaiting:      "⏳ 前のメッセージを処理しています。しばらくお待ちください…",
+		InterimThinking:  "⏳ 考えています。しばらくお待ちください…",
+		InterimTool:      "⏳ ツール {tool} を呼び出しています…",
+		InterimSummarize: "⏳ 結果をまとめています…",
+		Continued:        "\n\n……（回答が長いため、残りは別途送信します）",
+		Truncated:        "\n\n……（回答が長すぎるため、省略しました）",
+		ContinuationPart: "（続き {part}/{total}）",
+		ImageOnly:        "送った画像を見てください",
+		ImageUnsupported: "画像を受け取りましたが、現在画像の分析には対応していません。質問を文章で説明していただければお答えします。",
+		AccessDenied:     "申し訳ありません。このアシスタントを利用する権限がありません。必要な場合は管理者にお問い合わせください。",
+		SessionReset:     "🆕 新しい会話を開始しました。以前の会話内容はコンテキストとして使用されません。",
+		Interrupted:      "（サービスが再起動したため、回答が中断されました。もう一度質問を送信してください）",
+		WelcomeAbilities: "お手伝いできること：",
+		WelcomeExamples:  "こんな質問をしてみてください：",
+		CommandPersona:   "ペルソナの確認・切り替え",
+		CommandSettings:  "個人設定（回答言語、詳しさ、通知）",
+		CommandStatus:    "長時間タスクの進捗と結果を確認",
+		CommandFeedback:  "直前の回答の問題を報告",
+		CommandHandoff:   "有人サポートに切り替え",
+		CommandKnowledge: "ナレッジベースの管理",
+	},
+}
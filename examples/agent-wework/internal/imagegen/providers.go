@@ -0,0 +1,237 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// maxDownloadBytes 下载生成结果的大小上限
+const maxDownloadBytes = 20 << 20
+
+// wanxPollInterval 通义万相异步任务的查询间隔
+const wanxPollInterval = 2 * time.Second
+
+// Generator 图片生成服务接口，返回图片内容（PNG或JPG）
+type Generator interface {
+	Generate(ctx context.Context, prompt string) ([]byte, error)
+}
+
+// NewGeneratorFromConfig 根据配置创建图片生成服务
+func NewGeneratorFromConfig(cfg config.ImageGenConfig) (Generator, error) {
+	switch cfg.Provider {
+	case "openai":
+		return NewOpenAIGenerator(cfg.APIKey, cfg.BaseURL, cfg.Model, cfg.Size), nil
+	case "wanx":
+		return NewWanxGenerator(cfg.APIKey, cfg.BaseURL, cfg.Model, cfg.Size), nil
+	default:
+		return nil, fmt.Errorf("不支持的图片生成服务: %s", cfg.Provider)
+	}
+}
+
+// OpenAIGenerator 基于OpenAI Images API（DALL·E）的图片生成
+type OpenAIGenerator struct {
+	apiKey  string
+	baseURL string
+	model   string
+	size    string
+	client  *http.Client
+}
+
+// NewOpenAIGenerator 创建DALL·E图片生成
+func NewOpenAIGenerator(apiKey, baseURL, model, size string) *OpenAIGenerator {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return &OpenAIGenerator{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		size:    size,
+		client:  &http.Client{},
+	}
+}
+
+// Generate 实现Generator接口
+func (g *OpenAIGenerator) Generate(ctx context.Context, prompt string) ([]byte, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"model":           g.model,
+		"prompt":          prompt,
+		"n":               1,
+		"size":            g.size,
+		"response_format": "b64_json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/images/generations", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.apiKey)
+
+	body, err := doRequest(g.client, req, "DALL·E")
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析DALL·E响应失败: %w", err)
+	}
+	if len(result.Data) == 0 || result.Data[0].B64JSON == "" {
+		return nil, fmt.Errorf("DALL·E未返回图片")
+	}
+	return base64.StdEncoding.DecodeString(result.Data[0].B64JSON)
+}
+
+// WanxGenerator 基于通义万相（DashScope文生图异步接口）的图片生成
+type WanxGenerator struct {
+	apiKey  string
+	baseURL string
+	model   string
+	size    string
+	client  *http.Client
+}
+
+// NewWanxGenerator 创建通义万相图片生成
+func NewWanxGenerator(apiKey, baseURL, model, size string) *WanxGenerator {
+	if baseURL == "" {
+		baseURL = "https://dashscope.aliyuncs.com"
+	}
+	return &WanxGenerator{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		size:    strings.Replace(size, "x", "*", 1), // 万相尺寸格式为 宽*高
+		client:  &http.Client{},
+	}
+}
+
+// wanxTask 异步任务状态
+type wanxTask struct {
+	Output struct {
+		TaskID     string `json:"task_id"`
+		TaskStatus string `json:"task_status"` // PENDING | RUNNING | SUCCEEDED | FAILED
+		Code       string `json:"code"`
+		Message    string `json:"message"`
+		Results    []struct {
+			URL string `json:"url"`
+		} `json:"results"`
+	} `json:"output"`
+}
+
+// Generate 实现Generator接口：提交异步任务，轮询到完成后下载图片
+func (g *WanxGenerator) Generate(ctx context.Context, prompt string) ([]byte, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"model":      g.model,
+		"input":      map[string]string{"prompt": prompt},
+		"parameters": map[string]interface{}{"size": g.size, "n": 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		g.baseURL+"/api/v1/services/aigc/text2image/image-synthesis", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-DashScope-Async", "enable")
+
+	task, err := g.call(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch task.Output.TaskStatus {
+		case "SUCCEEDED":
+			if len(task.Output.Results) == 0 || task.Output.Results[0].URL == "" {
+				return nil, fmt.Errorf("通义万相未返回图片")
+			}
+			return g.download(ctx, task.Output.Results[0].URL)
+		case "FAILED", "CANCELED", "UNKNOWN":
+			return nil, fmt.Errorf("通义万相生成失败: %s %s", task.Output.Code, task.Output.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("等待通义万相生成超时: %w", ctx.Err())
+		case <-time.After(wanxPollInterval):
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/api/v1/tasks/"+url.PathEscape(task.Output.TaskID), nil)
+		if err != nil {
+			return nil, err
+		}
+		if task, err = g.call(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// call 调用DashScope接口并解析任务状态
+func (g *WanxGenerator) call(req *http.Request) (*wanxTask, error) {
+	req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	body, err := doRequest(g.client, req, "通义万相")
+	if err != nil {
+		return nil, err
+	}
+	var task wanxTask
+	if err := json.Unmarshal(body, &task); err != nil {
+		return nil, fmt.Errorf("解析通义万相响应失败: %w", err)
+	}
+	return &task, nil
+}
+
+// download 下载生成的图片（结果URL有效期24小时）
+func (g *WanxGenerator) download(ctx context.Context, imageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doRequest(g.client, req, "下载生成的图片")
+}
+
+// doRequest 发送请求并读取响应体，非200时返回错误（错误中不含请求URL）
+func doRequest(client *http.Client, req *http.Request, service string) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("%s请求失败: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxDownloadBytes {
+		return nil, fmt.Errorf("%s响应超过%d字节", service, maxDownloadBytes)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s返回错误: %d, %s", service, resp.StatusCode, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,101 @@
+package imagegen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// Sink 生成的图片的去处（当前任务的回复）
+type Sink interface {
+	// Check 当前任务还能否附带图片（无人接收或已达数量上限时返回错误，避免白白生成）
+	Check(ctx context.Context) error
+	// Attach 将图片附在当前任务的回复末尾
+	Attach(ctx context.Context, image []byte) error
+}
+
+// Tool 图片生成工具（供Agent调用），生成的图片附在本次回复末尾
+type Tool struct {
+	generator Generator
+	sink      Sink
+	timeout   time.Duration
+}
+
+// NewTool 创建图片生成工具
+func NewTool(generator Generator, sink Sink, timeout time.Duration) *Tool {
+	return &Tool{generator: generator, sink: sink, timeout: timeout}
+}
+
+// Name implements interfaces.Tool.Name
+func (t *Tool) Name() string {
+	return "generate_image"
+}
+
+// Description implements interfaces.Tool.Description
+func (t *Tool) Description() string {
+	return "根据文字描述生成一张图片（示意图、插画、海报等），图片会自动附在本次回复末尾展示给用户。" +
+		"描述应具体说明主体、布局、风格和配色；需要多张时分多次调用。"
+}
+
+// Parameters implements interfaces.Tool.Parameters
+func (t *Tool) Parameters() map[string]interfaces.ParameterSpec {
+	return map[string]interfaces.ParameterSpec{
+		"prompt": {
+			Type:        "string",
+			Description: "图片的详细描述",
+			Required:    true,
+		},
+	}
+}
+
+// Run implements interfaces.Tool.Run
+func (t *Tool) Run(ctx context.Context, input string) (string, error) {
+	prompt := strings.TrimSpace(input)
+	if prompt == "" {
+		return "", fmt.Errorf("图片描述不能为空")
+	}
+	if err := t.sink.Check(ctx); err != nil {
+		return "", err
+	}
+
+	genCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	start := time.Now()
+	image, err := t.generator.Generate(genCtx, prompt)
+	if err != nil {
+		slog.Warn("generate_image 失败", "err", err)
+		return "", fmt.Errorf("图片生成失败: %w", err)
+	}
+	if contentType := http.DetectContentType(image); contentType != "image/png" && contentType != "image/jpeg" {
+		return "", fmt.Errorf("生成的图片格式不受支持: %s", contentType)
+	}
+	if len(image) > wework.MaxStreamImageBytes {
+		return "", fmt.Errorf("生成的图片超过%dMB，无法发送", wework.MaxStreamImageBytes>>20)
+	}
+
+	if err := t.sink.Attach(ctx, image); err != nil {
+		return "", err
+	}
+	slog.Info("generate_image", "bytes", len(image), "duration", time.Since(start).Truncate(time.Millisecond))
+	return "图片已生成，将在回复结束时附在末尾展示。请用一两句话说明图片内容，不要输出图片链接或Markdown图片语法。", nil
+}
+
+// Execute implements interfaces.Tool.Execute
+func (t *Tool) Execute(ctx context.Context, args string) (string, error) {
+	var params struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil || params.Prompt == "" {
+		// 兼容直接传入描述的情况
+		return t.Run(ctx, args)
+	}
+	return t.Run(ctx, params.Prompt)
+}
@@ -0,0 +1,299 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/knowledge"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/profile"
+)
+
+// Record 历史工单对话记录（一行一条消息）
+type Record struct {
+	TicketID string    `json:"ticket_id"`
+	UserID   string    `json:"user_id"`
+	Role     string    `json:"role"` // user 或 agent
+	Content  string    `json:"content"`
+	Category string    `json:"category,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Ticket 按工单聚合的对话
+type Ticket struct {
+	ID       string
+	UserID   string
+	Category string
+	Messages []Record
+}
+
+// Result 导入结果统计
+type Result struct {
+	Records   int
+	Tickets   int
+	Users     int
+	Documents int
+	Skipped   int
+}
+
+// timeLayouts 支持的时间格式
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+}
+
+// LoadRecords 从文件加载记录，format为空时按扩展名识别（csv/json/jsonl）
+func LoadRecords(path, format string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取导入文件失败: %w", err)
+	}
+
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	switch format {
+	case "csv":
+		return parseCSV(data)
+	case "json":
+		return parseJSON(data)
+	case "jsonl", "ndjson":
+		return parseJSONL(data)
+	default:
+		return nil, fmt.Errorf("不支持的导入格式: %s（支持csv/json/jsonl）", format)
+	}
+}
+
+// parseCSV 解析带表头的CSV（列：ticket_id,user_id,role,content,category,time）
+func parseCSV(data []byte) ([]Record, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取CSV表头失败: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"user_id", "content"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV缺少必需列: %s", required)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		if idx, ok := columns[name]; ok && idx < len(row) {
+			return strings.TrimSpace(row[idx])
+		}
+		return ""
+	}
+
+	var records []Record
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析CSV第%d行失败: %w", line, err)
+		}
+
+		records = append(records, Record{
+			TicketID: field(row, "ticket_id"),
+			UserID:   field(row, "user_id"),
+			Role:     field(row, "role"),
+			Content:  field(row, "content"),
+			Category: field(row, "category"),
+			Time:     parseTime(field(row, "time")),
+		})
+	}
+
+	return records, nil
+}
+
+// rawRecord JSON中的原始记录（时间按字符串解析以兼容多种格式）
+type rawRecord struct {
+	TicketID string `json:"ticket_id"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+	Content  string `json:"content"`
+	Category string `json:"category"`
+	Time     string `json:"time"`
+}
+
+func (r rawRecord) toRecord() Record {
+	return Record{
+		TicketID: r.TicketID,
+		UserID:   r.UserID,
+		Role:     r.Role,
+		Content:  r.Content,
+		Category: r.Category,
+		Time:     parseTime(r.Time),
+	}
+}
+
+// parseJSON 解析JSON数组
+func parseJSON(data []byte) ([]Record, error) {
+	var raws []rawRecord
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %w", err)
+	}
+
+	records := make([]Record, 0, len(raws))
+	for _, r := range raws {
+		records = append(records, r.toRecord())
+	}
+	return records, nil
+}
+
+// parseJSONL 解析每行一个JSON对象的文件
+func parseJSONL(data []byte) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var r rawRecord
+		if err := json.Unmarshal([]byte(text), &r); err != nil {
+			return nil, fmt.Errorf("解析第%d行失败: %w", line, err)
+		}
+		records = append(records, r.toRecord())
+	}
+
+	return records, scanner.Err()
+}
+
+// parseTime 解析时间，失败返回零值
+func parseTime(value string) time.Time {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// GroupTickets 按工单聚合记录（无工单号时按用户+日期聚合）
+func GroupTickets(records []Record) []*Ticket {
+	tickets := make(map[string]*Ticket)
+	var order []string
+
+	for _, r := range records {
+		key := r.TicketID
+		if key == "" {
+			key = fmt.Sprintf("%s@%s", r.UserID, r.Time.Format("2006-01-02"))
+		}
+
+		t, ok := tickets[key]
+		if !ok {
+			t = &Ticket{ID: key, UserID: r.UserID}
+			tickets[key] = t
+			order = append(order, key)
+		}
+		if t.Category == "" {
+			t.Category = r.Category
+		}
+		t.Messages = append(t.Messages, r)
+	}
+
+	result := make([]*Ticket, 0, len(order))
+	for _, key := range order {
+		t := tickets[key]
+		sort.SliceStable(t.Messages, func(i, j int) bool { return t.Messages[i].Time.Before(t.Messages[j].Time) })
+		result = append(result, t)
+	}
+	return result
+}
+
+// Import 将工单写入用户画像和知识库（任一存储可为nil）
+func Import(records []Record, profiles *profile.Store, kb *knowledge.Store) Result {
+	result := Result{Records: len(records)}
+	users := make(map[string]bool)
+
+	for _, t := range GroupTickets(records) {
+		question := t.firstUserMessage()
+		if t.UserID == "" || question == "" {
+			result.Skipped++
+			continue
+		}
+		result.Tickets++
+		users[t.UserID] = true
+
+		if profiles != nil {
+			profiles.RecordIssue(t.UserID, t.Category, question, t.Messages[len(t.Messages)-1].Time)
+		}
+
+		// 只有包含客服回复的工单才有知识价值
+		if kb != nil && t.hasAgentReply() {
+			kb.Add(t.title(question), t.transcript(), "helpdesk", t.Category)
+			result.Documents++
+		}
+	}
+
+	result.Users = len(users)
+	return result
+}
+
+// firstUserMessage 获取工单中用户的第一条消息
+func (t *Ticket) firstUserMessage() string {
+	for _, m := range t.Messages {
+		if m.Role == "" || m.Role == "user" {
+			if content := strings.TrimSpace(m.Content); content != "" {
+				return content
+			}
+		}
+	}
+	return ""
+}
+
+// hasAgentReply 判断工单是否包含客服回复
+func (t *Ticket) hasAgentReply() bool {
+	for _, m := range t.Messages {
+		if m.Role == "agent" || m.Role == "assistant" {
+			return true
+		}
+	}
+	return false
+}
+
+// title 生成知识库文档标题
+func (t *Ticket) title(question string) string {
+	runes := []rune(question)
+	if len(runes) > 40 {
+		question = string(runes[:40]) + "..."
+	}
+	if t.Category != "" {
+		return fmt.Sprintf("[%s] %s", t.Category, question)
+	}
+	return question
+}
+
+// transcript 生成工单对话文本（隐去用户ID）
+func (t *Ticket) transcript() string {
+	var sb strings.Builder
+	for _, m := range t.Messages {
+		role := "用户"
+		if m.Role == "agent" || m.Role == "assistant" {
+			role = "IT支持"
+		}
+		sb.WriteString(fmt.Sprintf("%s：%s\n\n", role, strings.TrimSpace(m.Content)))
+	}
+	return strings.TrimSpace(sb.String())
+}
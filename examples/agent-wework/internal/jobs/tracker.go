@@ -0,0 +1,201 @@
+package jobs
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// 任务状态
+const (
+	StateRunning     = "running"
+	StateSucceeded   = "succeeded"
+	StateFailed      = "failed"
+	StateInterrupted = "interrupted" // 服务重启时仍在运行
+)
+
+// Job 长时间运行的工具调用链（流式回复结束后仍可通过 /status 查询）
+type Job struct {
+	ID             int       `json:"id"`
+	StreamID       string    `json:"stream_id"`
+	ConversationID string    `json:"conversation_id"`
+	Question       string    `json:"question"`
+	State          string    `json:"state"`
+	Step           string    `json:"step,omitempty"`   // 当前步骤（工具名或工具上报的步骤说明）
+	Steps          int       `json:"steps"`            // 已发起的工具调用次数
+	Percent        int       `json:"percent"`          // 工具上报的进度百分比，-1表示未知
+	Answer         string    `json:"answer,omitempty"` // 最终回复（结束后保存）
+	Error          string    `json:"error,omitempty"`  // 失败原因
+	Started        time.Time `json:"started"`          // 回复开始时间（早于转为跟踪任务的时间）
+	Updated        time.Time `json:"updated"`
+	Finished       time.Time `json:"finished,omitempty"`
+}
+
+// Elapsed 已运行时间（结束的任务为总耗时）
+func (j Job) Elapsed() time.Duration {
+	end := time.Now()
+	if !j.Finished.IsZero() {
+		end = j.Finished
+	}
+	return end.Sub(j.Started).Truncate(time.Second)
+}
+
+// state 持久化内容
+type state struct {
+	NextID int    `json:"next_id"`
+	Jobs   []*Job `json:"jobs"`
+}
+
+// Tracker 长任务跟踪，创建和结束时保存到JSON文件（进度更新只在内存中）
+type Tracker struct {
+	path      string
+	retention time.Duration
+	nextID    int
+	jobs      map[int]*Job
+	mutex     sync.Mutex
+}
+
+// NewTracker 创建跟踪器并加载已有任务，上次运行中的任务标记为中断
+func NewTracker(path string, retention time.Duration) (*Tracker, error) {
+	t := &Tracker{
+		path:      path,
+		retention: retention,
+		nextID:    1,
+		jobs:      make(map[int]*Job),
+	}
+
+	var s state
+	if _, err := fsutil.ReadJSON(path, &s); err != nil {
+		return nil, err
+	}
+	if s.NextID > 0 {
+		t.nextID = s.NextID
+	}
+	for _, job := range s.Jobs {
+		if job.State == StateRunning {
+			job.State = StateInterrupted
+			job.Finished = job.Updated
+		}
+		t.jobs[job.ID] = job
+	}
+	t.pruneLocked()
+	return t, nil
+}
+
+// Create 开始跟踪任务
+func (t *Tracker) Create(job Job) (Job, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	job.ID = t.nextID
+	job.State = StateRunning
+	job.Updated = time.Now()
+	t.nextID++
+	t.jobs[job.ID] = &job
+	if err := t.saveLocked(); err != nil {
+		delete(t.jobs, job.ID)
+		return Job{}, err
+	}
+	return job, nil
+}
+
+// Progress 更新运行中任务的进度（step为空时保持原步骤，percent<0时保持原进度）
+func (t *Tracker) Progress(id int, step string, percent int, toolCall bool) (Job, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	job, ok := t.jobs[id]
+	if !ok || job.State != StateRunning {
+		return Job{}, false
+	}
+	if step != "" {
+		job.Step = step
+	}
+	if percent >= 0 {
+		job.Percent = min(percent, 100)
+	}
+	if toolCall {
+		job.Steps++
+	}
+	job.Updated = time.Now()
+	return *job, true
+}
+
+// Finish 结束任务，err非空时标记为失败
+func (t *Tracker) Finish(id int, answer string, err error) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	job, ok := t.jobs[id]
+	if !ok {
+		return nil
+	}
+	job.State = StateSucceeded
+	job.Percent = 100
+	if err != nil {
+		job.State = StateFailed
+		job.Error = err.Error()
+		job.Percent = -1
+	}
+	job.Answer = answer
+	job.Updated = time.Now()
+	job.Finished = job.Updated
+	t.pruneLocked()
+	return t.saveLocked()
+}
+
+// Get 查询任务
+func (t *Tracker) Get(id int) (Job, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	job, ok := t.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// List 列出会话的任务（最近的在前，最多limit个）
+func (t *Tracker) List(conversationID string, limit int) []Job {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var list []Job
+	for _, job := range t.jobs {
+		if job.ConversationID == conversationID {
+			list = append(list, *job)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// pruneLocked 清理超过保留时间的已结束任务（调用方需持有锁）
+func (t *Tracker) pruneLocked() {
+	cutoff := time.Now().Add(-t.retention)
+	for id, job := range t.jobs {
+		if job.State != StateRunning && job.Finished.Before(cutoff) {
+			delete(t.jobs, id)
+		}
+	}
+}
+
+// saveLocked 保存状态（调用方需持有锁）
+func (t *Tracker) saveLocked() error {
+	s := state{NextID: t.nextID, Jobs: make([]*Job, 0, len(t.jobs))}
+	for _, job := range t.jobs {
+		s.Jobs = append(s.Jobs, job)
+	}
+	sort.Slice(s.Jobs, func(i, j int) bool { return s.Jobs[i].ID < s.Jobs[j].ID })
+	if err := fsutil.WriteJSONAtomic(t.path, s); err != nil {
+		return fmt.Errorf("保存任务状态失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package kf
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/relay"
+)
+
+const (
+	syncTimeout      = 30 * time.Second // 单次拉取消息的超时
+	sendTimeout      = 10 * time.Second // 单条回复的发送超时
+	replyTimeout     = 10 * time.Minute // 等待机器人完成回复的最长时间
+	refreshInterval  = time.Second      // 轮询流式回复的间隔
+	maxReplyMessages = 5                // 每条客户消息最多回复的条数（微信客服限制）
+)
+
+// unsupportedReply 非文字消息的回复
+const unsupportedReply = "目前仅支持文字消息，请用文字描述您的问题。"
+
+// Channel 微信客服渠道：收到回调后拉取客户消息，交给机器人处理（与企业微信内部消息相同的流程），
+// 等待流式回复完成后通过客服接口发送给客户
+type Channel struct {
+	client  *wework.KFClient
+	handler wework.MessageHandler
+	dedup   wework.Deduplicator
+
+	cursorPath string
+	cursors    map[string]string // open_kfid -> 下次拉取的游标
+	started    time.Time         // 首次拉取（无游标）时忽略启动前的历史消息
+	mutex      sync.Mutex        // 串行拉取，游标按顺序推进
+}
+
+// NewChannel 创建微信客服渠道并加载已保存的拉取游标
+func NewChannel(cfg config.KFConfig, handler wework.MessageHandler, dedup wework.Deduplicator) (*Channel, error) {
+	client := wework.NewKFClient(cfg.CorpID, cfg.Secret)
+	if cfg.BaseURL != "" {
+		client.SetBaseURL(cfg.BaseURL)
+	}
+
+	c := &Channel{
+		client:     client,
+		handler:    handler,
+		dedup:      dedup,
+		cursorPath: cfg.CursorPath,
+		cursors:    make(map[string]string),
+		started:    time.Now(),
+	}
+	if _, err := fsutil.ReadJSON(cfg.CursorPath, &c.cursors); err != nil {
+		return nil, fmt.Errorf("加载微信客服拉取游标失败: %w", err)
+	}
+	return c, nil
+}
+
+// HandleEvent 处理回调事件：在后台拉取该客服账号的新消息（回调需尽快返回）
+func (c *Channel) HandleEvent(ev *wework.KFCallbackEvent) {
+	go c.sync(ev.OpenKfID, ev.Token)
+}
+
+// sync 从上次的游标开始拉取消息，直到没有更多消息
+func (c *Channel) sync(openKfID, token string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fresh := c.cursors[openKfID] == ""
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+		resp, err := c.client.SyncMsg(ctx, wework.KFSyncRequest{
+			Cursor:   c.cursors[openKfID],
+			Token:    token,
+			OpenKfID: openKfID,
+		})
+		cancel()
+		if err != nil {
+			slog.Warn("拉取微信客服消息失败", "open_kfid", openKfID, "err", err)
+			return
+		}
+
+		for _, m := range resp.MsgList {
+			if fresh && time.Unix(m.SendTime, 0).Before(c.started) {
+				continue // 首次接入时不回复历史消息
+			}
+			c.dispatch(m)
+		}
+
+		if resp.NextCursor != "" && resp.NextCursor != c.cursors[openKfID] {
+			c.cursors[openKfID] = resp.NextCursor
+			if err := fsutil.WriteJSONAtomic(c.cursorPath, c.cursors); err != nil {
+				slog.Warn("保存微信客服拉取游标失败", "err", err)
+			}
+		}
+		if resp.HasMore != 1 {
+			return
+		}
+	}
+}
+
+// dispatch 处理客户发送的消息（系统事件和接待人员的消息不回复）
+func (c *Channel) dispatch(m wework.KFMessage) {
+	if m.Origin != wework.KFOriginCustomer || m.ExternalUserID == "" {
+		return
+	}
+	if c.dedup.Seen("kf_" + m.MsgID) {
+		return
+	}
+	go c.reply(m)
+}
+
+// reply 交给机器人处理并把回复发送给客户
+func (c *Channel) reply(m wework.KFMessage) {
+	if m.MsgType != wework.MsgTypeText || m.Text == nil || strings.TrimSpace(m.Text.Content) == "" {
+		c.send(m, []string{unsupportedReply})
+		return
+	}
+
+	slog.Debug("微信客服消息", "external_user_id", m.ExternalUserID, "content", m.Text.Content)
+	resp, err := c.handler.HandleMessage(&wework.IncomingMessage{
+		BaseMessage: wework.BaseMessage{
+			MsgID:    m.MsgID,
+			ChatType: wework.ChatTypeSingle,
+			From:     wework.From{UserID: m.ExternalUserID},
+			MsgType:  wework.MsgTypeText,
+		},
+		Text: &wework.TextContent{Content: m.Text.Content},
+	})
+	if err != nil {
+		slog.Warn("微信客服消息处理失败", "external_user_id", m.ExternalUserID, "err", err)
+	}
+	c.send(m, c.await(resp))
+}
+
+// await 等待回复完成，返回要发送的消息（欢迎卡片转为文字放在回答之前）
+func (c *Channel) await(resp *wework.WeWorkResponse) []string {
+	if resp == nil {
+		return nil
+	}
+
+	var messages []string
+	if text := relay.CardText(resp.TemplateCard); text != "" {
+		messages = append(messages, text)
+	}
+	relay.Follow(c.handler, resp, refreshInterval, replyTimeout, func(content string, finish bool) {
+		if finish && strings.TrimSpace(content) != "" {
+			messages = append(messages, content)
+		}
+	})
+	return messages
+}
+
+// send 按长度限制拆分后发送给客户
+func (c *Channel) send(m wework.KFMessage, messages []string) {
+	var chunks []string
+	for _, message := range messages {
+		chunks = append(chunks, relay.Split(message, wework.KFTextMaxBytes)...)
+	}
+	if len(chunks) > maxReplyMessages {
+		slog.Warn("微信客服回复过长，仅发送前几条", "messages", len(chunks), "max", maxReplyMessages, "external_user_id", m.ExternalUserID)
+		chunks = chunks[:maxReplyMessages]
+	}
+
+	for _, chunk := range chunks {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		_, err := c.client.SendText(ctx, m.ExternalUserID, m.OpenKfID, chunk)
+		cancel()
+		if err != nil {
+			slog.Warn("微信客服回复发送失败", "external_user_id", m.ExternalUserID, "err", err)
+			return
+		}
+	}
+}
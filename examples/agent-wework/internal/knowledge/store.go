@@ -0,0 +1,293 @@
+package knowledge
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// DefaultChunkSize 默认分块大小（字符数）
+const DefaultChunkSize = 500
+
+// Document 知识库文档
+type Document struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Source    string    `json:"source,omitempty"` // 来源：helpdesk、upload等
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// chunk 文档分块（检索单元）
+type chunk struct {
+	docID string
+	text  string
+	terms map[string]int
+}
+
+// SearchResult 检索结果
+type SearchResult struct {
+	DocID string  `json:"doc_id"`
+	Title string  `json:"title"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// Store 知识库存储（JSON文件持久化 + 内存关键词索引）
+type Store struct {
+	path      string
+	chunkSize int
+	docs      map[string]*Document
+	chunks    []chunk
+	mutex     sync.RWMutex
+}
+
+// NewStore 创建知识库存储并加载已有文档
+func NewStore(path string, chunkSize int) (*Store, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	s := &Store{
+		path:      path,
+		chunkSize: chunkSize,
+		docs:      make(map[string]*Document),
+	}
+
+	var docs []*Document
+	if _, err := fsutil.ReadJSON(path, &docs); err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		s.docs[d.ID] = d
+	}
+	s.rebuildIndex()
+
+	return s, nil
+}
+
+// Add 添加文档（不自动持久化，调用方需调用Save）
+func (s *Store) Add(title, content, source string, tags ...string) *Document {
+	doc := &Document{
+		ID:        newDocID(),
+		Title:     title,
+		Content:   content,
+		Source:    source,
+		Tags:      tags,
+		CreatedAt: time.Now(),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.docs[doc.ID] = doc
+	s.chunks = append(s.chunks, s.splitDocument(doc)...)
+	return doc
+}
+
+// Get 获取文档
+func (s *Store) Get(id string) (*Document, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	doc, ok := s.docs[id]
+	return doc, ok
+}
+
+// List 按创建时间列出所有文档
+func (s *Store) List() []*Document {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	docs := make([]*Document, 0, len(s.docs))
+	for _, d := range s.docs {
+		docs = append(docs, d)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].CreatedAt.Before(docs[j].CreatedAt) })
+	return docs
+}
+
+// Delete 删除文档
+func (s *Store) Delete(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.docs[id]; !ok {
+		return false
+	}
+	delete(s.docs, id)
+
+	kept := s.chunks[:0]
+	for _, c := range s.chunks {
+		if c.docID != id {
+			kept = append(kept, c)
+		}
+	}
+	s.chunks = kept
+	return true
+}
+
+// Reindex 重建检索索引，返回分块数量
+func (s *Store) Reindex() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rebuildIndex()
+	return len(s.chunks)
+}
+
+// Save 持久化到文件
+func (s *Store) Save() error {
+	return fsutil.WriteJSONAtomic(s.path, s.List())
+}
+
+// Search 关键词检索（TF-IDF打分），返回最相关的topK个分块
+func (s *Store) Search(query string, topK int) []SearchResult {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 || len(s.chunks) == 0 {
+		return nil
+	}
+
+	// 计算文档频率
+	df := make(map[string]int)
+	for _, c := range s.chunks {
+		for term := range queryTerms {
+			if c.terms[term] > 0 {
+				df[term]++
+			}
+		}
+	}
+
+	var results []SearchResult
+	total := float64(len(s.chunks))
+	for _, c := range s.chunks {
+		var score float64
+		for term := range queryTerms {
+			if tf := c.terms[term]; tf > 0 {
+				idf := math.Log(1 + total/float64(df[term]))
+				score += (1 + math.Log(float64(tf))) * idf
+			}
+		}
+		if score > 0 {
+			results = append(results, SearchResult{
+				DocID: c.docID,
+				Title: s.docs[c.docID].Title,
+				Text:  c.text,
+				Score: score,
+			})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
+// rebuildIndex 重建全部分块（调用方需持有写锁）
+func (s *Store) rebuildIndex() {
+	s.chunks = s.chunks[:0]
+	for _, doc := range s.docs {
+		s.chunks = append(s.chunks, s.splitDocument(doc)...)
+	}
+}
+
+// splitDocument 按段落将文档切分为不超过chunkSize的分块
+func (s *Store) splitDocument(doc *Document) []chunk {
+	var chunks []chunk
+	var current strings.Builder
+
+	flush := func() {
+		text := strings.TrimSpace(current.String())
+		if text != "" {
+			chunks = append(chunks, chunk{
+				docID: doc.ID,
+				text:  text,
+				terms: countTerms(doc.Title + "\n" + text),
+			})
+		}
+		current.Reset()
+	}
+
+	for _, para := range strings.Split(doc.Content, "\n\n") {
+		runes := []rune(para)
+		for len(runes) > s.chunkSize {
+			flush()
+			current.WriteString(string(runes[:s.chunkSize]))
+			flush()
+			runes = runes[s.chunkSize:]
+		}
+		if len([]rune(current.String()))+len(runes) > s.chunkSize {
+			flush()
+		}
+		current.WriteString(string(runes))
+		current.WriteString("\n\n")
+	}
+	flush()
+
+	return chunks
+}
+
+// tokenize 分词：英文按单词，中文按二元组
+func tokenize(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for term := range countTerms(text) {
+		terms[term] = true
+	}
+	return terms
+}
+
+// countTerms 统计词频
+func countTerms(text string) map[string]int {
+	counts := make(map[string]int)
+	var word []rune
+	var prevHan rune
+
+	flushWord := func() {
+		if len(word) > 1 {
+			counts[strings.ToLower(string(word))]++
+		}
+		word = word[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			if prevHan != 0 {
+				counts[string([]rune{prevHan, r})]++
+			}
+			prevHan = r
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			prevHan = 0
+			word = append(word, r)
+		default:
+			prevHan = 0
+			flushWord()
+		}
+	}
+	flushWord()
+
+	return counts
+}
+
+// newDocID 生成文档ID
+func newDocID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "kb_" + strings.ReplaceAll(time.Now().Format("150405.000000"), ".", "")
+	}
+	return "kb_" + hex.EncodeToString(b)
+}
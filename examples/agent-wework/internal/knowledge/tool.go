@@ -0,0 +1,71 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// SearchTool 知识库检索工具（供Agent调用）
+type SearchTool struct {
+	store *Store
+	topK  int
+}
+
+// NewSearchTool 创建知识库检索工具
+func NewSearchTool(store *Store, topK int) *SearchTool {
+	if topK <= 0 {
+		topK = 3
+	}
+	return &SearchTool{store: store, topK: topK}
+}
+
+// Name implements interfaces.Tool.Name
+func (t *SearchTool) Name() string {
+	return "search_knowledge_base"
+}
+
+// Description implements interfaces.Tool.Description
+func (t *SearchTool) Description() string {
+	return "检索企业内部知识库（包括历史工单处理记录和技术文档），回答IT问题前优先使用。输入关键词或问题描述。"
+}
+
+// Parameters implements interfaces.Tool.Parameters
+func (t *SearchTool) Parameters() map[string]interfaces.ParameterSpec {
+	return map[string]interfaces.ParameterSpec{
+		"query": {
+			Type:        "string",
+			Description: "检索关键词或问题描述",
+			Required:    true,
+		},
+	}
+}
+
+// Run implements interfaces.Tool.Run
+func (t *SearchTool) Run(ctx context.Context, input string) (string, error) {
+	results := t.store.Search(input, t.topK)
+	if len(results) == 0 {
+		return "知识库中没有找到相关内容", nil
+	}
+
+	var sb strings.Builder
+	for i, r := range results {
+		sb.WriteString(fmt.Sprintf("[%d] %s (文档ID: %s)\n%s\n\n", i+1, r.Title, r.DocID, r.Text))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// Execute implements interfaces.Tool.Execute
+func (t *SearchTool) Execute(ctx context.Context, args string) (string, error) {
+	var params struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil || params.Query == "" {
+		// 兼容直接传入文本的情况
+		return t.Run(ctx, args)
+	}
+	return t.Run(ctx, params.Query)
+}
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
 
@@ -23,6 +24,11 @@ func CreateLLMFromConfig(cfg *config.Config, logger logging.Logger) (interfaces.
 		llmName = override
 	}
 
+	return CreateLLMByName(cfg, llmName, logger)
+}
+
+// CreateLLMByName 根据提供商名称创建LLM客户端
+func CreateLLMByName(cfg *config.Config, llmName string, logger logging.Logger) (interfaces.LLM, error) {
 	// 查找对应的provider配置
 	provider, ok := cfg.LLM.Providers[llmName]
 	if !ok {
@@ -35,7 +41,7 @@ func CreateLLMFromConfig(cfg *config.Config, logger logging.Logger) (interfaces.
 
 	// 如果启用思考模式，输出提示信息
 	if provider.ThinkingMode {
-		fmt.Printf("🧠 深入思考模式已启用 (Provider: %s)\n", provider.Provider)
+		slog.Info("深入思考模式已启用", "provider", provider.Provider)
 	}
 
 	return createLLMClient(provider, logger)
@@ -57,17 +63,15 @@ func createLLMClient(config config.LLMProviderConfig, logger logging.Logger) (in
 			if reasoningLevel == "" {
 				reasoningLevel = "minimal" // 默认简洁
 			}
-			fmt.Printf("✅ Ollama 思考模式已启用 (推理级别: %s", reasoningLevel)
 
 			wrapper := NewOpenAIThinkingWrapperWithLevel(client, reasoningLevel)
 
 			// 如果配置了温度，设置温度
 			if config.Temperature > 0 {
 				wrapper.WithTemperature(config.Temperature)
-				fmt.Printf(", 温度: %.1f", config.Temperature)
 			}
 
-			fmt.Printf(")\n")
+			slog.Info("Ollama 思考模式已启用", "reasoning_level", reasoningLevel, "temperature", config.Temperature)
 			return wrapper, nil
 		}
 
@@ -90,7 +94,7 @@ func createLLMClient(config config.LLMProviderConfig, logger logging.Logger) (in
 			if reasoningLevel == "" {
 				reasoningLevel = "minimal" // 默认简洁
 			}
-			fmt.Printf("✅ 千问 思考模式已启用 (推理级别: %s)\n", reasoningLevel)
+			slog.Info("千问 思考模式已启用", "reasoning_level", reasoningLevel)
 			return NewOpenAIThinkingWrapperWithLevel(client, reasoningLevel), nil
 		}
 
@@ -121,7 +125,7 @@ func createLLMClient(config config.LLMProviderConfig, logger logging.Logger) (in
 			if reasoningLevel == "" {
 				reasoningLevel = "minimal" // 默认简洁
 			}
-			fmt.Printf("✅ OpenAI 思考模式已启用 (模型: %s, 推理级别: %s)\n", config.Model, reasoningLevel)
+			slog.Info("OpenAI 思考模式已启用", "model", config.Model, "reasoning_level", reasoningLevel)
 			return NewOpenAIThinkingWrapperWithLevel(client, reasoningLevel), nil
 		}
 
@@ -140,11 +144,11 @@ func createLLMClient(config config.LLMProviderConfig, logger logging.Logger) (in
 
 		// 检查是否支持thinking mode
 		if config.ThinkingMode && anthropic.SupportsThinking(config.Model) {
-			fmt.Printf("✅ 模型 %s 支持深入思考模式\n", config.Model)
+			slog.Info("模型支持深入思考模式", "model", config.Model)
 			// 创建包装客户端以启用thinking
 			return NewThinkingLLMWrapper(client, config.Model), nil
 		} else if config.ThinkingMode {
-			fmt.Printf("⚠️  警告: 模型 %s 不支持深入思考模式\n", config.Model)
+			slog.Warn("模型不支持深入思考模式", "model", config.Model)
 		}
 
 		return client, nil
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// toolsFetchTimeout 后台获取工具列表的超时
+const toolsFetchTimeout = 10 * time.Second
+
+// cachedServer 缓存工具列表的MCP服务器包装
+//
+// Agent每次运行都会调用ListTools，缓存后回复无需等待MCP服务器往返；
+// 列表过期后先返回旧列表并在后台刷新，Close由原服务器的所有者负责。
+type cachedServer struct {
+	interfaces.MCPServer
+	name       string
+	ttl        time.Duration
+	tools      []interfaces.MCPTool
+	fetched    time.Time
+	refreshing bool
+	mutex      sync.Mutex
+}
+
+// WithCachedTools 为服务器包装工具列表缓存，并在后台预先获取工具列表
+func WithCachedTools(servers []NamedServer, ttl time.Duration) []NamedServer {
+	cached := make([]NamedServer, len(servers))
+	for i, server := range servers {
+		s := &cachedServer{MCPServer: server.Server, name: server.Name, ttl: ttl}
+		s.refreshing = true
+		go s.refresh()
+		cached[i] = NamedServer{Name: server.Name, Server: s}
+	}
+	return cached
+}
+
+// ListTools 返回缓存的工具列表（尚未获取成功时直接请求服务器）
+func (s *cachedServer) ListTools(ctx context.Context) ([]interfaces.MCPTool, error) {
+	s.mutex.Lock()
+	if s.fetched.IsZero() {
+		s.mutex.Unlock()
+		tools, err := s.MCPServer.ListTools(ctx)
+		if err == nil {
+			s.store(tools)
+		}
+		return tools, err
+	}
+
+	tools := s.tools
+	if time.Since(s.fetched) >= s.ttl && !s.refreshing {
+		s.refreshing = true
+		go s.refresh()
+	}
+	s.mutex.Unlock()
+	return tools, nil
+}
+
+// refresh 在后台重新获取工具列表（失败时保留旧列表，下次调用再试）
+func (s *cachedServer) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), toolsFetchTimeout)
+	defer cancel()
+
+	tools, err := s.MCPServer.ListTools(ctx)
+	if err != nil {
+		slog.Warn("获取MCP工具列表失败", "server", s.name, "err", err)
+		s.mutex.Lock()
+		s.refreshing = false
+		s.mutex.Unlock()
+		return
+	}
+	s.store(tools)
+}
+
+// store 保存工具列表
+func (s *cachedServer) store(tools []interfaces.MCPTool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tools = tools
+	s.fetched = time.Now()
+	s.refreshing = false
+}
+
+// Close 不关闭共享的底层服务器
+func (s *cachedServer) Close() error {
+	return nil
+}
@@ -3,45 +3,88 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
 	"github.com/Ingenimax/agent-sdk-go/pkg/mcp"
 
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
 	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
-	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/session"
+	"github.com/deepsage-ai/b0dy/pkg/session"
 )
 
+// NamedServer 带配置名称的MCP服务器（用于按群聊筛选工具集）
+type NamedServer struct {
+	Name   string
+	Server interfaces.MCPServer
+}
+
 // CreateMCPServersFromConfig 根据配置创建MCP服务器列表
 func CreateMCPServersFromConfig(cfg *config.Config) ([]interfaces.MCPServer, error) {
-	var servers []interfaces.MCPServer
+	named, err := CreateNamedMCPServersFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return ServersOf(named), nil
+}
+
+// ServersOf 提取MCP服务器列表
+func ServersOf(named []NamedServer) []interfaces.MCPServer {
+	servers := make([]interfaces.MCPServer, 0, len(named))
+	for _, n := range named {
+		servers = append(servers, n.Server)
+	}
+	return servers
+}
+
+// CreateNamedMCPServersFromConfig 根据配置创建带名称的MCP服务器列表
+func CreateNamedMCPServersFromConfig(cfg *config.Config) ([]NamedServer, error) {
+	var servers []NamedServer
 
 	for _, serverConfig := range cfg.MCP.Servers {
 		// 检查是否通过环境变量禁用
 		if isDisabledByEnv(serverConfig.Name) {
-			fmt.Printf("⏭️  跳过MCP服务器: %s (被环境变量禁用)\n", serverConfig.Name)
+			slog.Info("跳过MCP服务器（被环境变量禁用）", "server", serverConfig.Name)
 			continue
 		}
 
 		if !serverConfig.Enabled {
-			fmt.Printf("⏭️  跳过MCP服务器: %s (配置中禁用)\n", serverConfig.Name)
+			slog.Info("跳过MCP服务器（配置中禁用）", "server", serverConfig.Name)
 			continue
 		}
 
 		// 处理环境变量
 		processServerEnvVars(&serverConfig)
 
+		// 回放模式不连接真实服务器
+		if mode := vcrMode(serverConfig); mode == session.VCRPlayback {
+			sessionManager, err := newVCRSessionManager(serverConfig, mode)
+			if err != nil {
+				slog.Warn("MCP服务器回放模式启动失败", "server", serverConfig.Name, "err", err)
+				continue
+			}
+			servers = append(servers, NamedServer{Name: serverConfig.Name, Server: sessionManager})
+			slog.Info("配置MCP服务器（回放模式）", "server", serverConfig.Name, "cassette", cassettePath(serverConfig))
+			continue
+		}
+
 		server, err := createMCPServer(serverConfig)
 		if err != nil {
-			fmt.Printf("⚠️  警告: 创建MCP服务器 '%s' 失败: %v\n", serverConfig.Name, err)
+			slog.Warn("创建MCP服务器失败", "server", serverConfig.Name, "err", err)
 			continue
 		}
 
 		// HTTP类型包装为SessionMCPManager以支持连接复用
 		if serverConfig.Type == "http" {
-			sessionManager := session.NewSessionMCPManager(serverConfig.BaseURL)
+			sessionManager, err := newVCRSessionManager(serverConfig, vcrMode(serverConfig))
+			if err != nil {
+				slog.Warn("MCP服务器录制模式启动失败", "server", serverConfig.Name, "err", err)
+				continue
+			}
 
 			// 尝试初始连接测试
 			testCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -51,34 +94,99 @@ func CreateMCPServersFromConfig(cfg *config.Config) ([]interfaces.MCPServer, err
 			if testErr != nil {
 				// 分析错误类型并提供友好提示
 				errMsg := analyzeConnectionError(serverConfig.Name, serverConfig.BaseURL, testErr)
-				fmt.Printf("⚠️  警告: MCP服务器 '%s' 连接测试失败\n%s", serverConfig.Name, errMsg)
-				fmt.Printf("   ℹ️  该服务器将被跳过，但您仍可以启动服务\n\n")
+				slog.Warn("MCP服务器连接测试失败，该服务器将被跳过", "server", serverConfig.Name, "detail", errMsg)
 				continue
 			}
 
-			servers = append(servers, sessionManager)
-			fmt.Printf("✅ 配置MCP服务器: %s (HTTP/SSE，连接正常)\n", serverConfig.Name)
+			servers = append(servers, NamedServer{Name: serverConfig.Name, Server: sessionManager})
+			slog.Info("配置MCP服务器（HTTP/SSE，连接正常）", "server", serverConfig.Name)
 		} else {
-			servers = append(servers, server)
-			fmt.Printf("✅ 配置MCP服务器: %s (Stdio)\n", serverConfig.Name)
+			servers = append(servers, NamedServer{Name: serverConfig.Name, Server: server})
+			slog.Info("配置MCP服务器（Stdio）", "server", serverConfig.Name)
 		}
 	}
 
 	// 检查是否有额外的MCP服务器通过环境变量添加
 	if extraServer := os.Getenv("MCP_EXTRA_SERVER"); extraServer != "" {
-		sessionManager := session.NewSessionMCPManager(extraServer)
-		servers = append(servers, sessionManager)
-		fmt.Printf("✅ 添加额外MCP服务器: %s (通过环境变量)\n", extraServer)
+		sessionManager := session.NewSessionMCPManager(extraServer, session.WithContentExtraction(), session.WithLogf(applog.Logf))
+		servers = append(servers, NamedServer{Name: "extra", Server: sessionManager})
+		slog.Info("添加额外MCP服务器（通过环境变量）", "url", extraServer)
+	}
+
+	// 记录工具调用耗时指标
+	for i := range servers {
+		servers[i].Server = newInstrumentedServer(servers[i].Name, servers[i].Server)
 	}
 
 	// 显示MCP服务器配置汇总
 	if len(servers) > 0 {
-		fmt.Printf("✅ MCP工具服务配置完成，成功加载 %d 个服务器\n", len(servers))
+		slog.Info("MCP工具服务配置完成", "servers", len(servers))
 	}
 
 	return servers, nil
 }
 
+// vcrMode 获取服务器的录制/回放模式（环境变量 MCP_VCR_MODE 对所有HTTP服务器生效）
+func vcrMode(serverConfig config.MCPServerConfig) string {
+	if serverConfig.Type != "http" {
+		return ""
+	}
+	if mode := os.Getenv("MCP_VCR_MODE"); mode != "" {
+		return mode
+	}
+	return serverConfig.VCR
+}
+
+// cassettePath 获取录制文件路径
+func cassettePath(serverConfig config.MCPServerConfig) string {
+	if serverConfig.Cassette != "" {
+		return serverConfig.Cassette
+	}
+	dir := os.Getenv("MCP_VCR_DIR")
+	if dir == "" {
+		dir = "data/cassettes"
+	}
+	return filepath.Join(dir, serverConfig.Name+".json")
+}
+
+// newVCRSessionManager 创建会话管理器，mode非空时启用录制/回放
+func newVCRSessionManager(serverConfig config.MCPServerConfig, mode string) (*session.SessionMCPManager, error) {
+	sessionManager := session.NewSessionMCPManager(serverConfig.BaseURL, session.WithContentExtraction(), session.WithLogf(applog.Logf))
+	if mode == "" {
+		return sessionManager, nil
+	}
+	if err := sessionManager.EnableVCR(mode, cassettePath(serverConfig)); err != nil {
+		return nil, err
+	}
+	if mode == session.VCRRecord {
+		slog.Info("MCP服务器录制中", "server", serverConfig.Name, "cassette", cassettePath(serverConfig))
+	}
+	return sessionManager, nil
+}
+
+// CheckServer 连接单个MCP服务器并列出工具（用于部署前自检），返回工具数量
+func CheckServer(ctx context.Context, serverConfig config.MCPServerConfig) (int, error) {
+	processServerEnvVars(&serverConfig)
+
+	var server interfaces.MCPServer
+	if serverConfig.Type == "http" {
+		server = session.NewSessionMCPManager(serverConfig.BaseURL, session.WithContentExtraction(), session.WithLogf(applog.Logf))
+	} else {
+		created, err := createMCPServer(serverConfig)
+		if err != nil {
+			return 0, err
+		}
+		server = created
+	}
+	defer server.Close()
+
+	tools, err := server.ListTools(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s", strings.TrimSpace(analyzeConnectionError(serverConfig.Name, serverConfig.BaseURL, err)))
+	}
+	return len(tools), nil
+}
+
 // createMCPServer 创建单个MCP服务器
 func createMCPServer(config config.MCPServerConfig) (interfaces.MCPServer, error) {
 	ctx := context.Background()
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/applog"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/errreport"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+	"github.com/deepsage-ai/b0dy/pkg/metrics"
+)
+
+// instrumentedServer 记录工具调用耗时的MCP服务器包装
+type instrumentedServer struct {
+	interfaces.MCPServer
+	name string
+}
+
+// newInstrumentedServer 包装MCP服务器
+func newInstrumentedServer(name string, server interfaces.MCPServer) *instrumentedServer {
+	return &instrumentedServer{MCPServer: server, name: name}
+}
+
+// CallTool 调用工具并记录耗时（日志带上下文中的请求ID）
+func (s *instrumentedServer) CallTool(ctx context.Context, name string, args interface{}) (*interfaces.MCPToolResponse, error) {
+	start := time.Now()
+	response, err := s.MCPServer.CallTool(ctx, name, args)
+	failure := err
+	if err == nil && response != nil && response.IsError {
+		failure = errToolFailed
+	}
+	metrics.ObserveMCPTool(s.name, name, start, failure)
+	errreport.ToolResult(ctx, s.name, name, failure)
+	if failure != nil {
+		publishToolFailed(ctx, s.name, name, err, response, time.Since(start))
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "MCP工具调用失败", "server", s.name, "tool", name, "duration", time.Since(start), "err", err)
+	} else {
+		slog.DebugContext(ctx, "MCP工具调用", "server", s.name, "tool", name, "duration", time.Since(start))
+	}
+	return response, err
+}
+
+// publishToolFailed 在任务的事件总线上发布工具调用失败（任务外的调用上下文中没有总线，为空操作）
+func publishToolFailed(ctx context.Context, server, tool string, err error, response *interfaces.MCPToolResponse, duration time.Duration) {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	} else if response != nil {
+		message = toolErrorText(response)
+	}
+	conversationID, _ := memory.GetConversationID(ctx)
+	events.FromContext(ctx).Publish(events.ToolFailed{
+		RequestID:      applog.RequestID(ctx),
+		ConversationID: conversationID,
+		Server:         server,
+		Tool:           tool,
+		Error:          message,
+		Duration:       duration,
+		Time:           time.Now(),
+	})
+}
+
+// maxToolErrorLen 事件中工具错误内容的最大长度（字节）
+const maxToolErrorLen = 1000
+
+// toolErrorText 工具返回的错误结果内容（过长时截断）
+func toolErrorText(response *interfaces.MCPToolResponse) string {
+	var text string
+	switch content := response.Content.(type) {
+	case nil:
+		return errToolFailed.Error()
+	case string:
+		text = content
+	default:
+		data, err := json.Marshal(content)
+		if err != nil {
+			return errToolFailed.Error()
+		}
+		text = string(data)
+	}
+	if len(text) > maxToolErrorLen {
+		text = strings.ToValidUTF8(text[:maxToolErrorLen], "") + "..."
+	}
+	return text
+}
+
+// errToolFailed 工具返回错误结果（用于指标状态标签）
+var errToolFailed = errors.New("tool returned error")
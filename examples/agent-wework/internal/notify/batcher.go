@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 紧急程度
+const (
+	UrgencyNormal = "normal" // 合并到摘要中延迟发送
+	UrgencyUrgent = "urgent" // 立即发送，不参与合并
+)
+
+// Notification 主动推送的通知
+type Notification struct {
+	Target   string    // 会话标识：single_<userid> 或 group_<chatid>
+	Category string    // 通知类别（决定紧急程度）
+	Title    string    // 标题
+	Content  string    // 正文
+	Time     time.Time // 产生时间
+}
+
+// Options 合并发送参数
+type Options struct {
+	Window     time.Duration     // 合并窗口：窗口内同一会话的通知合并为一条摘要
+	MaxBatch   int               // 单条摘要最多包含的通知数，达到后立即发送
+	Categories map[string]string // 类别 -> 紧急程度
+}
+
+// Batcher 通知合并器 - 将窗口内发往同一会话的低优先级通知合并为一条摘要
+type Batcher struct {
+	sender  Sender
+	options Options
+	pending map[string][]Notification // target -> 待发送通知
+	timers  map[string]*time.Timer
+	mutex   sync.Mutex
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+// NewBatcher 创建通知合并器
+func NewBatcher(sender Sender, options Options) *Batcher {
+	if options.Window <= 0 {
+		options.Window = 5 * time.Minute
+	}
+	if options.MaxBatch <= 0 {
+		options.MaxBatch = 10
+	}
+	return &Batcher{
+		sender:  sender,
+		options: options,
+		pending: make(map[string][]Notification),
+		timers:  make(map[string]*time.Timer),
+	}
+}
+
+// Notify 提交通知：紧急类别立即发送，其余进入合并窗口
+func (b *Batcher) Notify(n Notification) error {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	if b.isUrgent(n.Category) {
+		return b.sender.Send(context.Background(), n.Target, formatSingle(n))
+	}
+
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return fmt.Errorf("通知合并器已关闭")
+	}
+
+	b.pending[n.Target] = append(b.pending[n.Target], n)
+	if len(b.pending[n.Target]) >= b.options.MaxBatch {
+		batch := b.takeLocked(n.Target)
+		b.mutex.Unlock()
+		return b.send(n.Target, batch)
+	}
+
+	if _, ok := b.timers[n.Target]; !ok {
+		target := n.Target
+		b.timers[target] = time.AfterFunc(b.options.Window, func() { b.Flush(target) })
+	}
+	b.mutex.Unlock()
+	return nil
+}
+
+// Flush 立即发送某个会话的待发送通知
+func (b *Batcher) Flush(target string) error {
+	b.mutex.Lock()
+	batch := b.takeLocked(target)
+	b.mutex.Unlock()
+
+	return b.send(target, batch)
+}
+
+// Pending 获取待发送通知数量
+func (b *Batcher) Pending() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	count := 0
+	for _, batch := range b.pending {
+		count += len(batch)
+	}
+	return count
+}
+
+// Close 发送所有待发送通知并停止接收
+func (b *Batcher) Close() {
+	b.mutex.Lock()
+	b.closed = true
+	targets := make([]string, 0, len(b.pending))
+	for target := range b.pending {
+		targets = append(targets, target)
+	}
+	b.mutex.Unlock()
+
+	for _, target := range targets {
+		if err := b.Flush(target); err != nil {
+			slog.Warn("关闭时发送通知失败", "target", target, "err", err)
+		}
+	}
+	b.wg.Wait()
+}
+
+// takeLocked 取出会话的待发送通知并停止计时器（调用方需持有锁）
+func (b *Batcher) takeLocked(target string) []Notification {
+	if timer, ok := b.timers[target]; ok {
+		timer.Stop()
+		delete(b.timers, target)
+	}
+	batch := b.pending[target]
+	delete(b.pending, target)
+	if len(batch) > 0 {
+		b.wg.Add(1)
+	}
+	return batch
+}
+
+// send 发送一批通知（单条时不加摘要头）
+func (b *Batcher) send(target string, batch []Notification) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	defer b.wg.Done()
+
+	content := formatSingle(batch[0])
+	if len(batch) > 1 {
+		content = formatDigest(batch)
+	}
+
+	err := b.sender.Send(context.Background(), target, content)
+	if err != nil {
+		slog.Warn("发送通知摘要失败", "target", target, "err", err)
+	}
+	return err
+}
+
+// isUrgent 判断类别是否为紧急
+func (b *Batcher) isUrgent(category string) bool {
+	return b.options.Categories[category] == UrgencyUrgent
+}
+
+// formatSingle 格式化单条通知
+func formatSingle(n Notification) string {
+	if n.Title == "" {
+		return n.Content
+	}
+	return fmt.Sprintf("**%s**\n%s", n.Title, n.Content)
+}
+
+// formatDigest 格式化通知摘要
+func formatDigest(batch []Notification) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📬 您有 %d 条新通知\n", len(batch)))
+	for i, n := range batch {
+		line := n.Content
+		if n.Title != "" {
+			line = fmt.Sprintf("**%s**：%s", n.Title, n.Content)
+		}
+		if n.Category != "" {
+			line = fmt.Sprintf("[%s] %s", n.Category, line)
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s `%s`\n", i+1, line, n.Time.Format("15:04")))
+	}
+	return strings.TrimSpace(sb.String())
+}
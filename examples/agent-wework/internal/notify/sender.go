@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// Sender 消息发送接口
+type Sender interface {
+	// Send 向会话发送一条Markdown消息（target为会话标识，如 single_<userid>、group_<chatid>）
+	Send(ctx context.Context, target, content string) error
+}
+
+// WebhookSender 通过企业微信群机器人Webhook发送消息
+type WebhookSender struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSender 创建Webhook发送器
+func NewWebhookSender(url string) *WebhookSender {
+	return &WebhookSender{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send 实现Sender接口，单聊目标以@提及的方式通知到人
+func (s *WebhookSender) Send(ctx context.Context, target, content string) error {
+	if userID, ok := strings.CutPrefix(target, "single_"); ok {
+		content = fmt.Sprintf("<@%s>\n%s", userID, content)
+	}
+
+	return s.post(ctx, map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": content,
+		},
+	})
+}
+
+// SendVoice 上传AMR语音并发送语音消息（不超过2MB、播放时长不超过60秒）
+func (s *WebhookSender) SendVoice(ctx context.Context, amr []byte) error {
+	mediaID, err := s.upload(ctx, "voice", "reply.amr", amr)
+	if err != nil {
+		return err
+	}
+	return s.post(ctx, map[string]interface{}{
+		"msgtype": "voice",
+		"voice": map[string]string{
+			"media_id": mediaID,
+		},
+	})
+}
+
+// upload 上传临时素材，返回media_id（上传地址与发送地址使用同一个key）
+func (s *WebhookSender) upload(ctx context.Context, mediaType, filename string, data []byte) (string, error) {
+	uploadURL := strings.Replace(s.url, "/webhook/send", "/webhook/upload_media", 1) + "&type=" + mediaType
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("media", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var result struct {
+		MediaID string `json:"media_id"`
+	}
+	if err := s.do(req, &result); err != nil {
+		return "", fmt.Errorf("上传素材失败: %w", err)
+	}
+	return result.MediaID, nil
+}
+
+// post 发送消息
+func (s *WebhookSender) post(ctx context.Context, message map[string]interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := s.do(req, nil); err != nil {
+		return fmt.Errorf("发送通知失败: %w", err)
+	}
+	return nil
+}
+
+// do 调用群机器人接口，errcode非0时返回错误，result非nil时解析响应
+func (s *WebhookSender) do(req *http.Request, result interface{}) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("通知接口返回错误: %d, %s", resp.StatusCode, string(body))
+	}
+
+	var status struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &status); err == nil && status.ErrCode != 0 {
+		return fmt.Errorf("通知接口返回错误: %d, %s", status.ErrCode, status.ErrMsg)
+	}
+	if result != nil {
+		return json.Unmarshal(body, result)
+	}
+	return nil
+}
+
+// AppSender 通过自建应用消息接口发给单聊用户本人（只支持 single_<userid> 目标）
+type AppSender struct {
+	client *wework.AppClient
+}
+
+// NewAppSender 创建应用消息发送器
+func NewAppSender(client *wework.AppClient) *AppSender {
+	return &AppSender{client: client}
+}
+
+// Send 实现Sender接口，群聊目标返回错误（应用消息无法发到智能机器人所在的群）
+func (s *AppSender) Send(ctx context.Context, target, content string) error {
+	userID, ok := strings.CutPrefix(target, "single_")
+	if !ok {
+		return fmt.Errorf("应用消息只能发送给单聊用户: %s", target)
+	}
+	return s.client.SendMarkdown(ctx, userID, content)
+}
@@ -0,0 +1,101 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// Recognizer 文字识别服务接口
+type Recognizer interface {
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// Downloader 下载图片（企业微信图片需解密），超过maxBytes时返回错误
+type Downloader func(url string, maxBytes int64) ([]byte, error)
+
+// Service 图片文字识别服务 - 负责下载、数量/大小限制和结果拼接
+type Service struct {
+	recognizer Recognizer
+	timeout    time.Duration
+	maxImages  int
+	maxBytes   int64
+	maxChars   int
+}
+
+// NewService 创建识别服务
+func NewService(recognizer Recognizer, cfg config.OCRConfig) *Service {
+	return &Service{
+		recognizer: recognizer,
+		timeout:    time.Duration(cfg.Timeout) * time.Second,
+		maxImages:  cfg.MaxImages,
+		maxBytes:   int64(cfg.MaxSize) * 1024,
+		maxChars:   cfg.MaxChars,
+	}
+}
+
+// NewServiceFromConfig 根据配置创建识别服务，未启用时返回nil
+func NewServiceFromConfig(cfg config.OCRConfig) (*Service, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Provider {
+	case "paddleocr":
+		return NewService(NewPaddleOCR(cfg.BaseURL), cfg), nil
+	case "baidu":
+		return NewService(NewBaiduOCR(cfg.APIKey, cfg.SecretKey, cfg.BaseURL), cfg), nil
+	default:
+		return nil, fmt.Errorf("不支持的OCR服务: %s", cfg.Provider)
+	}
+}
+
+// Extract 依次下载并识别图片，返回拼接后的文字（超过max_images的图片忽略，失败的图片跳过），
+// 没有识别到任何文字时返回空字符串
+func (s *Service) Extract(ctx context.Context, urls []string, download Downloader) string {
+	if len(urls) > s.maxImages {
+		urls = urls[:s.maxImages]
+	}
+
+	var parts []string
+	for i, url := range urls {
+		text, err := s.recognize(ctx, url, download)
+		if err != nil {
+			slog.Warn("图片文字识别失败", "image", i+1, "err", err)
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		if len(urls) > 1 {
+			text = fmt.Sprintf("图片%d：\n%s", i+1, text)
+		}
+		parts = append(parts, text)
+	}
+
+	result := strings.Join(parts, "\n\n")
+	if runes := []rune(result); len(runes) > s.maxChars {
+		result = string(runes[:s.maxChars]) + "…（识别文字过长，已截断）"
+	}
+	return result
+}
+
+// recognize 下载并识别单张图片
+func (s *Service) recognize(ctx context.Context, url string, download Downloader) (string, error) {
+	image, err := download(url, s.maxBytes)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	text, err := s.recognizer.Recognize(ctx, image)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
@@ -0,0 +1,209 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PaddleOCR 基于自建PaddleOCR服务（PaddleHub Serving 的 ocr_system 模块）的识别实现
+type PaddleOCR struct {
+	url    string
+	client *http.Client
+}
+
+// NewPaddleOCR 创建PaddleOCR识别器（url为完整的预测接口地址）
+func NewPaddleOCR(url string) *PaddleOCR {
+	return &PaddleOCR{url: url, client: &http.Client{}}
+}
+
+// Recognize 实现Recognizer接口
+func (p *PaddleOCR) Recognize(ctx context.Context, image []byte) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"images": []string{base64.StdEncoding.EncodeToString(image)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, err := doRequest(p.client, req, "PaddleOCR")
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Status  string `json:"status"`
+		Msg     string `json:"msg"`
+		Results [][]struct {
+			Text string `json:"text"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("解析PaddleOCR响应失败: %w", err)
+	}
+	if result.Status != "" && result.Status != "000" {
+		return "", fmt.Errorf("PaddleOCR返回错误: %s %s", result.Status, result.Msg)
+	}
+
+	var lines []string
+	for _, image := range result.Results {
+		for _, line := range image {
+			lines = append(lines, line.Text)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// BaiduOCR 基于百度智能云通用文字识别（标准版）的识别实现
+type BaiduOCR struct {
+	apiKey    string
+	secretKey string
+	baseURL   string
+	client    *http.Client
+
+	mutex     sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewBaiduOCR 创建百度OCR识别器
+func NewBaiduOCR(apiKey, secretKey, baseURL string) *BaiduOCR {
+	if baseURL == "" {
+		baseURL = "https://aip.baidubce.com"
+	}
+	return &BaiduOCR{
+		apiKey:    apiKey,
+		secretKey: secretKey,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		client:    &http.Client{},
+	}
+}
+
+// Recognize 实现Recognizer接口
+func (b *BaiduOCR) Recognize(ctx context.Context, image []byte) (string, error) {
+	token, err := b.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{"image": {base64.StdEncoding.EncodeToString(image)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		b.baseURL+"/rest/2.0/ocr/v1/general_basic?access_token="+url.QueryEscape(token),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, err := doRequest(b.client, req, "百度OCR")
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ErrorCode   int    `json:"error_code"`
+		ErrorMsg    string `json:"error_msg"`
+		WordsResult []struct {
+			Words string `json:"words"`
+		} `json:"words_result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("解析百度OCR响应失败: %w", err)
+	}
+	if result.ErrorCode != 0 {
+		if result.ErrorCode == 110 || result.ErrorCode == 111 {
+			b.resetToken() // access_token无效或过期，下次重新获取
+		}
+		return "", fmt.Errorf("百度OCR返回错误: %d %s", result.ErrorCode, result.ErrorMsg)
+	}
+
+	lines := make([]string, len(result.WordsResult))
+	for i, w := range result.WordsResult {
+		lines[i] = w.Words
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// accessToken 获取access_token（有效期30天，提前一小时刷新）
+func (b *BaiduOCR) accessToken(ctx context.Context) (string, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.token != "" && time.Now().Before(b.expiresAt) {
+		return b.token, nil
+	}
+
+	query := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {b.apiKey},
+		"client_secret": {b.secretKey},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+"/oauth/2.0/token?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	body, err := doRequest(b.client, req, "百度OCR鉴权")
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("解析百度OCR鉴权响应失败: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("获取百度OCR access_token失败: %s", result.ErrorDescription)
+	}
+
+	b.token = result.AccessToken
+	b.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Hour)
+	return b.token, nil
+}
+
+// resetToken 清除缓存的access_token
+func (b *BaiduOCR) resetToken() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.token = ""
+}
+
+// doRequest 发送请求并读取响应体，非200时返回错误（错误中不含请求URL，避免泄露密钥）
+func doRequest(client *http.Client, req *http.Request, service string) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("%s请求失败: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s返回错误: %d, %s", service, resp.StatusCode, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,274 @@
+// Package outbound 出站Webhook：将新会话、负面反馈、转人工和工具失败等事件推送到ITSM、告警等企业系统
+//
+// 每个事件以JSON POST到订阅了该事件的地址，配置了密钥时附带HMAC-SHA256签名：
+//
+//	X-B0dy-Event: handoff_requested
+//	X-B0dy-Delivery: <事件ID，重试时不变，可用于去重>
+//	X-B0dy-Timestamp: <Unix秒>
+//	X-B0dy-Signature: sha256=<hex(HMAC-SHA256(secret, timestamp + "." + body))>
+//
+// 接收方按相同方式计算签名并比较，同时检查时间戳与当前时间的偏差以防重放。
+// 发送在后台队列中进行，不阻塞消息处理；失败按指数退避重试，队列满时丢弃并记录日志。
+package outbound
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/events"
+)
+
+// 事件类型
+const (
+	EventConversationStarted = "conversation_started" // 新会话开始
+	EventNegativeFeedback    = "negative_feedback"    // 用户反馈回复有问题
+	EventHandoffRequested    = "handoff_requested"    // 会话转人工
+	EventToolFailed          = "tool_failed"          // MCP工具调用失败
+)
+
+// 请求头
+const (
+	HeaderEvent     = "X-B0dy-Event"
+	HeaderDelivery  = "X-B0dy-Delivery"
+	HeaderTimestamp = "X-B0dy-Timestamp"
+	HeaderSignature = "X-B0dy-Signature"
+)
+
+// Payload 推送的事件内容
+type Payload struct {
+	ID             string                 `json:"id"`
+	Event          string                 `json:"event"`
+	Bot            string                 `json:"bot"`
+	Org            string                 `json:"org,omitempty"` // 机器人所属组织
+	ConversationID string                 `json:"conversation_id,omitempty"`
+	UserID         string                 `json:"user_id,omitempty"`
+	RequestID      string                 `json:"request_id,omitempty"`
+	Data           map[string]interface{} `json:"data,omitempty"`
+	Time           time.Time              `json:"time"`
+}
+
+// delivery 待发送到某个地址的事件
+type delivery struct {
+	endpoint config.OutboundEndpointConfig
+	payload  Payload
+}
+
+// Dispatcher 出站Webhook分发器
+type Dispatcher struct {
+	endpoints []config.OutboundEndpointConfig
+	retries   int
+	client    *http.Client
+	queue     chan delivery
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// New 创建分发器并启动后台发送（未启用时返回nil）
+func New(cfg config.OutboundConfig) *Dispatcher {
+	if !cfg.Enabled {
+		return nil
+	}
+	d := &Dispatcher{
+		endpoints: cfg.Endpoints,
+		retries:   cfg.Retries,
+		client:    &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		queue:     make(chan delivery, cfg.QueueSize),
+		done:      make(chan struct{}),
+	}
+	d.wg.Add(1)
+	go d.run()
+	return d
+}
+
+// Subscribe 订阅机器人事件总线，将支持的事件转换后推送（事件带上机器人名称和所属组织）
+func (d *Dispatcher) Subscribe(bot, org string, bus *events.Bus) {
+	events.Subscribe(bus, func(e events.ConversationStarted) {
+		d.Dispatch(Payload{
+			Event:          EventConversationStarted,
+			Bot:            bot,
+			Org:            org,
+			ConversationID: e.ConversationID,
+			UserID:         e.UserID,
+			RequestID:      e.RequestID,
+			Time:           e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.NegativeFeedback) {
+		d.Dispatch(Payload{
+			Event:          EventNegativeFeedback,
+			Bot:            bot,
+			Org:            org,
+			ConversationID: e.ConversationID,
+			UserID:         e.UserID,
+			RequestID:      e.RequestID,
+			Data:           map[string]interface{}{"comment": e.Comment, "question": e.Question, "answer": e.Answer},
+			Time:           e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.HandoffStarted) {
+		d.Dispatch(Payload{
+			Event:          EventHandoffRequested,
+			Bot:            bot,
+			Org:            org,
+			ConversationID: e.ConversationID,
+			UserID:         e.UserID,
+			Data:           map[string]interface{}{"ticket": e.Ticket, "reason": e.Reason},
+			Time:           e.Time,
+		})
+	})
+	events.Subscribe(bus, func(e events.ToolFailed) {
+		d.Dispatch(Payload{
+			Event:          EventToolFailed,
+			Bot:            bot,
+			Org:            org,
+			ConversationID: e.ConversationID,
+			RequestID:      e.RequestID,
+			Data: map[string]interface{}{
+				"server":      e.Server,
+				"tool":        e.Tool,
+				"error":       e.Error,
+				"duration_ms": e.Duration.Milliseconds(),
+			},
+			Time: e.Time,
+		})
+	})
+}
+
+// Dispatch 将事件放入订阅了该事件的各地址的发送队列（队列满时丢弃）
+func (d *Dispatcher) Dispatch(payload Payload) {
+	if payload.ID == "" {
+		payload.ID = newDeliveryID()
+	}
+	for _, endpoint := range d.endpoints {
+		if len(endpoint.Events) > 0 && !slices.Contains(endpoint.Events, payload.Event) {
+			continue
+		}
+		select {
+		case <-d.done:
+			return
+		default:
+		}
+		select {
+		case d.queue <- delivery{endpoint: endpoint, payload: payload}:
+		default:
+			slog.Warn("出站Webhook队列已满，丢弃事件", "endpoint", endpoint.Name, "event", payload.Event, "conversation_id", payload.ConversationID)
+		}
+	}
+}
+
+// closeTimeout 关闭时等待队列中事件发送完毕的最长时间
+const closeTimeout = 5 * time.Second
+
+// Close 停止接收新事件，等待队列中的事件发送完毕（超时后丢弃）
+func (d *Dispatcher) Close() {
+	d.closeOnce.Do(func() { close(d.done) })
+	finished := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(closeTimeout):
+		slog.Warn("出站Webhook关闭超时，未发送的事件已丢弃", "pending", len(d.queue))
+	}
+}
+
+// run 后台依次发送队列中的事件，关闭后发送完剩余事件再退出
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+	for {
+		select {
+		case item := <-d.queue:
+			d.deliver(item)
+		case <-d.done:
+			for {
+				select {
+				case item := <-d.queue:
+					d.deliver(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver 发送事件，失败时按1s、2s、4s...退避重试
+func (d *Dispatcher) deliver(item delivery) {
+	body, err := json.Marshal(item.payload)
+	if err != nil {
+		slog.Error("出站Webhook事件序列化失败", "event", item.payload.Event, "err", err)
+		return
+	}
+
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		err = d.post(item.endpoint, item.payload, body)
+		if err == nil {
+			slog.Debug("出站Webhook已发送", "endpoint", item.endpoint.Name, "event", item.payload.Event, "id", item.payload.ID)
+			return
+		}
+		if attempt >= d.retries {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	slog.Warn("出站Webhook发送失败", "endpoint", item.endpoint.Name, "event", item.payload.Event, "id", item.payload.ID, "attempts", d.retries+1, "err", err)
+}
+
+// post 发送一次请求，非2xx状态视为失败
+func (d *Dispatcher) post(endpoint config.OutboundEndpointConfig, payload Payload, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, payload.Event)
+	req.Header.Set(HeaderDelivery, payload.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign 计算请求签名：sha256=<hex(HMAC-SHA256(secret, timestamp + "." + body))>
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID 生成事件ID（32位十六进制）
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
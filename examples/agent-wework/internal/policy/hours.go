@@ -0,0 +1,179 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateLayout 节假日和调休日期格式
+const DateLayout = "2006-01-02"
+
+// maxDateRange 单个日期区间的最大天数
+const maxDateRange = 366
+
+// weekdayNames 星期名称（按 time.Weekday 顺序）
+var weekdayNames = [7]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// period 每日营业时段（当天分钟数，左闭右开）
+type period struct {
+	start, end int
+}
+
+// BusinessHours 营业时间：按星期和每日时段判断，支持节假日和调休上班日
+type BusinessHours struct {
+	location *time.Location
+	weekdays [7]bool // 按 time.Weekday 索引
+	periods  []period
+	holidays map[string]bool
+	workdays map[string]bool
+}
+
+// NewBusinessHours 创建营业时间
+//
+// weekdays 为营业日（1=周一 … 7=周日），hours 为每日营业时段（如 09:00-18:00），
+// holidays 为节假日、workdays 为调休上班日（2006-01-02 或 2006-01-01~2006-01-03）。
+func NewBusinessHours(loc *time.Location, weekdays []int, hours, holidays, workdays []string) (*BusinessHours, error) {
+	h := &BusinessHours{location: loc}
+
+	for _, day := range weekdays {
+		if day < 1 || day > 7 {
+			return nil, fmt.Errorf("营业日无效: %d（1=周一 … 7=周日）", day)
+		}
+		h.weekdays[day%7] = true
+	}
+
+	for _, spec := range hours {
+		p, err := parsePeriod(spec)
+		if err != nil {
+			return nil, err
+		}
+		h.periods = append(h.periods, p)
+	}
+
+	var err error
+	if h.holidays, err = parseDates(holidays); err != nil {
+		return nil, fmt.Errorf("节假日%w", err)
+	}
+	if h.workdays, err = parseDates(workdays); err != nil {
+		return nil, fmt.Errorf("调休上班日%w", err)
+	}
+	return h, nil
+}
+
+// Open 指定时间是否在营业时间内（节假日优先于调休上班日和营业日）
+func (h *BusinessHours) Open(t time.Time) bool {
+	t = t.In(h.location)
+	date := t.Format(DateLayout)
+	if h.holidays[date] {
+		return false
+	}
+	if !h.weekdays[t.Weekday()] && !h.workdays[date] {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	for _, p := range h.periods {
+		if minute >= p.start && minute < p.end {
+			return true
+		}
+	}
+	return false
+}
+
+// String 营业时间说明，如 “周一至周五 09:00-12:00、13:30-18:00”
+func (h *BusinessHours) String() string {
+	var periods []string
+	for _, p := range h.periods {
+		periods = append(periods, fmt.Sprintf("%s-%s", formatMinute(p.start), formatMinute(p.end)))
+	}
+	return h.describeWeekdays() + " " + strings.Join(periods, "、")
+}
+
+// describeWeekdays 营业日说明，连续三天及以上合并为区间（从周一开始计）
+func (h *BusinessHours) describeWeekdays() string {
+	var parts []string
+	for i := 0; i < 7; {
+		if !h.weekdays[(i+1)%7] {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < 7 && h.weekdays[(j+2)%7] {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, weekdayNames[(i+1)%7]+"至"+weekdayNames[(j+1)%7])
+		default:
+			for k := i; k <= j; k++ {
+				parts = append(parts, weekdayNames[(k+1)%7])
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, "、")
+}
+
+// parsePeriod 解析营业时段，如 09:00-18:00（结束时间可为24:00，不支持跨天）
+func parsePeriod(spec string) (period, error) {
+	startText, endText, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return period{}, fmt.Errorf("营业时段格式应为 09:00-18:00: %s", spec)
+	}
+	start, err := parseMinute(startText)
+	if err != nil {
+		return period{}, fmt.Errorf("营业时段 %s: %w", spec, err)
+	}
+	end, err := parseMinute(endText)
+	if err != nil {
+		return period{}, fmt.Errorf("营业时段 %s: %w", spec, err)
+	}
+	if end <= start {
+		return period{}, fmt.Errorf("营业时段 %s 的结束时间应晚于开始时间（不支持跨天，可拆为两段）", spec)
+	}
+	return period{start: start, end: end}, nil
+}
+
+// parseMinute 解析 15:04 格式的时间为当天分钟数
+func parseMinute(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", text)
+	if err != nil {
+		return 0, fmt.Errorf("时间格式应为 15:04: %s", text)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatMinute 将当天分钟数格式化为 15:04
+func formatMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// parseDates 解析日期列表，支持 2006-01-01~2006-01-03 区间
+func parseDates(specs []string) (map[string]bool, error) {
+	dates := make(map[string]bool)
+	for _, spec := range specs {
+		startText, endText, isRange := strings.Cut(strings.TrimSpace(spec), "~")
+		start, err := time.Parse(DateLayout, strings.TrimSpace(startText))
+		if err != nil {
+			return nil, fmt.Errorf("格式应为 %s 或 %s~%s: %s", DateLayout, DateLayout, DateLayout, spec)
+		}
+		end := start
+		if isRange {
+			if end, err = time.Parse(DateLayout, strings.TrimSpace(endText)); err != nil {
+				return nil, fmt.Errorf("格式应为 %s~%s: %s", DateLayout, DateLayout, spec)
+			}
+		}
+		if end.Before(start) || end.Sub(start) > maxDateRange*24*time.Hour {
+			return nil, fmt.Errorf("日期区间无效: %s", spec)
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			dates[d.Format(DateLayout)] = true
+		}
+	}
+	return dates, nil
+}
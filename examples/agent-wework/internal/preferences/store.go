@@ -0,0 +1,135 @@
+package preferences
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// 回答详略程度
+const (
+	VerbosityConcise  = "concise"
+	VerbosityNormal   = "normal"
+	VerbosityDetailed = "detailed"
+)
+
+// Preferences 用户个人偏好（未设置的字段使用默认行为）
+type Preferences struct {
+	UserID    string    `json:"user_id"`
+	Language  string    `json:"language,omitempty"`  // 回复语言（如 中文、English，为空时跟随提问语言）
+	Verbosity string    `json:"verbosity,omitempty"` // 回答详略：concise|normal|detailed（为空同normal）
+	Model     string    `json:"model,omitempty"`     // 偏好的模型（llm.providers中的名称，为空使用默认）
+	Notify    *bool     `json:"notify,omitempty"`    // 是否接收主动通知（为空表示接收）
+	Voice     *bool     `json:"voice,omitempty"`     // 是否附带语音回复（为空时按tts.default）
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotifyEnabled 是否接收主动通知
+func (p Preferences) NotifyEnabled() bool {
+	return p.Notify == nil || *p.Notify
+}
+
+// VoiceEnabled 是否附带语音回复（未设置时使用defaultOn）
+func (p Preferences) VoiceEnabled(defaultOn bool) bool {
+	if p.Voice == nil {
+		return defaultOn
+	}
+	return *p.Voice
+}
+
+// Empty 是否未设置任何偏好
+func (p Preferences) Empty() bool {
+	return p.Language == "" && p.Verbosity == "" && p.Model == "" && p.Notify == nil && p.Voice == nil
+}
+
+// Prompt 生成注入系统提示词的偏好说明，无需说明时返回空字符串（模型选择、通知和语音不影响回答内容）
+func (p Preferences) Prompt() string {
+	var lines []string
+	if p.Language != "" {
+		lines = append(lines, "- 始终使用"+p.Language+"回复")
+	}
+	switch p.Verbosity {
+	case VerbosityConcise:
+		lines = append(lines, "- 回答简明扼要，只给出结论和必要步骤")
+	case VerbosityDetailed:
+		lines = append(lines, "- 回答详细全面，说明原因、步骤和注意事项")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Store 用户偏好存储（JSON文件持久化）
+type Store struct {
+	path  string
+	prefs map[string]*Preferences
+	mutex sync.RWMutex
+}
+
+// NewStore 创建用户偏好存储并加载已有数据
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:  path,
+		prefs: make(map[string]*Preferences),
+	}
+
+	var prefs []*Preferences
+	if _, err := fsutil.ReadJSON(path, &prefs); err != nil {
+		return nil, err
+	}
+	for _, p := range prefs {
+		s.prefs[p.UserID] = p
+	}
+	return s, nil
+}
+
+// Get 获取用户偏好（未设置时返回零值）
+func (s *Store) Get(userID string) Preferences {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if p, ok := s.prefs[userID]; ok {
+		return *p
+	}
+	return Preferences{UserID: userID}
+}
+
+// Update 修改用户偏好并持久化，全部恢复默认时删除记录
+func (s *Store) Update(userID string, update func(p *Preferences)) (Preferences, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := Preferences{UserID: userID}
+	if existing, ok := s.prefs[userID]; ok {
+		p = *existing
+	}
+	update(&p)
+	p.UpdatedAt = time.Now()
+
+	previous, existed := s.prefs[userID]
+	if p.Empty() {
+		delete(s.prefs, userID)
+	} else {
+		s.prefs[userID] = &p
+	}
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.prefs[userID] = previous
+		} else {
+			delete(s.prefs, userID)
+		}
+		return Preferences{}, err
+	}
+	return p, nil
+}
+
+// saveLocked 持久化到文件（调用方需持有锁）
+func (s *Store) saveLocked() error {
+	prefs := make([]*Preferences, 0, len(s.prefs))
+	for _, p := range s.prefs {
+		prefs = append(prefs, p)
+	}
+	sort.Slice(prefs, func(i, j int) bool { return prefs[i].UserID < prefs[j].UserID })
+	return fsutil.WriteJSONAtomic(s.path, prefs)
+}
@@ -0,0 +1,204 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// maxExamples 每类问题保留的示例数量
+const maxExamples = 3
+
+// Profile 用户画像记忆
+type Profile struct {
+	UserID    string      `json:"user_id"`
+	Notes     []string    `json:"notes,omitempty"`        // 人工或导入的备注
+	Issues    []IssueStat `json:"issues,omitempty"`       // 历史问题统计
+	Session   *Session    `json:"last_session,omitempty"` // 上次会话摘要
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Session 会话结束（空闲）后生成的摘要
+type Session struct {
+	Summary string    `json:"summary"`
+	EndedAt time.Time `json:"ended_at"`
+}
+
+// IssueStat 某类问题的历史统计
+type IssueStat struct {
+	Category string    `json:"category"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+	Examples []string  `json:"examples,omitempty"` // 最近的问题示例
+}
+
+// Store 用户画像存储（JSON文件持久化）
+type Store struct {
+	path     string
+	profiles map[string]*Profile
+	mutex    sync.RWMutex
+}
+
+// NewStore 创建用户画像存储并加载已有数据
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:     path,
+		profiles: make(map[string]*Profile),
+	}
+
+	var profiles []*Profile
+	if _, err := fsutil.ReadJSON(path, &profiles); err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		s.profiles[p.UserID] = p
+	}
+
+	return s, nil
+}
+
+// Get 获取用户画像
+func (s *Store) Get(userID string) (*Profile, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p, ok := s.profiles[userID]
+	return p, ok
+}
+
+// RecordIssue 记录一次用户问题
+func (s *Store) RecordIssue(userID, category, example string, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.getOrCreate(userID)
+	if category == "" {
+		category = "其他"
+	}
+
+	var stat *IssueStat
+	for i := range p.Issues {
+		if p.Issues[i].Category == category {
+			stat = &p.Issues[i]
+			break
+		}
+	}
+	if stat == nil {
+		p.Issues = append(p.Issues, IssueStat{Category: category})
+		stat = &p.Issues[len(p.Issues)-1]
+	}
+
+	stat.Count++
+	if at.After(stat.LastSeen) {
+		stat.LastSeen = at
+	}
+	if example = strings.TrimSpace(example); example != "" {
+		stat.Examples = append([]string{truncate(example, 80)}, stat.Examples...)
+		if len(stat.Examples) > maxExamples {
+			stat.Examples = stat.Examples[:maxExamples]
+		}
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// AddNote 为用户添加备注
+func (s *Store) AddNote(userID, note string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.getOrCreate(userID)
+	p.Notes = append(p.Notes, note)
+	p.UpdatedAt = time.Now()
+}
+
+// SetSession 记录用户上次会话的摘要（覆盖更早的摘要）
+func (s *Store) SetSession(userID, summary string, endedAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.getOrCreate(userID)
+	p.Session = &Session{Summary: summary, EndedAt: endedAt}
+	p.UpdatedAt = time.Now()
+}
+
+// Count 获取画像数量
+func (s *Store) Count() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.profiles)
+}
+
+// Save 持久化到文件
+func (s *Store) Save() error {
+	s.mutex.RLock()
+	profiles := make([]*Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		profiles = append(profiles, p)
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].UserID < profiles[j].UserID })
+	return fsutil.WriteJSONAtomic(s.path, profiles)
+}
+
+// Summary 生成注入提示词的用户背景摘要，无数据时返回空字符串
+func (s *Store) Summary(userID string) string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p, ok := s.profiles[userID]
+	if !ok || (len(p.Issues) == 0 && len(p.Notes) == 0 && p.Session == nil) {
+		return ""
+	}
+
+	issues := make([]IssueStat, len(p.Issues))
+	copy(issues, p.Issues)
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Count > issues[j].Count })
+	if len(issues) > 5 {
+		issues = issues[:5]
+	}
+
+	var sb strings.Builder
+	if len(issues) > 0 {
+		sb.WriteString("该用户历史上反复遇到的问题：\n")
+		for _, issue := range issues {
+			sb.WriteString(fmt.Sprintf("- %s（%d次，最近一次%s）", issue.Category, issue.Count, issue.LastSeen.Format("2006-01-02")))
+			if len(issue.Examples) > 0 {
+				sb.WriteString("，例如：" + issue.Examples[0])
+			}
+			sb.WriteString("\n")
+		}
+	}
+	for _, note := range p.Notes {
+		sb.WriteString("- 备注：" + note + "\n")
+	}
+	if p.Session != nil {
+		sb.WriteString(fmt.Sprintf("上次会话（%s）的摘要：\n%s\n", p.Session.EndedAt.Format("2006-01-02 15:04"), p.Session.Summary))
+	}
+
+	return sb.String()
+}
+
+// getOrCreate 获取或创建画像（调用方需持有写锁）
+func (s *Store) getOrCreate(userID string) *Profile {
+	p, ok := s.profiles[userID]
+	if !ok {
+		p = &Profile{UserID: userID}
+		s.profiles[userID] = p
+	}
+	return p
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,145 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// natsConsumer JetStream持久消费者名称（所有worker共用，任务在worker间分摊）
+const natsConsumer = "workers"
+
+// natsMaxDeliver 任务最多投递次数（worker处理时反复崩溃的任务不再重试）
+const natsMaxDeliver = 3
+
+// fetchWait 单次拉取任务的最长等待时间（也是worker停止时的最长等待）
+const fetchWait = 5 * time.Second
+
+// NATSBroker 基于NATS JetStream工作队列的任务队列（任务确认后删除，worker异常退出时重新投递）
+type NATSBroker struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	stream  jetstream.Stream
+	subject string
+	options options
+}
+
+// NewNATSBroker 连接NATS并创建（或更新）工作队列
+func NewNATSBroker(cfg config.QueueConfig, maxAge time.Duration) (*NATSBroker, error) {
+	conn, err := nats.Connect(cfg.URL, nats.Name("b0dy"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("连接NATS失败: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("初始化JetStream失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      cfg.Name,
+		Subjects:  []string{cfg.Name},
+		Retention: jetstream.WorkQueuePolicy,
+		MaxAge:    maxAge,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("创建JetStream队列 %s 失败: %w", cfg.Name, err)
+	}
+
+	return &NATSBroker{
+		conn:    conn,
+		js:      js,
+		stream:  stream,
+		subject: cfg.Name,
+		options: newOptions(cfg, maxAge),
+	}, nil
+}
+
+// Publish 发布任务（等待JetStream确认持久化）
+func (b *NATSBroker) Publish(ctx context.Context, job Job) error {
+	data, err := marshalJob(job)
+	if err != nil {
+		return err
+	}
+	if _, err := b.js.Publish(ctx, b.subject, data); err != nil {
+		return fmt.Errorf("发布任务失败: %w", err)
+	}
+	return nil
+}
+
+// Consume 启动concurrency个拉取循环消费任务
+func (b *NATSBroker) Consume(ctx context.Context, handler Handler) error {
+	setup, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	consumer, err := b.stream.CreateOrUpdateConsumer(setup, jetstream.ConsumerConfig{
+		Durable:       natsConsumer,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       b.options.ackWait(),
+		MaxDeliver:    natsMaxDeliver,
+		MaxAckPending: -1,
+	})
+	if err != nil {
+		return fmt.Errorf("创建JetStream消费者失败: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < b.options.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				batch, err := consumer.Fetch(1, jetstream.FetchMaxWait(fetchWait))
+				if err != nil {
+					slog.Warn("拉取任务失败", "err", err)
+					sleepContext(ctx, time.Second)
+					continue
+				}
+				for msg := range batch.Messages() {
+					b.handle(msg, handler)
+				}
+				if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) && !errors.Is(err, jetstream.ErrNoMessages) {
+					slog.Warn("拉取任务失败", "err", err)
+					sleepContext(ctx, time.Second)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// handle 处理任务并确认，无法处理的任务终止投递
+func (b *NATSBroker) handle(msg jetstream.Msg, handler Handler) {
+	ack := msg.Ack
+	if !b.options.dispatch(msg.Data(), handler) {
+		ack = msg.Term
+	}
+	if err := ack(); err != nil {
+		slog.Warn("确认任务失败", "err", err)
+	}
+}
+
+// Close 关闭NATS连接
+func (b *NATSBroker) Close() error {
+	b.conn.Close()
+	return nil
+}
+
+// sleepContext 等待指定时间或ctx结束
+func sleepContext(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// Job 待处理的用户消息（Webhook副本入队，worker进程消费）
+type Job struct {
+	StreamID       string    `json:"stream_id"` // Webhook已返回给企业微信的任务ID，worker沿用该ID发布回复
+	Bot            string    `json:"bot"`
+	Org            string    `json:"org,omitempty"` // 机器人所属组织（worker据此拒绝其他组织的任务）
+	ConversationID string    `json:"conversation_id"`
+	UserID         string    `json:"user_id"`
+	Question       string    `json:"question"`             // 带用户前缀的消息
+	Language       string    `json:"language,omitempty"`   // 用户消息语言（启用翻译时）
+	Locale         string    `json:"locale,omitempty"`     // 系统消息语言
+	Images         []string  `json:"images,omitempty"`     // 待识别文字的图片URL（启用图片文字识别时）
+	Persona        string    `json:"persona,omitempty"`    // 会话通过 /persona 选择的人设
+	RequestID      string    `json:"request_id,omitempty"` // Webhook回调的请求ID（用于关联日志）
+	Created        time.Time `json:"created"`
+}
+
+// Handler 处理任务，返回错误时任务不再重新投递
+type Handler func(ctx context.Context, job Job) error
+
+// Broker 任务队列：Webhook副本发布任务，多个worker竞争消费（每个任务只由一个worker处理）
+type Broker interface {
+	Publish(ctx context.Context, job Job) error
+	// Consume 持续消费任务直到ctx结束，返回前等待处理中的任务完成
+	Consume(ctx context.Context, handler Handler) error
+	Close() error
+}
+
+// New 按配置创建任务队列，未启用时返回nil；maxAge为任务有效期（超过后企业微信已不再刷新，直接丢弃）
+func New(cfg config.QueueConfig, password string, maxAge time.Duration) (Broker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	switch cfg.Backend {
+	case "redis":
+		return NewRedisBroker(cfg, password, maxAge)
+	default:
+		return NewNATSBroker(cfg, maxAge)
+	}
+}
+
+// options 各后端共用的消费参数
+type options struct {
+	concurrency int
+	jobTimeout  time.Duration
+	maxAge      time.Duration
+}
+
+// newOptions 从配置生成消费参数
+func newOptions(cfg config.QueueConfig, maxAge time.Duration) options {
+	return options{
+		concurrency: cfg.Concurrency,
+		jobTimeout:  time.Duration(cfg.JobTimeout) * time.Second,
+		maxAge:      maxAge,
+	}
+}
+
+// ackWait 任务未确认时重新投递的等待时间（留出任务超时后的收尾时间）
+func (o options) ackWait() time.Duration {
+	return o.jobTimeout + 30*time.Second
+}
+
+// dispatch 解析并处理一条任务，返回false表示任务无法处理（不再重新投递）
+func (o options) dispatch(data []byte, handler Handler) bool {
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		slog.Error("丢弃无法解析的任务", "err", err)
+		return false
+	}
+	if o.maxAge > 0 && time.Since(job.Created) > o.maxAge {
+		slog.Warn("丢弃过期任务", "stream_id", job.StreamID, "created", job.Created.Format(time.DateTime))
+		return true
+	}
+
+	// 任务不随ctx取消，worker停止时处理完已领取的任务
+	ctx, cancel := context.WithTimeout(context.Background(), o.jobTimeout)
+	defer cancel()
+	if err := handler(ctx, job); err != nil {
+		slog.Error("任务处理失败", "stream_id", job.StreamID, "err", err)
+		return false
+	}
+	return true
+}
+
+// marshalJob 序列化任务
+func marshalJob(job Job) ([]byte, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务失败: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,192 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/redisutil"
+)
+
+// redisGroup Redis Stream消费组名称（所有worker共用，任务在worker间分摊）
+const redisGroup = "workers"
+
+// redisMaxLen 队列保留的最大任务数（近似裁剪，防止无worker时无限增长）
+const redisMaxLen = 100000
+
+// redisMaxDeliver 任务最多投递次数（worker处理时反复崩溃的任务不再重试）
+const redisMaxDeliver = 3
+
+// RedisBroker 基于Redis Streams消费组的任务队列（worker异常退出时由其他worker认领超时未确认的任务）
+type RedisBroker struct {
+	client   *redis.Client
+	key      string
+	consumer string
+	options  options
+}
+
+// NewRedisBroker 连接Redis并创建任务队列
+func NewRedisBroker(cfg config.QueueConfig, password string, maxAge time.Duration) (*RedisBroker, error) {
+	client, err := redisutil.Connect(cfg.URL, password)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	return &RedisBroker{
+		client:   client,
+		key:      cfg.Name,
+		consumer: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		options:  newOptions(cfg, maxAge),
+	}, nil
+}
+
+// Publish 发布任务
+func (b *RedisBroker) Publish(ctx context.Context, job Job) error {
+	data, err := marshalJob(job)
+	if err != nil {
+		return err
+	}
+	err = b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.key,
+		MaxLen: redisMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"job": data},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("发布任务失败: %w", err)
+	}
+	return nil
+}
+
+// Consume 启动concurrency个读取循环消费任务
+func (b *RedisBroker) Consume(ctx context.Context, handler Handler) error {
+	err := b.client.XGroupCreateMkStream(ctx, b.key, redisGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("创建Redis消费组失败: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < b.options.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				msg, ok, err := b.next(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						slog.Warn("读取任务失败", "err", err)
+						sleepContext(ctx, time.Second)
+					}
+					continue
+				}
+				if ok {
+					b.handle(msg, handler)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// next 优先认领其他worker超时未确认的任务，否则等待新任务
+func (b *RedisBroker) next(ctx context.Context) (redis.XMessage, bool, error) {
+	msg, ok, err := b.claim(ctx)
+	if err != nil || ok {
+		return msg, ok, err
+	}
+
+	streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    redisGroup,
+		Consumer: b.consumer,
+		Streams:  []string{b.key, ">"},
+		Count:    1,
+		Block:    fetchWait,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return redis.XMessage{}, false, nil
+	}
+	if err != nil {
+		return redis.XMessage{}, false, err
+	}
+	for _, stream := range streams {
+		if len(stream.Messages) > 0 {
+			return stream.Messages[0], true, nil
+		}
+	}
+	return redis.XMessage{}, false, nil
+}
+
+// claim 认领超过ackWait未确认的任务（处理它的worker可能已退出），投递次数过多的任务直接丢弃
+func (b *RedisBroker) claim(ctx context.Context) (redis.XMessage, bool, error) {
+	pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: b.key,
+		Group:  redisGroup,
+		Start:  "-",
+		End:    "+",
+		Count:  10,
+	}).Result()
+	if err != nil {
+		return redis.XMessage{}, false, err
+	}
+
+	ackWait := b.options.ackWait()
+	for _, p := range pending {
+		if p.Idle < ackWait {
+			continue
+		}
+		if p.RetryCount >= redisMaxDeliver {
+			slog.Error("任务多次投递仍未完成，丢弃", "id", p.ID, "deliveries", p.RetryCount)
+			b.remove(p.ID)
+			continue
+		}
+		claimed, err := b.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   b.key,
+			Group:    redisGroup,
+			Consumer: b.consumer,
+			MinIdle:  ackWait,
+			Messages: []string{p.ID},
+		}).Result()
+		if err != nil {
+			return redis.XMessage{}, false, err
+		}
+		// 已被其他worker认领时为空
+		if len(claimed) > 0 {
+			return claimed[0], true, nil
+		}
+	}
+	return redis.XMessage{}, false, nil
+}
+
+// handle 处理任务，完成后确认并从队列删除
+func (b *RedisBroker) handle(msg redis.XMessage, handler Handler) {
+	data, _ := msg.Values["job"].(string)
+	b.options.dispatch([]byte(data), handler)
+	b.remove(msg.ID)
+}
+
+// remove 确认任务并从队列删除
+func (b *RedisBroker) remove(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, b.key, redisGroup, id)
+		pipe.XDel(ctx, b.key, id)
+		return nil
+	}); err != nil {
+		slog.Warn("确认任务失败", "err", err)
+	}
+}
+
+// Close 关闭Redis连接
+func (b *RedisBroker) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,29 @@
+package redisutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Connect 按URL创建Redis客户端并检查连通性，password非空时覆盖URL中的密码
+func Connect(url, password string) (*redis.Client, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("解析Redis地址失败: %w", err)
+	}
+	if password != "" {
+		options.Password = password
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接Redis失败: %w", err)
+	}
+	return client, nil
+}
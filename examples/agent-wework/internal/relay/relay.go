@@ -0,0 +1,117 @@
+package relay
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// thinkBlockRegex 匹配完整或未闭合（仍在生成中）的思考过程
+var thinkBlockRegex = regexp.MustCompile(`(?s)<think>.*?(</think>|$)`)
+
+// Follow 跟随机器人的回复直到结束（外部渠道没有企业微信的流式刷新回调，由渠道主动轮询）：
+// 流式回复每隔interval刷新一次，内容变化时调用update，最后一次调用的finish为true；
+// 超过timeout未结束时以已生成的内容结束
+func Follow(handler wework.MessageHandler, resp *wework.WeWorkResponse, interval, timeout time.Duration, update func(content string, finish bool)) {
+	if resp == nil {
+		return
+	}
+	if resp.Text != nil {
+		update(resp.Text.Content, true)
+		return
+	}
+	stream := resp.Stream
+	if stream == nil {
+		return
+	}
+
+	last := ""
+	deadline := time.Now().Add(timeout)
+	for {
+		content := StripThinking(stream.Content)
+		if stream.Finish {
+			update(content, true)
+			return
+		}
+		if time.Now().After(deadline) {
+			slog.Warn("回复未在时限内完成，使用已生成的内容", "timeout", timeout, "stream_id", stream.ID)
+			update(content, true)
+			return
+		}
+		if content != "" && content != last {
+			update(content, false)
+			last = content
+		}
+
+		time.Sleep(interval)
+		refreshed, err := handler.HandleStreamRefresh(stream.ID)
+		if err != nil || refreshed == nil || refreshed.Stream == nil {
+			update(content, true)
+			return
+		}
+		stream = refreshed.Stream
+	}
+}
+
+// StripThinking 移除思考过程（外部渠道只展示正式回复）
+func StripThinking(content string) string {
+	if !strings.Contains(content, "<think>") {
+		return content
+	}
+	return strings.TrimSpace(thinkBlockRegex.ReplaceAllString(content, ""))
+}
+
+// CardText 将文本通知卡片（如欢迎卡片）转为文字，其他卡片（如审批按钮）外部渠道无法操作，返回空字符串
+func CardText(card *wework.WeWorkTemplateCard) string {
+	if card == nil || card.CardType != wework.CardTypeTextNotice {
+		return ""
+	}
+
+	var parts []string
+	if card.MainTitle != nil {
+		if card.MainTitle.Title != "" {
+			parts = append(parts, card.MainTitle.Title)
+		}
+		if card.MainTitle.Desc != "" {
+			parts = append(parts, card.MainTitle.Desc)
+		}
+	}
+	if card.SubTitleText != "" {
+		parts = append(parts, card.SubTitleText)
+	}
+	if len(card.HorizontalContentList) > 0 {
+		lines := make([]string, len(card.HorizontalContentList))
+		for i, item := range card.HorizontalContentList {
+			lines[i] = item.KeyName + "：" + item.Value
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+	if card.CardAction != nil && card.CardAction.URL != "" {
+		parts = append(parts, card.CardAction.URL)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Split 按字节数拆分过长的回复，尽量在换行处断开且不截断UTF-8字符
+func Split(text string, maxBytes int) []string {
+	var chunks []string
+	for len(text) > maxBytes {
+		cut := strings.LastIndex(text[:maxBytes], "\n")
+		if cut <= 0 {
+			cut = maxBytes
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
@@ -0,0 +1,219 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job 定时任务
+type Job struct {
+	Name    string
+	Cron    *Cron
+	Timeout time.Duration                   // 单次执行超时（<=0 表示不限制）
+	Run     func(ctx context.Context) error // 执行函数
+}
+
+// Status 定时任务状态（管理接口）
+type Status struct {
+	Name     string    `json:"name"`
+	Next     time.Time `json:"next"`
+	LastRun  time.Time `json:"last_run"`
+	LastErr  string    `json:"last_error,omitempty"`
+	Running  bool      `json:"running"`
+	RunCount int       `json:"run_count"`
+}
+
+// entry 调度中的任务及其运行状态
+type entry struct {
+	job      Job
+	next     time.Time
+	lastRun  time.Time
+	lastErr  error
+	running  bool
+	runCount int
+}
+
+// Scheduler 定时任务调度器：单个goroutine等待最近的触发时间，任务在独立goroutine中执行，
+// 上一次执行未结束时跳过本次触发
+type Scheduler struct {
+	entries map[string]*entry
+	wake    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+// New 创建调度器（需调用Start启动）
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		entries: make(map[string]*entry),
+		wake:    make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Set 替换全部任务（配置热更新），同名任务保留运行状态
+func (s *Scheduler) Set(jobs []Job) {
+	s.mutex.Lock()
+	now := time.Now()
+	entries := make(map[string]*entry, len(jobs))
+	for _, job := range jobs {
+		e, ok := s.entries[job.Name]
+		if !ok {
+			e = &entry{}
+		}
+		e.job = job
+		e.next = job.Cron.Next(now)
+		entries[job.Name] = e
+	}
+	s.entries = entries
+	s.mutex.Unlock()
+
+	s.notify()
+}
+
+// Start 启动调度循环
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop 停止调度，取消并等待执行中的任务
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// RunNow 立即执行任务（不影响下次触发时间），任务不存在或正在执行时返回错误
+func (s *Scheduler) RunNow(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.entries[name]
+	if !ok {
+		return fmt.Errorf("定时任务 %s 不存在", name)
+	}
+	if e.running {
+		return fmt.Errorf("定时任务 %s 正在执行", name)
+	}
+	s.startLocked(e)
+	return nil
+}
+
+// Status 列出任务状态（按下次触发时间排序）
+func (s *Scheduler) Status() []Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		status := Status{
+			Name:     e.job.Name,
+			Next:     e.next,
+			LastRun:  e.lastRun,
+			Running:  e.running,
+			RunCount: e.runCount,
+		}
+		if e.lastErr != nil {
+			status.LastErr = e.lastErr.Error()
+		}
+		list = append(list, status)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Next.Before(list[j].Next) })
+	return list
+}
+
+// notify 唤醒调度循环重新计算等待时间
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop 调度循环
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		timer.Reset(s.runDue(time.Now()))
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// runDue 执行已到期的任务，返回距下一次触发的等待时间
+func (s *Scheduler) runDue(now time.Time) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wait := time.Hour
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if !now.Before(e.next) {
+			if e.running {
+				slog.Warn("定时任务上次执行尚未结束，跳过本次触发", "job", e.job.Name)
+			} else {
+				s.startLocked(e)
+			}
+			e.next = e.job.Cron.Next(now)
+			if e.next.IsZero() {
+				continue
+			}
+		}
+		if d := e.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// startLocked 在独立goroutine中执行任务（调用方需持有锁）
+func (s *Scheduler) startLocked(e *entry) {
+	e.running = true
+	e.lastRun = time.Now()
+	e.runCount++
+	job := e.job
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ctx := s.ctx
+		if job.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+			defer cancel()
+		}
+
+		start := time.Now()
+		err := job.Run(ctx)
+		if err != nil {
+			slog.Error("定时任务执行失败", "job", job.Name, "err", err)
+		} else {
+			slog.Info("定时任务执行完成", "job", job.Name, "duration", time.Since(start).Truncate(time.Millisecond))
+		}
+
+		s.mutex.Lock()
+		e.running = false
+		e.lastErr = err
+		s.mutex.Unlock()
+	}()
+}
@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Provider 密钥后端
+type Provider interface {
+	// Resolve 解析去掉前缀后的密钥引用
+	Resolve(ref string) (string, error)
+}
+
+// Resolver 密钥引用解析器，按前缀分发到不同后端，并缓存解析结果
+//
+// 支持的引用格式：
+//
+//	vault:kv/wework#token        HashiCorp Vault KV（VAULT_ADDR / VAULT_TOKEN）
+//	aws-kms:<base64密文>         AWS KMS解密（AWS_REGION / AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY）
+//	file:/run/secrets/api_key    读取文件内容（Docker/Kubernetes secrets）
+//	envfile:/etc/ai-body.env#KEY 读取dotenv文件中的变量
+type Resolver struct {
+	providers map[string]Provider
+	cache     map[string]string
+	mutex     sync.Mutex
+}
+
+// NewResolver 创建带默认后端的解析器
+func NewResolver() *Resolver {
+	return &Resolver{
+		providers: map[string]Provider{
+			"vault":   NewVaultProvider(),
+			"aws-kms": NewKMSProvider(),
+			"file":    FileProvider{},
+			"envfile": EnvFileProvider{},
+		},
+		cache: make(map[string]string),
+	}
+}
+
+// Register 注册或替换密钥后端
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.providers[scheme] = provider
+}
+
+// IsReference 判断值是否为密钥引用
+func (r *Resolver) IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, exists := r.providers[scheme]
+	return exists
+}
+
+// Resolve 解析值：密钥引用返回解析结果，其他值原样返回
+func (r *Resolver) Resolve(value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+
+	r.mutex.Lock()
+	provider, exists := r.providers[scheme]
+	cached, hit := r.cache[value]
+	r.mutex.Unlock()
+
+	if !exists {
+		return value, nil
+	}
+	if hit {
+		return cached, nil
+	}
+
+	secret, err := provider.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("解析密钥引用 %s 失败: %w", redact(value), err)
+	}
+
+	r.mutex.Lock()
+	r.cache[value] = secret
+	r.mutex.Unlock()
+
+	return secret, nil
+}
+
+// redact 隐去引用中可能较长的密文，仅用于错误信息
+func redact(value string) string {
+	if len(value) > 48 {
+		return value[:40] + "..."
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultProvider HashiCorp Vault KV密钥后端（vault:<mount>/<path>#<field>）
+//
+// 优先按KV v2读取（<mount>/data/<path>），不存在时回退KV v1。
+type VaultProvider struct {
+	client *http.Client
+}
+
+// NewVaultProvider 创建Vault后端
+func NewVaultProvider() *VaultProvider {
+	return &VaultProvider{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Resolve 实现Provider接口
+func (p *VaultProvider) Resolve(ref string) (string, error) {
+	addr := strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("需要设置环境变量 VAULT_ADDR 和 VAULT_TOKEN")
+	}
+
+	secretPath, field, ok := strings.Cut(ref, "#")
+	if !ok || field == "" {
+		return "", fmt.Errorf("vault引用需要指定字段: vault:<mount>/<path>#<field>")
+	}
+	mount, path, ok := strings.Cut(strings.Trim(secretPath, "/"), "/")
+	if !ok {
+		return "", fmt.Errorf("vault引用格式错误: %s", secretPath)
+	}
+
+	// KV v2
+	body, status, err := p.get(addr, token, fmt.Sprintf("%s/data/%s", mount, path))
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusOK {
+		var v2 struct {
+			Data struct {
+				Data map[string]interface{} `json:"data"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &v2); err != nil {
+			return "", fmt.Errorf("解析Vault响应失败: %w", err)
+		}
+		return pickField(v2.Data.Data, field)
+	}
+
+	// KV v1
+	body, status, err = p.get(addr, token, fmt.Sprintf("%s/%s", mount, path))
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("Vault返回错误: %d", status)
+	}
+	var v1 struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &v1); err != nil {
+		return "", fmt.Errorf("解析Vault响应失败: %w", err)
+	}
+	return pickField(v1.Data, field)
+}
+
+// get 读取Vault路径
+func (p *VaultProvider) get(addr, token, path string) ([]byte, int, error) {
+	req, err := http.NewRequest(http.MethodGet, addr+"/v1/"+path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("请求Vault失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	return body, resp.StatusCode, err
+}
+
+// pickField 取出字段值
+func pickField(data map[string]interface{}, field string) (string, error) {
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("Vault密钥中没有字段 %s", field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,178 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/relay"
+)
+
+const (
+	apiTimeout    = 10 * time.Second // 单次Web API调用超时
+	replyTimeout  = 10 * time.Minute // 等待机器人完成回复的最长时间
+	maxTextLength = 39000            // 消息文本上限（Slack约40000字符）
+)
+
+// Adapter Slack渠道：频道中@机器人或私信的消息交给机器人处理，回复发在该消息的话题中，
+// 生成过程中按编辑间隔更新同一条消息；每个话题对应一个会话（共享对话记忆）
+type Adapter struct {
+	bot     *Client // Bot Token：收发消息
+	app     *Client // App-Level Token：Socket Mode（未配置时使用Events API）
+	handler wework.MessageHandler
+	dedup   wework.Deduplicator
+
+	signingSecret string
+	botUserID     string
+	editInterval  time.Duration
+}
+
+// NewAdapter 创建Slack渠道（调用auth.test验证Bot Token并获取机器人用户ID）
+func NewAdapter(cfg config.SlackConfig, handler wework.MessageHandler, dedup wework.Deduplicator) (*Adapter, error) {
+	a := &Adapter{
+		bot:           NewClient(cfg.BotToken, cfg.BaseURL),
+		handler:       handler,
+		dedup:         dedup,
+		signingSecret: cfg.SigningSecret,
+		editInterval:  time.Duration(cfg.EditInterval) * time.Millisecond,
+	}
+	if cfg.AppToken != "" {
+		a.app = NewClient(cfg.AppToken, cfg.BaseURL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	userID, err := a.bot.AuthTest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("验证slack.bot_token失败: %w", err)
+	}
+	a.botUserID = userID
+	return a, nil
+}
+
+// SocketMode 是否使用Socket Mode接收事件
+func (a *Adapter) SocketMode() bool {
+	return a.app != nil
+}
+
+// ConversationChatID 话题对应的会话ChatID（会话标识为 group_slack_<channel>_<thread_ts>）
+func ConversationChatID(channel, threadTS string) string {
+	return "slack_" + channel + "_" + threadTS
+}
+
+// dispatch 筛选需要回复的消息并在后台处理：频道中只回复@机器人的消息，私信回复所有消息
+func (a *Adapter) dispatch(env Envelope) {
+	if env.Type != "event_callback" {
+		return
+	}
+	ev := env.Event
+	if ev.Subtype != "" || ev.BotID != "" || ev.User == "" || ev.User == a.botUserID {
+		return
+	}
+	switch {
+	case ev.Type == "app_mention":
+	case ev.Type == "message" && ev.ChannelType == "im":
+	default:
+		return
+	}
+	// 同一条消息可能同时触发 app_mention 和 message 事件，Slack重试时event_id也相同，按消息去重
+	if a.dedup.Seen("slack_" + ev.Channel + "_" + ev.TS) {
+		return
+	}
+	go a.reply(ev)
+}
+
+// reply 交给机器人处理，在话题中发送回复并随生成进度编辑
+func (a *Adapter) reply(ev Event) {
+	threadTS := ev.ThreadTS
+	if threadTS == "" {
+		threadTS = ev.TS // 新消息以该消息为根开启话题
+	}
+	text := unescape(strings.TrimSpace(strings.ReplaceAll(ev.Text, "<@"+a.botUserID+">", "")))
+	if text == "" {
+		return
+	}
+
+	slog.Debug("Slack消息", "channel", ev.Channel, "user", ev.User, "text", text)
+	resp, err := a.handler.HandleMessage(&wework.IncomingMessage{
+		BaseMessage: wework.BaseMessage{
+			MsgID:    ev.TS,
+			ChatID:   ConversationChatID(ev.Channel, threadTS),
+			ChatType: wework.ChatTypeGroup,
+			From:     wework.From{UserID: ev.User},
+			MsgType:  wework.MsgTypeText,
+		},
+		Text: &wework.TextContent{Content: text},
+	})
+	if err != nil {
+		slog.Warn("Slack消息处理失败", "channel", ev.Channel, "err", err)
+	}
+	if resp == nil {
+		return
+	}
+
+	if card := relay.CardText(resp.TemplateCard); card != "" {
+		a.post(ev.Channel, threadTS, card)
+	}
+	var ts string
+	relay.Follow(a.handler, resp, a.editInterval, replyTimeout, func(content string, finish bool) {
+		if strings.TrimSpace(content) == "" {
+			return
+		}
+		if ts == "" {
+			ts = a.post(ev.Channel, threadTS, content)
+			return
+		}
+		a.update(ev.Channel, ts, content)
+	})
+}
+
+// post 在话题中发送消息，失败时返回空字符串
+func (a *Adapter) post(channel, threadTS, content string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	ts, err := a.bot.PostMessage(ctx, channel, threadTS, toMrkdwn(content))
+	if err != nil {
+		slog.Warn("Slack回复发送失败", "channel", channel, "err", err)
+	}
+	return ts
+}
+
+// update 编辑已发送的回复（失败时忽略，下次编辑会带上完整内容）
+func (a *Adapter) update(channel, ts, content string) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	if err := a.bot.UpdateMessage(ctx, channel, ts, toMrkdwn(content)); err != nil {
+		slog.Warn("Slack回复编辑失败", "channel", channel, "err", err)
+	}
+}
+
+var (
+	boldRegex    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	strikeRegex  = regexp.MustCompile(`~~(.+?)~~`)
+	headingRegex = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+	linkRegex    = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// toMrkdwn 将Markdown回复转换为Slack的mrkdwn格式（粗体、删除线、标题、链接），超长时截断
+func toMrkdwn(content string) string {
+	content = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(content)
+	content = boldRegex.ReplaceAllString(content, "*$1*")
+	content = strikeRegex.ReplaceAllString(content, "~$1~")
+	content = headingRegex.ReplaceAllString(content, "*$1*")
+	content = linkRegex.ReplaceAllString(content, "<$2|$1>")
+	if runes := []rune(content); len(runes) > maxTextLength {
+		content = string(runes[:maxTextLength]) + "…"
+	}
+	return content
+}
+
+// unescape 还原Slack消息文本中转义的 & < >
+func unescape(text string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(text)
+}
@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultBaseURL Slack Web API地址
+const DefaultBaseURL = "https://slack.com/api"
+
+// Client Slack Web API客户端（Bot Token调用消息接口，App-Level Token建立Socket Mode连接）
+type Client struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient 创建Web API客户端
+func NewClient(token, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		token:   token,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthTest 查询Token对应的机器人用户ID（用于识别@机器人和忽略自己发送的消息）
+func (c *Client) AuthTest(ctx context.Context) (string, error) {
+	var resp struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.call(ctx, "auth.test", struct{}{}, &resp); err != nil {
+		return "", err
+	}
+	return resp.UserID, nil
+}
+
+// PostMessage 发送消息（threadTS非空时发在该话题中），返回消息ts
+func (c *Client) PostMessage(ctx context.Context, channel, threadTS, text string) (string, error) {
+	req := map[string]string{"channel": channel, "text": text}
+	if threadTS != "" {
+		req["thread_ts"] = threadTS
+	}
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	if err := c.call(ctx, "chat.postMessage", req, &resp); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+// UpdateMessage 编辑已发送的消息
+func (c *Client) UpdateMessage(ctx context.Context, channel, ts, text string) error {
+	return c.call(ctx, "chat.update", map[string]string{"channel": channel, "ts": ts, "text": text}, nil)
+}
+
+// OpenConnection 获取Socket Mode的WebSocket地址（需使用App-Level Token）
+func (c *Client) OpenConnection(ctx context.Context) (string, error) {
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := c.call(ctx, "apps.connections.open", struct{}{}, &resp); err != nil {
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+// call 调用Web API方法，ok为false时返回Slack的错误码
+func (c *Client) call(ctx context.Context, method string, req, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json; charset=utf-8")
+	httpReq.Header.Set("Authorization", "Bearer "+c.token)
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("调用Slack %s失败: %w", method, err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack %s返回错误: HTTP %d", method, httpResp.StatusCode)
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("解析Slack %s响应失败: %w", method, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("Slack %s返回错误: %s", method, result.Error)
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.Unmarshal(data, resp)
+}
@@ -0,0 +1,91 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxClockSkew 请求时间戳与本机时间的最大偏差（防重放）
+const maxClockSkew = 5 * time.Minute
+
+// maxEventBody Events API请求体的最大长度
+const maxEventBody = 1 << 20
+
+// Envelope Events API回调（Socket Mode中为events_api消息的payload）
+type Envelope struct {
+	Type      string `json:"type"`      // url_verification | event_callback
+	Challenge string `json:"challenge"` // URL验证时原样返回
+	EventID   string `json:"event_id"`  // 事件ID（用于去重，Slack未收到200时会重试）
+	Event     Event  `json:"event"`
+}
+
+// Event 消息事件（app_mention 或 message）
+type Event struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"` // 编辑、删除、机器人消息等有subtype，不处理
+	User        string `json:"user"`
+	BotID       string `json:"bot_id,omitempty"`
+	Text        string `json:"text"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type,omitempty"` // im 为私信
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts,omitempty"` // 话题中的消息为话题根消息的ts
+}
+
+// VerifySignature 校验Events API请求签名（HMAC-SHA256(v0:时间戳:请求体)）
+func VerifySignature(secret, timestamp, signature string, body []byte) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("时间戳无效: %q", timestamp)
+	}
+	if skew := time.Since(time.Unix(ts, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("请求时间戳已过期")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("签名验证失败")
+	}
+	return nil
+}
+
+// HandleEvents Events API回调处理器：验证签名，URL验证返回challenge，消息事件在后台处理后立即返回200
+func (a *Adapter) HandleEvents(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEventBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	err = VerifySignature(a.signingSecret,
+		c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body)
+	if err != nil {
+		slog.Warn("Slack回调校验失败", "err", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Verification failed"})
+		return
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event format"})
+		return
+	}
+	if env.Type == "url_verification" {
+		c.JSON(http.StatusOK, gin.H{"challenge": env.Challenge})
+		return
+	}
+	a.dispatch(env)
+	c.Status(http.StatusOK)
+}
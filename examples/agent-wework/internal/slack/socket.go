@@ -0,0 +1,96 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// reconnectDelay Socket Mode连接失败后的重连间隔
+const reconnectDelay = 5 * time.Second
+
+// socketMessage Socket Mode消息
+type socketMessage struct {
+	EnvelopeID string          `json:"envelope_id,omitempty"`
+	Type       string          `json:"type"` // hello | events_api | disconnect 等
+	Reason     string          `json:"reason,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// RunSocketMode 以Socket Mode接收事件（主动连接Slack，无需公网回调地址），断线后自动重连，直到ctx取消
+func (a *Adapter) RunSocketMode(ctx context.Context) {
+	for {
+		err := a.runSocket(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Warn("Slack Socket Mode连接断开，稍后重连", "err", err, "delay", reconnectDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}
+}
+
+// runSocket 建立一次连接并处理消息，Slack要求刷新连接（disconnect）时返回nil
+func (a *Adapter) runSocket(ctx context.Context) error {
+	openCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+	url, err := a.app.OpenConnection(openCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return fmt.Errorf("连接Socket Mode失败: %w", err)
+	}
+	defer conn.Close()
+
+	// ctx取消时关闭连接，结束阻塞的读取
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var msg socketMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+
+		// 先确认再处理，Slack 3秒内未收到确认会重发
+		if msg.EnvelopeID != "" {
+			if err := conn.WriteJSON(map[string]string{"envelope_id": msg.EnvelopeID}); err != nil {
+				return err
+			}
+		}
+
+		switch msg.Type {
+		case "hello":
+			slog.Info("Slack Socket Mode已连接")
+		case "disconnect":
+			slog.Info("Slack要求重新连接", "reason", msg.Reason)
+			return nil
+		case "events_api":
+			var env Envelope
+			if err := json.Unmarshal(msg.Payload, &env); err != nil {
+				slog.Warn("Slack事件解析失败", "err", err)
+				continue
+			}
+			a.dispatch(env)
+		}
+	}
+}
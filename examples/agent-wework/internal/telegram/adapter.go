@@ -0,0 +1,230 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/relay"
+)
+
+const (
+	apiTimeout      = 10 * time.Second // 单次Bot API调用超时
+	replyTimeout    = 10 * time.Minute // 等待机器人完成回复的最长时间
+	maxMessageBytes = 4096             // 消息文本上限（Telegram为4096字符，按字节拆分保证不超限）
+)
+
+// Adapter Telegram渠道：私聊回复所有消息，群聊只回复@机器人、回复机器人消息或发给机器人的命令；
+// 生成过程中按编辑间隔编辑同一条消息；私聊按用户、群聊按群对应一个会话
+type Adapter struct {
+	api     *Client
+	handler wework.MessageHandler
+	dedup   wework.Deduplicator
+	me      *User
+
+	webhookURL   string
+	secretToken  string
+	editInterval time.Duration
+	commands     []BotCommand
+}
+
+// NewAdapter 创建Telegram渠道（调用getMe验证Token并获取机器人用户名）
+func NewAdapter(cfg config.TelegramConfig, handler wework.MessageHandler, dedup wework.Deduplicator) (*Adapter, error) {
+	a := &Adapter{
+		api:          NewClient(cfg.Token, cfg.BaseURL),
+		handler:      handler,
+		dedup:        dedup,
+		webhookURL:   cfg.WebhookURL,
+		secretToken:  cfg.SecretToken,
+		editInterval: time.Duration(cfg.EditInterval) * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	me, err := a.api.GetMe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("验证telegram.token失败: %w", err)
+	}
+	a.me = me
+	return a, nil
+}
+
+// SetCommands 设置机器人的斜杠命令（交给机器人的命令处理，/start、/help 由渠道回复）
+func (a *Adapter) SetCommands(commands []BotCommand) {
+	a.commands = commands
+}
+
+// Webhook 是否使用Webhook接收更新
+func (a *Adapter) Webhook() bool {
+	return a.webhookURL != ""
+}
+
+// Start 注册命令菜单；Webhook模式设置回调地址，否则删除Webhook后在后台长轮询，直到ctx取消
+func (a *Adapter) Start(ctx context.Context) error {
+	menu := append([]BotCommand{{Command: "help", Description: "查看使用说明"}}, a.commands...)
+	callCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	if err := a.api.SetMyCommands(callCtx, menu); err != nil {
+		slog.Warn("Telegram命令菜单设置失败", "err", err)
+	}
+
+	if a.Webhook() {
+		if err := a.api.SetWebhook(callCtx, a.webhookURL, a.secretToken); err != nil {
+			return err
+		}
+		slog.Info("Telegram Webhook已设置", "webhook_url", a.webhookURL)
+		return nil
+	}
+	if err := a.api.DeleteWebhook(callCtx); err != nil {
+		return err
+	}
+	go a.poll(ctx)
+	return nil
+}
+
+// dispatch 筛选需要回复的消息并在后台处理
+func (a *Adapter) dispatch(update Update) {
+	m := update.Message
+	if m == nil || m.From == nil || m.From.IsBot || m.Text == "" {
+		return
+	}
+	text, ok := a.addressed(m)
+	if !ok || text == "" {
+		return
+	}
+	// Webhook未及时收到200时Telegram会重发
+	if a.dedup.Seen(fmt.Sprintf("tg_%d_%d", m.Chat.ID, m.MessageID)) {
+		return
+	}
+	go a.reply(m, text)
+}
+
+// addressed 判断消息是否发给机器人，返回去掉@机器人后的文本；
+// 命令中的 @机器人名 一并去掉（/settings@my_bot language English → /settings language English），
+// 交给机器人的命令处理
+func (a *Adapter) addressed(m *Message) (string, bool) {
+	text := m.Text
+	mention := "@" + a.me.Username
+	private := m.Chat.Type == "private"
+	ok := private || strings.Contains(text, mention) ||
+		(m.ReplyTo != nil && m.ReplyTo.From != nil && m.ReplyTo.From.ID == a.me.ID)
+
+	if strings.HasPrefix(text, "/") {
+		command, args, _ := strings.Cut(text, " ")
+		if name, target, found := strings.Cut(command, "@"); found {
+			if !strings.EqualFold(target, a.me.Username) {
+				return "", false // 发给群里其他机器人的命令
+			}
+			command = name
+		}
+		text = strings.TrimSpace(command + " " + args)
+		ok = true
+	}
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(strings.ReplaceAll(text, mention, "")), true
+}
+
+// conversation 私聊按用户（single_tg_<用户ID>）、群聊按群（group_tg_<群ID>）对应会话
+func conversation(m *Message) wework.BaseMessage {
+	base := wework.BaseMessage{
+		MsgID:    strconv.FormatInt(m.MessageID, 10),
+		ChatType: wework.ChatTypeSingle,
+		From:     wework.From{UserID: "tg_" + strconv.FormatInt(m.From.ID, 10)},
+		MsgType:  wework.MsgTypeText,
+	}
+	if m.Chat.Type != "private" {
+		base.ChatType = wework.ChatTypeGroup
+		base.ChatID = "tg_" + strconv.FormatInt(m.Chat.ID, 10)
+	}
+	return base
+}
+
+// reply 交给机器人处理，发送回复并随生成进度编辑
+func (a *Adapter) reply(m *Message, text string) {
+	chatID := m.Chat.ID
+	// 私聊直接回复，群聊引用原消息
+	var replyTo int64
+	if m.Chat.Type != "private" {
+		replyTo = m.MessageID
+	}
+
+	if command, _, _ := strings.Cut(text, " "); command == "/start" || command == "/help" {
+		a.send(chatID, replyTo, a.help())
+		return
+	}
+
+	slog.Debug("Telegram消息", "chat_id", chatID, "user_id", m.From.ID, "text", text)
+	resp, err := a.handler.HandleMessage(&wework.IncomingMessage{
+		BaseMessage: conversation(m),
+		Text:        &wework.TextContent{Content: text},
+	})
+	if err != nil {
+		slog.Warn("Telegram消息处理失败", "chat_id", chatID, "err", err)
+	}
+	if resp == nil {
+		return
+	}
+
+	if card := relay.CardText(resp.TemplateCard); card != "" {
+		a.send(chatID, replyTo, card)
+	}
+	var messageID int64
+	relay.Follow(a.handler, resp, a.editInterval, replyTimeout, func(content string, finish bool) {
+		if strings.TrimSpace(content) == "" {
+			return
+		}
+		chunks := relay.Split(content, maxMessageBytes)
+		if messageID == 0 {
+			messageID = a.send(chatID, replyTo, chunks[0])
+		} else {
+			a.edit(chatID, messageID, chunks[0])
+		}
+		// 生成过程中只编辑第一条，结束后超出部分作为新消息发送
+		if finish {
+			for _, chunk := range chunks[1:] {
+				a.send(chatID, 0, chunk)
+			}
+		}
+	})
+}
+
+// help /start、/help 的回复
+func (a *Adapter) help() string {
+	var b strings.Builder
+	b.WriteString("你好，直接发送问题即可开始对话。")
+	if len(a.commands) > 0 {
+		b.WriteString("\n\n可用命令：")
+		for _, c := range a.commands {
+			fmt.Fprintf(&b, "\n/%s %s", c.Command, c.Description)
+		}
+	}
+	return b.String()
+}
+
+// send 发送消息，失败时返回0
+func (a *Adapter) send(chatID, replyTo int64, content string) int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	messageID, err := a.api.SendMessage(ctx, chatID, replyTo, content)
+	if err != nil {
+		slog.Warn("Telegram回复发送失败", "chat_id", chatID, "err", err)
+	}
+	return messageID
+}
+
+// edit 编辑已发送的回复（失败时忽略，下次编辑会带上完整内容）
+func (a *Adapter) edit(chatID, messageID int64, content string) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	err := a.api.EditMessageText(ctx, chatID, messageID, content)
+	if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		slog.Warn("Telegram回复编辑失败", "chat_id", chatID, "err", err)
+	}
+}
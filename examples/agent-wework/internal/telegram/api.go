@@ -0,0 +1,183 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL Telegram Bot API地址
+const DefaultBaseURL = "https://api.telegram.org"
+
+// Update 收到的更新（只处理消息）
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message 消息
+type Message struct {
+	MessageID int64    `json:"message_id"`
+	From      *User    `json:"from,omitempty"`
+	Chat      Chat     `json:"chat"`
+	Text      string   `json:"text,omitempty"`
+	Entities  []Entity `json:"entities,omitempty"`
+	ReplyTo   *Message `json:"reply_to_message,omitempty"`
+}
+
+// User 用户
+type User struct {
+	ID       int64  `json:"id"`
+	IsBot    bool   `json:"is_bot"`
+	Username string `json:"username,omitempty"`
+}
+
+// Chat 会话
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // private | group | supergroup | channel
+}
+
+// Entity 消息中的特殊片段（命令、@提及等）
+type Entity struct {
+	Type   string `json:"type"` // bot_command | mention 等
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+// BotCommand 命令菜单项
+type BotCommand struct {
+	Command     string `json:"command"` // 不含 / 前缀
+	Description string `json:"description"`
+}
+
+// Client Telegram Bot API客户端
+type Client struct {
+	baseURL string // 含Bot Token的接口前缀
+	client  *http.Client
+}
+
+// NewClient 创建Bot API客户端
+func NewClient(token, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/bot" + token,
+		client:  &http.Client{Timeout: 60 * time.Second}, // 长轮询最长等待pollTimeout
+	}
+}
+
+// GetMe 获取机器人信息（验证Token，群聊中识别@机器人）
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	var me User
+	if err := c.call(ctx, "getMe", struct{}{}, &me); err != nil {
+		return nil, err
+	}
+	return &me, nil
+}
+
+// GetUpdates 长轮询获取更新（offset为上次最大update_id+1，同时确认之前的更新）
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout int) ([]Update, error) {
+	var updates []Update
+	req := map[string]interface{}{
+		"offset":          offset,
+		"timeout":         timeout,
+		"allowed_updates": []string{"message"},
+	}
+	if err := c.call(ctx, "getUpdates", req, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// SetWebhook 设置Webhook（Telegram回调时在 X-Telegram-Bot-Api-Secret-Token 头中携带secretToken）
+func (c *Client) SetWebhook(ctx context.Context, webhookURL, secretToken string) error {
+	req := map[string]interface{}{
+		"url":             webhookURL,
+		"secret_token":    secretToken,
+		"allowed_updates": []string{"message"},
+	}
+	return c.call(ctx, "setWebhook", req, nil)
+}
+
+// DeleteWebhook 删除Webhook（设置了Webhook时无法使用长轮询）
+func (c *Client) DeleteWebhook(ctx context.Context) error {
+	return c.call(ctx, "deleteWebhook", struct{}{}, nil)
+}
+
+// SetMyCommands 设置命令菜单
+func (c *Client) SetMyCommands(ctx context.Context, commands []BotCommand) error {
+	return c.call(ctx, "setMyCommands", map[string]interface{}{"commands": commands}, nil)
+}
+
+// SendMessage 发送文本消息（replyTo非0时作为对该消息的回复），返回消息ID
+func (c *Client) SendMessage(ctx context.Context, chatID, replyTo int64, text string) (int64, error) {
+	req := map[string]interface{}{"chat_id": chatID, "text": text}
+	if replyTo != 0 {
+		req["reply_parameters"] = map[string]interface{}{"message_id": replyTo, "allow_sending_without_reply": true}
+	}
+	var msg Message
+	if err := c.call(ctx, "sendMessage", req, &msg); err != nil {
+		return 0, err
+	}
+	return msg.MessageID, nil
+}
+
+// EditMessageText 编辑已发送的文本消息（内容未变化时Telegram返回错误，调用方可忽略）
+func (c *Client) EditMessageText(ctx context.Context, chatID, messageID int64, text string) error {
+	req := map[string]interface{}{"chat_id": chatID, "message_id": messageID, "text": text}
+	return c.call(ctx, "editMessageText", req, nil)
+}
+
+// call 调用Bot API方法，ok为false时返回Telegram的错误描述
+func (c *Client) call(ctx context.Context, method string, req, result interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		// 错误信息中的URL含Bot Token，只保留底层错误
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("调用Telegram %s失败: %w", method, err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("解析Telegram %s响应失败: HTTP %d", method, httpResp.StatusCode)
+	}
+	if !resp.OK {
+		return fmt.Errorf("Telegram %s返回错误: %s", method, resp.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
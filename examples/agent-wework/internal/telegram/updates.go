@@ -0,0 +1,69 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	pollTimeout   = 30              // 长轮询等待时间（秒）
+	retryDelay    = 5 * time.Second // 长轮询失败后的重试间隔
+	maxUpdateBody = 1 << 20         // Webhook请求体的最大长度
+)
+
+// poll 长轮询接收更新（无需公网回调地址），直到ctx取消
+func (a *Adapter) poll(ctx context.Context) {
+	slog.Info("Telegram长轮询已启动")
+	var offset int64
+	for ctx.Err() == nil {
+		pollCtx, cancel := context.WithTimeout(ctx, pollTimeout*time.Second+apiTimeout)
+		updates, err := a.api.GetUpdates(pollCtx, offset, pollTimeout)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("Telegram获取更新失败，稍后重试", "err", err, "delay", retryDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1 // 下次请求时确认已收到的更新
+			a.dispatch(update)
+		}
+	}
+}
+
+// HandleWebhook Webhook回调处理器：校验 X-Telegram-Bot-Api-Secret-Token，消息在后台处理后立即返回200
+func (a *Adapter) HandleWebhook(c *gin.Context) {
+	token := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.secretToken)) != 1 {
+		slog.Warn("Telegram回调校验令牌不匹配")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Verification failed"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxUpdateBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	var update Update
+	if err := json.Unmarshal(body, &update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid update format"})
+		return
+	}
+	a.dispatch(update)
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,171 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// feishuTokenMargin 插件令牌提前刷新的时间
+const feishuTokenMargin = 5 * time.Minute
+
+// feishuPriorities 优先级在描述中的说明（工作项优先级字段的选项由各空间自定义，不直接设置）
+var feishuPriorities = map[string]string{
+	PriorityHigh:   "高",
+	PriorityMedium: "中",
+	PriorityLow:    "低",
+}
+
+// FeishuProject 基于飞书项目开放接口的工单系统（插件令牌+提单人user_key）
+type FeishuProject struct {
+	baseURL      string
+	project      string
+	typeKey      string
+	userKey      string
+	pluginID     string
+	pluginSecret string
+	client       *http.Client
+
+	mutex   sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// feishuError 飞书项目接口的错误字段（不同接口分别使用err_code和error.code）
+type feishuError struct {
+	ErrCode int    `json:"err_code"`
+	ErrMsg  string `json:"err_msg"`
+	Error   struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// err 接口返回的业务错误（成功时为nil）
+func (e feishuError) err() error {
+	if e.ErrCode != 0 {
+		return fmt.Errorf("飞书项目返回错误: %d, %s", e.ErrCode, e.ErrMsg)
+	}
+	if e.Error.Code != 0 {
+		return fmt.Errorf("飞书项目返回错误: %d, %s", e.Error.Code, e.Error.Msg)
+	}
+	return nil
+}
+
+// pluginToken 获取插件令牌（有效期内复用）
+func (f *FeishuProject) pluginToken(ctx context.Context) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.token != "" && time.Now().Before(f.expires) {
+		return f.token, nil
+	}
+
+	var result struct {
+		feishuError
+		Data struct {
+			Token      string `json:"token"`
+			ExpireTime int    `json:"expire_time"` // 秒
+		} `json:"data"`
+	}
+	body := map[string]interface{}{"plugin_id": f.pluginID, "plugin_secret": f.pluginSecret, "type": 0}
+	if err := doJSON(ctx, f.client, http.MethodPost, f.baseURL+"/open_api/authen/plugin_token", nil, body, &result, "飞书项目"); err != nil {
+		return "", err
+	}
+	if err := result.err(); err != nil {
+		return "", err
+	}
+	if result.Data.Token == "" {
+		return "", fmt.Errorf("飞书项目未返回插件令牌")
+	}
+	f.token = result.Data.Token
+	f.expires = time.Now().Add(time.Duration(result.Data.ExpireTime)*time.Second - feishuTokenMargin)
+	return f.token, nil
+}
+
+// call 以插件令牌和提单人身份调用开放接口
+func (f *FeishuProject) call(ctx context.Context, path string, body, out interface{}) error {
+	token, err := f.pluginToken(ctx)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("X-PLUGIN-TOKEN", token)
+	header.Set("X-USER-KEY", f.userKey)
+	return doJSON(ctx, f.client, http.MethodPost, f.baseURL+"/open_api/"+url.PathEscape(f.project)+path, header, body, out, "飞书项目")
+}
+
+// Create 实现Provider接口
+func (f *FeishuProject) Create(ctx context.Context, req Request) (*Ticket, error) {
+	description := req.Description
+	if level, ok := feishuPriorities[req.Priority]; ok {
+		description = "优先级：" + level + "\n\n" + description
+	}
+
+	var result struct {
+		feishuError
+		Data int64 `json:"data"` // 工作项ID
+	}
+	body := map[string]interface{}{
+		"work_item_type_key": f.typeKey,
+		"name":               req.Title,
+		"field_value_pairs":  []map[string]interface{}{{"field_key": "description", "field_value": description}},
+	}
+	if err := f.call(ctx, "/work_item/create", body, &result); err != nil {
+		return nil, err
+	}
+	if err := result.err(); err != nil {
+		return nil, err
+	}
+	key := strconv.FormatInt(result.Data, 10)
+	return &Ticket{Key: key, Title: req.Title, URL: f.detailURL(key)}, nil
+}
+
+// Get 实现Provider接口
+func (f *FeishuProject) Get(ctx context.Context, key string) (*Ticket, error) {
+	id, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("飞书项目工作项ID应为数字: %s", key)
+	}
+
+	var result struct {
+		feishuError
+		Data []struct {
+			ID             int64  `json:"id"`
+			Name           string `json:"name"`
+			UpdatedAt      int64  `json:"updated_at"` // 毫秒
+			WorkItemStatus struct {
+				StateKey string `json:"state_key"`
+			} `json:"work_item_status"`
+		} `json:"data"`
+	}
+	if err := f.call(ctx, "/work_item/"+url.PathEscape(f.typeKey)+"/query", map[string]interface{}{"work_item_ids": []int64{id}}, &result); err != nil {
+		return nil, err
+	}
+	if err := result.err(); err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("工作项 %s 不存在", key)
+	}
+
+	item := result.Data[0]
+	t := &Ticket{
+		Key:    key,
+		Title:  item.Name,
+		Status: item.WorkItemStatus.StateKey,
+		URL:    f.detailURL(key),
+	}
+	if item.UpdatedAt > 0 {
+		t.Updated = time.UnixMilli(item.UpdatedAt).Format("2006-01-02 15:04")
+	}
+	return t, nil
+}
+
+// detailURL 工作项详情页地址
+func (f *FeishuProject) detailURL(key string) string {
+	return f.baseURL + "/" + f.project + "/" + f.typeKey + "/detail/" + key
+}
@@ -0,0 +1,106 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// jiraKeyRegex Jira问题编号格式（项目Key-序号）
+var jiraKeyRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// jiraPriorities 优先级对应的Jira默认优先级名称
+var jiraPriorities = map[string]string{
+	PriorityHigh:   "High",
+	PriorityMedium: "Medium",
+	PriorityLow:    "Low",
+}
+
+// Jira 基于Jira REST API v2的工单系统（Cloud使用邮箱+API令牌，Server/DC可使用个人访问令牌）
+type Jira struct {
+	baseURL   string
+	project   string
+	issueType string
+	username  string
+	token     string
+	client    *http.Client
+}
+
+// header 认证请求头：配置了账号时使用Basic认证，否则作为个人访问令牌
+func (j *Jira) header() http.Header {
+	req := &http.Request{Header: http.Header{}}
+	if j.username != "" {
+		req.SetBasicAuth(j.username, j.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+	return req.Header
+}
+
+// Create 实现Provider接口
+func (j *Jira) Create(ctx context.Context, req Request) (*Ticket, error) {
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": j.project},
+		"issuetype":   map[string]string{"name": j.issueType},
+		"summary":     req.Title,
+		"description": req.Description,
+	}
+	if name, ok := jiraPriorities[req.Priority]; ok {
+		fields["priority"] = map[string]string{"name": name}
+	}
+
+	var result struct {
+		Key string `json:"key"`
+	}
+	if err := doJSON(ctx, j.client, http.MethodPost, j.baseURL+"/rest/api/2/issue", j.header(), map[string]interface{}{"fields": fields}, &result, "Jira"); err != nil {
+		return nil, err
+	}
+	if result.Key == "" {
+		return nil, fmt.Errorf("Jira未返回问题编号")
+	}
+	return &Ticket{Key: result.Key, Title: req.Title, URL: j.browseURL(result.Key)}, nil
+}
+
+// Get 实现Provider接口
+func (j *Jira) Get(ctx context.Context, key string) (*Ticket, error) {
+	if !jiraKeyRegex.MatchString(key) {
+		return nil, fmt.Errorf("Jira问题编号格式无效: %s（应如 %s-123）", key, j.project)
+	}
+
+	var result struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Updated string `json:"updated"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+			Assignee *struct {
+				DisplayName string `json:"displayName"`
+			} `json:"assignee"`
+		} `json:"fields"`
+	}
+	endpoint := j.baseURL + "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,status,assignee,updated"
+	if err := doJSON(ctx, j.client, http.MethodGet, endpoint, j.header(), nil, &result, "Jira"); err != nil {
+		return nil, err
+	}
+
+	t := &Ticket{
+		Key:     result.Key,
+		Title:   result.Fields.Summary,
+		Status:  result.Fields.Status.Name,
+		Updated: result.Fields.Updated,
+		URL:     j.browseURL(result.Key),
+	}
+	if result.Fields.Assignee != nil {
+		t.Assignee = result.Fields.Assignee.DisplayName
+	}
+	return t, nil
+}
+
+// browseURL 问题的浏览地址
+func (j *Jira) browseURL(key string) string {
+	return j.baseURL + "/browse/" + key
+}
@@ -0,0 +1,116 @@
+// Package ticket 工单集成：为Agent提供在Jira、飞书项目或ServiceNow中提单和查询工单的工具
+package ticket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// maxResponseBytes 工单系统响应的大小上限
+const maxResponseBytes = 1 << 20
+
+// 工单优先级
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// Request 提单内容
+type Request struct {
+	Title       string
+	Description string
+	Priority    string // high、medium 或 low（为空时使用工单系统默认值）
+}
+
+// Ticket 工单
+type Ticket struct {
+	Key      string // 工单编号（Jira如 OPS-123，飞书项目为工作项ID，ServiceNow如 INC0010001）
+	Title    string
+	Status   string
+	Assignee string
+	Updated  string
+	URL      string
+}
+
+// Provider 工单系统接口
+type Provider interface {
+	// Create 创建工单
+	Create(ctx context.Context, req Request) (*Ticket, error)
+	// Get 按编号查询工单
+	Get(ctx context.Context, key string) (*Ticket, error)
+}
+
+// NewProviderFromConfig 根据配置创建工单系统客户端
+func NewProviderFromConfig(cfg config.TicketConfig) (Provider, error) {
+	client := &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second}
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	switch cfg.Provider {
+	case "jira":
+		return &Jira{baseURL: baseURL, project: cfg.Project, issueType: cfg.Type, username: cfg.Username, token: cfg.Token, client: client}, nil
+	case "feishu_project":
+		return &FeishuProject{baseURL: baseURL, project: cfg.Project, typeKey: cfg.Type, userKey: cfg.Username, pluginID: cfg.PluginID, pluginSecret: cfg.Token, client: client}, nil
+	case "servicenow":
+		return &ServiceNow{baseURL: baseURL, table: cfg.Type, username: cfg.Username, password: cfg.Token, client: client}, nil
+	default:
+		return nil, fmt.Errorf("不支持的工单系统: %s", cfg.Provider)
+	}
+}
+
+// doJSON 发送JSON请求并将响应解析到out（非2xx状态视为失败）
+func doJSON(ctx context.Context, client *http.Client, method, endpoint string, header http.Header, body, out interface{}, service string) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s请求失败: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s返回错误: %d, %s", service, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析%s响应失败: %w", service, err)
+	}
+	return nil
+}
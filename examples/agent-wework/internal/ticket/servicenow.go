@@ -0,0 +1,109 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// serviceNowNumberRegex ServiceNow记录编号格式（前缀+数字，如 INC0010001）
+var serviceNowNumberRegex = regexp.MustCompile(`^[A-Z]{2,8}[0-9]+$`)
+
+// serviceNowUrgency 优先级对应的ServiceNow紧急程度
+var serviceNowUrgency = map[string]string{
+	PriorityHigh:   "1",
+	PriorityMedium: "2",
+	PriorityLow:    "3",
+}
+
+// serviceNowFields 查询时返回的字段
+const serviceNowFields = "sys_id,number,short_description,state,assigned_to,sys_updated_on"
+
+// ServiceNow 基于ServiceNow Table API的工单系统（Basic认证）
+type ServiceNow struct {
+	baseURL  string
+	table    string
+	username string
+	password string
+	client   *http.Client
+}
+
+// serviceNowRecord 以显示值返回的记录（引用字段为显示名称）
+type serviceNowRecord struct {
+	SysID            string `json:"sys_id"`
+	Number           string `json:"number"`
+	ShortDescription string `json:"short_description"`
+	State            string `json:"state"`
+	AssignedTo       string `json:"assigned_to"`
+	UpdatedOn        string `json:"sys_updated_on"`
+}
+
+// header Basic认证请求头
+func (s *ServiceNow) header() http.Header {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(s.username, s.password)
+	return req.Header
+}
+
+// tableURL 表接口地址（返回显示值，引用字段不带链接）
+func (s *ServiceNow) tableURL(query url.Values) string {
+	query.Set("sysparm_display_value", "true")
+	query.Set("sysparm_exclude_reference_link", "true")
+	query.Set("sysparm_fields", serviceNowFields)
+	return s.baseURL + "/api/now/table/" + url.PathEscape(s.table) + "?" + query.Encode()
+}
+
+// Create 实现Provider接口
+func (s *ServiceNow) Create(ctx context.Context, req Request) (*Ticket, error) {
+	body := map[string]string{
+		"short_description": req.Title,
+		"description":       req.Description,
+	}
+	if urgency, ok := serviceNowUrgency[req.Priority]; ok {
+		body["urgency"] = urgency
+	}
+
+	var result struct {
+		Result serviceNowRecord `json:"result"`
+	}
+	if err := doJSON(ctx, s.client, http.MethodPost, s.tableURL(url.Values{}), s.header(), body, &result, "ServiceNow"); err != nil {
+		return nil, err
+	}
+	if result.Result.Number == "" {
+		return nil, fmt.Errorf("ServiceNow未返回记录编号")
+	}
+	return s.ticket(result.Result), nil
+}
+
+// Get 实现Provider接口
+func (s *ServiceNow) Get(ctx context.Context, key string) (*Ticket, error) {
+	if !serviceNowNumberRegex.MatchString(key) {
+		return nil, fmt.Errorf("ServiceNow记录编号格式无效: %s（应如 INC0010001）", key)
+	}
+
+	var result struct {
+		Result []serviceNowRecord `json:"result"`
+	}
+	query := url.Values{"sysparm_query": {"number=" + key}, "sysparm_limit": {"1"}}
+	if err := doJSON(ctx, s.client, http.MethodGet, s.tableURL(query), s.header(), nil, &result, "ServiceNow"); err != nil {
+		return nil, err
+	}
+	if len(result.Result) == 0 {
+		return nil, fmt.Errorf("工单 %s 不存在", key)
+	}
+	return s.ticket(result.Result[0]), nil
+}
+
+// ticket 转换为工单（地址为后台中的记录页面）
+func (s *ServiceNow) ticket(r serviceNowRecord) *Ticket {
+	return &Ticket{
+		Key:      r.Number,
+		Title:    r.ShortDescription,
+		Status:   r.State,
+		Assignee: r.AssignedTo,
+		Updated:  r.UpdatedOn,
+		URL:      s.baseURL + "/nav_to.do?uri=" + url.QueryEscape(s.table+".do?sys_id="+r.SysID),
+	}
+}
@@ -0,0 +1,179 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+)
+
+// RequesterFunc 返回上下文中发起对话的用户（无法确定时返回空字符串）
+type RequesterFunc func(ctx context.Context) string
+
+// CreateTool 提单工具（供Agent调用），描述末尾附带提单人和来源会话
+type CreateTool struct {
+	provider  Provider
+	requester RequesterFunc
+}
+
+// NewCreateTool 创建提单工具
+func NewCreateTool(provider Provider, requester RequesterFunc) *CreateTool {
+	return &CreateTool{provider: provider, requester: requester}
+}
+
+// Name implements interfaces.Tool.Name
+func (t *CreateTool) Name() string {
+	return "create_ticket"
+}
+
+// Description implements interfaces.Tool.Description
+func (t *CreateTool) Description() string {
+	return "在公司的工单系统中为用户提交工单（故障报修、权限申请、需求等）。" +
+		"仅在用户明确要求提单，或问题需要IT/运维跟进处理时使用；提交前应向用户确认已收集到现象、影响范围和复现方式等关键信息。"
+}
+
+// Parameters implements interfaces.Tool.Parameters
+func (t *CreateTool) Parameters() map[string]interfaces.ParameterSpec {
+	return map[string]interfaces.ParameterSpec{
+		"title": {
+			Type:        "string",
+			Description: "工单标题，一句话概括问题",
+			Required:    true,
+		},
+		"description": {
+			Type:        "string",
+			Description: "问题详情：现象、影响范围、复现步骤、已尝试的操作等",
+			Required:    true,
+		},
+		"priority": {
+			Type:        "string",
+			Description: "优先级：high（影响业务或多人）、medium（默认）、low",
+			Enum:        []interface{}{PriorityHigh, PriorityMedium, PriorityLow},
+		},
+	}
+}
+
+// Run implements interfaces.Tool.Run（直接传入文本时作为标题和描述）
+func (t *CreateTool) Run(ctx context.Context, input string) (string, error) {
+	input = strings.TrimSpace(input)
+	title, _, _ := strings.Cut(input, "\n")
+	return t.create(ctx, Request{Title: title, Description: input})
+}
+
+// Execute implements interfaces.Tool.Execute
+func (t *CreateTool) Execute(ctx context.Context, args string) (string, error) {
+	var params struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Priority    string `json:"priority"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil || params.Title == "" {
+		// 兼容直接传入文本的情况
+		return t.Run(ctx, args)
+	}
+	return t.create(ctx, Request{Title: params.Title, Description: params.Description, Priority: params.Priority})
+}
+
+// create 附加提单人信息后提交
+func (t *CreateTool) create(ctx context.Context, req Request) (string, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		return "", fmt.Errorf("工单标题不能为空")
+	}
+	var source []string
+	if t.requester != nil {
+		if user := t.requester(ctx); user != "" {
+			source = append(source, "提单人："+user)
+		}
+	}
+	if conversationID, ok := memory.GetConversationID(ctx); ok {
+		source = append(source, "来源会话："+conversationID)
+	}
+	if len(source) > 0 {
+		req.Description = strings.TrimSpace(req.Description) + "\n\n---\n由企业微信机器人代为提交\n" + strings.Join(source, "\n")
+	}
+
+	ticket, err := t.provider.Create(ctx, req)
+	if err != nil {
+		slog.WarnContext(ctx, "create_ticket 失败", "err", err)
+		return "", fmt.Errorf("提交工单失败: %w", err)
+	}
+	slog.InfoContext(ctx, "create_ticket", "key", ticket.Key)
+	return fmt.Sprintf("工单已创建，编号 %s，链接 %s。请告知用户工单编号和链接，后续可凭编号查询进度。", ticket.Key, ticket.URL), nil
+}
+
+// GetTool 查询工单工具（供Agent调用）
+type GetTool struct {
+	provider Provider
+}
+
+// NewGetTool 创建查询工单工具
+func NewGetTool(provider Provider) *GetTool {
+	return &GetTool{provider: provider}
+}
+
+// Name implements interfaces.Tool.Name
+func (t *GetTool) Name() string {
+	return "get_ticket"
+}
+
+// Description implements interfaces.Tool.Description
+func (t *GetTool) Description() string {
+	return "按工单编号查询工单的标题、状态、处理人和最近更新时间，用于回答用户“我的工单处理到哪了”之类的问题。"
+}
+
+// Parameters implements interfaces.Tool.Parameters
+func (t *GetTool) Parameters() map[string]interfaces.ParameterSpec {
+	return map[string]interfaces.ParameterSpec{
+		"key": {
+			Type:        "string",
+			Description: "工单编号",
+			Required:    true,
+		},
+	}
+}
+
+// Run implements interfaces.Tool.Run
+func (t *GetTool) Run(ctx context.Context, input string) (string, error) {
+	key := strings.TrimPrefix(strings.TrimSpace(input), "#")
+	if key == "" {
+		return "", fmt.Errorf("工单编号不能为空")
+	}
+	ticket, err := t.provider.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "get_ticket 失败", "key", key, "err", err)
+		return "", fmt.Errorf("查询工单失败: %w", err)
+	}
+
+	lines := []string{
+		"编号：" + ticket.Key,
+		"标题：" + ticket.Title,
+		"状态：" + ticket.Status,
+	}
+	if ticket.Assignee != "" {
+		lines = append(lines, "处理人："+ticket.Assignee)
+	} else {
+		lines = append(lines, "处理人：未分配")
+	}
+	if ticket.Updated != "" {
+		lines = append(lines, "更新时间："+ticket.Updated)
+	}
+	lines = append(lines, "链接："+ticket.URL)
+	return strings.Join(lines, "\n"), nil
+}
+
+// Execute implements interfaces.Tool.Execute
+func (t *GetTool) Execute(ctx context.Context, args string) (string, error) {
+	var params struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil || params.Key == "" {
+		// 兼容直接传入编号的情况
+		return t.Run(ctx, args)
+	}
+	return t.Run(ctx, params.Key)
+}
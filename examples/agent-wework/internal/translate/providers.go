@@ -0,0 +1,107 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// LLMTranslator 基于LLM的翻译实现
+type LLMTranslator struct {
+	client interfaces.LLM
+}
+
+// NewLLMTranslator 创建LLM翻译器
+func NewLLMTranslator(client interfaces.LLM) *LLMTranslator {
+	return &LLMTranslator{client: client}
+}
+
+// Translate 实现Translator接口
+func (t *LLMTranslator) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	systemPrompt := fmt.Sprintf("你是专业翻译引擎。将用户提供的%s文本翻译为%s。"+
+		"要求：只输出译文，不要解释；保留Markdown格式（标题、列表、表格、加粗等）；"+
+		"形如⟦数字⟧的占位符和方括号标记（如[用户 xxx]）必须原样保留。",
+		languageName(sourceLang), languageName(targetLang))
+
+	result, err := t.client.Generate(ctx, text, func(opts *interfaces.GenerateOptions) {
+		opts.SystemMessage = systemPrompt
+	})
+	if err != nil {
+		return "", fmt.Errorf("LLM翻译失败: %w", err)
+	}
+
+	return strings.TrimSpace(result), nil
+}
+
+// DeepLTranslator 基于DeepL API的翻译实现
+type DeepLTranslator struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewDeepLTranslator 创建DeepL翻译器
+func NewDeepLTranslator(apiKey, baseURL string) *DeepLTranslator {
+	if baseURL == "" {
+		baseURL = "https://api-free.deepl.com/v2"
+	}
+	return &DeepLTranslator{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Translate 实现Translator接口
+func (t *DeepLTranslator) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"text":        []string{text},
+		"source_lang": strings.ToUpper(sourceLang),
+		"target_lang": strings.ToUpper(targetLang),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/translate", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+t.apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("DeepL请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("DeepL返回错误: %d, %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("解析DeepL响应失败: %w", err)
+	}
+	if len(result.Translations) == 0 {
+		return "", fmt.Errorf("DeepL未返回译文")
+	}
+
+	return result.Translations[0].Text, nil
+}
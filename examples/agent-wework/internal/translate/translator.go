@@ -0,0 +1,187 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/logging"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/llm"
+)
+
+// 语言代码常量
+const (
+	LangChinese  = "zh"
+	LangEnglish  = "en"
+	LangJapanese = "ja"
+	LangKorean   = "ko"
+	LangRussian  = "ru"
+)
+
+// Translator 翻译提供商接口
+type Translator interface {
+	Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error)
+}
+
+// Service 翻译服务 - 负责语言检测、代码块保护和提供商调用
+type Service struct {
+	translator Translator
+}
+
+// NewService 创建翻译服务
+func NewService(translator Translator) *Service {
+	return &Service{translator: translator}
+}
+
+// NewServiceFromConfig 根据配置创建翻译服务，未启用时返回nil
+func NewServiceFromConfig(cfg *config.Config, logger logging.Logger) (*Service, error) {
+	tc := cfg.Translation
+	if !tc.Enabled {
+		return nil, nil
+	}
+
+	switch tc.Provider {
+	case "", "llm":
+		llmName := tc.LLMProvider
+		if llmName == "" {
+			llmName = cfg.LLM.Default
+		}
+		client, err := llm.CreateLLMByName(cfg, llmName, logger)
+		if err != nil {
+			return nil, fmt.Errorf("创建翻译LLM失败: %w", err)
+		}
+		return NewService(NewLLMTranslator(client)), nil
+
+	case "deepl":
+		if tc.APIKey == "" {
+			return nil, fmt.Errorf("deepl翻译需要配置api_key")
+		}
+		return NewService(NewDeepLTranslator(tc.APIKey, tc.BaseURL)), nil
+
+	default:
+		return nil, fmt.Errorf("不支持的翻译提供商: %s", tc.Provider)
+	}
+}
+
+// ToChinese 将用户消息翻译为中文（智能体工作语言）
+func (s *Service) ToChinese(ctx context.Context, text, sourceLang string) (string, error) {
+	return s.translate(ctx, text, sourceLang, LangChinese)
+}
+
+// FromChinese 将中文回复翻译回用户语言
+func (s *Service) FromChinese(ctx context.Context, text, targetLang string) (string, error) {
+	return s.translate(ctx, text, LangChinese, targetLang)
+}
+
+// translate 保护代码块与思考内容后调用提供商翻译
+func (s *Service) translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	if strings.TrimSpace(text) == "" || sourceLang == targetLang {
+		return text, nil
+	}
+
+	masked, segments := protect(text)
+	translated, err := s.translator.Translate(ctx, masked, sourceLang, targetLang)
+	if err != nil {
+		return "", err
+	}
+
+	return restore(translated, segments), nil
+}
+
+// protectedPattern 翻译时需要原样保留的片段：思考标签、围栏代码块、行内代码、链接地址
+var protectedPattern = regexp.MustCompile("(?s)<think>.*?</think>|```.*?```|`[^`\n]+`|https?://[^\\s)\\]>]+")
+
+// placeholderPattern 占位符格式
+var placeholderPattern = regexp.MustCompile(`⟦(\d+)⟧`)
+
+// protect 将需要保留的片段替换为占位符
+func protect(text string) (string, []string) {
+	var segments []string
+	masked := protectedPattern.ReplaceAllStringFunc(text, func(m string) string {
+		segments = append(segments, m)
+		return fmt.Sprintf("⟦%d⟧", len(segments)-1)
+	})
+	return masked, segments
+}
+
+// restore 将占位符还原为原始片段
+func restore(text string, segments []string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+		var idx int
+		if _, err := fmt.Sscanf(m, "⟦%d⟧", &idx); err != nil || idx < 0 || idx >= len(segments) {
+			return m
+		}
+		return segments[idx]
+	})
+}
+
+// DetectLanguage 基于字符分布的轻量语言检测
+func DetectLanguage(text string) string {
+	var han, kana, hangul, cyrillic, latin int
+
+	masked, _ := protect(text)
+	for _, r := range masked {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			latin++
+		}
+	}
+
+	switch {
+	case kana > 0:
+		return LangJapanese
+	case hangul > 0 && hangul >= han:
+		return LangKorean
+	case han > 0 && han*4 >= latin:
+		// 中文字符信息密度高，少量汉字即视为中文
+		return LangChinese
+	case cyrillic > latin:
+		return LangRussian
+	case latin > 0:
+		return LangEnglish
+	default:
+		return LangChinese
+	}
+}
+
+// languageNames 语言代码对应的名称（用于提示词）
+var languageNames = map[string]string{
+	LangChinese:  "简体中文",
+	LangEnglish:  "English",
+	LangJapanese: "日本語",
+	LangKorean:   "한국어",
+	LangRussian:  "Русский",
+}
+
+// languageName 获取语言名称
+func languageName(lang string) string {
+	if name, ok := languageNames[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+type sourceLangKey struct{}
+
+// WithSourceLanguage 在上下文中记录用户消息的原始语言
+func WithSourceLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, sourceLangKey{}, lang)
+}
+
+// SourceLanguage 从上下文获取用户消息的原始语言
+func SourceLanguage(ctx context.Context) string {
+	lang, _ := ctx.Value(sourceLangKey{}).(string)
+	return lang
+}
@@ -0,0 +1,173 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// MaxVoiceBytes 企业微信语音消息的大小上限
+const MaxVoiceBytes = 2 << 20
+
+var (
+	thinkRegex    = regexp.MustCompile(`(?s)<think>.*?(</think>|$)`)
+	codeRegex     = regexp.MustCompile("(?s)```.*?(```|$)")
+	linkRegex     = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	markRegex     = regexp.MustCompile("(?m)^\\s*(#{1,6}|[-*+]|>|\\d+\\.)\\s+|[*_`~|]")
+	spaceRegex    = regexp.MustCompile(`\n{2,}`)
+	sentenceRunes = "。！？；.!?;\n"
+)
+
+// Service 语音合成：调用OpenAI兼容的 /audio/speech 接口，再用ffmpeg转为企业微信支持的AMR
+type Service struct {
+	baseURL  string
+	apiKey   string
+	model    string
+	voice    string
+	format   string
+	ffmpeg   string
+	maxChars int
+	timeout  time.Duration
+	client   *http.Client
+}
+
+// NewServiceFromConfig 根据配置创建语音合成服务，未启用时返回nil
+func NewServiceFromConfig(cfg config.TTSConfig) (*Service, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	ffmpeg := ""
+	if cfg.Format != "amr" {
+		path, err := exec.LookPath(cfg.FFmpeg)
+		if err != nil {
+			return nil, fmt.Errorf("合成格式为%s时需要ffmpeg转码为AMR: %w", cfg.Format, err)
+		}
+		ffmpeg = path
+	}
+
+	return &Service{
+		baseURL:  strings.TrimSuffix(cfg.BaseURL, "/"),
+		apiKey:   cfg.APIKey,
+		model:    cfg.Model,
+		voice:    cfg.Voice,
+		format:   cfg.Format,
+		ffmpeg:   ffmpeg,
+		maxChars: cfg.MaxChars,
+		timeout:  time.Duration(cfg.Timeout) * time.Second,
+		client:   &http.Client{},
+	}, nil
+}
+
+// Speak 将回答转为AMR语音，回答中没有可朗读的内容时返回nil
+func (s *Service) Speak(ctx context.Context, answer string) ([]byte, error) {
+	text := s.Prepare(answer)
+	if text == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	audio, err := s.synthesize(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if s.ffmpeg != "" {
+		if audio, err = s.toAMR(ctx, audio); err != nil {
+			return nil, err
+		}
+	}
+	if len(audio) > MaxVoiceBytes {
+		return nil, fmt.Errorf("语音大小%d字节超过企业微信限制", len(audio))
+	}
+	return audio, nil
+}
+
+// Prepare 提取适合朗读的文本：去掉思考过程、代码块和Markdown标记，超过max_chars时在句末截断
+func (s *Service) Prepare(answer string) string {
+	text := thinkRegex.ReplaceAllString(answer, "")
+	text = codeRegex.ReplaceAllString(text, "（代码略）")
+	text = linkRegex.ReplaceAllString(text, "$1")
+	text = markRegex.ReplaceAllString(text, "")
+	text = strings.TrimSpace(spaceRegex.ReplaceAllString(text, "\n"))
+
+	runes := []rune(text)
+	if len(runes) <= s.maxChars {
+		return text
+	}
+	cut := s.maxChars
+	for i := s.maxChars - 1; i > s.maxChars/2; i-- {
+		if strings.ContainsRune(sentenceRunes, runes[i]) {
+			cut = i + 1
+			break
+		}
+	}
+	return string(runes[:cut]) + "……详细内容请查看文字回复"
+}
+
+// synthesize 调用语音合成接口
+func (s *Service) synthesize(ctx context.Context, text string) ([]byte, error) {
+	payload, err := json.Marshal(map[string]string{
+		"model":           s.model,
+		"input":           text,
+		"voice":           s.voice,
+		"response_format": s.format,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/audio/speech", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("语音合成请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4*MaxVoiceBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("语音合成返回错误: %d, %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// toAMR 用ffmpeg转码为AMR-NB（8kHz单声道，企业微信语音格式）
+func (s *Service) toAMR(ctx context.Context, audio []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.ffmpeg, "-hide_banner", "-loglevel", "error",
+		"-i", "pipe:0", "-ar", "8000", "-ac", "1", "-c:a", "libopencore_amrnb", "-b:a", "12.2k", "-f", "amr", "pipe:1")
+	cmd.Stdin = bytes.NewReader(audio)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg转码失败: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
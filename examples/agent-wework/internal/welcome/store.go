@@ -0,0 +1,51 @@
+package welcome
+
+import (
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/fsutil"
+)
+
+// Store 已欢迎过的会话（JSON文件持久化，重启后不重复欢迎）
+type Store struct {
+	path    string
+	greeted map[string]time.Time // conversationID -> 首次对话时间
+	mutex   sync.Mutex
+}
+
+// NewStore 创建欢迎记录并加载已有数据
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		greeted: make(map[string]time.Time),
+	}
+	if _, err := fsutil.ReadJSON(path, &s.greeted); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// First 判断是否为会话的首次对话，是则记录并持久化（保存失败时撤销记录，下次仍视为首次）
+func (s *Store) First(conversationID string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.greeted[conversationID]; ok {
+		return false, nil
+	}
+	s.greeted[conversationID] = time.Now()
+	if err := fsutil.WriteJSONAtomic(s.path, s.greeted); err != nil {
+		delete(s.greeted, conversationID)
+		return false, err
+	}
+	return true, nil
+}
+
+// Count 已欢迎的会话数量
+func (s *Store) Count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.greeted)
+}
@@ -11,23 +11,13 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/wework"
+	"github.com/deepsage-ai/b0dy/channels/wework"
 )
 
 const (
-	// 企业微信配置（与服务端保持一致）
-	TOKEN   = "9hLM5K4pnxRu8d"
-	AES_KEY = "E2852LABnwUkzMQKciaNNDG2fhOOlQ2kCIwCHNZnrVa"
-	BOT_ID  = "aib2luFCOChzgjguHi58WvVgwjJoeAHgkQo"
-
-	// 服务器地址
-	SERVER_URL = "http://localhost:8889/b0dy/webhook"
-
-	// 固定的用户ID
-	USER_ID = "test-user-001"
-
 	// 颜色定义
 	ColorReset  = "\033[0m"
 	ColorRed    = "\033[31m"
@@ -39,12 +29,14 @@ const (
 	ColorGray   = "\033[90m"
 )
 
-// 简单的消息ID生成器
-var msgCounter = 0
+// settings 当前配置（见 loadClientConfig）
+var settings ClientConfig
+
+// 简单的消息ID生成器（压测模式下并发调用）
+var msgCounter atomic.Int64
 
 func generateMsgID() string {
-	msgCounter++
-	return fmt.Sprintf("msg_%d_%d", time.Now().Unix(), msgCounter)
+	return fmt.Sprintf("msg_%d_%d", time.Now().Unix(), msgCounter.Add(1))
 }
 
 // 计算签名
@@ -65,26 +57,54 @@ func calculateSignature(token, timestamp, nonce, encrypt string) string {
 }
 
 func main() {
+	var err error
+	if settings, err = loadClientConfig(os.Args[1:]); err != nil {
+		fmt.Printf("%s❌ %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(exitError)
+	}
+
+	if settings.Script != "" {
+		os.Exit(runScript(settings.Script))
+	}
+	if settings.Users > 0 {
+		os.Exit(runLoadTest())
+	}
+	if settings.CryptoCheck > 0 {
+		seed := settings.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		os.Exit(runCryptoCheck(settings.CryptoCheck, seed))
+	}
+
 	fmt.Printf("%s🤖 企业微信智能助手测试客户端%s\n", ColorCyan, ColorReset)
 	fmt.Println("=" + strings.Repeat("=", 60))
-	fmt.Printf("服务器地址: %s%s%s\n", ColorYellow, SERVER_URL, ColorReset)
-	fmt.Printf("用户ID: %s%s%s\n", ColorYellow, USER_ID, ColorReset)
-	fmt.Printf("机器人ID: %s%s%s\n", ColorYellow, BOT_ID, ColorReset)
+	fmt.Printf("服务器地址: %s%s%s\n", ColorYellow, settings.WebhookURL, ColorReset)
+	fmt.Printf("用户ID: %s%s%s\n", ColorYellow, strings.Join(settings.Senders, ", "), ColorReset)
+	if settings.GroupID != "" {
+		fmt.Printf("群聊ID: %s%s%s（自动@%s）\n", ColorYellow, settings.GroupID, ColorReset, settings.Mention)
+	}
+	fmt.Printf("机器人ID: %s%s%s\n", ColorYellow, settings.BotID, ColorReset)
 	fmt.Println("=" + strings.Repeat("=", 60))
 	fmt.Printf("%s提示: 输入消息并按回车发送，输入 'exit' 退出%s\n", ColorGray, ColorReset)
+	fmt.Printf("%s提示: /image <图片> 发送图片，/mixed <图片[,图片]> <文本> 发送图文混排，/file <文件> 发送文件%s\n", ColorGray, ColorReset)
+	if len(settings.Senders) > 1 {
+		fmt.Printf("%s提示: 输入 “用户ID: 消息” 切换发送者%s\n", ColorGray, ColorReset)
+	}
 	fmt.Println()
 
 	// 初始化加密器
-	wxcpt, err := wework.NewWXBizJsonMsgCrypt(TOKEN, AES_KEY, "") // 智能机器人场景receiverId使用空字符串
+	wxcpt, err := wework.NewWXBizJsonMsgCrypt(settings.Token, settings.AESKey, "") // 智能机器人场景receiverId使用空字符串
 	if err != nil {
 		fmt.Printf("%s❌ 初始化加密器失败: %v%s\n", ColorRed, err, ColorReset)
 		return
 	}
 
 	scanner := bufio.NewScanner(os.Stdin)
+	sender := defaultSender()
 
 	for {
-		fmt.Printf("%s你: %s", ColorGreen, ColorReset)
+		fmt.Printf("%s%s: %s", ColorGreen, sender.UserID, ColorReset)
 		if !scanner.Scan() {
 			break
 		}
@@ -95,6 +115,9 @@ func main() {
 			break
 		}
 
+		if name, rest, ok := parseSenderPrefix(input); ok {
+			sender.UserID, input = name, rest
+		}
 		if input == "" {
 			continue
 		}
@@ -102,8 +125,20 @@ func main() {
 		// 记录发送时间
 		startTime := time.Now()
 
-		// 发送消息并获取响应
-		response, streamID, err := sendMessage(wxcpt, input)
+		// 发送消息并获取响应（/image、/mixed 命令发送本地图片，/file 发送文件）
+		var response, streamID string
+		if path, ok := strings.CutPrefix(input, "/file "); ok {
+			response, streamID, err = sendFile(wxcpt, sender, strings.TrimSpace(path))
+		} else {
+			var images []string
+			if text, paths, ok := parseMediaCommand(input); ok {
+				input, images = text, paths
+			}
+			if input != "" {
+				input = withMention(sender, input)
+			}
+			response, streamID, err = sendMessage(wxcpt, sender, input, images...)
+		}
 		if err != nil {
 			fmt.Printf("%s❌ 错误: %v%s\n", ColorRed, err, ColorReset)
 			continue
@@ -111,7 +146,7 @@ func main() {
 
 		// 如果返回了流式ID，处理流式响应
 		if streamID != "" {
-			response, err = handleStreamResponse(wxcpt, streamID, startTime)
+			response, err = handleStreamResponse(wxcpt, sender, streamID, startTime)
 			if err != nil {
 				fmt.Printf("%s❌ 流式处理错误: %v%s\n", ColorRed, err, ColorReset)
 			}
@@ -125,24 +160,26 @@ func main() {
 	}
 }
 
-// sendMessage 发送消息到服务器
-func sendMessage(wxcpt *wework.WXBizJsonMsgCrypt, content string) (string, string, error) {
+// sendMessage 以指定发送者身份发送消息到服务器，附带图片时发送图片或图文混排消息
+func sendMessage(wxcpt *wework.WXBizJsonMsgCrypt, sender Sender, content string, images ...string) (string, string, error) {
 	// 构造消息
 	msg := wework.IncomingMessage{
-		BaseMessage: wework.BaseMessage{
-			MsgID:    generateMsgID(),
-			AIBotID:  BOT_ID,
-			ChatType: wework.ChatTypeSingle,
-			From: wework.From{
-				UserID: USER_ID,
-			},
-			MsgType: wework.MsgTypeText,
-		},
+		BaseMessage: sender.baseMessage(wework.MsgTypeText),
 		Text: &wework.TextContent{
 			Content: content,
 		},
 	}
+	if len(images) > 0 {
+		var err error
+		if msg, err = mediaMessage(wxcpt, sender, content, images); err != nil {
+			return "", "", err
+		}
+	}
+	return postMessage(wxcpt, msg)
+}
 
+// postMessage 加密消息并发送到服务器，返回文本回复或流式ID
+func postMessage(wxcpt *wework.WXBizJsonMsgCrypt, msg wework.IncomingMessage) (string, string, error) {
 	// 序列化消息
 	msgData, err := json.Marshal(msg)
 	if err != nil {
@@ -176,15 +213,17 @@ func sendMessage(wxcpt *wework.WXBizJsonMsgCrypt, content string) (string, strin
 	msgSignature := encryptedData["msgsignature"]
 
 	// 调试输出
-	fmt.Printf("%s[调试] 原始加密JSON:%s\n%s\n", ColorGray, ColorReset, encryptedJSON)
-	fmt.Printf("%s[调试] 紧凑JSON:%s\n%s\n", ColorGray, ColorReset, string(compactJSON))
-	fmt.Printf("%s[调试] timestamp=%s, nonce=%s, msg_signature=%s%s\n",
-		ColorGray, timestamp, nonce, msgSignature, ColorReset)
+	if settings.Debug {
+		fmt.Printf("%s[调试] 原始加密JSON:%s\n%s\n", ColorGray, ColorReset, encryptedJSON)
+		fmt.Printf("%s[调试] 紧凑JSON:%s\n%s\n", ColorGray, ColorReset, string(compactJSON))
+		fmt.Printf("%s[调试] timestamp=%s, nonce=%s, msg_signature=%s%s\n",
+			ColorGray, timestamp, nonce, msgSignature, ColorReset)
+	}
 
 	// 发送HTTP请求，msg_signature从加密结果中获取
 	// 注意：需要对msg_signature进行URL编码
 	requestURL := fmt.Sprintf("%s?timestamp=%s&nonce=%s&msg_signature=%s",
-		SERVER_URL, timestamp, nonce, url.QueryEscape(msgSignature))
+		settings.WebhookURL, timestamp, nonce, url.QueryEscape(msgSignature))
 
 	// 创建HTTP客户端，设置超时
 	client := &http.Client{
@@ -243,58 +282,76 @@ func sendMessage(wxcpt *wework.WXBizJsonMsgCrypt, content string) (string, strin
 }
 
 // handleStreamResponse 处理流式响应
-func handleStreamResponse(wxcpt *wework.WXBizJsonMsgCrypt, streamID string, startTime time.Time) (string, error) {
+func handleStreamResponse(wxcpt *wework.WXBizJsonMsgCrypt, sender Sender, streamID string, startTime time.Time) (string, error) {
 	fmt.Printf("%s🤖 小兴: %s", ColorPurple, ColorReset)
 
+	// 打印机效果：只打印新增的内容
+	content, err := pollStream(wxcpt, sender, streamID, defaultStreamTimeout, func(delta string) {
+		fmt.Print(delta)
+	})
+	if err != nil {
+		fmt.Println() // 换行
+		return content, err
+	}
+
+	elapsed := time.Since(startTime)
+	fmt.Printf(" %s(耗时: %.2fs)%s\n", ColorGray, elapsed.Seconds(), ColorReset)
+	return content, nil
+}
+
+// defaultStreamTimeout 等待流式回复完成的默认超时
+const defaultStreamTimeout = 30 * time.Second
+
+// pollStream 轮询流式刷新直到回复完成，onDelta 接收每次新增的内容（可为nil）
+func pollStream(wxcpt *wework.WXBizJsonMsgCrypt, sender Sender, streamID string, timeout time.Duration, onDelta func(string)) (string, error) {
 	var fullContent string
-	var displayedLen int
-	maxRetries := 60 // 最多重试60次（约60秒）
+	failures := 0
+	deadline := time.Now().Add(timeout)
 
-	for i := 0; i < maxRetries; i++ {
+	for time.Now().Before(deadline) {
 		time.Sleep(500 * time.Millisecond) // 每0.5秒刷新一次，更快的响应
 
 		// 发送刷新请求
-		content, finished, err := sendStreamRefresh(wxcpt, streamID)
+		content, finished, err := sendStreamRefresh(wxcpt, sender, streamID)
 		if err != nil {
 			// 如果是暂时性错误，继续重试
-			if i < 5 {
+			if failures++; failures <= 5 {
 				continue
 			}
 			return fullContent, fmt.Errorf("刷新失败: %w", err)
 		}
 
-		fullContent = content // 累积模式，每次都是完整内容
-
-		// 打印机效果：只打印新增的内容
-		if len(fullContent) > displayedLen {
-			newContent := fullContent[displayedLen:]
-			fmt.Print(newContent) // 直接打印新内容，不换行
-			displayedLen = len(fullContent)
+		// 累积模式，每次都是完整内容
+		if len(content) > len(fullContent) && onDelta != nil {
+			onDelta(content[len(fullContent):])
 		}
+		fullContent = content
 
 		if finished {
-			elapsed := time.Since(startTime)
-			fmt.Printf(" %s(耗时: %.2fs)%s\n", ColorGray, elapsed.Seconds(), ColorReset)
 			return fullContent, nil
 		}
 	}
 
-	fmt.Println() // 换行
 	return fullContent, fmt.Errorf("流式响应超时")
 }
 
+// ask 发送消息并等待完整回复（非流式回复直接返回）
+func ask(wxcpt *wework.WXBizJsonMsgCrypt, sender Sender, content string, timeout time.Duration, images ...string) (string, error) {
+	response, streamID, err := sendMessage(wxcpt, sender, content, images...)
+	if err != nil {
+		return "", err
+	}
+	if streamID == "" {
+		return response, nil
+	}
+	return pollStream(wxcpt, sender, streamID, timeout, nil)
+}
+
 // sendStreamRefresh 发送流式刷新请求
-func sendStreamRefresh(wxcpt *wework.WXBizJsonMsgCrypt, streamID string) (string, bool, error) {
+func sendStreamRefresh(wxcpt *wework.WXBizJsonMsgCrypt, sender Sender, streamID string) (string, bool, error) {
 	// 构造流式刷新消息
 	msg := wework.IncomingMessage{
-		BaseMessage: wework.BaseMessage{
-			MsgID:   generateMsgID(),
-			AIBotID: BOT_ID,
-			From: wework.From{
-				UserID: USER_ID,
-			},
-			MsgType: wework.MsgTypeStream,
-		},
+		BaseMessage: sender.baseMessage(wework.MsgTypeStream),
 		Stream: &wework.StreamContent{
 			ID: streamID,
 		},
@@ -334,7 +391,7 @@ func sendStreamRefresh(wxcpt *wework.WXBizJsonMsgCrypt, streamID string) (string
 
 	// 发送请求
 	requestURL := fmt.Sprintf("%s?timestamp=%s&nonce=%s&msg_signature=%s",
-		SERVER_URL, timestamp, nonce, url.QueryEscape(msgSignature))
+		settings.WebhookURL, timestamp, nonce, url.QueryEscape(msgSignature))
 
 	client := &http.Client{
 		Timeout: 10 * time.Second,
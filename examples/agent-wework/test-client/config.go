@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/internal/config"
+)
+
+// 内置的本地联调默认值（服务端未配置凭证时，将 WEWORK_* 环境变量设置为这些值）
+const (
+	defaultToken      = "9hLM5K4pnxRu8d"
+	defaultAESKey     = "E2852LABnwUkzMQKciaNNDG2fhOOlQ2kCIwCHNZnrVa"
+	defaultBotID      = "aib2luFCOChzgjguHi58WvVgwjJoeAHgkQo"
+	defaultWebhookURL = "http://localhost:8889" + config.DefaultRoutePrefix + config.DefaultWebhookPath
+	defaultUserID     = "test-user-001"
+)
+
+// ClientConfig 测试客户端配置
+type ClientConfig struct {
+	Token      string
+	AESKey     string
+	BotID      string
+	WebhookURL string
+	UserID     string
+
+	GroupID string   // 群聊ChatID，非空时以群聊身份发送（chattype=group）
+	Senders []string // 模拟的发送者（第一个为默认），未指定时为 UserID
+	Mention string   // 群聊中自动@的机器人名称
+
+	MediaAddr string // 本地图片服务监听地址
+	MediaURL  string // 服务端访问本地图片服务的地址（服务端不在本机时设置）
+
+	Debug  bool   // 打印加密请求等调试信息
+	Script string // 场景脚本路径，非空时以脚本模式运行
+
+	Users    int           // 压测模拟用户数，>0 时以压测模式运行
+	RPS      float64       // 压测目标请求速率（每秒）
+	Duration time.Duration // 压测持续时间
+	Message  string        // 压测发送的消息
+
+	CryptoCheck int   // 加解密自检的每项次数，>0 时以自检模式运行（不连接服务端）
+	Seed        int64 // 加解密自检的随机种子（0为按当前时间生成）
+}
+
+// loadClientConfig 按 命令行参数 > 服务端配置文件 > 环境变量 > 内置默认值 的优先级解析配置
+//
+// 指定 -config 时复用服务端的配置文件（同一份Schema和 ${ENV} / 密钥引用解析，凭证通常仍来自
+// WEWORK_* 环境变量），多机器人配置用 -bot 选择目标机器人。
+func loadClientConfig(args []string) (ClientConfig, error) {
+	fs := flag.NewFlagSet("test-client", flag.ExitOnError)
+	configPath := fs.String("config", "", "服务端配置文件路径（JSON/YAML），从中读取凭证、端口和Webhook路由")
+	botName := fs.String("bot", "", "多机器人配置时的目标机器人名称（默认第一个）")
+	webhookURL := fs.String("url", "", "Webhook完整地址（环境变量 AIBODY_WEBHOOK_URL）")
+	token := fs.String("token", "", "企业微信Token（环境变量 WEWORK_TOKEN）")
+	aesKey := fs.String("aes-key", "", "企业微信EncodingAESKey（环境变量 WEWORK_AES_KEY）")
+	botID := fs.String("bot-id", "", "机器人ID（环境变量 WEWORK_BOT_ID）")
+	userID := fs.String("user", "", "模拟的用户ID（环境变量 AIBODY_TEST_USER）")
+	groupID := fs.String("group", "", "群聊ChatID，指定后以群聊消息发送")
+	senders := fs.String("senders", "", "群聊中模拟的多个发送者，逗号分隔（交互时输入 “用户ID: 消息” 切换）")
+	mention := fs.String("mention", "小兴", "群聊消息自动@的机器人名称（为空则不添加）")
+	mediaAddr := fs.String("media-addr", "127.0.0.1:0", "本地图片服务监听地址（发送图片时启动）")
+	mediaURL := fs.String("media-url", "", "服务端访问本地图片服务的地址，如 http://10.0.0.5:9900（默认由监听地址推导）")
+	debug := fs.Bool("debug", true, "打印加密请求等调试信息（脚本模式下始终关闭）")
+	script := fs.String("script", "", "场景脚本（YAML），逐条发送消息并校验回复，失败时以非零状态码退出")
+	users := fs.Int("users", 0, "压测模式：模拟的并发用户数（各自独立的UserID和会话）")
+	rps := fs.Float64("rps", 1, "压测模式：目标请求速率（每秒）")
+	duration := fs.Duration("duration", time.Minute, "压测模式：持续时间")
+	message := fs.String("message", "你好，请简单介绍一下你自己", "压测模式：发送的消息")
+	cryptoCheck := fs.Int("crypto-check", 0, "加解密自检模式：每项检查的次数（往返、验签、篡改、畸形输入、PKCS7），不连接服务端")
+	seed := fs.Int64("seed", 0, "加解密自检的随机种子，用于复现失败（默认按当前时间生成）")
+	fs.Parse(args)
+
+	cfg := ClientConfig{
+		Token:      defaultToken,
+		AESKey:     defaultAESKey,
+		BotID:      defaultBotID,
+		WebhookURL: defaultWebhookURL,
+		UserID:     defaultUserID,
+		GroupID:    *groupID,
+		Mention:    *mention,
+		MediaAddr:  *mediaAddr,
+		MediaURL:   *mediaURL,
+		Debug:      *debug,
+		Script:     *script,
+		Users:      *users,
+		RPS:        *rps,
+		Duration:   *duration,
+		Message:    *message,
+
+		CryptoCheck: *cryptoCheck,
+		Seed:        *seed,
+	}
+
+	override(&cfg.Token, os.Getenv("WEWORK_TOKEN"))
+	override(&cfg.AESKey, os.Getenv("WEWORK_AES_KEY"))
+	override(&cfg.BotID, os.Getenv("WEWORK_BOT_ID"))
+	override(&cfg.WebhookURL, os.Getenv("AIBODY_WEBHOOK_URL"))
+	override(&cfg.UserID, os.Getenv("AIBODY_TEST_USER"))
+
+	if *configPath != "" {
+		if err := applyServerConfig(&cfg, *configPath, *botName); err != nil {
+			return cfg, err
+		}
+	}
+
+	override(&cfg.Token, *token)
+	override(&cfg.AESKey, *aesKey)
+	override(&cfg.BotID, *botID)
+	override(&cfg.WebhookURL, *webhookURL)
+	override(&cfg.UserID, *userID)
+
+	for _, s := range strings.Split(*senders, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			cfg.Senders = append(cfg.Senders, s)
+		}
+	}
+	if len(cfg.Senders) == 0 {
+		cfg.Senders = []string{cfg.UserID}
+	}
+
+	if cfg.Users > 0 && (cfg.RPS <= 0 || cfg.Duration <= 0) {
+		return cfg, fmt.Errorf("压测模式下 -rps 和 -duration 必须大于0")
+	}
+	if len(cfg.AESKey) != 43 {
+		return cfg, fmt.Errorf("EncodingAESKey长度必须为43位，当前长度: %d", len(cfg.AESKey))
+	}
+	return cfg, nil
+}
+
+// applyServerConfig 从服务端配置文件读取目标机器人的凭证和Webhook地址
+func applyServerConfig(cfg *ClientConfig, path, botName string) error {
+	serverCfg, err := config.LoadConfigFromFile(path)
+	if err != nil {
+		return fmt.Errorf("加载服务端配置失败: %w", err)
+	}
+
+	bots := serverCfg.BotConfigs()
+	target := bots[0]
+	if botName != "" {
+		found := false
+		for _, b := range bots {
+			if b.Name == botName {
+				target, found = b, true
+			}
+		}
+		if !found {
+			return fmt.Errorf("配置中不存在机器人: %s", botName)
+		}
+	}
+
+	scheme := "http"
+	if serverCfg.Server.TLS.Enabled() {
+		scheme = "https"
+	}
+	cfg.Token = target.WeWork.Token
+	cfg.AESKey = target.WeWork.AESKey
+	cfg.BotID = target.WeWork.BotID
+	cfg.WebhookURL = fmt.Sprintf("%s://localhost:%s%s", scheme, serverCfg.Server.Port, target.Path)
+	return nil
+}
+
+// override 非空时覆盖
+func override(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	mrand "math/rand"
+	"strconv"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// cryptoCase 加解密自检中的一类检查
+type cryptoCase struct {
+	name   string
+	run    func(r *mrand.Rand) error
+	failed int
+	first  error // 第一次失败的原因
+}
+
+// runCryptoCheck 加解密自检模式：不连接服务端，对 channels/wework 的加解密做往返、篡改和畸形输入检查
+//
+// 每类检查运行iterations次（随机种子固定为seed，失败时可用同一种子复现），
+// 任何一次panic、往返结果不一致或篡改后仍解密出原文都计为失败。
+func runCryptoCheck(iterations int, seed int64) int {
+	crypts := make([]*wework.WXBizJsonMsgCrypt, 0, 4)
+	for _, c := range []struct {
+		receiveID string
+		format    wework.MsgFormat
+	}{
+		{"", wework.MsgFormatJSON},
+		{settings.BotID, wework.MsgFormatJSON},
+		{"wwcorp" + strconv.Itoa(int(seed)), wework.MsgFormatXML},
+	} {
+		crypt, err := wework.NewWXBizMsgCrypt(settings.Token, settings.AESKey, c.receiveID, c.format)
+		if err != nil {
+			fmt.Printf("%s❌ 初始化加密器失败: %v%s\n", ColorRed, err, ColorReset)
+			return exitError
+		}
+		crypts = append(crypts, crypt)
+	}
+	pick := func(r *mrand.Rand) *wework.WXBizJsonMsgCrypt { return crypts[r.Intn(len(crypts))] }
+
+	cases := []*cryptoCase{
+		{name: "加密后解密还原原文", run: func(r *mrand.Rand) error {
+			return checkRoundTrip(pick(r), randomText(r))
+		}},
+		{name: "签名错误被拒绝", run: func(r *mrand.Rand) error {
+			return checkBadSignature(pick(r), randomText(r))
+		}},
+		{name: "篡改密文不返回原文", run: func(r *mrand.Rand) error {
+			return checkTampered(r, pick(r), randomText(r))
+		}},
+		{name: "畸形密文不panic", run: func(r *mrand.Rand) error {
+			return checkMalformed(r, pick(r))
+		}},
+		{name: "PKCS7填充往返与校验", run: func(r *mrand.Rand) error {
+			return checkPKCS7(r)
+		}},
+	}
+
+	fmt.Printf("%s🔐 加解密自检（每项 %d 次，种子 %d）%s\n", ColorCyan, iterations, seed, ColorReset)
+	failed := 0
+	for _, c := range cases {
+		r := mrand.New(mrand.NewSource(seed))
+		for i := 0; i < iterations; i++ {
+			if err := safeRun(c.run, r); err != nil {
+				c.failed++
+				if c.first == nil {
+					c.first = fmt.Errorf("第%d次: %w", i+1, err)
+				}
+			}
+		}
+		if c.failed > 0 {
+			failed++
+			fmt.Printf("   %s❌ %s: %d/%d 次失败%s\n      %v\n", ColorRed, c.name, c.failed, iterations, ColorReset, c.first)
+			continue
+		}
+		fmt.Printf("   %s✅ %s%s\n", ColorGreen, c.name, ColorReset)
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%s❌ %d/%d 项检查未通过%s\n", ColorRed, failed, len(cases), ColorReset)
+		return exitFailed
+	}
+	fmt.Printf("\n%s✅ 全部 %d 项检查通过%s\n", ColorGreen, len(cases), ColorReset)
+	return exitOK
+}
+
+// safeRun 执行一次检查，panic视为失败
+func safeRun(run func(r *mrand.Rand) error, r *mrand.Rand) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return run(r)
+}
+
+// randomText 随机长度（0~4096字节）的任意字节内容
+func randomText(r *mrand.Rand) string {
+	b := make([]byte, r.Intn(4097))
+	r.Read(b)
+	return string(b)
+}
+
+// encryptForCallback 按回调流程加密：返回密文和对应的签名参数
+func encryptForCallback(crypt *wework.WXBizJsonMsgCrypt, msg string) (body, encrypt, timestamp, nonce string, err error) {
+	timestamp, nonce = "1700000000", "cryptocheck"
+	if _, body, err = crypt.EncryptMsg(msg, nonce, &timestamp); err != nil {
+		return "", "", "", "", fmt.Errorf("加密失败: %w", err)
+	}
+	if crypt.Format == wework.MsgFormatXML {
+		_, encrypt, err = (&wework.XMLHelper{}).Extract(body)
+	} else {
+		_, encrypt, err = (&wework.JsonHelper{}).Extract(body)
+	}
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("解析加密结果失败: %w", err)
+	}
+	return body, encrypt, timestamp, nonce, nil
+}
+
+// wrapEncrypt 按实例的回调格式包装密文
+func wrapEncrypt(crypt *wework.WXBizJsonMsgCrypt, encrypt, signature, timestamp, nonce string) string {
+	if crypt.Format == wework.MsgFormatXML {
+		return (&wework.XMLHelper{}).Generate(encrypt, signature, timestamp, nonce)
+	}
+	return (&wework.JsonHelper{}).Generate(encrypt, signature, timestamp, nonce)
+}
+
+// checkRoundTrip 加密后按回调流程验签解密，结果必须与原文一致
+func checkRoundTrip(crypt *wework.WXBizJsonMsgCrypt, msg string) error {
+	body, encrypt, timestamp, nonce, err := encryptForCallback(crypt, msg)
+	if err != nil {
+		return err
+	}
+	signature := calculateSignature(crypt.Token, timestamp, nonce, encrypt)
+	ret, plain, err := crypt.DecryptMsg(body, signature, timestamp, nonce)
+	if ret != wework.WXBizMsgCrypt_OK {
+		return fmt.Errorf("解密失败（错误码%d）: %v", ret, err)
+	}
+	if plain != msg {
+		return fmt.Errorf("解密结果与原文不一致（%d字节 → %d字节）", len(msg), len(plain))
+	}
+	return nil
+}
+
+// checkBadSignature 签名改动一位后必须返回签名错误
+func checkBadSignature(crypt *wework.WXBizJsonMsgCrypt, msg string) error {
+	body, encrypt, timestamp, nonce, err := encryptForCallback(crypt, msg)
+	if err != nil {
+		return err
+	}
+	signature := []byte(calculateSignature(crypt.Token, timestamp, nonce, encrypt))
+	if signature[0] == '0' {
+		signature[0] = '1'
+	} else {
+		signature[0] = '0'
+	}
+	if ret, _, _ := crypt.DecryptMsg(body, string(signature), timestamp, nonce); ret != wework.WXBizMsgCrypt_ValidateSignature_Error {
+		return fmt.Errorf("错误的签名返回错误码%d", ret)
+	}
+	return nil
+}
+
+// checkTampered 篡改密文（截断、翻转一位、追加或删除块）并重新签名后，不得解密出原文
+func checkTampered(r *mrand.Rand, crypt *wework.WXBizJsonMsgCrypt, msg string) error {
+	_, encrypt, timestamp, nonce, err := encryptForCallback(crypt, msg)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypt)
+	if err != nil {
+		return fmt.Errorf("密文不是合法的Base64: %w", err)
+	}
+
+	tampered := append([]byte(nil), ciphertext...)
+	var how string
+	switch r.Intn(4) {
+	case 0:
+		how = "截断"
+		tampered = tampered[:r.Intn(len(tampered))]
+	case 1:
+		how = "翻转一位"
+		tampered[r.Intn(len(tampered))] ^= 1 << r.Intn(8)
+	case 2:
+		how = "追加一块"
+		block := make([]byte, 16)
+		rand.Read(block)
+		tampered = append(tampered, block...)
+	default:
+		how = "删除首块"
+		tampered = tampered[16:]
+	}
+	if bytes.Equal(tampered, ciphertext) {
+		return nil
+	}
+
+	forged := base64.StdEncoding.EncodeToString(tampered)
+	signature := calculateSignature(crypt.Token, timestamp, nonce, forged)
+	ret, plain, _ := crypt.DecryptMsg(wrapEncrypt(crypt, forged, signature, timestamp, nonce), signature, timestamp, nonce)
+	if ret == wework.WXBizMsgCrypt_OK && plain == msg {
+		return fmt.Errorf("%s后仍解密出原文", how)
+	}
+	return nil
+}
+
+// checkMalformed 随机字节、非法Base64、超长长度字段等畸形输入只能返回错误，不能panic
+func checkMalformed(r *mrand.Rand, crypt *wework.WXBizJsonMsgCrypt) error {
+	raw := make([]byte, r.Intn(128))
+	r.Read(raw)
+	inputs := []string{
+		string(raw),
+		base64.StdEncoding.EncodeToString(raw),
+		base64.StdEncoding.EncodeToString(raw[:len(raw)/16*16]),
+	}
+	timestamp, nonce := "1700000000", "cryptocheck"
+	for _, encrypt := range inputs {
+		signature := calculateSignature(crypt.Token, timestamp, nonce, encrypt)
+		crypt.DecryptMsg(wrapEncrypt(crypt, encrypt, signature, timestamp, nonce), signature, timestamp, nonce)
+		crypt.VerifyURL(signature, timestamp, nonce, encrypt)
+	}
+	crypt.DecryptMsg(string(raw), "", timestamp, nonce)
+	crypt.DecryptMedia(raw)
+	return nil
+}
+
+// checkPKCS7 填充后去除必须还原，随机数据的校验只能返回错误不能panic
+func checkPKCS7(r *mrand.Rand) error {
+	pkcs7 := wework.NewPKCS7Encoder()
+	data := make([]byte, r.Intn(200))
+	r.Read(data)
+
+	padded := pkcs7.Encode(append([]byte(nil), data...))
+	if len(padded)%pkcs7.BlockSize != 0 {
+		return fmt.Errorf("填充后长度%d不是%d的整数倍", len(padded), pkcs7.BlockSize)
+	}
+	unpadded, err := pkcs7.Unpad(padded)
+	if err != nil {
+		return fmt.Errorf("去除合法填充失败: %w", err)
+	}
+	if !bytes.Equal(unpadded, data) {
+		return fmt.Errorf("去除填充后与原数据不一致")
+	}
+
+	pkcs7.Unpad(data)
+	pkcs7.Decode(data)
+	return nil
+}
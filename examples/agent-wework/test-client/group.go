@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// Sender 模拟的消息发送者，ChatID非空时以群聊身份发送
+type Sender struct {
+	UserID string
+	ChatID string
+}
+
+// IsGroup 是否为群聊
+func (s Sender) IsGroup() bool {
+	return s.ChatID != ""
+}
+
+// baseMessage 构造该发送者的消息头（群聊消息携带chatid和chattype=group）
+func (s Sender) baseMessage(msgType string) wework.BaseMessage {
+	chatType := wework.ChatTypeSingle
+	if s.IsGroup() {
+		chatType = wework.ChatTypeGroup
+	}
+	return wework.BaseMessage{
+		MsgID:    generateMsgID(),
+		AIBotID:  settings.BotID,
+		ChatID:   s.ChatID,
+		ChatType: chatType,
+		From: wework.From{
+			UserID: s.UserID,
+		},
+		MsgType: msgType,
+	}
+}
+
+// String 用于提示符和日志
+func (s Sender) String() string {
+	if s.IsGroup() {
+		return s.UserID + "@" + s.ChatID
+	}
+	return s.UserID
+}
+
+// defaultSender 配置的第一个发送者
+func defaultSender() Sender {
+	return Sender{UserID: settings.Senders[0], ChatID: settings.GroupID}
+}
+
+// withMention 群聊消息自动加上 @机器人（企业微信群里只有@机器人的消息才会回调）
+//
+// 消息中已包含@时原样发送，便于测试@其他成员等情况；-mention 为空时不添加。
+func withMention(sender Sender, content string) string {
+	if !sender.IsGroup() || settings.Mention == "" || strings.Contains(content, "@") {
+		return content
+	}
+	return "@" + settings.Mention + " " + content
+}
+
+// parseSenderPrefix 解析交互输入中的 “发送者: 内容” 前缀，发送者须在 -senders 列表中
+func parseSenderPrefix(input string) (string, string, bool) {
+	idx := strings.IndexAny(input, ":：")
+	if idx <= 0 {
+		return "", input, false
+	}
+	name := strings.TrimSpace(input[:idx])
+	for _, s := range settings.Senders {
+		if s == name {
+			_, size := utf8.DecodeRuneInString(input[idx:])
+			return name, strings.TrimSpace(input[idx+size:]), true
+		}
+	}
+	return "", input, false
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// loadResult 单次请求的压测结果
+type loadResult struct {
+	latency time.Duration // 发送到流式回复完成的耗时
+	err     error
+}
+
+// loadStats 压测统计
+type loadStats struct {
+	mu      sync.Mutex
+	results []loadResult
+	dropped atomic.Int64 // 所有用户都在等待回复，未能按速率发出的请求
+}
+
+func (s *loadStats) add(r loadResult) {
+	s.mu.Lock()
+	s.results = append(s.results, r)
+	s.mu.Unlock()
+}
+
+// snapshot 当前完成数和失败数
+func (s *loadStats) snapshot() (done, failed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.results {
+		if r.err != nil {
+			failed++
+		}
+	}
+	return len(s.results), failed
+}
+
+// runLoadTest 压测模式：N个模拟用户按总速率R发送消息，返回进程退出码
+//
+// 每个用户同一时刻只有一条消息在处理（与真实用户一致），速率令牌在所有用户都忙时丢弃并计入
+// “未发出”，说明服务端吞吐已跟不上目标速率。
+func runLoadTest() int {
+	settings.Debug = false
+	wxcpt, err := wework.NewWXBizJsonMsgCrypt(settings.Token, settings.AESKey, "")
+	if err != nil {
+		fmt.Printf("%s❌ 初始化加密器失败: %v%s\n", ColorRed, err, ColorReset)
+		return exitError
+	}
+
+	fmt.Printf("%s🔥 压测: %d个用户, 目标 %.2f 请求/秒, 持续 %s%s\n",
+		ColorCyan, settings.Users, settings.RPS, settings.Duration, ColorReset)
+	fmt.Printf("%s服务器地址: %s%s\n", ColorGray, settings.WebhookURL, ColorReset)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, settings.Duration)
+	defer cancel()
+
+	stats := &loadStats{}
+	tokens := make(chan struct{})
+	start := time.Now()
+
+	// 按目标速率发放令牌
+	go func() {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / settings.RPS))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				close(tokens)
+				return
+			case <-ticker.C:
+				select {
+				case tokens <- struct{}{}:
+				default:
+					stats.dropped.Add(1)
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= settings.Users; i++ {
+		sender := Sender{UserID: fmt.Sprintf("%s-load-%03d", settings.UserID, i)}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range tokens {
+				begin := time.Now()
+				_, err := ask(wxcpt, sender, settings.Message, defaultStreamTimeout)
+				stats.add(loadResult{latency: time.Since(begin), err: err})
+			}
+		}()
+	}
+
+	// 定期输出进度
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				completed, failed := stats.snapshot()
+				fmt.Printf("%s⏱️  %4.0fs 完成 %d, 失败 %d, 未发出 %d%s\n",
+					ColorGray, time.Since(start).Seconds(), completed, failed, stats.dropped.Load(), ColorReset)
+			}
+		}
+	}()
+
+	wg.Wait() // 到期后不再发送，等待进行中的请求完成
+	close(done)
+
+	return printLoadReport(stats, time.Since(start))
+}
+
+// printLoadReport 输出压测汇总报告，存在失败请求时返回 exitFailed
+func printLoadReport(stats *loadStats, elapsed time.Duration) int {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	var latencies []time.Duration
+	errorKinds := make(map[string]int)
+	for _, r := range stats.results {
+		if r.err != nil {
+			errorKinds[errorKind(r.err)]++
+			continue
+		}
+		latencies = append(latencies, r.latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	total := len(stats.results)
+	failed := total - len(latencies)
+
+	fmt.Println(strings.Repeat("=", 61))
+	fmt.Printf("📊 压测报告（%d个用户, 耗时 %.1fs）\n", settings.Users, elapsed.Seconds())
+	fmt.Printf("请求: %d, 成功: %d, 失败: %d, 未发出: %d\n", total, len(latencies), failed, stats.dropped.Load())
+	if total > 0 {
+		fmt.Printf("错误率: %.2f%%, 实际吞吐: %.2f 请求/秒\n",
+			float64(failed)*100/float64(total), float64(total)/elapsed.Seconds())
+	}
+	if len(latencies) > 0 {
+		fmt.Printf("完成耗时: p50=%.2fs p95=%.2fs p99=%.2fs max=%.2fs\n",
+			percentile(latencies, 0.50).Seconds(), percentile(latencies, 0.95).Seconds(),
+			percentile(latencies, 0.99).Seconds(), latencies[len(latencies)-1].Seconds())
+	}
+	if len(errorKinds) > 0 {
+		fmt.Println("错误分布:")
+		kinds := make([]string, 0, len(errorKinds))
+		for kind := range errorKinds {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Printf("   - %s: %d\n", kind, errorKinds[kind])
+		}
+	}
+
+	if failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// percentile 计算已排序耗时的分位数（最近秩法）
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// errorKind 错误归类（去掉URL等每次不同的细节）
+func errorKind(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "流式响应超时"):
+		return "流式响应超时"
+	case strings.HasPrefix(msg, "HTTP请求失败"):
+		return "HTTP请求失败"
+	case strings.HasPrefix(msg, "服务器返回错误"):
+		return truncate(msg, 40)
+	case strings.HasPrefix(msg, "刷新失败"):
+		return "刷新失败"
+	}
+	return truncate(msg, 60)
+}
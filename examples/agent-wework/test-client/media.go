@@ -0,0 +1,211 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+)
+
+// mediaTTL 图片URL有效期（与企业微信一致，5分钟）
+const mediaTTL = 5 * time.Minute
+
+// mediaFile 已加密的待下载文件
+type mediaFile struct {
+	data    []byte
+	expires time.Time
+}
+
+// MediaServer 本地图片服务，模拟企业微信的加密图片下载地址
+//
+// 企业微信回调中的图片URL指向加密文件：AES-256-CBC，密钥为EncodingAESKey解码后的32字节，
+// IV为密钥前16字节，PKCS#7按32字节填充。测试客户端按同样格式加密本地文件，服务端需要能
+// 从这里下载并解密。
+type MediaServer struct {
+	key     []byte
+	baseURL string
+
+	mutex sync.Mutex
+	files map[string]mediaFile
+}
+
+var (
+	mediaServer     *MediaServer
+	mediaServerErr  error
+	mediaServerOnce sync.Once
+)
+
+// getMediaServer 首次发送图片时启动本地图片服务
+func getMediaServer(wxcpt *wework.WXBizJsonMsgCrypt) (*MediaServer, error) {
+	mediaServerOnce.Do(func() {
+		mediaServer, mediaServerErr = startMediaServer(wxcpt.Key, settings.MediaAddr, settings.MediaURL)
+		if mediaServerErr == nil {
+			fmt.Printf("%s🖼️  本地图片服务: %s%s\n", ColorGray, mediaServer.baseURL, ColorReset)
+		}
+	})
+	return mediaServer, mediaServerErr
+}
+
+// startMediaServer 监听addr，publicURL为服务端可访问的地址（为空时由监听地址推导）
+func startMediaServer(key []byte, addr, publicURL string) (*MediaServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("启动本地图片服务失败: %w", err)
+	}
+	if publicURL == "" {
+		publicURL = "http://" + listener.Addr().String()
+	}
+
+	m := &MediaServer{
+		key:     key,
+		baseURL: strings.TrimRight(publicURL, "/"),
+		files:   make(map[string]mediaFile),
+	}
+	go http.Serve(listener, m)
+	return m, nil
+}
+
+// Add 加密本地图片或文件并返回下载URL
+func (m *MediaServer) Add(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取文件失败: %w", err)
+	}
+	encrypted, err := encryptMedia(m.key, data)
+	if err != nil {
+		return "", err
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", fmt.Errorf("生成文件ID失败: %w", err)
+	}
+	id := hex.EncodeToString(idBytes)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := time.Now()
+	for k, f := range m.files {
+		if now.After(f.expires) {
+			delete(m.files, k)
+		}
+	}
+	m.files[id] = mediaFile{data: encrypted, expires: now.Add(mediaTTL)}
+	return m.baseURL + "/media/" + id, nil
+}
+
+// ServeHTTP 下载加密文件 GET /media/{id}
+func (m *MediaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/media/")
+
+	m.mutex.Lock()
+	f, ok := m.files[id]
+	m.mutex.Unlock()
+
+	if !ok || time.Now().After(f.expires) {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Printf("%s[媒体] 服务端下载 %s（%d字节）%s\n", ColorGray, id[:8], len(f.data), ColorReset)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(f.data)
+}
+
+// encryptMedia 按企业微信图片格式加密
+func encryptMedia(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建AES加密器失败: %w", err)
+	}
+	padded := wework.NewPKCS7Encoder().Encode(append([]byte(nil), data...))
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, key[:16]).CryptBlocks(encrypted, padded)
+	return encrypted, nil
+}
+
+// mediaMessage 构造图片（单张、无文本）或图文混排消息
+func mediaMessage(wxcpt *wework.WXBizJsonMsgCrypt, sender Sender, text string, images []string) (wework.IncomingMessage, error) {
+	server, err := getMediaServer(wxcpt)
+	if err != nil {
+		return wework.IncomingMessage{}, err
+	}
+
+	urls := make([]string, 0, len(images))
+	for _, path := range images {
+		url, err := server.Add(path)
+		if err != nil {
+			return wework.IncomingMessage{}, err
+		}
+		urls = append(urls, url)
+	}
+
+	if text == "" && len(urls) == 1 {
+		return wework.IncomingMessage{
+			BaseMessage: sender.baseMessage(wework.MsgTypeImage),
+			Image:       &wework.ImageContent{URL: urls[0]},
+		}, nil
+	}
+
+	var items []wework.MixedItem
+	if text != "" {
+		items = append(items, wework.MixedItem{
+			MsgType: wework.MsgTypeText,
+			Text:    &wework.TextContent{Content: text},
+		})
+	}
+	for _, url := range urls {
+		items = append(items, wework.MixedItem{
+			MsgType: wework.MsgTypeImage,
+			Image:   &wework.ImageContent{URL: url},
+		})
+	}
+	return wework.IncomingMessage{
+		BaseMessage: sender.baseMessage(wework.MsgTypeMixed),
+		Mixed:       &wework.MixedContent{MsgItem: items},
+	}, nil
+}
+
+// sendFile 发送文件消息（与图片相同的加密格式）
+func sendFile(wxcpt *wework.WXBizJsonMsgCrypt, sender Sender, path string) (string, string, error) {
+	server, err := getMediaServer(wxcpt)
+	if err != nil {
+		return "", "", err
+	}
+	url, err := server.Add(path)
+	if err != nil {
+		return "", "", err
+	}
+	return postMessage(wxcpt, wework.IncomingMessage{
+		BaseMessage: sender.baseMessage(wework.MsgTypeFile),
+		File:        &wework.FileContent{URL: url},
+	})
+}
+
+// parseMediaCommand 解析交互命令：/image <图片> 或 /mixed <图片[,图片...]> <文本>
+func parseMediaCommand(input string) (text string, images []string, ok bool) {
+	cmd, rest, _ := strings.Cut(input, " ")
+	rest = strings.TrimSpace(rest)
+	switch cmd {
+	case "/image":
+		if rest == "" {
+			return "", nil, false
+		}
+		return "", []string{rest}, true
+	case "/mixed":
+		paths, text, _ := strings.Cut(rest, " ")
+		if paths == "" {
+			return "", nil, false
+		}
+		return strings.TrimSpace(text), strings.Split(paths, ","), true
+	}
+	return "", nil, false
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/deepsage-ai/b0dy/channels/wework"
+	"gopkg.in/yaml.v3"
+)
+
+// 脚本模式的退出码
+const (
+	exitOK     = 0 // 全部步骤通过
+	exitFailed = 1 // 存在未通过的步骤
+	exitError  = 2 // 配置或脚本错误，未能执行
+)
+
+// Scenario 场景脚本
+//
+//	name: 基础回归
+//	user: regression-001      # 可选，覆盖 -user，同一脚本内的消息共享会话上下文
+//	chat_id: wr_test_group    # 可选，覆盖 -group，以群聊消息发送（自动@机器人）
+//	timeout: 60s              # 可选，单条消息等待回复的超时
+//	steps:
+//	  - message: 现在几点了？
+//	    contains: ["点"]
+//	    matches: ['\d{1,2}[:：]\d{2}']
+//	    not_contains: ["错误"]
+//	  - from: alice           # 可选，群聊中本条消息的发送者
+//	    message: "@小兴 我是谁？"
+type Scenario struct {
+	Name    string         `yaml:"name"`
+	User    string         `yaml:"user"`
+	ChatID  string         `yaml:"chat_id"`
+	Timeout time.Duration  `yaml:"timeout"`
+	Steps   []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioStep 单条消息及对回复的断言
+type ScenarioStep struct {
+	Name        string        `yaml:"name"`
+	Message     string        `yaml:"message"`
+	From        string        `yaml:"from"`         // 发送者，默认为脚本的user
+	Images      []string      `yaml:"images"`       // 附带的本地图片（相对脚本目录），有message时为图文混排
+	Contains    []string      `yaml:"contains"`     // 回复必须包含的子串
+	NotContains []string      `yaml:"not_contains"` // 回复不得包含的子串
+	Matches     []string      `yaml:"matches"`      // 回复必须匹配的正则
+	Timeout     time.Duration `yaml:"timeout"`      // 覆盖脚本级超时
+
+	patterns []*regexp.Regexp
+}
+
+// loadScenario 读取并校验场景脚本
+func loadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取脚本失败: %w", err)
+	}
+
+	var sc Scenario
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("解析脚本失败: %w", err)
+	}
+	if len(sc.Steps) == 0 {
+		return nil, fmt.Errorf("脚本中没有步骤: %s", path)
+	}
+	if sc.Name == "" {
+		sc.Name = path
+	}
+	if sc.Timeout <= 0 {
+		sc.Timeout = defaultStreamTimeout
+	}
+
+	for i := range sc.Steps {
+		step := &sc.Steps[i]
+		if strings.TrimSpace(step.Message) == "" && len(step.Images) == 0 {
+			return nil, fmt.Errorf("第%d步缺少message或images", i+1)
+		}
+		if step.Name == "" {
+			step.Name = step.Message
+			if step.Name == "" {
+				step.Name = "[图片] " + strings.Join(step.Images, ", ")
+			}
+		}
+		for j, img := range step.Images {
+			if !filepath.IsAbs(img) {
+				step.Images[j] = filepath.Join(filepath.Dir(path), img)
+			}
+		}
+		if step.Timeout <= 0 {
+			step.Timeout = sc.Timeout
+		}
+		for _, expr := range step.Matches {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("第%d步正则无效 %q: %w", i+1, expr, err)
+			}
+			step.patterns = append(step.patterns, re)
+		}
+	}
+	return &sc, nil
+}
+
+// check 校验回复，返回未通过的断言
+func (s *ScenarioStep) check(reply string) []string {
+	var failures []string
+	for _, sub := range s.Contains {
+		if !strings.Contains(reply, sub) {
+			failures = append(failures, fmt.Sprintf("未包含 %q", sub))
+		}
+	}
+	for _, sub := range s.NotContains {
+		if strings.Contains(reply, sub) {
+			failures = append(failures, fmt.Sprintf("不应包含 %q", sub))
+		}
+	}
+	for _, re := range s.patterns {
+		if !re.MatchString(reply) {
+			failures = append(failures, fmt.Sprintf("未匹配 /%s/", re))
+		}
+	}
+	return failures
+}
+
+// runScript 按顺序执行场景脚本，返回进程退出码（脚本模式关闭调试输出）
+func runScript(path string) int {
+	settings.Debug = false
+	sc, err := loadScenario(path)
+	if err != nil {
+		fmt.Printf("%s❌ %v%s\n", ColorRed, err, ColorReset)
+		return exitError
+	}
+	wxcpt, err := wework.NewWXBizJsonMsgCrypt(settings.Token, settings.AESKey, "")
+	if err != nil {
+		fmt.Printf("%s❌ 初始化加密器失败: %v%s\n", ColorRed, err, ColorReset)
+		return exitError
+	}
+	base := defaultSender()
+	if sc.User != "" {
+		base.UserID = sc.User
+	}
+	if sc.ChatID != "" {
+		base.ChatID = sc.ChatID
+	}
+
+	fmt.Printf("%s📜 场景: %s（%d步，%s）%s\n", ColorCyan, sc.Name, len(sc.Steps), base, ColorReset)
+
+	passed := 0
+	startAll := time.Now()
+	for i := range sc.Steps {
+		step := &sc.Steps[i]
+		start := time.Now()
+		sender := base
+		if step.From != "" {
+			sender.UserID = step.From
+		}
+		message := step.Message
+		if message != "" {
+			message = withMention(sender, message)
+		}
+		reply, err := ask(wxcpt, sender, message, step.Timeout, step.Images...)
+		elapsed := time.Since(start)
+
+		var failures []string
+		if err != nil {
+			failures = []string{err.Error()}
+		} else {
+			failures = step.check(reply)
+		}
+
+		if len(failures) == 0 {
+			passed++
+			fmt.Printf("%s✅ [%d/%d] %s%s %s(%.2fs)%s\n",
+				ColorGreen, i+1, len(sc.Steps), step.Name, ColorReset, ColorGray, elapsed.Seconds(), ColorReset)
+			continue
+		}
+
+		fmt.Printf("%s❌ [%d/%d] %s%s %s(%.2fs)%s\n",
+			ColorRed, i+1, len(sc.Steps), step.Name, ColorReset, ColorGray, elapsed.Seconds(), ColorReset)
+		for _, f := range failures {
+			fmt.Printf("   - %s\n", f)
+		}
+		if reply != "" {
+			fmt.Printf("   %s回复: %s%s\n", ColorGray, truncate(reply, 200), ColorReset)
+		}
+	}
+
+	failed := len(sc.Steps) - passed
+	fmt.Println(strings.Repeat("=", 61))
+	fmt.Printf("📊 通过 %d，失败 %d，总耗时 %.2fs\n", passed, failed, time.Since(startAll).Seconds())
+	if failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// truncate 截断过长的文本（按字符）
+func truncate(text string, max int) string {
+	runes := []rune(strings.ReplaceAll(text, "\n", " "))
+	if len(runes) <= max {
+		return string(runes)
+	}
+	return string(runes[:max]) + "..."
+}
@@ -3,17 +3,25 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Ingenimax/agent-sdk-go/pkg/agent"
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
 
 	"github.com/Ingenimax/agent-sdk-go/pkg/llm/openai"
 	"github.com/Ingenimax/agent-sdk-go/pkg/logging"
 	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
 	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
 	"github.com/Ingenimax/agent-sdk-go/pkg/tools"
+
+	"github.com/deepsage-ai/b0dy/pkg/console"
 )
 
 // 颜色代码用于终端输出
@@ -28,10 +36,11 @@ const (
 	ColorGray   = "\033[37m"
 )
 
-func main() {
-	// 创建日志器
-	logger := logging.New()
+// defaultSystemPrompt 默认系统提示词
+const defaultSystemPrompt = "你是一个有用的AI助手，使用中文回答问题。请提供详细和有帮助的回答。"
 
+// newAgent 创建连接到 Ollama 的流式智能体
+func newAgent(logger logging.Logger, mem interfaces.Memory, systemPrompt string) (*agent.Agent, error) {
 	// 创建 OpenAI 兼容的客户端，连接到 Ollama
 	openaiClient := openai.NewClient("", // Ollama 不需要 API Key
 		openai.WithBaseURL("http://10.20.88.156:11434/v1"), // Ollama 的 OpenAI 兼容接口
@@ -43,14 +52,35 @@ func main() {
 	// 可以在这里添加工具，暂时为空
 
 	// 创建智能体
-	agent, err := agent.NewAgent(
+	return agent.NewAgent(
 		agent.WithLLM(openaiClient),
-		agent.WithMemory(memory.NewConversationBuffer()),
+		agent.WithMemory(mem),
 		agent.WithTools(toolRegistry.List()...),
-		agent.WithSystemPrompt("你是一个有用的AI助手，使用中文回答问题。请提供详细和有帮助的回答。"),
+		agent.WithSystemPrompt(systemPrompt),
 		agent.WithMaxIterations(5),
 		agent.WithName("AIBodyStreamingAssistant"),
 	)
+}
+
+func main() {
+	// 解析命令行参数
+	replayFile := flag.String("replay", "", "回放审计日志中的一轮对话（JSONL审计日志或agent-wework会话日志）")
+	replayTurn := flag.Int("turn", 0, "要回放的轮次（从1开始，0表示最后一轮）")
+	withHistory := flag.Bool("history", true, "回放时将之前的轮次载入对话记忆")
+	flag.Parse()
+
+	// 创建日志器
+	logger := logging.New()
+
+	if *replayFile != "" {
+		if err := runReplay(logger, *replayFile, *replayTurn, *withHistory); err != nil {
+			fmt.Printf("%s回放失败: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		return
+	}
+
+	agent, err := newAgent(logger, memory.NewConversationBuffer(), defaultSystemPrompt)
 	if err != nil {
 		logger.Error(context.Background(), "创建智能体失败", map[string]interface{}{"error": err.Error()})
 		return
@@ -115,10 +145,9 @@ func main() {
 		for event := range eventChan {
 			eventCount++
 
-			// 只显示有内容的事件，忽略调试信息
-			if event.Content != "" {
-				fmt.Print(event.Content)
-				responseText.WriteString(event.Content)
+			// 按事件类型显示：思考过程、工具调用及结果、错误和完成信息
+			if content := console.PrintEvent(os.Stdout, event); content != "" {
+				responseText.WriteString(content)
 				contentEvents++
 			}
 		}
@@ -128,3 +157,153 @@ func main() {
 		fmt.Println("\n")
 	}
 }
+
+// === 审计日志回放 ===
+
+// auditTurn 审计日志中的一轮对话
+type auditTurn struct {
+	Time           time.Time `json:"time"`
+	ConversationID string    `json:"conversation_id"`
+	UserID         string    `json:"user_id"`
+	Prompt         string    `json:"prompt"`                  // 发送给智能体的完整输入
+	SystemPrompt   string    `json:"system_prompt,omitempty"` // 当时生效的系统提示词
+	Answer         string    `json:"answer,omitempty"`        // 当时的回复
+}
+
+// runReplay 加载审计日志中的一轮对话，在本地模型上重新执行其提示词
+func runReplay(logger logging.Logger, path string, turn int, withHistory bool) error {
+	turns, err := loadAuditTurns(path)
+	if err != nil {
+		return err
+	}
+	if len(turns) == 0 {
+		return fmt.Errorf("日志中没有可回放的对话: %s", path)
+	}
+	if turn == 0 {
+		turn = len(turns)
+	}
+	if turn < 1 || turn > len(turns) {
+		return fmt.Errorf("轮次超出范围: %d（共 %d 轮）", turn, len(turns))
+	}
+	target := turns[turn-1]
+
+	systemPrompt := target.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = defaultSystemPrompt
+	}
+
+	ctx := context.Background()
+	ctx = multitenancy.WithOrgID(ctx, "ai-body-streaming-demo")
+	ctx = context.WithValue(ctx, memory.ConversationIDKey, "replay-"+target.ConversationID)
+
+	// 载入同一会话之前的轮次，尽量还原当时的上下文
+	mem := memory.NewConversationBuffer()
+	history := 0
+	if withHistory {
+		for _, t := range turns[:turn-1] {
+			if t.ConversationID != target.ConversationID {
+				continue
+			}
+			mem.AddMessage(ctx, interfaces.Message{Role: "user", Content: t.Prompt})
+			if t.Answer != "" {
+				mem.AddMessage(ctx, interfaces.Message{Role: "assistant", Content: t.Answer})
+			}
+			history++
+		}
+	}
+
+	agent, err := newAgent(logger, mem, systemPrompt)
+	if err != nil {
+		return fmt.Errorf("创建智能体失败: %w", err)
+	}
+
+	fmt.Printf("%s=== 回放第 %d/%d 轮 ===%s\n", ColorCyan, turn, len(turns), ColorReset)
+	fmt.Printf("%s会话: %s  用户: %s  时间: %s  载入历史: %d 轮%s\n", ColorGray,
+		target.ConversationID, target.UserID, target.Time.Format("2006-01-02 15:04:05"), history, ColorReset)
+	fmt.Printf("%s提示词: %s%s\n", ColorBlue, target.Prompt, ColorReset)
+	if target.Answer != "" {
+		fmt.Printf("%s--- 原始回复 ---%s\n%s\n", ColorYellow, ColorReset, target.Answer)
+	}
+
+	fmt.Printf("%s--- 本地回放 ---%s\n", ColorPurple, ColorReset)
+	eventChan, err := agent.RunStream(ctx, target.Prompt)
+	if err != nil {
+		return err
+	}
+	for event := range eventChan {
+		switch {
+		case event.Error != nil:
+			fmt.Printf("\n%s错误: %v%s\n", ColorRed, event.Error, ColorReset)
+		case event.Content != "":
+			fmt.Print(event.Content)
+		}
+	}
+	fmt.Printf("\n%s=== 回放完成 ===%s\n", ColorGreen, ColorReset)
+
+	return nil
+}
+
+// loadAuditTurns 加载审计日志：.jsonl 为审计记录，其他按 agent-wework 会话日志解析
+func loadAuditTurns(path string) ([]auditTurn, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取日志失败: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".json":
+		return parseAuditJSONL(string(data))
+	default:
+		conversationID := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		return parseChatLog(conversationID, string(data)), nil
+	}
+}
+
+// parseAuditJSONL 解析每行一条的JSON审计记录
+func parseAuditJSONL(data string) ([]auditTurn, error) {
+	var turns []auditTurn
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var t auditTurn
+		if err := json.Unmarshal([]byte(line), &t); err != nil {
+			return nil, fmt.Errorf("解析第%d行失败: %w", i+1, err)
+		}
+		if t.Prompt != "" {
+			turns = append(turns, t)
+		}
+	}
+	return turns, nil
+}
+
+// chatLogLine 会话日志行格式: [2006-01-02 15:04:05]userID:content
+var chatLogLine = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\]([^:]*):(.*)$`)
+
+// parseChatLog 解析会话日志（仅记录了用户消息，按agent-wework的格式还原提示词）
+func parseChatLog(conversationID, data string) []auditTurn {
+	var turns []auditTurn
+	for _, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(line, "=== ") {
+			continue
+		}
+		m := chatLogLine.FindStringSubmatch(line)
+		if m == nil {
+			// 多行消息的续行
+			if len(turns) > 0 && line != "" {
+				last := &turns[len(turns)-1]
+				last.Prompt += "\n" + line
+			}
+			continue
+		}
+		t, _ := time.ParseInLocation("2006-01-02 15:04:05", m[1], time.Local)
+		turns = append(turns, auditTurn{
+			Time:           t,
+			ConversationID: conversationID,
+			UserID:         m[2],
+			Prompt:         fmt.Sprintf("[用户 %s]: %s", m[2], m[3]),
+		})
+	}
+	return turns
+}
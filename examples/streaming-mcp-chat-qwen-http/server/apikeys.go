@@ -0,0 +1,217 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultAPIKeysFile API密钥配置文件默认路径（可用 API_KEYS_FILE 环境变量覆盖）
+const defaultAPIKeysFile = "api_keys.yaml"
+
+// APIKeyConfig 单个API密钥配置
+type APIKeyConfig struct {
+	Name       string `yaml:"name"`        // 调用方名称（用于日志和统计）
+	Key        string `yaml:"key"`         // 密钥
+	RateLimit  int    `yaml:"rate_limit"`  // 每分钟请求数上限，0表示不限
+	DailyQuota int    `yaml:"daily_quota"` // 每日请求数上限，0表示不限
+}
+
+// APIKeysFile 配置文件结构
+type APIKeysFile struct {
+	APIKeys []APIKeyConfig `yaml:"api_keys"`
+}
+
+// KeyUsage 密钥用量
+type KeyUsage struct {
+	Name       string    `json:"name"`
+	Total      int64     `json:"total"`    // 累计放行的请求
+	Rejected   int64     `json:"rejected"` // 累计因限流/配额拒绝的请求
+	Today      int       `json:"today"`
+	DailyQuota int       `json:"daily_quota"`
+	RateLimit  int       `json:"rate_limit"`
+	LastUsed   time.Time `json:"last_used,omitempty"`
+}
+
+// keyState 密钥运行时状态
+type keyState struct {
+	config      APIKeyConfig
+	usage       KeyUsage
+	windowStart time.Time // 当前分钟窗口起点
+	windowCount int
+	day         string // 当前计数日期（YYYY-MM-DD）
+}
+
+// APIKeyStore API密钥校验与限流
+type APIKeyStore struct {
+	keys  []*keyState
+	mutex sync.Mutex
+}
+
+// LoadAPIKeys 加载API密钥配置，文件不存在时返回nil（不启用认证）
+func LoadAPIKeys(path string) (*APIKeyStore, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取API密钥配置失败: %w", err)
+	}
+
+	var file APIKeysFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析API密钥配置失败: %w", err)
+	}
+	if len(file.APIKeys) == 0 {
+		return nil, nil
+	}
+
+	store := &APIKeyStore{}
+	seen := make(map[string]bool)
+	for i, cfg := range file.APIKeys {
+		if cfg.Key == "" {
+			return nil, fmt.Errorf("api_keys[%d] 缺少key", i)
+		}
+		if seen[cfg.Key] {
+			return nil, fmt.Errorf("api_keys[%d] 的key重复", i)
+		}
+		if cfg.RateLimit < 0 || cfg.DailyQuota < 0 {
+			return nil, fmt.Errorf("api_keys[%d] 的rate_limit/daily_quota不能为负数", i)
+		}
+		seen[cfg.Key] = true
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("key-%d", i+1)
+		}
+		store.keys = append(store.keys, &keyState{
+			config: cfg,
+			usage:  KeyUsage{Name: cfg.Name, RateLimit: cfg.RateLimit, DailyQuota: cfg.DailyQuota},
+		})
+	}
+	return store, nil
+}
+
+// lookup 按密钥查找（常量时间比较）
+func (s *APIKeyStore) lookup(key string) *keyState {
+	var found *keyState
+	for _, state := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(state.config.Key)) == 1 {
+			found = state
+		}
+	}
+	return found
+}
+
+// allow 检查并记录一次请求，被拒绝时返回原因和建议的重试等待秒数
+func (s *APIKeyStore) allow(state *keyState) (string, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if today := now.Format("2006-01-02"); state.day != today {
+		state.day = today
+		state.usage.Today = 0
+	}
+	if now.Sub(state.windowStart) >= time.Minute {
+		state.windowStart = now
+		state.windowCount = 0
+	}
+
+	if quota := state.config.DailyQuota; quota > 0 && state.usage.Today >= quota {
+		state.usage.Rejected++
+		tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+		return fmt.Sprintf("已超出每日配额（%d次）", quota), int(tomorrow.Sub(now).Seconds()) + 1
+	}
+	if limit := state.config.RateLimit; limit > 0 && state.windowCount >= limit {
+		state.usage.Rejected++
+		return fmt.Sprintf("请求过于频繁（每分钟最多%d次）", limit), int(time.Minute-now.Sub(state.windowStart))/int(time.Second) + 1
+	}
+
+	state.windowCount++
+	state.usage.Today++
+	state.usage.Total++
+	state.usage.LastUsed = now
+	return "", 0
+}
+
+// Usage 获取密钥用量副本
+func (s *APIKeyStore) Usage(state *keyState) KeyUsage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	usage := state.usage
+	if state.day != time.Now().Format("2006-01-02") {
+		usage.Today = 0
+	}
+	return usage
+}
+
+// requestAPIKey 从请求中提取密钥：Authorization: Bearer、X-API-Key，或 ?api_key=（WebSocket无法设置请求头时使用）
+func requestAPIKey(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	return c.Query("api_key")
+}
+
+// Middleware 认证与限流中间件，store为nil时放行所有请求
+func (s *APIKeyStore) Middleware() gin.HandlerFunc {
+	return s.middleware(true)
+}
+
+// Authenticate 仅认证、不计入用量的中间件（用于用量查询等接口）
+func (s *APIKeyStore) Authenticate() gin.HandlerFunc {
+	return s.middleware(false)
+}
+
+func (s *APIKeyStore) middleware(limit bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s == nil {
+			c.Next()
+			return
+		}
+
+		state := s.lookup(requestAPIKey(c))
+		if state == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "缺少或无效的API密钥"})
+			return
+		}
+
+		if !limit {
+			c.Set("api_key", state)
+			c.Next()
+			return
+		}
+
+		if reason, retryAfter := s.allow(state); reason != "" {
+			fmt.Printf("⛔ API密钥 %s 被限流: %s\n", state.config.Name, reason)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": reason})
+			return
+		}
+
+		c.Set("api_key", state)
+		c.Next()
+	}
+}
+
+// handleUsage 查询当前密钥的用量 GET /usage
+func handleUsage(c *gin.Context) {
+	state, ok := c.Get("api_key")
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"auth": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"auth": true, "usage": apiKeys.Usage(state.(*keyState))})
+}
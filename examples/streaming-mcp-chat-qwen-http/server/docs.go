@@ -0,0 +1,60 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPISpec OpenAPI 3 接口文档（随程序编译）
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// swaggerUIPage Swagger UI页面（静态资源从CDN加载）
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <title>AI-Body 千问 HTTP API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>`
+
+// openAPIJSON 由YAML转换的JSON文档（启动时转换一次）
+var openAPIJSON = func() []byte {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(openAPISpec, &doc); err != nil {
+		panic("openapi.yaml 格式错误: " + err.Error())
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		panic("openapi.yaml 转换JSON失败: " + err.Error())
+	}
+	return data
+}()
+
+// handleOpenAPIYAML 接口文档 GET /openapi.yaml
+func handleOpenAPIYAML(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", openAPISpec)
+}
+
+// handleOpenAPIJSON 接口文档 GET /openapi.json
+func handleOpenAPIJSON(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPIJSON)
+}
+
+// handleSwaggerUI 在线接口文档 GET /docs
+func handleSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,397 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	embeddingModel     = "text-embedding-v3" // 千问文本向量模型
+	embeddingBatchSize = 10                  // DashScope单次请求最多10条
+	documentChunkSize  = 500                 // 分块大小（字符数）
+	documentTopK       = 3                   // 每次聊天注入的相关分块数
+	maxDocumentSize    = 10 << 20            // 上传文件大小上限
+)
+
+// DocumentInfo 上传文档信息
+type DocumentInfo struct {
+	ID        string    `json:"document_id"`
+	SessionID string    `json:"session_id"`
+	Filename  string    `json:"filename"`
+	Size      int       `json:"size"`
+	Chunks    int       `json:"chunks"`
+	CreatedAt time.Time `json:"created_at"`
+	owner     *keyState // 上传文档的API密钥（未启用认证时为nil）
+}
+
+// docChunk 带向量的文档分块
+type docChunk struct {
+	docID    string
+	filename string
+	text     string
+	vector   []float32
+}
+
+// DocumentStore 会话文档存储（内存，按会话隔离）
+type DocumentStore struct {
+	docs   map[string]*DocumentInfo
+	chunks map[string][]docChunk // 会话ID -> 分块
+	mutex  sync.RWMutex
+}
+
+// NewDocumentStore 创建文档存储
+func NewDocumentStore() *DocumentStore {
+	return &DocumentStore{docs: make(map[string]*DocumentInfo), chunks: make(map[string][]docChunk)}
+}
+
+// Add 添加文档及其分块
+func (s *DocumentStore) Add(info *DocumentInfo, texts []string, vectors [][]float32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.docs[info.ID] = info
+	for i, text := range texts {
+		s.chunks[info.SessionID] = append(s.chunks[info.SessionID], docChunk{
+			docID:    info.ID,
+			filename: info.Filename,
+			text:     text,
+			vector:   vectors[i],
+		})
+	}
+}
+
+// List 列出会话的文档
+func (s *DocumentStore) List(sessionID string) []DocumentInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := []DocumentInfo{}
+	for _, info := range s.docs {
+		if info.SessionID == sessionID {
+			list = append(list, *info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// Has 会话是否有文档
+func (s *DocumentStore) Has(sessionID string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.chunks[sessionID]) > 0
+}
+
+// Delete 删除文档，owner不为nil时只允许同一API密钥删除
+func (s *DocumentStore) Delete(id string, owner *keyState) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	info, ok := s.docs[id]
+	if !ok || (owner != nil && info.owner != owner) {
+		return false
+	}
+	delete(s.docs, id)
+
+	kept := s.chunks[info.SessionID][:0]
+	for _, c := range s.chunks[info.SessionID] {
+		if c.docID != id {
+			kept = append(kept, c)
+		}
+	}
+	s.chunks[info.SessionID] = kept
+	return true
+}
+
+// DeleteSession 删除会话的全部文档
+func (s *DocumentStore) DeleteSession(sessionID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, info := range s.docs {
+		if info.SessionID == sessionID {
+			delete(s.docs, id)
+		}
+	}
+	delete(s.chunks, sessionID)
+}
+
+// Search 按余弦相似度检索会话内最相关的分块
+func (s *DocumentStore) Search(sessionID string, query []float32, topK int) []docChunk {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	type scored struct {
+		chunk docChunk
+		score float64
+	}
+	var results []scored
+	for _, c := range s.chunks[sessionID] {
+		results = append(results, scored{chunk: c, score: cosine(query, c.vector)})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].score > results[j].score })
+
+	var top []docChunk
+	for i := 0; i < len(results) && i < topK; i++ {
+		top = append(top, results[i].chunk)
+	}
+	return top
+}
+
+// cosine 余弦相似度
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// Embedder 文本向量客户端（DashScope OpenAI兼容接口）
+type Embedder struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewEmbedder 创建向量客户端
+func NewEmbedder(apiKey, baseURL string) *Embedder {
+	return &Embedder{apiKey: apiKey, baseURL: baseURL, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Embed 批量生成向量
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+		batch, err := e.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (e *Embedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	body, _ := json.Marshal(map[string]interface{}{"model": embeddingModel, "input": texts})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("调用向量接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("向量接口返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析向量结果失败: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("向量数量不匹配: 期望 %d，实际 %d", len(texts), len(result.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("向量结果索引越界: %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// splitText 按段落将文本切分为不超过size的分块
+func splitText(text string, size int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		runes := []rune(para)
+		for len(runes) > size {
+			flush()
+			current.WriteString(string(runes[:size]))
+			flush()
+			runes = runes[size:]
+		}
+		if utf8.RuneCountInString(current.String())+len(runes) > size {
+			flush()
+		}
+		current.WriteString(string(runes))
+		current.WriteString("\n\n")
+	}
+	flush()
+
+	return chunks
+}
+
+// withDocumentContext 为会话消息附加上传文档中的相关内容，检索失败时返回原消息
+func withDocumentContext(sessionID, message string) string {
+	if !documents.Has(sessionID) {
+		return message
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	vectors, err := embedder.Embed(ctx, []string{message})
+	if err != nil {
+		fmt.Printf("⚠️  文档检索失败，按普通消息处理: %v\n", err)
+		return message
+	}
+
+	chunks := documents.Search(sessionID, vectors[0], documentTopK)
+	if len(chunks) == 0 {
+		return message
+	}
+
+	var b strings.Builder
+	b.WriteString("请参考以下资料回答问题（资料来自用户上传的文档，与问题无关时请忽略）：\n\n")
+	for i, c := range chunks {
+		fmt.Fprintf(&b, "[%d] %s\n%s\n\n", i+1, c.filename, c.text)
+	}
+	b.WriteString("用户问题：")
+	b.WriteString(message)
+	return b.String()
+}
+
+// handleUploadDocument 上传文档 POST /documents（multipart：file、session_id）
+func handleUploadDocument(c *gin.Context) {
+	sessionID := c.PostForm("session_id")
+	if _, ok := sessions.Get(sessionID, requestOwner(c)); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在，请先创建会话并传入session_id"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少上传文件（字段名 file）"})
+		return
+	}
+	if header.Size > maxDocumentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("文件不能超过 %dMB", maxDocumentSize>>20)})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取上传文件失败"})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxDocumentSize+1))
+	file.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取上传文件失败"})
+		return
+	}
+
+	var text string
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".pdf":
+		if text, err = extractPDFText(data); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	case ".md", ".markdown", ".txt":
+		if !utf8.Valid(data) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "文本文件需为UTF-8编码"})
+			return
+		}
+		text = string(data)
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "仅支持PDF、Markdown和纯文本文件"})
+		return
+	}
+
+	chunks := splitText(text, documentChunkSize)
+	if len(chunks) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "文档内容为空"})
+		return
+	}
+
+	vectors, err := embedder.Embed(c.Request.Context(), chunks)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("生成向量失败: %v", err)})
+		return
+	}
+
+	b := make([]byte, 8)
+	rand.Read(b)
+	info := &DocumentInfo{
+		ID:        "doc_" + hex.EncodeToString(b),
+		SessionID: sessionID,
+		Filename:  filepath.Base(header.Filename),
+		Size:      len(data),
+		Chunks:    len(chunks),
+		CreatedAt: time.Now(),
+		owner:     requestOwner(c),
+	}
+	documents.Add(info, chunks, vectors)
+
+	fmt.Printf("📄 会话 %s 上传文档: %s (%d个分块)\n", sessionID, info.Filename, info.Chunks)
+	c.JSON(http.StatusCreated, info)
+}
+
+// handleListDocuments 列出会话文档 GET /sessions/:id/documents
+func handleListDocuments(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := sessions.Get(id, requestOwner(c)); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"documents": documents.List(id)})
+}
+
+// handleDeleteDocument 删除文档 DELETE /documents/:id
+func handleDeleteDocument(c *gin.Context) {
+	id := c.Param("id")
+	if !documents.Delete(id, requestOwner(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文档不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": id})
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/gin-gonic/gin"
+
+	"github.com/deepsage-ai/b0dy/pkg/metrics"
+)
+
+// openAIModel 对外暴露的模型名称（实际由带MCP工具的千问智能体处理）
+const openAIModel = "ai-body-qwen"
+
+// OpenAIMessage OpenAI格式的消息
+type OpenAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OpenAIChatRequest OpenAI格式的聊天请求（仅使用所需字段，其余参数忽略）
+type OpenAIChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []OpenAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+// openAIChoice 响应选项（非流式使用Message，流式使用Delta）
+type openAIChoice struct {
+	Index        int            `json:"index"`
+	Message      *OpenAIMessage `json:"message,omitempty"`
+	Delta        *OpenAIMessage `json:"delta,omitempty"`
+	FinishReason *string        `json:"finish_reason"`
+}
+
+// openAIResponse 聊天响应/流式分块
+type openAIResponse struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   *openAIUsage   `json:"usage,omitempty"`
+}
+
+// openAIUsage 用量（智能体不返回token统计，固定为0）
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// openAIError 返回OpenAI格式的错误
+func openAIError(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{"error": gin.H{"message": message, "type": "invalid_request_error"}})
+}
+
+// handleOpenAIModels 模型列表 GET /v1/models
+func handleOpenAIModels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data": []gin.H{
+			{"id": openAIModel, "object": "model", "created": 0, "owned_by": "ai-body"},
+		},
+	})
+}
+
+// handleOpenAIChat OpenAI兼容的聊天接口 POST /v1/chat/completions
+//
+// 客户端每次携带完整历史：除最后一条用户消息外的历史写入一次性会话的记忆，
+// 最后一条用户消息交给智能体处理，请求结束后清理记忆。客户端的system消息被忽略，
+// 使用智能体自身的系统提示词。
+func handleOpenAIChat(c *gin.Context) {
+	var req OpenAIChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		openAIError(c, http.StatusBadRequest, "无效的请求格式")
+		return
+	}
+
+	last := len(req.Messages) - 1
+	if last < 0 || req.Messages[last].Role != "user" || strings.TrimSpace(req.Messages[last].Content) == "" {
+		openAIError(c, http.StatusBadRequest, "messages的最后一条必须是非空的user消息")
+		return
+	}
+
+	conversationID := fmt.Sprintf("openai-%d", time.Now().UnixNano())
+	ctx := sessionContext(conversationID)
+	defer chatMemory.Clear(ctx)
+	for _, m := range req.Messages[:last] {
+		if m.Role != "user" && m.Role != "assistant" {
+			continue
+		}
+		chatMemory.AddMessage(ctx, interfaces.Message{Role: m.Role, Content: m.Content})
+	}
+
+	// 客户端断开时立即取消（OpenAI客户端不支持续传），也可用 DELETE /chat/:request_id 取消
+	chat, runCtx := runningChats.Start(c, ctx)
+	defer runningChats.Finish(chat)
+	defer context.AfterFunc(c.Request.Context(), chat.cancel)()
+	c.Header("X-Request-ID", chat.id)
+
+	atomic.AddInt64(&activeChats, 1)
+	defer atomic.AddInt64(&activeChats, -1)
+	start := time.Now()
+
+	id := newCompletionID()
+	created := time.Now().Unix()
+	stop := "stop"
+
+	eventChan, err := agentInstance.RunStream(runCtx, req.Messages[last].Content)
+	if err != nil {
+		metrics.ObserveLLM(start, err)
+		openAIError(c, http.StatusInternalServerError, fmt.Sprintf("处理失败: %v", err))
+		return
+	}
+
+	if !req.Stream {
+		var answer strings.Builder
+		var streamErr error
+		for event := range eventChan {
+			if event.Type == interfaces.AgentEventError && event.Error != nil {
+				streamErr = event.Error
+				continue
+			}
+			if event.Type != interfaces.AgentEventToolCall && event.Type != interfaces.AgentEventToolResult {
+				answer.WriteString(event.Content)
+			}
+		}
+		metrics.ObserveLLM(start, streamErr)
+		if streamErr != nil && answer.Len() == 0 {
+			openAIError(c, http.StatusInternalServerError, streamErr.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, openAIResponse{
+			ID:      id,
+			Object:  "chat.completion",
+			Created: created,
+			Model:   openAIModel,
+			Choices: []openAIChoice{{
+				Message:      &OpenAIMessage{Role: "assistant", Content: answer.String()},
+				FinishReason: &stop,
+			}},
+			Usage: &openAIUsage{},
+		})
+		return
+	}
+
+	// 流式：按OpenAI的SSE格式逐块输出，以 [DONE] 结束
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	writeChunk := func(delta OpenAIMessage, reason *string) {
+		data, _ := json.Marshal(openAIResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   openAIModel,
+			Choices: []openAIChoice{{Delta: &delta, FinishReason: reason}},
+		})
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		c.Writer.Flush()
+	}
+
+	writeChunk(OpenAIMessage{Role: "assistant"}, nil)
+	var streamErr error
+	wrote := false
+	for event := range eventChan {
+		switch event.Type {
+		case interfaces.AgentEventToolCall, interfaces.AgentEventToolResult:
+			continue
+		case interfaces.AgentEventError:
+			if event.Error != nil {
+				streamErr = event.Error
+			}
+			continue
+		}
+		if event.Content != "" {
+			writeChunk(OpenAIMessage{Content: event.Content}, nil)
+			wrote = true
+		}
+	}
+	metrics.ObserveLLM(start, streamErr)
+
+	// 没有任何输出时把错误以OpenAI错误对象的形式告知客户端
+	if streamErr != nil && !wrote {
+		data, _ := json.Marshal(gin.H{"error": gin.H{"message": streamErr.Error(), "type": "server_error"}})
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+	}
+
+	writeChunk(OpenAIMessage{}, &stop)
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+// newCompletionID 生成响应ID
+func newCompletionID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "chatcmpl-" + hex.EncodeToString(b)
+}
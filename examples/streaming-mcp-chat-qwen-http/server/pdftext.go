@@ -0,0 +1,334 @@
+package server
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// maxPDFStreamSize 单个PDF流解压后的大小上限
+const maxPDFStreamSize = 32 << 20
+
+// extractPDFText 从PDF中提取文本
+//
+// 仅做轻量解析：解压FlateDecode内容流并读取Tj/TJ文本操作符，适用于导出自Word、Markdown等
+// 工具的文本型PDF。扫描件、加密PDF以及没有Unicode映射的CID字体（部分中文PDF）无法提取，
+// 此时返回错误，建议先转换为Markdown再上传。
+func extractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("不是有效的PDF文件")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", errors.New("不支持加密的PDF")
+	}
+
+	var out strings.Builder
+	pos := 0
+	for {
+		idx := bytes.Index(data[pos:], []byte("stream"))
+		if idx < 0 {
+			break
+		}
+		start := pos + idx
+		pos = start + len("stream")
+		if start > 0 && data[start-1] == 'd' { // endstream
+			continue
+		}
+
+		// 流数据从 stream 关键字后的换行开始
+		body := pos
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body < len(data) && data[body] == '\n' {
+			body++
+		}
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		raw := data[body : body+end]
+		pos = body + end + len("endstream")
+
+		dict := streamDict(data[:start])
+		if !isContentStream(dict) {
+			continue
+		}
+		content := raw
+		if strings.Contains(dict, "FlateDecode") {
+			decoded, err := inflate(raw)
+			if err != nil {
+				continue
+			}
+			content = decoded
+		}
+		extractContentText(content, &out)
+	}
+
+	text := normalizeExtractedText(out.String())
+	if !looksLikeText(text) {
+		return "", errors.New("未能从PDF中提取文本（可能是扫描件或使用了不支持的字体编码）")
+	}
+	return text, nil
+}
+
+// nonContentStream 图片、字体、交叉引用等非页面内容的流
+var nonContentStream = regexp.MustCompile(`/Subtype\s*/(Image|Type1C|CIDFontType0C|OpenType)|/Type\s*/(XRef|ObjStm|Metadata)|/Length[123]\s|/(DCT|JPX|CCITTFax|JBIG2)Decode`)
+
+// streamDict 获取流对象的字典
+func streamDict(before []byte) string {
+	objStart := bytes.LastIndex(before, []byte(" obj"))
+	if objStart < 0 {
+		return ""
+	}
+	return string(before[objStart:])
+}
+
+// isContentStream 判断是否为可能包含文本的内容流
+func isContentStream(dict string) bool {
+	return !nonContentStream.MatchString(dict)
+}
+
+// inflate 解压zlib数据（限制大小）
+func inflate(raw []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxPDFStreamSize))
+	if err != nil && len(data) == 0 {
+		return nil, err
+	}
+	return data, nil // 部分损坏的流保留已解出的内容
+}
+
+// extractContentText 解析内容流中的文本操作符
+func extractContentText(content []byte, out *strings.Builder) {
+	var last []byte    // 最近一个字符串操作数
+	var array [][]byte // TJ数组中的字符串
+	inArray := false
+
+	newline := func() {
+		if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			out.WriteString("\n")
+		}
+	}
+	push := func(s []byte) {
+		if inArray {
+			array = append(array, s)
+		} else {
+			last = s
+		}
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, n := readLiteralString(content[i:])
+			push(s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			s, n := readHexString(content[i:])
+			push(s)
+			i += n
+		case c == '[':
+			inArray, array = true, nil
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '/':
+			i++
+			for i < len(content) && isRegularPDFChar(content[i]) {
+				i++
+			}
+		case isRegularPDFChar(c):
+			start := i
+			for i < len(content) && isRegularPDFChar(content[i]) {
+				i++
+			}
+			token := string(content[start:i])
+
+			if num, err := strconv.ParseFloat(token, 64); err == nil {
+				if inArray && num < -200 { // TJ中较大的负偏移通常是单词间距
+					array = append(array, []byte(" "))
+				}
+				continue
+			}
+
+			switch token {
+			case "Tj":
+				out.WriteString(decodePDFString(last))
+			case "'", "\"":
+				newline()
+				out.WriteString(decodePDFString(last))
+			case "TJ":
+				for _, s := range array {
+					out.WriteString(decodePDFString(s))
+				}
+				array = nil
+			case "Td", "TD", "T*", "ET":
+				newline()
+			}
+			last = nil
+		default:
+			i++
+		}
+	}
+	newline()
+}
+
+// isRegularPDFChar PDF中的常规字符（非空白、非分隔符）
+func isRegularPDFChar(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
+
+// readLiteralString 读取 (...) 字符串，返回内容和消耗的字节数
+func readLiteralString(data []byte) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch e := data[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n': // 续行
+				if e == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					j := i
+					for ; j < len(data) && j < i+3 && data[j] >= '0' && data[j] <= '7'; j++ {
+						n = n*8 + int(data[j]-'0')
+					}
+					out = append(out, byte(n))
+					i = j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return out, i + 1
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, len(data)
+}
+
+// readHexString 读取 <...> 字符串
+func readHexString(data []byte) ([]byte, int) {
+	end := bytes.IndexByte(data, '>')
+	if end < 0 {
+		return nil, len(data)
+	}
+
+	var digits []byte
+	for _, c := range data[1:end] {
+		if unicode.Is(unicode.ASCII_Hex_Digit, rune(c)) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out, end + 1
+}
+
+// decodePDFString 解码PDF字符串：带BOM的UTF-16BE，否则按Latin-1处理
+func decodePDFString(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	runes := make([]rune, 0, len(s))
+	for _, b := range s {
+		runes = append(runes, rune(b))
+	}
+	return string(runes)
+}
+
+// normalizeExtractedText 去除控制字符并合并多余空行
+func normalizeExtractedText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, text)
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	text = strings.Join(lines, "\n")
+	for strings.Contains(text, "\n\n\n") {
+		text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
+	}
+	return strings.TrimSpace(text)
+}
+
+// looksLikeText 判断提取结果是否为可读文本（字母数字占比足够）
+func looksLikeText(text string) bool {
+	total, readable := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsPunct(r) {
+			readable++
+		}
+	}
+	return total >= 20 && readable*10 >= total*7
+}
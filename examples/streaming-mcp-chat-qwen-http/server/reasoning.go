@@ -0,0 +1,71 @@
+package server
+
+import "strings"
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// reasoningSplitter 把回复内容中的<think>块拆分为reasoning事件，便于界面折叠或隐藏思考过程
+//
+// 模型流式输出时标签可能被拆到相邻的两个分块中，末尾疑似标签开头的部分暂存到下一分块再判断。
+type reasoningSplitter struct {
+	inThink bool
+	pending string
+}
+
+// split 拆分一个内容分块
+func (s *reasoningSplitter) split(content string) []SSEEvent {
+	text := s.pending + content
+	s.pending = ""
+
+	var out []SSEEvent
+	for text != "" {
+		tag, eventType := thinkOpenTag, "content"
+		if s.inThink {
+			tag, eventType = thinkCloseTag, "reasoning"
+		}
+		if i := strings.Index(text, tag); i >= 0 {
+			out = appendSegment(out, eventType, text[:i])
+			text = text[i+len(tag):]
+			s.inThink = !s.inThink
+			continue
+		}
+
+		keep := partialTagSuffix(text, tag)
+		out = appendSegment(out, eventType, text[:len(text)-keep])
+		s.pending = text[len(text)-keep:]
+		break
+	}
+	return out
+}
+
+// flush 流结束时输出暂存的内容
+func (s *reasoningSplitter) flush() []SSEEvent {
+	eventType := "content"
+	if s.inThink {
+		eventType = "reasoning"
+	}
+	out := appendSegment(nil, eventType, s.pending)
+	s.pending = ""
+	return out
+}
+
+// appendSegment 追加非空的内容片段
+func appendSegment(out []SSEEvent, eventType, text string) []SSEEvent {
+	if text == "" {
+		return out
+	}
+	return append(out, SSEEvent{Type: eventType, Content: text})
+}
+
+// partialTagSuffix text末尾与tag开头重合的长度（不含完整的tag）
+func partialTagSuffix(text, tag string) int {
+	for n := len(tag) - 1; n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// resumeGrace 客户端断开后等待重连的时长，超时仍无人接收则取消生成
+	resumeGrace = 30 * time.Second
+	// streamRetention 回复结束后保留事件供断线重连补齐的时长
+	streamRetention = 5 * time.Minute
+)
+
+// streamEvent 带编号的SSE事件
+type streamEvent struct {
+	ID   int
+	Data string
+}
+
+// ChatStream 一次回复的事件流，生成与推送解耦，支持多次重连按事件ID续传
+type ChatStream struct {
+	events   []streamEvent
+	finished bool
+	notify   chan struct{} // 每追加一个事件关闭并替换，用于唤醒等待方
+	mutex    sync.Mutex
+}
+
+// NewChatStream 创建事件流
+func NewChatStream() *ChatStream {
+	return &ChatStream{notify: make(chan struct{})}
+}
+
+// Append 追加事件（编号从1递增）
+func (s *ChatStream) Append(event SSEEvent) {
+	data, _ := json.Marshal(event)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.finished {
+		return
+	}
+	s.events = append(s.events, streamEvent{ID: len(s.events) + 1, Data: string(data)})
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// Finish 标记结束
+func (s *ChatStream) Finish() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.finished {
+		s.finished = true
+		close(s.notify)
+	}
+}
+
+// Since 获取编号大于lastID的事件，未结束时返回用于等待新事件的通道
+func (s *ChatStream) Since(lastID int) ([]streamEvent, bool, <-chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if lastID < 0 || lastID > len(s.events) {
+		lastID = len(s.events)
+	}
+	return append([]streamEvent(nil), s.events[lastID:]...), s.finished, s.notify
+}
+
+// runningChat 聊天请求（进行中或刚结束、仍可重连补齐）
+type runningChat struct {
+	id          string
+	cancel      context.CancelFunc
+	owner       *keyState // 发起请求的API密钥（未启用认证时为nil）
+	stream      *ChatStream
+	subscribers int
+	detachTimer *time.Timer
+	finished    bool
+}
+
+// ChatRegistry 聊天请求登记，用于按request_id取消和断线重连
+type ChatRegistry struct {
+	chats map[string]*runningChat
+	mutex sync.Mutex
+}
+
+// NewChatRegistry 创建请求登记
+func NewChatRegistry() *ChatRegistry {
+	return &ChatRegistry{chats: make(map[string]*runningChat)}
+}
+
+// Start 登记一个请求，返回请求和可取消的上下文
+func (r *ChatRegistry) Start(c *gin.Context, parent context.Context) (*runningChat, context.Context) {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	ctx, cancel := context.WithCancel(parent)
+	chat := &runningChat{id: "req_" + hex.EncodeToString(b), cancel: cancel, owner: requestOwner(c), stream: NewChatStream()}
+
+	r.mutex.Lock()
+	r.chats[chat.id] = chat
+	r.mutex.Unlock()
+	return chat, ctx
+}
+
+// Finish 请求结束：结束事件流，保留一段时间供重连补齐后移除
+func (r *ChatRegistry) Finish(chat *runningChat) {
+	chat.stream.Finish()
+
+	r.mutex.Lock()
+	chat.finished = true
+	if chat.detachTimer != nil {
+		chat.detachTimer.Stop()
+	}
+	r.mutex.Unlock()
+	chat.cancel()
+
+	time.AfterFunc(streamRetention, func() {
+		r.mutex.Lock()
+		delete(r.chats, chat.id)
+		r.mutex.Unlock()
+	})
+}
+
+// Get 按request_id获取请求，owner不为nil时只允许同一API密钥访问
+func (r *ChatRegistry) Get(requestID string, owner *keyState) (*runningChat, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	chat, ok := r.chats[requestID]
+	if !ok || (owner != nil && chat.owner != owner) {
+		return nil, false
+	}
+	return chat, true
+}
+
+// Cancel 取消进行中的请求
+func (r *ChatRegistry) Cancel(requestID string, owner *keyState) bool {
+	chat, ok := r.Get(requestID, owner)
+	if !ok {
+		return false
+	}
+
+	r.mutex.Lock()
+	finished := chat.finished
+	r.mutex.Unlock()
+	if finished {
+		return false
+	}
+	chat.cancel()
+	return true
+}
+
+// attach 有客户端开始接收事件
+func (r *ChatRegistry) attach(chat *runningChat) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	chat.subscribers++
+	if chat.detachTimer != nil {
+		chat.detachTimer.Stop()
+		chat.detachTimer = nil
+	}
+}
+
+// detach 客户端断开；没有任何接收方且超过重连等待时长后取消生成，避免继续消耗LLM token
+func (r *ChatRegistry) detach(chat *runningChat) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	chat.subscribers--
+	if chat.subscribers > 0 || chat.finished {
+		return
+	}
+	chat.detachTimer = time.AfterFunc(resumeGrace, func() {
+		fmt.Printf("🛑 客户端断开超过%v未重连，取消请求: %s\n", resumeGrace, chat.id)
+		chat.cancel()
+	})
+}
+
+// writeStream 以SSE推送编号大于lastID的事件直到回复结束或客户端断开
+func writeStream(c *gin.Context, chat *runningChat, lastID int) {
+	runningChats.attach(chat)
+	defer runningChats.detach(chat)
+
+	for {
+		events, finished, wait := chat.stream.Since(lastID)
+		for _, event := range events {
+			fmt.Fprintf(c.Writer, "id:%d\ndata:%s\n\n", event.ID, event.Data)
+			lastID = event.ID
+		}
+		c.Writer.Flush()
+		if finished {
+			return
+		}
+
+		select {
+		case <-wait:
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// requestOwner 当前请求的API密钥（未启用认证时为nil）
+func requestOwner(c *gin.Context) *keyState {
+	if state, ok := c.Get("api_key"); ok {
+		return state.(*keyState)
+	}
+	return nil
+}
+
+// handleCancelChat 取消进行中的聊天 DELETE /chat/:request_id
+func handleCancelChat(c *gin.Context) {
+	requestID := c.Param("request_id")
+	if !runningChats.Cancel(requestID, requestOwner(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "请求不存在或已结束"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"cancelled": requestID})
+}
+
+// handleResumeChat 断线重连续传 GET /chat/:request_id/stream
+// 通过 Last-Event-ID 请求头（EventSource自动携带）或 ?last_event_id= 指定已收到的最后一个事件
+func handleResumeChat(c *gin.Context) {
+	chat, ok := runningChats.Get(c.Param("request_id"), requestOwner(c))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "请求不存在或已过期"})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	lastID := 0
+	if lastEventID != "" {
+		id, err := strconv.Atoi(lastEventID)
+		if err != nil || id < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的Last-Event-ID"})
+			return
+		}
+		lastID = id
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Request-ID", chat.id)
+	writeStream(c, chat, lastID)
+}
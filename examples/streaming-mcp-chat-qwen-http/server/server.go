@@ -0,0 +1,427 @@
+package server
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/agent"
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/llm/openai"
+	"github.com/Ingenimax/agent-sdk-go/pkg/logging"
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+	"github.com/Ingenimax/agent-sdk-go/pkg/tools"
+	"github.com/gin-gonic/gin"
+
+	"github.com/deepsage-ai/b0dy/pkg/metrics"
+	"github.com/deepsage-ai/b0dy/pkg/session"
+)
+
+// === HTTP API 相关结构 ===
+type ChatRequest struct {
+	Message   string `json:"message" binding:"required"`
+	SessionID string `json:"session_id,omitempty"` // 可选，指定时在该会话中保持上下文
+}
+
+type SSEEvent struct {
+	Type      string                 `json:"type"` // start | content | reasoning | tool_call | tool_result | error | cancelled | done
+	Content   string                 `json:"content,omitempty"`
+	Tool      *ToolInfo              `json:"tool,omitempty"`     // tool_call、tool_result事件携带
+	Metadata  map[string]interface{} `json:"metadata,omitempty"` // done事件携带智能体的完成信息
+	Events    int                    `json:"events,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"` // start事件携带，用于 DELETE /chat/:request_id
+}
+
+// ToolInfo 工具调用信息（SSE事件和WebSocket帧共用）
+type ToolInfo struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+	Result    string `json:"result,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// newToolInfo 转换智能体的工具调用事件
+func newToolInfo(call *interfaces.ToolCallEvent) *ToolInfo {
+	return &ToolInfo{
+		Name:      call.Name,
+		Arguments: call.Arguments,
+		Result:    call.Result,
+		Status:    call.Status,
+	}
+}
+
+// === 全局变量 ===
+var (
+	agentInstance  *agent.Agent
+	sessionManager *session.SessionMCPManager
+	chatMemory     interfaces.Memory // 智能体记忆（按会话隔离）
+	sessions       = NewSessionStore()
+	activeChats    int64        // 正在处理的聊天请求数（用于监控指标）
+	apiKeys        *APIKeyStore // API密钥认证与限流（未配置时为nil，不启用认证）
+	runningChats   = NewChatRegistry()
+	documents      = NewDocumentStore()
+	embedder       *Embedder // 文档向量化（与对话使用同一DashScope密钥）
+)
+
+// initAgent 完全复用千问版本的智能体初始化逻辑
+func initAgent() error {
+	// 创建日志器
+	logger := logging.New()
+
+	// 创建千问客户端配置 - 完全与千问版本一致
+	apiKey := os.Getenv("DASHSCOPE_API_KEY") // 千问API密钥
+	if apiKey == "" {
+		return fmt.Errorf("请设置 DASHSCOPE_API_KEY 环境变量")
+	}
+	modelName := "qwen-max" // 千问最强模型
+	baseURL := "https://dashscope.aliyuncs.com/compatible-mode/v1"
+
+	fmt.Printf("使用千问模型: %s (支持工具调用)\n", modelName)
+	fmt.Printf("连接到: %s\n", baseURL)
+
+	embedder = NewEmbedder(apiKey, baseURL)
+
+	qwenClient := openai.NewClient(apiKey,
+		openai.WithBaseURL(baseURL),
+		openai.WithModel(modelName),
+		openai.WithLogger(logger))
+
+	// 创建工具注册器 - 保持streaming-chat原有结构
+	toolRegistry := tools.NewRegistry()
+
+	// === MCP 按需连接配置 - 完全复用千问版本逻辑 ===
+	fmt.Printf("=== MCP按需连接配置 ===\n")
+	var mcpServers []interfaces.MCPServer
+
+	// 配置会话级MCP管理器（连接复用 + 调用去重）
+	mcpURL := "http://sn.7soft.cn/sse"
+	fmt.Printf("配置会话级MCP管理器: %s\n", mcpURL)
+
+	// 创建会话级MCP管理器（一个会话回合 = 一个连接 + 去重）
+	sessionManager = session.NewSessionMCPManager(mcpURL,
+		session.WithLogf(func(format string, args ...interface{}) { fmt.Printf(format+"\n", args...) }),
+		session.WithCallObserver(func(tool string, start time.Time, err error) {
+			metrics.ObserveMCPTool(mcpURL, tool, start, err)
+		}),
+	)
+	mcpServers = append(mcpServers, sessionManager)
+	fmt.Printf("✅ 会话级MCP管理器配置完成（连接复用+去重）\n")
+
+	// 测试连接以验证配置正确性
+	fmt.Printf("正在测试连接和工具发现...\n")
+	tools, err := sessionManager.ListTools(context.Background())
+	if err != nil {
+		fmt.Printf("Warning: 测试连接失败: %v\n", err)
+	} else {
+		fmt.Printf("发现 %d 个MCP工具:\n", len(tools))
+		for i, tool := range tools {
+			fmt.Printf("  [%d] %s: %s\n", i+1, tool.Name, tool.Description)
+		}
+	}
+
+	// === 创建智能体 - 完全复用千问版本逻辑 ===
+	if len(mcpServers) > 0 {
+		// 有MCP服务器时，使用WithMCPServers
+		// 千问DashScope API对工具消息格式要求严格，限制记忆大小避免格式问题
+		fmt.Printf("创建MCP智能体 (连接 %d 个MCP服务器)...\n", len(mcpServers))
+		chatMemory = memory.NewConversationBuffer(memory.WithMaxSize(3)) // 限制记忆大小避免工具消息格式问题
+		agentInstance, err = agent.NewAgent(
+			agent.WithLLM(qwenClient),
+			agent.WithMemory(chatMemory),
+			agent.WithTools(toolRegistry.List()...),
+			agent.WithMCPServers(mcpServers),
+			agent.WithRequirePlanApproval(false), // 自动执行工具，不需要审批
+			agent.WithSystemPrompt("你是一个有用的AI助手，使用中文回答问题。你可以使用各种MCP工具来帮助回答问题，请根据用户问题智能选择和调用合适的工具。当你需要获取实时信息（如时间）或执行特定任务时，请主动使用相关工具。"),
+			agent.WithMaxIterations(5),
+			agent.WithName("AIBodyQwenHTTPAssistant"),
+		)
+	} else {
+		// 没有MCP服务器时，使用基础配置（完全兼容streaming-chat）
+		fmt.Printf("创建基础智能体 (无MCP支持)...\n")
+		chatMemory = memory.NewConversationBuffer()
+		agentInstance, err = agent.NewAgent(
+			agent.WithLLM(qwenClient),
+			agent.WithMemory(chatMemory),
+			agent.WithTools(toolRegistry.List()...),
+			agent.WithSystemPrompt("你是一个有用的AI助手，使用中文回答问题。请提供详细和有帮助的回答。"),
+			agent.WithMaxIterations(5),
+			agent.WithName("AIBodyQwenHTTPAssistant"),
+		)
+	}
+
+	if err != nil {
+		return fmt.Errorf("创建智能体失败: %w", err)
+	}
+
+	return nil
+}
+
+// handleChat 处理聊天请求 - 复用千问版本的流式处理逻辑
+func handleChat(c *gin.Context) {
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求格式"})
+		return
+	}
+
+	// 指定会话时保持上下文，否则为一次性对话（结束后清理记忆）
+	if req.SessionID != "" {
+		if _, ok := sessions.Get(req.SessionID, requestOwner(c)); !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在"})
+			return
+		}
+		message := withDocumentContext(req.SessionID, req.Message)
+		streamChat(c, req.SessionID, message, func() { sessions.Touch(req.SessionID) })
+		return
+	}
+
+	conversationID := fmt.Sprintf("http-request-%d", time.Now().UnixNano())
+	streamChat(c, conversationID, req.Message, func() { chatMemory.Clear(sessionContext(conversationID)) })
+}
+
+// streamChat 在指定会话中处理消息并以SSE流式返回
+// 生成在后台进行，事件带递增ID；客户端断线后可通过 GET /chat/:request_id/stream 续传，
+// onFinish 在回复结束（而不是客户端断开）时调用
+func streamChat(c *gin.Context, conversationID, message string, onFinish func()) {
+	// 设置SSE响应头
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	// 创建上下文 - 记忆按组织ID+会话ID隔离，可通过request_id取消
+	chat, ctx := runningChats.Start(c, sessionContext(conversationID))
+	c.Header("X-Request-ID", chat.id)
+	chat.stream.Append(SSEEvent{Type: "start", RequestID: chat.id})
+
+	go func() {
+		defer onFinish()
+		defer runningChats.Finish(chat)
+		generateChat(ctx, chat, message)
+	}()
+
+	writeStream(c, chat, 0)
+}
+
+// generateChat 调用智能体生成回复并写入事件流
+func generateChat(ctx context.Context, chat *runningChat, message string) {
+	// === 完全保持千问版本的流式处理逻辑 ===
+	atomic.AddInt64(&activeChats, 1)
+	defer atomic.AddInt64(&activeChats, -1)
+	start := time.Now()
+
+	// 尝试使用流式传输
+	eventChan, err := agentInstance.RunStream(ctx, message)
+	if err != nil {
+		// 如果流式传输不支持，使用普通模式
+		response, normalErr := agentInstance.Run(ctx, message)
+		metrics.ObserveLLM(start, normalErr)
+		if normalErr != nil {
+			chat.stream.Append(SSEEvent{Type: "error", Content: fmt.Sprintf("处理失败: %v", normalErr)})
+			return
+		}
+
+		// 发送完整响应（<think>块拆分为reasoning事件）
+		var reasoning reasoningSplitter
+		for _, e := range append(reasoning.split(response), reasoning.flush()...) {
+			chat.stream.Append(e)
+		}
+		chat.stream.Append(SSEEvent{Type: "done", Events: 1})
+		return
+	}
+
+	// 处理真实的流式事件 - 完全复用千问版本的事件处理逻辑
+	eventCount := 0
+	var streamErr error
+	var metadata map[string]interface{}
+	var reasoning reasoningSplitter
+	for event := range eventChan {
+		eventCount++
+
+		// 按事件类型转发：思考过程（含内容中的<think>块）、工具调用及结果、错误，完成信息随done事件发送
+		switch event.Type {
+		case interfaces.AgentEventThinking:
+			if event.ThinkingStep != "" {
+				chat.stream.Append(SSEEvent{Type: "reasoning", Content: event.ThinkingStep})
+			}
+		case interfaces.AgentEventToolCall, interfaces.AgentEventToolResult:
+			if event.ToolCall != nil {
+				chat.stream.Append(SSEEvent{Type: string(event.Type), Tool: newToolInfo(event.ToolCall)})
+			}
+		case interfaces.AgentEventError:
+			if event.Error != nil {
+				streamErr = event.Error
+				chat.stream.Append(SSEEvent{Type: "error", Content: event.Error.Error()})
+			}
+		case interfaces.AgentEventComplete:
+			metadata = event.Metadata
+		default:
+			for _, e := range reasoning.split(event.Content) {
+				chat.stream.Append(e)
+			}
+		}
+	}
+	for _, e := range reasoning.flush() {
+		chat.stream.Append(e)
+	}
+
+	// 被取消：DELETE /chat/:request_id 主动取消，或客户端断开后未及时重连
+	if err := ctx.Err(); err != nil {
+		metrics.ObserveLLM(start, err)
+		fmt.Printf("🛑 聊天请求已取消: %s\n", chat.id)
+		chat.stream.Append(SSEEvent{Type: "cancelled", Events: eventCount})
+		return
+	}
+
+	metrics.ObserveLLM(start, streamErr)
+
+	// 发送完成事件
+	chat.stream.Append(SSEEvent{Type: "done", Events: eventCount, Metadata: metadata})
+}
+
+// handleHealth 健康检查
+func handleHealth(c *gin.Context) {
+	// 检查MCP连接状态
+	mcpStatus := "disconnected"
+	if sessionManager != nil {
+		if sessionManager.IsAlive() {
+			mcpStatus = "connected"
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "healthy",
+		"service":    "AI-Body 千问 HTTP API",
+		"mcp_status": mcpStatus,
+		"features":   []string{"streaming", "mcp_tools", "session_management", "sessions_api", "websocket", "openai_compatible", "api_keys", "sse_resume", "openapi", "documents"},
+		"sessions":   len(sessions.List(nil)),
+	})
+}
+
+// handleTools 获取可用工具列表
+func handleTools(c *gin.Context) {
+	if sessionManager == nil {
+		c.JSON(http.StatusOK, gin.H{"tools": []interface{}{}, "count": 0})
+		return
+	}
+
+	tools, err := sessionManager.ListTools(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("获取工具失败: %v", err)})
+		return
+	}
+
+	// 简化工具信息
+	simplifiedTools := make([]map[string]interface{}, len(tools))
+	for i, tool := range tools {
+		simplifiedTools[i] = map[string]interface{}{
+			"name":        tool.Name,
+			"description": tool.Description,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tools": simplifiedTools,
+		"count": len(tools),
+	})
+}
+
+// Run 启动千问HTTP API服务（阻塞运行），返回进程退出码
+func Run(args []string) int {
+	fs := flag.NewFlagSet("serve-http", flag.ExitOnError)
+	port := fs.String("port", "8080", "监听端口")
+	fs.Parse(args)
+
+	// 初始化智能体
+	fmt.Println("🚀 初始化AI助手（基于千问版本）...")
+	if err := initAgent(); err != nil {
+		fmt.Printf("❌ 初始化失败: %v\n", err)
+		return 1
+	}
+	fmt.Println("✅ AI助手初始化完成")
+
+	// 加载API密钥配置
+	keysFile := os.Getenv("API_KEYS_FILE")
+	if keysFile == "" {
+		keysFile = defaultAPIKeysFile
+	}
+	var err error
+	if apiKeys, err = LoadAPIKeys(keysFile); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	if apiKeys != nil {
+		fmt.Printf("🔑 已启用API密钥认证: %d 个密钥 (%s)\n", len(apiKeys.keys), keysFile)
+	} else {
+		fmt.Printf("⚠️  未配置API密钥 (%s)，接口对所有人开放\n", keysFile)
+	}
+
+	// 创建Gin引擎
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Logger(), gin.Recovery())
+	r.Use(metrics.Middleware())
+
+	// 添加CORS中间件
+	r.Use(func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "POST, GET, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	})
+
+	// 路由配置：聊天和工具相关接口需要API密钥（配置了api_keys时）
+	protected := r.Group("/", apiKeys.Middleware())
+	protected.POST("/chat", handleChat)
+	protected.DELETE("/chat/:request_id", handleCancelChat)
+	protected.GET("/chat/:request_id/stream", handleResumeChat)
+	protected.POST("/sessions", handleCreateSession)
+	protected.GET("/sessions", handleListSessions)
+	protected.POST("/sessions/:id/chat", handleSessionChat)
+	protected.GET("/sessions/:id/messages", handleSessionMessages)
+	protected.DELETE("/sessions/:id", handleDeleteSession)
+	protected.GET("/sessions/:id/documents", handleListDocuments)
+	protected.POST("/documents", handleUploadDocument)
+	protected.DELETE("/documents/:id", handleDeleteDocument)
+	protected.GET("/ws", handleWebSocket)
+	protected.GET("/v1/models", handleOpenAIModels)
+	protected.POST("/v1/chat/completions", handleOpenAIChat)
+	protected.GET("/tools", handleTools)
+	r.GET("/usage", apiKeys.Authenticate(), handleUsage)
+	r.GET("/health", handleHealth)
+	r.GET("/openapi.yaml", handleOpenAPIYAML)
+	r.GET("/openapi.json", handleOpenAPIJSON)
+	r.GET("/docs", handleSwaggerUI)
+	r.GET("/metrics", metrics.Handler())
+	metrics.RegisterActiveTasks(func() int { return int(atomic.LoadInt64(&activeChats)) })
+
+	// 启动服务器
+	fmt.Printf("\n🌐 HTTP API 服务启动在: http://localhost:%s\n", *port)
+	fmt.Printf("📡 聊天端点: POST http://localhost:%s/chat\n", *port)
+	fmt.Printf("💬 会话管理: POST/GET http://localhost:%s/sessions\n", *port)
+	fmt.Printf("🔌 WebSocket: ws://localhost:%s/ws\n", *port)
+	fmt.Printf("🤝 OpenAI兼容: http://localhost:%s/v1/chat/completions\n", *port)
+	fmt.Printf("🛠️  工具查看: GET http://localhost:%s/tools\n", *port)
+	fmt.Printf("📊 密钥用量: GET http://localhost:%s/usage\n", *port)
+	fmt.Printf("❤️  健康检查: GET http://localhost:%s/health\n", *port)
+	fmt.Printf("📖 接口文档: http://localhost:%s/docs (OpenAPI: /openapi.yaml)\n", *port)
+	fmt.Printf("📈 监控指标: GET http://localhost:%s/metrics\n", *port)
+	fmt.Println("\n基于千问版本，完整复用SessionMCPManager和流式处理逻辑")
+
+	if err := r.Run(":" + *port); err != nil {
+		fmt.Printf("❌ 服务启动失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
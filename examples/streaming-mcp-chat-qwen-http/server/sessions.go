@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
+	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
+	"github.com/gin-gonic/gin"
+)
+
+// orgID 多租户组织ID（会话记忆按 组织ID+会话ID 隔离）
+const orgID = "ai-body-streaming-mcp-demo"
+
+// sessionIdleTTL 会话闲置超过该时长后被清理
+const sessionIdleTTL = 24 * time.Hour
+
+// Session 会话元信息（对话内容保存在智能体记忆中）
+type Session struct {
+	ID         string    `json:"session_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastActive time.Time `json:"last_active"`
+	Turns      int       `json:"turns"`
+	owner      *keyState // 创建会话的API密钥（未启用认证时为nil）
+}
+
+// SessionStore 会话管理（启用认证时每个API密钥只能访问自己创建的会话）
+type SessionStore struct {
+	sessions map[string]*Session
+	mutex    sync.RWMutex
+}
+
+// NewSessionStore 创建会话管理
+func NewSessionStore() *SessionStore {
+	return &SessionStore{sessions: make(map[string]*Session)}
+}
+
+// Create 为owner创建会话，同时清理闲置过期的会话
+func (s *SessionStore) Create(owner *keyState) (*Session, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &Session{ID: "sess_" + hex.EncodeToString(b), CreatedAt: now, LastActive: now, owner: owner}
+
+	s.mutex.Lock()
+	var expired []string
+	for id, existing := range s.sessions {
+		if now.Sub(existing.LastActive) > sessionIdleTTL {
+			expired = append(expired, id)
+			delete(s.sessions, id)
+		}
+	}
+	s.sessions[session.ID] = session
+	s.mutex.Unlock()
+
+	for _, id := range expired {
+		chatMemory.Clear(sessionContext(id))
+		documents.DeleteSession(id)
+	}
+	return session, nil
+}
+
+// Get 获取会话副本，owner不为nil时只允许同一API密钥访问
+func (s *SessionStore) Get(id string, owner *keyState) (Session, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok || (owner != nil && session.owner != owner) {
+		return Session{}, false
+	}
+	return *session, true
+}
+
+// Touch 记录一轮对话
+func (s *SessionStore) Touch(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		session.LastActive = time.Now()
+		session.Turns++
+	}
+}
+
+// List 按最近活跃倒序列出会话，owner不为nil时只列出该API密钥的会话
+func (s *SessionStore) List(owner *keyState) []Session {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if owner == nil || session.owner == owner {
+			list = append(list, *session)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastActive.After(list[j].LastActive) })
+	return list
+}
+
+// Delete 删除会话，owner不为nil时只允许同一API密钥删除
+func (s *SessionStore) Delete(id string, owner *keyState) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || (owner != nil && session.owner != owner) {
+		return false
+	}
+	delete(s.sessions, id)
+	return true
+}
+
+// sessionContext 构造携带组织ID和会话ID的上下文（智能体记忆按此隔离）
+func sessionContext(conversationID string) context.Context {
+	ctx := multitenancy.WithOrgID(context.Background(), orgID)
+	return context.WithValue(ctx, memory.ConversationIDKey, conversationID)
+}
+
+// handleCreateSession 创建会话 POST /sessions
+func handleCreateSession(c *gin.Context) {
+	session, err := sessions.Create(requestOwner(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建会话失败"})
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+// handleListSessions 列出会话 GET /sessions
+func handleListSessions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions.List(requestOwner(c))})
+}
+
+// handleSessionChat 会话内聊天 POST /sessions/:id/chat
+func handleSessionChat(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := sessions.Get(id, requestOwner(c)); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在"})
+		return
+	}
+
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求格式"})
+		return
+	}
+
+	streamChat(c, id, withDocumentContext(id, req.Message), func() { sessions.Touch(id) })
+}
+
+// handleSessionMessages 获取会话历史 GET /sessions/:id/messages
+func handleSessionMessages(c *gin.Context) {
+	id := c.Param("id")
+	session, ok := sessions.Get(id, requestOwner(c))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在"})
+		return
+	}
+
+	messages, err := chatMemory.GetMessages(sessionContext(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取会话记忆失败"})
+		return
+	}
+
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	history := make([]message, 0, len(messages))
+	for _, m := range messages {
+		history = append(history, message{Role: m.Role, Content: m.Content})
+	}
+	c.JSON(http.StatusOK, gin.H{"session": session, "messages": history})
+}
+
+// handleDeleteSession 删除会话及其记忆 DELETE /sessions/:id
+func handleDeleteSession(c *gin.Context) {
+	id := c.Param("id")
+	if !sessions.Delete(id, requestOwner(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在"})
+		return
+	}
+	chatMemory.Clear(sessionContext(id))
+	documents.DeleteSession(id)
+	c.JSON(http.StatusOK, gin.H{"deleted": id})
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/deepsage-ai/b0dy/pkg/metrics"
+)
+
+// wsUpgrader WebSocket升级器（与CORS中间件一致，允许任意来源）
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WSRequest 客户端请求帧
+type WSRequest struct {
+	Type      string `json:"type"` // chat 或 ping
+	Message   string `json:"message,omitempty"`
+	SessionID string `json:"session_id,omitempty"` // 可选，默认使用本连接的会话
+}
+
+// WSFrame 服务端事件帧
+type WSFrame struct {
+	Type    string    `json:"type"` // content | tool_call | tool_result | done | error | pong
+	Content string    `json:"content,omitempty"`
+	Tool    *ToolInfo `json:"tool,omitempty"`
+	Events  int       `json:"events,omitempty"`
+}
+
+// handleWebSocket WebSocket流式聊天 GET /ws
+// 每个连接默认是一个独立会话（连接关闭后清理记忆），请求帧中带session_id时使用指定会话
+func handleWebSocket(c *gin.Context) {
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // Upgrade已向客户端返回错误
+	}
+	defer conn.Close()
+
+	connID := fmt.Sprintf("ws-%d", time.Now().UnixNano())
+	defer chatMemory.Clear(sessionContext(connID))
+	owner := requestOwner(c)
+
+	// 连接断开时取消进行中的回复
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := make(chan WSRequest)
+	go func() {
+		defer cancel()
+		defer close(requests)
+		for {
+			var req WSRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for req := range requests {
+		switch req.Type {
+		case "ping":
+			conn.WriteJSON(WSFrame{Type: "pong"})
+		case "chat":
+			conversationID := connID
+			if req.SessionID != "" {
+				if _, ok := sessions.Get(req.SessionID, owner); !ok {
+					conn.WriteJSON(WSFrame{Type: "error", Content: "会话不存在"})
+					continue
+				}
+				conversationID = req.SessionID
+				req.Message = withDocumentContext(req.SessionID, req.Message)
+			}
+			if req.Message == "" {
+				conn.WriteJSON(WSFrame{Type: "error", Content: "message不能为空"})
+				continue
+			}
+			if err := streamWebSocket(ctx, conn, conversationID, req.Message); err != nil {
+				return
+			}
+			if req.SessionID != "" {
+				sessions.Touch(req.SessionID)
+			}
+		default:
+			conn.WriteJSON(WSFrame{Type: "error", Content: fmt.Sprintf("未知的请求类型: %s", req.Type)})
+		}
+	}
+}
+
+// streamWebSocket 处理一条消息并以事件帧推送，返回写入错误（连接已断开）
+func streamWebSocket(parent context.Context, conn *websocket.Conn, conversationID, message string) error {
+	ctx, cancel := context.WithCancel(sessionContext(conversationID))
+	defer cancel()
+	stop := context.AfterFunc(parent, cancel)
+	defer stop()
+
+	atomic.AddInt64(&activeChats, 1)
+	defer atomic.AddInt64(&activeChats, -1)
+	start := time.Now()
+
+	eventChan, err := agentInstance.RunStream(ctx, message)
+	if err != nil {
+		metrics.ObserveLLM(start, err)
+		return conn.WriteJSON(WSFrame{Type: "error", Content: fmt.Sprintf("处理失败: %v", err)})
+	}
+
+	eventCount := 0
+	var streamErr error
+	for event := range eventChan {
+		eventCount++
+
+		var frame *WSFrame
+		switch event.Type {
+		case interfaces.AgentEventToolCall, interfaces.AgentEventToolResult:
+			if event.ToolCall != nil {
+				frame = &WSFrame{Type: string(event.Type), Tool: newToolInfo(event.ToolCall)}
+			}
+		case interfaces.AgentEventError:
+			if event.Error != nil {
+				streamErr = event.Error
+				frame = &WSFrame{Type: "error", Content: event.Error.Error()}
+			}
+		default:
+			if event.Content != "" {
+				frame = &WSFrame{Type: "content", Content: event.Content}
+			}
+		}
+
+		if frame != nil {
+			if err := conn.WriteJSON(frame); err != nil {
+				metrics.ObserveLLM(start, err)
+				go func() {
+					for range eventChan { // 取消后排空剩余事件，避免生产方阻塞
+					}
+				}()
+				return err
+			}
+		}
+	}
+
+	metrics.ObserveLLM(start, streamErr)
+	return conn.WriteJSON(WSFrame{Type: "done", Events: eventCount})
+}
@@ -7,17 +7,17 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/Ingenimax/agent-sdk-go/pkg/agent"
 	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
 	"github.com/Ingenimax/agent-sdk-go/pkg/llm/openai"
 	"github.com/Ingenimax/agent-sdk-go/pkg/logging"
-	"github.com/Ingenimax/agent-sdk-go/pkg/mcp"
 	"github.com/Ingenimax/agent-sdk-go/pkg/memory"
 	"github.com/Ingenimax/agent-sdk-go/pkg/multitenancy"
 	"github.com/Ingenimax/agent-sdk-go/pkg/tools"
+
+	"github.com/deepsage-ai/b0dy/pkg/console"
+	"github.com/deepsage-ai/b0dy/pkg/session"
 )
 
 // 颜色代码用于终端输出
@@ -38,8 +38,12 @@ func main() {
 
 	// 创建千问客户端 - 通过DashScope兼容模式
 	// 千问支持Function Calling和流式传输
-	apiKey := "sk-0d8bebab081044f682fbeb6c147d8f2c" // 千问API密钥
-	modelName := "qwen-max"                         // 千问最强模型
+	apiKey := os.Getenv("DASHSCOPE_API_KEY") // 千问API密钥
+	if apiKey == "" {
+		fmt.Printf("%s错误: 请设置 DASHSCOPE_API_KEY 环境变量%s\n", ColorRed, ColorReset)
+		return
+	}
+	modelName := "qwen-max" // 千问最强模型
 	baseURL := "https://dashscope.aliyuncs.com/compatible-mode/v1"
 
 	fmt.Printf("%s使用千问模型: %s (支持工具调用)%s\n", ColorYellow, modelName, ColorReset)
@@ -62,7 +66,7 @@ func main() {
 	fmt.Printf("%s配置会话级MCP管理器: %s%s\n", ColorYellow, mcpURL, ColorReset)
 
 	// 创建会话级MCP管理器（一个会话回合 = 一个连接 + 去重）
-	sessionManager := NewSessionMCPManager(mcpURL)
+	sessionManager := session.NewSessionMCPManager(mcpURL, session.WithLogf(sessionLog))
 	mcpServers = append(mcpServers, sessionManager)
 	fmt.Printf("%s✅ 会话级MCP管理器配置完成（连接复用+去重）%s\n", ColorGreen, ColorReset)
 
@@ -182,10 +186,9 @@ func main() {
 		for event := range eventChan {
 			eventCount++
 
-			// 只显示有内容的事件，忽略调试信息
-			if event.Content != "" {
-				fmt.Print(event.Content)
-				responseText.WriteString(event.Content)
+			// 按事件类型显示：思考过程、工具调用及结果、错误和完成信息
+			if content := console.PrintEvent(os.Stdout, event); content != "" {
+				responseText.WriteString(content)
 				contentEvents++
 			}
 		}
@@ -408,178 +411,7 @@ func generateDynamicUsageExample(tool interfaces.MCPTool) {
 	}
 }
 
-// SessionMCPManager - 会话级MCP连接管理器
-// 特性：连接复用 + 健康检查
-type SessionMCPManager struct {
-	baseURL       string
-	connection    interfaces.MCPServer
-	lastActivity  time.Time    // 最后活动时间
-	sessionActive bool         // 会话是否活跃
-	mutex         sync.RWMutex // 读写锁
-}
-
-// NewSessionMCPManager 创建会话级MCP管理器
-func NewSessionMCPManager(baseURL string) *SessionMCPManager {
-	return &SessionMCPManager{
-		baseURL: baseURL,
-		mutex:   sync.RWMutex{},
-	}
-}
-
-// isConnectionAlive 检查连接是否仍然有效
-func (s *SessionMCPManager) isConnectionAlive() bool {
-	if s.connection == nil {
-		return false
-	}
-
-	// 轻量级健康检查：测试ListTools
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	_, err := s.connection.ListTools(ctx)
-	return err == nil
-}
-
-// createNewConnection 创建新的MCP连接
-func (s *SessionMCPManager) createNewConnection(ctx context.Context) (interfaces.MCPServer, error) {
-	fmt.Printf("%s[SessionMCP] 创建新连接...%s\n", ColorGreen, ColorReset)
-
-	server, err := mcp.NewHTTPServer(context.Background(), mcp.HTTPServerConfig{
-		BaseURL: s.baseURL,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("创建MCP连接失败: %w", err)
-	}
-
-	s.connection = server
-	s.sessionActive = true
-	s.lastActivity = time.Now()
-
-	return server, nil
-}
-
-// cleanupConnection 清理连接和相关状态
-func (s *SessionMCPManager) cleanupConnection() {
-	if s.connection != nil {
-		s.connection.Close()
-		s.connection = nil
-	}
-	s.sessionActive = false
-	fmt.Printf("%s[SessionMCP] 连接已清理%s\n", ColorGray, ColorReset)
-}
-
-// ensureConnection 确保有活跃的MCP连接（使用时验证）
-func (s *SessionMCPManager) ensureConnection(ctx context.Context) (interfaces.MCPServer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	// 检查现有连接的有效性
-	if s.connection != nil && s.sessionActive {
-		// 时间检查：超过2分钟自动重建
-		if time.Since(s.lastActivity) > 2*time.Minute {
-			fmt.Printf("%s[SessionMCP] 连接超时(2分钟)，重建连接%s\n", ColorYellow, ColorReset)
-			s.cleanupConnection()
-		} else {
-			// 健康检查：验证连接可用性
-			if s.isConnectionAlive() {
-				s.lastActivity = time.Now()
-				fmt.Printf("%s[SessionMCP] 复用现有连接%s\n", ColorBlue, ColorReset)
-				return s.connection, nil
-			} else {
-				fmt.Printf("%s[SessionMCP] 连接失效，重建连接%s\n", ColorYellow, ColorReset)
-				s.cleanupConnection()
-			}
-		}
-	}
-
-	// 创建新连接
-	return s.createNewConnection(ctx)
-}
-
-// Initialize 实现MCPServer接口
-func (s *SessionMCPManager) Initialize(ctx context.Context) error {
-	server, err := s.ensureConnection(ctx)
-	if err != nil {
-		return err
-	}
-	return server.Initialize(ctx)
-}
-
-// ListTools 实现MCPServer接口 - 使用会话连接
-func (s *SessionMCPManager) ListTools(ctx context.Context) ([]interfaces.MCPTool, error) {
-	server, err := s.ensureConnection(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	tools, err := server.ListTools(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	// 转换schema格式，确保LLM能正确理解工具参数
-	convertedTools := make([]interfaces.MCPTool, len(tools))
-	for i, tool := range tools {
-		convertedTools[i] = s.convertToolSchema(tool)
-	}
-
-	return convertedTools, nil
-}
-
-// convertToolSchema 将*jsonschema.Schema转换为标准的map格式
-func (s *SessionMCPManager) convertToolSchema(tool interfaces.MCPTool) interfaces.MCPTool {
-	if tool.Schema == nil {
-		return tool
-	}
-
-	// 尝试将*jsonschema.Schema转换为map[string]interface{}
-	if schemaBytes, err := json.Marshal(tool.Schema); err == nil {
-		var schemaMap map[string]interface{}
-		if err := json.Unmarshal(schemaBytes, &schemaMap); err == nil {
-			// 创建新的工具对象，使用转换后的schema
-			return interfaces.MCPTool{
-				Name:        tool.Name,
-				Description: tool.Description,
-				Schema:      schemaMap, // 使用转换后的map格式
-			}
-		}
-	}
-
-	// 如果转换失败，返回原始工具
-	return tool
-}
-
-// CallTool 实现MCPServer接口 - 会话连接复用（无缓存）
-func (s *SessionMCPManager) CallTool(ctx context.Context, name string, args interface{}) (*interfaces.MCPToolResponse, error) {
-	fmt.Printf("%s[SessionMCP] 调用工具: %s%s\n", ColorYellow, name, ColorReset)
-
-	// 获取会话连接
-	server, err := s.ensureConnection(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	// 执行工具调用
-	response, err := server.CallTool(ctx, name, args)
-	if err != nil {
-		return nil, err
-	}
-
-	// 更新活动时间
-	s.mutex.Lock()
-	s.lastActivity = time.Now()
-	s.mutex.Unlock()
-
-	fmt.Printf("%s[SessionMCP] 工具调用完成: %s%s\n", ColorGreen, name, ColorReset)
-	return response, nil
-}
-
-// Close 实现MCPServer接口 - 手动清理会话连接
-func (s *SessionMCPManager) Close() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	fmt.Printf("%s[SessionMCP] 手动关闭会话连接%s\n", ColorYellow, ColorReset)
-	s.cleanupConnection()
-	return nil
+// sessionLog 在终端输出会话级MCP连接事件
+func sessionLog(format string, args ...interface{}) {
+	fmt.Printf(ColorGray+format+ColorReset+"\n", args...)
 }
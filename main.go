@@ -1,121 +1,71 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
-	"strconv"
-	"strings"
+
+	"github.com/deepsage-ai/b0dy/examples/agent-wework/app"
+	"github.com/deepsage-ai/b0dy/examples/streaming-mcp-chat-qwen-http/server"
 )
 
 // 颜色代码
 const (
 	ColorReset  = "\033[0m"
+	ColorRed    = "\033[31m"
 	ColorGreen  = "\033[32m"
 	ColorYellow = "\033[33m"
 	ColorBlue   = "\033[34m"
 	ColorPurple = "\033[35m"
 	ColorCyan   = "\033[36m"
+	ColorGray   = "\033[90m"
 )
 
+// ai-body 统一命令行：服务和工具编译进同一个二进制，部署时无需Go工具链
+//
+//	go build -o ai-body .
+//	ai-body serve-wework -config config.yaml
 func main() {
-	fmt.Printf("%s╔══════════════════════════════════════════╗%s\n", ColorCyan, ColorReset)
-	fmt.Printf("%s║           AI-Body 示例选择器             ║%s\n", ColorCyan, ColorReset)
-	fmt.Printf("%s║        企业微信智能机器人框架            ║%s\n", ColorCyan, ColorReset)
-	fmt.Printf("%s╚══════════════════════════════════════════╝%s\n", ColorCyan, ColorReset)
-	fmt.Println()
-
-	examples := []Example{
-		{
-			Name:        "简单对话示例",
-			Description: "基础的命令行对话机器人",
-			Path:        "examples/simple-chat",
-			Color:       ColorGreen,
-		},
-		{
-			Name:        "流式对话示例",
-			Description: "实时流式传输对话，支持逐字符显示",
-			Path:        "examples/streaming-chat",
-			Color:       ColorPurple,
-		},
-		{
-			Name:        "MCP配置驱动智能体",
-			Description: "配置文件驱动的智能体，支持MCP工具集成",
-			Path:        "examples/mcp-config-agent",
-			Color:       ColorCyan,
-		},
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
 
-	for {
-		displayMenu(examples)
-
-		scanner := bufio.NewScanner(os.Stdin)
-		fmt.Printf("%s请选择示例 (1-%d) 或输入 'quit' 退出: %s", ColorBlue, len(examples), ColorReset)
-
-		if !scanner.Scan() {
-			break
-		}
-
-		input := strings.TrimSpace(scanner.Text())
-
-		if input == "quit" || input == "exit" || input == "q" {
-			fmt.Printf("%s再见！%s\n", ColorGreen, ColorReset)
-			break
-		}
-
-		choice, err := strconv.Atoi(input)
-		if err != nil || choice < 1 || choice > len(examples) {
-			fmt.Printf("%s无效选择，请输入 1-%d%s\n\n", ColorYellow, len(examples), ColorReset)
-			continue
-		}
-
-		selectedExample := examples[choice-1]
-		runExample(selectedExample)
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "serve-wework":
+		app.Serve(args)
+	case "serve-http":
+		os.Exit(server.Run(args))
+	case "chat":
+		os.Exit(runChat(args))
+	case "config":
+		os.Exit(app.RunConfig(args))
+	case "import":
+		os.Exit(app.RunImport(args))
+	case "examples":
+		os.Exit(runExampleSelector(args))
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		fmt.Printf("%s未知命令: %s%s\n\n", ColorYellow, os.Args[1], ColorReset)
+		usage()
+		os.Exit(2)
 	}
 }
 
-type Example struct {
-	Name        string
-	Description string
-	Path        string
-	Color       string
-}
-
-func displayMenu(examples []Example) {
-	fmt.Printf("%s可用示例:%s\n", ColorCyan, ColorReset)
+// usage 输出命令列表
+func usage() {
+	fmt.Println("AI-Body 企业微信智能机器人框架")
 	fmt.Println()
-
-	for i, example := range examples {
-		fmt.Printf("%s[%d] %s%s%s\n", ColorBlue, i+1, example.Color, example.Name, ColorReset)
-		fmt.Printf("    %s%s%s\n", ColorYellow, example.Description, ColorReset)
-		fmt.Printf("    路径: %s\n", example.Path)
-		fmt.Println()
-	}
-}
-
-func runExample(example Example) {
-	fmt.Printf("%s正在运行: %s%s%s\n", ColorGreen, example.Color, example.Name, ColorReset)
-	fmt.Printf("%s路径: %s%s\n", ColorBlue, example.Path, ColorReset)
-	fmt.Printf("%s%s%s\n", ColorYellow, strings.Repeat("=", 50), ColorReset)
-
-	cmd := exec.Command("go", "run", "main.go")
-	cmd.Dir = example.Path
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("%s运行示例时出错: %v%s\n", ColorYellow, err, ColorReset)
-	}
-
-	fmt.Printf("\n%s%s%s\n", ColorYellow, strings.Repeat("=", 50), ColorReset)
-	fmt.Printf("%s示例运行完毕，按 Enter 返回菜单...%s", ColorGreen, ColorReset)
-
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-
-	// 清屏
-	fmt.Print("\033[2J\033[H")
+	fmt.Println("用法: ai-body <命令> [参数]")
+	fmt.Println()
+	fmt.Println("命令:")
+	fmt.Println("  serve-wework   启动企业微信机器人服务（-config config.json，-watch=false 关闭热更新）")
+	fmt.Println("  serve-http     启动千问HTTP API服务（-port 8080）")
+	fmt.Println("  chat           终端聊天，连接HTTP API服务（-url、-api-key）")
+	fmt.Println("  config         配置工具：validate | doctor | schema")
+	fmt.Println("  import         导入历史工单到用户画像和知识库")
+	fmt.Println("  examples       示例选择器（以 go run 运行示例，需要Go工具链）")
+	fmt.Println()
+	fmt.Println("各命令的参数: ai-body <命令> -h")
 }
@@ -0,0 +1,4 @@
+// Package bot 聊天机器人回复编排的通用部分：同一会话的回复按消息顺序执行、过滤模型重复输出的内容、生成流式消息ID
+//
+// 与具体渠道、Agent实现和配置无关，agent-wework 等示例和其他项目都可以直接引用。
+package bot
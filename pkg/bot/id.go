@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// streamIDLetters 流式消息ID使用的字符（与企业微信官方Python示例的 string.ascii_letters + string.digits 一致）
+const streamIDLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// StreamIDLength 流式消息ID长度（与官方示例的 _generate_random_string(10) 一致）
+const StreamIDLength = 10
+
+// NewStreamID 生成随机的流式消息ID（同时用作回复任务ID）
+func NewStreamID() (string, error) {
+	result := make([]byte, StreamIDLength)
+	for i := range result {
+		randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(streamIDLetters))))
+		if err != nil {
+			return "", err
+		}
+		result[i] = streamIDLetters[randomIndex.Int64()]
+	}
+	return string(result), nil
+}
@@ -0,0 +1,26 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewStreamID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id, err := NewStreamID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) != StreamIDLength {
+			t.Fatalf("ID %q 长度为 %d，期望 %d", id, len(id), StreamIDLength)
+		}
+		if strings.Trim(id, streamIDLetters) != "" {
+			t.Fatalf("ID %q 包含字母和数字以外的字符", id)
+		}
+		if seen[id] {
+			t.Fatalf("ID %q 重复", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// repeatMinOverlap 判定为重复输出的最小重叠长度（字节，避免把常见的开头词当作重复）
+	repeatMinOverlap = 48
+	// RepeatWindow 与新内容比对的已输出内容长度（字节，调用Boundary时传入已输出内容末尾的这部分）
+	RepeatWindow = 4096
+)
+
+// RepeatFilter 重复内容过滤：部分模型在工具调用后（或续传时）会把已输出的段落再输出一遍，
+// 边界之后的新内容先暂存，只要仍出现在已输出的末尾部分中就继续等待，出现不同内容时丢弃与已输出内容重叠的开头
+//
+// 零值即可使用；不需要过滤时可使用nil，所有方法在nil上为空操作。
+type RepeatFilter struct {
+	previous string          // 边界前已输出内容的末尾部分
+	pending  strings.Builder // 边界后尚未确认是否重复的内容
+	active   bool            // 是否处于边界之后的比对阶段
+}
+
+// Boundary 标记边界（工具调用结束、续传开始），emitted为已输出内容的末尾部分
+func (f *RepeatFilter) Boundary(emitted string) {
+	if f == nil || f.active {
+		return // 上一边界后的内容仍在比对中，沿用原来的比对基准
+	}
+	if len(emitted) < repeatMinOverlap {
+		return
+	}
+	f.previous = emitted
+	f.active = true
+}
+
+// Filter 过滤新内容，返回可以立即输出的部分
+func (f *RepeatFilter) Filter(content string) string {
+	if f == nil || !f.active {
+		return content
+	}
+	f.pending.WriteString(content)
+	pending := f.pending.String()
+	if strings.Contains(f.previous, pending) {
+		// 重复到已输出内容的末尾：整段都是重复，之后的内容照常输出
+		if len(pending) >= repeatMinOverlap && strings.HasSuffix(f.previous, pending) {
+			f.reset()
+		}
+		return ""
+	}
+
+	// 出现不同内容：重叠部分足够长时丢弃
+	overlap := f.overlap(pending, len(pending)-len(content))
+	f.reset()
+	if overlap >= repeatMinOverlap {
+		return pending[overlap:]
+	}
+	return pending
+}
+
+// Flush 流结束时返回仍在暂存的内容（整段与已输出内容重复时丢弃）
+func (f *RepeatFilter) Flush() string {
+	if f == nil || !f.active {
+		return ""
+	}
+	pending := f.pending.String()
+	f.reset()
+	if len(pending) >= repeatMinOverlap {
+		return ""
+	}
+	return pending
+}
+
+// overlap 计算pending开头出现在已输出内容中的最大长度（前known字节已确认出现）
+func (f *RepeatFilter) overlap(pending string, known int) int {
+	n := known
+	for n < len(pending) {
+		_, size := utf8.DecodeRuneInString(pending[n:])
+		if !strings.Contains(f.previous, pending[:n+size]) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// reset 结束比对
+func (f *RepeatFilter) reset() {
+	f.previous = ""
+	f.pending.Reset()
+	f.active = false
+}
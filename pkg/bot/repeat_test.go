@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+// paragraph 超过最小重叠长度的段落
+const paragraph = "1. 打开设置，进入网络与Internet；2. 选择VPN并点击添加；3. 服务器地址填写 vpn.example.com，账号为工号。"
+
+func TestRepeatFilterDropsRepeatedParagraph(t *testing.T) {
+	f := &RepeatFilter{}
+	f.Boundary("请按以下步骤操作：" + paragraph)
+
+	// 重复内容分多块到达：比对期间暂缓输出，确认重复后丢弃
+	var out strings.Builder
+	half := strings.Index(paragraph, "3.")
+	out.WriteString(f.Filter(paragraph[:half]))
+	out.WriteString(f.Filter(paragraph[half:]))
+	out.WriteString(f.Filter("如仍无法连接，请联系IT。"))
+	out.WriteString(f.Flush())
+
+	if got := out.String(); got != "如仍无法连接，请联系IT。" {
+		t.Errorf("过滤结果 = %q，期望只保留新内容", got)
+	}
+}
+
+func TestRepeatFilterKeepsNewContent(t *testing.T) {
+	f := &RepeatFilter{}
+	f.Boundary("请按以下步骤操作：" + paragraph)
+
+	// 开头与已输出内容的重叠太短，不视为重复
+	content := "请按以下步骤检查网线。"
+	if got := f.Filter(content) + f.Flush(); got != content {
+		t.Errorf("过滤结果 = %q，期望 %q", got, content)
+	}
+}
+
+func TestRepeatFilterShortBoundaryIgnored(t *testing.T) {
+	f := &RepeatFilter{}
+	f.Boundary("好的")
+	if got := f.Filter("好的"); got != "好的" {
+		t.Errorf("已输出内容太短时不应比对，Filter() = %q", got)
+	}
+}
+
+func TestRepeatFilterNil(t *testing.T) {
+	var f *RepeatFilter
+	f.Boundary(paragraph)
+	if got := f.Filter(paragraph); got != paragraph {
+		t.Errorf("nil过滤器 Filter() = %q，期望原样返回", got)
+	}
+	if got := f.Flush(); got != "" {
+		t.Errorf("nil过滤器 Flush() = %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"sync"
+)
+
+// TurnOrder 同一会话的回复按消息到达顺序依次执行（各轮共享会话记忆，并发执行会交错写入）
+type TurnOrder struct {
+	mutex sync.Mutex
+	last  map[string]chan struct{} // 会话最后一轮回复的结束信号
+}
+
+// Turn 会话中的一轮回复
+type Turn struct {
+	order          *TurnOrder
+	conversationID string
+	prev           chan struct{} // 上一轮的结束信号（没有进行中的上一轮时为nil）
+	done           chan struct{} // 本轮的结束信号
+}
+
+// NewTurnOrder 创建会话轮次排序
+func NewTurnOrder() *TurnOrder {
+	return &TurnOrder{last: make(map[string]chan struct{})}
+}
+
+// Enter 按调用顺序登记会话的一轮回复：执行前调用Wait等待上一轮结束，结束后调用Done
+//
+// 应在收到消息时同步调用，保证执行顺序与消息到达顺序一致
+func (o *TurnOrder) Enter(conversationID string) *Turn {
+	turn := &Turn{order: o, conversationID: conversationID, done: make(chan struct{})}
+
+	o.mutex.Lock()
+	turn.prev = o.last[conversationID]
+	o.last[conversationID] = turn.done
+	o.mutex.Unlock()
+
+	return turn
+}
+
+// Ready 上一轮是否已结束（无需等待）
+func (t *Turn) Ready() bool {
+	if t.prev == nil {
+		return true
+	}
+	select {
+	case <-t.prev:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait 等待上一轮结束，ctx取消时返回其错误
+func (t *Turn) Wait(ctx context.Context) error {
+	if t.prev == nil {
+		return nil
+	}
+	select {
+	case <-t.prev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done 结束本轮：上一轮结束后才发出本轮的结束信号，保持后续轮次的顺序（等待被取消时同样需要调用）
+func (t *Turn) Done() {
+	o := t.order
+	finish := func() {
+		close(t.done)
+		o.mutex.Lock()
+		if o.last[t.conversationID] == t.done {
+			delete(o.last, t.conversationID)
+		}
+		o.mutex.Unlock()
+	}
+
+	if t.Ready() {
+		finish()
+		return
+	}
+	// 等待中被取消，上一轮仍在执行
+	go func() {
+		<-t.prev
+		finish()
+	}()
+}
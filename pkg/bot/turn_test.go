@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTurnOrderRunsInArrivalOrder(t *testing.T) {
+	order := NewTurnOrder()
+
+	var mutex sync.Mutex
+	var ran []int
+	var wg sync.WaitGroup
+	turns := make([]*Turn, 3)
+	for i := range turns {
+		turns[i] = order.Enter("single_zhangsan")
+	}
+	if !turns[0].Ready() || turns[1].Ready() {
+		t.Fatal("第一轮应可以立即执行，第二轮应等待第一轮结束")
+	}
+
+	// 倒序启动，仍按登记顺序执行
+	for i := len(turns) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			turn := turns[i]
+			defer turn.Done()
+			if err := turn.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+			mutex.Lock()
+			ran = append(ran, i)
+			mutex.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	if len(ran) != 3 || ran[0] != 0 || ran[1] != 1 || ran[2] != 2 {
+		t.Errorf("执行顺序 = %v，期望 [0 1 2]", ran)
+	}
+	waitEmpty(t, order)
+}
+
+func TestTurnOrderIndependentConversations(t *testing.T) {
+	order := NewTurnOrder()
+	first := order.Enter("single_zhangsan")
+	other := order.Enter("group_wrk123")
+	if !other.Ready() {
+		t.Error("不同会话的回复不应互相等待")
+	}
+	first.Done()
+	other.Done()
+	waitEmpty(t, order)
+}
+
+func TestTurnCancelledKeepsOrder(t *testing.T) {
+	order := NewTurnOrder()
+	first := order.Enter("single_zhangsan")
+	second := order.Enter("single_zhangsan")
+	third := order.Enter("single_zhangsan")
+
+	// 第二轮等待中被取消：立即返回，但在第一轮结束前不放行第三轮
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := second.Wait(ctx); err != context.Canceled {
+		t.Errorf("取消后 Wait() = %v，期望 context.Canceled", err)
+	}
+	second.Done()
+	time.Sleep(10 * time.Millisecond)
+	if third.Ready() {
+		t.Fatal("上一轮仍在执行时第三轮不应开始")
+	}
+
+	first.Done()
+	if err := third.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	third.Done()
+	waitEmpty(t, order)
+}
+
+// waitEmpty 等待所有轮次结束后会话记录被清理
+func waitEmpty(t *testing.T, order *TurnOrder) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		order.mutex.Lock()
+		n := len(order.last)
+		order.mutex.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("所有轮次结束后仍记录了 %d 个会话", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,63 @@
+// Package console 在终端显示Agent流式事件（streaming-chat 与 streaming-mcp-chat 系列示例共用）
+//
+// 思考过程、工具调用及结果、错误和完成信息按类型着色显示，回复正文原样输出。
+package console
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// 颜色代码用于终端输出
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorGray   = "\033[37m"
+)
+
+// toolResultMax 工具结果最多显示的字符数
+const toolResultMax = 200
+
+// PrintEvent 按事件类型将流式事件写入w，返回其中的回复正文
+func PrintEvent(w io.Writer, event interfaces.AgentStreamEvent) string {
+	switch event.Type {
+	case interfaces.AgentEventThinking:
+		if event.ThinkingStep != "" {
+			fmt.Fprintf(w, "%s%s%s", colorGray, event.ThinkingStep, colorReset)
+		}
+	case interfaces.AgentEventToolCall:
+		if event.ToolCall != nil {
+			fmt.Fprintf(w, "\n%s[调用工具 %s (%s) 参数: %s]%s\n", colorYellow, event.ToolCall.Name, event.ToolCall.Status, event.ToolCall.Arguments, colorReset)
+		}
+	case interfaces.AgentEventToolResult:
+		if event.ToolCall != nil {
+			fmt.Fprintf(w, "%s[工具结果 %s (%s) %s]%s\n", colorYellow, event.ToolCall.Name, event.ToolCall.Status, Truncate(event.ToolCall.Result, toolResultMax), colorReset)
+		}
+	case interfaces.AgentEventError:
+		if event.Error != nil {
+			fmt.Fprintf(w, "\n%s错误: %v%s\n", colorRed, event.Error, colorReset)
+		}
+	case interfaces.AgentEventComplete:
+		if len(event.Metadata) > 0 {
+			fmt.Fprintf(w, "\n%s[完成 %v]%s", colorGray, event.Metadata, colorReset)
+		}
+	default:
+		if event.Content != "" {
+			fmt.Fprint(w, event.Content)
+			return event.Content
+		}
+	}
+	return ""
+}
+
+// Truncate 截断过长的显示内容（按字符）
+func Truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,52 @@
+package console
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+func TestPrintEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   interfaces.AgentStreamEvent
+		content string // 返回的回复正文
+		output  string // 终端输出应包含的内容（空表示不输出）
+	}{
+		{"正文", interfaces.AgentStreamEvent{Type: interfaces.AgentEventContent, Content: "你好"}, "你好", "你好"},
+		{"思考过程", interfaces.AgentStreamEvent{Type: interfaces.AgentEventThinking, ThinkingStep: "分析"}, "", "分析"},
+		{"工具调用", interfaces.AgentStreamEvent{Type: interfaces.AgentEventToolCall, ToolCall: &interfaces.ToolCallEvent{
+			Name: "search", Status: "executing", Arguments: `{"q":"go"}`,
+		}}, "", `[调用工具 search (executing) 参数: {"q":"go"}]`},
+		{"工具结果", interfaces.AgentStreamEvent{Type: interfaces.AgentEventToolResult, ToolCall: &interfaces.ToolCallEvent{
+			Name: "search", Status: "completed", Result: strings.Repeat("结", toolResultMax+1),
+		}}, "", "[工具结果 search (completed) " + strings.Repeat("结", toolResultMax) + "...]"},
+		{"错误", interfaces.AgentStreamEvent{Type: interfaces.AgentEventError, Error: errors.New("超时")}, "", "错误: 超时"},
+		{"完成", interfaces.AgentStreamEvent{Type: interfaces.AgentEventComplete, Metadata: map[string]interface{}{"tokens": 3}}, "", "[完成 map[tokens:3]]"},
+		{"无内容的完成", interfaces.AgentStreamEvent{Type: interfaces.AgentEventComplete}, "", ""},
+		{"缺少工具信息", interfaces.AgentStreamEvent{Type: interfaces.AgentEventToolCall}, "", ""},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		if got := PrintEvent(&out, tt.event); got != tt.content {
+			t.Errorf("%s: PrintEvent() = %q，期望 %q", tt.name, got, tt.content)
+		}
+		if tt.output == "" && out.Len() > 0 {
+			t.Errorf("%s: 不应输出内容，实际输出 %q", tt.name, out.String())
+		}
+		if !strings.Contains(out.String(), tt.output) {
+			t.Errorf("%s: 输出 %q 不包含 %q", tt.name, out.String(), tt.output)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("中文内容", 4); got != "中文内容" {
+		t.Errorf("Truncate() = %q，未超长时应原样返回", got)
+	}
+	if got := Truncate("中文内容", 2); got != "中文..." {
+		t.Errorf("Truncate() = %q，期望 %q", got, "中文...")
+	}
+}
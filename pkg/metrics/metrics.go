@@ -0,0 +1,132 @@
+// Package metrics Prometheus指标（agent-wework与qwen-http示例共用）
+//
+// 所有指标注册到默认Registry，通过 Handler 暴露在 /metrics 路由。
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "aibody"
+
+var (
+	// HTTPRequests HTTP请求数（按路由、方法、状态码）
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP请求总数",
+	}, []string{"route", "method", "status"})
+
+	// HTTPDuration HTTP请求耗时
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// WebhookDecryptFailures 企业微信回调解密/验签失败次数
+	WebhookDecryptFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "wework_decrypt_failures_total",
+		Help:      "企业微信回调解密或验签失败次数",
+	})
+
+	// StreamRefreshes 企业微信流式消息刷新次数（用rate()计算刷新频率）
+	StreamRefreshes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "wework_stream_refreshes_total",
+		Help:      "企业微信流式消息刷新回调次数",
+	})
+
+	// LLMLatency 一次完整回复（含工具调用）的LLM耗时
+	LLMLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "LLM生成一次完整回复的耗时（秒）",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+	}, []string{"status"})
+
+	// MCPToolLatency MCP工具调用耗时
+	MCPToolLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mcp_tool_duration_seconds",
+		Help:      "MCP工具调用耗时（秒）",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+	}, []string{"server", "tool", "status"})
+)
+
+// activeTasks 活跃任务数来源（由应用注册）
+var (
+	activeTasksFuncs []func() int
+	activeTasksMutex sync.RWMutex
+)
+
+func init() {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_tasks",
+		Help:      "正在处理或等待展示的流式任务数",
+	}, func() float64 {
+		activeTasksMutex.RLock()
+		defer activeTasksMutex.RUnlock()
+
+		total := 0
+		for _, fn := range activeTasksFuncs {
+			total += fn()
+		}
+		return float64(total)
+	})
+}
+
+// RegisterActiveTasks 注册活跃任务数来源（多个来源求和）
+func RegisterActiveTasks(fn func() int) {
+	activeTasksMutex.Lock()
+	defer activeTasksMutex.Unlock()
+
+	activeTasksFuncs = append(activeTasksFuncs, fn)
+}
+
+// ObserveLLM 记录一次LLM回复耗时
+func ObserveLLM(start time.Time, err error) {
+	LLMLatency.WithLabelValues(status(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveMCPTool 记录一次MCP工具调用耗时
+func ObserveMCPTool(server, tool string, start time.Time, err error) {
+	MCPToolLatency.WithLabelValues(server, tool, status(err)).Observe(time.Since(start).Seconds())
+}
+
+// Middleware 记录HTTP请求数和耗时的gin中间件（按路由模板聚合，避免标签基数膨胀）
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		HTTPRequests.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+		HTTPDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler /metrics 路由处理器
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,429 @@
+// Package session 会话级MCP连接管理：连接复用、健康检查，以及用于离线调试的录制/回放（VCR）
+//
+// 不依赖具体示例，agent-wework 等示例和其他项目都可以直接引用。
+// 工具调用缓存、响应文本提取、日志和调用耗时回调通过 Option 按需开启。
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+	"github.com/Ingenimax/agent-sdk-go/pkg/mcp"
+)
+
+const (
+	// idleTimeout 连接空闲超过该时长后重建
+	idleTimeout = 2 * time.Minute
+	// healthCheckTimeout 复用连接前健康检查的超时
+	healthCheckTimeout = 3 * time.Second
+	// callCacheSize 工具调用结果缓存的最大条数
+	callCacheSize = 256
+)
+
+// SessionMCPManager - 会话级MCP连接管理器
+// 特性：连接复用 + 健康检查，可选工具调用缓存、响应文本提取和录制/回放
+type SessionMCPManager struct {
+	baseURL       string
+	connection    interfaces.MCPServer
+	lastActivity  time.Time    // 最后活动时间
+	sessionActive bool         // 会话是否活跃
+	mutex         sync.RWMutex // 读写锁
+
+	vcrMode  string    // VCR模式：空表示直连，record 录制，playback 回放
+	cassette *Cassette // VCR录制文件
+
+	extractContent bool                                          // 是否把MCP响应内容提取为纯文本
+	cacheTTL       time.Duration                                 // 工具调用缓存时长（0为不缓存）
+	cache          map[string]cachedCall                         // 工具名+参数 -> 调用结果
+	cacheMutex     sync.Mutex                                    // 缓存锁
+	logf           func(format string, args ...interface{})      // 连接事件日志（nil不输出）
+	observe        func(tool string, start time.Time, err error) // 工具调用完成回调（nil不回调）
+}
+
+// cachedCall 缓存的工具调用结果
+type cachedCall struct {
+	response *interfaces.MCPToolResponse
+	expires  time.Time
+}
+
+// Option SessionMCPManager的可选配置
+type Option func(*SessionMCPManager)
+
+// WithCallCache 缓存工具调用结果：相同工具和参数在ttl内直接返回上次的成功结果（适合查询类工具）
+func WithCallCache(ttl time.Duration) Option {
+	return func(s *SessionMCPManager) {
+		s.cacheTTL = ttl
+	}
+}
+
+// WithContentExtraction 把MCP响应中的 [{"type":"text","text":...}] 内容提取为纯文本，便于LLM直接使用
+func WithContentExtraction() Option {
+	return func(s *SessionMCPManager) {
+		s.extractContent = true
+	}
+}
+
+// WithLogf 输出连接创建、复用、重建和工具调用等事件
+func WithLogf(logf func(format string, args ...interface{})) Option {
+	return func(s *SessionMCPManager) {
+		s.logf = logf
+	}
+}
+
+// WithCallObserver 每次真实调用工具（不含缓存命中和回放）后回调，用于记录耗时指标
+func WithCallObserver(observe func(tool string, start time.Time, err error)) Option {
+	return func(s *SessionMCPManager) {
+		s.observe = observe
+	}
+}
+
+// NewSessionMCPManager 创建会话级MCP管理器
+func NewSessionMCPManager(baseURL string, opts ...Option) *SessionMCPManager {
+	s := &SessionMCPManager{
+		baseURL: baseURL,
+		mutex:   sync.RWMutex{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cacheTTL > 0 {
+		s.cache = make(map[string]cachedCall)
+	}
+	return s
+}
+
+// log 输出事件日志
+func (s *SessionMCPManager) log(format string, args ...interface{}) {
+	if s.logf != nil {
+		s.logf(format, args...)
+	}
+}
+
+// EnableVCR 启用录制/回放模式（mode为 record 或 playback）
+func (s *SessionMCPManager) EnableVCR(mode, cassettePath string) error {
+	if mode != VCRRecord && mode != VCRPlayback {
+		return fmt.Errorf("不支持的VCR模式: %s（支持record/playback）", mode)
+	}
+
+	cassette, err := LoadCassette(cassettePath, s.baseURL)
+	if err != nil {
+		return err
+	}
+	if mode == VCRPlayback && len(cassette.Tools) == 0 {
+		return fmt.Errorf("录制文件不存在或没有工具列表: %s", cassettePath)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.vcrMode = mode
+	s.cassette = cassette
+	return nil
+}
+
+// isConnectionAlive 检查连接是否仍然有效
+func (s *SessionMCPManager) isConnectionAlive() bool {
+	if s.connection == nil {
+		return false
+	}
+
+	// 轻量级健康检查：测试ListTools
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	_, err := s.connection.ListTools(ctx)
+	return err == nil
+}
+
+// IsAlive 当前是否有可用的会话连接（用于健康检查接口，不会创建新连接）
+func (s *SessionMCPManager) IsAlive() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.isConnectionAlive()
+}
+
+// createNewConnection 创建新的MCP连接
+func (s *SessionMCPManager) createNewConnection(ctx context.Context) (interfaces.MCPServer, error) {
+	s.log("[SessionMCP] 创建新连接: %s", s.baseURL)
+
+	server, err := mcp.NewHTTPServer(context.Background(), mcp.HTTPServerConfig{
+		BaseURL: s.baseURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建MCP连接失败 (%s): %w", s.baseURL, err)
+	}
+
+	s.connection = server
+	s.sessionActive = true
+	s.lastActivity = time.Now()
+
+	return server, nil
+}
+
+// cleanupConnection 清理连接和相关状态
+func (s *SessionMCPManager) cleanupConnection() {
+	if s.connection != nil {
+		s.connection.Close()
+		s.connection = nil
+	}
+	s.sessionActive = false
+	s.log("[SessionMCP] 连接已清理")
+}
+
+// ensureConnection 确保有活跃的MCP连接（使用时验证）
+func (s *SessionMCPManager) ensureConnection(ctx context.Context) (interfaces.MCPServer, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 检查现有连接的有效性
+	if s.connection != nil && s.sessionActive {
+		// 时间检查：超过2分钟自动重建
+		if time.Since(s.lastActivity) > idleTimeout {
+			s.log("[SessionMCP] 连接超时(2分钟)，重建连接")
+			s.cleanupConnection()
+		} else {
+			// 健康检查：验证连接可用性
+			if s.isConnectionAlive() {
+				s.lastActivity = time.Now()
+				s.log("[SessionMCP] 复用现有连接")
+				return s.connection, nil
+			} else {
+				s.log("[SessionMCP] 连接失效，重建连接")
+				s.cleanupConnection()
+			}
+		}
+	}
+
+	// 创建新连接
+	return s.createNewConnection(ctx)
+}
+
+// Initialize 实现MCPServer接口
+func (s *SessionMCPManager) Initialize(ctx context.Context) error {
+	if s.vcrMode == VCRPlayback {
+		return nil
+	}
+	server, err := s.ensureConnection(ctx)
+	if err != nil {
+		return err
+	}
+	return server.Initialize(ctx)
+}
+
+// ListTools 实现MCPServer接口 - 使用会话连接
+func (s *SessionMCPManager) ListTools(ctx context.Context) ([]interfaces.MCPTool, error) {
+	if s.vcrMode == VCRPlayback {
+		return s.cassette.ListTools()
+	}
+
+	server, err := s.ensureConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tools, err := server.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 转换schema格式，确保LLM能正确理解工具参数
+	convertedTools := make([]interfaces.MCPTool, len(tools))
+	for i, tool := range tools {
+		convertedTools[i] = s.convertToolSchema(tool)
+	}
+
+	if s.vcrMode == VCRRecord {
+		if err := s.cassette.RecordTools(convertedTools); err != nil {
+			fmt.Printf("⚠️  MCP录制工具列表失败: %v\n", err)
+		}
+	}
+
+	return convertedTools, nil
+}
+
+// convertToolSchema 将*jsonschema.Schema转换为标准的map格式
+func (s *SessionMCPManager) convertToolSchema(tool interfaces.MCPTool) interfaces.MCPTool {
+	if tool.Schema == nil {
+		return tool
+	}
+
+	// 尝试将*jsonschema.Schema转换为map[string]interface{}
+	if schemaBytes, err := json.Marshal(tool.Schema); err == nil {
+		var schemaMap map[string]interface{}
+		if err := json.Unmarshal(schemaBytes, &schemaMap); err == nil {
+			// 创建新的工具对象，使用转换后的schema
+			return interfaces.MCPTool{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Schema:      schemaMap, // 使用转换后的map格式
+			}
+		}
+	}
+
+	// 如果转换失败，返回原始工具
+	return tool
+}
+
+// CallTool 实现MCPServer接口 - 会话连接复用（启用缓存时相同调用直接返回上次结果）
+func (s *SessionMCPManager) CallTool(ctx context.Context, name string, args interface{}) (*interfaces.MCPToolResponse, error) {
+	s.log("[SessionMCP] 调用工具: %s", name)
+
+	key, cacheable := s.cacheKey(name, args)
+	if cacheable {
+		if response, ok := s.cachedResponse(key); ok {
+			s.log("[SessionMCP] 工具调用命中缓存: %s", name)
+			return response, nil
+		}
+	}
+
+	var response *interfaces.MCPToolResponse
+	var err error
+
+	if s.vcrMode == VCRPlayback {
+		// 回放模式：直接返回录制结果
+		response, err = s.cassette.Replay(name, args)
+	} else {
+		response, err = s.callLive(ctx, name, args)
+		if s.vcrMode == VCRRecord {
+			if recordErr := s.cassette.RecordCall(name, args, response, err); recordErr != nil {
+				fmt.Printf("⚠️  MCP录制工具调用失败: %v\n", recordErr)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// MCP协议返回的Content可能是JSON数组格式：[{"type":"text","text":"actual content"}]
+	// 启用内容提取时取出其中的文本，让agent-sdk-go能正确处理
+	if s.extractContent && response != nil && response.Content != nil {
+		response.Content = s.extractTextFromMCPContent(response.Content)
+	}
+
+	if cacheable && response != nil && !response.IsError {
+		s.storeResponse(key, response)
+	}
+
+	s.log("[SessionMCP] 工具调用完成: %s", name)
+	return response, nil
+}
+
+// callLive 通过会话连接调用真实工具
+func (s *SessionMCPManager) callLive(ctx context.Context, name string, args interface{}) (*interfaces.MCPToolResponse, error) {
+	start := time.Now()
+
+	// 获取会话连接
+	server, err := s.ensureConnection(ctx)
+	if err != nil {
+		s.observeCall(name, start, err)
+		return nil, err
+	}
+
+	// 执行工具调用
+	response, err := server.CallTool(ctx, name, args)
+	s.observeCall(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	// 更新活动时间
+	s.mutex.Lock()
+	s.lastActivity = time.Now()
+	s.mutex.Unlock()
+
+	return response, nil
+}
+
+// observeCall 触发工具调用完成回调
+func (s *SessionMCPManager) observeCall(name string, start time.Time, err error) {
+	if s.observe != nil {
+		s.observe(name, start, err)
+	}
+}
+
+// cacheKey 工具调用的缓存键（未启用缓存或参数无法序列化时不缓存）
+func (s *SessionMCPManager) cacheKey(name string, args interface{}) (string, bool) {
+	if s.cache == nil {
+		return "", false
+	}
+	raw, err := canonicalArgs(args)
+	if err != nil {
+		return "", false
+	}
+	return name + "\x00" + string(raw), true
+}
+
+// cachedResponse 获取未过期的缓存结果（返回副本，调用方可以改写）
+func (s *SessionMCPManager) cachedResponse(key string) (*interfaces.MCPToolResponse, bool) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
+	entry, ok := s.cache[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	response := *entry.response
+	return &response, true
+}
+
+// storeResponse 缓存调用结果（超出容量时先清理过期记录，仍然超出则清空）
+func (s *SessionMCPManager) storeResponse(key string, response *interfaces.MCPToolResponse) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
+	now := time.Now()
+	if len(s.cache) >= callCacheSize {
+		for k, entry := range s.cache {
+			if now.After(entry.expires) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= callCacheSize {
+			s.cache = make(map[string]cachedCall)
+		}
+	}
+	stored := *response
+	s.cache[key] = cachedCall{response: &stored, expires: now.Add(s.cacheTTL)}
+}
+
+// extractTextFromMCPContent 从MCP响应中提取文本内容
+func (s *SessionMCPManager) extractTextFromMCPContent(content interface{}) interface{} {
+	// 尝试将content转换为[]interface{}（JSON数组）
+	if arr, ok := content.([]interface{}); ok && len(arr) > 0 {
+		// 遍历数组，查找包含text字段的元素
+		var textParts []string
+		for _, item := range arr {
+			if obj, ok := item.(map[string]interface{}); ok {
+				// 检查是否有type="text"和text字段
+				if typeVal, hasType := obj["type"].(string); hasType && typeVal == "text" {
+					if textVal, hasText := obj["text"].(string); hasText {
+						textParts = append(textParts, textVal)
+					}
+				}
+			}
+		}
+
+		// 如果找到文本内容，返回拼接后的字符串
+		if len(textParts) > 0 {
+			return strings.Join(textParts, "\n")
+		}
+	}
+
+	// 如果不是MCP格式，返回原始内容
+	return content
+}
+
+// Close 实现MCPServer接口 - 手动清理会话连接
+func (s *SessionMCPManager) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.log("[SessionMCP] 手动关闭会话连接")
+	s.cleanupConnection()
+	return nil
+}
@@ -0,0 +1,257 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// newPlaybackManager 创建回放模式的管理器：录制文件中依次包含给定的调用记录
+func newPlaybackManager(t *testing.T, interactions []Interaction, opts ...Option) *SessionMCPManager {
+	t.Helper()
+
+	for i := range interactions {
+		if interactions[i].Args == nil {
+			interactions[i].Args = json.RawMessage(`{}`)
+		}
+	}
+	cassette := &Cassette{
+		BaseURL:      "http://mcp.invalid",
+		Tools:        []interfaces.MCPTool{{Name: "search"}},
+		Interactions: interactions,
+	}
+	data, err := json.Marshal(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSessionMCPManager(cassette.BaseURL, opts...)
+	if err := s.EnableVCR(VCRPlayback, path); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// textResponse 录制的MCP文本响应（与真实服务器返回的JSON结构一致）
+func textResponse(texts ...string) *interfaces.MCPToolResponse {
+	content := make([]interface{}, 0, len(texts))
+	for _, text := range texts {
+		content = append(content, map[string]interface{}{"type": "text", "text": text})
+	}
+	return &interfaces.MCPToolResponse{Content: content}
+}
+
+func TestOptions(t *testing.T) {
+	s := NewSessionMCPManager("http://mcp.invalid")
+	if s.cache != nil || s.extractContent || s.logf != nil || s.observe != nil {
+		t.Fatal("未传入选项时不应启用缓存、内容提取、日志和回调")
+	}
+	if _, ok := s.cacheKey("search", map[string]interface{}{}); ok {
+		t.Error("未启用缓存时不应生成缓存键")
+	}
+
+	var logged []string
+	s = NewSessionMCPManager("http://mcp.invalid",
+		WithCallCache(time.Minute),
+		WithContentExtraction(),
+		WithLogf(func(format string, args ...interface{}) { logged = append(logged, format) }),
+		WithCallObserver(func(string, time.Time, error) {}),
+	)
+	if s.cache == nil || s.cacheTTL != time.Minute {
+		t.Error("WithCallCache 未启用缓存")
+	}
+	if !s.extractContent {
+		t.Error("WithContentExtraction 未启用内容提取")
+	}
+	if s.observe == nil {
+		t.Error("WithCallObserver 未设置回调")
+	}
+	s.Close()
+	if len(logged) == 0 {
+		t.Error("WithLogf 未收到事件日志")
+	}
+
+	if s := NewSessionMCPManager("http://mcp.invalid", WithCallCache(0)); s.cache != nil {
+		t.Error("ttl为0时不应启用缓存")
+	}
+}
+
+func TestEnableVCRErrors(t *testing.T) {
+	s := NewSessionMCPManager("http://mcp.invalid")
+	if err := s.EnableVCR("replay", filepath.Join(t.TempDir(), "x.json")); err == nil {
+		t.Error("不支持的VCR模式应返回错误")
+	}
+	if err := s.EnableVCR(VCRPlayback, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("回放不存在的录制文件应返回错误")
+	}
+}
+
+func TestPlaybackWithoutCache(t *testing.T) {
+	observed := 0
+	s := newPlaybackManager(t, []Interaction{
+		{Tool: "search", Response: textResponse("first")},
+		{Tool: "search", Response: textResponse("second")},
+	}, WithContentExtraction(), WithCallObserver(func(string, time.Time, error) { observed++ }))
+
+	for _, want := range []string{"first", "second", "second"} {
+		response, err := s.CallTool(context.Background(), "search", map[string]interface{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Content != want {
+			t.Errorf("CallTool() = %v，期望 %q", response.Content, want)
+		}
+	}
+	if observed != 0 {
+		t.Errorf("回放调用触发了 %d 次回调，期望 0", observed)
+	}
+
+	if _, err := s.CallTool(context.Background(), "unknown", nil); err == nil {
+		t.Error("没有录制结果的调用应返回错误")
+	}
+}
+
+func TestCallCacheHit(t *testing.T) {
+	s := newPlaybackManager(t, []Interaction{
+		{Tool: "search", Args: json.RawMessage(`{"a":1,"b":2}`), Response: textResponse("first")},
+		{Tool: "search", Args: json.RawMessage(`{"a":1,"b":2}`), Response: textResponse("second")},
+	}, WithCallCache(time.Minute), WithContentExtraction())
+
+	response, err := s.CallTool(context.Background(), "search", map[string]interface{}{"a": 1, "b": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Content != "first" {
+		t.Fatalf("CallTool() = %v，期望 %q", response.Content, "first")
+	}
+	// 调用方改写返回结果不影响缓存
+	response.Content = "changed"
+
+	// 字符串形式的JSON参数和不同键顺序命中同一缓存
+	for _, args := range []interface{}{
+		map[string]interface{}{"b": 2, "a": 1},
+		`{"b":2,"a":1}`,
+		json.RawMessage(`{"a":1,"b":2}`),
+	} {
+		response, err := s.CallTool(context.Background(), "search", args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Content != "first" {
+			t.Errorf("参数 %v 未命中缓存: %v", args, response.Content)
+		}
+	}
+}
+
+func TestCallCacheExpiry(t *testing.T) {
+	s := newPlaybackManager(t, []Interaction{
+		{Tool: "search", Response: textResponse("first")},
+		{Tool: "search", Response: textResponse("second")},
+	}, WithCallCache(time.Minute), WithContentExtraction())
+
+	if _, err := s.CallTool(context.Background(), "search", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	for key, entry := range s.cache {
+		entry.expires = time.Now().Add(-time.Second)
+		s.cache[key] = entry
+	}
+
+	response, err := s.CallTool(context.Background(), "search", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Content != "second" {
+		t.Errorf("缓存过期后 CallTool() = %v，期望重新调用得到 %q", response.Content, "second")
+	}
+}
+
+func TestCallCacheSkipsErrors(t *testing.T) {
+	failed := textResponse("failed")
+	failed.IsError = true
+	s := newPlaybackManager(t, []Interaction{
+		{Tool: "search", Error: "connection reset"},
+		{Tool: "search", Response: failed},
+		{Tool: "search", Response: textResponse("ok")},
+	}, WithCallCache(time.Minute), WithContentExtraction())
+
+	if _, err := s.CallTool(context.Background(), "search", map[string]interface{}{}); err == nil {
+		t.Fatal("录制的调用错误应返回错误")
+	}
+	response, err := s.CallTool(context.Background(), "search", map[string]interface{}{})
+	if err != nil || !response.IsError {
+		t.Fatalf("CallTool() = %v, %v，期望工具错误响应", response, err)
+	}
+	response, err = s.CallTool(context.Background(), "search", map[string]interface{}{})
+	if err != nil || response.Content != "ok" {
+		t.Errorf("CallTool() = %v, %v，期望错误结果未被缓存", response, err)
+	}
+}
+
+func TestCallCacheCapacity(t *testing.T) {
+	s := NewSessionMCPManager("http://mcp.invalid", WithCallCache(time.Minute))
+	for i := 0; i < callCacheSize; i++ {
+		key, _ := s.cacheKey("search", i)
+		s.storeResponse(key, &interfaces.MCPToolResponse{Content: i})
+	}
+	if len(s.cache) != callCacheSize {
+		t.Fatalf("缓存记录数 = %d，期望 %d", len(s.cache), callCacheSize)
+	}
+
+	// 已满且没有过期记录时清空后再写入
+	key, _ := s.cacheKey("search", "new")
+	s.storeResponse(key, &interfaces.MCPToolResponse{Content: "new"})
+	if len(s.cache) != 1 {
+		t.Errorf("超出容量后缓存记录数 = %d，期望 1", len(s.cache))
+	}
+
+	// 已满时优先清理过期记录，保留未过期的
+	for i := 0; i < callCacheSize-1; i++ {
+		key, _ := s.cacheKey("search", i)
+		s.cache[key] = cachedCall{response: &interfaces.MCPToolResponse{}, expires: time.Now().Add(-time.Second)}
+	}
+	key, _ = s.cacheKey("search", "newer")
+	s.storeResponse(key, &interfaces.MCPToolResponse{Content: "newer"})
+	if len(s.cache) != 2 {
+		t.Errorf("清理过期记录后缓存记录数 = %d，期望 2", len(s.cache))
+	}
+	if _, ok := s.cachedResponse(key); !ok {
+		t.Error("新写入的结果未命中缓存")
+	}
+}
+
+func TestExtractTextFromMCPContent(t *testing.T) {
+	s := NewSessionMCPManager("http://mcp.invalid")
+	tests := []struct {
+		name    string
+		content interface{}
+		want    interface{}
+	}{
+		{"多段文本", textResponse("a", "b").Content, "a\nb"},
+		{"忽略非文本", []interface{}{
+			map[string]interface{}{"type": "image", "data": "..."},
+			map[string]interface{}{"type": "text", "text": "a"},
+		}, "a"},
+		{"普通字符串", "plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := s.extractTextFromMCPContent(tt.content); got != tt.want {
+			t.Errorf("%s: extractTextFromMCPContent() = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 没有文本内容时原样返回
+	images := []interface{}{map[string]interface{}{"type": "image"}}
+	if got, ok := s.extractTextFromMCPContent(images).([]interface{}); !ok || len(got) != 1 {
+		t.Errorf("没有文本内容时应原样返回: %v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Ingenimax/agent-sdk-go/pkg/interfaces"
+)
+
+// VCR模式
+const (
+	VCRRecord   = "record"   // 转发到真实服务器并录制工具列表和调用结果
+	VCRPlayback = "playback" // 不连接服务器，回放录制的结果
+)
+
+// Interaction 一次录制的工具调用
+type Interaction struct {
+	Tool       string                      `json:"tool"`
+	Args       json.RawMessage             `json:"args"`
+	Response   *interfaces.MCPToolResponse `json:"response,omitempty"`
+	Error      string                      `json:"error,omitempty"`
+	RecordedAt time.Time                   `json:"recorded_at"`
+}
+
+// Cassette 录制文件：工具列表 + 调用记录
+type Cassette struct {
+	BaseURL      string               `json:"base_url"`
+	Tools        []interfaces.MCPTool `json:"tools"`
+	Interactions []Interaction        `json:"interactions"`
+
+	path   string
+	played map[int]bool // 回放时已使用的记录
+	mutex  sync.Mutex
+}
+
+// LoadCassette 加载录制文件，不存在时返回空录制
+func LoadCassette(path, baseURL string) (*Cassette, error) {
+	c := &Cassette{BaseURL: baseURL, path: path, played: make(map[int]bool)}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("加载MCP录制文件失败: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("加载MCP录制文件失败: %w", err)
+		}
+	}
+
+	// 文件中的参数是格式化后的JSON，统一为规范形式以便匹配
+	for i := range c.Interactions {
+		if args, err := canonicalArgs(c.Interactions[i].Args); err == nil {
+			c.Interactions[i].Args = args
+		}
+	}
+	return c, nil
+}
+
+// RecordTools 录制工具列表
+func (c *Cassette) RecordTools(tools []interfaces.MCPTool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.Tools = tools
+	return c.saveLocked()
+}
+
+// RecordCall 录制一次工具调用
+func (c *Cassette) RecordCall(tool string, args interface{}, response *interfaces.MCPToolResponse, callErr error) error {
+	raw, err := canonicalArgs(args)
+	if err != nil {
+		return err
+	}
+
+	interaction := Interaction{
+		Tool:       tool,
+		Args:       raw,
+		RecordedAt: time.Now(),
+	}
+	if response != nil {
+		// 保存副本：调用方随后会改写响应内容
+		recorded := *response
+		interaction.Response = &recorded
+	}
+	if callErr != nil {
+		interaction.Error = callErr.Error()
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.Interactions = append(c.Interactions, interaction)
+	return c.saveLocked()
+}
+
+// Replay 查找匹配的录制结果：按工具名和参数匹配，优先使用尚未回放过的记录，
+// 全部回放过后重复使用最后一条匹配记录
+func (c *Cassette) Replay(tool string, args interface{}) (*interfaces.MCPToolResponse, error) {
+	raw, err := canonicalArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	match := -1
+	for i, interaction := range c.Interactions {
+		if interaction.Tool != tool || string(interaction.Args) != string(raw) {
+			continue
+		}
+		match = i
+		if !c.played[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("MCP回放: 没有工具 %s 参数 %s 的录制结果", tool, string(raw))
+	}
+
+	c.played[match] = true
+	interaction := c.Interactions[match]
+	if interaction.Error != "" {
+		return nil, fmt.Errorf("%s", interaction.Error)
+	}
+	if interaction.Response == nil {
+		return nil, nil
+	}
+
+	// 返回副本，避免调用方修改录制内容
+	response := *interaction.Response
+	return &response, nil
+}
+
+// ListTools 回放工具列表
+func (c *Cassette) ListTools() ([]interfaces.MCPTool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.Tools == nil {
+		return nil, fmt.Errorf("MCP回放: 录制文件中没有工具列表: %s", c.path)
+	}
+	return c.Tools, nil
+}
+
+// saveLocked 持久化录制文件（调用方需持有锁，先写临时文件再重命名）
+func (c *Cassette) saveLocked() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化MCP录制文件失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("创建录制文件目录失败: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入MCP录制文件失败: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// canonicalArgs 将参数序列化为规范JSON（map按键排序），用于匹配
+func canonicalArgs(args interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("序列化工具参数失败: %w", err)
+	}
+
+	// 字符串形式的JSON参数与对象参数视为相同
+	var decoded interface{}
+	if raw, ok := args.(json.RawMessage); ok && json.Unmarshal(raw, &decoded) == nil {
+		data, _ = json.Marshal(decoded)
+	} else if s, ok := args.(string); ok && json.Unmarshal([]byte(s), &decoded) == nil {
+		data, _ = json.Marshal(decoded)
+	} else if json.Unmarshal(data, &decoded) == nil {
+		data, _ = json.Marshal(decoded)
+	}
+	return data, nil
+}
@@ -0,0 +1,323 @@
+package stream
+
+import (
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Buffer 流式内容缓冲区 - 累积模式：Agent持续追加，企业微信每次刷新取回全部已生成内容
+//
+// 内容块追加到同一块连续内存，ends记录每个块的结束位置；展示文本按内容长度缓存，
+// 刷新请求之间没有新内容时不再重复拼接和合并think标签。
+//
+// 读取进度按读取者（Reader）分别记录：GetAccumulated 等方法使用内置的展示读取者，
+// 其他观察方（重放、管理端实时查看等）通过 NewReader 创建各自的读取者，互不影响。
+type Buffer struct {
+	data       []byte        // 所有内容块依次拼接（累积存储，只在Truncate时回退）
+	ends       []int         // 每个内容块在data中的结束位置
+	rendered   string        // 最近一次构建的展示文本（已合并think标签，不含临时片段）
+	renderedAt int           // rendered对应的data长度（-1表示尚未构建）
+	ephemeral  string        // 临时片段（进度提示），追加在内容末尾展示，有新内容时被替换
+	mutex      sync.RWMutex  // 线程安全锁
+	aiFinished bool          // AI是否完成生成
+	display    Reader        // 展示读取者（企业微信刷新，模拟Python的current_step）
+	changed    chan struct{} // 下次变化时关闭，通知等待中的读取者（没有等待者时为nil）
+	cuts       []int         // 每次Truncate保留的内容块数，读取者据此回退读取进度
+	lastUpdate time.Time     // 最后更新时间
+}
+
+// Reader 缓冲区的读取者：各自记录读取进度，同一缓冲区的多个读取者互不影响
+//
+// 读取者的方法与缓冲区共用同一把锁，可在多个协程中使用；同一读取者的进度在读取时原子更新，
+// 返回的内容与完成状态来自同一时刻，不会出现内容较旧却已标记完成的情况。
+type Reader struct {
+	sb   *Buffer
+	next int // 下一个未读取的内容块索引
+	cuts int // 已处理的Truncate次数
+}
+
+// retainBytes 归还到复用池的缓冲区最多保留的内存（更大的直接丢弃）
+const retainBytes = 256 * 1024
+
+// bufferPool 复用临时缓冲区（如翻译、审核前Agent输出的中转）
+var bufferPool = sync.Pool{
+	New: func() interface{} { return NewBuffer() },
+}
+
+// NewBuffer 创建流式缓冲区
+func NewBuffer() *Buffer {
+	sb := &Buffer{
+		renderedAt: -1,
+		lastUpdate: time.Now(),
+	}
+	sb.display.sb = sb
+	return sb
+}
+
+// AcquireBuffer 从复用池获取空的临时缓冲区，用完后调用Release归还
+func AcquireBuffer() *Buffer {
+	sb := bufferPool.Get().(*Buffer)
+	sb.lastUpdate = time.Now()
+	return sb
+}
+
+// Release 清空缓冲区并归还复用池（归还后不能再使用，长期持有的缓冲区不要归还）
+func (sb *Buffer) Release() {
+	sb.mutex.Lock()
+	if cap(sb.data) > retainBytes {
+		sb.mutex.Unlock()
+		return
+	}
+	sb.data = sb.data[:0]
+	sb.ends = sb.ends[:0]
+	sb.rendered = ""
+	sb.renderedAt = -1
+	sb.ephemeral = ""
+	sb.aiFinished = false
+	sb.display.next = 0
+	sb.display.cuts = 0
+	sb.cuts = sb.cuts[:0]
+	sb.notify()
+	sb.mutex.Unlock()
+
+	bufferPool.Put(sb)
+}
+
+// Push AI生产内容到缓冲区
+func (sb *Buffer) Push(content string) {
+	if content == "" {
+		return
+	}
+
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	sb.data = append(sb.data, content...)
+	sb.ends = append(sb.ends, len(sb.data))
+	sb.ephemeral = ""
+	sb.lastUpdate = time.Now()
+	sb.notify()
+}
+
+// Chunks 已追加的内容块数（可作为Truncate的回退位置）
+func (sb *Buffer) Chunks() int {
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+
+	return len(sb.ends)
+}
+
+// Truncate 丢弃第chunks个内容块之后的内容（如重试前撤回失败尝试已输出的思考过程），
+// 超出新末尾的读取进度回退到末尾
+func (sb *Buffer) Truncate(chunks int) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	if chunks < 0 || chunks >= len(sb.ends) {
+		return
+	}
+	size := 0
+	if chunks > 0 {
+		size = sb.ends[chunks-1]
+	}
+	sb.data = sb.data[:size]
+	sb.ends = sb.ends[:chunks]
+	sb.renderedAt = -1
+	sb.cuts = append(sb.cuts, chunks)
+	sb.lastUpdate = time.Now()
+	sb.notify()
+}
+
+// SetEphemeral 设置临时片段（为空时清除），不计入最终回复
+func (sb *Buffer) SetEphemeral(content string) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	sb.ephemeral = content
+	sb.lastUpdate = time.Now()
+	sb.notify()
+}
+
+// GetAccumulated 获取累积内容（优化版本：一次性返回所有已生成内容），通过展示读取者读取
+func (sb *Buffer) GetAccumulated() (string, bool) {
+	return sb.display.Accumulated()
+}
+
+// NewReader 创建从第一个内容块开始读取的读取者（可重放已生成的全部内容）
+func (sb *Buffer) NewReader() *Reader {
+	return &Reader{sb: sb}
+}
+
+// Changed 返回在缓冲区下次变化（新内容、临时片段变化、完成或归还）时关闭的通道，
+// 读取者可据此等待新内容而不必轮询；每次等待前需重新获取，并先获取通道再读取，避免错过两者之间的变化
+func (sb *Buffer) Changed() <-chan struct{} {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	if sb.changed == nil {
+		sb.changed = make(chan struct{})
+	}
+	return sb.changed
+}
+
+// notify 通知等待中的读取者（调用方需持有写锁）
+func (sb *Buffer) notify() {
+	if sb.changed != nil {
+		close(sb.changed)
+		sb.changed = nil
+	}
+}
+
+// Accumulated 获取全部已生成内容的展示文本（合并think标签并追加临时片段），读取进度更新到末尾；
+// finished表示AI已完成且全部内容已读取
+func (r *Reader) Accumulated() (content string, finished bool) {
+	sb := r.sb
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	// 一次性更新到当前所有内容块，而不是每次只前进一块
+	r.sync()
+	if r.next < len(sb.ends) {
+		r.next = len(sb.ends)
+		sb.lastUpdate = time.Now()
+	}
+	return sb.render(r.next), sb.aiFinished && r.next >= len(sb.ends)
+}
+
+// Read 获取上次读取之后新增的原始内容（不合并think标签，不含临时片段），读取进度更新到末尾；
+// finished表示AI已完成且全部内容已读取
+func (r *Reader) Read() (content string, finished bool) {
+	sb := r.sb
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	r.sync()
+	start := 0
+	if r.next > 0 {
+		start = sb.ends[r.next-1]
+	}
+	r.next = len(sb.ends)
+	return string(sb.data[start:]), sb.aiFinished
+}
+
+// Seek 将读取进度移动到第chunk个内容块（0表示从头重放，超出范围时取最近的有效位置）
+func (r *Reader) Seek(chunk int) {
+	sb := r.sb
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	r.next = min(max(chunk, 0), len(sb.ends))
+	r.cuts = len(sb.cuts)
+}
+
+// Unread 尚未读取的内容块数
+func (r *Reader) Unread() int {
+	sb := r.sb
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+
+	return len(sb.ends) - r.position()
+}
+
+// position 读取进度（回退到上次读取之后各次Truncate保留的位置，调用方需持有锁）
+func (r *Reader) position() int {
+	next := r.next
+	for _, chunks := range r.sb.cuts[r.cuts:] {
+		next = min(next, chunks)
+	}
+	return next
+}
+
+// sync 按position更新读取进度（调用方需持有写锁）
+func (r *Reader) sync() {
+	r.next = r.position()
+	r.cuts = len(r.sb.cuts)
+}
+
+// Peek 获取当前应展示的内容（与GetAccumulated一致，但不影响展示进度）
+func (sb *Buffer) Peek() string {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	return sb.render(len(sb.ends))
+}
+
+// render 构建前count个内容块的展示文本（调用方需持有写锁，内容未变化时复用上次结果）
+func (sb *Buffer) render(count int) string {
+	size := 0
+	if count > 0 {
+		size = sb.ends[count-1]
+	}
+	if size != sb.renderedAt {
+		// 合并多个think标签（企业微信只能识别一个）
+		sb.rendered = MergeThinkTags(string(sb.data[:size]))
+		sb.renderedAt = size
+	}
+	content := sb.rendered
+
+	// 追加临时进度提示
+	if sb.ephemeral != "" {
+		if content != "" {
+			content += "\n\n"
+		}
+		content += sb.ephemeral
+	}
+	return content
+}
+
+// SetAIFinished 标记AI完成生成
+func (sb *Buffer) SetAIFinished() {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	sb.aiFinished = true
+	sb.ephemeral = ""
+	sb.lastUpdate = time.Now()
+	sb.notify()
+}
+
+// IsEmpty 检查是否还有未展示的内容
+func (sb *Buffer) IsEmpty() bool {
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+
+	// 累积模式：检查是否所有内容都已展示
+	return sb.display.position() >= len(sb.ends)
+}
+
+// IsAIFinished 检查AI是否完成
+func (sb *Buffer) IsAIFinished() bool {
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+
+	return sb.aiFinished
+}
+
+// Snapshot 获取全部已生成内容（不影响展示进度）
+func (sb *Buffer) Snapshot() string {
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+
+	return string(sb.data)
+}
+
+// Tail 获取已生成内容末尾不超过n字节的部分（不截断UTF-8字符，不影响展示进度）
+func (sb *Buffer) Tail(n int) string {
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+
+	start := max(len(sb.data)-n, 0)
+	for start < len(sb.data) && !utf8.RuneStart(sb.data[start]) {
+		start++
+	}
+	return string(sb.data[start:])
+}
+
+// GetStatus 获取缓冲区状态（用于调试）
+func (sb *Buffer) GetStatus() (totalChunks int, displayedChunks int, aiFinished bool) {
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+
+	return len(sb.ends), sb.display.position(), sb.aiFinished
+}
@@ -0,0 +1,291 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+)
+
+// closed 通道是否已关闭
+func closed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestReadersIndependent(t *testing.T) {
+	sb := NewBuffer()
+	first, second := sb.NewReader(), sb.NewReader()
+
+	sb.Push("a")
+	sb.Push("b")
+	if got, _ := first.Read(); got != "ab" {
+		t.Fatalf("first.Read() = %q，期望 %q", got, "ab")
+	}
+	sb.Push("c")
+	if got, _ := first.Read(); got != "c" {
+		t.Errorf("first.Read() = %q，期望 %q", got, "c")
+	}
+	if got, _ := second.Read(); got != "abc" {
+		t.Errorf("second.Read() = %q，期望 %q", got, "abc")
+	}
+	if got, _ := first.Read(); got != "" {
+		t.Errorf("没有新内容时 first.Read() = %q", got)
+	}
+
+	// 其他读取者不影响展示进度
+	if total, displayed, _ := sb.GetStatus(); total != 3 || displayed != 0 {
+		t.Errorf("GetStatus() = %d, %d，期望 3, 0", total, displayed)
+	}
+	if sb.IsEmpty() {
+		t.Error("展示读取者尚未读取时 IsEmpty() 应为false")
+	}
+	if got, _ := sb.GetAccumulated(); got != "abc" {
+		t.Errorf("GetAccumulated() = %q，期望 %q", got, "abc")
+	}
+	if !sb.IsEmpty() {
+		t.Error("展示读取者读取后 IsEmpty() 应为true")
+	}
+}
+
+func TestReaderFinished(t *testing.T) {
+	sb := NewBuffer()
+	reader := sb.NewReader()
+
+	sb.Push("answer")
+	if content, finished := reader.Accumulated(); content != "answer" || finished {
+		t.Fatalf("Accumulated() = %q, %v，期望 %q, false", content, finished, "answer")
+	}
+
+	// 完成前的最后一块内容与完成状态在同一次读取中返回
+	sb.Push(" done")
+	sb.SetAIFinished()
+	if content, finished := reader.Accumulated(); content != "answer done" || !finished {
+		t.Errorf("Accumulated() = %q, %v，期望 %q, true", content, finished, "answer done")
+	}
+
+	// 新读取者从头读取，读取后同样报告完成
+	if content, finished := sb.NewReader().Read(); content != "answer done" || !finished {
+		t.Errorf("Read() = %q, %v，期望 %q, true", content, finished, "answer done")
+	}
+}
+
+func TestReaderSeekUnread(t *testing.T) {
+	sb := NewBuffer()
+	reader := sb.NewReader()
+	for _, chunk := range []string{"one ", "two ", "three"} {
+		sb.Push(chunk)
+	}
+	if n := reader.Unread(); n != 3 {
+		t.Fatalf("Unread() = %d，期望 3", n)
+	}
+
+	reader.Seek(1)
+	if n := reader.Unread(); n != 2 {
+		t.Errorf("Seek(1) 后 Unread() = %d，期望 2", n)
+	}
+	if got, _ := reader.Read(); got != "two three" {
+		t.Errorf("Seek(1) 后 Read() = %q，期望 %q", got, "two three")
+	}
+	if n := reader.Unread(); n != 0 {
+		t.Errorf("读取后 Unread() = %d，期望 0", n)
+	}
+
+	reader.Seek(-5)
+	if n := reader.Unread(); n != 3 {
+		t.Errorf("Seek(-5) 后 Unread() = %d，期望 3", n)
+	}
+	reader.Seek(99)
+	if n := reader.Unread(); n != 0 {
+		t.Errorf("Seek(99) 后 Unread() = %d，期望 0", n)
+	}
+	if got, _ := reader.Read(); got != "" {
+		t.Errorf("Seek(99) 后 Read() = %q", got)
+	}
+
+	reader.Seek(0)
+	if got, _ := reader.Read(); got != "one two three" {
+		t.Errorf("Seek(0) 后 Read() = %q，期望重放全部内容", got)
+	}
+}
+
+func TestRenderCache(t *testing.T) {
+	sb := NewBuffer()
+	sb.Push("<think>a</think>")
+	sb.Push("<think>b</think>")
+	sb.Push("answer")
+
+	want := "<think>\na\n\nb\n</think>\nanswer"
+	if got, _ := sb.GetAccumulated(); got != want {
+		t.Fatalf("GetAccumulated() = %q，期望 %q", got, want)
+	}
+	if sb.renderedAt != len(sb.data) {
+		t.Fatalf("renderedAt = %d，期望 %d", sb.renderedAt, len(sb.data))
+	}
+
+	// 内容没有变化时复用缓存，临时片段追加在缓存之后
+	sb.rendered = "cached"
+	sb.SetEphemeral("⏳")
+	if got := sb.Peek(); got != "cached\n\n⏳" {
+		t.Errorf("内容未变化时 Peek() = %q，期望复用缓存", got)
+	}
+
+	// 新内容使缓存失效并清除临时片段
+	sb.Push("!")
+	if got := sb.Peek(); got != want+"!" {
+		t.Errorf("新内容后 Peek() = %q，期望 %q", got, want+"!")
+	}
+
+	// 空内容只展示临时片段
+	empty := NewBuffer()
+	empty.SetEphemeral("⏳")
+	if got, _ := empty.GetAccumulated(); got != "⏳" {
+		t.Errorf("空内容时 GetAccumulated() = %q，期望 %q", got, "⏳")
+	}
+	empty.SetAIFinished()
+	if got, finished := empty.GetAccumulated(); got != "" || !finished {
+		t.Errorf("完成后 GetAccumulated() = %q, %v，期望临时片段被清除", got, finished)
+	}
+}
+
+func TestPeekSnapshotKeepProgress(t *testing.T) {
+	sb := NewBuffer()
+	sb.Push("<think>x</think>")
+	sb.Push("<think>y</think>z")
+
+	if got := sb.Snapshot(); got != "<think>x</think><think>y</think>z" {
+		t.Errorf("Snapshot() = %q，期望原始内容", got)
+	}
+	sb.Peek()
+	if _, displayed, _ := sb.GetStatus(); displayed != 0 {
+		t.Errorf("Peek/Snapshot 后展示进度为 %d，期望 0", displayed)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	sb := NewBuffer()
+	for name, change := range map[string]func(){
+		"Push":          func() { sb.Push("a") },
+		"SetEphemeral":  func() { sb.SetEphemeral("⏳") },
+		"SetAIFinished": func() { sb.SetAIFinished() },
+	} {
+		ch := sb.Changed()
+		if closed(ch) {
+			t.Fatalf("%s: 变化前通道已关闭", name)
+		}
+		change()
+		if !closed(ch) {
+			t.Errorf("%s: 变化后通道未关闭", name)
+		}
+	}
+
+	// 空内容不算变化
+	ch := sb.Changed()
+	sb.Push("")
+	if closed(ch) {
+		t.Error("Push(\"\") 不应通知读取者")
+	}
+}
+
+func TestTail(t *testing.T) {
+	sb := NewBuffer()
+	sb.Push("ab中文")
+	for n, want := range map[int]string{0: "", 3: "文", 4: "文", 6: "中文", 100: "ab中文"} {
+		if got := sb.Tail(n); got != want {
+			t.Errorf("Tail(%d) = %q，期望 %q", n, got, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	sb := NewBuffer()
+	sb.Push("答案：")
+	mark := sb.Chunks()
+	sb.Push("<think>\n")
+	sb.Push("先查询")
+	reader := sb.NewReader()
+	if got, _ := sb.GetAccumulated(); got != "答案：<think>\n先查询" {
+		t.Fatalf("GetAccumulated() = %q", got)
+	}
+	reader.Read()
+
+	changed := sb.Changed()
+	sb.Truncate(mark)
+	if !closed(changed) {
+		t.Error("Truncate 后应通知等待中的读取者")
+	}
+	if n := sb.Chunks(); n != mark {
+		t.Errorf("Chunks() = %d，期望 %d", n, mark)
+	}
+	if got := sb.Snapshot(); got != "答案：" {
+		t.Errorf("Snapshot() = %q，期望 %q", got, "答案：")
+	}
+
+	// 读取进度回退到新末尾，之后追加的内容正常读取，展示文本不复用截断前的缓存
+	sb.Push("42")
+	if got, _ := sb.GetAccumulated(); got != "答案：42" {
+		t.Errorf("GetAccumulated() = %q，期望 %q", got, "答案：42")
+	}
+	if got, _ := reader.Read(); got != "42" {
+		t.Errorf("Read() = %q，期望 %q", got, "42")
+	}
+	if n := reader.Unread(); n != 0 {
+		t.Errorf("Unread() = %d，期望 0", n)
+	}
+
+	// 超出范围时不做处理
+	sb.Truncate(99)
+	sb.Truncate(-1)
+	if got := sb.Snapshot(); got != "答案：42" {
+		t.Errorf("越界 Truncate 后 Snapshot() = %q", got)
+	}
+}
+
+func TestReleaseResets(t *testing.T) {
+	sb := AcquireBuffer()
+	sb.Push("<think>a</think><think>b</think>")
+	sb.SetEphemeral("⏳")
+	sb.GetAccumulated()
+	sb.SetAIFinished()
+	ch := sb.Changed()
+
+	sb.Release()
+	if !closed(ch) {
+		t.Error("Release 后等待中的读取者未被通知")
+	}
+	if len(sb.data) != 0 || len(sb.ends) != 0 || sb.rendered != "" || sb.renderedAt != -1 ||
+		sb.ephemeral != "" || sb.aiFinished || sb.display.next != 0 {
+		t.Errorf("Release 后状态未清空: data=%d ends=%d rendered=%q renderedAt=%d ephemeral=%q finished=%v next=%d",
+			len(sb.data), len(sb.ends), sb.rendered, sb.renderedAt, sb.ephemeral, sb.aiFinished, sb.display.next)
+	}
+}
+
+func TestAcquireAfterRelease(t *testing.T) {
+	// 复用池可能返回刚归还的缓冲区，也可能新建；两种情况都必须是空的
+	for i := 0; i < 100; i++ {
+		sb := AcquireBuffer()
+		if content, finished := sb.GetAccumulated(); content != "" || finished {
+			t.Fatalf("第%d次获取的缓冲区不为空: %q, %v", i, content, finished)
+		}
+		if sb.Snapshot() != "" || sb.IsAIFinished() {
+			t.Fatalf("第%d次获取的缓冲区残留上次的内容", i)
+		}
+		sb.Push(strings.Repeat("x", i+1))
+		sb.GetAccumulated()
+		sb.SetAIFinished()
+		sb.Release()
+	}
+}
+
+func TestReleaseDropsLargeBuffer(t *testing.T) {
+	sb := AcquireBuffer()
+	sb.Push(strings.Repeat("x", retainBytes+1))
+	sb.Release()
+
+	// 超过保留上限的缓冲区直接丢弃，不清空也不归还复用池
+	if len(sb.data) != retainBytes+1 {
+		t.Errorf("超过保留上限的缓冲区被清空: %d", len(sb.data))
+	}
+}
@@ -0,0 +1,6 @@
+// Package stream 流式回复的内容缓冲和思考过程（<think>块）处理
+//
+// 企业微信的流式消息每次刷新都要返回完整内容，Buffer 累积Agent输出的内容块并缓存展示文本，
+// 多个读取者（Reader）各自记录读取进度，可同时查看或重放同一回复；
+// 与具体渠道和Agent实现无关，可在其他项目中直接使用。
+package stream
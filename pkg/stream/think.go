@@ -0,0 +1,56 @@
+package stream
+
+import (
+	"regexp"
+	"strings"
+)
+
+// thinkBlockRegex 匹配完整或未闭合（仍在生成中）的think块
+var thinkBlockRegex = regexp.MustCompile(`(?s)<think>.*?(</think>|$)`)
+
+// StripThinkTags 移除思考过程，仅保留正式回复
+func StripThinkTags(content string) string {
+	if !strings.Contains(content, "<think>") {
+		return content
+	}
+	return strings.TrimLeft(thinkBlockRegex.ReplaceAllString(content, ""), "\n")
+}
+
+// MergeThinkTags 合并多个think标签为一个（企业微信只识别第一个）
+func MergeThinkTags(content string) string {
+	// 如果内容为空或不包含think标签，直接返回
+	if content == "" || !strings.Contains(content, "<think>") {
+		return content
+	}
+
+	// 使用正则表达式匹配所有的think标签及其内容
+	thinkRegex := regexp.MustCompile(`(?s)<think>(.*?)</think>`)
+	matches := thinkRegex.FindAllStringSubmatch(content, -1)
+
+	// 如果没有匹配或只有一个think标签，直接返回
+	if len(matches) <= 1 {
+		return content
+	}
+
+	// 收集所有think内容
+	var thinkContents []string
+	for _, match := range matches {
+		if len(match) > 1 && strings.TrimSpace(match[1]) != "" {
+			thinkContents = append(thinkContents, strings.TrimSpace(match[1]))
+		}
+	}
+
+	// 移除所有think标签
+	cleanContent := thinkRegex.ReplaceAllString(content, "")
+
+	// 如果没有收集到think内容，返回清理后的内容
+	if len(thinkContents) == 0 {
+		return cleanContent
+	}
+
+	// 合并所有think内容，用换行分隔
+	mergedThink := "<think>\n" + strings.Join(thinkContents, "\n\n") + "\n</think>\n"
+
+	// 将合并后的think标签放在内容开头
+	return mergedThink + strings.TrimSpace(cleanContent)
+}
@@ -0,0 +1,33 @@
+package stream
+
+import "testing"
+
+func TestStripThinkTags(t *testing.T) {
+	tests := map[string]string{
+		"answer":                             "answer",
+		"<think>x</think>\nanswer":           "answer",
+		"<think>x</think>a<think>y</think>b": "ab",
+		"answer<think>still thinking":        "answer",
+		"<think>only":                        "",
+	}
+	for input, want := range tests {
+		if got := StripThinkTags(input); got != want {
+			t.Errorf("StripThinkTags(%q) = %q，期望 %q", input, got, want)
+		}
+	}
+}
+
+func TestMergeThinkTags(t *testing.T) {
+	tests := map[string]string{
+		"":                                   "",
+		"answer":                             "answer",
+		"<think>x</think>answer":             "<think>x</think>answer",
+		"<think>x</think>a<think>y</think>b": "<think>\nx\n\ny\n</think>\nab",
+		"<think> </think>a<think></think>b":  "ab",
+	}
+	for input, want := range tests {
+		if got := MergeThinkTags(input); got != want {
+			t.Errorf("MergeThinkTags(%q) = %q，期望 %q", input, got, want)
+		}
+	}
+}